@@ -573,6 +573,21 @@ func (hs *HTTPServer) registerRoutes() {
 
 			// Some channels may have info
 			liveRoute.Get("/info/*", routing.Wrap(hs.Live.HandleInfoHTTP))
+
+			if hs.Live.Pipeline != nil {
+				// POST data to a channel processed by the Live pipeline.
+				liveRoute.Post("/pipeline/push/*", reqOrgAdmin, hs.LivePushGateway.HandlePipelinePush)
+				liveRoute.Post("/pipeline-convert-test", reqOrgAdmin, routing.Wrap(hs.Live.HandlePipelineConvertTestHTTP))
+				liveRoute.Get("/pipeline-entities", reqOrgAdmin, routing.Wrap(hs.Live.HandlePipelineEntitiesListHTTP))
+				liveRoute.Get("/channel-rules", reqOrgAdmin, routing.Wrap(hs.Live.HandleChannelRulesListHTTP))
+				liveRoute.Post("/channel-rules", reqOrgAdmin, routing.Wrap(hs.Live.HandleChannelRulesPostHTTP))
+				liveRoute.Put("/channel-rules", reqOrgAdmin, routing.Wrap(hs.Live.HandleChannelRulesPutHTTP))
+				liveRoute.Delete("/channel-rules", reqOrgAdmin, routing.Wrap(hs.Live.HandleChannelRulesDeleteHTTP))
+				liveRoute.Get("/write-configs", reqOrgAdmin, routing.Wrap(hs.Live.HandleWriteConfigsListHTTP))
+				liveRoute.Post("/write-configs", reqOrgAdmin, routing.Wrap(hs.Live.HandleWriteConfigsPostHTTP))
+				liveRoute.Put("/write-configs", reqOrgAdmin, routing.Wrap(hs.Live.HandleWriteConfigsPutHTTP))
+				liveRoute.Delete("/write-configs", reqOrgAdmin, routing.Wrap(hs.Live.HandleWriteConfigsDeleteHTTP))
+			}
 		}, requestmeta.SetSLOGroup(requestmeta.SLOGroupNone))
 
 		// short urls
@@ -0,0 +1,148 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// AnomalyCommand is an expression command that detects anomalies in time series locally,
+// by comparing each point with a baseline calculated from the points before it.
+type AnomalyCommand struct {
+	VarToDetect string
+	Options     mathexp.AnomalyOptions
+	// IncludeBands adds the upper and lower band series to the result, next to the score series.
+	IncludeBands bool
+	refID        string
+}
+
+// NewAnomalyCommand creates a new AnomalyCommand from the query properties and validates them.
+func NewAnomalyCommand(refID, varToDetect string, q *AnomalyQuery) (*AnomalyCommand, error) {
+	opts := mathexp.AnomalyOptions{
+		Algorithm:   q.Algorithm,
+		Sensitivity: mathexp.DefaultAnomalySensitivity,
+		Alpha:       mathexp.DefaultHoltWintersAlpha,
+		Beta:        mathexp.DefaultHoltWintersBeta,
+		Gamma:       mathexp.DefaultHoltWintersGamma,
+	}
+	if q.Sensitivity != nil {
+		if *q.Sensitivity <= 0 {
+			return nil, fmt.Errorf("anomaly sensitivity must be positive, got %v", *q.Sensitivity)
+		}
+		opts.Sensitivity = *q.Sensitivity
+	}
+
+	switch q.Algorithm {
+	case mathexp.AnomalyMAD:
+		if q.Window == "" {
+			return nil, fmt.Errorf(`the %s algorithm requires a "window"`, q.Algorithm)
+		}
+		window, err := gtime.ParseDuration(q.Window)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse anomaly "window" duration field %q: %w`, q.Window, err)
+		}
+		if window <= 0 {
+			return nil, fmt.Errorf(`anomaly "window" must be positive, got %q`, q.Window)
+		}
+		opts.Window = window
+	case mathexp.AnomalyHoltWinters:
+		if q.Season != "" {
+			season, err := gtime.ParseDuration(q.Season)
+			if err != nil {
+				return nil, fmt.Errorf(`failed to parse anomaly "season" duration field %q: %w`, q.Season, err)
+			}
+			if season <= 0 {
+				return nil, fmt.Errorf(`anomaly "season" must be positive, got %q`, q.Season)
+			}
+			opts.Season = season
+		}
+		if s := q.Smoothing; s != nil {
+			for _, f := range []struct {
+				name  string
+				value *float64
+				dst   *float64
+			}{
+				{"alpha", s.Alpha, &opts.Alpha},
+				{"beta", s.Beta, &opts.Beta},
+				{"gamma", s.Gamma, &opts.Gamma},
+			} {
+				if f.value == nil {
+					continue
+				}
+				if *f.value <= 0 || *f.value > 1 {
+					return nil, fmt.Errorf("anomaly smoothing factor %s must be in the range (0, 1], got %v", f.name, *f.value)
+				}
+				*f.dst = *f.value
+			}
+		}
+	default:
+		return nil, fmt.Errorf("expected anomaly algorithm to be one of [%s, %s], got %q", mathexp.AnomalyMAD, mathexp.AnomalyHoltWinters, q.Algorithm)
+	}
+
+	return &AnomalyCommand{
+		VarToDetect:  varToDetect,
+		Options:      opts,
+		IncludeBands: q.IncludeBands,
+		refID:        refID,
+	}, nil
+}
+
+// UnmarshalAnomalyCommand creates an AnomalyCommand from Grafana's frontend query.
+func UnmarshalAnomalyCommand(rn *rawNode) (*AnomalyCommand, error) {
+	q := &AnomalyQuery{}
+	if err := json.Unmarshal(rn.QueryRaw, q); err != nil {
+		return nil, fmt.Errorf("failed to parse the anomaly command: %w", err)
+	}
+	varToDetect := strings.TrimPrefix(q.Expression, "$")
+	if varToDetect == "" {
+		return nil, fmt.Errorf("no variable specified to reference for refId %v", rn.RefID)
+	}
+	return NewAnomalyCommand(rn.RefID, varToDetect, q)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (gr *AnomalyCommand) NeedsVars() []string {
+	return []string{gr.VarToDetect}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (gr *AnomalyCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
+	_, span := tracer.Start(ctx, "SSE.ExecuteAnomaly")
+	defer span.End()
+	span.SetAttributes(attribute.String("algorithm", string(gr.Options.Algorithm)))
+
+	newRes := mathexp.Results{}
+	for _, val := range vars[gr.VarToDetect].Values {
+		switch v := val.(type) {
+		case mathexp.Series:
+			anomalies, err := v.DetectAnomalies(gr.refID, gr.Options)
+			if err != nil {
+				return newRes, err
+			}
+			newRes.Values = append(newRes.Values, anomalies.Score)
+			if gr.IncludeBands {
+				newRes.Values = append(newRes.Values, anomalies.Upper, anomalies.Lower)
+			}
+		case mathexp.NoData:
+			newRes.Values = append(newRes.Values, v.New())
+			return newRes, nil
+		default:
+			return newRes, fmt.Errorf("can only detect anomalies in type series, got type %v", val.Type())
+		}
+	}
+	return newRes, nil
+}
+
+func (gr *AnomalyCommand) Type() string {
+	return TypeAnomaly.String()
+}
@@ -0,0 +1,116 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestUnmarshalAnomalyCommand(t *testing.T) {
+	unmarshal := func(query string) (*AnomalyCommand, error) {
+		return UnmarshalAnomalyCommand(&rawNode{RefID: "B", QueryRaw: []byte(query)})
+	}
+
+	t.Run("mad with defaults", func(t *testing.T) {
+		cmd, err := unmarshal(`{"expression": "$A", "algorithm": "mad", "window": "1h"}`)
+		require.NoError(t, err)
+		require.Equal(t, "A", cmd.VarToDetect)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		require.Equal(t, time.Hour, cmd.Options.Window)
+		require.Equal(t, mathexp.DefaultAnomalySensitivity, cmd.Options.Sensitivity)
+		require.False(t, cmd.IncludeBands)
+	})
+
+	t.Run("holt_winters with all options", func(t *testing.T) {
+		cmd, err := unmarshal(`{"expression": "A", "algorithm": "holt_winters", "season": "1d", "sensitivity": 2,
+			"smoothing": {"alpha": 0.2, "beta": 0.05, "gamma": 0.3}, "includeBands": true}`)
+		require.NoError(t, err)
+		require.Equal(t, mathexp.AnomalyOptions{
+			Algorithm:   mathexp.AnomalyHoltWinters,
+			Season:      24 * time.Hour,
+			Sensitivity: 2,
+			Alpha:       0.2,
+			Beta:        0.05,
+			Gamma:       0.3,
+		}, cmd.Options)
+		require.True(t, cmd.IncludeBands)
+	})
+
+	for _, tc := range []struct {
+		name  string
+		query string
+	}{
+		{"missing expression", `{"algorithm": "mad", "window": "1h"}`},
+		{"unknown algorithm", `{"expression": "$A", "algorithm": "foo"}`},
+		{"mad without window", `{"expression": "$A", "algorithm": "mad"}`},
+		{"invalid window", `{"expression": "$A", "algorithm": "mad", "window": "foo"}`},
+		{"invalid season", `{"expression": "$A", "algorithm": "holt_winters", "season": "-1h"}`},
+		{"invalid sensitivity", `{"expression": "$A", "algorithm": "mad", "window": "1h", "sensitivity": 0}`},
+		{"invalid smoothing", `{"expression": "$A", "algorithm": "holt_winters", "smoothing": {"gamma": 2}}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := unmarshal(tc.query)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAnomalyCommandExecute(t *testing.T) {
+	series := mathexp.NewSeries("A", data.Labels{"host": "a"}, 6)
+	for i, v := range []float64{1, 2, 3, 4, 100, 3} {
+		series.SetPoint(i, time.Unix(int64(i*60), 0), util.Pointer(v))
+	}
+	vars := mathexp.Vars{"A": mathexp.Results{Values: mathexp.Values{series}}}
+
+	t.Run("returns the score series", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", &AnomalyQuery{Algorithm: mathexp.AnomalyMAD, Window: "5m"})
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, rsp.Values, 1)
+		score, ok := rsp.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"host": "a"}, score.GetLabels())
+		require.Greater(t, *score.GetValue(4), 1.0)
+	})
+
+	t.Run("returns the bands when requested", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", &AnomalyQuery{Algorithm: mathexp.AnomalyMAD, Window: "5m", IncludeBands: true})
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.Len(t, rsp.Values, 3)
+		require.Equal(t, "upper", rsp.Values[1].GetLabels()[mathexp.AnomalyBandLabel])
+		require.Equal(t, "lower", rsp.Values[2].GetLabels()[mathexp.AnomalyBandLabel])
+	})
+
+	t.Run("passes no data through", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", &AnomalyQuery{Algorithm: mathexp.AnomalyMAD, Window: "5m"})
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNoData()}},
+		}, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, rsp.IsNoData())
+	})
+
+	t.Run("fails for numbers", func(t *testing.T) {
+		cmd, err := NewAnomalyCommand("B", "A", &AnomalyQuery{Algorithm: mathexp.AnomalyMAD, Window: "5m"})
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(context.Background(), time.Now(), mathexp.Vars{
+			"A": mathexp.Results{Values: mathexp.Values{mathexp.NewNumber("A", nil)}},
+		}, tracing.InitializeTracerForTest())
+		require.Error(t, err)
+	})
+}
@@ -83,6 +83,7 @@ func (gm *MathCommand) Type() string {
 // ReduceCommand is an expression command for reduction of a timeseries such as a min, mean, or max.
 type ReduceCommand struct {
 	Reducer      mathexp.ReducerID
+	ReducerArgs  mathexp.ReducerArgs
 	VarToReduce  string
 	refID        string
 	seriesMapper mathexp.ReduceMapper
@@ -90,13 +91,19 @@ type ReduceCommand struct {
 
 // NewReduceCommand creates a new ReduceCMD.
 func NewReduceCommand(refID string, reducer mathexp.ReducerID, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
-	_, err := mathexp.GetReduceFunc(reducer)
+	return NewReduceCommandWithArgs(refID, reducer, mathexp.ReducerArgs{}, varToReduce, mapper)
+}
+
+// NewReduceCommandWithArgs creates a new ReduceCMD for reducers that accept arguments, such as percentile.
+func NewReduceCommandWithArgs(refID string, reducer mathexp.ReducerID, args mathexp.ReducerArgs, varToReduce string, mapper mathexp.ReduceMapper) (*ReduceCommand, error) {
+	_, err := mathexp.GetSeriesReduceFunc(reducer, args)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ReduceCommand{
 		Reducer:      reducer,
+		ReducerArgs:  args,
 		VarToReduce:  varToReduce,
 		refID:        refID,
 		seriesMapper: mapper,
@@ -126,10 +133,18 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 	redFunc := mathexp.ReducerID(strings.ToLower(redString))
 
 	var mapper mathexp.ReduceMapper = nil
+	var args mathexp.ReducerArgs
 	settings, ok := rn.Query["settings"]
 	if ok {
 		switch s := settings.(type) {
 		case map[string]any:
+			if rawPercentile, ok := s["percentile"]; ok {
+				percentile, ok := rawPercentile.(float64)
+				if !ok {
+					return nil, fmt.Errorf("setting percentile must be a number, got %T", rawPercentile)
+				}
+				args.Percentile = &percentile
+			}
 			mode, ok := s["mode"]
 			if ok && mode != "" {
 				switch mode {
@@ -154,7 +169,7 @@ func UnmarshalReduceCommand(rn *rawNode) (*ReduceCommand, error) {
 			return nil, fmt.Errorf("field settings must be an object, got %T for refId %v", s, rn.RefID)
 		}
 	}
-	return NewReduceCommand(rn.RefID, redFunc, varToReduce, mapper)
+	return NewReduceCommandWithArgs(rn.RefID, redFunc, args, varToReduce, mapper)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -175,7 +190,7 @@ func (gr *ReduceCommand) Execute(ctx context.Context, _ time.Time, vars mathexp.
 	for i, val := range vars[gr.VarToReduce].Values {
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Reduce(gr.refID, gr.Reducer, gr.seriesMapper)
+			num, err := v.ReduceWithArgs(gr.refID, gr.Reducer, gr.ReducerArgs, gr.seriesMapper)
 			if err != nil {
 				return newRes, err
 			}
@@ -217,11 +232,13 @@ type ResampleCommand struct {
 	Downsampler   mathexp.ReducerID
 	Upsampler     mathexp.Upsampler
 	TimeRange     TimeRange
-	refID         string
+	// Alignment of the resampled points. If nil, the points start at the beginning of the time range.
+	Alignment *mathexp.ResampleAlignment
+	refID     string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange) (*ResampleCommand, error) {
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler mathexp.ReducerID, upsampler mathexp.Upsampler, tr TimeRange, alignment *mathexp.ResampleAlignment) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
@@ -233,10 +250,32 @@ func NewResampleCommand(refID, rawWindow, varToResample string, downsampler math
 		Downsampler:   downsampler,
 		Upsampler:     upsampler,
 		TimeRange:     tr,
+		Alignment:     alignment,
 		refID:         refID,
 	}, nil
 }
 
+// NewResampleAlignment creates the alignment of resampled points from the raw offset duration and IANA time zone name.
+// Both can be empty, in which case the points are aligned to multiples of the window in UTC.
+func NewResampleAlignment(rawOffset, timezone string) (*mathexp.ResampleAlignment, error) {
+	alignment := &mathexp.ResampleAlignment{}
+	if rawOffset != "" {
+		offset, err := gtime.ParseDuration(rawOffset)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to parse resample alignment "offset" duration field %q: %w`, rawOffset, err)
+		}
+		alignment.Offset = offset
+	}
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf(`failed to load resample alignment "timezone" %q: %w`, timezone, err)
+		}
+		alignment.Location = loc
+	}
+	return alignment, nil
+}
+
 // UnmarshalResampleCommand creates a ResampleCMD from Grafana's frontend query.
 func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 	if rn.TimeRange == nil {
@@ -280,11 +319,36 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T", upsampler)
 	}
 
+	var alignment *mathexp.ResampleAlignment
+	if rawAlign, ok := rn.Query["align"]; ok {
+		align, ok := rawAlign.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("field align must be an object, got %T for refId %v", rawAlign, rn.RefID)
+		}
+		var offset, timezone string
+		if rawOffset, ok := align["offset"]; ok {
+			if offset, ok = rawOffset.(string); !ok {
+				return nil, fmt.Errorf("expected resample alignment offset to be a string, got type %T", rawOffset)
+			}
+		}
+		if rawTimezone, ok := align["timezone"]; ok {
+			if timezone, ok = rawTimezone.(string); !ok {
+				return nil, fmt.Errorf("expected resample alignment timezone to be a string, got type %T", rawTimezone)
+			}
+		}
+		var err error
+		alignment, err = NewResampleAlignment(offset, timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return NewResampleCommand(rn.RefID, window,
 		varToResample,
 		mathexp.ReducerID(downsampler),
 		mathexp.Upsampler(upsampler),
-		rn.TimeRange)
+		rn.TimeRange,
+		alignment)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -306,7 +370,13 @@ func (gr *ResampleCommand) Execute(ctx context.Context, now time.Time, vars math
 		}
 		switch v := val.(type) {
 		case mathexp.Series:
-			num, err := v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			var num mathexp.Series
+			var err error
+			if gr.Alignment != nil {
+				num, err = v.ResampleAligned(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To, *gr.Alignment)
+			} else {
+				num, err = v.Resample(gr.refID, gr.Window, gr.Downsampler, gr.Upsampler, timeRange.From, timeRange.To)
+			}
 			if err != nil {
 				return newRes, err
 			}
@@ -343,6 +413,8 @@ const (
 	TypeThreshold
 	// TypeSQL is the CMDType for running SQL expressions
 	TypeSQL
+	// TypeAnomaly is the CMDType for detecting anomalies in time series
+	TypeAnomaly
 )
 
 func (gt CommandType) String() string {
@@ -359,6 +431,8 @@ func (gt CommandType) String() string {
 		return "threshold"
 	case TypeSQL:
 		return "sql"
+	case TypeAnomaly:
+		return "anomaly"
 	default:
 		return "unknown"
 	}
@@ -379,6 +453,8 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeThreshold, nil
 	case "sql":
 		return TypeSQL, nil
+	case "anomaly":
+		return TypeAnomaly, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
@@ -70,6 +70,10 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 			name:          "error if mode is 'replaceNN' but field replaceWithValue is not a number",
 			querySettings: `, "settings" : { "mode": "replaceNN", "replaceWithValue" : "-12" }`,
 			isError:       true,
+		}, {
+			name:          "error if percentile is not a number",
+			querySettings: `, "settings" : { "percentile": "95" }`,
+			isError:       true,
 		},
 	}
 
@@ -99,6 +103,36 @@ func Test_UnmarshalReduceCommand_Settings(t *testing.T) {
 	}
 }
 
+func Test_UnmarshalReduceCommand_Percentile(t *testing.T) {
+	unmarshal := func(q string) (*ReduceCommand, error) {
+		var qmap = make(map[string]any)
+		require.NoError(t, json.Unmarshal([]byte(q), &qmap))
+		return UnmarshalReduceCommand(&rawNode{
+			RefID:     "B",
+			Query:     qmap,
+			TimeRange: RelativeTimeRange{},
+		})
+	}
+
+	t.Run("should read the percentile from settings", func(t *testing.T) {
+		cmd, err := unmarshal(`{ "expression" : "$A", "reducer": "percentile", "settings": { "mode": "dropNN", "percentile": 95 } }`)
+		require.NoError(t, err)
+		require.Equal(t, mathexp.ReducerPercentile, cmd.Reducer)
+		require.Equal(t, util.Pointer(95.0), cmd.ReducerArgs.Percentile)
+		require.Equal(t, mathexp.DropNonNumber{}, cmd.seriesMapper)
+	})
+
+	t.Run("should error if percentile is missing", func(t *testing.T) {
+		_, err := unmarshal(`{ "expression" : "$A", "reducer": "percentile" }`)
+		require.Error(t, err)
+	})
+
+	t.Run("should error if percentile is out of range", func(t *testing.T) {
+		_, err := unmarshal(`{ "expression" : "$A", "reducer": "percentile", "settings": { "percentile": -1 } }`)
+		require.Error(t, err)
+	})
+}
+
 func TestReduceExecute(t *testing.T) {
 	varToReduce := util.GenerateShortUID()
 
@@ -211,7 +245,12 @@ func TestReduceExecute(t *testing.T) {
 }
 
 func randomReduceFunc() mathexp.ReducerID {
-	res := mathexp.GetSupportedReduceFuncs()
+	res := make([]mathexp.ReducerID, 0)
+	for _, r := range mathexp.GetSupportedReduceFuncs() {
+		if !mathexp.IsParameterisedReducer(r) {
+			res = append(res, r)
+		}
+	}
 	return res[rand.Intn(len(res))]
 }
 
@@ -221,7 +260,7 @@ func TestResampleCommand_Execute(t *testing.T) {
 		From: -10 * time.Second,
 		To:   0,
 	}
-	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr)
+	cmd, err := NewResampleCommand(util.GenerateShortUID(), "1s", varToReduce, "sum", "pad", tr, nil)
 	require.NoError(t, err)
 
 	var tests = []struct {
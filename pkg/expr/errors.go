@@ -93,3 +93,54 @@ func makeUnexpectedNodeTypeError(refID, nodeType string) error {
 
 	return UnexpectedNodeTypeError.Build(data)
 }
+
+var SQLParseError = errutil.BadRequest("sse.sqlParseError").MustTemplate(
+	"failed to parse SQL expression [{{ .Public.refId }}]: {{ .Error }}",
+	errutil.WithPublic(
+		"failed to parse SQL expression [{{ .Public.refId }}]: {{ .Public.error }}",
+	))
+
+func makeSQLParseError(refID string, err error) error {
+	data := errutil.TemplateData{
+		Public: map[string]any{
+			"refId": refID,
+			"error": err.Error(),
+		},
+		Error: err,
+	}
+	return SQLParseError.Build(data)
+}
+
+var SQLQueryError = errutil.BadRequest("sse.sqlQueryError").MustTemplate(
+	"failed to execute SQL expression [{{ .Public.refId }}]: {{ .Error }}",
+	errutil.WithPublic(
+		"failed to execute SQL expression [{{ .Public.refId }}]: {{ .Public.error }}",
+	))
+
+func makeSQLQueryError(refID string, err error) error {
+	data := errutil.TemplateData{
+		Public: map[string]any{
+			"refId": refID,
+			"error": err.Error(),
+		},
+		Error: err,
+	}
+	return SQLQueryError.Build(data)
+}
+
+var SQLLimitError = errutil.BadRequest("sse.sqlLimitExceeded").MustTemplate(
+	"SQL expression [{{ .Public.refId }}] exceeded a resource limit: {{ .Error }}",
+	errutil.WithPublic(
+		"SQL expression [{{ .Public.refId }}] exceeded a resource limit: {{ .Public.error }}",
+	))
+
+func makeSQLLimitError(refID string, err error) error {
+	data := errutil.TemplateData{
+		Public: map[string]any{
+			"refId": refID,
+			"error": err.Error(),
+		},
+		Error: err,
+	}
+	return SQLLimitError.Build(data)
+}
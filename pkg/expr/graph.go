@@ -279,7 +279,13 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 		case TypeDatasourceNode:
 			node, err = s.buildDSNode(dp, rn, req)
 		case TypeCMDNode:
-			node, err = buildCMDNode(rn, s.features)
+			var cmdNode *CMDNode
+			if cmdNode, err = buildCMDNode(rn, s.features); err == nil {
+				if sqlCmd, ok := cmdNode.Command.(*SQLCommand); ok {
+					sqlCmd.limits = s.sqlLimits()
+				}
+				node = cmdNode
+			}
 		case TypeMLNode:
 			if s.features.IsEnabledGlobally(featuremgmt.FlagMlExpressions) {
 				node, err = s.buildMLNode(dp, rn, req)
@@ -0,0 +1,337 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// The anomaly detection algorithm
+// +enum
+type AnomalyAlgorithm string
+
+const (
+	// Rolling median and median absolute deviation over a trailing window
+	AnomalyMAD AnomalyAlgorithm = "mad"
+
+	// Additive Holt-Winters forecast with deviation bands, seasonal when a season is set
+	AnomalyHoltWinters AnomalyAlgorithm = "holt_winters"
+)
+
+// AnomalyBandLabel is the label that distinguishes the upper and lower band series
+// from the score series of the same input series.
+const AnomalyBandLabel = "anomaly_band"
+
+const (
+	// DefaultAnomalySensitivity is the default half width of the bands, in deviations.
+	DefaultAnomalySensitivity = 3.0
+	// DefaultHoltWintersAlpha is the default smoothing factor of the level.
+	DefaultHoltWintersAlpha = 0.3
+	// DefaultHoltWintersBeta is the default smoothing factor of the trend.
+	DefaultHoltWintersBeta = 0.1
+	// DefaultHoltWintersGamma is the default smoothing factor of the seasonal component and the deviation.
+	DefaultHoltWintersGamma = 0.1
+
+	// madMinPoints is the minimum number of points in the window of the mad algorithm.
+	madMinPoints = 3
+	// madScale makes the median absolute deviation a consistent estimator of the
+	// standard deviation of normally distributed values.
+	madScale = 1.4826
+)
+
+// AnomalyOptions configure the detection of anomalies in a series.
+type AnomalyOptions struct {
+	Algorithm AnomalyAlgorithm
+	// Window is the trailing time window the baseline of the mad algorithm is calculated from.
+	Window time.Duration
+	// Season is the length of the seasonal cycle of the holt_winters algorithm. Zero disables seasonality.
+	Season time.Duration
+	// Sensitivity is the half width of the bands, in deviations.
+	Sensitivity float64
+	// Alpha, Beta and Gamma are the smoothing factors of the level, the trend and the
+	// seasonal component and deviation of the holt_winters algorithm.
+	Alpha, Beta, Gamma float64
+}
+
+// Anomalies is the result of the anomaly detection of a single series.
+type Anomalies struct {
+	// Score is the distance of each point from the baseline in multiples of the half width of the bands.
+	// It is positive above and negative below the baseline, so points with an absolute score above 1 are
+	// outside the bands. It is null where the point or the baseline is null.
+	Score Series
+	// Upper and Lower are the bands around the baseline.
+	Upper Series
+	Lower Series
+}
+
+// DetectAnomalies compares every point of the series with a baseline calculated from the points before it.
+// The points do not have to be sorted, but the holt_winters algorithm assumes that they are evenly spaced.
+func (s Series) DetectAnomalies(refID string, opts AnomalyOptions) (Anomalies, error) {
+	if opts.Sensitivity <= 0 {
+		return Anomalies{}, fmt.Errorf("the anomaly sensitivity must be positive")
+	}
+
+	points := sortedPoints(s)
+	var bands []anomalyBand
+	switch opts.Algorithm {
+	case AnomalyMAD:
+		if opts.Window <= 0 {
+			return Anomalies{}, fmt.Errorf("the mad algorithm requires a positive window")
+		}
+		bands = madBands(points, opts.Window)
+	case AnomalyHoltWinters:
+		for _, f := range []float64{opts.Alpha, opts.Beta, opts.Gamma} {
+			if f <= 0 || f > 1 {
+				return Anomalies{}, fmt.Errorf("the holt_winters smoothing factors must be in the range (0, 1], got %v", f)
+			}
+		}
+		var err error
+		if bands, err = holtWintersBands(points, opts); err != nil {
+			return Anomalies{}, err
+		}
+	default:
+		return Anomalies{}, fmt.Errorf("unsupported anomaly detection algorithm '%s'", opts.Algorithm)
+	}
+
+	labels := s.GetLabels()
+	bandLabels := func(band string) data.Labels {
+		l := labels.Copy()
+		if l == nil {
+			l = data.Labels{}
+		}
+		l[AnomalyBandLabel] = band
+		return l
+	}
+	result := Anomalies{
+		Score: NewSeries(refID, labels.Copy(), len(points)),
+		Upper: NewSeries(refID, bandLabels("upper"), len(points)),
+		Lower: NewSeries(refID, bandLabels("lower"), len(points)),
+	}
+	for i, p := range points {
+		b := bands[i]
+		if !b.ok {
+			result.Score.SetPoint(i, p.t, nil)
+			result.Upper.SetPoint(i, p.t, nil)
+			result.Lower.SetPoint(i, p.t, nil)
+			continue
+		}
+		width := opts.Sensitivity * b.deviation
+		upper, lower := b.baseline+width, b.baseline-width
+		result.Upper.SetPoint(i, p.t, &upper)
+		result.Lower.SetPoint(i, p.t, &lower)
+		if p.v == nil {
+			result.Score.SetPoint(i, p.t, nil)
+			continue
+		}
+		score := anomalyScore(*p.v, b.baseline, width)
+		result.Score.SetPoint(i, p.t, &score)
+	}
+	return result, nil
+}
+
+// anomalyScore returns the distance of v from the baseline in multiples of width.
+func anomalyScore(v, baseline, width float64) float64 {
+	d := v - baseline
+	if width == 0 {
+		switch {
+		case d > 0:
+			return math.Inf(1)
+		case d < 0:
+			return math.Inf(-1)
+		}
+		return 0
+	}
+	return d / width
+}
+
+type anomalyPoint struct {
+	t time.Time
+	v *float64 // nil for null and NaN values
+}
+
+// anomalyBand is the baseline and the deviation at a point. ok is false when there
+// is not enough data before the point to calculate them.
+type anomalyBand struct {
+	baseline  float64
+	deviation float64
+	ok        bool
+}
+
+func sortedPoints(s Series) []anomalyPoint {
+	points := make([]anomalyPoint, s.Len())
+	for i := range points {
+		t, v := s.GetPoint(i)
+		if v != nil && math.IsNaN(*v) {
+			v = nil
+		}
+		points[i] = anomalyPoint{t: t, v: v}
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].t.Before(points[j].t)
+	})
+	return points
+}
+
+// madBands uses the median of the values in the window before each point as the baseline and
+// the scaled median absolute deviation from it as the deviation.
+func madBands(points []anomalyPoint, window time.Duration) []anomalyBand {
+	bands := make([]anomalyBand, len(points))
+	values := make([]float64, 0, len(points))
+	start := 0
+	for i, p := range points {
+		for start < i && !points[start].t.After(p.t.Add(-window)) {
+			start++
+		}
+		values = values[:0]
+		for _, w := range points[start:i] {
+			if w.v != nil {
+				values = append(values, *w.v)
+			}
+		}
+		if len(values) < madMinPoints {
+			continue
+		}
+		median := medianOf(values)
+		for j, v := range values {
+			values[j] = math.Abs(v - median)
+		}
+		bands[i] = anomalyBand{baseline: median, deviation: madScale * medianOf(values), ok: true}
+	}
+	return bands
+}
+
+// medianOf returns the median of values, which are reordered.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
+
+// holtWintersBands uses the one step ahead forecast of additive Holt-Winters exponential smoothing as
+// the baseline. The deviation is the exponentially smoothed absolute forecast error, per position in the
+// season, as described by Brutlag in "Aberrant Behavior Detection in Time Series for Network Monitoring".
+func holtWintersBands(points []anomalyPoint, opts AnomalyOptions) ([]anomalyBand, error) {
+	bands := make([]anomalyBand, len(points))
+
+	period := 1
+	if opts.Season > 0 {
+		step := medianStep(points)
+		if step <= 0 {
+			return bands, nil
+		}
+		period = int(math.Round(float64(opts.Season) / float64(step)))
+		if period < 2 {
+			return nil, fmt.Errorf("the season %v must span at least two points, the points are %v apart", opts.Season, step)
+		}
+	}
+
+	level, trend, seasonal, deviation, start, ok := holtWintersInit(points, period)
+	if !ok {
+		return bands, nil
+	}
+
+	for i := start; i < len(points); i++ {
+		k := i % period
+		forecast := level + trend + seasonal[k]
+		bands[i] = anomalyBand{baseline: forecast, deviation: deviation[k], ok: true}
+
+		p := points[i]
+		if p.v == nil {
+			// continue the forecast without updating the model
+			level += trend
+			continue
+		}
+		v := *p.v
+		prevLevel := level
+		level = opts.Alpha*(v-seasonal[k]) + (1-opts.Alpha)*(level+trend)
+		trend = opts.Beta*(level-prevLevel) + (1-opts.Beta)*trend
+		if period > 1 {
+			seasonal[k] = opts.Gamma*(v-level) + (1-opts.Gamma)*seasonal[k]
+		}
+		deviation[k] = opts.Gamma*math.Abs(v-forecast) + (1-opts.Gamma)*deviation[k]
+	}
+	return bands, nil
+}
+
+// holtWintersInit estimates the initial state of the model. Without seasonality it uses the first two
+// values, otherwise the first two seasons. start is the index of the first point that is forecast.
+func holtWintersInit(points []anomalyPoint, period int) (level, trend float64, seasonal, deviation []float64, start int, ok bool) {
+	seasonal = make([]float64, period)
+	deviation = make([]float64, period)
+
+	if period == 1 {
+		first := -1
+		for i, p := range points {
+			if p.v == nil {
+				continue
+			}
+			if first < 0 {
+				first = i
+				continue
+			}
+			level = *p.v
+			trend = *p.v - *points[first].v
+			deviation[0] = math.Abs(trend)
+			return level, trend, seasonal, deviation, i + 1, true
+		}
+		return 0, 0, nil, nil, 0, false
+	}
+
+	if len(points) < 2*period {
+		return 0, 0, nil, nil, 0, false
+	}
+	mean := func(ps []anomalyPoint) (float64, bool) {
+		sum, n := 0.0, 0
+		for _, p := range ps {
+			if p.v != nil {
+				sum += *p.v
+				n++
+			}
+		}
+		return sum / float64(n), n > 0
+	}
+	first, ok1 := mean(points[:period])
+	second, ok2 := mean(points[period : 2*period])
+	if !ok1 || !ok2 {
+		return 0, 0, nil, nil, 0, false
+	}
+	level = first
+	trend = (second - first) / float64(period)
+
+	// the initial deviation is the mean absolute difference between the two seasons, after removing the trend
+	diffSum, diffs := 0.0, 0
+	for k := 0; k < period; k++ {
+		if v := points[k].v; v != nil {
+			seasonal[k] = *v - first
+		}
+		a, b := points[k].v, points[k+period].v
+		if a != nil && b != nil {
+			diffSum += math.Abs(*b - *a - (second - first))
+			diffs++
+		}
+	}
+	if diffs > 0 {
+		for k := range deviation {
+			deviation[k] = diffSum / float64(diffs)
+		}
+	}
+	return level, trend, seasonal, deviation, period, true
+}
+
+// medianStep returns the median time between consecutive points.
+func medianStep(points []anomalyPoint) time.Duration {
+	if len(points) < 2 {
+		return 0
+	}
+	steps := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		steps = append(steps, float64(points[i].t.Sub(points[i-1].t)))
+	}
+	return time.Duration(medianOf(steps))
+}
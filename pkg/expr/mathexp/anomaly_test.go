@@ -0,0 +1,155 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeEvenSeries(step time.Duration, values ...float64) Series {
+	points := make([]tp, len(values))
+	for i, v := range values {
+		points[i] = tp{time.Unix(0, 0).Add(time.Duration(i) * step), float64Pointer(v)}
+	}
+	return makeSeries("A", data.Labels{"host": "a"}, points...)
+}
+
+func TestDetectAnomaliesMAD(t *testing.T) {
+	s := makeEvenSeries(time.Minute, 1, 2, 3, 4, 100, 3)
+	opts := AnomalyOptions{Algorithm: AnomalyMAD, Window: 4 * time.Minute, Sensitivity: 3}
+
+	result, err := s.DetectAnomalies("B", opts)
+	require.NoError(t, err)
+
+	require.Equal(t, data.Labels{"host": "a"}, result.Score.GetLabels())
+	require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "upper"}, result.Upper.GetLabels())
+	require.Equal(t, data.Labels{"host": "a", AnomalyBandLabel: "lower"}, result.Lower.GetLabels())
+	require.Equal(t, "B", result.Score.GetName())
+	require.Equal(t, s.Len(), result.Score.Len())
+
+	// the first points don't have enough points before them
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, result.Score.GetValue(i))
+		assert.Nil(t, result.Upper.GetValue(i))
+		assert.Nil(t, result.Lower.GetValue(i))
+	}
+
+	// median of 1, 2, 3 is 2 and the median absolute deviation is 1
+	width := 3 * madScale
+	assert.InDelta(t, 2+width, *result.Upper.GetValue(3), 1e-9)
+	assert.InDelta(t, 2-width, *result.Lower.GetValue(3), 1e-9)
+	assert.InDelta(t, 2/width, *result.Score.GetValue(3), 1e-9)
+
+	// the spike is far above the median of 2, 3, 4
+	assert.InDelta(t, 97/width, *result.Score.GetValue(4), 1e-9)
+
+	// the spike does not move the median of 3, 4, 100
+	assert.InDelta(t, -1/width, *result.Score.GetValue(5), 1e-9)
+}
+
+func TestDetectAnomaliesMADConstantBaseline(t *testing.T) {
+	s := makeEvenSeries(time.Minute, 5, 5, 5, 5, 6, 4)
+	result, err := s.DetectAnomalies("B", AnomalyOptions{Algorithm: AnomalyMAD, Window: time.Hour, Sensitivity: 3})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.0, *result.Score.GetValue(3))
+	assert.Equal(t, math.Inf(1), *result.Score.GetValue(4))
+	assert.Equal(t, math.Inf(-1), *result.Score.GetValue(5))
+}
+
+func TestDetectAnomaliesNullsAndOrder(t *testing.T) {
+	s := makeSeries("A", nil,
+		tp{time.Unix(240, 0), nil},
+		tp{time.Unix(0, 0), float64Pointer(1)},
+		tp{time.Unix(120, 0), float64Pointer(3)},
+		tp{time.Unix(60, 0), float64Pointer(2)},
+		tp{time.Unix(180, 0), float64Pointer(math.NaN())},
+	)
+	result, err := s.DetectAnomalies("B", AnomalyOptions{Algorithm: AnomalyMAD, Window: time.Hour, Sensitivity: 3})
+	require.NoError(t, err)
+
+	for i := 0; i < result.Score.Len(); i++ {
+		assert.Equal(t, time.Unix(int64(i*60), 0), result.Score.GetTime(i))
+	}
+	// null and NaN values have bands but no score
+	for _, i := range []int{3, 4} {
+		assert.Nil(t, result.Score.GetValue(i))
+		assert.InDelta(t, 2+3*madScale, *result.Upper.GetValue(i), 1e-9)
+	}
+}
+
+func TestDetectAnomaliesHoltWinters(t *testing.T) {
+	t.Run("without season", func(t *testing.T) {
+		s := makeEvenSeries(time.Minute, 1, 2, 3, 4, 5, 20, 7)
+		opts := AnomalyOptions{Algorithm: AnomalyHoltWinters, Sensitivity: 3, Alpha: 0.5, Beta: 0.1, Gamma: 0.1}
+		result, err := s.DetectAnomalies("B", opts)
+		require.NoError(t, err)
+
+		assert.Nil(t, result.Score.GetValue(0))
+		assert.Nil(t, result.Score.GetValue(1))
+		// the linear trend is forecast exactly
+		for i := 2; i < 5; i++ {
+			assert.InDelta(t, 0, *result.Score.GetValue(i), 1e-9)
+		}
+		assert.Greater(t, *result.Score.GetValue(5), 1.0)
+	})
+
+	t.Run("with season", func(t *testing.T) {
+		values := make([]float64, 40)
+		for i := range values {
+			values[i] = 10 + 5*math.Sin(2*math.Pi*float64(i)/8) + float64(i%3)*0.1
+		}
+		values[30] = 40
+		s := makeEvenSeries(time.Minute, values...)
+		opts := AnomalyOptions{Algorithm: AnomalyHoltWinters, Season: 8 * time.Minute, Sensitivity: 3, Alpha: 0.5, Beta: 0.1, Gamma: 0.1}
+		result, err := s.DetectAnomalies("B", opts)
+		require.NoError(t, err)
+
+		// the first season is used to initialize the model
+		for i := 0; i < 8; i++ {
+			assert.Nil(t, result.Score.GetValue(i))
+		}
+		// the seasonal pattern is within the bands
+		for i := 8; i < 30; i++ {
+			assert.Less(t, math.Abs(*result.Score.GetValue(i)), 1.0, "point %d", i)
+		}
+		assert.Greater(t, *result.Score.GetValue(30), 1.0)
+		for i := 8; i < 40; i++ {
+			assert.LessOrEqual(t, *result.Lower.GetValue(i), *result.Upper.GetValue(i))
+		}
+	})
+
+	t.Run("not enough points for two seasons", func(t *testing.T) {
+		s := makeEvenSeries(time.Minute, 1, 2, 3, 4, 5)
+		opts := AnomalyOptions{Algorithm: AnomalyHoltWinters, Season: 4 * time.Minute, Sensitivity: 3, Alpha: 0.5, Beta: 0.1, Gamma: 0.1}
+		result, err := s.DetectAnomalies("B", opts)
+		require.NoError(t, err)
+		for i := 0; i < result.Score.Len(); i++ {
+			assert.Nil(t, result.Score.GetValue(i))
+		}
+	})
+}
+
+func TestDetectAnomaliesErrors(t *testing.T) {
+	s := makeEvenSeries(time.Minute, 1, 2, 3, 4, 5)
+	tests := []struct {
+		name string
+		opts AnomalyOptions
+	}{
+		{name: "unknown algorithm", opts: AnomalyOptions{Algorithm: "foo", Sensitivity: 3}},
+		{name: "missing window", opts: AnomalyOptions{Algorithm: AnomalyMAD, Sensitivity: 3}},
+		{name: "zero sensitivity", opts: AnomalyOptions{Algorithm: AnomalyMAD, Window: time.Hour}},
+		{name: "smoothing factor out of range", opts: AnomalyOptions{Algorithm: AnomalyHoltWinters, Sensitivity: 3, Alpha: 1.5, Beta: 0.1, Gamma: 0.1}},
+		{name: "season shorter than two points", opts: AnomalyOptions{Algorithm: AnomalyHoltWinters, Season: time.Minute, Sensitivity: 3, Alpha: 0.5, Beta: 0.1, Gamma: 0.1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.DetectAnomalies("B", tt.opts)
+			require.Error(t, err)
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package mathexp
 
 import (
+	"fmt"
 	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
 )
@@ -68,6 +72,43 @@ var builtins = map[string]parse.Func{
 		VariantReturn: true,
 		F:             floor,
 	},
+	"clamp": {
+		Args:          []parse.ReturnType{parse.TypeVariantSet, parse.TypeScalar, parse.TypeScalar},
+		VariantReturn: true,
+		F:             clamp,
+	},
+	"moving_avg": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeScalar},
+		Return: parse.TypeSeriesSet,
+		F:      movingAvg,
+		Check:  checkWindowSize,
+	},
+	"rate": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      rate,
+	},
+	"delta": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      delta,
+	},
+	"shift": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet, parse.TypeString},
+		Return: parse.TypeSeriesSet,
+		F:      shift,
+		Check:  checkDuration,
+	},
+	"cumsum": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      cumsum,
+	},
+	"zscore": {
+		Args:   []parse.ReturnType{parse.TypeSeriesSet},
+		Return: parse.TypeSeriesSet,
+		F:      zscore,
+	},
 }
 
 // abs returns the absolute value for each result in NumberSet, SeriesSet, or Scalar
@@ -307,3 +348,235 @@ func floor(e *State, varSet Results) (Results, error) {
 	}
 	return newRes, nil
 }
+
+// clamp limits the value for each result in NumberSet, SeriesSet, or Scalar to the range [min, max].
+func clamp(e *State, varSet Results, minArg Results, maxArg Results) (Results, error) {
+	newRes := Results{}
+	lower, err := scalarArg("clamp", minArg)
+	if err != nil {
+		return newRes, err
+	}
+	upper, err := scalarArg("clamp", maxArg)
+	if err != nil {
+		return newRes, err
+	}
+	if lower > upper {
+		return newRes, fmt.Errorf("clamp: min %v is greater than max %v", lower, upper)
+	}
+	for _, res := range varSet.Values {
+		newVal, err := perFloat(e, res, func(f float64) float64 {
+			return math.Max(lower, math.Min(upper, f))
+		})
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, newVal)
+	}
+	return newRes, nil
+}
+
+// movingAvg returns for each point of each series the average of the non-null values of the last n points, including the point itself.
+func movingAvg(e *State, varSet Results, nArg Results) (Results, error) {
+	f, err := scalarArg("moving_avg", nArg)
+	if err != nil {
+		return Results{}, err
+	}
+	if f < 1 || f != math.Trunc(f) {
+		return Results{}, fmt.Errorf("moving_avg: window size must be a positive integer, got %v", f)
+	}
+	n := int(f)
+	return perSeries(e, "moving_avg", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			var sum float64
+			count := 0
+			for j := max(0, i-n+1); j <= i; j++ {
+				if v := s.GetValue(j); v != nil {
+					sum += *v
+					count++
+				}
+			}
+			var avg *float64
+			if count > 0 {
+				a := sum / float64(count)
+				avg = &a
+			}
+			newSeries.SetPoint(i, s.GetTime(i), avg)
+		}
+		return newSeries
+	})
+}
+
+// rate returns for each point of each series the per-second increase since the previous point.
+// A value lower than the previous one is considered as a counter reset. The first point of the series is dropped.
+func rate(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "rate", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(prevT, t time.Time, prev, cur float64) *float64 {
+			seconds := t.Sub(prevT).Seconds()
+			if seconds <= 0 {
+				return nil
+			}
+			inc := cur - prev
+			if cur < prev {
+				inc = cur
+			}
+			r := inc / seconds
+			return &r
+		})
+	})
+}
+
+// delta returns for each point of each series the difference to the previous point. The first point of the series is dropped.
+func delta(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "delta", varSet, func(s Series) Series {
+		return perPointPair(e, s, func(_, _ time.Time, prev, cur float64) *float64 {
+			d := cur - prev
+			return &d
+		})
+	})
+}
+
+// shift moves the time of each point of each series forward by the given duration, so that the
+// result can be compared with the original series, e.g. $A - shift($A, "1h").
+func shift(e *State, varSet Results, rawDuration string) (Results, error) {
+	d, err := gtime.ParseDuration(rawDuration)
+	if err != nil {
+		return Results{}, fmt.Errorf("shift: failed to parse duration %q: %w", rawDuration, err)
+	}
+	return perSeries(e, "shift", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			newSeries.SetPoint(i, t.Add(d), f)
+		}
+		return newSeries
+	})
+}
+
+// cumsum returns for each point of each series the sum of all non-null values up to and including the point.
+// Null points remain null.
+func cumsum(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "cumsum", varSet, func(s Series) Series {
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		var sum float64
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			sum += *f
+			nF := sum
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// zscore returns for each point of each series the number of standard deviations the value is away from the mean of the series.
+// The mean and the standard deviation are calculated from the non-null values. Null points remain null.
+func zscore(e *State, varSet Results) (Results, error) {
+	return perSeries(e, "zscore", varSet, func(s Series) Series {
+		var sum float64
+		count := 0
+		for i := 0; i < s.Len(); i++ {
+			if f := s.GetValue(i); f != nil {
+				sum += *f
+				count++
+			}
+		}
+		mean := sum / float64(count)
+		var sqSum float64
+		for i := 0; i < s.Len(); i++ {
+			if f := s.GetValue(i); f != nil {
+				sqSum += (*f - mean) * (*f - mean)
+			}
+		}
+		stdDev := math.Sqrt(sqSum / float64(count))
+
+		newSeries := NewSeries(e.RefID, s.GetLabels(), s.Len())
+		for i := 0; i < s.Len(); i++ {
+			t, f := s.GetPoint(i)
+			if f == nil {
+				newSeries.SetPoint(i, t, nil)
+				continue
+			}
+			nF := math.NaN()
+			if stdDev != 0 {
+				nF = (*f - mean) / stdDev
+			}
+			newSeries.SetPoint(i, t, &nF)
+		}
+		return newSeries
+	})
+}
+
+// perSeries applies seriesF to each Series in varSet. NoData is passed through,
+// any other type results in an error because window functions need the time of the points.
+func perSeries(e *State, name string, varSet Results, seriesF func(s Series) Series) (Results, error) {
+	newRes := Results{}
+	for _, res := range varSet.Values {
+		switch v := res.(type) {
+		case Series:
+			newRes.Values = append(newRes.Values, seriesF(v))
+		case NoData:
+			newRes.Values = append(newRes.Values, v.New())
+		default:
+			return newRes, fmt.Errorf("%s: can only be applied to series, got type %v", name, res.Type())
+		}
+	}
+	return newRes, nil
+}
+
+// perPointPair creates a series with a point for each point of s except the first one. The value is calculated
+// by pairF from the point and its predecessor. If either of the values is null, the new point is null.
+func perPointPair(e *State, s Series, pairF func(prevT, t time.Time, prev, cur float64) *float64) Series {
+	newSeries := NewSeries(e.RefID, s.GetLabels(), 0)
+	for i := 1; i < s.Len(); i++ {
+		prevT, prev := s.GetPoint(i - 1)
+		t, cur := s.GetPoint(i)
+		if prev == nil || cur == nil {
+			newSeries.AppendPoint(t, nil)
+			continue
+		}
+		newSeries.AppendPoint(t, pairF(prevT, t, *prev, *cur))
+	}
+	return newSeries
+}
+
+// scalarArg returns the value of a function argument that must be a single non-null scalar.
+func scalarArg(name string, arg Results) (float64, error) {
+	if len(arg.Values) != 1 {
+		return 0, fmt.Errorf("%s: expected a single scalar argument, got %d values", name, len(arg.Values))
+	}
+	s, ok := arg.Values[0].(Scalar)
+	if !ok {
+		return 0, fmt.Errorf("%s: expected a scalar argument, got type %v", name, arg.Values[0].Type())
+	}
+	f := s.GetFloat64Value()
+	if f == nil {
+		return 0, fmt.Errorf("%s: scalar argument must not be null", name)
+	}
+	return *f, nil
+}
+
+// checkWindowSize verifies at parse time that a constant window size is a positive integer.
+func checkWindowSize(_ *parse.Tree, f *parse.FuncNode) error {
+	n, ok := f.Args[1].(*parse.ScalarNode)
+	if !ok {
+		return nil // not a constant, checked on execution
+	}
+	if !n.IsUint || n.Uint64 == 0 {
+		return fmt.Errorf("parse: window size of %s must be a positive integer, got %s", f.Name, n.Text)
+	}
+	return nil
+}
+
+// checkDuration verifies at parse time that the duration argument can be parsed.
+func checkDuration(_ *parse.Tree, f *parse.FuncNode) error {
+	s := f.Args[1].(*parse.StringNode)
+	if _, err := gtime.ParseDuration(s.Text); err != nil {
+		return fmt.Errorf("parse: invalid duration %s for %s: %w", s.Quoted, f.Name, err)
+	}
+	return nil
+}
@@ -5,8 +5,11 @@ import (
 	"testing"
 	"time"
 
-	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/google/go-cmp/cmp"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestAbsFunc(t *testing.T) {
@@ -140,3 +143,226 @@ func TestIsNumberFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestWindowFuncs(t *testing.T) {
+	counter := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("", nil,
+				tp{time.Unix(0, 0), float64Pointer(10)},
+				tp{time.Unix(10, 0), float64Pointer(20)},
+				tp{time.Unix(20, 0), nil},
+				tp{time.Unix(30, 0), float64Pointer(40)},
+				tp{time.Unix(40, 0), float64Pointer(5)}),
+		),
+	}
+
+	var tests = []struct {
+		name    string
+		expr    string
+		vars    Vars
+		results Results
+	}{
+		{
+			name: "moving_avg ignores null values in the window",
+			expr: "moving_avg($A, 2)",
+			vars: counter,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(15)},
+					tp{time.Unix(20, 0), float64Pointer(20)},
+					tp{time.Unix(30, 0), float64Pointer(40)},
+					tp{time.Unix(40, 0), float64Pointer(22.5)}),
+			),
+		},
+		{
+			name: "delta drops the first point",
+			expr: "delta($A)",
+			vars: counter,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(10)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(-35)}),
+			),
+		},
+		{
+			name: "rate handles counter resets",
+			expr: "rate($A)",
+			vars: counter,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(10, 0), float64Pointer(1)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), nil},
+					tp{time.Unix(40, 0), float64Pointer(0.5)}),
+			),
+		},
+		{
+			name: "cumsum keeps null points",
+			expr: "cumsum($A)",
+			vars: counter,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(10)},
+					tp{time.Unix(10, 0), float64Pointer(30)},
+					tp{time.Unix(20, 0), nil},
+					tp{time.Unix(30, 0), float64Pointer(70)},
+					tp{time.Unix(40, 0), float64Pointer(75)}),
+			),
+		},
+		{
+			name: "shift moves points forward in time",
+			expr: `shift($A, "1m")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}),
+				),
+			},
+			results: resultValuesNoErr(
+				makeSeries("", nil, tp{time.Unix(60, 0), float64Pointer(1)}),
+			),
+		},
+		{
+			name: "current value compared with the value an hour ago",
+			expr: `$A - shift($A, "1h")`,
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(3600, 0), float64Pointer(5)}),
+				),
+			},
+			results: resultValuesNoErr(
+				makeSeries("", nil, tp{time.Unix(3600, 0), float64Pointer(4)}),
+			),
+		},
+		{
+			name: "zscore",
+			expr: "zscore($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("", nil,
+						tp{time.Unix(0, 0), float64Pointer(1)},
+						tp{time.Unix(10, 0), nil},
+						tp{time.Unix(20, 0), float64Pointer(3)}),
+				),
+			},
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(-1)},
+					tp{time.Unix(10, 0), nil},
+					tp{time.Unix(20, 0), float64Pointer(1)}),
+			),
+		},
+		{
+			name: "clamp on series",
+			expr: "clamp($A, 15, 30)",
+			vars: counter,
+			results: resultValuesNoErr(
+				makeSeries("", nil,
+					tp{time.Unix(0, 0), float64Pointer(15)},
+					tp{time.Unix(10, 0), float64Pointer(20)},
+					tp{time.Unix(20, 0), float64Pointer(math.NaN())},
+					tp{time.Unix(30, 0), float64Pointer(30)},
+					tp{time.Unix(40, 0), float64Pointer(15)}),
+			),
+		},
+		{
+			name:    "clamp on scalar",
+			expr:    "clamp(-5, -1, 1)",
+			vars:    Vars{},
+			results: resultValuesNoErr(NewScalar("", float64Pointer(-1))),
+		},
+		{
+			name: "window function on no data",
+			expr: "moving_avg($A, 3)",
+			vars: Vars{
+				"A": resultValuesNoErr(NewNoData()),
+			},
+			results: resultValuesNoErr(NewNoData()),
+		},
+	}
+	opt := cmp.Comparer(func(x, y float64) bool {
+		return (math.IsNaN(x) && math.IsNaN(y)) || x == y
+	})
+	options := append([]cmp.Option{opt}, data.FrameTestCompareOptions()...)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			require.NoError(t, err)
+			res, err := e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+			if diff := cmp.Diff(tt.results, res, options...); diff != "" {
+				t.Errorf("Result mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWindowFuncsErrors(t *testing.T) {
+	var tests = []struct {
+		name      string
+		expr      string
+		vars      Vars
+		newErrIs  require.ErrorAssertionFunc
+		execErrIs require.ErrorAssertionFunc
+	}{
+		{
+			name:     "moving_avg with a non-integer window should fail to parse",
+			expr:     "moving_avg($A, 1.5)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "moving_avg with a series as window should fail to parse",
+			expr:     "moving_avg($A, $B)",
+			newErrIs: require.Error,
+		},
+		{
+			name:     "shift with an invalid duration should fail to parse",
+			expr:     `shift($A, "soon")`,
+			newErrIs: require.Error,
+		},
+		{
+			name:     "rate on a scalar should fail to parse",
+			expr:     "rate(1)",
+			newErrIs: require.Error,
+		},
+		{
+			name:      "moving_avg with a zero window from an expression should fail",
+			expr:      "moving_avg($A, 1 - 1)",
+			vars:      aSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name: "rate on numbers should fail",
+			expr: "rate($A)",
+			vars: Vars{
+				"A": resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+			},
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+		{
+			name:      "clamp with min greater than max should fail",
+			expr:      "clamp($A, 2, 1)",
+			vars:      aSeries,
+			newErrIs:  require.NoError,
+			execErrIs: require.Error,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(tt.expr)
+			tt.newErrIs(t, err)
+			if err != nil {
+				return
+			}
+			_, err = e.Execute("", tt.vars, tracing.InitializeTracerForTest())
+			tt.execErrIs(t, err)
+		})
+	}
+}
@@ -15,18 +15,41 @@ type ReducerFunc = func(fv *Float64Field) *float64
 type ReducerID string
 
 const (
-	ReducerSum    ReducerID = "sum"
-	ReducerMean   ReducerID = "mean"
-	ReducerMin    ReducerID = "min"
-	ReducerMax    ReducerID = "max"
-	ReducerCount  ReducerID = "count"
-	ReducerLast   ReducerID = "last"
-	ReducerMedian ReducerID = "median"
+	ReducerSum        ReducerID = "sum"
+	ReducerMean       ReducerID = "mean"
+	ReducerMin        ReducerID = "min"
+	ReducerMax        ReducerID = "max"
+	ReducerCount      ReducerID = "count"
+	ReducerLast       ReducerID = "last"
+	ReducerMedian     ReducerID = "median"
+	ReducerFirst      ReducerID = "first"
+	ReducerStdDev     ReducerID = "stddev"
+	ReducerDelta      ReducerID = "delta"
+	ReducerIncrease   ReducerID = "increase"
+	ReducerRate       ReducerID = "rate"
+	ReducerPercentile ReducerID = "percentile"
 )
 
+// ReducerArgs holds the arguments of parameterised reducers.
+type ReducerArgs struct {
+	// Percentile is the percentile in the range [0, 100] calculated by ReducerPercentile.
+	Percentile *float64
+}
+
+// SeriesReducerFunc reduces a whole series. Unlike ReducerFunc it has access to the time of each point.
+type SeriesReducerFunc = func(s Series) *float64
+
 // GetSupportedReduceFuncs returns collection of supported function names
 func GetSupportedReduceFuncs() []ReducerID {
-	return []ReducerID{ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian}
+	return []ReducerID{
+		ReducerSum, ReducerMean, ReducerMin, ReducerMax, ReducerCount, ReducerLast, ReducerMedian,
+		ReducerFirst, ReducerStdDev, ReducerDelta, ReducerIncrease, ReducerRate, ReducerPercentile,
+	}
+}
+
+// IsParameterisedReducer returns true if the reducer cannot be used without ReducerArgs.
+func IsParameterisedReducer(rFunc ReducerID) bool {
+	return rFunc == ReducerPercentile
 }
 
 func Sum(fv *Float64Field) *float64 {
@@ -126,6 +149,111 @@ func Median(fv *Float64Field) *float64 {
 	}
 }
 
+func First(fv *Float64Field) *float64 {
+	var f float64
+	if fv.Len() == 0 {
+		f = math.NaN()
+		return &f
+	}
+	return fv.GetValue(0)
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return mean
+	}
+	var sum float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		sum += d * d
+	}
+	f := math.Sqrt(sum / float64(fv.Len()))
+	return &f
+}
+
+// Delta returns the difference between the last and the first value.
+func Delta(fv *Float64Field) *float64 {
+	values, ok := fieldValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := values[len(values)-1] - values[0]
+	return &f
+}
+
+// Increase returns the increase of a monotonic counter. A value lower than the previous one
+// is considered as a counter reset, and the value itself is counted as the increase since the reset.
+func Increase(fv *Float64Field) *float64 {
+	values, ok := fieldValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	var f float64
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			f += values[i]
+			continue
+		}
+		f += values[i] - values[i-1]
+	}
+	return &f
+}
+
+// Percentile returns the p-th percentile (0-100) of the values using linear interpolation between the closest ranks.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	values, ok := fieldValues(fv)
+	if !ok || len(values) == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	sort.Float64s(values)
+	rank := p / 100 * float64(len(values)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := values[lower] + (values[upper]-values[lower])*(rank-float64(lower))
+	return &f
+}
+
+// Rate returns the per-second increase of the series, calculated over the time between the first and the last point.
+func Rate(s Series) *float64 {
+	if s.Len() < 2 {
+		nan := math.NaN()
+		return &nan
+	}
+	seconds := s.GetTime(s.Len() - 1).Sub(s.GetTime(0)).Seconds()
+	increase := Increase(seriesValueField(s))
+	if math.IsNaN(*increase) || seconds <= 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *increase / seconds
+	return &f
+}
+
+// fieldValues returns the values of the field. It returns false if any of the values is null or NaN.
+func fieldValues(fv *Float64Field) ([]float64, bool) {
+	values := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			return nil, false
+		}
+		values = append(values, *v)
+	}
+	return values, true
+}
+
+func seriesValueField(s Series) *Float64Field {
+	ff := Float64Field(*s.Frame.Fields[seriesTypeValIdx])
+	return &ff
+}
+
+// GetReduceFunc returns the function for reducers that only need the values of a series.
+// Use GetSeriesReduceFunc for reducers that need arguments or the time of the points.
 func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 	switch rFunc {
 	case ReducerSum:
@@ -142,15 +270,57 @@ func GetReduceFunc(rFunc ReducerID) (ReducerFunc, error) {
 		return Last, nil
 	case ReducerMedian:
 		return Median, nil
+	case ReducerFirst:
+		return First, nil
+	case ReducerStdDev:
+		return StdDev, nil
+	case ReducerDelta:
+		return Delta, nil
+	case ReducerIncrease:
+		return Increase, nil
+	case ReducerRate, ReducerPercentile:
+		return nil, fmt.Errorf("reduction %v cannot be applied to values only", rFunc)
 	default:
 		return nil, fmt.Errorf("reduction %v not implemented", rFunc)
 	}
 }
 
+// GetSeriesReduceFunc returns the function for any supported reducer.
+// It returns an error if the reducer is parameterised and args are missing or invalid.
+func GetSeriesReduceFunc(rFunc ReducerID, args ReducerArgs) (SeriesReducerFunc, error) {
+	switch rFunc {
+	case ReducerRate:
+		return Rate, nil
+	case ReducerPercentile:
+		if args.Percentile == nil {
+			return nil, fmt.Errorf("reduction %v requires a percentile argument", rFunc)
+		}
+		p := *args.Percentile
+		if math.IsNaN(p) || p < 0 || p > 100 {
+			return nil, fmt.Errorf("reduction %v requires a percentile in the range [0, 100], got %v", rFunc, p)
+		}
+		return func(s Series) *float64 {
+			return Percentile(seriesValueField(s), p)
+		}, nil
+	}
+	reduceFunc, err := GetReduceFunc(rFunc)
+	if err != nil {
+		return nil, err
+	}
+	return func(s Series) *float64 {
+		return reduceFunc(seriesValueField(s))
+	}, nil
+}
+
 // Reduce turns the Series into a Number based on the given reduction function
 // if ReduceMapper is defined it applies it to the provided series and performs reduction of the resulting series.
 // Otherwise, the reduction operation is done against the original series.
 func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Number, error) {
+	return s.ReduceWithArgs(refID, rFunc, ReducerArgs{}, mapper)
+}
+
+// ReduceWithArgs is like Reduce but also accepts the arguments of parameterised reducers such as percentile.
+func (s Series) ReduceWithArgs(refID string, rFunc ReducerID, args ReducerArgs, mapper ReduceMapper) (Number, error) {
 	var l data.Labels
 	if s.GetLabels() != nil {
 		l = s.GetLabels().Copy()
@@ -161,13 +331,11 @@ func (s Series) Reduce(refID string, rFunc ReducerID, mapper ReduceMapper) (Numb
 	if mapper != nil {
 		series = mapSeries(s, mapper)
 	}
-	fVec := series.Frame.Fields[seriesTypeValIdx]
-	floatField := Float64Field(*fVec)
-	reduceFunc, err := GetReduceFunc(rFunc)
+	reduceFunc, err := GetSeriesReduceFunc(rFunc, args)
 	if err != nil {
 		return number, fmt.Errorf("invalid expression '%s': %w", refID, err)
 	}
-	f = reduceFunc(&floatField)
+	f = reduceFunc(series)
 	if f != nil && mapper != nil {
 		f = mapper.MapOutput(f)
 	}
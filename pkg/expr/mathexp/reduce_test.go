@@ -28,6 +28,17 @@ var seriesEmpty = Vars{
 	),
 }
 
+var counterSeries = Vars{
+	"A": resultValuesNoErr(
+		makeSeries("temp", nil,
+			tp{time.Unix(0, 0), float64Pointer(10)},
+			tp{time.Unix(10, 0), float64Pointer(15)},
+			tp{time.Unix(20, 0), float64Pointer(3)},
+			tp{time.Unix(30, 0), float64Pointer(7)},
+		),
+	),
+}
+
 func TestSeriesReduce(t *testing.T) {
 	var tests = []struct {
 		name        string
@@ -274,6 +285,117 @@ func TestSeriesReduce(t *testing.T) {
 			resultsIs:   require.Equal,
 			results:     resultValuesNoErr(makeNumber("", nil, nil)),
 		},
+		{
+			name:        "first series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(10))),
+		},
+		{
+			name:        "first empty series",
+			red:         "first",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "stddev series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(math.Sqrt(19.1875)))),
+		},
+		{
+			name:        "stddev series with a nil value",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "stddev empty series",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "delta series",
+			red:         "delta",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(-3))),
+		},
+		{
+			name:        "delta empty series",
+			red:         "delta",
+			varToReduce: "A",
+			vars:        seriesEmpty,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "increase series with counter reset",
+			red:         "increase",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(12))),
+		},
+		{
+			name:        "increase series with a nil value",
+			red:         "increase",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "rate series with counter reset",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        counterSeries,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0.4))),
+		},
+		{
+			name:        "rate series with a single point",
+			red:         "rate",
+			varToReduce: "A",
+			vars: Vars{
+				"A": resultValuesNoErr(
+					makeSeries("temp", nil, tp{time.Unix(5, 0), float64Pointer(2)}),
+				),
+			},
+			errIs:     require.NoError,
+			resultsIs: require.Equal,
+			results:   resultValuesNoErr(makeNumber("", nil, NaN)),
+		},
+		{
+			name:        "percentile without argument will error",
+			red:         "percentile",
+			varToReduce: "A",
+			vars:        aSeries,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 	}
 
 	for _, tt := range tests {
@@ -393,6 +515,26 @@ func TestSeriesReduceDropNN(t *testing.T) {
 			varToReduce: "A",
 			vars:        seriesWithNil,
 			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(1))),
+		}, {
+			name:        "DropNN: stddev series that becomes empty after filtering non-number",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesNonNumbers,
+			results:     resultValuesNoErr(makeNumber("", nil, nil)),
+		},
+		{
+			name:        "DropNN: delta series with a nil value and real value",
+			red:         "delta",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     resultValuesNoErr(makeNumber("", nil, float64Pointer(0))),
+		},
+		{
+			name:        "DropNN: rate series that becomes a single point after filtering non-number",
+			red:         "rate",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			results:     resultValuesNoErr(makeNumber("", nil, nil)),
 		},
 	}
 
@@ -543,3 +685,75 @@ func sortedFloat64(f []float64) []float64 {
 	sort.Float64s(f)
 	return f
 }
+
+func TestSeriesReducePercentile(t *testing.T) {
+	vars := Vars{
+		"A": resultValuesNoErr(
+			makeSeries("temp", nil,
+				tp{time.Unix(5, 0), float64Pointer(4)},
+				tp{time.Unix(10, 0), float64Pointer(1)},
+				tp{time.Unix(15, 0), float64Pointer(3)},
+				tp{time.Unix(20, 0), float64Pointer(2)},
+				tp{time.Unix(25, 0), nil},
+			),
+		),
+	}
+	series := vars["A"].Values[0].Value().(*Series)
+
+	var tests = []struct {
+		name       string
+		percentile float64
+		mapper     ReduceMapper
+		expected   *float64
+	}{
+		{
+			name:       "null values make the result NaN",
+			percentile: 50,
+			expected:   NaN,
+		},
+		{
+			name:       "dropNN: 0th percentile is the minimum",
+			percentile: 0,
+			mapper:     DropNonNumber{},
+			expected:   float64Pointer(1),
+		},
+		{
+			name:       "dropNN: 100th percentile is the maximum",
+			percentile: 100,
+			mapper:     DropNonNumber{},
+			expected:   float64Pointer(4),
+		},
+		{
+			name:       "dropNN: percentile is interpolated between closest ranks",
+			percentile: 95,
+			mapper:     DropNonNumber{},
+			expected:   float64Pointer(3.85),
+		},
+		{
+			name:       "replaceNN: null values are replaced before calculation",
+			percentile: 50,
+			mapper:     ReplaceNonNumberWithValue{Value: 10},
+			expected:   float64Pointer(3),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.percentile
+			n, err := series.ReduceWithArgs("", ReducerPercentile, ReducerArgs{Percentile: &p}, tt.mapper)
+			require.NoError(t, err)
+			actual := n.GetFloat64Value()
+			if math.IsNaN(*tt.expected) {
+				require.True(t, math.IsNaN(*actual))
+				return
+			}
+			require.InDelta(t, *tt.expected, *actual, 1e-9)
+		})
+	}
+
+	t.Run("should error if percentile is out of range", func(t *testing.T) {
+		p := 101.0
+		_, err := series.ReduceWithArgs("", ReducerPercentile, ReducerArgs{Percentile: &p}, nil)
+		require.Error(t, err)
+	})
+}
@@ -20,10 +20,57 @@ const (
 
 	// Do not fill values (nill)
 	UpsamplerFillNA Upsampler = "fillna"
+
+	// Interpolate linearly between the last seen and the next value
+	UpsamplerLinear Upsampler = "linear"
+
+	// Use the value closest in time, either the last seen or the next one
+	UpsamplerNearest Upsampler = "nearest"
 )
 
+// ResampleAlignment makes the resampled points fall on multiples of the interval, instead of on the start of the time range.
+type ResampleAlignment struct {
+	// Offset shifts the points relative to the multiples of the interval.
+	Offset time.Duration
+	// Location is the time zone in which the multiples of the interval are calculated, UTC if nil.
+	// The UTC offset of the zone at the start of the time range is used for the whole range.
+	Location *time.Location
+}
+
 // Resample turns the Series into a Number based on the given reduction function
 func (s Series) Resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
+	return s.resample(refID, interval, downsampler, upsampler, from, to)
+}
+
+// ResampleAligned is like Resample but the first point is placed on the first boundary described by alignment
+// that is not before from, for example at midnight in the given time zone if the interval is a day.
+func (s Series) ResampleAligned(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time, alignment ResampleAlignment) (Series, error) {
+	if interval <= 0 {
+		return s, fmt.Errorf("the resample interval must be positive")
+	}
+	return s.resample(refID, interval, downsampler, upsampler, alignedStart(from, interval, alignment), to)
+}
+
+// alignedStart returns the first time that is not before from and is a multiple of interval
+// in the alignment's time zone, shifted by the alignment's offset.
+func alignedStart(from time.Time, interval time.Duration, alignment ResampleAlignment) time.Time {
+	loc := alignment.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	_, zoneOffset := from.In(loc).Zone()
+	sinceEpoch := time.Duration(from.UnixNano()) + time.Duration(zoneOffset)*time.Second - alignment.Offset
+	rem := sinceEpoch % interval
+	if rem < 0 {
+		rem += interval
+	}
+	if rem == 0 {
+		return from
+	}
+	return from.Add(interval - rem)
+}
+
+func (s Series) resample(refID string, interval time.Duration, downsampler ReducerID, upsampler Upsampler, from, to time.Time) (Series, error) {
 	newSeriesLength := int(float64(to.Sub(from).Nanoseconds()) / float64(interval.Nanoseconds()))
 	if newSeriesLength <= 0 {
 		return s, fmt.Errorf("the series cannot be sampled further; the time range is shorter than the interval")
@@ -31,6 +78,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	resampled := NewSeries(refID, s.GetLabels(), newSeriesLength+1)
 	bookmark := 0
 	var lastSeen *float64
+	var lastSeenTime time.Time
 	idx := 0
 	t := from
 	for !t.After(to) && idx <= newSeriesLength {
@@ -47,6 +95,7 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 			bookmark++
 			sIdx++
 			lastSeen = v
+			lastSeenTime = st
 			vals = append(vals, v)
 		}
 		var value *float64
@@ -66,6 +115,24 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 				}
 			case UpsamplerFillNA:
 				value = nil
+			case UpsamplerLinear:
+				if bookmark > 0 && sIdx < s.Len() {
+					nextTime, next := s.GetPoint(sIdx)
+					value = interpolate(t, lastSeenTime, lastSeen, nextTime, next)
+				}
+			case UpsamplerNearest:
+				switch {
+				case sIdx == s.Len(): // no vals left
+					value = lastSeen
+				case bookmark == 0: // no vals seen yet
+					_, value = s.GetPoint(sIdx)
+				default:
+					nextTime, next := s.GetPoint(sIdx)
+					value = lastSeen
+					if nextTime.Sub(t) < t.Sub(lastSeenTime) {
+						value = next
+					}
+				}
 			default:
 				return s, fmt.Errorf("upsampling %v not implemented", upsampler)
 			}
@@ -97,3 +164,17 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler Reduc
 	}
 	return resampled, nil
 }
+
+// interpolate returns the value at t on the line between the points (prevTime, prev) and (nextTime, next).
+// It returns nil if either of the values is nil.
+func interpolate(t time.Time, prevTime time.Time, prev *float64, nextTime time.Time, next *float64) *float64 {
+	if prev == nil || next == nil {
+		return nil
+	}
+	span := nextTime.Sub(prevTime)
+	if span <= 0 {
+		return prev
+	}
+	f := *prev + (*next-*prev)*float64(t.Sub(prevTime))/float64(span)
+	return &f
+}
@@ -277,6 +277,62 @@ func TestResampleSeries(t *testing.T) {
 				time.Unix(9, 0), float64Pointer(0),
 			}),
 		},
+		{
+			name:        "resample series: upsampling (mean / linear)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "linear",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(8),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), nil,
+			}, tp{
+				time.Unix(2, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(8),
+			}, tp{
+				time.Unix(10, 0), nil,
+			}),
+		},
+		{
+			name:        "resample series: upsampling (mean / nearest)",
+			interval:    time.Second * 2,
+			downsampler: "mean",
+			upsampler:   "nearest",
+			timeRange: backend.TimeRange{
+				From: time.Unix(0, 0),
+				To:   time.Unix(10, 0),
+			},
+			seriesToResample: makeSeries("", nil, tp{
+				time.Unix(1, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(7, 0), float64Pointer(7),
+			}),
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(2, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(4, 0), float64Pointer(1),
+			}, tp{
+				time.Unix(6, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(8, 0), float64Pointer(7),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(7),
+			}),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -290,3 +346,54 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestResampleSeriesAligned(t *testing.T) {
+	series := makeSeries("", nil, tp{
+		time.Unix(9, 0), float64Pointer(1),
+	}, tp{
+		time.Unix(12, 0), float64Pointer(2),
+	}, tp{
+		time.Unix(14, 0), float64Pointer(3),
+	})
+
+	t.Run("should place points on multiples of the interval", func(t *testing.T) {
+		resampled, err := series.ResampleAligned("", 5*time.Second, "max", "pad", time.Unix(7, 0), time.Unix(20, 0), ResampleAlignment{})
+		require.NoError(t, err)
+		assert.Equal(t, makeSeries("", nil, tp{
+			time.Unix(10, 0), float64Pointer(1),
+		}, tp{
+			time.Unix(15, 0), float64Pointer(3),
+		}, tp{
+			time.Unix(20, 0), float64Pointer(3),
+		}), resampled)
+	})
+
+	t.Run("should shift points by the offset", func(t *testing.T) {
+		resampled, err := series.ResampleAligned("", 5*time.Second, "max", "pad", time.Unix(7, 0), time.Unix(20, 0), ResampleAlignment{Offset: time.Second})
+		require.NoError(t, err)
+		assert.Equal(t, makeSeries("", nil, tp{
+			time.Unix(11, 0), float64Pointer(1),
+		}, tp{
+			time.Unix(16, 0), float64Pointer(3),
+		}), resampled)
+	})
+}
+
+func TestAlignedStart(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should return from if already aligned", func(t *testing.T) {
+		require.Equal(t, from, alignedStart(from, time.Hour, ResampleAlignment{}))
+	})
+
+	t.Run("should align to midnight in the time zone", func(t *testing.T) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		start := alignedStart(from, 24*time.Hour, ResampleAlignment{Location: loc})
+		require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, loc).UTC(), start.UTC())
+	})
+
+	t.Run("should align with a negative offset", func(t *testing.T) {
+		start := alignedStart(from.Add(time.Minute), time.Hour, ResampleAlignment{Offset: -15 * time.Minute})
+		require.Equal(t, from.Add(45*time.Minute), start)
+	})
+}
@@ -12,6 +12,10 @@ const (
 type metrics struct {
 	dsRequests *prometheus.CounterVec
 
+	// dsQueryCacheRequests counts the datasource queries that were executed with a QueryCache by whether
+	// they were served from it
+	dsQueryCacheRequests *prometheus.CounterVec
+
 	// older metric
 	expressionsQuerySummary *prometheus.SummaryVec
 }
@@ -25,6 +29,13 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 			Help:      "Number of datasource queries made via server side expression requests",
 		}, []string{"error", "dataplane", "datasource_type"}),
 
+		dsQueryCacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "ds_query_cache_requests_total",
+			Help:      "Number of datasource queries of server side expression requests that were looked up in a query cache, by whether the response was shared with an identical query",
+		}, []string{"result", "datasource_type"}),
+
 		// older (No Namespace or Subsystem)
 		expressionsQuerySummary: prometheus.NewSummaryVec(
 			prometheus.SummaryOpts{
@@ -39,6 +50,7 @@ func newMetrics(reg prometheus.Registerer) *metrics {
 	if reg != nil {
 		reg.MustRegister(
 			m.dsRequests,
+			m.dsQueryCacheRequests,
 			m.expressionsQuerySummary,
 		)
 	}
@@ -158,6 +158,8 @@ func buildCMDNode(rn *rawNode, toggles featuremgmt.FeatureToggles) (*CMDNode, er
 		node.Command, err = UnmarshalThresholdCommand(rn, toggles)
 	case TypeSQL:
 		node.Command, err = UnmarshalSQLCommand(rn)
+	case TypeAnomaly:
+		node.Command, err = UnmarshalAnomalyCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in expression '%v' not implemented", commandType, rn.RefID)
 	}
@@ -248,15 +250,32 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 }
 
 // executeDSNodesGrouped groups datasource node queries by the datasource instance, and then sends them
-// in a single request with one or more queries to the datasource.
+// in a single request with one or more queries to the datasource. If ctx has a QueryCache, queries that
+// are already executed by another pipeline are not sent again.
 func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars, s *Service, nodes []*DSNode) {
 	type dsKey struct {
 		uid   string // in theory I think this all I need for the key, but rather be safe
 		id    int64
 		orgID int64
 	}
+	type sharedNode struct {
+		node  *DSNode
+		entry *queryCacheEntry
+	}
+	// queries that are executed by another pipeline are not sent, their responses are awaited
+	// after all queries of this pipeline are executed
+	var shared []sharedNode
+	owned := make(map[*DSNode]*queryCacheEntry)
 	byDS := make(map[dsKey][]*DSNode)
 	for _, node := range nodes {
+		entry, owner := node.acquireCacheEntry(ctx, now, s)
+		if entry != nil && !owner {
+			shared = append(shared, sharedNode{node: node, entry: entry})
+			continue
+		}
+		if entry != nil {
+			owned[node] = entry
+		}
 		k := dsKey{id: node.datasource.ID, uid: node.datasource.UID, orgID: node.orgID}
 		byDS[k] = append(byDS[k], node)
 	}
@@ -326,6 +345,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 
 			for _, dn := range nodeGroup {
 				dataFrames, err := getResponseFrame(logger, resp, dn.refID)
+				owned[dn].complete(dataFrames, err)
 				if err != nil {
 					vars[dn.refID] = mathexp.Results{Error: MakeQueryError(dn.refID, dn.datasource.UID, err)}
 					instrument(err, "")
@@ -342,6 +362,29 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 			}
 		}()
 	}
+
+	// release the queries that failed before waiting for the other pipelines
+	for _, entry := range owned {
+		entry.release()
+	}
+
+	for _, sn := range shared {
+		dn := sn.node
+		frames, ok := dn.waitCacheEntry(ctx, sn.entry, s)
+		if !ok {
+			result, err := dn.Execute(ctx, now, vars, s)
+			if err != nil {
+				result.Error = err
+			}
+			vars[dn.refID] = result
+			continue
+		}
+		_, result, err := s.converter.Convert(ctx, dn.datasource.Type, frames, s.allowLongFrames)
+		if err != nil {
+			result.Error = makeConversionError(dn.RefID(), err)
+		}
+		vars[dn.refID] = result
+	}
 }
 
 // Execute runs the node and adds the results to vars. If the node requires
@@ -351,15 +394,30 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	logger := logger.FromContext(ctx).New("datasourceType", dn.datasource.Type, "queryRefId", dn.refID, "datasourceUid", dn.datasource.UID, "datasourceVersion", dn.datasource.Version)
 	ctx, span := s.tracer.Start(ctx, "SSE.ExecuteDatasourceQuery")
 	defer span.End()
+	span.SetAttributes(
+		attribute.String("datasource.type", dn.datasource.Type),
+		attribute.String("datasource.uid", dn.datasource.UID),
+	)
+
+	entry, owner := dn.acquireCacheEntry(ctx, now, s)
+	if entry != nil && !owner {
+		if frames, ok := dn.waitCacheEntry(ctx, entry, s); ok {
+			logger.Debug("Data source query shared with an identical query")
+			span.SetAttributes(attribute.Bool("query.shared", true))
+			_, result, err := s.converter.Convert(ctx, dn.datasource.Type, frames, s.allowLongFrames)
+			if err != nil {
+				err = makeConversionError(dn.refID, err)
+			}
+			return result, err
+		}
+		entry = nil
+	}
+	defer entry.release()
 
 	pCtx, err := s.pCtxProvider.GetWithDataSource(ctx, dn.datasource.Type, dn.request.User, dn.datasource)
 	if err != nil {
 		return mathexp.Results{}, err
 	}
-	span.SetAttributes(
-		attribute.String("datasource.type", dn.datasource.Type),
-		attribute.String("datasource.uid", dn.datasource.UID),
-	)
 
 	req := &backend.QueryDataRequest{
 		PluginContext: pCtx,
@@ -396,6 +454,7 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}
 
 	dataFrames, err := getResponseFrame(logger, resp, dn.refID)
+	entry.complete(dataFrames, err)
 	if err != nil {
 		return mathexp.Results{}, MakeQueryError(dn.refID, dn.datasource.UID, err)
 	}
@@ -27,8 +27,11 @@ const (
 	// Threshold
 	QueryTypeThreshold QueryType = "threshold"
 
-	// SQL query via DuckDB
+	// SQL query via an embedded SQL engine
 	QueryTypeSQL QueryType = "sql"
+
+	// Detect anomalies in time series
+	QueryTypeAnomaly QueryType = "anomaly"
 )
 
 type MathQuery struct {
@@ -60,6 +63,9 @@ type ResampleQuery struct {
 
 	// The upsample function
 	Upsampler mathexp.Upsampler `json:"upsampler"`
+
+	// Align the resampled points to multiples of the window, instead of the start of the time range
+	Align *ResampleAlign `json:"align,omitempty"`
 }
 
 type ThresholdQuery struct {
@@ -79,6 +85,30 @@ type SQLExpression struct {
 	Expression string `json:"expression" jsonschema:"minLength=1,example=SELECT * FROM A LIMIT 1"`
 }
 
+// QueryType = anomaly
+type AnomalyQuery struct {
+	// Reference to single query result
+	Expression string `json:"expression" jsonschema:"minLength=1,example=$A"`
+
+	// The detection algorithm
+	Algorithm mathexp.AnomalyAlgorithm `json:"algorithm"`
+
+	// The trailing time window of the baseline, required by the mad algorithm
+	Window string `json:"window,omitempty" jsonschema:"example=1h,example=30m"`
+
+	// The length of the seasonal cycle of the holt_winters algorithm, no seasonality when empty
+	Season string `json:"season,omitempty" jsonschema:"example=1d,example=1w"`
+
+	// The half width of the bands in deviations, 3 when not set
+	Sensitivity *float64 `json:"sensitivity,omitempty"`
+
+	// Smoothing factors of the holt_winters algorithm
+	Smoothing *AnomalySmoothing `json:"smoothing,omitempty"`
+
+	// Return the upper and lower bands in addition to the anomaly score
+	IncludeBands bool `json:"includeBands,omitempty"`
+}
+
 //-------------------------------
 // Non-query commands
 //-------------------------------
@@ -89,6 +119,28 @@ type ReduceSettings struct {
 
 	// Only valid when mode is replace
 	ReplaceWithValue *float64 `json:"replaceWithValue,omitempty"`
+
+	// The percentile (0-100), only valid when the reducer is percentile
+	Percentile *float64 `json:"percentile,omitempty"`
+}
+
+type ResampleAlign struct {
+	// Offset of the resampled points from the multiples of the window
+	Offset string `json:"offset,omitempty" jsonschema:"example=15m,example=-1h"`
+
+	// IANA time zone in which the multiples of the window are calculated, UTC when empty
+	Timezone string `json:"timezone,omitempty" jsonschema:"example=Europe/Berlin"`
+}
+
+type AnomalySmoothing struct {
+	// Smoothing factor of the level, in the range (0, 1]
+	Alpha *float64 `json:"alpha,omitempty"`
+
+	// Smoothing factor of the trend, in the range (0, 1]
+	Beta *float64 `json:"beta,omitempty"`
+
+	// Smoothing factor of the seasonal component and the deviation, in the range (0, 1]
+	Gamma *float64 `json:"gamma,omitempty"`
 }
 
 // Non-Number behavior mode
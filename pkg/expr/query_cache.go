@@ -0,0 +1,205 @@
+package expr
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// QueryCache deduplicates identical datasource queries across the pipelines that are executed with it.
+// The first pipeline that runs a query executes it, and every other pipeline that runs the same query
+// against the same datasource and time range waits for and gets a copy of the same response.
+//
+// Only successful responses are shared. If a query fails, the pipelines waiting for it execute the query
+// themselves, so an error of one pipeline never fails another one, and retries always query the datasource.
+//
+// A QueryCache is meant to be short-lived, for example for the evaluations of a single scheduler tick,
+// because it keeps all responses in memory until it is garbage collected.
+type QueryCache struct {
+	mu      sync.Mutex
+	entries map[string]*queryCacheEntry
+}
+
+// NewQueryCache creates an empty QueryCache.
+func NewQueryCache() *QueryCache {
+	return &QueryCache{
+		entries: make(map[string]*queryCacheEntry),
+	}
+}
+
+type queryCacheContextKey struct{}
+
+// WithQueryCache returns a copy of ctx that makes pipelines executed with it share the responses of
+// identical datasource queries through cache. If cache is nil, ctx is returned unchanged.
+func WithQueryCache(ctx context.Context, cache *QueryCache) context.Context {
+	if cache == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, queryCacheContextKey{}, cache)
+}
+
+func queryCacheFromContext(ctx context.Context) *QueryCache {
+	cache, _ := ctx.Value(queryCacheContextKey{}).(*QueryCache)
+	return cache
+}
+
+// acquire returns the entry of the query with the key. If owner is true, the query is not executed
+// yet and the caller must execute it and complete the entry.
+func (c *QueryCache) acquire(key string) (entry *queryCacheEntry, owner bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		return e, false
+	}
+	e := &queryCacheEntry{cache: c, key: key, done: make(chan struct{})}
+	c.entries[key] = e
+	return e, true
+}
+
+func (c *QueryCache) remove(e *queryCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries[e.key] == e {
+		delete(c.entries, e.key)
+	}
+}
+
+// queryCacheEntry holds the response of a single query. The frames are stored encoded, so that
+// every pipeline decodes its own copy and can't modify the frames of another one.
+type queryCacheEntry struct {
+	cache *QueryCache
+	key   string
+	once  sync.Once
+	done  chan struct{}
+
+	frames [][]byte
+	ok     bool
+}
+
+// complete stores the response of the query and wakes up the pipelines waiting for it. If err is
+// not nil, the response is not shared. Only the first call has an effect, and it is a no-op on a nil entry.
+func (e *queryCacheEntry) complete(frames data.Frames, err error) {
+	if e == nil {
+		return
+	}
+	var encoded [][]byte
+	if err == nil {
+		encoded, err = frames.MarshalArrow()
+	}
+	if err != nil {
+		e.release()
+		return
+	}
+	e.once.Do(func() {
+		e.frames = encoded
+		e.ok = true
+		close(e.done)
+	})
+}
+
+// release completes the entry without a response, if it was not completed yet, and removes it
+// from the cache so that the next pipeline that runs the query executes it again.
+func (e *queryCacheEntry) release() {
+	if e == nil {
+		return
+	}
+	e.once.Do(func() {
+		e.cache.remove(e)
+		close(e.done)
+	})
+}
+
+// wait blocks until the entry is completed and returns a copy of the frames. ok is false if the
+// response can't be shared, in which case the caller has to execute the query itself.
+func (e *queryCacheEntry) wait(ctx context.Context, refID string) (frames data.Frames, ok bool) {
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case <-e.done:
+	}
+	if !e.ok {
+		return nil, false
+	}
+	frames, err := data.UnmarshalArrowFrames(e.frames)
+	if err != nil {
+		return nil, false
+	}
+	for _, frame := range frames {
+		frame.RefID = refID
+	}
+	return frames, true
+}
+
+// cacheKey returns the key that identifies the query of the node. Two nodes have the same key if they
+// send the same query to the same datasource for the same time range. The refID is not part of the key.
+func (dn *DSNode) cacheKey(now time.Time) (string, error) {
+	var model map[string]any
+	if err := json.Unmarshal(dn.query, &model); err != nil {
+		return "", err
+	}
+	delete(model, "refId")
+
+	tr := dn.timeRange.AbsoluteTime(now)
+	b, err := json.Marshal(struct {
+		OrgID      int64          `json:"orgId"`
+		UID        string         `json:"uid"`
+		QueryType  string         `json:"queryType"`
+		Query      map[string]any `json:"query"`
+		From       int64          `json:"from"`
+		To         int64          `json:"to"`
+		IntervalMS int64          `json:"intervalMs"`
+		MaxDP      int64          `json:"maxDataPoints"`
+	}{
+		OrgID:      dn.orgID,
+		UID:        dn.datasource.UID,
+		QueryType:  dn.queryType,
+		Query:      model,
+		From:       tr.From.UnixNano(),
+		To:         tr.To.UnixNano(),
+		IntervalMS: dn.intervalMS,
+		MaxDP:      dn.maxDP,
+	})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// acquireCacheEntry acquires the entry of the query of the node in the QueryCache of ctx. It returns a nil
+// entry if there is no cache. If owner is true, the node must be executed and the entry completed with its
+// response, otherwise the response can be awaited with waitCacheEntry. A pipeline must not wait for an
+// entry while it owns entries that are not completed yet.
+func (dn *DSNode) acquireCacheEntry(ctx context.Context, now time.Time, s *Service) (entry *queryCacheEntry, owner bool) {
+	cache := queryCacheFromContext(ctx)
+	if cache == nil {
+		return nil, false
+	}
+	key, err := dn.cacheKey(now)
+	if err != nil {
+		logger.FromContext(ctx).Debug("Failed to build the query cache key", "queryRefId", dn.refID, "error", err)
+		return nil, false
+	}
+	entry, owner = cache.acquire(key)
+	if owner {
+		s.metrics.dsQueryCacheRequests.WithLabelValues("miss", dn.datasource.Type).Inc()
+	}
+	return entry, owner
+}
+
+// waitCacheEntry waits for the response of an identical query that is executed by another node and returns
+// a copy of its frames. ok is false if the response is not shared, in which case the node must be executed.
+func (dn *DSNode) waitCacheEntry(ctx context.Context, entry *queryCacheEntry, s *Service) (frames data.Frames, ok bool) {
+	frames, ok = entry.wait(ctx, dn.refID)
+	result := "hit"
+	if !ok {
+		result = "miss"
+	}
+	s.metrics.dsQueryCacheRequests.WithLabelValues(result, dn.datasource.Type).Inc()
+	return frames, ok
+}
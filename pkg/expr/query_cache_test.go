@@ -0,0 +1,202 @@
+package expr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	datafakes "github.com/grafana/grafana/pkg/services/datasources/fakes"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginconfig"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestQueryCacheEntry(t *testing.T) {
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+		data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(2)}))
+	frame.RefID = "A"
+
+	t.Run("shares a copy of the response", func(t *testing.T) {
+		cache := NewQueryCache()
+		entry, owner := cache.acquire("key")
+		require.True(t, owner)
+		shared, owner := cache.acquire("key")
+		require.False(t, owner)
+		require.Same(t, entry, shared)
+
+		entry.complete(data.Frames{frame}, nil)
+		frames, ok := shared.wait(context.Background(), "B")
+		require.True(t, ok)
+		require.Len(t, frames, 1)
+		require.Equal(t, "B", frames[0].RefID)
+		require.Equal(t, frame.Fields, frames[0].Fields)
+		require.Equal(t, "A", frame.RefID)
+	})
+
+	t.Run("does not share errors", func(t *testing.T) {
+		cache := NewQueryCache()
+		entry, _ := cache.acquire("key")
+		shared, _ := cache.acquire("key")
+
+		entry.complete(nil, errors.New("failed"))
+		_, ok := shared.wait(context.Background(), "B")
+		require.False(t, ok)
+
+		_, owner := cache.acquire("key")
+		require.True(t, owner)
+	})
+
+	t.Run("release does not overwrite a response", func(t *testing.T) {
+		cache := NewQueryCache()
+		entry, _ := cache.acquire("key")
+		entry.complete(data.Frames{frame}, nil)
+		entry.release()
+
+		shared, owner := cache.acquire("key")
+		require.False(t, owner)
+		_, ok := shared.wait(context.Background(), "B")
+		require.True(t, ok)
+	})
+
+	t.Run("stops waiting when the context is cancelled", func(t *testing.T) {
+		cache := NewQueryCache()
+		_, _ = cache.acquire("key")
+		shared, _ := cache.acquire("key")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, ok := shared.wait(ctx, "B")
+		require.False(t, ok)
+	})
+}
+
+func TestDSNodeCacheKey(t *testing.T) {
+	now := time.Now()
+	node := func(refID, query string, tr TimeRange) *DSNode {
+		return &DSNode{
+			baseNode:   baseNode{refID: refID},
+			orgID:      1,
+			query:      json.RawMessage(query),
+			datasource: &datasources.DataSource{UID: "test"},
+			timeRange:  tr,
+		}
+	}
+	key := func(dn *DSNode) string {
+		k, err := dn.cacheKey(now)
+		require.NoError(t, err)
+		return k
+	}
+	last := RelativeTimeRange{From: -time.Hour}
+
+	a := key(node("A", `{"refId": "A", "expr": "up", "datasource": {"uid": "test"}}`, last))
+	require.Equal(t, a, key(node("B", `{"datasource": {"uid": "test"}, "expr": "up", "refId": "B"}`, last)))
+	require.NotEqual(t, a, key(node("A", `{"refId": "A", "expr": "down", "datasource": {"uid": "test"}}`, last)))
+	require.NotEqual(t, a, key(node("A", `{"refId": "A", "expr": "up", "datasource": {"uid": "test"}}`, RelativeTimeRange{From: -2 * time.Hour})))
+}
+
+func TestServiceWithQueryCache(t *testing.T) {
+	for _, groupByDS := range []bool{false, true} {
+		features := featuremgmt.WithFeatures()
+		if groupByDS {
+			features = featuremgmt.WithFeatures(featuremgmt.FlagSseGroupByDatasource)
+		}
+		me := &countingEndpoint{}
+		s := newQueryCacheTestService(me, features)
+
+		buildPipeline := func(refID string) DataPipeline {
+			pl, err := s.BuildPipeline(&Request{Queries: []Query{
+				{
+					RefID: refID,
+					DataSource: &datasources.DataSource{
+						OrgID: 1,
+						UID:   "test",
+						Type:  "test",
+					},
+					JSON:      json.RawMessage(`{ "datasource": { "uid": "test" }, "expr": "up", "intervalMs": 1000, "maxDataPoints": 1000 }`),
+					TimeRange: RelativeTimeRange{From: -time.Hour},
+				},
+				{
+					RefID:      "C",
+					DataSource: dataSourceModel(),
+					JSON:       json.RawMessage(`{ "datasource": { "uid": "__expr__", "type": "__expr__"}, "type": "math", "expression": "$` + refID + ` * 2" }`),
+				},
+			}, User: &user.SignedInUser{}})
+			require.NoError(t, err)
+			return pl
+		}
+
+		now := time.Now()
+		ctx := WithQueryCache(context.Background(), NewQueryCache())
+		first, err := s.ExecutePipeline(ctx, now, buildPipeline("A"))
+		require.NoError(t, err)
+		second, err := s.ExecutePipeline(ctx, now, buildPipeline("B"))
+		require.NoError(t, err)
+
+		require.Equal(t, int32(1), me.calls.Load(), "groupByDS=%t", groupByDS)
+		require.Equal(t, "B", second.Responses["B"].Frames[0].RefID)
+		require.Equal(t, first.Responses["C"].Frames[0].Fields[1].At(0), second.Responses["C"].Frames[0].Fields[1].At(0))
+
+		// a different time range is queried again
+		_, err = s.ExecutePipeline(ctx, now.Add(time.Minute), buildPipeline("A"))
+		require.NoError(t, err)
+		require.Equal(t, int32(2), me.calls.Load())
+
+		// pipelines executed without the cache always query the datasource
+		_, err = s.ExecutePipeline(context.Background(), now, buildPipeline("A"))
+		require.NoError(t, err)
+		require.Equal(t, int32(3), me.calls.Load())
+	}
+}
+
+func newQueryCacheTestService(me backend.QueryDataHandler, features featuremgmt.FeatureToggles) *Service {
+	pCtxProvider := plugincontext.ProvideService(setting.NewCfg(), nil, &pluginstore.FakePluginStore{
+		PluginList: []pluginstore.Plugin{
+			{JSONData: plugins.JSONData{ID: "test"}},
+		},
+	}, &datafakes.FakeCacheService{}, &datafakes.FakeDataSourceService{}, nil, pluginconfig.NewFakePluginRequestConfigProvider())
+
+	return &Service{
+		cfg:          setting.NewCfg(),
+		dataService:  me,
+		pCtxProvider: pCtxProvider,
+		features:     features,
+		tracer:       tracing.InitializeTracerForTest(),
+		metrics:      newMetrics(nil),
+		converter: &ResultConverter{
+			Features: features,
+			Tracer:   tracing.InitializeTracerForTest(),
+		},
+	}
+}
+
+// countingEndpoint responds to every query with a new series and counts the queries.
+type countingEndpoint struct {
+	calls atomic.Int32
+}
+
+func (me *countingEndpoint) QueryData(_ context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		me.calls.Add(1)
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{q.TimeRange.To}),
+			data.NewField("value", data.Labels{"test": "label"}, []*float64{fp(2)}))
+		frame.RefID = q.RefID
+		resp.Responses[q.RefID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return resp, nil
+}
@@ -27,6 +27,7 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(ReduceModeDrop),       // pick an example value (not the root)
 				reflect.TypeOf(ThresholdIsAbove),
 				reflect.TypeOf(classic.ConditionOperatorAnd),
+				reflect.TypeOf(mathexp.AnomalyMAD),
 			},
 		})
 	require.NoError(t, err)
@@ -161,6 +162,29 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				},
 			},
 		},
+		schemabuilder.QueryTypeInfo{
+			Discriminators: data.NewDiscriminators("type", QueryTypeAnomaly),
+			GoType:         reflect.TypeOf(&AnomalyQuery{}),
+			Examples: []data.QueryExample{
+				{
+					Name: "detect outliers from the median of the last hour",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression: "$A",
+						Algorithm:  mathexp.AnomalyMAD,
+						Window:     "1h",
+					}),
+				},
+				{
+					Name: "daily seasonal baseline with bands",
+					SaveModel: data.AsUnstructured(AnomalyQuery{
+						Expression:   "$A",
+						Algorithm:    mathexp.AnomalyHoltWinters,
+						Season:       "1d",
+						IncludeBands: true,
+					}),
+				},
+			},
+		},
 	)
 
 	require.NoError(t, err)
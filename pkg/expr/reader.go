@@ -66,6 +66,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 
 	case QueryTypeReduce:
 		var mapper mathexp.ReduceMapper = nil
+		var args mathexp.ReducerArgs
 		q := &ReduceQuery{}
 		err = iter.ReadVal(q)
 		if err == nil {
@@ -73,7 +74,10 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Properties = q
 		}
 		if err == nil && q.Settings != nil {
+			args.Percentile = q.Settings.Percentile
 			switch q.Settings.Mode {
+			case "":
+				// only reducer arguments are set
 			case ReduceModeDrop:
 				mapper = mathexp.DropNonNumber{}
 			case ReduceModeReplace:
@@ -87,8 +91,8 @@ func (h *ExpressionQueryReader) ReadQuery(
 		}
 		if err == nil {
 			eq.Properties = q
-			eq.Command, err = NewReduceCommand(common.RefID,
-				q.Reducer, referenceVar, mapper)
+			eq.Command, err = NewReduceCommandWithArgs(common.RefID,
+				q.Reducer, args, referenceVar, mapper)
 		}
 
 	case QueryTypeResample:
@@ -100,6 +104,10 @@ func (h *ExpressionQueryReader) ReadQuery(
 		if err == nil {
 			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
 		}
+		var alignment *mathexp.ResampleAlignment
+		if err == nil && q.Align != nil {
+			alignment, err = NewResampleAlignment(q.Align.Offset, q.Align.Timezone)
+		}
 		if err == nil {
 			tr := gtime.NewTimeRange(common.TimeRange.From, common.TimeRange.To)
 			eq.Properties = q
@@ -112,6 +120,7 @@ func (h *ExpressionQueryReader) ReadQuery(
 					From: tr.GetFromAsTimeUTC(),
 					To:   tr.GetToAsTimeUTC(),
 				},
+				alignment,
 			)
 		}
 
@@ -131,6 +140,17 @@ func (h *ExpressionQueryReader) ReadQuery(
 			eq.Command, err = NewSQLCommand(common.RefID, q.Expression)
 		}
 
+	case QueryTypeAnomaly:
+		q := &AnomalyQuery{}
+		err = iter.ReadVal(q)
+		if err == nil {
+			referenceVar, err = getReferenceVar(q.Expression, common.RefID)
+		}
+		if err == nil {
+			eq.Properties = q
+			eq.Command, err = NewAnomalyCommand(common.RefID, referenceVar, q)
+		}
+
 	case QueryTypeThreshold:
 		q := &ThresholdQuery{}
 		err = iter.ReadVal(q)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr/sql"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -98,6 +99,18 @@ func (s *Service) isDisabled() bool {
 	return !s.cfg.ExpressionsEnabled
 }
 
+// sqlLimits returns the resource limits of SQL expressions.
+func (s *Service) sqlLimits() sql.Limits {
+	if s.cfg == nil {
+		return sql.DefaultLimits()
+	}
+	return sql.Limits{
+		MaxInputRows:   s.cfg.SQLExpressionMaxInputRows,
+		MaxOutputRows:  s.cfg.SQLExpressionMaxOutputRows,
+		MaxMemoryBytes: s.cfg.SQLExpressionMaxMemoryBytes,
+	}
+}
+
 // BuildPipeline builds a pipeline from a request.
 func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 	return s.buildPipeline(req)
@@ -0,0 +1,430 @@
+package sql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SelectStatement is a parsed SELECT query.
+type SelectStatement struct {
+	With     []*CommonTableExpr
+	Distinct bool
+	Columns  []*SelectItem
+	From     TableExpr // nil when the query has no FROM clause
+	Where    Expr
+	GroupBy  []Expr
+	Having   Expr
+	OrderBy  []*OrderItem
+	Limit    *int64
+	Offset   *int64
+}
+
+// CommonTableExpr is a named subquery of a WITH clause.
+type CommonTableExpr struct {
+	Name   string
+	Select *SelectStatement
+}
+
+// SelectItem is a single entry of the select list.
+type SelectItem struct {
+	Expr  Expr   // nil for star items
+	Alias string // optional output name
+	Star  bool   // true for * and <table>.*
+	Table string // qualifier of <table>.*
+}
+
+// OrderItem is a single entry of an ORDER BY clause.
+type OrderItem struct {
+	Expr       Expr
+	Desc       bool
+	NullsFirst *bool // nil uses the default: nulls sort as the largest value
+}
+
+// TableExpr is an entry of the FROM clause.
+type TableExpr interface {
+	tableExpr()
+}
+
+// TableName references an input table, i.e. a query or expression by refID.
+type TableName struct {
+	Name  string
+	Alias string
+}
+
+// SubqueryTable is a SELECT used as a table.
+type SubqueryTable struct {
+	Select *SelectStatement
+	Alias  string
+}
+
+// JoinKind is the kind of a JOIN.
+type JoinKind int
+
+const (
+	JoinInner JoinKind = iota
+	JoinLeft
+	JoinRight
+	JoinFull
+	JoinCross
+)
+
+// JoinExpr joins two table expressions.
+type JoinExpr struct {
+	Kind  JoinKind
+	Left  TableExpr
+	Right TableExpr
+	On    Expr     // join condition, nil for CROSS and USING joins
+	Using []string // columns of a USING join
+}
+
+func (*TableName) tableExpr()     {}
+func (*SubqueryTable) tableExpr() {}
+func (*JoinExpr) tableExpr()      {}
+
+// Expr is a scalar expression. String returns its canonical form, which is
+// used to name unaliased output columns and to match GROUP BY expressions.
+type Expr interface {
+	fmt.Stringer
+	expr()
+}
+
+// Literal is a constant of type int64, float64, string, bool or nil.
+type Literal struct {
+	Value any
+}
+
+// ColumnRef references a column, optionally qualified by a table name or alias.
+type ColumnRef struct {
+	Table string
+	Name  string
+}
+
+// UnaryExpr is NOT, unary minus or unary plus.
+type UnaryExpr struct {
+	Op string
+	X  Expr
+}
+
+// BinaryExpr is an arithmetic, comparison, logical or concatenation operation.
+type BinaryExpr struct {
+	Op    string
+	Left  Expr
+	Right Expr
+}
+
+// IsNullExpr is X IS [NOT] NULL.
+type IsNullExpr struct {
+	X   Expr
+	Not bool
+}
+
+// InExpr is X [NOT] IN (list).
+type InExpr struct {
+	X    Expr
+	List []Expr
+	Not  bool
+}
+
+// BetweenExpr is X [NOT] BETWEEN Low AND High.
+type BetweenExpr struct {
+	X    Expr
+	Low  Expr
+	High Expr
+	Not  bool
+}
+
+// LikeExpr is X [NOT] LIKE|ILIKE Pattern.
+type LikeExpr struct {
+	X               Expr
+	Pattern         Expr
+	Not             bool
+	CaseInsensitive bool
+}
+
+// CaseExpr is a simple (with Operand) or searched CASE expression.
+type CaseExpr struct {
+	Operand Expr
+	Whens   []*WhenClause
+	Else    Expr
+}
+
+// WhenClause is a WHEN ... THEN ... branch of a CASE expression.
+type WhenClause struct {
+	Cond   Expr
+	Result Expr
+}
+
+// CastExpr converts X to Type.
+type CastExpr struct {
+	X    Expr
+	Type Type
+}
+
+// FuncCall is a call of a scalar, aggregate or window function.
+type FuncCall struct {
+	Name     string // lower-cased function name
+	Args     []Expr
+	Star     bool // count(*)
+	Distinct bool
+	Over     *WindowSpec // non-nil for window function calls
+}
+
+// WindowSpec is the OVER clause of a window function call.
+type WindowSpec struct {
+	PartitionBy []Expr
+	OrderBy     []*OrderItem
+	Frame       *WindowFrame // nil uses the default frame
+}
+
+// FrameBoundKind is the kind of a window frame bound.
+type FrameBoundKind int
+
+const (
+	UnboundedPreceding FrameBoundKind = iota
+	Preceding
+	CurrentRow
+	Following
+	UnboundedFollowing
+)
+
+// FrameBound is one end of a ROWS window frame.
+type FrameBound struct {
+	Kind   FrameBoundKind
+	Offset int64 // number of rows for Preceding and Following
+}
+
+// WindowFrame is a ROWS BETWEEN Start AND End frame.
+type WindowFrame struct {
+	Start FrameBound
+	End   FrameBound
+}
+
+func (*Literal) expr()     {}
+func (*ColumnRef) expr()   {}
+func (*UnaryExpr) expr()   {}
+func (*BinaryExpr) expr()  {}
+func (*IsNullExpr) expr()  {}
+func (*InExpr) expr()      {}
+func (*BetweenExpr) expr() {}
+func (*LikeExpr) expr()    {}
+func (*CaseExpr) expr()    {}
+func (*CastExpr) expr()    {}
+func (*FuncCall) expr()    {}
+
+func (l *Literal) String() string {
+	switch v := l.Value.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return "'" + v.Format(time.RFC3339Nano) + "'"
+	}
+	return fmt.Sprintf("%v", l.Value)
+}
+
+func (c *ColumnRef) String() string {
+	if c.Table != "" {
+		return c.Table + "." + c.Name
+	}
+	return c.Name
+}
+
+func (u *UnaryExpr) String() string {
+	if u.Op == "NOT" {
+		return "NOT " + u.X.String()
+	}
+	return u.Op + u.X.String()
+}
+
+func (b *BinaryExpr) String() string {
+	return "(" + b.Left.String() + " " + b.Op + " " + b.Right.String() + ")"
+}
+
+func (e *IsNullExpr) String() string {
+	if e.Not {
+		return e.X.String() + " IS NOT NULL"
+	}
+	return e.X.String() + " IS NULL"
+}
+
+func (e *InExpr) String() string {
+	op := " IN ("
+	if e.Not {
+		op = " NOT IN ("
+	}
+	return e.X.String() + op + joinExprs(e.List) + ")"
+}
+
+func (e *BetweenExpr) String() string {
+	op := " BETWEEN "
+	if e.Not {
+		op = " NOT BETWEEN "
+	}
+	return e.X.String() + op + e.Low.String() + " AND " + e.High.String()
+}
+
+func (e *LikeExpr) String() string {
+	op := "LIKE"
+	if e.CaseInsensitive {
+		op = "ILIKE"
+	}
+	if e.Not {
+		op = "NOT " + op
+	}
+	return e.X.String() + " " + op + " " + e.Pattern.String()
+}
+
+func (e *CaseExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString("CASE")
+	if e.Operand != nil {
+		sb.WriteString(" " + e.Operand.String())
+	}
+	for _, w := range e.Whens {
+		sb.WriteString(" WHEN " + w.Cond.String() + " THEN " + w.Result.String())
+	}
+	if e.Else != nil {
+		sb.WriteString(" ELSE " + e.Else.String())
+	}
+	sb.WriteString(" END")
+	return sb.String()
+}
+
+func (e *CastExpr) String() string {
+	return "CAST(" + e.X.String() + " AS " + e.Type.String() + ")"
+}
+
+func (f *FuncCall) String() string {
+	var sb strings.Builder
+	sb.WriteString(f.Name + "(")
+	if f.Distinct {
+		sb.WriteString("DISTINCT ")
+	}
+	if f.Star {
+		sb.WriteString("*")
+	} else {
+		sb.WriteString(joinExprs(f.Args))
+	}
+	sb.WriteString(")")
+	if f.Over != nil {
+		sb.WriteString(" OVER (" + f.Over.String() + ")")
+	}
+	return sb.String()
+}
+
+func (w *WindowSpec) String() string {
+	var parts []string
+	if len(w.PartitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+joinExprs(w.PartitionBy))
+	}
+	if len(w.OrderBy) > 0 {
+		items := make([]string, 0, len(w.OrderBy))
+		for _, o := range w.OrderBy {
+			items = append(items, o.String())
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(items, ", "))
+	}
+	if w.Frame != nil {
+		parts = append(parts, "ROWS BETWEEN "+w.Frame.Start.String()+" AND "+w.Frame.End.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+func (b FrameBound) String() string {
+	switch b.Kind {
+	case UnboundedPreceding:
+		return "UNBOUNDED PRECEDING"
+	case Preceding:
+		return strconv.FormatInt(b.Offset, 10) + " PRECEDING"
+	case Following:
+		return strconv.FormatInt(b.Offset, 10) + " FOLLOWING"
+	case UnboundedFollowing:
+		return "UNBOUNDED FOLLOWING"
+	}
+	return "CURRENT ROW"
+}
+
+func (o *OrderItem) String() string {
+	s := o.Expr.String()
+	if o.Desc {
+		s += " DESC"
+	}
+	if o.NullsFirst != nil {
+		if *o.NullsFirst {
+			s += " NULLS FIRST"
+		} else {
+			s += " NULLS LAST"
+		}
+	}
+	return s
+}
+
+func joinExprs(exprs []Expr) string {
+	parts := make([]string, 0, len(exprs))
+	for _, e := range exprs {
+		parts = append(parts, e.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// walkExpr calls fn for e and every sub-expression of e in depth-first
+// order. When fn returns false the children of that expression are skipped.
+func walkExpr(e Expr, fn func(Expr) bool) {
+	if e == nil || !fn(e) {
+		return
+	}
+	switch n := e.(type) {
+	case *UnaryExpr:
+		walkExpr(n.X, fn)
+	case *BinaryExpr:
+		walkExpr(n.Left, fn)
+		walkExpr(n.Right, fn)
+	case *IsNullExpr:
+		walkExpr(n.X, fn)
+	case *InExpr:
+		walkExpr(n.X, fn)
+		for _, x := range n.List {
+			walkExpr(x, fn)
+		}
+	case *BetweenExpr:
+		walkExpr(n.X, fn)
+		walkExpr(n.Low, fn)
+		walkExpr(n.High, fn)
+	case *LikeExpr:
+		walkExpr(n.X, fn)
+		walkExpr(n.Pattern, fn)
+	case *CaseExpr:
+		walkExpr(n.Operand, fn)
+		for _, w := range n.Whens {
+			walkExpr(w.Cond, fn)
+			walkExpr(w.Result, fn)
+		}
+		walkExpr(n.Else, fn)
+	case *CastExpr:
+		walkExpr(n.X, fn)
+	case *FuncCall:
+		for _, x := range n.Args {
+			walkExpr(x, fn)
+		}
+		if n.Over != nil {
+			for _, x := range n.Over.PartitionBy {
+				walkExpr(x, fn)
+			}
+			for _, o := range n.Over.OrderBy {
+				walkExpr(o.Expr, fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,477 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLimitExceeded is returned when a query exceeds one of its Limits.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// Limits bound the resources a query may use. A zero value disables the
+// corresponding limit.
+type Limits struct {
+	// MaxInputRows is the maximum number of rows of all input tables together.
+	MaxInputRows int64
+	// MaxOutputRows is the maximum number of rows of the result.
+	MaxOutputRows int64
+	// MaxMemoryBytes is the maximum estimated size of all rows materialised
+	// while executing the query, including inputs, joins and the result.
+	MaxMemoryBytes int64
+}
+
+// DefaultLimits returns the limits used when none are configured.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxInputRows:   200_000,
+		MaxOutputRows:  100_000,
+		MaxMemoryBytes: 256 << 20,
+	}
+}
+
+// Engine executes SELECT statements in memory over a set of tables.
+type Engine struct {
+	limits Limits
+}
+
+// NewEngine creates an Engine that enforces the given limits.
+func NewEngine(limits Limits) *Engine {
+	return &Engine{limits: limits}
+}
+
+// Query parses and executes rawSQL. Tables are referenced by name in the
+// FROM clause.
+func (e *Engine) Query(ctx context.Context, rawSQL string, tables []*Table) (*Table, error) {
+	stmt, err := Parse(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+	return e.Execute(ctx, stmt, tables)
+}
+
+// Execute executes a parsed statement.
+func (e *Engine) Execute(ctx context.Context, stmt *SelectStatement, tables []*Table) (*Table, error) {
+	var inputRows int64
+	for _, t := range tables {
+		for _, c := range t.Columns {
+			if len(c.Values) != t.Rows() {
+				return nil, fmt.Errorf("table %s: column %s has %d values, expected %d", t.Name, c.Name, len(c.Values), t.Rows())
+			}
+		}
+		inputRows += int64(t.Rows())
+	}
+	if e.limits.MaxInputRows > 0 && inputRows > e.limits.MaxInputRows {
+		return nil, fmt.Errorf("%w: the input has %d rows, the maximum is %d", ErrLimitExceeded, inputRows, e.limits.MaxInputRows)
+	}
+
+	x := &executor{
+		tables: tables,
+		budget: &budget{ctx: ctx, limits: e.limits},
+	}
+	rel, err := x.execSelect(stmt)
+	if err != nil {
+		return nil, err
+	}
+	if e.limits.MaxOutputRows > 0 && int64(len(rel.rows)) > e.limits.MaxOutputRows {
+		return nil, fmt.Errorf("%w: the result has %d rows, the maximum is %d", ErrLimitExceeded, len(rel.rows), e.limits.MaxOutputRows)
+	}
+	return rel.toTable(), nil
+}
+
+// budget tracks the memory used by a query and checks for cancellation.
+type budget struct {
+	ctx    context.Context
+	limits Limits
+	memory int64
+	ticks  int
+}
+
+// charge accounts for a materialised row.
+func (b *budget) charge(row []any) error {
+	size := int64(24) // slice header
+	for _, v := range row {
+		size += valueSize(v)
+	}
+	return b.chargeBytes(size)
+}
+
+func (b *budget) chargeBytes(size int64) error {
+	b.memory += size
+	if b.limits.MaxMemoryBytes > 0 && b.memory > b.limits.MaxMemoryBytes {
+		return fmt.Errorf("%w: the query needs more than %d bytes of memory", ErrLimitExceeded, b.limits.MaxMemoryBytes)
+	}
+	return b.tick()
+}
+
+// tick is called for every processed row and checks periodically whether
+// the query was cancelled.
+func (b *budget) tick() error {
+	b.ticks++
+	if b.ticks%1024 == 0 {
+		return b.ctx.Err()
+	}
+	return nil
+}
+
+// relColumn is a column of a relation. Hidden columns can only be referenced
+// with a qualifier and are not part of star expansions; they are the right
+// hand side columns of a USING join.
+type relColumn struct {
+	table  string
+	name   string
+	typ    Type
+	hidden bool
+}
+
+// relation is the row oriented intermediate result of a query.
+type relation struct {
+	cols []relColumn
+	rows [][]any
+}
+
+// resolve returns the index of the referenced column. Names are matched
+// exactly first and case-insensitively otherwise.
+func (r *relation) resolve(ref *ColumnRef) (int, error) {
+	for _, match := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		found := -1
+		for i, c := range r.cols {
+			if !match(c.name, ref.Name) {
+				continue
+			}
+			if ref.Table == "" && c.hidden {
+				continue
+			}
+			if ref.Table != "" && !match(c.table, ref.Table) {
+				continue
+			}
+			if found >= 0 {
+				return 0, fmt.Errorf("column reference %s is ambiguous", ref)
+			}
+			found = i
+		}
+		if found >= 0 {
+			return found, nil
+		}
+	}
+	return 0, fmt.Errorf("column %s not found", ref)
+}
+
+func (r *relation) toTable() *Table {
+	t := &Table{Columns: make([]*Column, len(r.cols))}
+	for i, c := range r.cols {
+		values := make([]any, len(r.rows))
+		for j, row := range r.rows {
+			values[j] = row[i]
+		}
+		t.Columns[i] = &Column{Name: c.name, Type: c.typ, Values: values}
+	}
+	return t
+}
+
+// executor executes a statement and its subqueries.
+type executor struct {
+	tables []*Table
+	budget *budget
+	ctes   []namedRelation // common table expressions in scope, innermost last
+}
+
+type namedRelation struct {
+	name string
+	rel  *relation
+}
+
+// lookupCTE finds a common table expression in scope by name.
+func (x *executor) lookupCTE(name string) *relation {
+	for _, match := range []func(a, b string) bool{
+		func(a, b string) bool { return a == b },
+		strings.EqualFold,
+	} {
+		for i := len(x.ctes) - 1; i >= 0; i-- {
+			if match(x.ctes[i].name, name) {
+				return x.ctes[i].rel
+			}
+		}
+	}
+	return nil
+}
+
+// lookupTable finds an input table by name, exactly first and
+// case-insensitively otherwise.
+func (x *executor) lookupTable(name string) (*Table, error) {
+	for _, t := range x.tables {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	var found *Table
+	for _, t := range x.tables {
+		if strings.EqualFold(t.Name, name) {
+			if found != nil {
+				return nil, fmt.Errorf("table reference %s is ambiguous", name)
+			}
+			found = t
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("table %s not found", name)
+	}
+	return found, nil
+}
+
+func (x *executor) execTableExpr(te TableExpr) (*relation, error) {
+	switch t := te.(type) {
+	case *TableName:
+		return x.scanTable(t)
+	case *SubqueryTable:
+		rel, err := x.execSelect(t.Select)
+		if err != nil {
+			return nil, err
+		}
+		for i := range rel.cols {
+			rel.cols[i].table = t.Alias
+		}
+		return rel, nil
+	case *JoinExpr:
+		return x.execJoin(t)
+	}
+	return nil, fmt.Errorf("unsupported table expression %T", te)
+}
+
+func (x *executor) scanTable(tn *TableName) (*relation, error) {
+	qualifier := tn.Name
+	if tn.Alias != "" {
+		qualifier = tn.Alias
+	}
+
+	if cte := x.lookupCTE(tn.Name); cte != nil {
+		// the rows of a common table expression are never modified, so they
+		// are shared by all references
+		rel := &relation{cols: make([]relColumn, len(cte.cols)), rows: cte.rows}
+		for i, c := range cte.cols {
+			rel.cols[i] = relColumn{table: qualifier, name: c.name, typ: c.typ}
+		}
+		return rel, nil
+	}
+
+	t, err := x.lookupTable(tn.Name)
+	if err != nil {
+		return nil, err
+	}
+	rel := &relation{cols: make([]relColumn, len(t.Columns)), rows: make([][]any, t.Rows())}
+	for i, c := range t.Columns {
+		typ := c.Type
+		if typ == TypeNull {
+			typ = TypeFloat
+		}
+		rel.cols[i] = relColumn{table: qualifier, name: c.Name, typ: typ}
+	}
+	for j := range rel.rows {
+		row := make([]any, len(t.Columns))
+		for i, c := range t.Columns {
+			row[i] = c.Values[j]
+		}
+		if err := x.budget.charge(row); err != nil {
+			return nil, err
+		}
+		rel.rows[j] = row
+	}
+	return rel, nil
+}
+
+func (x *executor) execJoin(j *JoinExpr) (*relation, error) {
+	left, err := x.execTableExpr(j.Left)
+	if err != nil {
+		return nil, err
+	}
+	right, err := x.execTableExpr(j.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &relation{cols: make([]relColumn, 0, len(left.cols)+len(right.cols))}
+	out.cols = append(out.cols, left.cols...)
+	out.cols = append(out.cols, right.cols...)
+	nLeft := len(left.cols)
+
+	sc := newScope(x, out)
+	cond := j.On
+	if len(j.Using) > 0 {
+		if cond, err = sc.usingCondition(j.Using, left, right); err != nil {
+			return nil, err
+		}
+	}
+	if cond != nil {
+		t, err := sc.check(cond, checkMode{clause: "JOIN condition", rowLevel: true})
+		if err != nil {
+			return nil, err
+		}
+		if t != TypeBool && t != TypeNull {
+			return nil, fmt.Errorf("JOIN condition must be a boolean, got %s", t)
+		}
+	}
+
+	emit := func(l, r []any) error {
+		row := make([]any, 0, len(out.cols))
+		if l == nil {
+			l = make([]any, nLeft)
+		}
+		if r == nil {
+			r = make([]any, len(right.cols))
+		}
+		row = append(append(row, l...), r...)
+		if err := x.budget.charge(row); err != nil {
+			return err
+		}
+		out.rows = append(out.rows, row)
+		return nil
+	}
+
+	candidates := sc.joinCandidates(cond, nLeft, left, right)
+	rightMatched := make([]bool, len(right.rows))
+	for _, l := range left.rows {
+		matched := false
+		for _, ri := range candidates(l) {
+			r := right.rows[ri]
+			if err := x.budget.tick(); err != nil {
+				return nil, err
+			}
+			if cond != nil {
+				row := make([]any, 0, len(out.cols))
+				row = append(append(row, l...), r...)
+				v, err := sc.eval(cond, &rowCtx{row: row})
+				if err != nil {
+					return nil, err
+				}
+				if v != true {
+					continue
+				}
+			}
+			matched = true
+			rightMatched[ri] = true
+			if err := emit(l, r); err != nil {
+				return nil, err
+			}
+		}
+		if !matched && (j.Kind == JoinLeft || j.Kind == JoinFull) {
+			if err := emit(l, nil); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if j.Kind == JoinRight || j.Kind == JoinFull {
+		for ri, r := range right.rows {
+			if !rightMatched[ri] {
+				if err := emit(nil, r); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// usingCondition builds the join condition of a USING join and hides the
+// right hand side join columns.
+func (sc *scope) usingCondition(names []string, left, right *relation) (Expr, error) {
+	var cond Expr
+	for _, name := range names {
+		li, err := left.resolve(&ColumnRef{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		ri, err := right.resolve(&ColumnRef{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		l, r := &ColumnRef{Name: name}, &ColumnRef{Name: name}
+		sc.cols[l] = li
+		sc.cols[r] = len(left.cols) + ri
+		sc.rel.cols[len(left.cols)+ri].hidden = true
+		eq := &BinaryExpr{Op: "=", Left: l, Right: r}
+		if cond == nil {
+			cond = eq
+		} else {
+			cond = &BinaryExpr{Op: "AND", Left: cond, Right: eq}
+		}
+	}
+	return cond, nil
+}
+
+// joinCandidates returns a function listing the right hand side rows that
+// may match a left hand side row. When the condition contains equalities
+// between columns of both sides the right rows are looked up in a hash
+// table, otherwise all rows are candidates.
+func (sc *scope) joinCandidates(cond Expr, nLeft int, left, right *relation) func(l []any) []int {
+	all := make([]int, len(right.rows))
+	for i := range all {
+		all[i] = i
+	}
+	var leftKeys, rightKeys []int
+	for _, c := range conjuncts(cond) {
+		b, ok := c.(*BinaryExpr)
+		if !ok || b.Op != "=" {
+			continue
+		}
+		lref, lok := b.Left.(*ColumnRef)
+		rref, rok := b.Right.(*ColumnRef)
+		if !lok || !rok {
+			continue
+		}
+		li, ri := sc.cols[lref], sc.cols[rref]
+		if li >= nLeft {
+			li, ri = ri, li
+		}
+		if li >= nLeft || ri < nLeft {
+			continue
+		}
+		lt, rt := sc.rel.cols[li].typ, sc.rel.cols[ri].typ
+		if lt != rt && !(lt.numeric() && rt.numeric()) {
+			continue
+		}
+		leftKeys = append(leftKeys, li)
+		rightKeys = append(rightKeys, ri-nLeft)
+	}
+	if len(leftKeys) == 0 {
+		return func([]any) []int { return all }
+	}
+
+	key := func(row []any, idx []int) (string, bool) {
+		values := make([]any, len(idx))
+		for i, c := range idx {
+			if row[c] == nil {
+				return "", false
+			}
+			values[i] = row[c]
+		}
+		return rowKey(values), true
+	}
+	index := map[string][]int{}
+	for i, r := range right.rows {
+		if k, ok := key(r, rightKeys); ok {
+			index[k] = append(index[k], i)
+		}
+	}
+	return func(l []any) []int {
+		k, ok := key(l, leftKeys)
+		if !ok {
+			return nil
+		}
+		return index[k]
+	}
+}
+
+// conjuncts splits an expression into the operands of its top level ANDs.
+func conjuncts(e Expr) []Expr {
+	if e == nil {
+		return nil
+	}
+	if b, ok := e.(*BinaryExpr); ok && b.Op == "AND" {
+		return append(conjuncts(b.Left), conjuncts(b.Right)...)
+	}
+	return []Expr{e}
+}
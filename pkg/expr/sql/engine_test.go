@@ -0,0 +1,287 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTables() []*Table {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []*Table{
+		{
+			Name: "A",
+			Columns: []*Column{
+				{Name: "time", Type: TypeTime, Values: []any{t0, t0.Add(time.Minute), t0.Add(2 * time.Minute), t0, t0.Add(time.Minute)}},
+				{Name: "host", Type: TypeString, Values: []any{"a", "a", "a", "b", "b"}},
+				{Name: "value", Type: TypeFloat, Values: []any{1.0, 3.0, 2.0, 10.0, nil}},
+			},
+		},
+		{
+			Name: "B",
+			Columns: []*Column{
+				{Name: "host", Type: TypeString, Values: []any{"a", "c"}},
+				{Name: "limit", Type: TypeInt, Values: []any{int64(2), int64(5)}},
+			},
+		},
+	}
+}
+
+func TestEngineQuery(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		sql     string
+		columns []string
+		types   []Type
+		rows    [][]any
+	}{
+		{
+			name:    "select with where and order",
+			sql:     `SELECT host, value FROM A WHERE value > 1.5 ORDER BY value DESC`,
+			columns: []string{"host", "value"},
+			types:   []Type{TypeString, TypeFloat},
+			rows:    [][]any{{"b", 10.0}, {"a", 3.0}, {"a", 2.0}},
+		},
+		{
+			name:    "select without from",
+			sql:     `SELECT 1 + 2 AS three, 'x' || 'y', NULL AS n`,
+			columns: []string{"three", "('x' || 'y')", "n"},
+			types:   []Type{TypeInt, TypeString, TypeFloat},
+			rows:    [][]any{{int64(3), "xy", nil}},
+		},
+		{
+			name:    "star",
+			sql:     `SELECT * FROM B ORDER BY 1`,
+			columns: []string{"host", "limit"},
+			types:   []Type{TypeString, TypeInt},
+			rows:    [][]any{{"a", int64(2)}, {"c", int64(5)}},
+		},
+		{
+			name:    "inner join",
+			sql:     `SELECT A.host, A.value, B."limit" FROM A JOIN B ON A.host = B.host WHERE A.value > B."limit"`,
+			columns: []string{"host", "value", "limit"},
+			types:   []Type{TypeString, TypeFloat, TypeInt},
+			rows:    [][]any{{"a", 3.0, int64(2)}},
+		},
+		{
+			name:    "left join",
+			sql:     `SELECT DISTINCT a.host, b."limit" FROM A a LEFT JOIN B b ON a.host = b.host ORDER BY a.host`,
+			columns: []string{"host", "limit"},
+			types:   []Type{TypeString, TypeInt},
+			rows:    [][]any{{"a", int64(2)}, {"b", nil}},
+		},
+		{
+			name:    "right join",
+			sql:     `SELECT DISTINCT b.host, a.host AS other FROM A a RIGHT JOIN B b ON a.host = b.host ORDER BY 1`,
+			columns: []string{"host", "other"},
+			types:   []Type{TypeString, TypeString},
+			rows:    [][]any{{"a", "a"}, {"c", nil}},
+		},
+		{
+			name:    "full join with using",
+			sql:     `SELECT DISTINCT host, "limit" FROM A FULL JOIN B USING (host) ORDER BY host`,
+			columns: []string{"host", "limit"},
+			types:   []Type{TypeString, TypeInt},
+			rows:    [][]any{{"a", int64(2)}, {"b", nil}, {nil, int64(5)}},
+		},
+		{
+			name:    "group by with aggregates",
+			sql:     `SELECT host, count(*) AS n, count(value) AS c, sum(value) AS s, avg(value), min(time), max(value) FROM A GROUP BY host ORDER BY host`,
+			columns: []string{"host", "n", "c", "s", "avg(value)", "min(time)", "max(value)"},
+			types:   []Type{TypeString, TypeInt, TypeInt, TypeFloat, TypeFloat, TypeTime, TypeFloat},
+			rows: [][]any{
+				{"a", int64(3), int64(3), 6.0, 2.0, t0, 3.0},
+				{"b", int64(2), int64(1), 10.0, 10.0, t0, 10.0},
+			},
+		},
+		{
+			name:    "having",
+			sql:     `SELECT host FROM A GROUP BY host HAVING count(value) > 1`,
+			columns: []string{"host"},
+			types:   []Type{TypeString},
+			rows:    [][]any{{"a"}},
+		},
+		{
+			name:    "aggregate without group by on empty input",
+			sql:     `SELECT count(*), sum(value) FROM A WHERE value > 100`,
+			columns: []string{"count(*)", "sum(value)"},
+			types:   []Type{TypeInt, TypeFloat},
+			rows:    [][]any{{int64(0), nil}},
+		},
+		{
+			name:    "group by expression",
+			sql:     `SELECT date_trunc('hour', time) AS hour, count(*) FROM A GROUP BY 1`,
+			columns: []string{"hour", "count(*)"},
+			types:   []Type{TypeTime, TypeInt},
+			rows:    [][]any{{t0, int64(5)}},
+		},
+		{
+			name:    "window functions",
+			sql:     `SELECT host, value, row_number() OVER (PARTITION BY host ORDER BY time) AS rn, lag(value) OVER (PARTITION BY host ORDER BY time) AS prev, sum(value) OVER (PARTITION BY host) AS total FROM A ORDER BY host, time`,
+			columns: []string{"host", "value", "rn", "prev", "total"},
+			types:   []Type{TypeString, TypeFloat, TypeInt, TypeFloat, TypeFloat},
+			rows: [][]any{
+				{"a", 1.0, int64(1), nil, 6.0},
+				{"a", 3.0, int64(2), 1.0, 6.0},
+				{"a", 2.0, int64(3), 3.0, 6.0},
+				{"b", 10.0, int64(1), nil, 10.0},
+				{"b", nil, int64(2), 10.0, 10.0},
+			},
+		},
+		{
+			name:    "moving average over a rows frame",
+			sql:     `SELECT avg(value) OVER (ORDER BY time ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) AS m FROM A WHERE host = 'a' ORDER BY time`,
+			columns: []string{"m"},
+			types:   []Type{TypeFloat},
+			rows:    [][]any{{1.0}, {2.0}, {2.5}},
+		},
+		{
+			name:    "rank",
+			sql:     `SELECT time, rank() OVER (ORDER BY time) AS r, dense_rank() OVER (ORDER BY time) AS d FROM A ORDER BY time, host`,
+			columns: []string{"time", "r", "d"},
+			types:   []Type{TypeTime, TypeInt, TypeInt},
+			rows: [][]any{
+				{t0, int64(1), int64(1)},
+				{t0, int64(1), int64(1)},
+				{t0.Add(time.Minute), int64(3), int64(2)},
+				{t0.Add(time.Minute), int64(3), int64(2)},
+				{t0.Add(2 * time.Minute), int64(5), int64(3)},
+			},
+		},
+		{
+			name:    "common table expressions",
+			sql:     `WITH hosts AS (SELECT host, max(value) AS peak FROM A GROUP BY host), hot AS (SELECT host FROM hosts WHERE peak > 5) SELECT h.host FROM hot h`,
+			columns: []string{"host"},
+			types:   []Type{TypeString},
+			rows:    [][]any{{"b"}},
+		},
+		{
+			name:    "subquery with limit and offset",
+			sql:     `SELECT s.v FROM (SELECT value AS v FROM A WHERE value IS NOT NULL ORDER BY v) s LIMIT 2 OFFSET 1`,
+			columns: []string{"v"},
+			types:   []Type{TypeFloat},
+			rows:    [][]any{{2.0}, {3.0}},
+		},
+		{
+			name:    "case, cast, in, between and like",
+			sql:     `SELECT CASE WHEN value BETWEEN 2 AND 3 THEN 'mid' ELSE 'other' END AS c, CAST(value AS INTEGER) AS i FROM A WHERE host IN ('a', 'c') AND host ILIKE 'A%' AND host NOT LIKE 'A%' ORDER BY time`,
+			columns: []string{"c", "i"},
+			types:   []Type{TypeString, TypeInt},
+			rows:    [][]any{{"other", int64(1)}, {"mid", int64(3)}, {"mid", int64(2)}},
+		},
+		{
+			name:    "nulls first",
+			sql:     `SELECT value FROM A WHERE host = 'b' ORDER BY value NULLS FIRST`,
+			columns: []string{"value"},
+			types:   []Type{TypeFloat},
+			rows:    [][]any{{nil}, {10.0}},
+		},
+		{
+			name:    "division of integers returns a float",
+			sql:     `SELECT "limit" / 4 AS q, "limit" % 2 AS m, "limit" / 0 AS z FROM B ORDER BY q`,
+			columns: []string{"q", "m", "z"},
+			types:   []Type{TypeFloat, TypeInt, TypeFloat},
+			rows:    [][]any{{0.5, int64(0), nil}, {1.25, int64(1), nil}},
+		},
+		{
+			name:    "duplicate output names are made unique",
+			sql:     `SELECT host, host FROM B ORDER BY 1`,
+			columns: []string{"host", "host_1"},
+			types:   []Type{TypeString, TypeString},
+			rows:    [][]any{{"a", "a"}, {"c", "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewEngine(DefaultLimits()).Query(context.Background(), tt.sql, testTables())
+			require.NoError(t, err)
+
+			columns := make([]string, len(result.Columns))
+			types := make([]Type, len(result.Columns))
+			for i, c := range result.Columns {
+				columns[i] = c.Name
+				types[i] = c.Type
+			}
+			require.Equal(t, tt.columns, columns)
+			require.Equal(t, tt.types, types)
+
+			rows := make([][]any, result.Rows())
+			for j := range rows {
+				rows[j] = make([]any, len(result.Columns))
+				for i, c := range result.Columns {
+					rows[j][i] = c.Values[j]
+				}
+			}
+			require.Equal(t, tt.rows, rows)
+		})
+	}
+}
+
+func TestEngineQueryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		sql  string
+		err  string
+	}{
+		{name: "unknown table", sql: `SELECT * FROM C`, err: "table C not found"},
+		{name: "unknown column", sql: `SELECT foo FROM A`, err: "column foo not found"},
+		{name: "ambiguous column", sql: `SELECT host FROM A JOIN B ON A.host = B.host`, err: "column reference host is ambiguous"},
+		{name: "ungrouped column", sql: `SELECT host, value FROM A GROUP BY host`, err: "must appear in the GROUP BY clause"},
+		{name: "aggregate in where", sql: `SELECT host FROM A WHERE sum(value) > 1`, err: "WHERE"},
+		{name: "unknown function", sql: `SELECT foo(value) FROM A`, err: "foo"},
+		{name: "type mismatch", sql: `SELECT host + 1 FROM A`, err: "VARCHAR"},
+		{name: "non boolean where", sql: `SELECT host FROM A WHERE value`, err: "WHERE clause must be a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine(DefaultLimits()).Query(context.Background(), tt.sql, testTables())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestEngineLimits(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		sql    string
+	}{
+		{name: "input rows", limits: Limits{MaxInputRows: 6}, sql: `SELECT * FROM A`},
+		{name: "output rows", limits: Limits{MaxOutputRows: 9}, sql: `SELECT * FROM A CROSS JOIN B`},
+		{name: "memory", limits: Limits{MaxMemoryBytes: 1024}, sql: `SELECT * FROM A a1, A a2, A a3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewEngine(tt.limits).Query(context.Background(), tt.sql, testTables())
+			require.ErrorIs(t, err, ErrLimitExceeded)
+		})
+	}
+
+	t.Run("no limits", func(t *testing.T) {
+		result, err := NewEngine(Limits{}).Query(context.Background(), `SELECT * FROM A a1, A a2, A a3`, testTables())
+		require.NoError(t, err)
+		assert.Equal(t, 125, result.Rows())
+	})
+}
+
+func TestEngineCancellation(t *testing.T) {
+	values := make([]any, 2000)
+	for i := range values {
+		values[i] = int64(i)
+	}
+	tables := []*Table{{Name: "A", Columns: []*Column{{Name: "v", Type: TypeInt, Values: values}}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewEngine(DefaultLimits()).Query(ctx, `SELECT a.v FROM A a JOIN A b ON a.v < b.v`, tables)
+	require.ErrorIs(t, err, context.Canceled)
+}
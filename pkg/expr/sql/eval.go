@@ -0,0 +1,745 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// scope resolves and evaluates the expressions of a query against the rows
+// of a relation.
+type scope struct {
+	x    *executor
+	rel  *relation
+	cols map[*ColumnRef]int // resolved column indices
+
+	// aggregate queries only allow columns outside of aggregate functions
+	// when they are grouped by
+	groupKeys map[string]bool
+	groupCols map[int]bool
+
+	windows  []*FuncCall
+	patterns map[string]*regexp.Regexp
+}
+
+func newScope(x *executor, rel *relation) *scope {
+	return &scope{
+		x:         x,
+		rel:       rel,
+		cols:      map[*ColumnRef]int{},
+		groupKeys: map[string]bool{},
+		groupCols: map[int]bool{},
+		patterns:  map[string]*regexp.Regexp{},
+	}
+}
+
+// checkMode describes where an expression is used.
+type checkMode struct {
+	clause string
+	// rowLevel expressions are evaluated for individual input rows, all
+	// others for groups of rows in aggregate queries.
+	rowLevel    bool
+	allowAgg    bool
+	allowWindow bool
+}
+
+// rowCtx holds the row an expression is evaluated for. In aggregate queries
+// row is the first row of the group and group holds all rows of the group.
+type rowCtx struct {
+	row    []any
+	group  [][]any
+	window map[*FuncCall]any
+}
+
+// check validates an expression, resolves its column references and returns
+// its type.
+func (sc *scope) check(e Expr, m checkMode) (Type, error) {
+	if !m.rowLevel && sc.groupKeys[e.String()] {
+		// a grouped expression has the same value for all rows of a group
+		return sc.check(e, checkMode{clause: m.clause, rowLevel: true})
+	}
+
+	switch n := e.(type) {
+	case *Literal:
+		return typeOfValue(n.Value), nil
+
+	case *ColumnRef:
+		idx, ok := sc.cols[n]
+		if !ok {
+			var err error
+			if idx, err = sc.rel.resolve(n); err != nil {
+				return TypeNull, err
+			}
+			sc.cols[n] = idx
+		}
+		if !m.rowLevel && !sc.groupCols[idx] {
+			return TypeNull, fmt.Errorf("column %s must appear in the GROUP BY clause or be used in an aggregate function", n)
+		}
+		return sc.rel.cols[idx].typ, nil
+
+	case *UnaryExpr:
+		t, err := sc.check(n.X, m)
+		if err != nil {
+			return TypeNull, err
+		}
+		if n.Op == "NOT" {
+			if t != TypeBool && t != TypeNull {
+				return TypeNull, fmt.Errorf("argument of NOT must be a boolean, got %s", t)
+			}
+			return TypeBool, nil
+		}
+		if t != TypeNull && !t.numeric() {
+			return TypeNull, fmt.Errorf("operator %s cannot be applied to %s", n.Op, t)
+		}
+		return t, nil
+
+	case *BinaryExpr:
+		return sc.checkBinary(n, m)
+
+	case *IsNullExpr:
+		if _, err := sc.check(n.X, m); err != nil {
+			return TypeNull, err
+		}
+		return TypeBool, nil
+
+	case *InExpr:
+		t, err := sc.check(n.X, m)
+		if err != nil {
+			return TypeNull, err
+		}
+		for _, item := range n.List {
+			it, err := sc.check(item, m)
+			if err != nil {
+				return TypeNull, err
+			}
+			if !comparableTypes(t, it) {
+				return TypeNull, fmt.Errorf("cannot compare %s and %s in %s", t, it, n)
+			}
+		}
+		return TypeBool, nil
+
+	case *BetweenExpr:
+		t, err := sc.check(n.X, m)
+		if err != nil {
+			return TypeNull, err
+		}
+		for _, bound := range []Expr{n.Low, n.High} {
+			bt, err := sc.check(bound, m)
+			if err != nil {
+				return TypeNull, err
+			}
+			if !comparableTypes(t, bt) {
+				return TypeNull, fmt.Errorf("cannot compare %s and %s in %s", t, bt, n)
+			}
+		}
+		return TypeBool, nil
+
+	case *LikeExpr:
+		for _, x := range []Expr{n.X, n.Pattern} {
+			t, err := sc.check(x, m)
+			if err != nil {
+				return TypeNull, err
+			}
+			if t != TypeString && t != TypeNull {
+				return TypeNull, fmt.Errorf("LIKE requires %s arguments, got %s", TypeString, t)
+			}
+		}
+		return TypeBool, nil
+
+	case *CaseExpr:
+		return sc.checkCase(n, m)
+
+	case *CastExpr:
+		if _, err := sc.check(n.X, m); err != nil {
+			return TypeNull, err
+		}
+		return n.Type, nil
+
+	case *FuncCall:
+		return sc.checkFunc(n, m)
+	}
+	return TypeNull, fmt.Errorf("unsupported expression %s", e)
+}
+
+func (sc *scope) checkBinary(n *BinaryExpr, m checkMode) (Type, error) {
+	lt, err := sc.check(n.Left, m)
+	if err != nil {
+		return TypeNull, err
+	}
+	rt, err := sc.check(n.Right, m)
+	if err != nil {
+		return TypeNull, err
+	}
+	switch n.Op {
+	case "AND", "OR":
+		for _, t := range []Type{lt, rt} {
+			if t != TypeBool && t != TypeNull {
+				return TypeNull, fmt.Errorf("arguments of %s must be booleans, got %s", n.Op, t)
+			}
+		}
+		return TypeBool, nil
+	case "=", "<>", "<", "<=", ">", ">=":
+		if !comparableTypes(lt, rt) {
+			return TypeNull, fmt.Errorf("cannot compare %s and %s in %s", lt, rt, n)
+		}
+		return TypeBool, nil
+	case "||":
+		return TypeString, nil
+	}
+	for _, t := range []Type{lt, rt} {
+		if t != TypeNull && !t.numeric() {
+			return TypeNull, fmt.Errorf("operator %s cannot be applied to %s in %s", n.Op, t, n)
+		}
+	}
+	if n.Op == "/" {
+		return TypeFloat, nil
+	}
+	return unifyTypes(lt, rt)
+}
+
+func (sc *scope) checkCase(n *CaseExpr, m checkMode) (Type, error) {
+	operand := TypeNull
+	if n.Operand != nil {
+		var err error
+		if operand, err = sc.check(n.Operand, m); err != nil {
+			return TypeNull, err
+		}
+	}
+	result := TypeNull
+	for _, w := range n.Whens {
+		ct, err := sc.check(w.Cond, m)
+		if err != nil {
+			return TypeNull, err
+		}
+		if n.Operand != nil && !comparableTypes(operand, ct) {
+			return TypeNull, fmt.Errorf("cannot compare %s and %s in %s", operand, ct, n)
+		}
+		if n.Operand == nil && ct != TypeBool && ct != TypeNull {
+			return TypeNull, fmt.Errorf("WHEN condition must be a boolean, got %s", ct)
+		}
+		rt, err := sc.check(w.Result, m)
+		if err != nil {
+			return TypeNull, err
+		}
+		if result, err = unifyTypes(result, rt); err != nil {
+			return TypeNull, fmt.Errorf("%w in %s", err, n)
+		}
+	}
+	if n.Else != nil {
+		et, err := sc.check(n.Else, m)
+		if err != nil {
+			return TypeNull, err
+		}
+		if result, err = unifyTypes(result, et); err != nil {
+			return TypeNull, fmt.Errorf("%w in %s", err, n)
+		}
+	}
+	return result, nil
+}
+
+func (sc *scope) checkFunc(n *FuncCall, m checkMode) (Type, error) {
+	if n.Over != nil {
+		return sc.checkWindow(n, m)
+	}
+
+	if agg, ok := aggregateFuncs[n.Name]; ok {
+		if !m.allowAgg {
+			return TypeNull, fmt.Errorf("aggregate function %s is not allowed in %s", n.Name, m.clause)
+		}
+		if n.Star {
+			if n.Name != "count" {
+				return TypeNull, fmt.Errorf("%s(*) is not supported", n.Name)
+			}
+			return TypeInt, nil
+		}
+		if len(n.Args) != 1 {
+			return TypeNull, fmt.Errorf("aggregate function %s expects one argument, got %d", n.Name, len(n.Args))
+		}
+		t, err := sc.check(n.Args[0], checkMode{clause: "aggregate function arguments", rowLevel: true})
+		if err != nil {
+			return TypeNull, err
+		}
+		rt, err := agg.returnType(t)
+		if err != nil {
+			return TypeNull, fmt.Errorf("%s: %w", n.Name, err)
+		}
+		return rt, nil
+	}
+
+	if _, ok := windowFuncs[n.Name]; ok {
+		return TypeNull, fmt.Errorf("window function %s requires an OVER clause", n.Name)
+	}
+
+	fn, ok := scalarFuncs[n.Name]
+	if !ok {
+		return TypeNull, fmt.Errorf("unknown function %s", n.Name)
+	}
+	if n.Star || n.Distinct {
+		return TypeNull, fmt.Errorf("invalid arguments for function %s", n.Name)
+	}
+	if len(n.Args) < fn.minArgs || (fn.maxArgs >= 0 && len(n.Args) > fn.maxArgs) {
+		return TypeNull, fmt.Errorf("wrong number of arguments for function %s: %d", n.Name, len(n.Args))
+	}
+	types, err := sc.checkArgs(n.Args, m)
+	if err != nil {
+		return TypeNull, err
+	}
+	t, err := fn.returnType(types)
+	if err != nil {
+		return TypeNull, fmt.Errorf("%s: %w", n.Name, err)
+	}
+	return t, nil
+}
+
+func (sc *scope) checkArgs(args []Expr, m checkMode) ([]Type, error) {
+	types := make([]Type, len(args))
+	for i, a := range args {
+		var err error
+		if types[i], err = sc.check(a, m); err != nil {
+			return nil, err
+		}
+	}
+	return types, nil
+}
+
+func (sc *scope) checkWindow(n *FuncCall, m checkMode) (Type, error) {
+	if !m.allowWindow {
+		return TypeNull, fmt.Errorf("window function %s is not allowed in %s", n.Name, m.clause)
+	}
+	inner := checkMode{clause: "window function", rowLevel: m.rowLevel, allowAgg: m.allowAgg}
+	for _, p := range n.Over.PartitionBy {
+		if _, err := sc.check(p, inner); err != nil {
+			return TypeNull, err
+		}
+	}
+	for _, o := range n.Over.OrderBy {
+		if _, err := sc.check(o.Expr, inner); err != nil {
+			return TypeNull, err
+		}
+	}
+	if !containsFunc(sc.windows, n) {
+		sc.windows = append(sc.windows, n)
+	}
+
+	if agg, ok := aggregateFuncs[n.Name]; ok {
+		if n.Star {
+			if n.Name != "count" {
+				return TypeNull, fmt.Errorf("%s(*) is not supported", n.Name)
+			}
+			return TypeInt, nil
+		}
+		if len(n.Args) != 1 || n.Distinct {
+			return TypeNull, fmt.Errorf("invalid arguments for window function %s", n.Name)
+		}
+		t, err := sc.check(n.Args[0], inner)
+		if err != nil {
+			return TypeNull, err
+		}
+		rt, err := agg.returnType(t)
+		if err != nil {
+			return TypeNull, fmt.Errorf("%s: %w", n.Name, err)
+		}
+		return rt, nil
+	}
+
+	spec, ok := windowFuncs[n.Name]
+	if !ok {
+		return TypeNull, fmt.Errorf("unknown window function %s", n.Name)
+	}
+	if n.Star || n.Distinct || len(n.Args) < spec.minArgs || len(n.Args) > spec.maxArgs {
+		return TypeNull, fmt.Errorf("invalid arguments for window function %s", n.Name)
+	}
+	types, err := sc.checkArgs(n.Args, inner)
+	if err != nil {
+		return TypeNull, err
+	}
+	switch n.Name {
+	case "row_number", "rank", "dense_rank":
+		return TypeInt, nil
+	case "lag", "lead":
+		if len(types) > 1 && types[1] != TypeInt {
+			return TypeNull, fmt.Errorf("%s: offset must be an integer, got %s", n.Name, types[1])
+		}
+		if len(types) > 2 {
+			t, err := unifyTypes(types[0], types[2])
+			if err != nil {
+				return TypeNull, fmt.Errorf("%s: %w", n.Name, err)
+			}
+			return t, nil
+		}
+	}
+	return types[0], nil
+}
+
+func containsFunc(list []*FuncCall, f *FuncCall) bool {
+	for _, x := range list {
+		if x == f {
+			return true
+		}
+	}
+	return false
+}
+
+// eval evaluates a checked expression.
+func (sc *scope) eval(e Expr, rc *rowCtx) (any, error) {
+	switch n := e.(type) {
+	case *Literal:
+		return n.Value, nil
+
+	case *ColumnRef:
+		if rc.row == nil {
+			return nil, nil
+		}
+		return rc.row[sc.cols[n]], nil
+
+	case *UnaryExpr:
+		v, err := sc.eval(n.X, rc)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		switch n.Op {
+		case "NOT":
+			return !v.(bool), nil
+		case "-":
+			if i, ok := v.(int64); ok {
+				return -i, nil
+			}
+			return -v.(float64), nil
+		}
+		return v, nil
+
+	case *BinaryExpr:
+		return sc.evalBinary(n, rc)
+
+	case *IsNullExpr:
+		v, err := sc.eval(n.X, rc)
+		if err != nil {
+			return nil, err
+		}
+		return (v == nil) != n.Not, nil
+
+	case *InExpr:
+		x, err := sc.eval(n.X, rc)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		sawNull := false
+		for _, item := range n.List {
+			v, err := sc.eval(item, rc)
+			if err != nil {
+				return nil, err
+			}
+			if v == nil {
+				sawNull = true
+				continue
+			}
+			c, err := compareValues(x, v)
+			if err != nil {
+				return nil, err
+			}
+			if c == 0 {
+				return !n.Not, nil
+			}
+		}
+		if sawNull {
+			return nil, nil
+		}
+		return n.Not, nil
+
+	case *BetweenExpr:
+		x, err := sc.eval(n.X, rc)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		low, err := sc.eval(n.Low, rc)
+		if err != nil || low == nil {
+			return nil, err
+		}
+		high, err := sc.eval(n.High, rc)
+		if err != nil || high == nil {
+			return nil, err
+		}
+		cl, err := compareValues(x, low)
+		if err != nil {
+			return nil, err
+		}
+		ch, err := compareValues(x, high)
+		if err != nil {
+			return nil, err
+		}
+		return (cl >= 0 && ch <= 0) != n.Not, nil
+
+	case *LikeExpr:
+		x, err := sc.eval(n.X, rc)
+		if err != nil || x == nil {
+			return nil, err
+		}
+		pattern, err := sc.eval(n.Pattern, rc)
+		if err != nil || pattern == nil {
+			return nil, err
+		}
+		re, err := sc.likePattern(pattern.(string), n.CaseInsensitive)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(x.(string)) != n.Not, nil
+
+	case *CaseExpr:
+		return sc.evalCase(n, rc)
+
+	case *CastExpr:
+		v, err := sc.eval(n.X, rc)
+		if err != nil {
+			return nil, err
+		}
+		return convertValue(v, n.Type)
+
+	case *FuncCall:
+		return sc.evalFunc(n, rc)
+	}
+	return nil, fmt.Errorf("unsupported expression %s", e)
+}
+
+func (sc *scope) evalBinary(n *BinaryExpr, rc *rowCtx) (any, error) {
+	l, err := sc.eval(n.Left, rc)
+	if err != nil {
+		return nil, err
+	}
+
+	// AND and OR use three-valued logic and short-circuit
+	switch n.Op {
+	case "AND":
+		if l == false {
+			return false, nil
+		}
+		r, err := sc.eval(n.Right, rc)
+		if err != nil {
+			return nil, err
+		}
+		if r == false {
+			return false, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return true, nil
+	case "OR":
+		if l == true {
+			return true, nil
+		}
+		r, err := sc.eval(n.Right, rc)
+		if err != nil {
+			return nil, err
+		}
+		if r == true {
+			return true, nil
+		}
+		if l == nil || r == nil {
+			return nil, nil
+		}
+		return false, nil
+	}
+
+	r, err := sc.eval(n.Right, rc)
+	if err != nil || l == nil || r == nil {
+		return nil, err
+	}
+
+	switch n.Op {
+	case "=", "<>", "<", "<=", ">", ">=":
+		c, err := compareValues(l, r)
+		if err != nil {
+			return nil, err
+		}
+		switch n.Op {
+		case "=":
+			return c == 0, nil
+		case "<>":
+			return c != 0, nil
+		case "<":
+			return c < 0, nil
+		case "<=":
+			return c <= 0, nil
+		case ">":
+			return c > 0, nil
+		}
+		return c >= 0, nil
+	case "||":
+		ls, err := convertValue(l, TypeString)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := convertValue(r, TypeString)
+		if err != nil {
+			return nil, err
+		}
+		return ls.(string) + rs.(string), nil
+	}
+	return arithmetic(n.Op, l, r)
+}
+
+// arithmetic applies an arithmetic operator. Integer operands give integer
+// results except for division, which always gives a float. Division by zero
+// gives NULL.
+func arithmetic(op string, l, r any) (any, error) {
+	li, lok := l.(int64)
+	ri, rok := r.(int64)
+	if lok && rok && op != "/" {
+		switch op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		case "%":
+			if ri == 0 {
+				return nil, nil
+			}
+			return li % ri, nil
+		}
+	}
+	lf, lok := toFloat(l)
+	rf, rok := toFloat(r)
+	if !lok || !rok {
+		return nil, fmt.Errorf("operator %s cannot be applied to %s and %s", op, typeOfValue(l), typeOfValue(r))
+	}
+	switch op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, nil
+		}
+		return lf / rf, nil
+	case "%":
+		if rf == 0 {
+			return nil, nil
+		}
+		return math.Mod(lf, rf), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", op)
+}
+
+func (sc *scope) evalCase(n *CaseExpr, rc *rowCtx) (any, error) {
+	var operand any
+	if n.Operand != nil {
+		var err error
+		if operand, err = sc.eval(n.Operand, rc); err != nil {
+			return nil, err
+		}
+	}
+	for _, w := range n.Whens {
+		cond, err := sc.eval(w.Cond, rc)
+		if err != nil {
+			return nil, err
+		}
+		match := cond == true
+		if n.Operand != nil {
+			match = false
+			if operand != nil && cond != nil {
+				c, err := compareValues(operand, cond)
+				if err != nil {
+					return nil, err
+				}
+				match = c == 0
+			}
+		}
+		if match {
+			return sc.eval(w.Result, rc)
+		}
+	}
+	if n.Else != nil {
+		return sc.eval(n.Else, rc)
+	}
+	return nil, nil
+}
+
+func (sc *scope) evalFunc(n *FuncCall, rc *rowCtx) (any, error) {
+	if n.Over != nil {
+		return rc.window[n], nil
+	}
+	if isAggregate(n) {
+		agg := newAggregator(n)
+		for _, row := range rc.group {
+			v, err := sc.aggregateArg(n, &rowCtx{row: row})
+			if err != nil {
+				return nil, err
+			}
+			if err := agg.add(v); err != nil {
+				return nil, err
+			}
+		}
+		return agg.result(), nil
+	}
+
+	fn := scalarFuncs[n.Name]
+	args := make([]any, len(n.Args))
+	for i, a := range n.Args {
+		v, err := sc.eval(a, rc)
+		if err != nil {
+			return nil, err
+		}
+		if v == nil && !fn.nullSafe {
+			return nil, nil
+		}
+		args[i] = v
+	}
+	return fn.eval(args)
+}
+
+// aggregateArg evaluates the argument of an aggregate function. count(*)
+// counts every row, so it gets a non-NULL placeholder.
+func (sc *scope) aggregateArg(n *FuncCall, rc *rowCtx) (any, error) {
+	if n.Star {
+		return true, nil
+	}
+	return sc.eval(n.Args[0], rc)
+}
+
+func newAggregator(n *FuncCall) aggregator {
+	agg := aggregateFuncs[n.Name].new()
+	if n.Distinct {
+		return &distinctAgg{aggregator: agg, seen: map[string]bool{}}
+	}
+	return agg
+}
+
+// likePattern compiles a LIKE pattern, where % matches any sequence of
+// characters and _ matches a single character.
+func (sc *scope) likePattern(pattern string, caseInsensitive bool) (*regexp.Regexp, error) {
+	key := "s:" + pattern
+	if caseInsensitive {
+		key = "i:" + pattern
+	}
+	if re, ok := sc.patterns[key]; ok {
+		return re, nil
+	}
+	var sb strings.Builder
+	if caseInsensitive {
+		sb.WriteString("(?i)")
+	}
+	sb.WriteString("^(?s:")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			sb.WriteString(".*")
+		case '_':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	sb.WriteString(")$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, err
+	}
+	sc.patterns[key] = re
+	return re, nil
+}
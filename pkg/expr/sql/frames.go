@@ -0,0 +1,275 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// QueryFrames runs rawSQL over the frames of the inputs and returns the
+// result as a frame with the given name. Every input is a table named after
+// its key, usually the refID of a query or expression.
+func (e *Engine) QueryFrames(ctx context.Context, name, rawSQL string, inputs map[string][]*data.Frame) (*data.Frame, error) {
+	stmt, err := Parse(rawSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(inputs))
+	for n := range inputs {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	tables := make([]*Table, 0, len(names))
+	for _, n := range names {
+		t, err := FramesToTable(n, inputs[n])
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+
+	result, err := e.Execute(ctx, stmt, tables)
+	if err != nil {
+		return nil, err
+	}
+	return TableToFrame(name, result), nil
+}
+
+// FramesToTable converts frames to a single table. Fields become columns by
+// name and the labels of a field become string columns, so the series of a
+// multi-frame result are combined into one table in long format. Fields of a
+// frame that have different values for the same label keep their labels in
+// the column name instead. A label is dropped when a field with the same name
+// exists.
+func FramesToTable(name string, frames []*data.Frame) (*Table, error) {
+	t := &Table{Name: name}
+	columns := map[string]*Column{}
+	rows := 0
+
+	column := func(colName string, typ Type) (*Column, error) {
+		c, ok := columns[colName]
+		if !ok {
+			c = &Column{Name: colName, Type: typ, Values: make([]any, rows)}
+			columns[colName] = c
+			t.Columns = append(t.Columns, c)
+			return c, nil
+		}
+		unified, err := unifyTypes(c.Type, typ)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: column %s: %w", name, colName, err)
+		}
+		if unified != c.Type {
+			for i, v := range c.Values {
+				if c.Values[i], err = convertValue(v, unified); err != nil {
+					return nil, err
+				}
+			}
+			c.Type = unified
+		}
+		return c, nil
+	}
+
+	for _, frame := range frames {
+		if frame == nil {
+			continue
+		}
+		n := frame.Rows()
+		labels, labelsInNames := frameLabels(frame)
+
+		for _, f := range frame.Fields {
+			typ, err := fieldType(f)
+			if err != nil {
+				return nil, fmt.Errorf("table %s: field %s: %w", name, f.Name, err)
+			}
+			colName := f.Name
+			if labelsInNames && len(f.Labels) > 0 {
+				colName = f.Name + " " + f.Labels.String()
+			}
+			c, err := column(colName, typ)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i < n; i++ {
+				v, err := fieldValue(f, i)
+				if err != nil {
+					return nil, fmt.Errorf("table %s: field %s: %w", name, f.Name, err)
+				}
+				if v != nil && c.Type != typ {
+					if v, err = convertValue(v, c.Type); err != nil {
+						return nil, err
+					}
+				}
+				c.Values = append(c.Values, v)
+			}
+		}
+
+		for _, key := range labels.keys() {
+			if hasField(frame, key) {
+				continue
+			}
+			c, err := column(key, TypeString)
+			if err != nil {
+				return nil, err
+			}
+			value := labels[key]
+			for i := 0; i < n; i++ {
+				c.Values = append(c.Values, value)
+			}
+		}
+
+		rows += n
+		// columns that are not part of this frame are NULL for its rows
+		for _, c := range t.Columns {
+			for len(c.Values) < rows {
+				c.Values = append(c.Values, nil)
+			}
+		}
+	}
+	return t, nil
+}
+
+type frameLabelSet map[string]string
+
+func (l frameLabelSet) keys() []string {
+	keys := make([]string, 0, len(l))
+	for k := range l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// frameLabels merges the labels of the fields of a frame. When fields have
+// different values for the same label the labels can't be represented as
+// columns and the second return value is true.
+func frameLabels(frame *data.Frame) (frameLabelSet, bool) {
+	merged := frameLabelSet{}
+	for _, f := range frame.Fields {
+		for k, v := range f.Labels {
+			if existing, ok := merged[k]; ok && existing != v {
+				return nil, true
+			}
+			merged[k] = v
+		}
+	}
+	return merged, false
+}
+
+func hasField(frame *data.Frame, name string) bool {
+	for _, f := range frame.Fields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldType maps the type of a field to a column type.
+func fieldType(f *data.Field) (Type, error) {
+	switch f.Type() {
+	case data.FieldTypeInt8, data.FieldTypeNullableInt8,
+		data.FieldTypeInt16, data.FieldTypeNullableInt16,
+		data.FieldTypeInt32, data.FieldTypeNullableInt32,
+		data.FieldTypeInt64, data.FieldTypeNullableInt64,
+		data.FieldTypeUint8, data.FieldTypeNullableUint8,
+		data.FieldTypeUint16, data.FieldTypeNullableUint16,
+		data.FieldTypeUint32, data.FieldTypeNullableUint32,
+		data.FieldTypeUint64, data.FieldTypeNullableUint64:
+		return TypeInt, nil
+	case data.FieldTypeFloat32, data.FieldTypeNullableFloat32,
+		data.FieldTypeFloat64, data.FieldTypeNullableFloat64:
+		return TypeFloat, nil
+	case data.FieldTypeString, data.FieldTypeNullableString,
+		data.FieldTypeJSON, data.FieldTypeNullableJSON:
+		return TypeString, nil
+	case data.FieldTypeBool, data.FieldTypeNullableBool:
+		return TypeBool, nil
+	case data.FieldTypeTime, data.FieldTypeNullableTime:
+		return TypeTime, nil
+	}
+	return TypeNull, fmt.Errorf("unsupported field type %s", f.Type())
+}
+
+// fieldValue returns the value at index i of a field as one of the value
+// types of the engine.
+func fieldValue(f *data.Field, i int) (any, error) {
+	v, ok := f.ConcreteAt(i)
+	if !ok {
+		return nil, nil
+	}
+	switch x := v.(type) {
+	case int8:
+		return int64(x), nil
+	case int16:
+		return int64(x), nil
+	case int32:
+		return int64(x), nil
+	case int64:
+		return x, nil
+	case uint8:
+		return int64(x), nil
+	case uint16:
+		return int64(x), nil
+	case uint32:
+		return int64(x), nil
+	case uint64:
+		if x > math.MaxInt64 {
+			return nil, fmt.Errorf("value %d is out of range for %s", x, TypeInt)
+		}
+		return int64(x), nil
+	case float32:
+		return float64(x), nil
+	case float64, string, bool, time.Time:
+		return x, nil
+	case json.RawMessage:
+		return string(x), nil
+	}
+	return nil, fmt.Errorf("unsupported value type %T", v)
+}
+
+// TableToFrame converts a table to a frame. All fields are nullable, so the
+// field types only depend on the query and not on the returned data.
+func TableToFrame(name string, t *Table) *data.Frame {
+	fields := make([]*data.Field, len(t.Columns))
+	for i, c := range t.Columns {
+		f := data.NewFieldFromFieldType(frameFieldType(c.Type), len(c.Values))
+		f.Name = c.Name
+		for j, v := range c.Values {
+			switch x := v.(type) {
+			case bool:
+				f.Set(j, &x)
+			case int64:
+				f.Set(j, &x)
+			case float64:
+				f.Set(j, &x)
+			case string:
+				f.Set(j, &x)
+			case time.Time:
+				f.Set(j, &x)
+			}
+		}
+		fields[i] = f
+	}
+	return data.NewFrame(name, fields...)
+}
+
+func frameFieldType(t Type) data.FieldType {
+	switch t {
+	case TypeBool:
+		return data.FieldTypeNullableBool
+	case TypeInt:
+		return data.FieldTypeNullableInt64
+	case TypeString:
+		return data.FieldTypeNullableString
+	case TypeTime:
+		return data.FieldTypeNullableTime
+	}
+	return data.FieldTypeNullableFloat64
+}
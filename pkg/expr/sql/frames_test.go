@@ -0,0 +1,87 @@
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFramesToTable(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("series are combined in long format with labels as columns", func(t *testing.T) {
+		frames := []*data.Frame{
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t0, t0.Add(time.Minute)}),
+				data.NewField("value", data.Labels{"host": "a"}, []float64{1, 2}),
+			),
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t0}),
+				data.NewField("value", data.Labels{"host": "b"}, []*int64{nil}),
+			),
+		}
+		table, err := FramesToTable("A", frames)
+		require.NoError(t, err)
+		require.Equal(t, &Table{
+			Name: "A",
+			Columns: []*Column{
+				{Name: "time", Type: TypeTime, Values: []any{t0, t0.Add(time.Minute), t0}},
+				{Name: "value", Type: TypeFloat, Values: []any{1.0, 2.0, nil}},
+				{Name: "host", Type: TypeString, Values: []any{"a", "a", "b"}},
+			},
+		}, table)
+	})
+
+	t.Run("conflicting labels are kept in the column names", func(t *testing.T) {
+		frames := []*data.Frame{
+			data.NewFrame("",
+				data.NewField("a", data.Labels{"host": "a"}, []int64{1}),
+				data.NewField("b", data.Labels{"host": "b"}, []int64{2}),
+			),
+		}
+		table, err := FramesToTable("A", frames)
+		require.NoError(t, err)
+		require.Equal(t, "a {host=a}", table.Columns[0].Name)
+		require.Equal(t, "b {host=b}", table.Columns[1].Name)
+		require.Len(t, table.Columns, 2)
+	})
+
+	t.Run("incompatible types", func(t *testing.T) {
+		frames := []*data.Frame{
+			data.NewFrame("", data.NewField("value", nil, []float64{1})),
+			data.NewFrame("", data.NewField("value", nil, []string{"x"})),
+		}
+		_, err := FramesToTable("A", frames)
+		require.Error(t, err)
+	})
+}
+
+func TestQueryFrames(t *testing.T) {
+	inputs := map[string][]*data.Frame{
+		"A": {data.NewFrame("",
+			data.NewField("host", nil, []string{"a", "b", "a"}),
+			data.NewField("value", nil, []uint8{1, 2, 3}),
+		)},
+		"B": {data.NewFrame("",
+			data.NewField("host", nil, []string{"a"}),
+			data.NewField("weight", nil, []float32{0.5}),
+		)},
+	}
+
+	frame, err := NewEngine(DefaultLimits()).QueryFrames(context.Background(), "C",
+		`SELECT A.host, sum(value) AS total, sum(value * weight) AS weighted FROM A LEFT JOIN B ON A.host = B.host GROUP BY A.host ORDER BY 1`, inputs)
+	require.NoError(t, err)
+
+	s := func(v string) *string { return &v }
+	i := func(v int64) *int64 { return &v }
+	f := func(v float64) *float64 { return &v }
+	expected := data.NewFrame("C",
+		data.NewField("host", nil, []*string{s("a"), s("b")}),
+		data.NewField("total", nil, []*int64{i(4), i(2)}),
+		data.NewField("weighted", nil, []*float64{f(2), nil}),
+	)
+	require.Equal(t, expected, frame)
+}
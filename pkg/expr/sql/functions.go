@@ -0,0 +1,551 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// scalarFunc is a function that is evaluated for every row.
+type scalarFunc struct {
+	minArgs int
+	maxArgs int // -1 for variadic functions
+	// nullSafe functions are called with NULL arguments, for all other
+	// functions a NULL argument makes the result NULL.
+	nullSafe   bool
+	returnType func(args []Type) (Type, error)
+	eval       func(args []any) (any, error)
+}
+
+var scalarFuncs = map[string]*scalarFunc{
+	"abs": {minArgs: 1, maxArgs: 1, returnType: sameNumeric, eval: func(args []any) (any, error) {
+		if i, ok := args[0].(int64); ok {
+			if i < 0 {
+				return -i, nil
+			}
+			return i, nil
+		}
+		return math.Abs(args[0].(float64)), nil
+	}},
+	"ceil":    {minArgs: 1, maxArgs: 1, returnType: sameNumeric, eval: roundingFunc(math.Ceil)},
+	"ceiling": {minArgs: 1, maxArgs: 1, returnType: sameNumeric, eval: roundingFunc(math.Ceil)},
+	"floor":   {minArgs: 1, maxArgs: 1, returnType: sameNumeric, eval: roundingFunc(math.Floor)},
+	"round": {minArgs: 1, maxArgs: 2, returnType: roundType, eval: func(args []any) (any, error) {
+		f, ok := args[0].(float64)
+		if !ok {
+			return args[0], nil
+		}
+		if len(args) == 1 {
+			return math.Round(f), nil
+		}
+		p := math.Pow(10, float64(args[1].(int64)))
+		return math.Round(f*p) / p, nil
+	}},
+	"sqrt":  {minArgs: 1, maxArgs: 1, returnType: floatResult, eval: floatFunc(math.Sqrt)},
+	"exp":   {minArgs: 1, maxArgs: 1, returnType: floatResult, eval: floatFunc(math.Exp)},
+	"ln":    {minArgs: 1, maxArgs: 1, returnType: floatResult, eval: floatFunc(math.Log)},
+	"log10": {minArgs: 1, maxArgs: 1, returnType: floatResult, eval: floatFunc(math.Log10)},
+	"log2":  {minArgs: 1, maxArgs: 1, returnType: floatResult, eval: floatFunc(math.Log2)},
+	"power": {minArgs: 2, maxArgs: 2, returnType: floatResult, eval: powFunc},
+	"pow":   {minArgs: 2, maxArgs: 2, returnType: floatResult, eval: powFunc},
+	"coalesce": {minArgs: 1, maxArgs: -1, nullSafe: true, returnType: unifiedResult, eval: func(args []any) (any, error) {
+		for _, a := range args {
+			if a != nil {
+				return a, nil
+			}
+		}
+		return nil, nil
+	}},
+	"nullif": {minArgs: 2, maxArgs: 2, nullSafe: true, returnType: func(args []Type) (Type, error) {
+		if !comparableTypes(args[0], args[1]) {
+			return TypeNull, fmt.Errorf("cannot compare %s and %s", args[0], args[1])
+		}
+		return args[0], nil
+	}, eval: func(args []any) (any, error) {
+		if args[0] == nil || args[1] == nil {
+			return args[0], nil
+		}
+		c, err := compareValues(args[0], args[1])
+		if err != nil || c != 0 {
+			return args[0], err
+		}
+		return nil, nil
+	}},
+	"greatest": {minArgs: 1, maxArgs: -1, nullSafe: true, returnType: unifiedResult, eval: extremum(1)},
+	"least":    {minArgs: 1, maxArgs: -1, nullSafe: true, returnType: unifiedResult, eval: extremum(-1)},
+	"lower":    {minArgs: 1, maxArgs: 1, returnType: stringArgs(TypeString), eval: stringFunc(strings.ToLower)},
+	"upper":    {minArgs: 1, maxArgs: 1, returnType: stringArgs(TypeString), eval: stringFunc(strings.ToUpper)},
+	"trim":     {minArgs: 1, maxArgs: 1, returnType: stringArgs(TypeString), eval: stringFunc(strings.TrimSpace)},
+	"ltrim": {minArgs: 1, maxArgs: 1, returnType: stringArgs(TypeString), eval: stringFunc(func(s string) string {
+		return strings.TrimLeftFunc(s, isSpace)
+	})},
+	"rtrim": {minArgs: 1, maxArgs: 1, returnType: stringArgs(TypeString), eval: stringFunc(func(s string) string {
+		return strings.TrimRightFunc(s, isSpace)
+	})},
+	"length": {minArgs: 1, maxArgs: 1, returnType: stringArgs(TypeInt), eval: func(args []any) (any, error) {
+		return int64(utf8.RuneCountInString(args[0].(string))), nil
+	}},
+	"replace": {minArgs: 3, maxArgs: 3, returnType: stringArgs(TypeString), eval: func(args []any) (any, error) {
+		return strings.ReplaceAll(args[0].(string), args[1].(string), args[2].(string)), nil
+	}},
+	"substr":    {minArgs: 2, maxArgs: 3, returnType: substrType, eval: substr},
+	"substring": {minArgs: 2, maxArgs: 3, returnType: substrType, eval: substr},
+	"concat": {minArgs: 1, maxArgs: -1, nullSafe: true, returnType: fixedResult(TypeString), eval: func(args []any) (any, error) {
+		var sb strings.Builder
+		for _, a := range args {
+			if a == nil {
+				continue
+			}
+			s, err := convertValue(a, TypeString)
+			if err != nil {
+				return nil, err
+			}
+			sb.WriteString(s.(string))
+		}
+		return sb.String(), nil
+	}},
+	"date_trunc": {minArgs: 2, maxArgs: 2, returnType: argTypes(TypeTime, TypeString, TypeTime), eval: dateTrunc},
+	"time_bucket": {minArgs: 2, maxArgs: 2, returnType: argTypes(TypeTime, TypeString, TypeTime), eval: func(args []any) (any, error) {
+		d, err := time.ParseDuration(args[0].(string))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid bucket interval '%s'", args[0])
+		}
+		return args[1].(time.Time).UTC().Truncate(d), nil
+	}},
+	"epoch": {minArgs: 1, maxArgs: 1, returnType: argTypes(TypeFloat, TypeTime), eval: func(args []any) (any, error) {
+		return float64(args[0].(time.Time).UnixNano()) / float64(time.Second), nil
+	}},
+	"epoch_ms": {minArgs: 1, maxArgs: 1, returnType: argTypes(TypeInt, TypeTime), eval: func(args []any) (any, error) {
+		return args[0].(time.Time).UnixMilli(), nil
+	}},
+	"to_timestamp": {minArgs: 1, maxArgs: 1, returnType: func(args []Type) (Type, error) {
+		if err := expectNumeric(args[0]); err != nil {
+			return TypeNull, err
+		}
+		return TypeTime, nil
+	}, eval: func(args []any) (any, error) {
+		f, _ := toFloat(args[0])
+		return time.Unix(0, int64(f*float64(time.Second))).UTC(), nil
+	}},
+}
+
+func expectNumeric(t Type) error {
+	if t != TypeNull && !t.numeric() {
+		return fmt.Errorf("expected a numeric argument, got %s", t)
+	}
+	return nil
+}
+
+func sameNumeric(args []Type) (Type, error) {
+	if err := expectNumeric(args[0]); err != nil {
+		return TypeNull, err
+	}
+	if args[0] == TypeNull {
+		return TypeFloat, nil
+	}
+	return args[0], nil
+}
+
+func roundType(args []Type) (Type, error) {
+	if len(args) == 2 && args[1] != TypeInt && args[1] != TypeNull {
+		return TypeNull, fmt.Errorf("expected an integer number of decimal places, got %s", args[1])
+	}
+	return sameNumeric(args)
+}
+
+func floatResult(args []Type) (Type, error) {
+	for _, a := range args {
+		if err := expectNumeric(a); err != nil {
+			return TypeNull, err
+		}
+	}
+	return TypeFloat, nil
+}
+
+func fixedResult(t Type) func([]Type) (Type, error) {
+	return func([]Type) (Type, error) {
+		return t, nil
+	}
+}
+
+func unifiedResult(args []Type) (Type, error) {
+	result := TypeNull
+	for _, a := range args {
+		var err error
+		if result, err = unifyTypes(result, a); err != nil {
+			return TypeNull, err
+		}
+	}
+	return result, nil
+}
+
+// stringArgs requires all arguments to be strings.
+func stringArgs(result Type) func([]Type) (Type, error) {
+	return func(args []Type) (Type, error) {
+		for _, a := range args {
+			if a != TypeString && a != TypeNull {
+				return TypeNull, fmt.Errorf("expected a %s argument, got %s", TypeString, a)
+			}
+		}
+		return result, nil
+	}
+}
+
+// argTypes requires the arguments to have the given types.
+func argTypes(result Type, expected ...Type) func([]Type) (Type, error) {
+	return func(args []Type) (Type, error) {
+		for i, a := range args {
+			if a != expected[i] && a != TypeNull {
+				return TypeNull, fmt.Errorf("expected a %s argument, got %s", expected[i], a)
+			}
+		}
+		return result, nil
+	}
+}
+
+func substrType(args []Type) (Type, error) {
+	if args[0] != TypeString && args[0] != TypeNull {
+		return TypeNull, fmt.Errorf("expected a %s argument, got %s", TypeString, args[0])
+	}
+	for _, a := range args[1:] {
+		if a != TypeInt && a != TypeNull {
+			return TypeNull, fmt.Errorf("expected an integer argument, got %s", a)
+		}
+	}
+	return TypeString, nil
+}
+
+func roundingFunc(fn func(float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		if f, ok := args[0].(float64); ok {
+			return fn(f), nil
+		}
+		return args[0], nil
+	}
+}
+
+func floatFunc(fn func(float64) float64) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		f, _ := toFloat(args[0])
+		return fn(f), nil
+	}
+}
+
+func powFunc(args []any) (any, error) {
+	x, _ := toFloat(args[0])
+	y, _ := toFloat(args[1])
+	return math.Pow(x, y), nil
+}
+
+func stringFunc(fn func(string) string) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		return fn(args[0].(string)), nil
+	}
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
+
+// extremum returns the greatest (sign 1) or least (sign -1) non-NULL argument.
+func extremum(sign int) func([]any) (any, error) {
+	return func(args []any) (any, error) {
+		var result any
+		for _, a := range args {
+			if a == nil {
+				continue
+			}
+			if result == nil {
+				result = a
+				continue
+			}
+			c, err := compareValues(a, result)
+			if err != nil {
+				return nil, err
+			}
+			if c*sign > 0 {
+				result = a
+			}
+		}
+		return result, nil
+	}
+}
+
+// substr returns the substring starting at the 1-based character position
+// start with an optional length.
+func substr(args []any) (any, error) {
+	runes := []rune(args[0].(string))
+	start := args[1].(int64) - 1
+	end := int64(len(runes))
+	if len(args) == 3 {
+		if args[2].(int64) < 0 {
+			return nil, fmt.Errorf("negative substring length")
+		}
+		end = start + args[2].(int64)
+	}
+	start = max(start, 0)
+	end = min(end, int64(len(runes)))
+	if start >= end {
+		return "", nil
+	}
+	return string(runes[start:end]), nil
+}
+
+func dateTrunc(args []any) (any, error) {
+	t := args[1].(time.Time).UTC()
+	switch strings.ToLower(args[0].(string)) {
+	case "second":
+		return t.Truncate(time.Second), nil
+	case "minute":
+		return t.Truncate(time.Minute), nil
+	case "hour":
+		return t.Truncate(time.Hour), nil
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	case "week":
+		// weeks start on Monday
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC), nil
+	case "month":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case "year":
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+	return nil, fmt.Errorf("unsupported date_trunc unit '%s'", args[0])
+}
+
+// aggregator accumulates the values of an aggregate function.
+type aggregator interface {
+	add(v any) error
+	result() any
+}
+
+// aggregateFunc is a function that is evaluated over a group of rows or a
+// window frame.
+type aggregateFunc struct {
+	returnType func(arg Type) (Type, error)
+	new        func() aggregator
+}
+
+var aggregateFuncs = map[string]*aggregateFunc{
+	"count":       {returnType: func(Type) (Type, error) { return TypeInt, nil }, new: func() aggregator { return &countAgg{} }},
+	"sum":         {returnType: sameNumeric1, new: func() aggregator { return &sumAgg{} }},
+	"avg":         {returnType: floatResult1, new: func() aggregator { return &avgAgg{} }},
+	"min":         {returnType: anyResult1, new: func() aggregator { return &extremumAgg{sign: -1} }},
+	"max":         {returnType: anyResult1, new: func() aggregator { return &extremumAgg{sign: 1} }},
+	"stddev":      {returnType: floatResult1, new: func() aggregator { return &varianceAgg{sample: true, sqrt: true} }},
+	"stddev_samp": {returnType: floatResult1, new: func() aggregator { return &varianceAgg{sample: true, sqrt: true} }},
+	"stddev_pop":  {returnType: floatResult1, new: func() aggregator { return &varianceAgg{sqrt: true} }},
+	"variance":    {returnType: floatResult1, new: func() aggregator { return &varianceAgg{sample: true} }},
+	"var_samp":    {returnType: floatResult1, new: func() aggregator { return &varianceAgg{sample: true} }},
+	"var_pop":     {returnType: floatResult1, new: func() aggregator { return &varianceAgg{} }},
+	"median":      {returnType: floatResult1, new: func() aggregator { return &medianAgg{} }},
+}
+
+func sameNumeric1(t Type) (Type, error) {
+	return sameNumeric([]Type{t})
+}
+
+func floatResult1(t Type) (Type, error) {
+	return floatResult([]Type{t})
+}
+
+func anyResult1(t Type) (Type, error) {
+	return t, nil
+}
+
+type countAgg struct {
+	n int64
+}
+
+func (a *countAgg) add(v any) error {
+	if v != nil {
+		a.n++
+	}
+	return nil
+}
+
+func (a *countAgg) result() any {
+	return a.n
+}
+
+// sumAgg sums integers as int64 until the first float is added.
+type sumAgg struct {
+	seen    bool
+	isFloat bool
+	i       int64
+	f       float64
+}
+
+func (a *sumAgg) add(v any) error {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case int64:
+		a.i += x
+	case float64:
+		a.isFloat = true
+		a.f += x
+	}
+	a.seen = true
+	return nil
+}
+
+func (a *sumAgg) result() any {
+	switch {
+	case !a.seen:
+		return nil
+	case a.isFloat:
+		return a.f + float64(a.i)
+	}
+	return a.i
+}
+
+type avgAgg struct {
+	n   int64
+	sum float64
+}
+
+func (a *avgAgg) add(v any) error {
+	if f, ok := toFloat(v); ok {
+		a.n++
+		a.sum += f
+	}
+	return nil
+}
+
+func (a *avgAgg) result() any {
+	if a.n == 0 {
+		return nil
+	}
+	return a.sum / float64(a.n)
+}
+
+type extremumAgg struct {
+	sign  int
+	value any
+}
+
+func (a *extremumAgg) add(v any) error {
+	if v == nil {
+		return nil
+	}
+	if a.value == nil {
+		a.value = v
+		return nil
+	}
+	c, err := compareValues(v, a.value)
+	if err != nil {
+		return err
+	}
+	if c*a.sign > 0 {
+		a.value = v
+	}
+	return nil
+}
+
+func (a *extremumAgg) result() any {
+	return a.value
+}
+
+// varianceAgg computes the variance with Welford's online algorithm.
+type varianceAgg struct {
+	sample bool
+	sqrt   bool
+	n      int64
+	mean   float64
+	m2     float64
+}
+
+func (a *varianceAgg) add(v any) error {
+	if f, ok := toFloat(v); ok {
+		a.n++
+		delta := f - a.mean
+		a.mean += delta / float64(a.n)
+		a.m2 += delta * (f - a.mean)
+	}
+	return nil
+}
+
+func (a *varianceAgg) result() any {
+	d := a.n
+	if a.sample {
+		d--
+	}
+	if d <= 0 {
+		return nil
+	}
+	variance := a.m2 / float64(d)
+	if a.sqrt {
+		return math.Sqrt(variance)
+	}
+	return variance
+}
+
+type medianAgg struct {
+	values []float64
+}
+
+func (a *medianAgg) add(v any) error {
+	if f, ok := toFloat(v); ok {
+		a.values = append(a.values, f)
+	}
+	return nil
+}
+
+func (a *medianAgg) result() any {
+	n := len(a.values)
+	if n == 0 {
+		return nil
+	}
+	sorted := make([]float64, n)
+	copy(sorted, a.values)
+	sort.Float64s(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// distinctAgg only passes the first occurrence of each value to the wrapped
+// aggregator.
+type distinctAgg struct {
+	aggregator
+	seen map[string]bool
+}
+
+func (a *distinctAgg) add(v any) error {
+	if v == nil {
+		return nil
+	}
+	var sb strings.Builder
+	valueKey(&sb, v)
+	if a.seen[sb.String()] {
+		return nil
+	}
+	a.seen[sb.String()] = true
+	return a.aggregator.add(v)
+}
+
+// windowFuncs are the functions that can only be used with an OVER clause.
+// Aggregate functions can be used as window functions too.
+var windowFuncs = map[string]struct {
+	minArgs int
+	maxArgs int
+}{
+	"row_number":  {0, 0},
+	"rank":        {0, 0},
+	"dense_rank":  {0, 0},
+	"lag":         {1, 3},
+	"lead":        {1, 3},
+	"first_value": {1, 1},
+	"last_value":  {1, 1},
+}
+
+func isAggregate(f *FuncCall) bool {
+	_, ok := aggregateFuncs[f.Name]
+	return ok && f.Over == nil
+}
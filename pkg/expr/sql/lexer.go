@@ -0,0 +1,202 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenType int
+
+const (
+	tokEOF tokenType = iota
+	tokIdent
+	tokKeyword
+	tokNumber
+	tokString
+	tokOperator
+	tokComma
+	tokDot
+	tokLeftParen
+	tokRightParen
+	tokSemicolon
+)
+
+// token is a lexical token of a SQL statement.
+type token struct {
+	typ    tokenType
+	pos    int    // byte offset of the token in the statement
+	val    string // raw value; upper-cased for keywords, unquoted for identifiers and strings
+	quoted bool   // identifier was quoted and must not be matched as a keyword
+}
+
+func (t token) String() string {
+	switch t.typ {
+	case tokEOF:
+		return "end of input"
+	case tokString:
+		return fmt.Sprintf("string '%s'", t.val)
+	case tokIdent:
+		return fmt.Sprintf("identifier %q", t.val)
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// keywords are the reserved words which cannot be used as unquoted
+// identifiers. Words that only have a meaning in a specific position, such as
+// PARTITION or PRECEDING, are lexed as identifiers and matched by the parser.
+var keywords = map[string]bool{
+	"ALL": true, "AND": true, "AS": true, "ASC": true, "BETWEEN": true, "BY": true,
+	"CASE": true, "CAST": true, "CROSS": true, "DESC": true, "DISTINCT": true,
+	"ELSE": true, "END": true, "FALSE": true, "FROM": true, "FULL": true,
+	"GROUP": true, "HAVING": true, "ILIKE": true, "IN": true, "INNER": true,
+	"IS": true, "JOIN": true, "LEFT": true, "LIKE": true, "LIMIT": true,
+	"NOT": true, "NULL": true, "OFFSET": true, "ON": true, "OR": true,
+	"ORDER": true, "OUTER": true, "RIGHT": true, "SELECT": true, "THEN": true,
+	"TRUE": true, "USING": true, "WHEN": true, "WHERE": true, "WITH": true,
+}
+
+// operators lists the multi-character operators before their prefixes so
+// that the longest match wins.
+var operators = []string{"<>", "!=", "<=", ">=", "==", "||", "::", "=", "<", ">", "+", "-", "*", "/", "%"}
+
+// lex splits the statement into tokens.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	pos := 0
+	for pos < len(input) {
+		r, width := utf8.DecodeRuneInString(input[pos:])
+		switch {
+		case unicode.IsSpace(r):
+			pos += width
+		case strings.HasPrefix(input[pos:], "--"):
+			end := strings.IndexByte(input[pos:], '\n')
+			if end < 0 {
+				pos = len(input)
+			} else {
+				pos += end + 1
+			}
+		case strings.HasPrefix(input[pos:], "/*"):
+			end := strings.Index(input[pos+2:], "*/")
+			if end < 0 {
+				return nil, &ParseError{Pos: pos, Msg: "unterminated comment"}
+			}
+			pos += end + 4
+		case r == '\'':
+			s, next, err := lexQuoted(input, pos, '\'')
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokString, pos: pos, val: s})
+			pos = next
+		case r == '"' || r == '`':
+			s, next, err := lexQuoted(input, pos, byte(r))
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{typ: tokIdent, pos: pos, val: s, quoted: true})
+			pos = next
+		case isDigit(r) || (r == '.' && pos+1 < len(input) && isDigit(rune(input[pos+1]))):
+			next := lexNumber(input, pos)
+			tokens = append(tokens, token{typ: tokNumber, pos: pos, val: input[pos:next]})
+			pos = next
+		case r == '_' || unicode.IsLetter(r):
+			next := pos
+			for next < len(input) {
+				r, w := utf8.DecodeRuneInString(input[next:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				next += w
+			}
+			word := input[pos:next]
+			if upper := strings.ToUpper(word); keywords[upper] {
+				tokens = append(tokens, token{typ: tokKeyword, pos: pos, val: upper})
+			} else {
+				tokens = append(tokens, token{typ: tokIdent, pos: pos, val: word})
+			}
+			pos = next
+		case r == ',':
+			tokens = append(tokens, token{typ: tokComma, pos: pos, val: ","})
+			pos++
+		case r == '.':
+			tokens = append(tokens, token{typ: tokDot, pos: pos, val: "."})
+			pos++
+		case r == '(':
+			tokens = append(tokens, token{typ: tokLeftParen, pos: pos, val: "("})
+			pos++
+		case r == ')':
+			tokens = append(tokens, token{typ: tokRightParen, pos: pos, val: ")"})
+			pos++
+		case r == ';':
+			tokens = append(tokens, token{typ: tokSemicolon, pos: pos, val: ";"})
+			pos++
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(input[pos:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, &ParseError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", r)}
+			}
+			tokens = append(tokens, token{typ: tokOperator, pos: pos, val: op})
+			pos += len(op)
+		}
+	}
+	return append(tokens, token{typ: tokEOF, pos: len(input)}), nil
+}
+
+// lexQuoted reads a quoted string or identifier starting at pos. A doubled
+// quote character escapes the quote.
+func lexQuoted(input string, pos int, quote byte) (string, int, error) {
+	var sb strings.Builder
+	i := pos + 1
+	for i < len(input) {
+		if input[i] == quote {
+			if i+1 < len(input) && input[i+1] == quote {
+				sb.WriteByte(quote)
+				i += 2
+				continue
+			}
+			return sb.String(), i + 1, nil
+		}
+		sb.WriteByte(input[i])
+		i++
+	}
+	return "", 0, &ParseError{Pos: pos, Msg: "unterminated quoted string"}
+}
+
+// lexNumber returns the end offset of the numeric literal starting at pos.
+func lexNumber(input string, pos int) int {
+	i := pos
+	for i < len(input) && isDigit(rune(input[i])) {
+		i++
+	}
+	if i < len(input) && input[i] == '.' {
+		i++
+		for i < len(input) && isDigit(rune(input[i])) {
+			i++
+		}
+	}
+	if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+		j := i + 1
+		if j < len(input) && (input[j] == '+' || input[j] == '-') {
+			j++
+		}
+		if j < len(input) && isDigit(rune(input[j])) {
+			i = j
+			for i < len(input) && isDigit(rune(input[i])) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
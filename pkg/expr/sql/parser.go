@@ -1,90 +1,961 @@
 package sql
 
 import (
-	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/log"
-	"github.com/jeremywohl/flatten"
-	"github.com/scottlepp/go-duck/duck"
-)
-
-const (
-	TABLE_NAME    = "table_name"
-	ERROR         = ".error"
-	ERROR_MESSAGE = ".error_message"
 )
 
 var logger = log.New("sql_expr")
 
+// ParseError is returned when a SQL statement is not valid.
+type ParseError struct {
+	Pos int // byte offset of the error in the statement
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Pos+1, e.Msg)
+}
+
 // TablesList returns a list of tables for the sql statement
 func TablesList(rawSQL string) ([]string, error) {
-	duckDB := duck.NewInMemoryDB()
-	rawSQL = strings.Replace(rawSQL, "'", "''", -1)
-	cmd := fmt.Sprintf("SELECT json_serialize_sql('%s')", rawSQL)
-	ret, err := duckDB.RunCommands([]string{cmd})
+	stmt, err := Parse(rawSQL)
 	if err != nil {
-		logger.Error("error serializing sql", "error", err.Error(), "sql", rawSQL, "cmd", cmd)
-		return nil, fmt.Errorf("error serializing sql: %s", err.Error())
+		logger.Debug("error parsing sql", "error", err.Error(), "sql", rawSQL)
+		return nil, err
+	}
+
+	tables := []string{}
+	collectTables(stmt, nil, func(name string) {
+		if !existsInList(name, tables) {
+			tables = append(tables, name)
+		}
+	})
+	sort.Strings(tables)
+
+	logger.Debug("tables found in sql", "tables", tables)
+
+	return tables, nil
+}
+
+// collectTables calls fn for every input table referenced by the statement,
+// including the tables of subqueries. References to common table expressions
+// in scope are skipped.
+func collectTables(stmt *SelectStatement, ctes map[string]bool, fn func(string)) {
+	if len(stmt.With) > 0 {
+		scoped := make(map[string]bool, len(ctes)+len(stmt.With))
+		for name := range ctes {
+			scoped[name] = true
+		}
+		for _, cte := range stmt.With {
+			collectTables(cte.Select, scoped, fn)
+			scoped[strings.ToLower(cte.Name)] = true
+		}
+		ctes = scoped
 	}
 
-	ast := []map[string]any{}
-	err = json.Unmarshal([]byte(ret), &ast)
+	var walk func(TableExpr)
+	walk = func(te TableExpr) {
+		switch t := te.(type) {
+		case *TableName:
+			if !ctes[strings.ToLower(t.Name)] {
+				fn(t.Name)
+			}
+		case *SubqueryTable:
+			collectTables(t.Select, ctes, fn)
+		case *JoinExpr:
+			walk(t.Left)
+			walk(t.Right)
+		}
+	}
+	if stmt.From != nil {
+		walk(stmt.From)
+	}
+}
+
+func existsInList(table string, list []string) bool {
+	for _, t := range list {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses a single SELECT statement.
+func Parse(rawSQL string) (*SelectStatement, error) {
+	tokens, err := lex(rawSQL)
 	if err != nil {
-		logger.Error("error converting json sql to ast", "error", err.Error(), "ret", ret)
-		return nil, fmt.Errorf("error converting json to ast: %s", err.Error())
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	stmt, err := p.parseSelect()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().typ == tokSemicolon {
+		p.next()
+	}
+	if tok := p.peek(); tok.typ != tokEOF {
+		return nil, p.unexpected(tok, "end of statement")
 	}
+	return stmt, nil
+}
+
+// parser is a recursive descent parser over the tokens of a statement.
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(n int) token {
+	if p.pos+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.typ != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) unexpected(tok token, context string) error {
+	return &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s, expected %s", tok, context)}
+}
+
+// isKeyword reports whether tok is one of the given words. Unquoted
+// identifiers match too, so non-reserved words like PARTITION can be used.
+func isKeyword(tok token, words ...string) bool {
+	if tok.typ != tokKeyword && (tok.typ != tokIdent || tok.quoted) {
+		return false
+	}
+	for _, w := range words {
+		if strings.EqualFold(tok.val, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptKeyword consumes the next token if it is the given word.
+func (p *parser) acceptKeyword(word string) bool {
+	if isKeyword(p.peek(), word) {
+		p.next()
+		return true
+	}
+	return false
+}
 
-	return tablesFromAST(ast)
+func (p *parser) expectKeyword(word string) error {
+	if !p.acceptKeyword(word) {
+		return p.unexpected(p.peek(), word)
+	}
+	return nil
+}
+
+func (p *parser) accept(typ tokenType) bool {
+	if p.peek().typ == typ {
+		p.next()
+		return true
+	}
+	return false
 }
 
-// tablesFromAST returns a list of tables from the ast
-func tablesFromAST(ast []map[string]any) ([]string, error) {
-	flat, err := flatten.Flatten(ast[0], "", flatten.DotStyle)
+func (p *parser) expect(typ tokenType, context string) (token, error) {
+	tok := p.next()
+	if tok.typ != typ {
+		return tok, p.unexpected(tok, context)
+	}
+	return tok, nil
+}
+
+func (p *parser) acceptOperator(op string) bool {
+	if tok := p.peek(); tok.typ == tokOperator && tok.val == op {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseIdent(context string) (string, error) {
+	tok, err := p.expect(tokIdent, context)
 	if err != nil {
-		logger.Error("error flattening ast", "error", err.Error(), "ast", ast)
-		return nil, fmt.Errorf("error flattening ast: %s", err.Error())
+		return "", err
 	}
+	return tok.val, nil
+}
 
-	tables := []string{}
-	for k, v := range flat {
-		if strings.HasSuffix(k, ERROR) {
-			v, ok := v.(bool)
-			if ok && v {
-				logger.Error("error in sql", "error", k)
-				return nil, astError(k, flat)
+func (p *parser) parseSelect() (*SelectStatement, error) {
+	var with []*CommonTableExpr
+	if p.acceptKeyword("WITH") {
+		for {
+			name, err := p.parseIdent("common table expression name")
+			if err != nil {
+				return nil, err
 			}
+			if err := p.expectKeyword("AS"); err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokLeftParen, "common table expression"); err != nil {
+				return nil, err
+			}
+			sub, err := p.parseSelect()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokRightParen, "end of common table expression"); err != nil {
+				return nil, err
+			}
+			with = append(with, &CommonTableExpr{Name: name, Select: sub})
+			if !p.accept(tokComma) {
+				break
+			}
+		}
+	}
+
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	stmt := &SelectStatement{With: with}
+	if p.acceptKeyword("DISTINCT") {
+		stmt.Distinct = true
+	} else {
+		p.acceptKeyword("ALL")
+	}
+
+	for {
+		item, err := p.parseSelectItem()
+		if err != nil {
+			return nil, err
+		}
+		stmt.Columns = append(stmt.Columns, item)
+		if !p.accept(tokComma) {
+			break
+		}
+	}
+
+	var err error
+	if p.acceptKeyword("FROM") {
+		if stmt.From, err = p.parseFrom(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("WHERE") {
+		if stmt.Where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("GROUP") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if stmt.GroupBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("HAVING") {
+		if stmt.Having, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if stmt.OrderBy, err = p.parseOrderList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("LIMIT") {
+		if stmt.Limit, err = p.parseCount("LIMIT"); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("OFFSET") {
+		if stmt.Offset, err = p.parseCount("OFFSET"); err != nil {
+			return nil, err
+		}
+	}
+	return stmt, nil
+}
+
+func (p *parser) parseCount(context string) (*int64, error) {
+	tok, err := p.expect(tokNumber, "row count after "+context)
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.ParseInt(tok.val, 10, 64)
+	if err != nil || n < 0 {
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("%s must be a non-negative integer", context)}
+	}
+	return &n, nil
+}
+
+func (p *parser) parseSelectItem() (*SelectItem, error) {
+	if tok := p.peek(); tok.typ == tokOperator && tok.val == "*" {
+		p.next()
+		return &SelectItem{Star: true}, nil
+	}
+	if tok := p.peek(); tok.typ == tokIdent && p.peekAt(1).typ == tokDot {
+		if star := p.peekAt(2); star.typ == tokOperator && star.val == "*" {
+			p.pos += 3
+			return &SelectItem{Star: true, Table: tok.val}, nil
+		}
+	}
+	e, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	item := &SelectItem{Expr: e}
+	if item.Alias, err = p.parseAlias(); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// parseAlias parses an optional [AS] alias.
+func (p *parser) parseAlias() (string, error) {
+	if p.acceptKeyword("AS") {
+		if tok := p.peek(); tok.typ == tokString {
+			p.next()
+			return tok.val, nil
+		}
+		return p.parseIdent("alias")
+	}
+	if tok := p.peek(); tok.typ == tokIdent {
+		p.next()
+		return tok.val, nil
+	}
+	return "", nil
+}
+
+// parseFrom parses a comma separated list of table references, which are
+// cross joined.
+func (p *parser) parseFrom() (TableExpr, error) {
+	left, err := p.parseTableRef()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept(tokComma) {
+		right, err := p.parseTableRef()
+		if err != nil {
+			return nil, err
+		}
+		left = &JoinExpr{Kind: JoinCross, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseTableRef() (TableExpr, error) {
+	left, err := p.parseTablePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		kind, ok, err := p.parseJoinKind()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return left, nil
 		}
-		if strings.Contains(k, TABLE_NAME) {
-			table, ok := v.(string)
-			if ok && !existsInList(table, tables) {
-				tables = append(tables, v.(string))
+		right, err := p.parseTablePrimary()
+		if err != nil {
+			return nil, err
+		}
+		join := &JoinExpr{Kind: kind, Left: left, Right: right}
+		if kind != JoinCross {
+			switch {
+			case p.acceptKeyword("ON"):
+				if join.On, err = p.parseExpr(); err != nil {
+					return nil, err
+				}
+			case p.acceptKeyword("USING"):
+				if _, err := p.expect(tokLeftParen, "USING column list"); err != nil {
+					return nil, err
+				}
+				for {
+					name, err := p.parseIdent("USING column")
+					if err != nil {
+						return nil, err
+					}
+					join.Using = append(join.Using, name)
+					if !p.accept(tokComma) {
+						break
+					}
+				}
+				if _, err := p.expect(tokRightParen, "USING column list"); err != nil {
+					return nil, err
+				}
+			default:
+				return nil, p.unexpected(p.peek(), "ON or USING")
 			}
 		}
+		left = join
 	}
-	sort.Strings(tables)
+}
 
-	logger.Debug("tables found in sql", "tables", tables)
+// parseJoinKind consumes a join operator, if there is one.
+func (p *parser) parseJoinKind() (JoinKind, bool, error) {
+	kind := JoinInner
+	switch {
+	case p.acceptKeyword("JOIN"):
+		return JoinInner, true, nil
+	case p.acceptKeyword("INNER"):
+	case p.acceptKeyword("CROSS"):
+		kind = JoinCross
+	case p.acceptKeyword("LEFT"):
+		kind = JoinLeft
+		p.acceptKeyword("OUTER")
+	case p.acceptKeyword("RIGHT"):
+		kind = JoinRight
+		p.acceptKeyword("OUTER")
+	case p.acceptKeyword("FULL"):
+		kind = JoinFull
+		p.acceptKeyword("OUTER")
+	default:
+		return kind, false, nil
+	}
+	if err := p.expectKeyword("JOIN"); err != nil {
+		return kind, false, err
+	}
+	return kind, true, nil
+}
 
-	return tables, nil
+func (p *parser) parseTablePrimary() (TableExpr, error) {
+	if p.accept(tokLeftParen) {
+		if isKeyword(p.peek(), "SELECT", "WITH") {
+			sub, err := p.parseSelect()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(tokRightParen, "end of subquery"); err != nil {
+				return nil, err
+			}
+			alias, err := p.parseAlias()
+			if err != nil {
+				return nil, err
+			}
+			return &SubqueryTable{Select: sub, Alias: alias}, nil
+		}
+		te, err := p.parseFrom()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRightParen, "closing parenthesis"); err != nil {
+			return nil, err
+		}
+		return te, nil
+	}
+	name, err := p.parseIdent("table name")
+	if err != nil {
+		return nil, err
+	}
+	alias, err := p.parseAlias()
+	if err != nil {
+		return nil, err
+	}
+	return &TableName{Name: name, Alias: alias}, nil
+}
+
+func (p *parser) parseExprList() ([]Expr, error) {
+	var list []Expr
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+		if !p.accept(tokComma) {
+			return list, nil
+		}
+	}
+}
+
+func (p *parser) parseOrderList() ([]*OrderItem, error) {
+	var list []*OrderItem
+	for {
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		item := &OrderItem{Expr: e}
+		if p.acceptKeyword("DESC") {
+			item.Desc = true
+		} else {
+			p.acceptKeyword("ASC")
+		}
+		if p.acceptKeyword("NULLS") {
+			first := true
+			switch {
+			case p.acceptKeyword("FIRST"):
+			case p.acceptKeyword("LAST"):
+				first = false
+			default:
+				return nil, p.unexpected(p.peek(), "FIRST or LAST")
+			}
+			item.NullsFirst = &first
+		}
+		list = append(list, item)
+		if !p.accept(tokComma) {
+			return list, nil
+		}
+	}
+}
+
+/* Expression grammar, from the lowest to the highest precedence:
+or         -> and {OR and}
+and        -> not {AND not}
+not        -> NOT not | comparison
+comparison -> additive [cmp additive | IS [NOT] NULL | [NOT] IN (list)
+              | [NOT] BETWEEN additive AND additive | [NOT] [I]LIKE additive]
+additive   -> mult {("+" | "-" | "||") mult}
+mult       -> unary {("*" | "/" | "%") unary}
+unary      -> ("-" | "+") unary | postfix
+postfix    -> primary {"::" type}
+*/
+
+func (p *parser) parseExpr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptKeyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (Expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return &UnaryExpr{Op: "NOT", X: x}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.typ == tokOperator {
+		op := tok.val
+		switch op {
+		case "=", "==", "!=", "<>", "<", "<=", ">", ">=":
+			p.next()
+			right, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			switch op {
+			case "==":
+				op = "="
+			case "!=":
+				op = "<>"
+			}
+			return &BinaryExpr{Op: op, Left: left, Right: right}, nil
+		}
+		return left, nil
+	}
+
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		if err := p.expectKeyword("NULL"); err != nil {
+			return nil, err
+		}
+		return &IsNullExpr{X: left, Not: not}, nil
+	}
+
+	not := false
+	if isKeyword(p.peek(), "NOT") && isKeyword(p.peekAt(1), "IN", "BETWEEN", "LIKE", "ILIKE") {
+		p.next()
+		not = true
+	}
+	switch {
+	case p.acceptKeyword("IN"):
+		if _, err := p.expect(tokLeftParen, "IN list"); err != nil {
+			return nil, err
+		}
+		list, err := p.parseExprList()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRightParen, "end of IN list"); err != nil {
+			return nil, err
+		}
+		return &InExpr{X: left, List: list, Not: not}, nil
+	case p.acceptKeyword("BETWEEN"):
+		low, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &BetweenExpr{X: left, Low: low, High: high, Not: not}, nil
+	case isKeyword(p.peek(), "LIKE", "ILIKE"):
+		ci := p.next().val == "ILIKE"
+		pattern, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &LikeExpr{X: left, Pattern: pattern, Not: not, CaseInsensitive: ci}, nil
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (Expr, error) {
+	left, err := p.parseMult()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.typ != tokOperator || (tok.val != "+" && tok.val != "-" && tok.val != "||") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseMult()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: tok.val, Left: left, Right: right}
+	}
 }
 
-func astError(k string, flat map[string]any) error {
-	key := strings.Replace(k, ERROR, "", 1)
-	message, ok := flat[key+ERROR_MESSAGE]
+func (p *parser) parseMult() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok := p.peek()
+		if tok.typ != tokOperator || (tok.val != "*" && tok.val != "/" && tok.val != "%") {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &BinaryExpr{Op: tok.val, Left: left, Right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if tok := p.peek(); tok.typ == tokOperator && (tok.val == "-" || tok.val == "+") {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		// fold negative numeric literals so they print naturally
+		if lit, ok := x.(*Literal); ok && tok.val == "-" {
+			switch v := lit.Value.(type) {
+			case int64:
+				return &Literal{Value: -v}, nil
+			case float64:
+				return &Literal{Value: -v}, nil
+			}
+		}
+		return &UnaryExpr{Op: tok.val, X: x}, nil
+	}
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptOperator("::") {
+		t, err := p.parseTypeName()
+		if err != nil {
+			return nil, err
+		}
+		x = &CastExpr{X: x, Type: t}
+	}
+	return x, nil
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.typ {
+	case tokNumber:
+		if i, err := strconv.ParseInt(tok.val, 10, 64); err == nil {
+			return &Literal{Value: i}, nil
+		}
+		f, err := strconv.ParseFloat(tok.val, 64)
+		if err != nil {
+			return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("invalid number %s", tok.val)}
+		}
+		return &Literal{Value: f}, nil
+	case tokString:
+		return &Literal{Value: tok.val}, nil
+	case tokLeftParen:
+		e, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRightParen, "closing parenthesis"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case tokKeyword:
+		switch tok.val {
+		case "NULL":
+			return &Literal{Value: nil}, nil
+		case "TRUE":
+			return &Literal{Value: true}, nil
+		case "FALSE":
+			return &Literal{Value: false}, nil
+		case "CASE":
+			return p.parseCase()
+		case "CAST":
+			return p.parseCast()
+		}
+	case tokIdent:
+		if p.peek().typ == tokLeftParen {
+			return p.parseFuncCall(tok.val)
+		}
+		if p.accept(tokDot) {
+			name, err := p.parseIdent("column name")
+			if err != nil {
+				return nil, err
+			}
+			return &ColumnRef{Table: tok.val, Name: name}, nil
+		}
+		return &ColumnRef{Name: tok.val}, nil
+	}
+	return nil, p.unexpected(tok, "expression")
+}
+
+func (p *parser) parseCase() (Expr, error) {
+	c := &CaseExpr{}
+	var err error
+	if !isKeyword(p.peek(), "WHEN") {
+		if c.Operand, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	for p.acceptKeyword("WHEN") {
+		w := &WhenClause{}
+		if w.Cond, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		if err := p.expectKeyword("THEN"); err != nil {
+			return nil, err
+		}
+		if w.Result, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		c.Whens = append(c.Whens, w)
+	}
+	if len(c.Whens) == 0 {
+		return nil, p.unexpected(p.peek(), "WHEN")
+	}
+	if p.acceptKeyword("ELSE") {
+		if c.Else, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.expectKeyword("END"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (p *parser) parseCast() (Expr, error) {
+	if _, err := p.expect(tokLeftParen, "CAST"); err != nil {
+		return nil, err
+	}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	t, err := p.parseTypeName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.expect(tokRightParen, "end of CAST"); err != nil {
+		return nil, err
+	}
+	return &CastExpr{X: x, Type: t}, nil
+}
+
+// parseTypeName parses a type name. Precision and length arguments such as
+// VARCHAR(255) or DECIMAL(10, 2) are accepted and ignored.
+func (p *parser) parseTypeName() (Type, error) {
+	tok := p.next()
+	if tok.typ != tokIdent || tok.quoted {
+		return TypeNull, p.unexpected(tok, "type name")
+	}
+	t, ok := typeNames[strings.ToUpper(tok.val)]
 	if !ok {
-		message = "unknown error in sql"
+		return TypeNull, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("unknown type %s", tok.val)}
 	}
-	return fmt.Errorf("error in sql: %s", message)
+	if strings.EqualFold(tok.val, "DOUBLE") {
+		p.acceptKeyword("PRECISION")
+	}
+	if p.accept(tokLeftParen) {
+		for {
+			if _, err := p.expect(tokNumber, "type argument"); err != nil {
+				return TypeNull, err
+			}
+			if !p.accept(tokComma) {
+				break
+			}
+		}
+		if _, err := p.expect(tokRightParen, "end of type arguments"); err != nil {
+			return TypeNull, err
+		}
+	}
+	return t, nil
 }
 
-func existsInList(table string, list []string) bool {
-	for _, t := range list {
-		if t == table {
-			return true
+func (p *parser) parseFuncCall(name string) (Expr, error) {
+	p.next() // (
+	f := &FuncCall{Name: strings.ToLower(name)}
+	switch {
+	case p.acceptOperator("*"):
+		f.Star = true
+	case p.peek().typ == tokRightParen:
+	default:
+		f.Distinct = p.acceptKeyword("DISTINCT")
+		args, err := p.parseExprList()
+		if err != nil {
+			return nil, err
 		}
+		f.Args = args
 	}
-	return false
+	if _, err := p.expect(tokRightParen, "end of function arguments"); err != nil {
+		return nil, err
+	}
+	if p.acceptKeyword("OVER") {
+		spec, err := p.parseWindowSpec()
+		if err != nil {
+			return nil, err
+		}
+		f.Over = spec
+	}
+	return f, nil
+}
+
+func (p *parser) parseWindowSpec() (*WindowSpec, error) {
+	if _, err := p.expect(tokLeftParen, "window specification"); err != nil {
+		return nil, err
+	}
+	spec := &WindowSpec{}
+	var err error
+	if p.acceptKeyword("PARTITION") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if spec.PartitionBy, err = p.parseExprList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ORDER") {
+		if err := p.expectKeyword("BY"); err != nil {
+			return nil, err
+		}
+		if spec.OrderBy, err = p.parseOrderList(); err != nil {
+			return nil, err
+		}
+	}
+	if p.acceptKeyword("ROWS") {
+		frame := &WindowFrame{}
+		if p.acceptKeyword("BETWEEN") {
+			if frame.Start, err = p.parseFrameBound(); err != nil {
+				return nil, err
+			}
+			if err := p.expectKeyword("AND"); err != nil {
+				return nil, err
+			}
+			if frame.End, err = p.parseFrameBound(); err != nil {
+				return nil, err
+			}
+		} else {
+			if frame.Start, err = p.parseFrameBound(); err != nil {
+				return nil, err
+			}
+			frame.End = FrameBound{Kind: CurrentRow}
+		}
+		if frame.Start.Kind == UnboundedFollowing || frame.End.Kind == UnboundedPreceding || frame.Start.Kind > frame.End.Kind {
+			return nil, &ParseError{Pos: p.peek().pos, Msg: "invalid window frame"}
+		}
+		spec.Frame = frame
+	}
+	if _, err := p.expect(tokRightParen, "end of window specification"); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func (p *parser) parseFrameBound() (FrameBound, error) {
+	switch {
+	case p.acceptKeyword("UNBOUNDED"):
+		switch {
+		case p.acceptKeyword("PRECEDING"):
+			return FrameBound{Kind: UnboundedPreceding}, nil
+		case p.acceptKeyword("FOLLOWING"):
+			return FrameBound{Kind: UnboundedFollowing}, nil
+		}
+		return FrameBound{}, p.unexpected(p.peek(), "PRECEDING or FOLLOWING")
+	case p.acceptKeyword("CURRENT"):
+		if err := p.expectKeyword("ROW"); err != nil {
+			return FrameBound{}, err
+		}
+		return FrameBound{Kind: CurrentRow}, nil
+	}
+	n, err := p.parseCount("frame offset")
+	if err != nil {
+		return FrameBound{}, err
+	}
+	switch {
+	case p.acceptKeyword("PRECEDING"):
+		return FrameBound{Kind: Preceding, Offset: *n}, nil
+	case p.acceptKeyword("FOLLOWING"):
+		return FrameBound{Kind: Following, Offset: *n}, nil
+	}
+	return FrameBound{}, p.unexpected(p.peek(), "PRECEDING or FOLLOWING")
 }
@@ -4,10 +4,10 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParse(t *testing.T) {
-	t.Skip()
 	sql := "select * from foo"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -16,7 +16,6 @@ func TestParse(t *testing.T) {
 }
 
 func TestParseWithComma(t *testing.T) {
-	t.Skip()
 	sql := "select * from foo,bar"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -26,7 +25,6 @@ func TestParseWithComma(t *testing.T) {
 }
 
 func TestParseWithCommas(t *testing.T) {
-	t.Skip()
 	sql := "select * from foo,bar,baz"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -36,27 +34,8 @@ func TestParseWithCommas(t *testing.T) {
 	assert.Equal(t, "foo", tables[2])
 }
 
-func TestArray(t *testing.T) {
-	t.Skip()
-	sql := "SELECT array_value(1, 2, 3)"
-	tables, err := TablesList((sql))
-	assert.Nil(t, err)
-
-	assert.Equal(t, 0, len(tables))
-}
-
-func TestArray2(t *testing.T) {
-	t.Skip()
-	sql := "SELECT array_value(1, 2, 3)[2]"
-	tables, err := TablesList((sql))
-	assert.Nil(t, err)
-
-	assert.Equal(t, 0, len(tables))
-}
-
-func TestXxx(t *testing.T) {
-	t.Skip()
-	sql := "SELECT [3, 2, 1]::INT[3];"
+func TestFunctionWithoutTables(t *testing.T) {
+	sql := "SELECT abs(-1), coalesce(NULL, 2)"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
@@ -64,7 +43,6 @@ func TestXxx(t *testing.T) {
 }
 
 func TestParseSubquery(t *testing.T) {
-	t.Skip()
 	sql := "select * from (select * from people limit 1)"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -74,7 +52,6 @@ func TestParseSubquery(t *testing.T) {
 }
 
 func TestJoin(t *testing.T) {
-	t.Skip()
 	sql := `select * from A
 	JOIN B ON A.name = B.name
 	LIMIT 10`
@@ -87,7 +64,6 @@ func TestJoin(t *testing.T) {
 }
 
 func TestRightJoin(t *testing.T) {
-	t.Skip()
 	sql := `select * from A
 	RIGHT JOIN B ON A.name = B.name
 	LIMIT 10`
@@ -100,7 +76,6 @@ func TestRightJoin(t *testing.T) {
 }
 
 func TestAliasWithJoin(t *testing.T) {
-	t.Skip()
 	sql := `select * from A as X
 	RIGHT JOIN B ON A.name = X.name
 	LIMIT 10`
@@ -113,7 +88,6 @@ func TestAliasWithJoin(t *testing.T) {
 }
 
 func TestAlias(t *testing.T) {
-	t.Skip()
 	sql := `select * from A as X LIMIT 10`
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -123,14 +97,12 @@ func TestAlias(t *testing.T) {
 }
 
 func TestError(t *testing.T) {
-	t.Skip()
 	sql := `select * from zzz aaa zzz`
 	_, err := TablesList((sql))
 	assert.NotNil(t, err)
 }
 
 func TestParens(t *testing.T) {
-	t.Skip()
 	sql := `SELECT  t1.Col1,
 	t2.Col1,
 	t3.Col1
@@ -149,7 +121,6 @@ func TestParens(t *testing.T) {
 }
 
 func TestWith(t *testing.T) {
-	t.Skip()
 	sql := `WITH
 
 	current_month AS (
@@ -191,14 +162,11 @@ func TestWith(t *testing.T) {
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
-	assert.Equal(t, 5, len(tables))
-	assert.Equal(t, "A", tables[0])
-	assert.Equal(t, "B", tables[1])
-	assert.Equal(t, "BEE", tables[2])
+	// common table expressions are not inputs
+	assert.Equal(t, []string{"A", "B", "BEE"}, tables)
 }
 
 func TestWithQuote(t *testing.T) {
-	t.Skip()
 	sql := "select *,'junk' from foo"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
@@ -207,10 +175,73 @@ func TestWithQuote(t *testing.T) {
 }
 
 func TestWithQuote2(t *testing.T) {
-	t.Skip()
 	sql := "SELECT json_serialize_sql('SELECT 1')"
 	tables, err := TablesList((sql))
 	assert.Nil(t, err)
 
 	assert.Equal(t, 0, len(tables))
 }
+
+func TestParseNestedSubquery(t *testing.T) {
+	sql := `SELECT a.host, b.total FROM A a
+	JOIN (SELECT host, sum(value) AS total FROM B GROUP BY host) b USING (host)
+	WHERE a.value > (1 + 2)`
+	tables, err := TablesList((sql))
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"A", "B"}, tables)
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, sql := range []string{
+		"",
+		"delete from A",
+		"select from A",
+		"select * from A where",
+		"select 'unterminated from A",
+		"select * from A limit -1",
+		"select cast(value as blob) from A",
+		"select sum(value) over (rows between current row and 1 preceding) from A",
+	} {
+		_, err := TablesList(sql)
+		var parseErr *ParseError
+		assert.ErrorAs(t, err, &parseErr, sql)
+	}
+}
+
+func TestParseExpressions(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"select 1 + 2 * 3", "(1 + (2 * 3))"},
+		{"select (1 + 2) * 3", "((1 + 2) * 3)"},
+		{"select -value", "-value"},
+		{"select -1.5", "-1.5"},
+		{"select a = 1 or b <> 2 and not c", "((a = 1) OR ((b <> 2) AND NOT c))"},
+		{"select a != 1", "(a <> 1)"},
+		{"select a is not null", "a IS NOT NULL"},
+		{"select a not in (1, 2)", "a NOT IN (1, 2)"},
+		{"select a between 1 and 2", "a BETWEEN 1 AND 2"},
+		{"select name not ilike 'cpu%'", "name NOT ILIKE 'cpu%'"},
+		{"select 'it''s' || name", "('it''s' || name)"},
+		{"select value::int", "CAST(value AS BIGINT)"},
+		{"select cast(value as varchar(20))", "CAST(value AS VARCHAR)"},
+		{"select case when a > 1 then 'x' else 'y' end", "CASE WHEN (a > 1) THEN 'x' ELSE 'y' END"},
+		{"select COUNT(DISTINCT host)", "count(DISTINCT host)"},
+		{"select count(*)", "count(*)"},
+		{`select "Time"`, "Time"},
+		{
+			"select avg(value) over (partition by host order by time desc rows between 2 preceding and current row)",
+			"avg(value) OVER (PARTITION BY host ORDER BY time DESC ROWS BETWEEN 2 PRECEDING AND CURRENT ROW)",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.sql, func(t *testing.T) {
+			stmt, err := Parse(test.sql)
+			require.NoError(t, err)
+			require.Len(t, stmt.Columns, 1)
+			require.Equal(t, test.expected, stmt.Columns[0].Expr.String())
+		})
+	}
+}
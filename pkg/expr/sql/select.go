@@ -0,0 +1,468 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// outputColumn is a column of the select list.
+type outputColumn struct {
+	name string
+	typ  Type
+	expr Expr
+}
+
+// execSelect executes a SELECT statement. The clauses are applied in the
+// standard order: WITH, FROM, WHERE, GROUP BY, HAVING, window functions, SELECT,
+// ORDER BY, DISTINCT and LIMIT.
+func (x *executor) execSelect(stmt *SelectStatement) (*relation, error) {
+	if len(stmt.With) > 0 {
+		defer func(n int) { x.ctes = x.ctes[:n] }(len(x.ctes))
+		for _, cte := range stmt.With {
+			rel, err := x.execSelect(cte.Select)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", cte.Name, err)
+			}
+			x.ctes = append(x.ctes, namedRelation{name: cte.Name, rel: rel})
+		}
+	}
+
+	src := &relation{rows: [][]any{{}}} // a query without FROM returns one row
+	if stmt.From != nil {
+		var err error
+		if src, err = x.execTableExpr(stmt.From); err != nil {
+			return nil, err
+		}
+	}
+	sc := newScope(x, src)
+
+	if stmt.Where != nil {
+		t, err := sc.check(stmt.Where, checkMode{clause: "WHERE", rowLevel: true})
+		if err != nil {
+			return nil, err
+		}
+		if t != TypeBool && t != TypeNull {
+			return nil, fmt.Errorf("WHERE clause must be a boolean, got %s", t)
+		}
+		filtered := make([][]any, 0, len(src.rows))
+		for _, row := range src.rows {
+			if err := x.budget.tick(); err != nil {
+				return nil, err
+			}
+			v, err := sc.eval(stmt.Where, &rowCtx{row: row})
+			if err != nil {
+				return nil, err
+			}
+			if v == true {
+				filtered = append(filtered, row)
+			}
+		}
+		src.rows = filtered
+	}
+
+	items, err := sc.expandStars(stmt.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregate := len(stmt.GroupBy) > 0 || stmt.Having != nil || hasAggregate(items) || hasAggregateOrder(stmt.OrderBy)
+	groupBy, err := sc.resolveGroupBy(stmt.GroupBy, items)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := checkMode{clause: "SELECT", rowLevel: !aggregate, allowAgg: aggregate, allowWindow: true}
+	out := make([]outputColumn, len(items))
+	for i, item := range items {
+		t, err := sc.check(item.Expr, mode)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = outputColumn{name: outputName(item), typ: t, expr: item.Expr}
+	}
+	uniqueNames(out)
+
+	if stmt.Having != nil {
+		t, err := sc.check(stmt.Having, checkMode{clause: "HAVING", allowAgg: true})
+		if err != nil {
+			return nil, err
+		}
+		if t != TypeBool && t != TypeNull {
+			return nil, fmt.Errorf("HAVING clause must be a boolean, got %s", t)
+		}
+	}
+
+	// ORDER BY items either reference an output column or are evaluated
+	// against the source rows.
+	orderOutput := make([]int, len(stmt.OrderBy))
+	for i, o := range stmt.OrderBy {
+		if orderOutput[i], err = resolveOrderItem(o, out); err != nil {
+			return nil, err
+		}
+		if orderOutput[i] < 0 {
+			mode.clause = "ORDER BY"
+			if _, err := sc.check(o.Expr, mode); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	ctxs, err := sc.rowContexts(groupBy, aggregate)
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt.Having != nil {
+		kept := ctxs[:0]
+		for _, rc := range ctxs {
+			v, err := sc.eval(stmt.Having, rc)
+			if err != nil {
+				return nil, err
+			}
+			if v == true {
+				kept = append(kept, rc)
+			}
+		}
+		ctxs = kept
+	}
+
+	for _, w := range sc.windows {
+		if err := sc.computeWindow(w, ctxs); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &relation{cols: make([]relColumn, len(out)), rows: make([][]any, len(ctxs))}
+	for i, c := range out {
+		typ := c.typ
+		if typ == TypeNull {
+			// a column that only holds NULL is mapped to a nullable float
+			typ = TypeFloat
+		}
+		result.cols[i] = relColumn{name: c.name, typ: typ}
+	}
+	for j, rc := range ctxs {
+		row := make([]any, len(out))
+		for i, c := range out {
+			v, err := sc.eval(c.expr, rc)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil && typeOfValue(v) != result.cols[i].typ {
+				if v, err = convertValue(v, result.cols[i].typ); err != nil {
+					return nil, err
+				}
+			}
+			row[i] = v
+		}
+		if err := x.budget.charge(row); err != nil {
+			return nil, err
+		}
+		result.rows[j] = row
+	}
+
+	if len(stmt.OrderBy) > 0 {
+		if err := sc.sortRows(result, ctxs, stmt.OrderBy, orderOutput); err != nil {
+			return nil, err
+		}
+	}
+
+	if stmt.Distinct {
+		seen := map[string]bool{}
+		distinct := result.rows[:0]
+		for _, row := range result.rows {
+			key := rowKey(row)
+			if !seen[key] {
+				seen[key] = true
+				distinct = append(distinct, row)
+			}
+		}
+		result.rows = distinct
+	}
+
+	if stmt.Offset != nil {
+		result.rows = result.rows[min(*stmt.Offset, int64(len(result.rows))):]
+	}
+	if stmt.Limit != nil && *stmt.Limit < int64(len(result.rows)) {
+		result.rows = result.rows[:*stmt.Limit]
+	}
+	return result, nil
+}
+
+// expandStars replaces * and <table>.* with references to the columns of
+// the source relation.
+func (sc *scope) expandStars(items []*SelectItem) ([]*SelectItem, error) {
+	expanded := make([]*SelectItem, 0, len(items))
+	for _, item := range items {
+		if !item.Star {
+			expanded = append(expanded, item)
+			continue
+		}
+		found := false
+		for i, c := range sc.rel.cols {
+			if c.hidden || (item.Table != "" && c.table != item.Table && !strings.EqualFold(c.table, item.Table)) {
+				continue
+			}
+			ref := &ColumnRef{Table: c.table, Name: c.name}
+			sc.cols[ref] = i
+			expanded = append(expanded, &SelectItem{Expr: ref, Alias: c.name})
+			found = true
+		}
+		if item.Table != "" && !found {
+			return nil, fmt.Errorf("table %s not found", item.Table)
+		}
+	}
+	if len(expanded) == 0 {
+		return nil, fmt.Errorf("the query does not select any columns")
+	}
+	return expanded, nil
+}
+
+// resolveGroupBy resolves ordinals and output column aliases in the GROUP BY
+// clause and registers the grouped expressions.
+func (sc *scope) resolveGroupBy(groupBy []Expr, items []*SelectItem) ([]Expr, error) {
+	resolved := make([]Expr, len(groupBy))
+	for i, e := range groupBy {
+		switch n := e.(type) {
+		case *Literal:
+			pos, ok := n.Value.(int64)
+			if !ok || pos < 1 || pos > int64(len(items)) {
+				return nil, fmt.Errorf("GROUP BY position %s is not in the select list", n)
+			}
+			e = items[pos-1].Expr
+		case *ColumnRef:
+			if _, err := sc.rel.resolve(n); err != nil && n.Table == "" {
+				for _, item := range items {
+					if item.Alias == n.Name {
+						e = item.Expr
+						break
+					}
+				}
+			}
+		}
+		if _, err := sc.check(e, checkMode{clause: "GROUP BY", rowLevel: true}); err != nil {
+			return nil, err
+		}
+		if ref, ok := e.(*ColumnRef); ok {
+			sc.groupCols[sc.cols[ref]] = true
+		}
+		sc.groupKeys[e.String()] = true
+		resolved[i] = e
+	}
+	return resolved, nil
+}
+
+// rowContexts returns the contexts the select list is evaluated for: one
+// per row, or one per group for aggregate queries.
+func (sc *scope) rowContexts(groupBy []Expr, aggregate bool) ([]*rowCtx, error) {
+	rows := sc.rel.rows
+	var ctxs []*rowCtx
+	switch {
+	case !aggregate:
+		ctxs = make([]*rowCtx, len(rows))
+		for i, row := range rows {
+			ctxs[i] = &rowCtx{row: row}
+		}
+	case len(groupBy) == 0:
+		rc := &rowCtx{group: rows}
+		if len(rows) > 0 {
+			rc.row = rows[0]
+		}
+		ctxs = []*rowCtx{rc}
+	default:
+		// groups are returned in the order of their first row
+		index := map[string]*rowCtx{}
+		keys := make([]any, len(groupBy))
+		for _, row := range rows {
+			if err := sc.x.budget.tick(); err != nil {
+				return nil, err
+			}
+			for i, e := range groupBy {
+				v, err := sc.eval(e, &rowCtx{row: row})
+				if err != nil {
+					return nil, err
+				}
+				keys[i] = v
+			}
+			key := rowKey(keys)
+			rc, ok := index[key]
+			if !ok {
+				rc = &rowCtx{row: row}
+				index[key] = rc
+				ctxs = append(ctxs, rc)
+			}
+			rc.group = append(rc.group, row)
+		}
+	}
+	if len(sc.windows) > 0 {
+		for _, rc := range ctxs {
+			rc.window = map[*FuncCall]any{}
+		}
+	}
+	return ctxs, nil
+}
+
+func hasAggregate(items []*SelectItem) bool {
+	for _, item := range items {
+		if containsAggregate(item.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAggregateOrder(items []*OrderItem) bool {
+	for _, item := range items {
+		if containsAggregate(item.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAggregate(e Expr) bool {
+	found := false
+	walkExpr(e, func(e Expr) bool {
+		if f, ok := e.(*FuncCall); ok && isAggregate(f) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// outputName is the name of an output column: the alias, the column name of
+// column references, and the canonical expression otherwise.
+func outputName(item *SelectItem) string {
+	if item.Alias != "" {
+		return item.Alias
+	}
+	if ref, ok := item.Expr.(*ColumnRef); ok {
+		return ref.Name
+	}
+	return item.Expr.String()
+}
+
+// uniqueNames renames duplicate output columns, for example the columns
+// with the same name of both sides of a join, by appending _1, _2, ...
+func uniqueNames(out []outputColumn) {
+	used := map[string]bool{}
+	for _, c := range out {
+		used[c.name] = true
+	}
+	seen := map[string]bool{}
+	for i, c := range out {
+		if !seen[c.name] {
+			seen[c.name] = true
+			continue
+		}
+		for n := 1; ; n++ {
+			name := c.name + "_" + strconv.Itoa(n)
+			if !used[name] {
+				out[i].name = name
+				used[name] = true
+				break
+			}
+		}
+	}
+}
+
+// resolveOrderItem returns the index of the output column an ORDER BY item
+// refers to by position or by name, or -1.
+func resolveOrderItem(o *OrderItem, out []outputColumn) (int, error) {
+	switch n := o.Expr.(type) {
+	case *Literal:
+		if pos, ok := n.Value.(int64); ok {
+			if pos < 1 || pos > int64(len(out)) {
+				return -1, fmt.Errorf("ORDER BY position %d is not in the select list", pos)
+			}
+			return int(pos - 1), nil
+		}
+	case *ColumnRef:
+		if n.Table != "" {
+			return -1, nil
+		}
+		for i, c := range out {
+			if c.name == n.Name {
+				return i, nil
+			}
+		}
+	}
+	return -1, nil
+}
+
+// sortRows sorts the result rows, and their contexts, by the ORDER BY items.
+func (sc *scope) sortRows(result *relation, ctxs []*rowCtx, orderBy []*OrderItem, orderOutput []int) error {
+	keys := make([][]any, len(result.rows))
+	for j, row := range result.rows {
+		keys[j] = make([]any, len(orderBy))
+		for i, o := range orderBy {
+			if orderOutput[i] >= 0 {
+				keys[j][i] = row[orderOutput[i]]
+				continue
+			}
+			v, err := sc.eval(o.Expr, ctxs[j])
+			if err != nil {
+				return err
+			}
+			keys[j][i] = v
+		}
+	}
+
+	idx := make([]int, len(result.rows))
+	for i := range idx {
+		idx[i] = i
+	}
+	var sortErr error
+	sort.SliceStable(idx, func(a, b int) bool {
+		c, err := compareKeys(keys[idx[a]], keys[idx[b]], orderBy)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	rows := make([][]any, len(idx))
+	for i, j := range idx {
+		rows[i] = result.rows[j]
+	}
+	result.rows = rows
+	return nil
+}
+
+// compareKeys compares two sort keys. NULLs sort as the largest value unless
+// NULLS FIRST or NULLS LAST is given.
+func compareKeys(a, b []any, items []*OrderItem) (int, error) {
+	for i, item := range items {
+		va, vb := a[i], b[i]
+		if va == nil || vb == nil {
+			if va == nil && vb == nil {
+				continue
+			}
+			nullsFirst := item.Desc
+			if item.NullsFirst != nil {
+				nullsFirst = *item.NullsFirst
+			}
+			if (va == nil) == nullsFirst {
+				return -1, nil
+			}
+			return 1, nil
+		}
+		c, err := compareValues(va, vb)
+		if err != nil {
+			return 0, err
+		}
+		if item.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c, nil
+		}
+	}
+	return 0, nil
+}
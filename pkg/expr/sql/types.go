@@ -0,0 +1,380 @@
+package sql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the type of a column or expression. Values of each type are held
+// as nil (NULL), bool, int64, float64, string or time.Time.
+type Type int
+
+const (
+	// TypeNull is the type of the NULL literal. It is only used during type
+	// checking and never for a column of a table.
+	TypeNull Type = iota
+	TypeBool
+	TypeInt
+	TypeFloat
+	TypeString
+	TypeTime
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "BOOLEAN"
+	case TypeInt:
+		return "BIGINT"
+	case TypeFloat:
+		return "DOUBLE"
+	case TypeString:
+		return "VARCHAR"
+	case TypeTime:
+		return "TIMESTAMP"
+	}
+	return "NULL"
+}
+
+func (t Type) numeric() bool {
+	return t == TypeInt || t == TypeFloat
+}
+
+// typeNames maps the type names accepted by CAST to types.
+var typeNames = map[string]Type{
+	"BOOL": TypeBool, "BOOLEAN": TypeBool,
+	"TINYINT": TypeInt, "SMALLINT": TypeInt, "INT": TypeInt, "INTEGER": TypeInt, "BIGINT": TypeInt,
+	"REAL": TypeFloat, "FLOAT": TypeFloat, "DOUBLE": TypeFloat, "DECIMAL": TypeFloat, "NUMERIC": TypeFloat,
+	"CHAR": TypeString, "VARCHAR": TypeString, "TEXT": TypeString, "STRING": TypeString,
+	"TIMESTAMP": TypeTime, "DATETIME": TypeTime,
+}
+
+// Column is a named, typed column of a Table.
+type Column struct {
+	Name   string
+	Type   Type
+	Values []any
+}
+
+// Table is the column oriented representation of the inputs and the result
+// of a query.
+type Table struct {
+	Name    string
+	Columns []*Column
+}
+
+// Rows returns the number of rows of the table.
+func (t *Table) Rows() int {
+	if len(t.Columns) == 0 {
+		return 0
+	}
+	return len(t.Columns[0].Values)
+}
+
+// unifyTypes returns the type that values of both types are converted to
+// when they are mixed, for example in the branches of a CASE expression.
+func unifyTypes(a, b Type) (Type, error) {
+	switch {
+	case a == b:
+		return a, nil
+	case a == TypeNull:
+		return b, nil
+	case b == TypeNull:
+		return a, nil
+	case a.numeric() && b.numeric():
+		return TypeFloat, nil
+	}
+	return TypeNull, fmt.Errorf("incompatible types %s and %s", a, b)
+}
+
+// comparableTypes reports whether values of both types can be compared.
+func comparableTypes(a, b Type) bool {
+	switch {
+	case a == TypeNull || b == TypeNull || a == b:
+		return true
+	case a.numeric() && b.numeric():
+		return true
+	case (a == TypeTime && b == TypeString) || (a == TypeString && b == TypeTime):
+		return true
+	}
+	return false
+}
+
+// compareValues compares two non-NULL values of comparable types and returns
+// -1, 0 or +1.
+func compareValues(a, b any) (int, error) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return compareOrdered(x, y), nil
+		case float64:
+			return compareFloats(float64(x), y), nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case int64:
+			return compareFloats(x, float64(y)), nil
+		case float64:
+			return compareFloats(x, y), nil
+		}
+	case string:
+		switch y := b.(type) {
+		case string:
+			return strings.Compare(x, y), nil
+		case time.Time:
+			t, err := parseTime(x)
+			if err != nil {
+				return 0, err
+			}
+			return t.Compare(y), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			switch {
+			case x == y:
+				return 0, nil
+			case !x:
+				return -1, nil
+			}
+			return 1, nil
+		}
+	case time.Time:
+		switch y := b.(type) {
+		case time.Time:
+			return x.Compare(y), nil
+		case string:
+			t, err := parseTime(y)
+			if err != nil {
+				return 0, err
+			}
+			return x.Compare(t), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s and %s", typeOfValue(a), typeOfValue(b))
+}
+
+func compareOrdered[T int64 | string](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// compareFloats orders NaN before all other numbers so sorting is total.
+func compareFloats(a, b float64) int {
+	switch {
+	case math.IsNaN(a) && math.IsNaN(b):
+		return 0
+	case math.IsNaN(a):
+		return -1
+	case math.IsNaN(b):
+		return 1
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func typeOfValue(v any) Type {
+	switch v.(type) {
+	case bool:
+		return TypeBool
+	case int64:
+		return TypeInt
+	case float64:
+		return TypeFloat
+	case string:
+		return TypeString
+	case time.Time:
+		return TypeTime
+	}
+	return TypeNull
+}
+
+// timeLayouts are the layouts accepted when a string is converted to a time.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02",
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp '%s'", s)
+}
+
+// toFloat returns the value of a numeric value as float64.
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	}
+	return 0, false
+}
+
+// convertValue converts a non-NULL value to the type t. It is used for casts
+// and to coerce values to the static type of their column.
+func convertValue(v any, t Type) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch t {
+	case TypeNull:
+		return nil, nil
+	case TypeBool:
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case int64:
+			return x != 0, nil
+		case float64:
+			return x != 0, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(x))
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert '%s' to %s", x, t)
+			}
+			return b, nil
+		}
+	case TypeInt:
+		switch x := v.(type) {
+		case bool:
+			if x {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case int64:
+			return x, nil
+		case float64:
+			return floatToInt(x)
+		case string:
+			s := strings.TrimSpace(x)
+			if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return i, nil
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert '%s' to %s", x, t)
+			}
+			return floatToInt(f)
+		}
+	case TypeFloat:
+		switch x := v.(type) {
+		case bool:
+			if x {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		case int64:
+			return float64(x), nil
+		case float64:
+			return x, nil
+		case string:
+			f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert '%s' to %s", x, t)
+			}
+			return f, nil
+		}
+	case TypeString:
+		switch x := v.(type) {
+		case bool:
+			return strconv.FormatBool(x), nil
+		case int64:
+			return strconv.FormatInt(x, 10), nil
+		case float64:
+			return strconv.FormatFloat(x, 'g', -1, 64), nil
+		case string:
+			return x, nil
+		case time.Time:
+			return x.Format(time.RFC3339Nano), nil
+		}
+	case TypeTime:
+		switch x := v.(type) {
+		case time.Time:
+			return x, nil
+		case string:
+			return parseTime(strings.TrimSpace(x))
+		}
+	}
+	return nil, fmt.Errorf("cannot convert %s to %s", typeOfValue(v), t)
+}
+
+func floatToInt(f float64) (any, error) {
+	r := math.Round(f)
+	if math.IsNaN(r) || r >= math.MaxInt64 || r < math.MinInt64 {
+		return nil, fmt.Errorf("value %v is out of range for %s", f, TypeInt)
+	}
+	return int64(r), nil
+}
+
+// valueKey returns a string that is equal for values that compare as equal.
+// It is used to group rows and to find distinct values.
+func valueKey(sb *strings.Builder, v any) {
+	switch x := v.(type) {
+	case nil:
+		sb.WriteString("n;")
+	case bool:
+		if x {
+			sb.WriteString("t;")
+		} else {
+			sb.WriteString("f;")
+		}
+	case int64:
+		sb.WriteString("d")
+		sb.WriteString(strconv.FormatInt(x, 10))
+		sb.WriteByte(';')
+	case float64:
+		sb.WriteString("d")
+		sb.WriteString(strconv.FormatFloat(x, 'g', -1, 64))
+		sb.WriteByte(';')
+	case string:
+		sb.WriteString("s")
+		sb.WriteString(strconv.Itoa(len(x)))
+		sb.WriteByte(':')
+		sb.WriteString(x)
+	case time.Time:
+		sb.WriteString("T")
+		sb.WriteString(strconv.FormatInt(x.UnixNano(), 10))
+		sb.WriteByte(';')
+	}
+}
+
+func rowKey(values []any) string {
+	var sb strings.Builder
+	for _, v := range values {
+		valueKey(&sb, v)
+	}
+	return sb.String()
+}
+
+// valueSize estimates the memory used by a value in bytes.
+func valueSize(v any) int64 {
+	const interfaceSize = 16
+	switch x := v.(type) {
+	case string:
+		return interfaceSize + 16 + int64(len(x))
+	case time.Time:
+		return interfaceSize + 24
+	case nil:
+		return interfaceSize
+	}
+	return interfaceSize + 8
+}
@@ -0,0 +1,236 @@
+package sql
+
+import (
+	"sort"
+)
+
+// computeWindow evaluates a window function for all rows and stores the
+// results in the row contexts.
+func (sc *scope) computeWindow(f *FuncCall, ctxs []*rowCtx) error {
+	partitions, err := sc.partitions(f.Over.PartitionBy, ctxs)
+	if err != nil {
+		return err
+	}
+	for _, part := range partitions {
+		keys, err := sc.sortPartition(part, f.Over.OrderBy, ctxs)
+		if err != nil {
+			return err
+		}
+		if err := sc.computePartition(f, part, keys, ctxs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// partitions groups the indices of the row contexts by the PARTITION BY
+// expressions, in the order of the first row of each partition.
+func (sc *scope) partitions(partitionBy []Expr, ctxs []*rowCtx) ([][]int, error) {
+	if len(partitionBy) == 0 {
+		all := make([]int, len(ctxs))
+		for i := range all {
+			all[i] = i
+		}
+		return [][]int{all}, nil
+	}
+	var partitions [][]int
+	index := map[string]int{}
+	values := make([]any, len(partitionBy))
+	for i, rc := range ctxs {
+		for j, e := range partitionBy {
+			v, err := sc.eval(e, rc)
+			if err != nil {
+				return nil, err
+			}
+			values[j] = v
+		}
+		key := rowKey(values)
+		p, ok := index[key]
+		if !ok {
+			p = len(partitions)
+			index[key] = p
+			partitions = append(partitions, nil)
+		}
+		partitions[p] = append(partitions[p], i)
+	}
+	return partitions, nil
+}
+
+// sortPartition sorts the partition by the window ORDER BY items and returns
+// the sort keys in the new order.
+func (sc *scope) sortPartition(part []int, orderBy []*OrderItem, ctxs []*rowCtx) ([][]any, error) {
+	if len(orderBy) == 0 {
+		return nil, nil
+	}
+	keys := make(map[int][]any, len(part))
+	for _, i := range part {
+		key := make([]any, len(orderBy))
+		for j, o := range orderBy {
+			v, err := sc.eval(o.Expr, ctxs[i])
+			if err != nil {
+				return nil, err
+			}
+			key[j] = v
+		}
+		keys[i] = key
+	}
+	var sortErr error
+	sort.SliceStable(part, func(a, b int) bool {
+		c, err := compareKeys(keys[part[a]], keys[part[b]], orderBy)
+		if err != nil && sortErr == nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return nil, sortErr
+	}
+	sorted := make([][]any, len(part))
+	for p, i := range part {
+		sorted[p] = keys[i]
+	}
+	return sorted, nil
+}
+
+// computePartition evaluates the window function over a sorted partition.
+// keys are the ORDER BY values of the rows, rows with equal keys are peers.
+func (sc *scope) computePartition(f *FuncCall, part []int, keys [][]any, ctxs []*rowCtx) error {
+	peers := func(a, b int) bool {
+		if keys == nil {
+			return true
+		}
+		c, _ := compareKeys(keys[a], keys[b], f.Over.OrderBy)
+		return c == 0
+	}
+	set := func(p int, v any) error {
+		ctxs[part[p]].window[f] = v
+		return sc.x.budget.chargeBytes(valueSize(v))
+	}
+
+	switch f.Name {
+	case "row_number":
+		for p := range part {
+			if err := set(p, int64(p+1)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "rank", "dense_rank":
+		rank := int64(0)
+		for p := range part {
+			if p == 0 || !peers(p-1, p) {
+				if f.Name == "rank" {
+					rank = int64(p + 1)
+				} else {
+					rank++
+				}
+			}
+			if err := set(p, rank); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case "lag", "lead":
+		for p := range part {
+			rc := ctxs[part[p]]
+			offset := int64(1)
+			if len(f.Args) > 1 {
+				v, err := sc.eval(f.Args[1], rc)
+				if err != nil {
+					return err
+				}
+				if v != nil {
+					offset = v.(int64)
+				}
+			}
+			if f.Name == "lag" {
+				offset = -offset
+			}
+			var v any
+			var err error
+			if target := int64(p) + offset; target >= 0 && target < int64(len(part)) {
+				v, err = sc.eval(f.Args[0], ctxs[part[target]])
+			} else if len(f.Args) > 2 {
+				v, err = sc.eval(f.Args[2], rc)
+			}
+			if err != nil {
+				return err
+			}
+			if err := set(p, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// first_value, last_value and aggregates are computed over the frame of
+	// each row
+	values := make([]any, len(part))
+	for p, i := range part {
+		var err error
+		if f.Star {
+			values[p] = true
+		} else if values[p], err = sc.eval(f.Args[0], ctxs[i]); err != nil {
+			return err
+		}
+	}
+	for p := range part {
+		start, end := frameBounds(f.Over, p, len(part), peers)
+		var v any
+		switch f.Name {
+		case "first_value":
+			if start <= end {
+				v = values[start]
+			}
+		case "last_value":
+			if start <= end {
+				v = values[end]
+			}
+		default:
+			agg := newAggregator(f)
+			for q := start; q <= end; q++ {
+				if err := agg.add(values[q]); err != nil {
+					return err
+				}
+			}
+			v = agg.result()
+		}
+		if err := set(p, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// frameBounds returns the first and last row of the frame of row p of a
+// partition with n rows. Without an explicit frame the frame spans the whole
+// partition, or the rows up to the last peer of the row when the window is
+// ordered. The frame is empty when start > end.
+func frameBounds(spec *WindowSpec, p, n int, peers func(a, b int) bool) (int, int) {
+	if spec.Frame == nil {
+		if len(spec.OrderBy) == 0 {
+			return 0, n - 1
+		}
+		end := p
+		for end+1 < n && peers(p, end+1) {
+			end++
+		}
+		return 0, end
+	}
+	bound := func(b FrameBound) int {
+		switch b.Kind {
+		case UnboundedPreceding:
+			return 0
+		case Preceding:
+			return p - int(min(b.Offset, int64(n)))
+		case Following:
+			return p + int(min(b.Offset, int64(n)))
+		case UnboundedFollowing:
+			return n - 1
+		}
+		return p
+	}
+	return max(bound(spec.Frame.Start), 0), min(bound(spec.Frame.End), n-1)
+}
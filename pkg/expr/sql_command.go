@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/scottlepp/go-duck/duck"
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/expr/mathexp"
@@ -20,6 +19,7 @@ type SQLCommand struct {
 	query       string
 	varsToQuery []string
 	refID       string
+	limits      sql.Limits
 }
 
 // NewSQLCommand creates a new SQLCommand.
@@ -31,9 +31,7 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 	tables, err := sql.TablesList(rawSQL)
 	if err != nil {
 		logger.Warn("invalid sql query", "sql", rawSQL, "error", err)
-		return nil, errutil.BadRequest("sql-invalid-sql",
-			errutil.WithPublicMessage("error reading SQL command"),
-		)
+		return nil, makeSQLParseError(refID, err)
 	}
 	if len(tables) == 0 {
 		logger.Warn("no tables found in SQL query", "sql", rawSQL)
@@ -45,6 +43,7 @@ func NewSQLCommand(refID, rawSQL string) (*SQLCommand, error) {
 		query:       rawSQL,
 		varsToQuery: tables,
 		refID:       refID,
+		limits:      sql.DefaultLimits(),
 	}, nil
 }
 
@@ -76,32 +75,32 @@ func (gr *SQLCommand) NeedsVars() []string {
 }
 
 // Execute runs the command and returns the results or an error if the command
-// failed to execute.
+// failed to execute. Every input is available as a table named after its refID.
 func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.Vars, tracer tracing.Tracer) (mathexp.Results, error) {
-	_, span := tracer.Start(ctx, "SSE.ExecuteSQL")
+	ctx, span := tracer.Start(ctx, "SSE.ExecuteSQL")
 	defer span.End()
 
-	allFrames := []*data.Frame{}
+	inputs := make(map[string][]*data.Frame, len(gr.varsToQuery))
 	for _, ref := range gr.varsToQuery {
 		results, ok := vars[ref]
 		if !ok {
 			logger.Warn("no results found for", "ref", ref)
 			continue
 		}
-		frames := results.Values.AsDataFrames(ref)
-		allFrames = append(allFrames, frames...)
+		inputs[ref] = results.Values.AsDataFrames(ref)
 	}
 
 	rsp := mathexp.Results{}
 
-	duckDB := duck.NewInMemoryDB()
-	var frame = &data.Frame{}
-
-	logger.Debug("Executing query", "query", gr.query, "frames", len(allFrames))
-	err := duckDB.QueryFramesInto(gr.refID, gr.query, allFrames, frame)
+	logger.Debug("Executing query", "query", gr.query, "inputs", len(inputs))
+	frame, err := sql.NewEngine(gr.limits).QueryFrames(ctx, gr.refID, gr.query, inputs)
 	if err != nil {
 		logger.Error("Failed to query frames", "error", err.Error())
-		rsp.Error = err
+		if errors.Is(err, sql.ErrLimitExceeded) {
+			rsp.Error = makeSQLLimitError(gr.refID, err)
+		} else {
+			rsp.Error = makeSQLQueryError(gr.refID, err)
+		}
 		return rsp, nil
 	}
 	logger.Debug("Done Executing query", "query", gr.query, "rows", frame.Rows())
@@ -112,6 +111,7 @@ func (gr *SQLCommand) Execute(ctx context.Context, now time.Time, vars mathexp.V
 		rsp.Values = mathexp.Values{
 			mathexp.NoData{Frame: frame},
 		}
+		return rsp, nil
 	}
 
 	rsp.Values = mathexp.Values{
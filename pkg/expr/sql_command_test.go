@@ -1,27 +1,96 @@
 package expr
 
 import (
-	"strings"
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/sql"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 func TestNewCommand(t *testing.T) {
-	t.Skip()
 	cmd, err := NewSQLCommand("a", "select a from foo, bar")
-	if err != nil && strings.Contains(err.Error(), "feature is not enabled") {
-		return
-	}
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar", "foo"}, cmd.NeedsVars())
 
-	if err != nil {
-		t.Fail()
-		return
-	}
+	t.Run("should return a parse error for invalid SQL", func(t *testing.T) {
+		_, err := NewSQLCommand("a", "select a from")
+		require.Error(t, err)
+		require.True(t, errors.Is(err, SQLParseError))
+	})
 
-	for _, v := range cmd.varsToQuery {
-		if strings.Contains("foo bar", v) {
-			continue
+	t.Run("should error on an empty query", func(t *testing.T) {
+		_, err := NewSQLCommand("a", "")
+		require.Error(t, err)
+	})
+}
+
+func TestSQLCommandExecute(t *testing.T) {
+	series := func(host string, values ...float64) mathexp.Value {
+		s := mathexp.NewSeries("A", data.Labels{"host": host}, len(values))
+		for i, v := range values {
+			s.SetPoint(i, time.Unix(int64(i*60), 0), util.Pointer(v))
 		}
-		t.Fail()
-		return
+		return s
 	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: mathexp.Values{series("a", 1, 2, 3), series("b", 10, 20)}},
+	}
+
+	t.Run("should aggregate series by label", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT host, avg(A) AS mean FROM A GROUP BY host ORDER BY host")
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Values, 1)
+
+		table, ok := rsp.Values[0].(mathexp.TableData)
+		require.True(t, ok)
+		require.Equal(t, "B", table.Frame.RefID)
+		require.Equal(t, 2, table.Frame.Rows())
+		require.Equal(t, util.Pointer("a"), table.Frame.Fields[0].At(0))
+		require.Equal(t, util.Pointer(2.0), table.Frame.Fields[1].At(0))
+		require.Equal(t, util.Pointer(15.0), table.Frame.Fields[1].At(1))
+	})
+
+	t.Run("should return no data for an empty result", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A WHERE A > 100")
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.NoError(t, rsp.Error)
+		require.Len(t, rsp.Values, 1)
+		require.Equal(t, "B", rsp.Values[0].AsDataFrame().RefID)
+		_, ok := rsp.Values[0].(mathexp.NoData)
+		require.True(t, ok)
+	})
+
+	t.Run("should return an error when a limit is exceeded", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT * FROM A")
+		require.NoError(t, err)
+		cmd.limits = sql.Limits{MaxOutputRows: 1}
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, errors.Is(rsp.Error, SQLLimitError))
+	})
+
+	t.Run("should return an error when the query fails", func(t *testing.T) {
+		cmd, err := NewSQLCommand("B", "SELECT missing FROM A")
+		require.NoError(t, err)
+
+		rsp, err := cmd.Execute(context.Background(), time.Now(), vars, tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		require.True(t, errors.Is(rsp.Error, SQLQueryError))
+	})
 }
@@ -84,6 +84,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/mqttinput"
 	"github.com/grafana/grafana/pkg/services/live/pushhttp"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfoimpl"
@@ -93,7 +94,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert"
 	ngimage "github.com/grafana/grafana/pkg/services/ngalert/image"
 	ngmetrics "github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngnotifier "github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	nghistorian "github.com/grafana/grafana/pkg/services/ngalert/state/historian"
 	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
+	ngwriter "github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/notifications"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/oauthtoken/oauthtokentest"
@@ -233,6 +237,7 @@ var wireBasicSet = wire.NewSet(
 	store.ProvideSystemUsersService,
 	live.ProvideService,
 	pushhttp.ProvideService,
+	mqttinput.ProvideService,
 	contexthandler.ProvideService,
 	ldapservice.ProvideService,
 	wire.Bind(new(ldapservice.LDAP), new(*ldapservice.LDAPImpl)),
@@ -240,6 +245,11 @@ var wireBasicSet = wire.NewSet(
 	wire.Bind(new(jwt.JWTService), new(*jwt.AuthService)),
 	ngstore.ProvideDBStore,
 	ngimage.ProvideDeleteExpiredService,
+	nghistorian.ProvideDeleteExpiredService,
+	ngwriter.ProvideDeleteExpiredService,
+	ngnotifier.ProvideDeleteExpiredNotificationHistoryService,
+	ngwriter.ProvideSQLReader,
+	wire.Bind(new(grafanads.RecordedMetricsReader), new(*ngwriter.SQLReader)),
 	ngalert.ProvideService,
 	librarypanels.ProvideService,
 	wire.Bind(new(librarypanels.Service), new(*librarypanels.LibraryPanelService)),
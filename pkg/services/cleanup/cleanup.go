@@ -21,6 +21,9 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/state/historian"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -28,39 +31,47 @@ import (
 )
 
 type CleanUpService struct {
-	log                       log.Logger
-	tracer                    tracing.Tracer
-	store                     db.DB
-	Cfg                       *setting.Cfg
-	ServerLockService         *serverlock.ServerLockService
-	ShortURLService           shorturls.Service
-	QueryHistoryService       queryhistory.Service
-	dashboardVersionService   dashver.Service
-	dashboardSnapshotService  dashboardsnapshots.Service
-	deleteExpiredImageService *image.DeleteExpiredService
-	tempUserService           tempuser.Service
-	annotationCleaner         annotations.Cleaner
-	dashboardService          dashboards.DashboardService
+	log                        log.Logger
+	tracer                     tracing.Tracer
+	store                      db.DB
+	Cfg                        *setting.Cfg
+	ServerLockService          *serverlock.ServerLockService
+	ShortURLService            shorturls.Service
+	QueryHistoryService        queryhistory.Service
+	dashboardVersionService    dashver.Service
+	dashboardSnapshotService   dashboardsnapshots.Service
+	deleteExpiredImageService  *image.DeleteExpiredService
+	deleteExpiredHistory       *historian.DeleteExpiredService
+	deleteExpiredSamples       *writer.DeleteExpiredService
+	deleteExpiredNotifications *notifier.DeleteExpiredNotificationHistoryService
+	tempUserService            tempuser.Service
+	annotationCleaner          annotations.Cleaner
+	dashboardService           dashboards.DashboardService
 }
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService,
+	deleteExpiredHistory *historian.DeleteExpiredService, deleteExpiredSamples *writer.DeleteExpiredService,
+	deleteExpiredNotifications *notifier.DeleteExpiredNotificationHistoryService) *CleanUpService {
 	s := &CleanUpService{
-		Cfg:                       cfg,
-		ServerLockService:         serverLockService,
-		ShortURLService:           shortURLService,
-		QueryHistoryService:       queryHistoryService,
-		store:                     sqlstore,
-		log:                       log.New("cleanup"),
-		dashboardVersionService:   dashboardVersionService,
-		dashboardSnapshotService:  dashSnapSvc,
-		deleteExpiredImageService: deleteExpiredImageService,
-		tempUserService:           tempUserService,
-		tracer:                    tracer,
-		annotationCleaner:         annotationCleaner,
-		dashboardService:          dashboardService,
+		Cfg:                        cfg,
+		ServerLockService:          serverLockService,
+		ShortURLService:            shortURLService,
+		QueryHistoryService:        queryHistoryService,
+		store:                      sqlstore,
+		log:                        log.New("cleanup"),
+		dashboardVersionService:    dashboardVersionService,
+		dashboardSnapshotService:   dashSnapSvc,
+		deleteExpiredImageService:  deleteExpiredImageService,
+		deleteExpiredHistory:       deleteExpiredHistory,
+		deleteExpiredSamples:       deleteExpiredSamples,
+		deleteExpiredNotifications: deleteExpiredNotifications,
+		tempUserService:            tempUserService,
+		tracer:                     tracer,
+		annotationCleaner:          annotationCleaner,
+		dashboardService:           dashboardService,
 	}
 	return s
 }
@@ -101,6 +112,9 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete expired snapshots", srv.deleteExpiredSnapshots},
 		{"delete expired dashboard versions", srv.deleteExpiredDashboardVersions},
 		{"delete expired images", srv.deleteExpiredImages},
+		{"delete expired alert state history", srv.deleteExpiredAlertStateHistory},
+		{"delete expired recorded samples", srv.deleteExpiredRecordedSamples},
+		{"delete expired notification history", srv.deleteExpiredNotificationHistory},
 		{"cleanup old annotations", srv.cleanUpOldAnnotations},
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
@@ -228,6 +242,42 @@ func (srv *CleanUpService) deleteExpiredImages(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredAlertStateHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
+		return
+	}
+	if rowsAffected, err := srv.deleteExpiredHistory.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired alert state history", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired alert state history", "rows affected", rowsAffected)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredRecordedSamples(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
+		return
+	}
+	if rowsAffected, err := srv.deleteExpiredSamples.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired recorded samples", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired recorded samples", "rows affected", rowsAffected)
+	}
+}
+
+func (srv *CleanUpService) deleteExpiredNotificationHistory(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	if !srv.Cfg.UnifiedAlerting.IsEnabled() {
+		return
+	}
+	if rowsAffected, err := srv.deleteExpiredNotifications.DeleteExpired(ctx); err != nil {
+		logger.Error("Failed to delete expired notification history", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired notification history", "rows affected", rowsAffected)
+	}
+}
+
 func (srv *CleanUpService) expireOldUserInvites(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	maxInviteLifetime := srv.Cfg.UserInviteMaxLifetime
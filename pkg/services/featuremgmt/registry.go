@@ -1068,7 +1068,7 @@ var (
 		},
 		{
 			Name:         "sqlExpressions",
-			Description:  "Enables using SQL and DuckDB functions as Expressions.",
+			Description:  "Enables using SQL functions as Expressions.",
 			Stage:        FeatureStageExperimental,
 			FrontendOnly: false,
 			Owner:        grafanaAppPlatformSquad,
@@ -1484,6 +1484,12 @@ var (
 			HideFromDocs:      true,
 			HideFromAdminPage: true,
 		},
+		{
+			Name:        "alertingQueryDeduplication",
+			Description: "Executes identical datasource queries of alert rules evaluated in the same scheduler tick only once",
+			Stage:       FeatureStageExperimental,
+			Owner:       grafanaAlertingSquad,
+		},
 	}
 )
 
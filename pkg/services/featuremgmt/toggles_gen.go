@@ -572,7 +572,7 @@ const (
 	FlagPromQLScope = "promQLScope"
 
 	// FlagSqlExpressions
-	// Enables using SQL and DuckDB functions as Expressions.
+	// Enables using SQL functions as Expressions.
 	FlagSqlExpressions = "sqlExpressions"
 
 	// FlagNodeGraphDotLayout
@@ -794,4 +794,8 @@ const (
 	// FlagImprovedExternalSessionHandling
 	// Enable improved support for external sessions in Grafana
 	FlagImprovedExternalSessionHandling = "improvedExternalSessionHandling"
+
+	// FlagAlertingQueryDeduplication
+	// Executes identical datasource queries of alert rules evaluated in the same scheduler tick only once
+	FlagAlertingQueryDeduplication = "alertingQueryDeduplication"
 )
@@ -5,12 +5,26 @@ import (
 	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/live/telemetry"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/otlp"
+	"github.com/grafana/grafana/pkg/services/live/telemetry/prometheus"
 	"github.com/grafana/grafana/pkg/services/live/telemetry/telegraf"
 )
 
+// Input formats of pushed metrics.
+const (
+	// InputFormatInflux is the Influx line protocol, for example sent by Telegraf.
+	InputFormatInflux = "influx"
+	// InputFormatPrometheus is the Prometheus text exposition format or the OpenMetrics text format.
+	InputFormatPrometheus = "prometheus"
+	// InputFormatOTLP is an OTLP/HTTP metrics export request, encoded in protobuf or JSON.
+	InputFormatOTLP = "otlp"
+)
+
 type Converter struct {
 	telegrafConverterWide         *telegraf.Converter
 	telegrafConverterLabelsColumn *telegraf.Converter
+	prometheusConverter           *prometheus.Converter
+	otlpConverter                 *otlp.Converter
 }
 
 func NewConverter() *Converter {
@@ -22,10 +36,38 @@ func NewConverter() *Converter {
 			telegraf.WithUseLabelsColumn(true),
 			telegraf.WithFloat64Numbers(true),
 		),
+		prometheusConverter: prometheus.NewConverter(),
+		otlpConverter:       otlp.NewConverter(),
 	}
 }
 
-var ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+var (
+	ErrUnsupportedFrameFormat = errors.New("unsupported frame format")
+	ErrUnsupportedInputFormat = errors.New("unsupported input format")
+)
+
+// ConvertInput converts metrics in the input format. The frame format only applies to the
+// Influx line protocol, the other formats are always converted to one frame per metric
+// family with a field per series.
+func (c *Converter) ConvertInput(data []byte, inputFormat string, frameFormat string) ([]telemetry.FrameWrapper, error) {
+	var converter telemetry.Converter
+	switch inputFormat {
+	case InputFormatInflux:
+		return c.Convert(data, frameFormat)
+	case InputFormatPrometheus:
+		converter = c.prometheusConverter
+	case InputFormatOTLP:
+		converter = c.otlpConverter
+	default:
+		return nil, ErrUnsupportedInputFormat
+	}
+
+	metricFrames, err := converter.Convert(data)
+	if err != nil {
+		return nil, fmt.Errorf("error converting metrics: %w", err)
+	}
+	return metricFrames, nil
+}
 
 func (c *Converter) Convert(data []byte, frameFormat string) ([]telemetry.FrameWrapper, error) {
 	var converter telemetry.Converter
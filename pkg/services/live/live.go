@@ -149,22 +149,54 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		}
 	}
 
+	history := managedstream.HistoryConfig{
+		MaxFrames:       g.Cfg.LiveManagedStreamHistoryFrames,
+		MaxAge:          g.Cfg.LiveManagedStreamHistoryDuration,
+		MaxChannelBytes: g.Cfg.LiveManagedStreamHistoryMaxBytes,
+	}
 	if redisClient != nil {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
+			managedstream.NewRedisFrameCacheWithHistory(redisClient, history),
 		)
 	} else {
 		managedStreamRunner = managedstream.NewRunner(
 			g.Publish,
 			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
+			managedstream.NewMemoryFrameCacheWithHistory(history),
 		)
 	}
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if g.Cfg.LivePipelineStorage != "" {
+		if g.Cfg.LivePipelineStorage == "database" {
+			g.pipelineStorage = pipeline.NewDatabaseStorage(g.SQLStore, g.SecretsService)
+		} else {
+			g.pipelineStorage = &pipeline.FileStorage{
+				DataPath:       g.Cfg.DataPath,
+				SecretsService: g.SecretsService,
+			}
+		}
+		g.pipelineRuleCache = pipeline.NewCacheSegmentedTree(&pipeline.StorageRuleBuilder{
+			Node:                   node,
+			ManagedStream:          g.ManagedStreamRunner,
+			FrameStorage:           pipeline.NewFrameStorage(),
+			Storage:                g.pipelineStorage,
+			ChannelHandlerGetter:   g,
+			SecretsService:         g.SecretsService,
+			WindowAggregateStorage: pipeline.NewWindowAggregateStorage(),
+		})
+		g.Pipeline, err = pipeline.New(g.pipelineRuleCache)
+		if err != nil {
+			return nil, err
+		}
+		// Changes of the pipeline storage are announced to all nodes, including this
+		// one, so that every node rebuilds the channel rules of the org.
+		node.OnNotification(g.handleOnNotification)
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -403,6 +435,7 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	pipelineRuleCache   *pipeline.CacheSegmentedTree
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -1051,6 +1084,53 @@ func (g *GrafanaLive) HandleInfoHTTP(ctx *contextmodel.ReqContext) response.Resp
 	})
 }
 
+const pipelineChangedNotification = "pipeline_changed"
+
+type pipelineChangedNotificationData struct {
+	OrgID int64 `json:"orgId"`
+}
+
+// notifyPipelineChanged tells all nodes that the channel rules or write configs of the org
+// changed. Failures are only logged: the change is already stored, and the rule cache of
+// every node rebuilds the rules of its orgs from the storage every 20 seconds, so nodes
+// that miss the notification apply the change with that delay.
+func (g *GrafanaLive) notifyPipelineChanged(orgID int64) {
+	data, err := json.Marshal(pipelineChangedNotificationData{OrgID: orgID})
+	if err != nil {
+		logger.Error("Error encoding pipeline change notification", "error", err)
+		return
+	}
+	if err := g.node.Notify(pipelineChangedNotification, data, ""); err != nil {
+		logger.Error("Error sending pipeline change notification", "error", err, "orgId", orgID)
+	}
+}
+
+func (g *GrafanaLive) handleOnNotification(e centrifuge.NotificationEvent) {
+	if e.Op != pipelineChangedNotification {
+		return
+	}
+	var data pipelineChangedNotificationData
+	if err := json.Unmarshal(e.Data, &data); err != nil {
+		logger.Error("Error decoding pipeline change notification", "error", err)
+		return
+	}
+	if err := g.pipelineRuleCache.Reload(data.OrgID); err != nil {
+		logger.Error("Error reloading channel rules", "error", err, "orgId", data.OrgID)
+	}
+}
+
+// pipelineStorageErrorResponse returns the response for an error of the pipeline storage.
+func pipelineStorageErrorResponse(message string, err error) response.Response {
+	switch {
+	case errors.Is(err, pipeline.ErrChannelRuleNotFound), errors.Is(err, pipeline.ErrWriteConfigNotFound):
+		return response.Error(http.StatusNotFound, message, err)
+	case errors.Is(err, pipeline.ErrVersionConflict):
+		return response.Error(http.StatusConflict, message, err)
+	default:
+		return response.Error(http.StatusInternalServerError, message, err)
+	}
+}
+
 // HandleChannelRulesListHTTP ...
 func (g *GrafanaLive) HandleChannelRulesListHTTP(c *contextmodel.ReqContext) response.Response {
 	result, err := g.pipelineStorage.ListChannelRules(c.Req.Context(), c.SignedInUser.GetOrgID())
@@ -1172,6 +1252,7 @@ func (g *GrafanaLive) HandleChannelRulesPostHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create channel rule", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1193,8 +1274,9 @@ func (g *GrafanaLive) HandleChannelRulesPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	rule, err := g.pipelineStorage.UpdateChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update channel rule", err)
+		return pipelineStorageErrorResponse("Failed to update channel rule", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"rule": rule,
 	})
@@ -1216,8 +1298,9 @@ func (g *GrafanaLive) HandleChannelRulesDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteChannelRule(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete channel rule", err)
+		return pipelineStorageErrorResponse("Failed to delete channel rule", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
@@ -1262,6 +1345,7 @@ func (g *GrafanaLive) HandleWriteConfigsPostHTTP(c *contextmodel.ReqContext) res
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Failed to create write config", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1304,8 +1388,9 @@ func (g *GrafanaLive) HandleWriteConfigsPutHTTP(c *contextmodel.ReqContext) resp
 	}
 	result, err := g.pipelineStorage.UpdateWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update write config", err)
+		return pipelineStorageErrorResponse("Failed to update write config", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{
 		"writeConfig": pipeline.WriteConfigToDto(result),
 	})
@@ -1327,8 +1412,9 @@ func (g *GrafanaLive) HandleWriteConfigsDeleteHTTP(c *contextmodel.ReqContext) r
 	}
 	err = g.pipelineStorage.DeleteWriteConfig(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete write config", err)
+		return pipelineStorageErrorResponse("Failed to delete write config", err)
 	}
+	g.notifyPipelineChanged(c.SignedInUser.GetOrgID())
 	return response.JSON(http.StatusOK, util.DynMap{})
 }
 
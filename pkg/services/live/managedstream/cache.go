@@ -3,6 +3,8 @@ package managedstream
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -11,8 +13,82 @@ import (
 type FrameCache interface {
 	// GetActiveChannels returns active managed stream channels with JSON schema.
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
-	// GetFrame returns full JSON frame for a channel in org.
+	// GetFrame returns full JSON frame for a channel in org. When history is enabled
+	// the frame contains the rows of all frames in the history of the channel.
 	GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
+
+// HistoryConfig configures the history of frames a FrameCache keeps for every channel,
+// so that new subscribers get the recent data of a channel instead of its last frame
+// only. The history of a channel is cleared when its schema changes, and always keeps
+// the last frame. History is disabled when both MaxFrames and MaxAge are zero.
+type HistoryConfig struct {
+	// MaxFrames is the maximum number of frames kept for a channel.
+	MaxFrames int
+	// MaxAge is the maximum time frames are kept for after they were pushed.
+	MaxAge time.Duration
+	// MaxChannelBytes caps the JSON size of the frames kept for a channel.
+	MaxChannelBytes int
+}
+
+func (c HistoryConfig) enabled() bool {
+	return c.MaxFrames > 0 || c.MaxAge > 0
+}
+
+// historyFrame is a frame of a channel history with the time, in milliseconds, it was
+// pushed at.
+type historyFrame struct {
+	Time  int64           `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// trim returns the most recent frames that are within the limits of the configuration.
+func (c HistoryConfig) trim(frames []historyFrame, now time.Time) []historyFrame {
+	size := 0
+	start := len(frames)
+	for i := len(frames) - 1; i >= 0; i-- {
+		size += len(frames[i].Frame)
+		if i < len(frames)-1 {
+			if c.MaxFrames > 0 && len(frames)-i > c.MaxFrames {
+				break
+			}
+			if c.MaxAge > 0 && now.Sub(time.UnixMilli(frames[i].Time)) > c.MaxAge {
+				break
+			}
+			if c.MaxChannelBytes > 0 && size > c.MaxChannelBytes {
+				break
+			}
+		}
+		start = i
+	}
+	return frames[start:]
+}
+
+// mergeHistory merges the frames of a history, which share the same schema, into a
+// single frame with the rows of all frames.
+func mergeHistory(frames []historyFrame) (json.RawMessage, error) {
+	var merged data.Frame
+	if err := json.Unmarshal(frames[0].Frame, &merged); err != nil {
+		return nil, err
+	}
+	for _, f := range frames[1:] {
+		var frame data.Frame
+		if err := json.Unmarshal(f.Frame, &frame); err != nil {
+			return nil, err
+		}
+		if len(frame.Fields) != len(merged.Fields) {
+			return nil, fmt.Errorf("frames of history have different schemas")
+		}
+		for i, field := range frame.Fields {
+			if field.Type() != merged.Fields[i].Type() {
+				return nil, fmt.Errorf("frames of history have different schemas")
+			}
+			for j := 0; j < field.Len(); j++ {
+				merged.Fields[i].Append(field.At(j))
+			}
+		}
+	}
+	return data.FrameToJSON(&merged, data.IncludeAll)
+}
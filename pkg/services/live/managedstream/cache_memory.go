@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -12,16 +13,26 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
-	log    log.Logger
+	mu        sync.RWMutex
+	frames    map[int64]map[string]data.FrameJSONCache
+	history   HistoryConfig
+	histories map[int64]map[string][]historyFrame
+	log       log.Logger
 }
 
 // NewMemoryFrameCache ...
 func NewMemoryFrameCache() *MemoryFrameCache {
+	return NewMemoryFrameCacheWithHistory(HistoryConfig{})
+}
+
+// NewMemoryFrameCacheWithHistory creates a MemoryFrameCache which keeps the history of
+// channels in memory.
+func NewMemoryFrameCacheWithHistory(history HistoryConfig) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
-		log:    log.New("live.memoryframecache"),
+		frames:    map[int64]map[string]data.FrameJSONCache{},
+		history:   history,
+		histories: map[int64]map[string][]historyFrame{},
+		log:       log.New("live.memoryframecache"),
 	}
 }
 
@@ -44,6 +55,14 @@ func (c *MemoryFrameCache) GetFrame(ctx context.Context, orgID int64, channel st
 	defer c.mu.RUnlock()
 	cachedFrame, ok := c.frames[orgID][channel]
 	raw := cachedFrame.Bytes(data.IncludeAll)
+	if history := c.history.trim(c.histories[orgID][channel], time.Now()); len(history) > 1 {
+		merged, err := mergeHistory(history)
+		if err != nil {
+			c.log.Error("Error merging history", "orgId", orgID, "channel", channel, "error", err)
+		} else {
+			raw = merged
+		}
+	}
 	c.log.Debug("Cache get",
 		"orgId", orgID,
 		"channel", channel,
@@ -61,6 +80,9 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.history.enabled() {
+		c.updateHistory(orgID, channel, jsonFrame, schemaUpdated)
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -68,3 +90,16 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	)
 	return schemaUpdated, nil
 }
+
+func (c *MemoryFrameCache) updateHistory(orgID int64, channel string, jsonFrame data.FrameJSONCache, schemaUpdated bool) {
+	if _, ok := c.histories[orgID]; !ok {
+		c.histories[orgID] = map[string][]historyFrame{}
+	}
+	var history []historyFrame
+	if !schemaUpdated {
+		history = c.histories[orgID][channel]
+	}
+	now := time.Now()
+	history = append(history, historyFrame{Time: now.UnixMilli(), Frame: jsonFrame.Bytes(data.IncludeAll)})
+	c.histories[orgID][channel] = c.history.trim(history, now)
+}
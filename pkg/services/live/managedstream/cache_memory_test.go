@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -67,3 +68,59 @@ func TestMemoryFrameCache(t *testing.T) {
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func testFrameCacheHistory(t *testing.T, c FrameCache) {
+	update := func(frame *data.Frame) bool {
+		frameJsonCache, err := data.FrameToJSONCache(frame)
+		require.NoError(t, err)
+		updated, err := c.Update(context.Background(), 1, "history", frameJsonCache)
+		require.NoError(t, err)
+		return updated
+	}
+	getFrame := func() *data.Frame {
+		frameJSON, ok, err := c.GetFrame(context.Background(), 1, "history")
+		require.NoError(t, err)
+		require.True(t, ok)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		return &f
+	}
+
+	for i := 1; i <= 4; i++ {
+		update(data.NewFrame("hello", data.NewField("value", nil, []int64{int64(i)})))
+	}
+
+	// History is limited to the last 3 frames.
+	f := getFrame()
+	require.Equal(t, 3, f.Rows())
+	require.Equal(t, int64(2), f.Fields[0].At(0))
+	require.Equal(t, int64(4), f.Fields[0].At(2))
+
+	// Schema change clears history.
+	require.True(t, update(data.NewFrame("hello", data.NewField("value", nil, []float64{5}))))
+	f = getFrame()
+	require.Equal(t, 1, f.Rows())
+	require.Equal(t, 5.0, f.Fields[0].At(0))
+}
+
+func TestMemoryFrameCache_History(t *testing.T) {
+	c := NewMemoryFrameCacheWithHistory(HistoryConfig{MaxFrames: 3})
+	testFrameCache(t, c)
+	testFrameCacheHistory(t, c)
+}
+
+func TestHistoryConfig_Trim(t *testing.T) {
+	now := time.Now()
+	frames := []historyFrame{
+		{Time: now.Add(-3 * time.Minute).UnixMilli(), Frame: json.RawMessage(`1234`)},
+		{Time: now.Add(-2 * time.Minute).UnixMilli(), Frame: json.RawMessage(`1234`)},
+		{Time: now.Add(-time.Minute).UnixMilli(), Frame: json.RawMessage(`1234`)},
+		{Time: now.Add(-time.Minute).UnixMilli(), Frame: json.RawMessage(`1234`)},
+	}
+	require.Len(t, HistoryConfig{MaxFrames: 10}.trim(frames, now), 4)
+	require.Len(t, HistoryConfig{MaxFrames: 2}.trim(frames, now), 2)
+	require.Len(t, HistoryConfig{MaxAge: 150 * time.Second}.trim(frames, now), 3)
+	require.Len(t, HistoryConfig{MaxAge: 10 * time.Minute, MaxChannelBytes: 10}.trim(frames, now), 2)
+	// The last frame is kept regardless of limits.
+	require.Len(t, HistoryConfig{MaxAge: time.Second, MaxChannelBytes: 1}.trim(frames, now), 1)
+}
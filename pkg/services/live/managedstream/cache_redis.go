@@ -18,13 +18,21 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	history     HistoryConfig
 }
 
 // NewRedisFrameCache ...
 func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+	return NewRedisFrameCacheWithHistory(redisClient, HistoryConfig{})
+}
+
+// NewRedisFrameCacheWithHistory creates a RedisFrameCache which keeps the history of
+// channels in Redis lists, shared by all Grafana servers.
+func NewRedisFrameCacheWithHistory(redisClient *redis.Client, history HistoryConfig) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		history:     history,
 	}
 }
 
@@ -52,11 +60,51 @@ func (c *RedisFrameCache) GetFrame(ctx context.Context, orgID int64, channel str
 	if len(result) == 0 {
 		return nil, false, nil
 	}
+	if c.history.enabled() {
+		history, err := c.getHistory(ctx, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if len(history) > 1 {
+			merged, err := mergeHistory(history)
+			if err == nil {
+				return merged, true, nil
+			}
+			logger.Error("Error merging history", "orgId", orgID, "channel", channel, "error", err)
+		}
+	}
 	return json.RawMessage(result["frame"]), true, nil
 }
 
+func (c *RedisFrameCache) getHistory(ctx context.Context, key string) ([]historyFrame, error) {
+	values, err := c.redisClient.LRange(ctx, getHistoryKey(key), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	history, err := decodeHistory(values)
+	if err != nil {
+		return nil, err
+	}
+	// Frames may have expired since the history was last written.
+	return c.history.trim(history, time.Now()), nil
+}
+
+func decodeHistory(values []string) ([]historyFrame, error) {
+	history := make([]historyFrame, 0, len(values))
+	for _, value := range values {
+		var f historyFrame
+		if err := json.Unmarshal([]byte(value), &f); err != nil {
+			return nil, err
+		}
+		history = append(history, f)
+	}
+	return history, nil
+}
+
 const (
 	frameCacheTTL = 7 * 24 * time.Hour
+	// maxHistoryLength bounds the length of history lists when MaxFrames is not set.
+	maxHistoryLength = 10000
 )
 
 func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
@@ -94,19 +142,141 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 		return false, err
 	}
 
+	schemaUpdated := true
 	if mapReply, ok := reply.(*redis.StringStringMapCmd); ok {
 		result, err := mapReply.Result()
 		if err != nil {
 			return false, err
 		}
-		if len(result) == 0 {
-			return true, nil
+		if len(result) > 0 {
+			schemaUpdated = result["schema"] != stringSchema
+		}
+	}
+	if c.history.enabled() {
+		if err := c.updateHistory(ctx, key, jsonFrame, schemaUpdated); err != nil {
+			return false, err
+		}
+	}
+	return schemaUpdated, nil
+}
+
+// updateHistory pushes the frame to the history list of the channel and removes frames
+// from the head of the list until it is within the limits of the history configuration.
+// The JSON size of the frames is kept in a counter next to the list, so that an update
+// only reads the frames it removes and the oldest frame it keeps.
+func (c *RedisFrameCache) updateHistory(ctx context.Context, key string, jsonFrame data.FrameJSONCache, schemaUpdated bool) error {
+	now := time.Now()
+	frame := jsonFrame.Bytes(data.IncludeAll)
+	value, err := json.Marshal(historyFrame{
+		Time:  now.UnixMilli(),
+		Frame: frame,
+	})
+	if err != nil {
+		return err
+	}
+	maxLength := int64(c.history.MaxFrames)
+	if maxLength <= 0 || maxLength > maxHistoryLength {
+		maxLength = maxHistoryLength
+	}
+	maxBytes := int64(c.history.MaxChannelBytes)
+
+	historyKey := getHistoryKey(key)
+	bytesKey := getHistoryBytesKey(key)
+	pipe := c.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	if schemaUpdated {
+		pipe.Del(ctx, historyKey, bytesKey)
+	}
+	lengthCmd := pipe.RPush(ctx, historyKey, value)
+	pipe.Expire(ctx, historyKey, frameCacheTTL)
+	var bytesCmd *redis.IntCmd
+	if maxBytes > 0 {
+		bytesCmd = pipe.IncrBy(ctx, bytesKey, int64(len(frame)))
+		pipe.Expire(ctx, bytesKey, frameCacheTTL)
+	} else {
+		// Without a size limit there is no counter to keep in sync with the list.
+		pipe.LTrim(ctx, historyKey, -maxLength, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	length := min(lengthCmd.Val(), maxLength)
+	var size int64
+	if bytesCmd != nil {
+		length = lengthCmd.Val()
+		size = bytesCmd.Val()
+	}
+	for length > 1 {
+		exceeded := length > maxLength || (maxBytes > 0 && size > maxBytes)
+		if !exceeded && c.history.MaxAge > 0 {
+			oldest, err := c.redisClient.LIndex(ctx, historyKey, 0).Bytes()
+			if errors.Is(err, redis.Nil) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			var f historyFrame
+			if err := json.Unmarshal(oldest, &f); err != nil {
+				return err
+			}
+			exceeded = now.Sub(time.UnixMilli(f.Time)) > c.history.MaxAge
+		}
+		if !exceeded {
+			return nil
+		}
+		if length, size, err = c.popHistory(ctx, historyKey, bytesKey, maxBytes > 0); err != nil {
+			return err
 		}
-		return result["schema"] != stringSchema, nil
 	}
-	return true, nil
+	return nil
+}
+
+// popHistory removes the oldest frame of a history list and returns the length and the
+// JSON size of the remaining frames. The size is only tracked when countBytes is set.
+func (c *RedisFrameCache) popHistory(ctx context.Context, historyKey, bytesKey string, countBytes bool) (int64, int64, error) {
+	pipe := c.redisClient.TxPipeline()
+	defer func() { _ = pipe.Close() }()
+
+	popCmd := pipe.LPop(ctx, historyKey)
+	lengthCmd := pipe.LLen(ctx, historyKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	popped := popCmd.Val()
+	if lengthCmd.Val() == 0 {
+		// Other servers removed frames in the meantime. The last frame is always kept.
+		return 0, 0, c.redisClient.LPush(ctx, historyKey, popped).Err()
+	}
+	if !countBytes {
+		return lengthCmd.Val(), 0, nil
+	}
+	var f historyFrame
+	if err := json.Unmarshal([]byte(popped), &f); err != nil {
+		return 0, 0, err
+	}
+	size, err := c.redisClient.DecrBy(ctx, bytesKey, int64(len(f.Frame))).Result()
+	if err != nil {
+		return 0, 0, err
+	}
+	return lengthCmd.Val(), size, nil
 }
 
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(cacheKey string) string {
+	return cacheKey + ".history"
+}
+
+// getHistoryBytesKey returns the key of the counter of the JSON size of the frames in the
+// history list.
+func getHistoryBytesKey(cacheKey string) string {
+	return cacheKey + ".history_bytes"
+}
@@ -1,14 +1,20 @@
 package managedstream
 
 import (
+	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
 )
 
-func TestIntegrationRedisCacheStorage(t *testing.T) {
+func newRedisClientForTests(t *testing.T) *redis.Client {
+	t.Helper()
 	if testing.Short() {
 		t.Skip("skipping integration test")
 	}
@@ -26,11 +32,64 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 		db = parsed.DB
 	}
 
-	redisClient := redis.NewClient(&redis.Options{
+	return redis.NewClient(&redis.Options{
 		Addr: addr,
 		DB:   db,
 	})
+}
+
+func TestIntegrationRedisCacheStorage(t *testing.T) {
+	redisClient := newRedisClientForTests(t)
 	c := NewRedisFrameCache(redisClient)
 	require.NotNil(t, c)
 	testFrameCache(t, c)
 }
+
+func TestIntegrationRedisCacheStorage_History(t *testing.T) {
+	redisClient := newRedisClientForTests(t)
+	c := NewRedisFrameCacheWithHistory(redisClient, HistoryConfig{MaxFrames: 3})
+	testFrameCacheHistory(t, c)
+}
+
+func TestIntegrationRedisCacheStorage_HistoryLimitsOnWrite(t *testing.T) {
+	redisClient := newRedisClientForTests(t)
+	ctx := context.Background()
+	cacheKey := getCacheKey(orgchannel.PrependOrgID(1, "history_limits"))
+	historyKey := getHistoryKey(cacheKey)
+	require.NoError(t, redisClient.Del(ctx, historyKey, getHistoryBytesKey(cacheKey)).Err())
+
+	newFrame := func(i int64) data.FrameJSONCache {
+		frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("hello", data.NewField("value", nil, []int64{i})))
+		require.NoError(t, err)
+		return frameJsonCache
+	}
+	frameSize := len(newFrame(0).Bytes(data.IncludeAll))
+
+	// The list is trimmed to the frames that fit into MaxChannelBytes when pushing, not
+	// only when reading.
+	c := NewRedisFrameCacheWithHistory(redisClient, HistoryConfig{MaxFrames: 100, MaxChannelBytes: 2 * frameSize})
+	for i := int64(1); i <= 5; i++ {
+		_, err := c.Update(ctx, 1, "history_limits", newFrame(i))
+		require.NoError(t, err)
+	}
+	length, err := redisClient.LLen(ctx, historyKey).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), length)
+	size, err := redisClient.Get(ctx, getHistoryBytesKey(cacheKey)).Int()
+	require.NoError(t, err)
+	require.Equal(t, 2*frameSize, size)
+
+	history, err := c.getHistory(ctx, cacheKey)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	require.JSONEq(t, string(newFrame(5).Bytes(data.IncludeAll)), string(history[1].Frame))
+
+	// Frames older than MaxAge are removed when pushing.
+	c = NewRedisFrameCacheWithHistory(redisClient, HistoryConfig{MaxAge: 500 * time.Millisecond})
+	time.Sleep(time.Second)
+	_, err = c.Update(ctx, 1, "history_limits", newFrame(6))
+	require.NoError(t, err)
+	length, err = redisClient.LLen(ctx, historyKey).Result()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), length)
+}
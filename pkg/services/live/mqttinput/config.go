@@ -0,0 +1,130 @@
+package mqttinput
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/live/pipeline/pattern"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Config of the MQTT input, read from the [live.mqtt] section.
+type Config struct {
+	// BrokerURL is the URL of the MQTT broker, like mqtt://localhost:1883. The input is
+	// disabled when it is empty.
+	BrokerURL string
+	ClientID  string
+	Username  string
+	Password  string
+	// QoS used to subscribe to topics, 0, 1 or 2.
+	QoS byte
+	// OrgID of the managed streams messages are published to.
+	OrgID int64
+	// Converter decodes message payloads to frames.
+	Converter *pipeline.ConverterConfig
+	// Mappings from topics to Live channels.
+	Mappings []TopicMapping
+}
+
+// TopicMapping maps topics that match Pattern to a channel. Pattern is a channel rule
+// pattern, where a :name segment matches a single topic level and a *name segment matches
+// the remaining levels. Channel can refer to the parameters of the pattern, whose names
+// must then consist of letters and digits, for example plant/:line/:machine can be
+// mapped to stream/plant/:line_:machine.
+type TopicMapping struct {
+	Pattern string
+	Channel string
+}
+
+func readConfig(cfg *setting.Cfg) (Config, error) {
+	section := cfg.SectionWithEnvOverrides("live.mqtt")
+	c := Config{
+		BrokerURL: section.Key("broker_url").MustString(""),
+		ClientID:  section.Key("client_id").MustString("grafana"),
+		Username:  section.Key("username").MustString(""),
+		Password:  section.Key("password").MustString(""),
+		OrgID:     section.Key("org_id").MustInt64(1),
+	}
+	if c.BrokerURL == "" {
+		return c, nil
+	}
+
+	qos := section.Key("qos").MustInt(0)
+	if qos < 0 || qos > 2 {
+		return c, fmt.Errorf("[live.mqtt] qos must be 0, 1 or 2")
+	}
+	c.QoS = byte(qos)
+
+	converterType := section.Key("converter").MustString(pipeline.ConverterTypeJsonAuto)
+	c.Converter = &pipeline.ConverterConfig{Type: converterType}
+	if converterType == pipeline.ConverterTypeInfluxAuto {
+		c.Converter.AutoInfluxConverterConfig = &pipeline.AutoInfluxConverterConfig{
+			FrameFormat: section.Key("frame_format").MustString("labels_column"),
+		}
+	}
+
+	mappings, err := parseTopicMappings(section.Key("topic_mappings").MustString(""))
+	if err != nil {
+		return c, fmt.Errorf("[live.mqtt] topic_mappings: %w", err)
+	}
+	if len(mappings) == 0 {
+		return c, fmt.Errorf("[live.mqtt] topic_mappings must not be empty")
+	}
+	c.Mappings = mappings
+	return c, nil
+}
+
+// parseTopicMappings parses a comma separated list of mappings in the
+// "<topic pattern> -> <channel>" format.
+func parseTopicMappings(value string) ([]TopicMapping, error) {
+	var mappings []TopicMapping
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, "->")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid mapping %q, expected <topic pattern> -> <channel>", item)
+		}
+		mapping := TopicMapping{
+			Pattern: strings.TrimSpace(parts[0]),
+			Channel: strings.TrimSpace(parts[1]),
+		}
+		if err := mapping.validate(); err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+func (m TopicMapping) validate() error {
+	if ok, reason := pattern.Valid(m.Pattern); !ok {
+		return fmt.Errorf("invalid topic pattern %q: %s", m.Pattern, reason)
+	}
+	if !strings.HasPrefix(m.Channel, live.ScopeStream+"/") {
+		return fmt.Errorf("invalid channel %q: only %s channels are supported", m.Channel, live.ScopeStream)
+	}
+	params := patternParams(m.Pattern)
+	for _, name := range channelParams(m.Channel) {
+		if _, ok := params[name]; !ok {
+			return fmt.Errorf("invalid channel %q: unknown parameter %s", m.Channel, name)
+		}
+	}
+	return nil
+}
+
+// patternParams returns the names of the parameters of a pattern.
+func patternParams(p string) map[string]struct{} {
+	params := map[string]struct{}{}
+	for _, segment := range strings.Split(p, "/") {
+		if i := strings.IndexAny(segment, ":*"); i >= 0 {
+			params[segment[i+1:]] = struct{}{}
+		}
+	}
+	return params
+}
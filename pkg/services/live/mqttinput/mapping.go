@@ -0,0 +1,80 @@
+package mqttinput
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
+)
+
+var channelParamRe = regexp.MustCompile(`[:*]([A-Za-z0-9]+)`)
+
+// channelParams returns the names of the parameters a channel refers to.
+func channelParams(channel string) []string {
+	var names []string
+	for _, match := range channelParamRe.FindAllStringSubmatch(channel, -1) {
+		names = append(names, match[1])
+	}
+	return names
+}
+
+// topicFilter returns the MQTT topic filter that matches the topics of a pattern.
+// Segments with a parameter become single level wildcards and segments with a
+// catch-all parameter become multi level wildcards, the pattern then filters topics
+// further if the parameter is only a part of the segment.
+func topicFilter(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if strings.Contains(segment, "*") {
+			segments[i] = "#"
+			return strings.Join(segments[:i+1], "/")
+		}
+		if strings.Contains(segment, ":") {
+			segments[i] = "+"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// topicMapper finds the channel of a topic.
+type topicMapper struct {
+	tree    *tree.Node
+	filters []string
+}
+
+func newTopicMapper(mappings []TopicMapping) (m *topicMapper, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			m, err = nil, fmt.Errorf("conflicting topic patterns: %v", r)
+		}
+	}()
+	m = &topicMapper{tree: tree.New()}
+	seen := map[string]struct{}{}
+	for i := range mappings {
+		m.tree.AddRoute("/"+mappings[i].Pattern, &mappings[i])
+		filter := topicFilter(mappings[i].Pattern)
+		if _, ok := seen[filter]; !ok {
+			seen[filter] = struct{}{}
+			m.filters = append(m.filters, filter)
+		}
+	}
+	return m, nil
+}
+
+// Channel returns the channel of a topic, false if no mapping matches the topic.
+func (m *topicMapper) Channel(topic string) (string, bool) {
+	nodeValue := m.tree.GetValue("/"+topic, false)
+	if nodeValue.Handler == nil {
+		return "", false
+	}
+	mapping := nodeValue.Handler.(*TopicMapping)
+	if nodeValue.Params == nil {
+		return mapping.Channel, true
+	}
+	params := *nodeValue.Params
+	return channelParamRe.ReplaceAllStringFunc(mapping.Channel, func(match string) string {
+		value, _ := params.Get(match[1:])
+		return strings.TrimPrefix(value, "/")
+	}), true
+}
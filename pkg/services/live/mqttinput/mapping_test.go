@@ -0,0 +1,63 @@
+package mqttinput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopicFilter(t *testing.T) {
+	require.Equal(t, "plant/line1/temperature", topicFilter("plant/line1/temperature"))
+	require.Equal(t, "plant/+/+", topicFilter("plant/:line/:machine"))
+	require.Equal(t, "plant/+/status", topicFilter("plant/line:number/status"))
+	require.Equal(t, "plant/#", topicFilter("plant/*rest"))
+}
+
+func TestParseTopicMappings(t *testing.T) {
+	mappings, err := parseTopicMappings("plant/:line/:machine -> stream/plant/:line_:machine, sensors/*path->stream/sensors/*path,")
+	require.NoError(t, err)
+	require.Equal(t, []TopicMapping{
+		{Pattern: "plant/:line/:machine", Channel: "stream/plant/:line_:machine"},
+		{Pattern: "sensors/*path", Channel: "stream/sensors/*path"},
+	}, mappings)
+
+	_, err = parseTopicMappings("plant/:line")
+	require.Error(t, err)
+	_, err = parseTopicMappings("plant/#  -> stream/plant/all")
+	require.Error(t, err, "MQTT wildcards are not valid patterns")
+	_, err = parseTopicMappings("plant/:line -> grafana/plant/:line")
+	require.Error(t, err, "only stream channels are supported")
+	_, err = parseTopicMappings("plant/:line -> stream/plant/:machine")
+	require.Error(t, err, "unknown parameters must be rejected")
+}
+
+func TestTopicMapper_Channel(t *testing.T) {
+	mapper, err := newTopicMapper([]TopicMapping{
+		{Pattern: "plant/:line/:machine", Channel: "stream/plant/:line_:machine"},
+		{Pattern: "plant/:line/status", Channel: "stream/status/:line"},
+		{Pattern: "sensors/*path", Channel: "stream/sensors/*path"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"plant/+/+", "plant/+/status", "sensors/#"}, mapper.filters)
+
+	channel, ok := mapper.Channel("plant/line1/press")
+	require.True(t, ok)
+	require.Equal(t, "stream/plant/line1_press", channel)
+
+	channel, ok = mapper.Channel("plant/line1/status")
+	require.True(t, ok)
+	require.Equal(t, "stream/status/line1", channel)
+
+	channel, ok = mapper.Channel("sensors/hall/a/temperature")
+	require.True(t, ok)
+	require.Equal(t, "stream/sensors/hall/a/temperature", channel)
+
+	_, ok = mapper.Channel("plant/line1")
+	require.False(t, ok)
+
+	_, err = newTopicMapper([]TopicMapping{
+		{Pattern: "plant/:line", Channel: "stream/plant/:line"},
+		{Pattern: "plant/:other", Channel: "stream/plant/:other"},
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,142 @@
+package mqttinput
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.mqtt_input")
+)
+
+const (
+	// minRetryWait and maxRetryWait bound the wait before subscribing again after the
+	// subscription failed. The wait doubles after every failure.
+	minRetryWait = time.Second
+	maxRetryWait = time.Minute
+)
+
+func ProvideService(cfg *setting.Cfg, live *live.GrafanaLive) (*Service, error) {
+	config, err := readConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{config: config}
+	if s.IsDisabled() {
+		return s, nil
+	}
+	logger.Info("Live MQTT input initialization", "brokerUrl", config.BrokerURL)
+	return newService(config, live.ManagedStreamRunner, newBrokerSubscriber(config))
+}
+
+func newService(config Config, runner *managedstream.Runner, subscriber Subscriber) (*Service, error) {
+	mapper, err := newTopicMapper(config.Mappings)
+	if err != nil {
+		return nil, err
+	}
+	converter, err := pipeline.NewConverter(config.Converter)
+	if err != nil {
+		return nil, err
+	}
+	if converter == nil {
+		return nil, fmt.Errorf("MQTT input requires a converter")
+	}
+	return &Service{
+		config:       config,
+		mapper:       mapper,
+		converter:    converter,
+		output:       pipeline.NewManagedStreamFrameOutput(runner),
+		subscriber:   subscriber,
+		minRetryWait: minRetryWait,
+		maxRetryWait: maxRetryWait,
+	}, nil
+}
+
+// Service subscribes to the topics of an MQTT broker and publishes the messages,
+// converted to frames, to the managed streams of the channels the topics map to.
+type Service struct {
+	config     Config
+	mapper     *topicMapper
+	converter  pipeline.Converter
+	output     *pipeline.ManagedStreamFrameOutput
+	subscriber Subscriber
+
+	minRetryWait time.Duration
+	maxRetryWait time.Duration
+}
+
+// IsDisabled returns true when no broker is configured.
+func (s *Service) IsDisabled() bool {
+	return s.config.BrokerURL == ""
+}
+
+// Run subscribes to the topics until ctx is done. When the subscription fails, for example
+// because the broker is not reachable, the error is logged and it is retried with backoff,
+// so that the broker does not stop Grafana.
+func (s *Service) Run(ctx context.Context) error {
+	wait := s.minRetryWait
+	for {
+		err := s.subscriber.Subscribe(ctx, s.mapper.filters, func(topic string, payload []byte) {
+			if err := s.handleMessage(ctx, topic, payload); err != nil {
+				logger.Error("Error handling MQTT message", "topic", topic, "error", err)
+			}
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.Error("Error subscribing to MQTT topics, retrying", "brokerUrl", s.config.BrokerURL, "error", err, "retryIn", wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait = min(2*wait, s.maxRetryWait)
+	}
+}
+
+func (s *Service) handleMessage(ctx context.Context, topic string, payload []byte) error {
+	channel, ok := s.mapper.Channel(topic)
+	if !ok {
+		logger.Debug("No mapping for MQTT topic", "topic", topic)
+		return nil
+	}
+	logger.Debug("Live MQTT message", "topic", topic, "channel", channel, "bodyLength", len(payload))
+
+	channelFrames, err := s.converter.Convert(ctx, pipeline.Vars{OrgID: s.config.OrgID, Channel: channel}, payload)
+	if err != nil {
+		return fmt.Errorf("error converting payload: %w", err)
+	}
+	for _, channelFrame := range channelFrames {
+		frameChannel := channel
+		if channelFrame.Channel != "" {
+			frameChannel = channelFrame.Channel
+		}
+		addr, err := liveDto.ParseChannel(frameChannel)
+		if err != nil {
+			return fmt.Errorf("invalid channel %s: %w", frameChannel, err)
+		}
+		if addr.Scope != liveDto.ScopeStream {
+			return fmt.Errorf("invalid channel %s: only %s channels are supported", frameChannel, liveDto.ScopeStream)
+		}
+		vars := pipeline.Vars{
+			OrgID:     s.config.OrgID,
+			Channel:   frameChannel,
+			Scope:     addr.Scope,
+			Namespace: addr.Namespace,
+			Path:      addr.Path,
+		}
+		if _, err := s.output.OutputFrame(ctx, vars, channelFrame.Frame); err != nil {
+			return fmt.Errorf("error pushing frame to %s: %w", frameChannel, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package mqttinput
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+)
+
+// testBroker is an in-process broker which delivers the messages published to it to the
+// subscriptions with a matching topic filter.
+type testBroker struct {
+	mu            sync.Mutex
+	subscriptions map[string][]MessageHandler
+	subscribed    chan struct{}
+}
+
+func newTestBroker() *testBroker {
+	return &testBroker{
+		subscriptions: map[string][]MessageHandler{},
+		subscribed:    make(chan struct{}),
+	}
+}
+
+func (b *testBroker) Subscribe(ctx context.Context, filters []string, handler MessageHandler) error {
+	b.mu.Lock()
+	for _, filter := range filters {
+		b.subscriptions[filter] = append(b.subscriptions[filter], handler)
+	}
+	b.mu.Unlock()
+	close(b.subscribed)
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (b *testBroker) Publish(topic string, payload []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for filter, handlers := range b.subscriptions {
+		if !topicMatches(filter, topic) {
+			continue
+		}
+		for _, handler := range handlers {
+			handler(topic, payload)
+		}
+	}
+}
+
+func topicMatches(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) || (level != "+" && level != topicLevels[i]) {
+			return false
+		}
+	}
+	return len(filterLevels) == len(topicLevels)
+}
+
+type testPublication struct {
+	orgID   int64
+	channel string
+}
+
+type testPublisher struct {
+	publications chan testPublication
+}
+
+func (p *testPublisher) publish(orgID int64, channel string, _ []byte) error {
+	p.publications <- testPublication{orgID: orgID, channel: channel}
+	return nil
+}
+
+func TestService_PublishesToManagedStreams(t *testing.T) {
+	publisher := &testPublisher{publications: make(chan testPublication, 10)}
+	runner := managedstream.NewRunner(publisher.publish, nil, managedstream.NewMemoryFrameCache())
+	broker := newTestBroker()
+
+	s, err := newService(Config{
+		BrokerURL: "mqtt://localhost:1883",
+		OrgID:     2,
+		Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		Mappings: []TopicMapping{
+			{Pattern: "plant/:line/:machine", Channel: "stream/plant/:line_:machine"},
+		},
+	}, runner, broker)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+	<-broker.subscribed
+
+	broker.Publish("plant/line1/press", []byte(`{"temperature": 71.5, "running": true}`))
+	broker.Publish("plant/line1", []byte(`{"temperature": 20}`))
+	broker.Publish("plant/line2/press", []byte(`not json`))
+	broker.Publish("plant/line2/lathe", []byte(`{"temperature": 40}`))
+
+	require.Equal(t, testPublication{orgID: 2, channel: "stream/plant/line1_press"}, <-publisher.publications)
+	require.Equal(t, testPublication{orgID: 2, channel: "stream/plant/line2_lathe"}, <-publisher.publications)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("service did not stop")
+	}
+	require.Empty(t, publisher.publications)
+}
+
+func TestService_InfluxConverter(t *testing.T) {
+	publisher := &testPublisher{publications: make(chan testPublication, 10)}
+	runner := managedstream.NewRunner(publisher.publish, nil, managedstream.NewMemoryFrameCache())
+
+	s, err := newService(Config{
+		OrgID: 1,
+		Converter: &pipeline.ConverterConfig{
+			Type:                      pipeline.ConverterTypeInfluxAuto,
+			AutoInfluxConverterConfig: &pipeline.AutoInfluxConverterConfig{FrameFormat: "labels_column"},
+		},
+		Mappings: []TopicMapping{
+			{Pattern: "telegraf/:host", Channel: "stream/telegraf/:host"},
+		},
+	}, runner, newTestBroker())
+	require.NoError(t, err)
+
+	err = s.handleMessage(context.Background(), "telegraf/server1", []byte("cpu,cpu=cpu0 usage_idle=98.5 1700000000000000000"))
+	require.NoError(t, err)
+	require.Equal(t, testPublication{orgID: 1, channel: "stream/telegraf/server1/cpu"}, <-publisher.publications)
+}
+
+// failingSubscriber fails the first subscriptions and then subscribes until ctx is done.
+type failingSubscriber struct {
+	failures int
+	attempts chan struct{}
+}
+
+func (s *failingSubscriber) Subscribe(ctx context.Context, _ []string, _ MessageHandler) error {
+	s.attempts <- struct{}{}
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("connection refused")
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestService_RetriesFailedSubscriptions(t *testing.T) {
+	runner := managedstream.NewRunner(nil, nil, managedstream.NewMemoryFrameCache())
+	subscriber := &failingSubscriber{failures: 2, attempts: make(chan struct{}, 10)}
+	s, err := newService(Config{
+		OrgID:     1,
+		Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+		Mappings:  []TopicMapping{{Pattern: "plant/:line", Channel: "stream/plant/:line"}},
+	}, runner, subscriber)
+	require.NoError(t, err)
+	s.minRetryWait = time.Millisecond
+	s.maxRetryWait = 2 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- s.Run(ctx)
+	}()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-subscriber.attempts:
+		case err := <-done:
+			t.Fatalf("service stopped after a failed subscription: %v", err)
+		case <-time.After(time.Second):
+			t.Fatal("service did not subscribe again")
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("service did not stop")
+	}
+}
@@ -0,0 +1,75 @@
+package mqttinput
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/at-wat/mqtt-go"
+)
+
+// MessageHandler is called for every message received from the broker.
+type MessageHandler func(topic string, payload []byte)
+
+// Subscriber subscribes to topics of an MQTT broker.
+type Subscriber interface {
+	// Subscribe subscribes to the topic filters and calls handler for every received
+	// message until ctx is done.
+	Subscribe(ctx context.Context, filters []string, handler MessageHandler) error
+}
+
+// brokerSubscriber is a Subscriber that connects to the broker of the configuration and
+// reconnects, subscribing again, whenever the connection is lost.
+type brokerSubscriber struct {
+	cfg Config
+}
+
+func newBrokerSubscriber(cfg Config) Subscriber {
+	return &brokerSubscriber{cfg: cfg}
+}
+
+func (s *brokerSubscriber) Subscribe(ctx context.Context, filters []string, handler MessageHandler) error {
+	client, err := mqtt.NewReconnectClient(
+		&mqtt.URLDialer{URL: s.cfg.BrokerURL},
+		mqtt.WithPingInterval(30*time.Second),
+		mqtt.WithTimeout(10*time.Second),
+		mqtt.WithReconnectWait(time.Second, time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating MQTT client: %w", err)
+	}
+	client.Handle(mqtt.HandlerFunc(func(msg *mqtt.Message) {
+		handler(msg.Topic, msg.Payload)
+	}))
+
+	options := []mqtt.ConnectOption{mqtt.WithCleanSession(true)}
+	if s.cfg.Username != "" {
+		options = append(options, mqtt.WithUserNamePassword(s.cfg.Username, s.cfg.Password))
+	}
+	if _, err := client.Connect(ctx, s.cfg.ClientID, options...); err != nil {
+		return fmt.Errorf("error connecting to MQTT broker: %w", err)
+	}
+
+	subscriptions := make([]mqtt.Subscription, 0, len(filters))
+	for _, filter := range filters {
+		subscriptions = append(subscriptions, mqtt.Subscription{Topic: filter, QoS: mqtt.QoS(s.cfg.QoS)})
+	}
+	// The reconnecting client subscribes again after reconnecting.
+	if _, err := client.Subscribe(ctx, subscriptions...); err != nil {
+		_ = disconnect(client)
+		return fmt.Errorf("error subscribing to MQTT topics: %w", err)
+	}
+
+	<-ctx.Done()
+	if err := disconnect(client); err != nil {
+		return fmt.Errorf("error disconnecting from MQTT broker: %w", err)
+	}
+	return ctx.Err()
+}
+
+// disconnect disconnects the client, which also stops it from reconnecting.
+func disconnect(client mqtt.ReconnectClient) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return client.Disconnect(ctx)
+}
@@ -0,0 +1,301 @@
+package mqttinput
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mqttTestBroker is a minimal MQTT 3.1.1 broker listening on a local port. It accepts every
+// client, acknowledges every subscription and publishes QoS 0 messages to all connected
+// clients, so that brokerSubscriber can be tested against a real connection.
+type mqttTestBroker struct {
+	t        *testing.T
+	listener net.Listener
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+
+	connects   chan mqttTestConnect
+	subscribes chan []string
+}
+
+type mqttTestConnect struct {
+	clientID string
+	username string
+	password string
+}
+
+func newMQTTTestBroker(t *testing.T) *mqttTestBroker {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &mqttTestBroker{
+		t:          t,
+		listener:   listener,
+		conns:      map[net.Conn]struct{}{},
+		connects:   make(chan mqttTestConnect, 10),
+		subscribes: make(chan []string, 10),
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		b.closeConnections()
+	})
+	go b.accept()
+	return b
+}
+
+func (b *mqttTestBroker) url() string {
+	return "mqtt://" + b.listener.Addr().String()
+}
+
+func (b *mqttTestBroker) accept() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns[conn] = struct{}{}
+		b.mu.Unlock()
+		go b.serve(conn)
+	}
+}
+
+func (b *mqttTestBroker) serve(conn net.Conn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+		_ = conn.Close()
+	}()
+	r := bufio.NewReader(conn)
+	for {
+		packetType, body, err := readMQTTPacket(r)
+		if err != nil {
+			return
+		}
+		switch packetType {
+		case 0x1: // CONNECT
+			connect, err := parseMQTTConnect(body)
+			if err != nil {
+				b.t.Errorf("invalid CONNECT packet: %v", err)
+				return
+			}
+			b.write(conn, []byte{0x20, 0x02, 0x00, 0x00})
+			b.connects <- connect
+		case 0x8: // SUBSCRIBE
+			packetID, filters, qos, err := parseMQTTSubscribe(body)
+			if err != nil {
+				b.t.Errorf("invalid SUBSCRIBE packet: %v", err)
+				return
+			}
+			suback := append([]byte{byte(len(qos) + 2)}, byte(packetID>>8), byte(packetID))
+			b.write(conn, append(append([]byte{0x90}, suback...), qos...))
+			b.subscribes <- filters
+		case 0xC: // PINGREQ
+			b.write(conn, []byte{0xD0, 0x00})
+		case 0xE: // DISCONNECT
+			return
+		}
+	}
+}
+
+func (b *mqttTestBroker) write(conn net.Conn, packet []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, _ = conn.Write(packet)
+}
+
+// publish sends a QoS 0 PUBLISH packet to all connected clients.
+func (b *mqttTestBroker) publish(topic string, payload string) {
+	body := append(encodeMQTTString(topic), payload...)
+	packet := append([]byte{0x30}, encodeMQTTLength(len(body))...)
+	packet = append(packet, body...)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.conns {
+		_, _ = conn.Write(packet)
+	}
+}
+
+// closeConnections drops the connections of all clients, as if the broker restarted.
+func (b *mqttTestBroker) closeConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.conns {
+		_ = conn.Close()
+	}
+}
+
+func readMQTTPacket(r *bufio.Reader) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for {
+		digit, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(digit&0x7F) * multiplier
+		if digit&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header >> 4, body, nil
+}
+
+func parseMQTTConnect(body []byte) (mqttTestConnect, error) {
+	var connect mqttTestConnect
+	protocol, rest, err := readMQTTString(body)
+	if err != nil {
+		return connect, err
+	}
+	if protocol != "MQTT" || len(rest) < 4 || rest[0] != 4 {
+		return connect, errors.New("unsupported protocol")
+	}
+	flags := rest[1]
+	// Skip the protocol level, the flags and the keep alive.
+	rest = rest[4:]
+	if connect.clientID, rest, err = readMQTTString(rest); err != nil {
+		return connect, err
+	}
+	if flags&0x04 != 0 {
+		// Skip the will topic and message.
+		if _, rest, err = readMQTTString(rest); err != nil {
+			return connect, err
+		}
+		if _, rest, err = readMQTTString(rest); err != nil {
+			return connect, err
+		}
+	}
+	if flags&0x80 != 0 {
+		if connect.username, rest, err = readMQTTString(rest); err != nil {
+			return connect, err
+		}
+	}
+	if flags&0x40 != 0 {
+		if connect.password, _, err = readMQTTString(rest); err != nil {
+			return connect, err
+		}
+	}
+	return connect, nil
+}
+
+func parseMQTTSubscribe(body []byte) (uint16, []string, []byte, error) {
+	if len(body) < 2 {
+		return 0, nil, nil, errors.New("missing packet identifier")
+	}
+	packetID := binary.BigEndian.Uint16(body)
+	rest := body[2:]
+	var filters []string
+	var qos []byte
+	for len(rest) > 0 {
+		filter, r, err := readMQTTString(rest)
+		if err != nil {
+			return 0, nil, nil, err
+		}
+		if len(r) < 1 {
+			return 0, nil, nil, errors.New("missing QoS")
+		}
+		filters = append(filters, filter)
+		qos = append(qos, r[0])
+		rest = r[1:]
+	}
+	return packetID, filters, qos, nil
+}
+
+func readMQTTString(b []byte) (string, []byte, error) {
+	if len(b) < 2 {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	n := int(binary.BigEndian.Uint16(b))
+	if len(b) < 2+n {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	return string(b[2 : 2+n]), b[2+n:], nil
+}
+
+func encodeMQTTString(s string) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(s))), s...)
+}
+
+func encodeMQTTLength(n int) []byte {
+	var b []byte
+	for {
+		digit := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			digit |= 0x80
+		}
+		b = append(b, digit)
+		if n == 0 {
+			return b
+		}
+	}
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the MQTT client")
+	}
+	var zero T
+	return zero
+}
+
+func TestBrokerSubscriber(t *testing.T) {
+	broker := newMQTTTestBroker(t)
+	s := newBrokerSubscriber(Config{
+		BrokerURL: broker.url(),
+		ClientID:  "grafana-test",
+		Username:  "user",
+		Password:  "password",
+	})
+
+	messages := make(chan string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() {
+		done <- s.Subscribe(ctx, []string{"plant/+/+", "sensors/#"}, func(topic string, payload []byte) {
+			messages <- topic + " " + string(payload)
+		})
+	}()
+
+	require.Equal(t, mqttTestConnect{clientID: "grafana-test", username: "user", password: "password"}, receive(t, broker.connects))
+	require.Equal(t, []string{"plant/+/+", "sensors/#"}, receive(t, broker.subscribes))
+
+	broker.publish("plant/line1/press", `{"temperature": 71.5}`)
+	require.Equal(t, `plant/line1/press {"temperature": 71.5}`, receive(t, messages))
+
+	// After the connection is lost the client connects and subscribes again.
+	broker.closeConnections()
+	require.Equal(t, "grafana-test", receive(t, broker.connects).clientID)
+	require.Equal(t, []string{"plant/+/+", "sensors/#"}, receive(t, broker.subscribes))
+
+	broker.publish("sensors/hall/humidity", "45")
+	require.Equal(t, "sensors/hall/humidity 45", receive(t, messages))
+
+	cancel()
+	require.ErrorIs(t, receive(t, done), context.Canceled)
+}
@@ -31,15 +31,18 @@ type ChannelRuleSettings struct {
 type ChannelRule struct {
 	OrgId    int64               `json:"-"`
 	Pattern  string              `json:"pattern"`
+	Version  int64               `json:"version,omitempty"`
 	Settings ChannelRuleSettings `json:"settings"`
 }
 
 type ConverterConfig struct {
-	Type                      string                     `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
-	AutoJsonConverterConfig   *AutoJsonConverterConfig   `json:"jsonAuto,omitempty"`
-	ExactJsonConverterConfig  *ExactJsonConverterConfig  `json:"jsonExact,omitempty"`
-	AutoInfluxConverterConfig *AutoInfluxConverterConfig `json:"influxAuto,omitempty"`
-	JsonFrameConverterConfig  *JsonFrameConverterConfig  `json:"jsonFrame,omitempty"`
+	Type                          string                         `json:"type" ts_type:"Omit<keyof ConverterConfig, 'type'>"`
+	AutoJsonConverterConfig       *AutoJsonConverterConfig       `json:"jsonAuto,omitempty"`
+	ExactJsonConverterConfig      *ExactJsonConverterConfig      `json:"jsonExact,omitempty"`
+	AutoInfluxConverterConfig     *AutoInfluxConverterConfig     `json:"influxAuto,omitempty"`
+	JsonFrameConverterConfig      *JsonFrameConverterConfig      `json:"jsonFrame,omitempty"`
+	AutoPrometheusConverterConfig *AutoPrometheusConverterConfig `json:"prometheusAuto,omitempty"`
+	AutoOTLPConverterConfig       *AutoOTLPConverterConfig       `json:"otlpAuto,omitempty"`
 }
 
 type DropFieldsFrameProcessorConfig struct {
@@ -51,10 +54,21 @@ type KeepFieldsFrameProcessorConfig struct {
 }
 
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                           string                               `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig      *DropFieldsFrameProcessorConfig      `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig      *KeepFieldsFrameProcessorConfig      `json:"keepFields,omitempty"`
+	MultipleProcessorConfig        *MultipleFrameProcessorConfig        `json:"multiple,omitempty"`
+	WindowAggregateProcessorConfig *WindowAggregateFrameProcessorConfig `json:"windowAggregate,omitempty"`
+}
+
+type WindowAggregateFrameProcessorConfig struct {
+	// WindowMilliseconds is the duration of a window.
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// StepMilliseconds is the interval between the starts of sliding windows. If not set
+	// windows are tumbling, i.e. a window starts when the previous one ends.
+	StepMilliseconds int64 `json:"stepMilliseconds,omitempty"`
+	// Functions to apply to the values of a window: min, max, avg, count or last. Defaults to avg.
+	Functions []string `json:"functions,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
@@ -152,4 +166,8 @@ type AutoInfluxConverterConfig struct {
 
 type JsonFrameConverterConfig struct{}
 
+type AutoPrometheusConverterConfig struct{}
+
+type AutoOTLPConverterConfig struct{}
+
 type ManagedStreamOutputConfig struct{}
@@ -0,0 +1,383 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Functions that WindowAggregateFrameProcessor can apply to the values of a window.
+const (
+	AggregateFunctionMin   = "min"
+	AggregateFunctionMax   = "max"
+	AggregateFunctionAvg   = "avg"
+	AggregateFunctionCount = "count"
+	AggregateFunctionLast  = "last"
+)
+
+var aggregateFunctions = []string{
+	AggregateFunctionMin,
+	AggregateFunctionMax,
+	AggregateFunctionAvg,
+	AggregateFunctionCount,
+	AggregateFunctionLast,
+}
+
+// WindowAggregateFrameProcessor downsamples frames by aggregating the values of every
+// numeric field, separately for each name and label set, over windows of time. Windows
+// are tumbling, or sliding when they start every StepMilliseconds. Frames are consumed
+// until a window is complete, which happens once a value at or after the end of the
+// window arrives. The processor then returns a frame with a row per completed window,
+// so for continuous streams a frame per window. Values that arrive after their window
+// was completed are dropped. Series are forgotten once they have no values in the windows
+// that are not complete. The open windows of channels that have not received frames for a
+// while are flushed, i.e. completed and returned with the next frame of the channel, and
+// channels that stay idle are forgotten.
+type WindowAggregateFrameProcessor struct {
+	config WindowAggregateFrameProcessorConfig
+
+	mu sync.Mutex
+	// states of the channels handled by the processor, by orgID and channel.
+	states map[string]*windowAggregateState
+	// lastCleanup is the last time that the states of idle channels were checked.
+	lastCleanup time.Time
+
+	nowTimeFunc func() time.Time
+}
+
+func NewWindowAggregateFrameProcessor(config WindowAggregateFrameProcessorConfig) *WindowAggregateFrameProcessor {
+	if len(config.Functions) == 0 {
+		config.Functions = []string{AggregateFunctionAvg}
+	}
+	return &WindowAggregateFrameProcessor{
+		config: config,
+		states: map[string]*windowAggregateState{},
+	}
+}
+
+const FrameProcessorTypeWindowAggregate = "windowAggregate"
+
+func (p *WindowAggregateFrameProcessor) Type() string {
+	return FrameProcessorTypeWindowAggregate
+}
+
+func (c WindowAggregateFrameProcessorConfig) validate() error {
+	if c.WindowMilliseconds <= 0 {
+		return fmt.Errorf("windowMilliseconds must be positive")
+	}
+	if c.StepMilliseconds < 0 || c.StepMilliseconds > c.WindowMilliseconds {
+		return fmt.Errorf("stepMilliseconds must be between 0 and windowMilliseconds")
+	}
+	for _, fn := range c.Functions {
+		if !stringInSlice(fn, aggregateFunctions) {
+			return fmt.Errorf("unknown aggregate function: %s", fn)
+		}
+	}
+	return nil
+}
+
+func (p *WindowAggregateFrameProcessor) window() time.Duration {
+	return time.Duration(p.config.WindowMilliseconds) * time.Millisecond
+}
+
+func (p *WindowAggregateFrameProcessor) step() time.Duration {
+	if p.config.StepMilliseconds <= 0 {
+		return p.window()
+	}
+	return time.Duration(p.config.StepMilliseconds) * time.Millisecond
+}
+
+// windowAggregateIdleFactor is the number of windows, each followed by a step, during which
+// a channel must not receive frames to be idle.
+const windowAggregateIdleFactor = 3
+
+// idleTimeout is the time after which a channel that has not received frames is idle. It is
+// well past the end of the windows of the last frame, so that channels which push once per
+// window, or even less often, keep their state.
+func (p *WindowAggregateFrameProcessor) idleTimeout() time.Duration {
+	return windowAggregateIdleFactor * (p.window() + p.step())
+}
+
+func (p *WindowAggregateFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIndex := -1
+	for i, f := range frame.Fields {
+		if f.Type().Time() {
+			timeIndex = i
+			break
+		}
+	}
+	if timeIndex < 0 {
+		return nil, fmt.Errorf("frame has no time field")
+	}
+	timeField := frame.Fields[timeIndex]
+
+	nowTimeFunc := p.nowTimeFunc
+	if nowTimeFunc == nil {
+		nowTimeFunc = time.Now
+	}
+	now := nowTimeFunc()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := strconv.FormatInt(vars.OrgID, 10) + "/" + vars.Channel
+	p.flushIdleStates(now, key)
+	state, ok := p.states[key]
+	if !ok {
+		state = &windowAggregateState{seriesIndex: map[string]int{}}
+		p.states[key] = state
+	}
+	state.updated = now
+
+	latest := state.latest
+	for _, f := range frame.Fields {
+		if !f.Type().Numeric() {
+			continue
+		}
+		series := state.series(f)
+		for i := 0; i < f.Len(); i++ {
+			t, ok := timeAt(timeField, i)
+			if !ok {
+				continue
+			}
+			value, err := f.FloatAt(i)
+			if err != nil || math.IsNaN(value) {
+				continue
+			}
+			state.add(t, series, value, p.window(), p.step())
+			if t.After(latest) {
+				latest = t
+			}
+		}
+	}
+	state.latest = latest
+
+	completed := append(state.flushed, state.complete(p.window())...)
+	state.flushed = nil
+	if len(completed) == 0 {
+		return nil, nil
+	}
+	result := state.frame(frame.Name, completed, p.config.Functions)
+	state.removeInactiveSeries()
+	return result, nil
+}
+
+// flushIdleStates flushes the open windows of the idle channels other than the channel of
+// key, so that they are returned with the next frame of the channel. Channels that have no
+// open windows, or are still idle after their windows were flushed, are removed. It checks
+// the channels at most once per idle timeout.
+func (p *WindowAggregateFrameProcessor) flushIdleStates(now time.Time, key string) {
+	idleTimeout := p.idleTimeout()
+	if now.Sub(p.lastCleanup) < idleTimeout {
+		return
+	}
+	p.lastCleanup = now
+	for k, state := range p.states {
+		if k == key || now.Sub(state.updated) < idleTimeout {
+			continue
+		}
+		if len(state.windows) == 0 || len(state.flushed) > 0 {
+			delete(p.states, k)
+			continue
+		}
+		state.flush(p.window())
+	}
+}
+
+func timeAt(field *data.Field, i int) (time.Time, bool) {
+	switch v := field.At(i).(type) {
+	case time.Time:
+		return v, true
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, false
+		}
+		return *v, true
+	}
+	return time.Time{}, false
+}
+
+type windowAggregateSeries struct {
+	name   string
+	labels data.Labels
+}
+
+type windowAggregateState struct {
+	// maintain the order of series as they appear in input.
+	seriesList  []windowAggregateSeries
+	seriesIndex map[string]int
+	// windows that are not complete yet, ordered by start.
+	windows []*aggregateWindow
+	// flushed windows of the channel, which are returned with its next frame.
+	flushed []*aggregateWindow
+	// latest is the latest time of a value of the channel. Windows that end at or
+	// before it are complete.
+	latest time.Time
+	// updated is the time when the channel last received a frame.
+	updated time.Time
+}
+
+type aggregateWindow struct {
+	start time.Time
+	stats map[int]*aggregateStats
+}
+
+type aggregateStats struct {
+	min, max, sum, last float64
+	count               int
+	lastTime            time.Time
+}
+
+func (s *windowAggregateState) series(f *data.Field) int {
+	key := f.Name + f.Labels.String()
+	if index, ok := s.seriesIndex[key]; ok {
+		return index
+	}
+	s.seriesList = append(s.seriesList, windowAggregateSeries{name: f.Name, labels: f.Labels.Copy()})
+	s.seriesIndex[key] = len(s.seriesList) - 1
+	return len(s.seriesList) - 1
+}
+
+// add adds the value to every window that contains t and is not complete yet.
+func (s *windowAggregateState) add(t time.Time, series int, value float64, window, step time.Duration) {
+	start := time.Unix(0, t.UnixNano()-mod(t.UnixNano(), int64(step)))
+	for ; start.Add(window).After(t); start = start.Add(-step) {
+		if !start.Add(window).After(s.latest) {
+			// The window was completed by a previous frame.
+			break
+		}
+		w := s.window(start)
+		stats, ok := w.stats[series]
+		if !ok {
+			stats = &aggregateStats{min: value, max: value}
+			w.stats[series] = stats
+		}
+		stats.min = math.Min(stats.min, value)
+		stats.max = math.Max(stats.max, value)
+		stats.sum += value
+		stats.count++
+		if !t.Before(stats.lastTime) {
+			stats.last = value
+			stats.lastTime = t
+		}
+	}
+}
+
+func mod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
+
+func (s *windowAggregateState) window(start time.Time) *aggregateWindow {
+	i := sort.Search(len(s.windows), func(i int) bool {
+		return !s.windows[i].start.Before(start)
+	})
+	if i < len(s.windows) && s.windows[i].start.Equal(start) {
+		return s.windows[i]
+	}
+	w := &aggregateWindow{start: start, stats: map[int]*aggregateStats{}}
+	s.windows = append(s.windows, nil)
+	copy(s.windows[i+1:], s.windows[i:])
+	s.windows[i] = w
+	return w
+}
+
+// complete removes and returns the windows that end at or before the latest value.
+func (s *windowAggregateState) complete(window time.Duration) []*aggregateWindow {
+	n := 0
+	for n < len(s.windows) && !s.windows[n].start.Add(window).After(s.latest) {
+		n++
+	}
+	completed := s.windows[:n:n]
+	s.windows = s.windows[n:]
+	return completed
+}
+
+// flush completes the open windows, and drops the values that arrive for them later.
+func (s *windowAggregateState) flush(window time.Duration) {
+	if end := s.windows[len(s.windows)-1].start.Add(window); end.After(s.latest) {
+		s.latest = end
+	}
+	s.flushed = append(s.flushed, s.windows...)
+	s.windows = nil
+}
+
+// removeInactiveSeries removes the series that have no values in the windows that are not
+// complete yet. They are added again at the end of the series if they get new values.
+func (s *windowAggregateState) removeInactiveSeries() {
+	active := make(map[int]int, len(s.seriesList))
+	for _, w := range s.windows {
+		for index := range w.stats {
+			active[index] = 0
+		}
+	}
+	if len(active) == len(s.seriesList) {
+		return
+	}
+	seriesList := make([]windowAggregateSeries, 0, len(active))
+	seriesIndex := make(map[string]int, len(active))
+	for index, series := range s.seriesList {
+		if _, ok := active[index]; !ok {
+			continue
+		}
+		active[index] = len(seriesList)
+		seriesIndex[series.name+series.labels.String()] = len(seriesList)
+		seriesList = append(seriesList, series)
+	}
+	for _, w := range s.windows {
+		stats := make(map[int]*aggregateStats, len(w.stats))
+		for index, st := range w.stats {
+			stats[active[index]] = st
+		}
+		w.stats = stats
+	}
+	s.seriesList = seriesList
+	s.seriesIndex = seriesIndex
+}
+
+// frame builds a frame with the start of every window in the time field, and a field
+// for every series and aggregate function.
+func (s *windowAggregateState) frame(name string, windows []*aggregateWindow, functions []string) *data.Frame {
+	timeField := data.NewField("time", nil, make([]time.Time, len(windows)))
+	fields := []*data.Field{timeField}
+	for i, w := range windows {
+		timeField.Set(i, w.start)
+	}
+	for index, series := range s.seriesList {
+		for _, fn := range functions {
+			field := data.NewField(series.name+"_"+fn, series.labels.Copy(), make([]*float64, len(windows)))
+			for i, w := range windows {
+				if stats, ok := w.stats[index]; ok {
+					value := stats.value(fn)
+					field.Set(i, &value)
+				}
+			}
+			fields = append(fields, field)
+		}
+	}
+	return data.NewFrame(name, fields...)
+}
+
+func (s *aggregateStats) value(fn string) float64 {
+	switch fn {
+	case AggregateFunctionMin:
+		return s.min
+	case AggregateFunctionMax:
+		return s.max
+	case AggregateFunctionAvg:
+		return s.sum / float64(s.count)
+	case AggregateFunctionCount:
+		return float64(s.count)
+	default:
+		return s.last
+	}
+}
@@ -0,0 +1,232 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func testWindowAggregateFrame(start time.Time, offsets []time.Duration, labels data.Labels, values []float64) *data.Frame {
+	f1 := data.NewField("time", nil, make([]time.Time, len(offsets)))
+	f2 := data.NewField("value", labels, make([]*float64, len(values)))
+	for i := range offsets {
+		f1.Set(i, start.Add(offsets[i]))
+		f2.SetConcrete(i, values[i])
+	}
+	return data.NewFrame("test", f1, f2)
+}
+
+func TestWindowAggregateFrameProcessor_Tumbling(t *testing.T) {
+	processor := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Functions: []string{
+			AggregateFunctionMin,
+			AggregateFunctionMax,
+			AggregateFunctionAvg,
+			AggregateFunctionCount,
+			AggregateFunctionLast,
+		},
+	})
+	start := time.Unix(1700000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+	frame, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{0, 200 * time.Millisecond, 500 * time.Millisecond}, data.Labels{"sensor": "a"}, []float64{4, 1, 7}))
+	require.NoError(t, err)
+	require.Nil(t, frame)
+
+	frame, err = processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{800 * time.Millisecond, 1100 * time.Millisecond}, data.Labels{"sensor": "a"}, []float64{2, 10}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+
+	require.Len(t, frame.Fields, 6)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start, frame.Fields[0].At(0))
+	expected := map[string]float64{
+		"value_min":   1,
+		"value_max":   7,
+		"value_avg":   3.5,
+		"value_count": 4,
+		"value_last":  2,
+	}
+	for _, f := range frame.Fields[1:] {
+		require.Equal(t, data.Labels{"sensor": "a"}, f.Labels)
+		require.Equal(t, expected[f.Name], *f.At(0).(*float64), f.Name)
+	}
+
+	// Values of the completed window are dropped.
+	frame, err = processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{900 * time.Millisecond, 2000 * time.Millisecond}, data.Labels{"sensor": "a"}, []float64{100, 1}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+	require.Equal(t, start.Add(time.Second), frame.Fields[0].At(0))
+	require.Equal(t, 10.0, *frame.Fields[1].At(0).(*float64))
+}
+
+func TestWindowAggregateFrameProcessor_Sliding(t *testing.T) {
+	processor := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		StepMilliseconds:   500,
+		Functions:          []string{AggregateFunctionCount},
+	})
+	start := time.Unix(1700000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+	frame, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{0, 600 * time.Millisecond, 1200 * time.Millisecond, 1600 * time.Millisecond}, nil, []float64{1, 2, 3, 4}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+
+	// Windows starting at -0.5s, 0s and 0.5s are complete, the windows starting at 1s and 1.5s are not.
+	require.Equal(t, 3, frame.Rows())
+	require.Equal(t, start.Add(-500*time.Millisecond), frame.Fields[0].At(0))
+	require.Equal(t, start, frame.Fields[0].At(1))
+	require.Equal(t, start.Add(500*time.Millisecond), frame.Fields[0].At(2))
+	require.Equal(t, "value_count", frame.Fields[1].Name)
+	require.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, 2.0, *frame.Fields[1].At(1).(*float64))
+	require.Equal(t, 2.0, *frame.Fields[1].At(2).(*float64))
+}
+
+func TestWindowAggregateFrameProcessor_LabelSets(t *testing.T) {
+	processor := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000})
+	start := time.Unix(1700000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+	_, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{0}, data.Labels{"sensor": "a"}, []float64{1}))
+	require.NoError(t, err)
+	_, err = processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{0}, data.Labels{"sensor": "b"}, []float64{3}))
+	require.NoError(t, err)
+	frame, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{time.Second}, data.Labels{"sensor": "b"}, []float64{5}))
+	require.NoError(t, err)
+	require.NotNil(t, frame)
+
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "value_avg", frame.Fields[1].Name)
+	require.Equal(t, data.Labels{"sensor": "a"}, frame.Fields[1].Labels)
+	require.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, data.Labels{"sensor": "b"}, frame.Fields[2].Labels)
+	require.Equal(t, 3.0, *frame.Fields[2].At(0).(*float64))
+}
+
+func TestWindowAggregateFrameProcessor_RemovesInactiveSeries(t *testing.T) {
+	processor := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000})
+	start := time.Unix(1700000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+	_, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{0}, data.Labels{"sensor": "a"}, []float64{1}))
+	require.NoError(t, err)
+	frame, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{time.Second}, data.Labels{"sensor": "b"}, []float64{3}))
+	require.NoError(t, err)
+	require.Len(t, frame.Fields, 3)
+
+	// Sensor a has no values in the open windows anymore.
+	state := processor.states["1/stream/test/sensor"]
+	require.Equal(t, []windowAggregateSeries{{name: "value", labels: data.Labels{"sensor": "b"}}}, state.seriesList)
+	require.Equal(t, map[string]int{"value" + data.Labels{"sensor": "b"}.String(): 0}, state.seriesIndex)
+
+	frame, err = processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+		[]time.Duration{2 * time.Second}, data.Labels{"sensor": "a"}, []float64{5}))
+	require.NoError(t, err)
+
+	// Sensor a is added again after sensor b.
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, data.Labels{"sensor": "b"}, frame.Fields[1].Labels)
+	require.Equal(t, 3.0, *frame.Fields[1].At(0).(*float64))
+	require.Equal(t, data.Labels{"sensor": "a"}, frame.Fields[2].Labels)
+	require.Nil(t, frame.Fields[2].At(0))
+}
+
+func TestWindowAggregateFrameProcessor_OneFramePerWindow(t *testing.T) {
+	processor := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{
+		WindowMilliseconds: 1000,
+		Functions:          []string{AggregateFunctionCount},
+	})
+	now := time.Unix(1800000000, 0)
+	processor.nowTimeFunc = func() time.Time {
+		return now
+	}
+	start := time.Unix(1700000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/sensor"}
+
+	// A sensor that pushes a value every second completes the window of the previous value.
+	for i := 0; i < 5; i++ {
+		frame, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+			[]time.Duration{time.Duration(i) * time.Second}, nil, []float64{1}))
+		require.NoError(t, err)
+		if i == 0 {
+			require.Nil(t, frame)
+		} else {
+			require.NotNil(t, frame)
+			require.Equal(t, 1, frame.Rows())
+			require.Equal(t, start.Add(time.Duration(i-1)*time.Second), frame.Fields[0].At(0))
+			require.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+		}
+		now = now.Add(time.Second)
+	}
+}
+
+func TestWindowAggregateFrameProcessor_FlushesIdleChannels(t *testing.T) {
+	processor := NewWindowAggregateFrameProcessor(WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000})
+	now := time.Unix(1800000000, 0)
+	processor.nowTimeFunc = func() time.Time {
+		return now
+	}
+	start := time.Unix(1700000000, 0)
+	idle := Vars{OrgID: 1, Channel: "stream/test/idle"}
+	active := Vars{OrgID: 1, Channel: "stream/test/active"}
+	push := func(vars Vars, offset time.Duration, value float64) *data.Frame {
+		frame, err := processor.ProcessFrame(context.Background(), vars, testWindowAggregateFrame(start,
+			[]time.Duration{offset}, nil, []float64{value}))
+		require.NoError(t, err)
+		return frame
+	}
+
+	push(idle, 0, 1)
+	push(active, 0, 1)
+	require.Len(t, processor.states, 2)
+
+	// Channels are idle after three windows and steps.
+	now = now.Add(5 * time.Second)
+	push(active, 100*time.Millisecond, 1)
+	require.Empty(t, processor.states["1/stream/test/idle"].flushed)
+
+	now = now.Add(2 * time.Second)
+	push(active, 200*time.Millisecond, 1)
+	require.Len(t, processor.states, 2)
+	require.Len(t, processor.states["1/stream/test/idle"].flushed, 1)
+
+	// The flushed window is returned with the next frame of the channel, later values of
+	// the window are dropped.
+	frame := push(idle, 500*time.Millisecond, 100)
+	require.NotNil(t, frame)
+	require.Equal(t, 1, frame.Rows())
+	require.Equal(t, start, frame.Fields[0].At(0))
+	require.Equal(t, 1.0, *frame.Fields[1].At(0).(*float64))
+
+	// The channel is removed when it is still idle after its windows were flushed.
+	push(idle, 2*time.Second, 1)
+	now = now.Add(7 * time.Second)
+	push(active, 300*time.Millisecond, 1)
+	require.Len(t, processor.states["1/stream/test/idle"].flushed, 1)
+	now = now.Add(7 * time.Second)
+	push(active, 400*time.Millisecond, 1)
+	require.Len(t, processor.states, 1)
+	require.Contains(t, processor.states, "1/stream/test/active")
+}
+
+func TestWindowAggregateFrameProcessorConfig_Validate(t *testing.T) {
+	require.NoError(t, WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000, StepMilliseconds: 500}.validate())
+	require.Error(t, WindowAggregateFrameProcessorConfig{}.validate())
+	require.Error(t, WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000, StepMilliseconds: 2000}.validate())
+	require.Error(t, WindowAggregateFrameProcessorConfig{WindowMilliseconds: 1000, Functions: []string{"median"}}.validate())
+}
@@ -66,6 +66,14 @@ var ConvertersRegistry = []EntityInfo{
 		Type:        ConverterTypeJsonFrame,
 		Description: "JSON-encoded Grafana data frame",
 	},
+	{
+		Type:        ConverterTypePrometheusAuto,
+		Description: "accept Prometheus text exposition format or OpenMetrics",
+	},
+	{
+		Type:        ConverterTypeOTLPAuto,
+		Description: "accept OTLP/HTTP metrics in protobuf or JSON",
+	},
 }
 
 var FrameProcessorsRegistry = []EntityInfo{
@@ -79,6 +87,14 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeWindowAggregate,
+		Description: "aggregate numeric fields over windows of time",
+		Example: WindowAggregateFrameProcessorConfig{
+			WindowMilliseconds: 1000,
+			Functions:          []string{AggregateFunctionMin, AggregateFunctionMax, AggregateFunctionAvg},
+		},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
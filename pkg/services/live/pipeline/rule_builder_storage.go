@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	"github.com/centrifugal/centrifuge"
 
@@ -17,6 +18,9 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	// WindowAggregateStorage keeps the state of window aggregate processors between builds.
+	// Without it the processors start over whenever the rules are built.
+	WindowAggregateStorage *WindowAggregateStorage
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -49,6 +53,12 @@ func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscr
 }
 
 func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converter, error) {
+	return NewConverter(config)
+}
+
+// NewConverter creates the Converter described by config. It is used to build channel
+// rules, and by inputs which convert data outside of channel rules.
+func NewConverter(config *ConverterConfig) (Converter, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -69,12 +79,24 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 			return nil, missingConfiguration
 		}
 		return NewAutoInfluxConverter(*config.AutoInfluxConverterConfig), nil
+	case ConverterTypePrometheusAuto:
+		if config.AutoPrometheusConverterConfig == nil {
+			config.AutoPrometheusConverterConfig = &AutoPrometheusConverterConfig{}
+		}
+		return NewAutoPrometheusConverter(*config.AutoPrometheusConverterConfig), nil
+	case ConverterTypeOTLPAuto:
+		if config.AutoOTLPConverterConfig == nil {
+			config.AutoOTLPConverterConfig = &AutoOTLPConverterConfig{}
+		}
+		return NewAutoOTLPConverter(*config.AutoOTLPConverterConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown converter type: %s", config.Type)
 	}
 }
 
-func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig) (FrameProcessor, error) {
+// extractFrameProcessor builds the processor at the path in the channel rules of the org. The
+// paths of window aggregate processors are added to windowAggregatePaths.
+func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig, orgID int64, path string, windowAggregatePaths map[string]struct{}) (FrameProcessor, error) {
 	if config == nil {
 		return nil, nil
 	}
@@ -90,14 +112,26 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			return nil, missingConfiguration
 		}
 		return NewKeepFieldsFrameProcessor(*config.KeepFieldsProcessorConfig), nil
+	case FrameProcessorTypeWindowAggregate:
+		if config.WindowAggregateProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		if err := config.WindowAggregateProcessorConfig.validate(); err != nil {
+			return nil, fmt.Errorf("invalid configuration for %s: %w", config.Type, err)
+		}
+		if f.WindowAggregateStorage == nil {
+			return NewWindowAggregateFrameProcessor(*config.WindowAggregateProcessorConfig), nil
+		}
+		windowAggregatePaths[path] = struct{}{}
+		return f.WindowAggregateStorage.Get(orgID, path, *config.WindowAggregateProcessorConfig), nil
 	case FrameProcessorTypeMultiple:
 		if config.MultipleProcessorConfig == nil {
 			return nil, missingConfiguration
 		}
 		var processors []FrameProcessor
-		for _, outConf := range config.MultipleProcessorConfig.Processors {
+		for i, outConf := range config.MultipleProcessorConfig.Processors {
 			out := outConf
-			proc, err := f.extractFrameProcessor(&out)
+			proc, err := f.extractFrameProcessor(&out, orgID, path+"."+strconv.Itoa(i), windowAggregatePaths)
 			if err != nil {
 				return nil, err
 			}
@@ -311,6 +345,7 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 	}
 
 	rules := make([]*LiveChannelRule, 0, len(channelRules))
+	windowAggregatePaths := map[string]struct{}{}
 
 	for _, ruleConfig := range channelRules {
 		rule := &LiveChannelRule{
@@ -334,8 +369,8 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		}
 
 		var processors []FrameProcessor
-		for _, procConfig := range ruleConfig.Settings.FrameProcessors {
-			proc, err := f.extractFrameProcessor(procConfig)
+		for i, procConfig := range ruleConfig.Settings.FrameProcessors {
+			proc, err := f.extractFrameProcessor(procConfig, orgID, rule.Pattern+"#"+strconv.Itoa(i), windowAggregatePaths)
 			if err != nil {
 				return nil, fmt.Errorf("error building processor for %s: %w", rule.Pattern, err)
 			}
@@ -376,5 +411,8 @@ func (f *StorageRuleBuilder) BuildRules(ctx context.Context, orgID int64) ([]*Li
 		rules = append(rules, rule)
 	}
 
+	if f.WindowAggregateStorage != nil {
+		f.WindowAggregateStorage.Retain(orgID, windowAggregatePaths)
+	}
 	return rules, nil
 }
@@ -0,0 +1,64 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testRuleStorage is a Storage which only lists channel rules.
+type testRuleStorage struct {
+	Storage
+	channelRules []ChannelRule
+}
+
+func (s *testRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]ChannelRule, error) {
+	return s.channelRules, nil
+}
+
+func (s *testRuleStorage) ListWriteConfigs(_ context.Context, _ int64) ([]WriteConfig, error) {
+	return nil, nil
+}
+
+func TestStorageRuleBuilder_ReusesWindowAggregateProcessors(t *testing.T) {
+	windowAggregateRule := func(windowMilliseconds int64) ChannelRule {
+		return ChannelRule{
+			Pattern: "stream/test/:sensor",
+			Settings: ChannelRuleSettings{
+				FrameProcessors: []*FrameProcessorConfig{{
+					Type:                           FrameProcessorTypeWindowAggregate,
+					WindowAggregateProcessorConfig: &WindowAggregateFrameProcessorConfig{WindowMilliseconds: windowMilliseconds},
+				}},
+			},
+		}
+	}
+	storage := &testRuleStorage{channelRules: []ChannelRule{windowAggregateRule(1000)}}
+	builder := &StorageRuleBuilder{
+		Storage:                storage,
+		WindowAggregateStorage: NewWindowAggregateStorage(),
+	}
+	build := func() []*LiveChannelRule {
+		rules, err := builder.BuildRules(context.Background(), 1)
+		require.NoError(t, err)
+		return rules
+	}
+
+	rules := build()
+	require.Len(t, rules, 1)
+	processor := rules[0].FrameProcessors[0]
+
+	// The rules are built again periodically, the processor keeps its open windows.
+	require.Same(t, processor, build()[0].FrameProcessors[0])
+
+	// The processor starts over if its configuration changes.
+	storage.channelRules = []ChannelRule{windowAggregateRule(2000)}
+	changed := build()[0].FrameProcessors[0]
+	require.NotSame(t, processor, changed)
+	require.Same(t, changed, build()[0].FrameProcessors[0])
+
+	// Processors of removed rules are forgotten.
+	storage.channelRules = nil
+	require.Empty(t, build())
+	require.Empty(t, builder.WindowAggregateStorage.processors)
+}
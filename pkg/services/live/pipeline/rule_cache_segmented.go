@@ -9,6 +9,11 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pipeline/tree"
 )
 
+// ruleCacheUpdateInterval is the interval at which CacheSegmentedTree rebuilds the channel
+// rules of the orgs it holds, so that changes in the storage are applied even if a node
+// missed a change notification.
+const ruleCacheUpdateInterval = 20 * time.Second
+
 // CacheSegmentedTree provides a fast access to channel rule configuration.
 type CacheSegmentedTree struct {
 	radixMu     sync.RWMutex
@@ -17,15 +22,19 @@ type CacheSegmentedTree struct {
 }
 
 func NewCacheSegmentedTree(storage RuleBuilder) *CacheSegmentedTree {
+	return newCacheSegmentedTree(storage, ruleCacheUpdateInterval)
+}
+
+func newCacheSegmentedTree(storage RuleBuilder, updateInterval time.Duration) *CacheSegmentedTree {
 	s := &CacheSegmentedTree{
 		radix:       map[int64]*tree.Node{},
 		ruleBuilder: storage,
 	}
-	go s.updatePeriodically()
+	go s.updatePeriodically(updateInterval)
 	return s
 }
 
-func (s *CacheSegmentedTree) updatePeriodically() {
+func (s *CacheSegmentedTree) updatePeriodically(interval time.Duration) {
 	for {
 		var orgIDs []int64
 		s.radixMu.Lock()
@@ -39,7 +48,7 @@ func (s *CacheSegmentedTree) updatePeriodically() {
 				logger.Error("Error filling orgId", "error", err, "orgId", orgID)
 			}
 		}
-		time.Sleep(20 * time.Second)
+		time.Sleep(interval)
 	}
 }
 
@@ -59,6 +68,18 @@ func (s *CacheSegmentedTree) fillOrg(orgID int64) error {
 	return nil
 }
 
+// Reload rebuilds the cached channel rules of the org, for example after they changed in
+// the storage. Nothing is done if the rules of the org were not used yet.
+func (s *CacheSegmentedTree) Reload(orgID int64) error {
+	s.radixMu.RLock()
+	_, ok := s.radix[orgID]
+	s.radixMu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return s.fillOrg(orgID)
+}
+
 func (s *CacheSegmentedTree) Get(orgID int64, channel string) (*LiveChannelRule, bool, error) {
 	s.radixMu.RLock()
 	_, ok := s.radix[orgID]
@@ -2,7 +2,9 @@ package pipeline
 
 import (
 	"context"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -53,6 +55,56 @@ func TestStorage_Get(t *testing.T) {
 	require.Equal(t, "stream/boom:er", rule.Pattern)
 }
 
+type testBuilderFunc func(orgID int64) []*LiveChannelRule
+
+func (f testBuilderFunc) BuildRules(_ context.Context, orgID int64) ([]*LiveChannelRule, error) {
+	return f(orgID), nil
+}
+
+func TestStorage_Reload(t *testing.T) {
+	pattern := "stream/telegraf/cpu"
+	s := NewCacheSegmentedTree(testBuilderFunc(func(orgID int64) []*LiveChannelRule {
+		return []*LiveChannelRule{{OrgId: orgID, Pattern: pattern}}
+	}))
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	pattern = "stream/telegraf/mem"
+	_, ok, err = s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.NoError(t, s.Reload(1))
+	_, ok, err = s.Get(1, "stream/telegraf/mem")
+	require.NoError(t, err)
+	require.True(t, ok)
+	_, ok, err = s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestStorage_UpdatePeriodically(t *testing.T) {
+	var mu sync.Mutex
+	pattern := "stream/telegraf/cpu"
+	s := newCacheSegmentedTree(testBuilderFunc(func(orgID int64) []*LiveChannelRule {
+		mu.Lock()
+		defer mu.Unlock()
+		return []*LiveChannelRule{{OrgId: orgID, Pattern: pattern}}
+	}), 10*time.Millisecond)
+	_, ok, err := s.Get(1, "stream/telegraf/cpu")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	mu.Lock()
+	pattern = "stream/telegraf/mem"
+	mu.Unlock()
+	require.Eventually(t, func() bool {
+		_, ok, err := s.Get(1, "stream/telegraf/mem")
+		return err == nil && ok
+	}, time.Second, 10*time.Millisecond)
+}
+
 func BenchmarkRuleGet(b *testing.B) {
 	s := NewCacheSegmentedTree(&testBuilder{})
 	for i := 0; i < b.N; i++ {
@@ -1,6 +1,16 @@
 package pipeline
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var (
+	ErrChannelRuleNotFound = errors.New("rule not found")
+	ErrWriteConfigNotFound = errors.New("write config not found")
+	// ErrVersionConflict is returned when an update is based on an outdated version.
+	ErrVersionConflict = errors.New("version conflict")
+)
 
 // Storage describes all methods to manage Live pipeline persistent data.
 type Storage interface {
@@ -0,0 +1,398 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DatabaseStorage stores channel rules and write configs in the Grafana database, so
+// all Grafana instances of a deployment share them. Every change of a rule or write
+// config increments its version.
+type DatabaseStorage struct {
+	store          db.DB
+	secretsService secrets.Service
+}
+
+func NewDatabaseStorage(store db.DB, secretsService secrets.Service) *DatabaseStorage {
+	return &DatabaseStorage{store: store, secretsService: secretsService}
+}
+
+type channelRuleRow struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	OrgID    int64     `xorm:"org_id"`
+	Pattern  string    `xorm:"pattern"`
+	Version  int64     `xorm:"'version'"`
+	Settings string    `xorm:"settings"`
+	Created  time.Time `xorm:"'created'"`
+	Updated  time.Time `xorm:"'updated'"`
+}
+
+func (channelRuleRow) TableName() string {
+	return "live_channel_rule"
+}
+
+func (r channelRuleRow) toChannelRule() (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:   r.OrgID,
+		Pattern: r.Pattern,
+		Version: r.Version,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &rule.Settings); err != nil {
+		return ChannelRule{}, fmt.Errorf("can't unmarshal settings of channel rule %s: %w", r.Pattern, err)
+	}
+	return rule, nil
+}
+
+type writeConfigRow struct {
+	ID             int64     `xorm:"pk autoincr 'id'"`
+	OrgID          int64     `xorm:"org_id"`
+	UID            string    `xorm:"uid"`
+	Version        int64     `xorm:"'version'"`
+	Settings       string    `xorm:"settings"`
+	SecureSettings string    `xorm:"secure_settings"`
+	Created        time.Time `xorm:"'created'"`
+	Updated        time.Time `xorm:"'updated'"`
+}
+
+func (writeConfigRow) TableName() string {
+	return "live_write_config"
+}
+
+func (r writeConfigRow) toWriteConfig() (WriteConfig, error) {
+	writeConfig := WriteConfig{
+		OrgId:   r.OrgID,
+		UID:     r.UID,
+		Version: r.Version,
+	}
+	if err := json.Unmarshal([]byte(r.Settings), &writeConfig.Settings); err != nil {
+		return WriteConfig{}, fmt.Errorf("can't unmarshal settings of write config %s: %w", r.UID, err)
+	}
+	if r.SecureSettings != "" {
+		if err := json.Unmarshal([]byte(r.SecureSettings), &writeConfig.SecureSettings); err != nil {
+			return WriteConfig{}, fmt.Errorf("can't unmarshal secure settings of write config %s: %w", r.UID, err)
+		}
+	}
+	return writeConfig, nil
+}
+
+func (s *DatabaseStorage) ListWriteConfigs(ctx context.Context, orgID int64) ([]WriteConfig, error) {
+	var rows []writeConfigRow
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", orgID).Asc("uid").Find(&rows)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can't read write configs: %w", err)
+	}
+	writeConfigs := make([]WriteConfig, 0, len(rows))
+	for _, row := range rows {
+		writeConfig, err := row.toWriteConfig()
+		if err != nil {
+			return nil, err
+		}
+		writeConfigs = append(writeConfigs, writeConfig)
+	}
+	return writeConfigs, nil
+}
+
+func (s *DatabaseStorage) GetWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigGetCmd) (WriteConfig, bool, error) {
+	var (
+		row writeConfigRow
+		ok  bool
+	)
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		ok, err = sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&row)
+		return err
+	})
+	if err != nil {
+		return WriteConfig{}, false, fmt.Errorf("can't read write config: %w", err)
+	}
+	if !ok {
+		return WriteConfig{}, false, nil
+	}
+	writeConfig, err := row.toWriteConfig()
+	if err != nil {
+		return WriteConfig{}, false, err
+	}
+	return writeConfig, true, nil
+}
+
+// newWriteConfig returns the write config with encrypted secure settings.
+func (s *DatabaseStorage) newWriteConfig(ctx context.Context, orgID int64, uid string, settings WriteSettings, secureSettings map[string]string) (WriteConfig, error) {
+	encryptedSettings, err := s.secretsService.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return WriteConfig{}, fmt.Errorf("error encrypting data: %w", err)
+	}
+	writeConfig := WriteConfig{
+		OrgId:          orgID,
+		UID:            uid,
+		Settings:       settings,
+		SecureSettings: encryptedSettings,
+	}
+	ok, reason := writeConfig.Valid()
+	if !ok {
+		return WriteConfig{}, fmt.Errorf("invalid write config: %s", reason)
+	}
+	return writeConfig, nil
+}
+
+func (s *DatabaseStorage) CreateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigCreateCmd) (WriteConfig, error) {
+	if cmd.UID == "" {
+		cmd.UID = util.GenerateShortUID()
+	}
+	writeConfig, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		return insertWriteConfig(sess, &writeConfig)
+	})
+	return writeConfig, err
+}
+
+func insertWriteConfig(sess *db.Session, writeConfig *WriteConfig) error {
+	exists, err := sess.Where("org_id = ? AND uid = ?", writeConfig.OrgId, writeConfig.UID).Exist(&writeConfigRow{})
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("backend already exists in org: %s", writeConfig.UID)
+	}
+	row, err := writeConfigToRow(*writeConfig)
+	if err != nil {
+		return err
+	}
+	row.Version = 1
+	row.Created = row.Updated
+	if _, err := sess.Insert(&row); err != nil {
+		return fmt.Errorf("can't save write config: %w", err)
+	}
+	writeConfig.Version = row.Version
+	return nil
+}
+
+func writeConfigToRow(writeConfig WriteConfig) (writeConfigRow, error) {
+	settings, err := json.Marshal(writeConfig.Settings)
+	if err != nil {
+		return writeConfigRow{}, fmt.Errorf("can't marshal write config settings: %w", err)
+	}
+	secureSettings, err := json.Marshal(writeConfig.SecureSettings)
+	if err != nil {
+		return writeConfigRow{}, fmt.Errorf("can't marshal write config secure settings: %w", err)
+	}
+	return writeConfigRow{
+		OrgID:          writeConfig.OrgId,
+		UID:            writeConfig.UID,
+		Version:        writeConfig.Version,
+		Settings:       string(settings),
+		SecureSettings: string(secureSettings),
+		Updated:        time.Now(),
+	}, nil
+}
+
+// UpdateWriteConfig updates the write config or creates it if it does not exist and the
+// command has no version.
+func (s *DatabaseStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigUpdateCmd) (WriteConfig, error) {
+	writeConfig, err := s.newWriteConfig(ctx, orgID, cmd.UID, cmd.Settings, cmd.SecureSettings)
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	err = s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing writeConfigRow
+		ok, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Get(&existing)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if cmd.Version != 0 {
+				return ErrWriteConfigNotFound
+			}
+			return insertWriteConfig(sess, &writeConfig)
+		}
+		if cmd.Version != 0 && cmd.Version != existing.Version {
+			return ErrVersionConflict
+		}
+		writeConfig.Version = existing.Version + 1
+		row, err := writeConfigToRow(writeConfig)
+		if err != nil {
+			return err
+		}
+		affected, err := sess.Where("id = ? AND version = ?", existing.ID, existing.Version).
+			Cols("version", "settings", "secure_settings", "updated").Update(&row)
+		if err != nil {
+			return fmt.Errorf("can't save write config: %w", err)
+		}
+		if affected == 0 {
+			return ErrVersionConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return WriteConfig{}, err
+	}
+	return writeConfig, nil
+}
+
+func (s *DatabaseStorage) DeleteWriteConfig(ctx context.Context, orgID int64, cmd WriteConfigDeleteCmd) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND uid = ?", orgID, cmd.UID).Delete(&writeConfigRow{})
+		if err != nil {
+			return fmt.Errorf("can't delete write config: %w", err)
+		}
+		if affected == 0 {
+			return ErrWriteConfigNotFound
+		}
+		return nil
+	})
+}
+
+func (s *DatabaseStorage) ListChannelRules(ctx context.Context, orgID int64) ([]ChannelRule, error) {
+	var rules []ChannelRule
+	err := s.store.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		rules, err = listChannelRules(sess, orgID)
+		return err
+	})
+	return rules, err
+}
+
+func listChannelRules(sess *db.Session, orgID int64) ([]ChannelRule, error) {
+	var rows []channelRuleRow
+	if err := sess.Where("org_id = ?", orgID).Asc("pattern").Find(&rows); err != nil {
+		return nil, fmt.Errorf("can't read channel rules: %w", err)
+	}
+	rules := make([]ChannelRule, 0, len(rows))
+	for _, row := range rows {
+		rule, err := row.toChannelRule()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// saveChannelRule inserts the rule, or updates the existing rule with the same pattern if
+// the version of the rule is not 0. It checks that the rule does not conflict with the
+// other rules of the org.
+func saveChannelRule(sess *db.Session, rule *ChannelRule, existing []ChannelRule) error {
+	rules := make([]ChannelRule, 0, len(existing)+1)
+	for _, r := range existing {
+		if r.Pattern != rule.Pattern {
+			rules = append(rules, r)
+		}
+	}
+	rules = append(rules, *rule)
+	ok, reason := checkRulesValid(rule.OrgId, rules)
+	if !ok {
+		return fmt.Errorf("invalid channel rule: %s", reason)
+	}
+
+	settings, err := json.Marshal(rule.Settings)
+	if err != nil {
+		return fmt.Errorf("can't marshal channel rule settings: %w", err)
+	}
+	row := channelRuleRow{
+		OrgID:    rule.OrgId,
+		Pattern:  rule.Pattern,
+		Version:  rule.Version + 1,
+		Settings: string(settings),
+		Updated:  time.Now(),
+	}
+	if rule.Version == 0 {
+		row.Created = row.Updated
+		if _, err := sess.Insert(&row); err != nil {
+			return fmt.Errorf("can't save channel rule: %w", err)
+		}
+	} else {
+		affected, err := sess.Where("org_id = ? AND pattern = ? AND version = ?", rule.OrgId, rule.Pattern, rule.Version).
+			Cols("version", "settings", "updated").Update(&row)
+		if err != nil {
+			return fmt.Errorf("can't save channel rule: %w", err)
+		}
+		if affected == 0 {
+			return ErrVersionConflict
+		}
+	}
+	rule.Version = row.Version
+	return nil
+}
+
+func (s *DatabaseStorage) CreateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleCreateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		for _, r := range existing {
+			if r.Pattern == rule.Pattern {
+				return fmt.Errorf("pattern already exists in org: %s", rule.Pattern)
+			}
+		}
+		return saveChannelRule(sess, &rule, existing)
+	})
+	return rule, err
+}
+
+// UpdateChannelRule updates the rule or creates it if it does not exist and the command
+// has no version.
+func (s *DatabaseStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleUpdateCmd) (ChannelRule, error) {
+	rule := ChannelRule{
+		OrgId:    orgID,
+		Pattern:  cmd.Pattern,
+		Settings: cmd.Settings,
+	}
+	ok, reason := rule.Valid()
+	if !ok {
+		return rule, fmt.Errorf("invalid channel rule: %s", reason)
+	}
+	err := s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing, err := listChannelRules(sess, orgID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, r := range existing {
+			if r.Pattern == rule.Pattern {
+				found = true
+				if cmd.Version != 0 && cmd.Version != r.Version {
+					return ErrVersionConflict
+				}
+				rule.Version = r.Version
+			}
+		}
+		if !found && cmd.Version != 0 {
+			return ErrChannelRuleNotFound
+		}
+		return saveChannelRule(sess, &rule, existing)
+	})
+	return rule, err
+}
+
+func (s *DatabaseStorage) DeleteChannelRule(ctx context.Context, orgID int64, cmd ChannelRuleDeleteCmd) error {
+	return s.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND pattern = ?", orgID, cmd.Pattern).Delete(&channelRuleRow{})
+		if err != nil {
+			return fmt.Errorf("can't delete channel rule: %w", err)
+		}
+		if affected == 0 {
+			return ErrChannelRuleNotFound
+		}
+		return nil
+	})
+}
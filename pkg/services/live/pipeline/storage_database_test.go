@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationDatabaseStorage_ChannelRules(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	storage := NewDatabaseStorage(db.InitTestDB(t), fakes.NewFakeSecretsService())
+
+	rule, err := storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{
+		Pattern: "stream/telegraf/:metric",
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeJsonFrame},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), rule.Version)
+
+	_, err = storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:metric"})
+	require.Error(t, err)
+
+	_, err = storage.CreateChannelRule(ctx, 1, ChannelRuleCreateCmd{Pattern: "stream/telegraf/:other"})
+	require.Error(t, err, "conflicting patterns must be rejected")
+
+	rules, err := storage.ListChannelRules(ctx, 2)
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	rule, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{
+		Pattern: "stream/telegraf/:metric",
+		Version: 1,
+		Settings: ChannelRuleSettings{
+			Converter: &ConverterConfig{Type: ConverterTypeInfluxAuto, AutoInfluxConverterConfig: &AutoInfluxConverterConfig{}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), rule.Version)
+
+	_, err = storage.UpdateChannelRule(ctx, 1, ChannelRuleUpdateCmd{Pattern: "stream/telegraf/:metric", Version: 1})
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	rules, err = storage.ListChannelRules(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, int64(2), rules[0].Version)
+	require.Equal(t, ConverterTypeInfluxAuto, rules[0].Settings.Converter.Type)
+
+	require.NoError(t, storage.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/telegraf/:metric"}))
+	require.ErrorIs(t, storage.DeleteChannelRule(ctx, 1, ChannelRuleDeleteCmd{Pattern: "stream/telegraf/:metric"}), ErrChannelRuleNotFound)
+}
+
+func TestIntegrationDatabaseStorage_WriteConfigs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	storage := NewDatabaseStorage(db.InitTestDB(t), fakes.NewFakeSecretsService())
+
+	writeConfig, err := storage.CreateWriteConfig(ctx, 1, WriteConfigCreateCmd{
+		Settings:       WriteSettings{Endpoint: "http://localhost:9090/api/v1/write"},
+		SecureSettings: map[string]string{"basicAuthPassword": "secret"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, writeConfig.UID)
+	require.Equal(t, int64(1), writeConfig.Version)
+
+	stored, ok, err := storage.GetWriteConfig(ctx, 1, WriteConfigGetCmd{UID: writeConfig.UID})
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, writeConfig, stored)
+
+	_, ok, err = storage.GetWriteConfig(ctx, 2, WriteConfigGetCmd{UID: writeConfig.UID})
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	updated, err := storage.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      writeConfig.UID,
+		Version:  1,
+		Settings: WriteSettings{Endpoint: "http://localhost:9091/api/v1/write"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), updated.Version)
+
+	_, err = storage.UpdateWriteConfig(ctx, 1, WriteConfigUpdateCmd{
+		UID:      writeConfig.UID,
+		Version:  1,
+		Settings: WriteSettings{Endpoint: "http://localhost:9092/api/v1/write"},
+	})
+	require.ErrorIs(t, err, ErrVersionConflict)
+
+	writeConfigs, err := storage.ListWriteConfigs(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, writeConfigs, 1)
+	require.Equal(t, "http://localhost:9091/api/v1/write", writeConfigs[0].Settings.Endpoint)
+
+	require.NoError(t, storage.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}))
+	require.ErrorIs(t, storage.DeleteWriteConfig(ctx, 1, WriteConfigDeleteCmd{UID: writeConfig.UID}), ErrWriteConfigNotFound)
+}
@@ -114,7 +114,11 @@ func (f *FileStorage) UpdateWriteConfig(ctx context.Context, orgID int64, cmd Wr
 	if index > -1 {
 		writeConfigs.Configs[index] = backend
 	} else {
-		return f.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd(cmd))
+		return f.CreateWriteConfig(ctx, orgID, WriteConfigCreateCmd{
+			UID:            cmd.UID,
+			Settings:       cmd.Settings,
+			SecureSettings: cmd.SecureSettings,
+		})
 	}
 
 	err = f.saveWriteConfigs(orgID, writeConfigs)
@@ -138,7 +142,7 @@ func (f *FileStorage) DeleteWriteConfig(_ context.Context, orgID int64, cmd Writ
 	if index > -1 {
 		writeConfigs.Configs = removeWriteConfigByIndex(writeConfigs.Configs, index)
 	} else {
-		return fmt.Errorf("write config not found")
+		return ErrWriteConfigNotFound
 	}
 
 	return f.saveWriteConfigs(orgID, writeConfigs)
@@ -220,7 +224,10 @@ func (f *FileStorage) UpdateChannelRule(ctx context.Context, orgID int64, cmd Ch
 	if index > -1 {
 		channelRules.Rules[index] = rule
 	} else {
-		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd(cmd))
+		return f.CreateChannelRule(ctx, orgID, ChannelRuleCreateCmd{
+			Pattern:  cmd.Pattern,
+			Settings: cmd.Settings,
+		})
 	}
 
 	err = f.saveChannelRules(orgID, channelRules)
@@ -290,7 +297,7 @@ func (f *FileStorage) DeleteChannelRule(_ context.Context, orgID int64, cmd Chan
 	if index > -1 {
 		channelRules.Rules = removeChannelRuleByIndex(channelRules.Rules, index)
 	} else {
-		return fmt.Errorf("rule not found")
+		return ErrChannelRuleNotFound
 	}
 
 	return f.saveChannelRules(orgID, channelRules)
@@ -0,0 +1,58 @@
+package pipeline
+
+import (
+	"reflect"
+	"sync"
+)
+
+// WindowAggregateStorage keeps the window aggregate processors of channel rules in memory,
+// so that their open windows are not lost when the rules are built again, which happens
+// periodically and whenever the rules of an org change. Not usable in HA setup.
+type WindowAggregateStorage struct {
+	mu sync.Mutex
+	// processors by orgID and path of the processor in the channel rules of the org.
+	processors map[int64]map[string]windowAggregateEntry
+}
+
+type windowAggregateEntry struct {
+	config    WindowAggregateFrameProcessorConfig
+	processor *WindowAggregateFrameProcessor
+}
+
+func NewWindowAggregateStorage() *WindowAggregateStorage {
+	return &WindowAggregateStorage{
+		processors: map[int64]map[string]windowAggregateEntry{},
+	}
+}
+
+// Get returns the processor at the path in the channel rules of the org. The processor of a
+// previous build is reused if its configuration is unchanged.
+func (s *WindowAggregateStorage) Get(orgID int64, path string, config WindowAggregateFrameProcessorConfig) *WindowAggregateFrameProcessor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	processors, ok := s.processors[orgID]
+	if !ok {
+		processors = map[string]windowAggregateEntry{}
+		s.processors[orgID] = processors
+	}
+	if entry, ok := processors[path]; ok && reflect.DeepEqual(entry.config, config) {
+		return entry.processor
+	}
+	processor := NewWindowAggregateFrameProcessor(config)
+	processors[path] = windowAggregateEntry{config: config, processor: processor}
+	return processor
+}
+
+// Retain removes the processors of the org that are not at one of the paths anymore.
+func (s *WindowAggregateStorage) Retain(orgID int64, paths map[string]struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for path := range s.processors[orgID] {
+		if _, ok := paths[path]; !ok {
+			delete(s.processors[orgID], path)
+		}
+	}
+	if len(s.processors[orgID]) == 0 {
+		delete(s.processors, orgID)
+	}
+}
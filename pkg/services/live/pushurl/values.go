@@ -1,12 +1,16 @@
 package pushurl
 
 import (
+	"mime"
 	"net/url"
 	"strings"
+
+	"github.com/grafana/grafana/pkg/services/live/convert"
 )
 
 const (
 	frameFormatParam = "gf_live_frame_format"
+	inputFormatParam = "gf_live_input_format"
 )
 
 // FrameFormatFromValues extracts frame format tip from url values.
@@ -17,3 +21,30 @@ func FrameFormatFromValues(values url.Values) string {
 	}
 	return frameFormat
 }
+
+// InputFormatFromValues extracts input format tip from url values. Without tip, the
+// input format is detected from the content type, if any, and defaults to Influx line
+// protocol. OTLP encoded in JSON requires the tip, because clients have always been
+// able to push Influx line protocol with any other content type.
+func InputFormatFromValues(values url.Values, contentType string) string {
+	if inputFormat := strings.ToLower(values.Get(inputFormatParam)); inputFormat != "" {
+		return inputFormat
+	}
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return convert.InputFormatInflux
+	}
+	switch mediaType {
+	case "application/openmetrics-text":
+		return convert.InputFormatPrometheus
+	case "text/plain":
+		// The Prometheus text exposition format has a version, Influx line protocol does not.
+		if params["version"] != "" {
+			return convert.InputFormatPrometheus
+		}
+	case "application/x-protobuf":
+		// The content type of OTLP/HTTP in protobuf.
+		return convert.InputFormatOTLP
+	}
+	return convert.InputFormatInflux
+}
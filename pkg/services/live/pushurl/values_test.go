@@ -13,3 +13,32 @@ func TestFrameFormatFromValues(t *testing.T) {
 	values.Set(frameFormatParam, "wide")
 	require.Equal(t, "wide", FrameFormatFromValues(values))
 }
+
+func TestInputFormatFromValues(t *testing.T) {
+	values := url.Values{}
+	require.Equal(t, "influx", InputFormatFromValues(values, ""))
+	require.Equal(t, "influx", InputFormatFromValues(values, "text/plain; charset=utf-8"))
+	require.Equal(t, "prometheus", InputFormatFromValues(values, "text/plain; version=0.0.4; charset=utf-8"))
+	require.Equal(t, "prometheus", InputFormatFromValues(values, "application/openmetrics-text; version=1.0.0; charset=utf-8"))
+	require.Equal(t, "otlp", InputFormatFromValues(values, "application/x-protobuf"))
+	values.Set(inputFormatParam, "otlp")
+	require.Equal(t, "otlp", InputFormatFromValues(values, "application/json"))
+	values.Set(inputFormatParam, "Prometheus")
+	require.Equal(t, "prometheus", InputFormatFromValues(values, "application/x-protobuf"))
+}
+
+func TestInputFormatFromValues_InfluxByDefault(t *testing.T) {
+	// Clients pushed Influx line protocol with any content type before the input format
+	// was detected, so only the content types of other formats change the default.
+	for _, contentType := range []string{
+		"",
+		"invalid",
+		"text/plain",
+		"application/json",
+		"application/json; charset=utf-8",
+		"application/octet-stream",
+		"application/x-www-form-urlencoded",
+	} {
+		require.Equal(t, "influx", InputFormatFromValues(url.Values{}, contentType), contentType)
+	}
+}
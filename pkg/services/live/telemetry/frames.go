@@ -0,0 +1,82 @@
+package telemetry
+
+import (
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// MetricFamilyFrames builds frames from the samples of metric families, such as the
+// families of the Prometheus exposition format or the metrics of OTLP. It generates
+// one frame for each family and timestamp combination. Every series of the family is
+// a field of the frame, named after the metric of the series and with its labels.
+type MetricFamilyFrames struct {
+	// maintain the order of frames as they appear in input.
+	keys   []familyFrameKey
+	frames map[familyFrameKey]*familyFrame
+}
+
+type familyFrameKey struct {
+	family string
+	time   int64
+}
+
+// NewMetricFamilyFrames creates new MetricFamilyFrames.
+func NewMetricFamilyFrames() *MetricFamilyFrames {
+	return &MetricFamilyFrames{
+		frames: make(map[familyFrameKey]*familyFrame),
+	}
+}
+
+// Append adds a sample of a series of the family. A sample of a series that already
+// has a sample at the same time replaces it.
+func (f *MetricFamilyFrames) Append(family string, metric string, labels data.Labels, t time.Time, value float64) {
+	key := familyFrameKey{family: family, time: t.UnixNano()}
+	frame, ok := f.frames[key]
+	if !ok {
+		frame = &familyFrame{
+			key:        family,
+			fields:     []*data.Field{data.NewField("time", nil, []time.Time{t})},
+			fieldCache: map[string]int{},
+		}
+		f.frames[key] = frame
+		f.keys = append(f.keys, key)
+	}
+	frame.set(metric, labels, value)
+}
+
+// FrameWrappers returns the frames in the order their families and timestamps appeared in input.
+func (f *MetricFamilyFrames) FrameWrappers() []FrameWrapper {
+	frameWrappers := make([]FrameWrapper, 0, len(f.keys))
+	for _, key := range f.keys {
+		frameWrappers = append(frameWrappers, f.frames[key])
+	}
+	return frameWrappers
+}
+
+type familyFrame struct {
+	key        string
+	fields     []*data.Field
+	fieldCache map[string]int
+}
+
+// Key returns a key which describes Frame metrics.
+func (s *familyFrame) Key() string {
+	return s.key
+}
+
+// Frame transforms familyFrame to Grafana data.Frame.
+func (s *familyFrame) Frame() *data.Frame {
+	return data.NewFrame(s.key, s.fields...)
+}
+
+func (s *familyFrame) set(metric string, labels data.Labels, value float64) {
+	fieldKey := metric + labels.String()
+	if index, ok := s.fieldCache[fieldKey]; ok {
+		s.fields[index].Set(0, &value)
+		return
+	}
+	field := data.NewField(metric, labels, []*float64{&value})
+	s.fields = append(s.fields, field)
+	s.fieldCache[fieldKey] = len(s.fields) - 1
+}
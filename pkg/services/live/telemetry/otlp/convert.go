@@ -0,0 +1,202 @@
+package otlp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// resourceLabels are the attributes of a resource that identify it. They are added to
+// the labels of its series.
+var resourceLabels = []string{"service.name", "service.namespace", "service.instance.id"}
+
+// Converter converts OTLP/HTTP metrics export requests to Grafana frames.
+type Converter struct {
+	nowTimeFunc func() time.Time
+}
+
+// NewConverter creates new Converter from OTLP metrics to Grafana Data Frames.
+// This converter generates one frame for each metric and time combination. The
+// series of histograms and summaries are named like in Prometheus, for example
+// <metric>_bucket, <metric>_sum and <metric>_count. Exponential histograms only
+// have the _sum and _count series.
+func NewConverter() *Converter {
+	return &Converter{nowTimeFunc: time.Now}
+}
+
+// Convert metrics. The input is decoded from JSON if it is a JSON object, otherwise
+// from protobuf.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	var err error
+	if isJSON(body) {
+		err = protojson.Unmarshal(body, req)
+	} else {
+		err = proto.Unmarshal(body, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding metrics: %w", err)
+	}
+
+	b := &builder{frames: telemetry.NewMetricFamilyFrames(), now: c.nowTimeFunc()}
+	for _, rm := range req.ResourceMetrics {
+		resource := data.Labels{}
+		for _, attr := range rm.GetResource().GetAttributes() {
+			for _, name := range resourceLabels {
+				if attr.Key == name {
+					resource[name] = anyValueString(attr.Value)
+				}
+			}
+		}
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				b.appendMetric(m, resource)
+			}
+		}
+	}
+	return b.frames.FrameWrappers(), nil
+}
+
+func isJSON(body []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("{"))
+}
+
+type builder struct {
+	frames *telemetry.MetricFamilyFrames
+	now    time.Time
+}
+
+func (b *builder) appendMetric(m *metricspb.Metric, resource data.Labels) {
+	name := m.Name
+	switch d := m.Data.(type) {
+	case *metricspb.Metric_Gauge:
+		b.appendNumberDataPoints(name, d.Gauge.DataPoints, resource)
+	case *metricspb.Metric_Sum:
+		b.appendNumberDataPoints(name, d.Sum.DataPoints, resource)
+	case *metricspb.Metric_Histogram:
+		for _, dp := range d.Histogram.DataPoints {
+			if noRecordedValue(dp.Flags) {
+				continue
+			}
+			t := b.time(dp.TimeUnixNano)
+			var cumulative uint64
+			for i, count := range dp.BucketCounts {
+				cumulative += count
+				le := "+Inf"
+				if i < len(dp.ExplicitBounds) {
+					le = strconv.FormatFloat(dp.ExplicitBounds[i], 'g', -1, 64)
+				}
+				lbls := toLabels(dp.Attributes, resource)
+				lbls["le"] = le
+				b.frames.Append(name, name+"_bucket", lbls, t, float64(cumulative))
+			}
+			if dp.Sum != nil {
+				b.frames.Append(name, name+"_sum", toLabels(dp.Attributes, resource), t, *dp.Sum)
+			}
+			b.frames.Append(name, name+"_count", toLabels(dp.Attributes, resource), t, float64(dp.Count))
+		}
+	case *metricspb.Metric_ExponentialHistogram:
+		for _, dp := range d.ExponentialHistogram.DataPoints {
+			if noRecordedValue(dp.Flags) {
+				continue
+			}
+			t := b.time(dp.TimeUnixNano)
+			if dp.Sum != nil {
+				b.frames.Append(name, name+"_sum", toLabels(dp.Attributes, resource), t, *dp.Sum)
+			}
+			b.frames.Append(name, name+"_count", toLabels(dp.Attributes, resource), t, float64(dp.Count))
+		}
+	case *metricspb.Metric_Summary:
+		for _, dp := range d.Summary.DataPoints {
+			if noRecordedValue(dp.Flags) {
+				continue
+			}
+			t := b.time(dp.TimeUnixNano)
+			for _, q := range dp.QuantileValues {
+				lbls := toLabels(dp.Attributes, resource)
+				lbls["quantile"] = strconv.FormatFloat(q.Quantile, 'g', -1, 64)
+				b.frames.Append(name, name, lbls, t, q.Value)
+			}
+			b.frames.Append(name, name+"_sum", toLabels(dp.Attributes, resource), t, dp.Sum)
+			b.frames.Append(name, name+"_count", toLabels(dp.Attributes, resource), t, float64(dp.Count))
+		}
+	}
+}
+
+func (b *builder) appendNumberDataPoints(name string, points []*metricspb.NumberDataPoint, resource data.Labels) {
+	for _, dp := range points {
+		if noRecordedValue(dp.Flags) {
+			continue
+		}
+		var value float64
+		switch v := dp.Value.(type) {
+		case *metricspb.NumberDataPoint_AsDouble:
+			value = v.AsDouble
+		case *metricspb.NumberDataPoint_AsInt:
+			value = float64(v.AsInt)
+		default:
+			continue
+		}
+		b.frames.Append(name, name, toLabels(dp.Attributes, resource), b.time(dp.TimeUnixNano), value)
+	}
+}
+
+// time returns the time of a data point. Data points without time get the time of the conversion.
+func (b *builder) time(unixNano uint64) time.Time {
+	if unixNano == 0 {
+		return b.now
+	}
+	return time.Unix(0, int64(unixNano))
+}
+
+func noRecordedValue(flags uint32) bool {
+	return flags&uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK) != 0
+}
+
+func toLabels(attributes []*commonpb.KeyValue, resource data.Labels) data.Labels {
+	result := make(data.Labels, len(attributes)+len(resource))
+	for k, v := range resource {
+		result[k] = v
+	}
+	for _, attr := range attributes {
+		result[attr.Key] = anyValueString(attr.Value)
+	}
+	return result
+}
+
+func anyValueString(v *commonpb.AnyValue) string {
+	switch value := v.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return value.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return strconv.FormatBool(value.BoolValue)
+	case *commonpb.AnyValue_IntValue:
+		return strconv.FormatInt(value.IntValue, 10)
+	case *commonpb.AnyValue_DoubleValue:
+		return strconv.FormatFloat(value.DoubleValue, 'g', -1, 64)
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(value.BytesValue)
+	case nil:
+		return ""
+	default:
+		// Arrays and key-value lists are kept in their JSON encoding.
+		b, err := protojson.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,106 @@
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func testRequest(t time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	sum := 12.5
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+				stringAttribute("service.name", "checkout"),
+				stringAttribute("host.arch", "amd64"),
+			}},
+			ScopeMetrics: []*metricspb.ScopeMetrics{{
+				Metrics: []*metricspb.Metric{
+					{
+						Name: "queue_size",
+						Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{DataPoints: []*metricspb.NumberDataPoint{
+							{
+								Attributes:   []*commonpb.KeyValue{stringAttribute("queue", "a")},
+								TimeUnixNano: uint64(t.UnixNano()),
+								Value:        &metricspb.NumberDataPoint_AsInt{AsInt: 3},
+							},
+							{
+								Attributes:   []*commonpb.KeyValue{stringAttribute("queue", "b")},
+								TimeUnixNano: uint64(t.UnixNano()),
+								Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: 4.5},
+							},
+							{
+								Attributes:   []*commonpb.KeyValue{stringAttribute("queue", "c")},
+								TimeUnixNano: uint64(t.UnixNano()),
+								Flags:        uint32(metricspb.DataPointFlags_DATA_POINT_FLAGS_NO_RECORDED_VALUE_MASK),
+							},
+						}}},
+					},
+					{
+						Name: "request_duration",
+						Data: &metricspb.Metric_Histogram{Histogram: &metricspb.Histogram{DataPoints: []*metricspb.HistogramDataPoint{{
+							TimeUnixNano:   uint64(t.UnixNano()),
+							Count:          5,
+							Sum:            &sum,
+							BucketCounts:   []uint64{2, 3},
+							ExplicitBounds: []float64{1},
+						}}}},
+					},
+				},
+			}},
+		}},
+	}
+}
+
+func TestConverter_Convert(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	protoBody, err := proto.Marshal(testRequest(ts))
+	require.NoError(t, err)
+	jsonBody, err := protojson.Marshal(testRequest(ts))
+	require.NoError(t, err)
+
+	for name, body := range map[string][]byte{"protobuf": protoBody, "JSON": jsonBody} {
+		t.Run(name, func(t *testing.T) {
+			frameWrappers, err := NewConverter().Convert(body)
+			require.NoError(t, err)
+			require.Len(t, frameWrappers, 2)
+
+			require.Equal(t, "queue_size", frameWrappers[0].Key())
+			frame := frameWrappers[0].Frame()
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, ts, frame.Fields[0].At(0))
+			require.Equal(t, data.Labels{"service.name": "checkout", "queue": "a"}, frame.Fields[1].Labels)
+			require.Equal(t, 3.0, *frame.Fields[1].At(0).(*float64))
+			require.Equal(t, 4.5, *frame.Fields[2].At(0).(*float64))
+
+			require.Equal(t, "request_duration", frameWrappers[1].Key())
+			frame = frameWrappers[1].Frame()
+			require.Len(t, frame.Fields, 5)
+			require.Equal(t, "request_duration_bucket", frame.Fields[1].Name)
+			require.Equal(t, data.Labels{"service.name": "checkout", "le": "1"}, frame.Fields[1].Labels)
+			require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+			require.Equal(t, data.Labels{"service.name": "checkout", "le": "+Inf"}, frame.Fields[2].Labels)
+			require.Equal(t, 5.0, *frame.Fields[2].At(0).(*float64))
+			require.Equal(t, "request_duration_sum", frame.Fields[3].Name)
+			require.Equal(t, 12.5, *frame.Fields[3].At(0).(*float64))
+			require.Equal(t, "request_duration_count", frame.Fields[4].Name)
+		})
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := NewConverter().Convert([]byte(`{"resourceMetrics": 1}`))
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,110 @@
+package prometheus
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/textparse"
+
+	"github.com/grafana/grafana/pkg/services/live/telemetry"
+)
+
+var _ telemetry.Converter = (*Converter)(nil)
+
+// familySuffixes are the suffixes of the series of a metric family that are not
+// named after the family, such as the buckets of a histogram.
+var familySuffixes = []string{"_bucket", "_count", "_sum", "_created", "_total", "_info", "_gcount", "_gsum"}
+
+// Converter converts metrics in the Prometheus text exposition format or in the
+// OpenMetrics text format to Grafana frames.
+type Converter struct {
+	nowTimeFunc func() time.Time
+}
+
+// NewConverter creates new Converter from the Prometheus text formats to Grafana Data Frames.
+// This converter generates one frame for each metric family and time combination. Samples
+// without timestamp get the time of the conversion.
+func NewConverter() *Converter {
+	return &Converter{nowTimeFunc: time.Now}
+}
+
+// Convert metrics. The input is parsed as OpenMetrics if it ends with the # EOF marker that
+// OpenMetrics requires, otherwise as the Prometheus text exposition format.
+func (c *Converter) Convert(body []byte) ([]telemetry.FrameWrapper, error) {
+	var parser textparse.Parser
+	if isOpenMetrics(body) {
+		parser = textparse.NewOpenMetricsParser(body, labels.NewSymbolTable())
+	} else {
+		parser = textparse.NewPromParser(body, labels.NewSymbolTable())
+	}
+
+	now := c.nowTimeFunc()
+	frames := telemetry.NewMetricFamilyFrames()
+	var (
+		family string
+		lset   labels.Labels
+	)
+	for {
+		entry, err := parser.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error parsing metrics: %w", err)
+		}
+		switch entry {
+		case textparse.EntryHelp:
+			name, _ := parser.Help()
+			family = string(name)
+		case textparse.EntryType:
+			name, _ := parser.Type()
+			family = string(name)
+		case textparse.EntrySeries:
+			_, ts, value := parser.Series()
+			parser.Metric(&lset)
+			t := now
+			if ts != nil {
+				t = time.UnixMilli(*ts)
+			}
+			metric := lset.Get(labels.MetricName)
+			frames.Append(familyOf(family, metric), metric, toDataLabels(lset), t, value)
+		}
+	}
+	return frames.FrameWrappers(), nil
+}
+
+func isOpenMetrics(body []byte) bool {
+	return bytes.HasSuffix(bytes.TrimSpace(body), []byte("# EOF"))
+}
+
+// familyOf returns the family of the metric of a series. It is the family of the last
+// metadata if the metric belongs to it, otherwise the metric itself.
+func familyOf(family string, metric string) string {
+	if family == "" || metric == family {
+		return metric
+	}
+	if suffix, ok := strings.CutPrefix(metric, family); ok {
+		for _, s := range familySuffixes {
+			if suffix == s {
+				return family
+			}
+		}
+	}
+	return metric
+}
+
+func toDataLabels(lset labels.Labels) data.Labels {
+	result := data.Labels{}
+	lset.Range(func(l labels.Label) {
+		if l.Name != labels.MetricName {
+			result[l.Name] = l.Value
+		}
+	})
+	return result
+}
@@ -0,0 +1,88 @@
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConverter(now time.Time) *Converter {
+	c := NewConverter()
+	c.nowTimeFunc = func() time.Time { return now }
+	return c
+}
+
+func TestConverter_Convert(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("text exposition format", func(t *testing.T) {
+		body := []byte(`# HELP http_requests_total The total number of HTTP requests.
+# TYPE http_requests_total counter
+http_requests_total{method="post",code="200"} 1027
+http_requests_total{method="post",code="400"} 3
+# HELP request_duration_seconds A histogram of the request duration.
+# TYPE request_duration_seconds histogram
+request_duration_seconds_bucket{le="0.5"} 24054
+request_duration_seconds_bucket{le="+Inf"} 144320
+request_duration_seconds_sum 53423
+request_duration_seconds_count 144320
+go_goroutines 12 1699999999000
+`)
+		frameWrappers, err := newTestConverter(now).Convert(body)
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 3)
+
+		require.Equal(t, "http_requests_total", frameWrappers[0].Key())
+		frame := frameWrappers[0].Frame()
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, now, frame.Fields[0].At(0))
+		require.Equal(t, "http_requests_total", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"method": "post", "code": "200"}, frame.Fields[1].Labels)
+		require.Equal(t, 1027.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, data.Labels{"method": "post", "code": "400"}, frame.Fields[2].Labels)
+
+		require.Equal(t, "request_duration_seconds", frameWrappers[1].Key())
+		frame = frameWrappers[1].Frame()
+		require.Len(t, frame.Fields, 5)
+		require.Equal(t, "request_duration_seconds_bucket", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"le": "0.5"}, frame.Fields[1].Labels)
+		require.Equal(t, "request_duration_seconds_sum", frame.Fields[3].Name)
+		require.Equal(t, "request_duration_seconds_count", frame.Fields[4].Name)
+
+		require.Equal(t, "go_goroutines", frameWrappers[2].Key())
+		frame = frameWrappers[2].Frame()
+		require.Equal(t, time.UnixMilli(1699999999000), frame.Fields[0].At(0))
+		require.Equal(t, 12.0, *frame.Fields[1].At(0).(*float64))
+	})
+
+	t.Run("OpenMetrics", func(t *testing.T) {
+		body := []byte(`# TYPE acme_http_router_request_seconds summary
+# UNIT acme_http_router_request_seconds seconds
+# HELP acme_http_router_request_seconds Latency though all of ACME's HTTP request router.
+acme_http_router_request_seconds_sum{path="/api/v1",method="GET"} 9036.32
+acme_http_router_request_seconds_count{path="/api/v1",method="GET"} 807283.0
+# TYPE process_start_time_seconds gauge
+process_start_time_seconds 1.7e+09
+# EOF
+`)
+		frameWrappers, err := newTestConverter(now).Convert(body)
+		require.NoError(t, err)
+		require.Len(t, frameWrappers, 2)
+
+		require.Equal(t, "acme_http_router_request_seconds", frameWrappers[0].Key())
+		frame := frameWrappers[0].Frame()
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, "acme_http_router_request_seconds_sum", frame.Fields[1].Name)
+		require.Equal(t, data.Labels{"path": "/api/v1", "method": "GET"}, frame.Fields[1].Labels)
+		require.Equal(t, 807283.0, *frame.Fields[2].At(0).(*float64))
+
+		require.Equal(t, "process_start_time_seconds", frameWrappers[1].Key())
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := newTestConverter(now).Convert([]byte("http_requests_total{method=\"post\" 1027\n"))
+		require.Error(t, err)
+	})
+}
@@ -75,6 +75,8 @@ type API struct {
 	ConditionValidator   *eval.ConditionValidator
 	FeatureManager       featuremgmt.FeatureToggles
 	Historian            Historian
+	NotificationHistory  NotificationHistoryStore
+	RecurringSilences    *notifier.RecurringSilenceService
 	Tracer               tracing.Tracer
 	AppUrl               *url.URL
 
@@ -109,6 +111,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 				api.RuleStore,
 				ruleAuthzService,
 			),
+			recurringSilenceSvc: api.RecurringSilences,
 		},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
@@ -133,6 +136,9 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			amConfigStore:      api.AlertingStore,
 			amRefresher:        api.MultiOrgAlertmanager,
 			featureManager:     api.FeatureManager,
+			evaluator:          api.EvaluatorFactory,
+			appUrl:             api.AppUrl,
+			tracer:             api.Tracer,
 		},
 	), m)
 	api.RegisterTestingApiEndpoints(NewTestingApi(
@@ -148,6 +154,7 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 			appUrl:          api.AppUrl,
 			tracer:          api.Tracer,
 			folderService:   api.RuleStore,
+			amConfigStore:   api.AlertingStore,
 		}), m)
 	api.RegisterConfigurationApiEndpoints(NewConfiguration(
 		&ConfigSrv{
@@ -176,8 +183,9 @@ func (api *API) RegisterAPIEndpoints(m *metrics.API) {
 	}), m)
 
 	api.RegisterNotificationsApiEndpoints(NewNotificationsApi(&NotificationSrv{
-		logger:            logger,
-		receiverService:   api.ReceiverService,
-		muteTimingService: api.MuteTimings,
+		logger:              logger,
+		receiverService:     api.ReceiverService,
+		muteTimingService:   api.MuteTimings,
+		notificationHistory: api.NotificationHistory,
 	}), m)
 }
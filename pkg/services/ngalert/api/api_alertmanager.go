@@ -35,6 +35,8 @@ type AlertmanagerSrv struct {
 	crypto         notifier.Crypto
 	silenceSvc     SilenceService
 	featureManager featuremgmt.FeatureToggles
+
+	recurringSilenceSvc RecurringSilenceService
 }
 
 type UnknownReceiverError struct {
@@ -96,6 +98,40 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfigHistory(c *contextmodel.ReqCont
 	return response.JSON(http.StatusOK, configs)
 }
 
+func (srv AlertmanagerSrv) RouteGetAlertingConfigVersions(c *contextmodel.ReqContext) response.Response {
+	limit := c.QueryInt("limit")
+	versions, err := srv.mam.GetAlertmanagerConfigurationVersions(c.Req.Context(), c.SignedInUser.GetOrgID(), limit)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, err.Error())
+	}
+
+	return response.JSON(http.StatusOK, versions)
+}
+
+func (srv AlertmanagerSrv) RouteGetAlertingConfigDiff(c *contextmodel.ReqContext) response.Response {
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse from")
+	}
+	var to int64
+	if c.Query("to") != "" {
+		to, err = strconv.ParseInt(c.Query("to"), 10, 64)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to parse to")
+		}
+	}
+
+	diff, err := srv.mam.DiffAlertmanagerConfigurationVersions(c.Req.Context(), c.SignedInUser.GetOrgID(), from, to)
+	if err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return response.JSON(http.StatusOK, diff)
+}
+
 func (srv AlertmanagerSrv) RouteGetAMAlertGroups(c *contextmodel.ReqContext) response.Response {
 	am, errResp := srv.AlertmanagerFor(c.SignedInUser.GetOrgID())
 	if errResp != nil {
@@ -181,7 +217,31 @@ func (srv AlertmanagerSrv) RoutePostGrafanaAlertingConfigHistoryActivate(c *cont
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration activated"})
 }
 
+func (srv AlertmanagerSrv) RoutePostGrafanaAlertingConfigHistoryRollback(c *contextmodel.ReqContext, id string) response.Response {
+	confId, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse config id")
+	}
+
+	config, err := srv.mam.GetHistoricalConfigurationForRollback(c.Req.Context(), c.SignedInUser.GetOrgID(), confId)
+	if err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return response.Error(http.StatusNotFound, err.Error(), err)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	// The historical configuration is validated and saved like a configuration that is posted.
+	return srv.saveAlertingConfig(c, config, "configuration rolled back")
+}
+
 func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *contextmodel.ReqContext, body apimodels.PostableUserConfig) response.Response {
+	return srv.saveAlertingConfig(c, body, "configuration created")
+}
+
+// saveAlertingConfig checks that the configuration does not change provisioned objects, and receivers if they are
+// managed by the k8s API, before it saves and applies it.
+func (srv AlertmanagerSrv) saveAlertingConfig(c *contextmodel.ReqContext, body apimodels.PostableUserConfig, message string) response.Response {
 	// Remove autogenerated config from the user config before checking provenance guard and eventually saving it.
 	// TODO: This and provenance guard should be moved to the notifier package.
 	notifier.RemoveAutogenConfigIfExists(body.AlertmanagerConfig.Route)
@@ -207,7 +267,7 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *contextmodel.ReqContext, b
 	}
 	err = srv.mam.SaveAndApplyAlertmanagerConfiguration(c.Req.Context(), c.SignedInUser.GetOrgID(), body)
 	if err == nil {
-		return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
+		return response.JSON(http.StatusAccepted, util.DynMap{"message": message})
 	}
 	var unknownReceiverError notifier.UnknownReceiverError
 	if errors.As(err, &unknownReceiverError) {
@@ -264,15 +324,20 @@ func (srv AlertmanagerSrv) RoutePostTestReceivers(c *contextmodel.ReqContext, bo
 		return errResp
 	}
 
+	var capture *notifier.TestNotificationCapture
+	if body.Capture {
+		ctx, capture = notifier.WithTestNotificationCapture(ctx)
+	}
+
 	result, status, err := am.TestReceivers(ctx, body)
 	if err != nil {
-		if errors.Is(err, alertingNotify.ErrNoReceivers) {
+		if errors.Is(err, alertingNotify.ErrNoReceivers) || errors.Is(err, notifier.ErrTestNotificationCaptureNotSupported) {
 			return response.Error(http.StatusBadRequest, "", err)
 		}
 		return response.Error(http.StatusInternalServerError, "", err)
 	}
 
-	return response.JSON(status, newTestReceiversResult(result))
+	return response.JSON(status, newTestReceiversResult(result, capture))
 }
 
 func (srv AlertmanagerSrv) RoutePostTestTemplates(c *contextmodel.ReqContext, body apimodels.TestTemplatesConfigBodyParams) response.Response {
@@ -311,7 +376,7 @@ func contextWithTimeoutFromRequest(ctx context.Context, r *http.Request, default
 	return ctx, cancelFunc, nil
 }
 
-func newTestReceiversResult(r *alertingNotify.TestReceiversResult) apimodels.TestReceiversResult {
+func newTestReceiversResult(r *alertingNotify.TestReceiversResult, capture *notifier.TestNotificationCapture) apimodels.TestReceiversResult {
 	v := apimodels.TestReceiversResult{
 		Alert: apimodels.TestReceiversConfigAlertParams{
 			Annotations: r.Alert.Annotations,
@@ -327,6 +392,7 @@ func newTestReceiversResult(r *alertingNotify.TestReceiversResult) apimodels.Tes
 			configs[jx].UID = config.UID
 			configs[jx].Status = config.Status
 			configs[jx].Error = config.Error
+			configs[jx].Captured = capture.Notifications(ix, jx)
 		}
 		v.Receivers[ix].Configs = configs
 		v.Receivers[ix].Name = next.Name
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RecurringSilenceService is the service for managing and authenticating access to the recurring silences of Grafana AM.
+type RecurringSilenceService interface {
+	GetRecurringSilence(ctx context.Context, user identity.Requester, uid string) (*models.RecurringSilence, error)
+	ListRecurringSilences(ctx context.Context, user identity.Requester) ([]*models.RecurringSilence, error)
+	SaveRecurringSilence(ctx context.Context, user identity.Requester, rs models.RecurringSilence) (string, error)
+	DeleteRecurringSilence(ctx context.Context, user identity.Requester, uid string) error
+}
+
+// RouteGetRecurringSilence is the single recurring silence GET endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteGetRecurringSilence(c *contextmodel.ReqContext, uid string) response.Response {
+	rs, err := srv.recurringSilenceSvc.GetRecurringSilence(c.Req.Context(), c.SignedInUser, uid)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get recurring silence", err)
+	}
+	return response.JSON(http.StatusOK, RecurringSilenceToGettableRecurringSilence(rs))
+}
+
+// RouteGetRecurringSilences is the recurring silence list GET endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteGetRecurringSilences(c *contextmodel.ReqContext) response.Response {
+	recurringSilences, err := srv.recurringSilenceSvc.ListRecurringSilences(c.Req.Context(), c.SignedInUser)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to list recurring silences", err)
+	}
+	result := make(apimodels.GettableRecurringSilences, 0, len(recurringSilences))
+	for _, rs := range recurringSilences {
+		result = append(result, RecurringSilenceToGettableRecurringSilence(rs))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// RouteCreateRecurringSilence is the recurring silence POST (create + update) endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteCreateRecurringSilence(c *contextmodel.ReqContext, postable apimodels.PostableRecurringSilence) response.Response {
+	uid, err := srv.recurringSilenceSvc.SaveRecurringSilence(c.Req.Context(), c.SignedInUser, PostableRecurringSilenceToRecurringSilence(postable))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create/update recurring silence", err)
+	}
+	return response.JSON(http.StatusAccepted, apimodels.PostRecurringSilenceOKBody{
+		UID: uid,
+	})
+}
+
+// RouteDeleteRecurringSilence is the recurring silence DELETE endpoint for Grafana AM.
+func (srv AlertmanagerSrv) RouteDeleteRecurringSilence(c *contextmodel.ReqContext, uid string) response.Response {
+	if err := srv.recurringSilenceSvc.DeleteRecurringSilence(c.Req.Context(), c.SignedInUser, uid); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete recurring silence", err)
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "recurring silence deleted"})
+}
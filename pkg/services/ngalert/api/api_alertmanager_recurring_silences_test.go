@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestRouteRecurringSilences(t *testing.T) {
+	matchers := amv2.Matchers{
+		{Name: util.Pointer("env"), Value: util.Pointer("prod"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)},
+	}
+	updated := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+	stored := &ngmodels.RecurringSilence{
+		OrgID:      1,
+		UID:        "maintenance",
+		Matchers:   matchers,
+		Schedule:   "0 2 * * SUN",
+		Duration:   2 * time.Hour,
+		Timezone:   "Europe/Berlin",
+		Comment:    "Weekly maintenance",
+		CreatedBy:  "admin",
+		Provenance: ngmodels.ProvenanceFile,
+		Updated:    updated,
+		Materialization: ngmodels.RecurringSilenceMaterialization{
+			Silences: []ngmodels.MaterializedSilence{{ID: "silence-1", EndsAt: updated.Add(time.Hour)}},
+		},
+	}
+	newRequest := func() *contextmodel.ReqContext {
+		return &contextmodel.ReqContext{
+			Context:      &web.Context{Req: &http.Request{}},
+			SignedInUser: &user.SignedInUser{OrgID: 1},
+		}
+	}
+	newSut := func(svc *fakeRecurringSilenceService) AlertmanagerSrv {
+		return AlertmanagerSrv{log: log.NewNopLogger(), recurringSilenceSvc: svc}
+	}
+
+	t.Run("returns the recurring silence", func(t *testing.T) {
+		sut := newSut(&fakeRecurringSilenceService{recurringSilences: []*ngmodels.RecurringSilence{stored}})
+		resp := sut.RouteGetRecurringSilence(newRequest(), "maintenance")
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.GettableRecurringSilence
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Equal(t, apimodels.GettableRecurringSilence{
+			UID:        "maintenance",
+			Matchers:   matchers,
+			Schedule:   "0 2 * * SUN",
+			Duration:   model.Duration(2 * time.Hour),
+			Timezone:   "Europe/Berlin",
+			Comment:    "Weekly maintenance",
+			CreatedBy:  "admin",
+			SilenceIDs: []string{"silence-1"},
+			Provenance: apimodels.Provenance(ngmodels.ProvenanceFile),
+			UpdatedAt:  updated,
+		}, result)
+	})
+
+	t.Run("returns 404 if the recurring silence does not exist", func(t *testing.T) {
+		sut := newSut(&fakeRecurringSilenceService{})
+		resp := sut.RouteGetRecurringSilence(newRequest(), "maintenance")
+		require.Equal(t, http.StatusNotFound, resp.Status())
+	})
+
+	t.Run("lists the recurring silences", func(t *testing.T) {
+		sut := newSut(&fakeRecurringSilenceService{recurringSilences: []*ngmodels.RecurringSilence{stored}})
+		resp := sut.RouteGetRecurringSilences(newRequest())
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.GettableRecurringSilences
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Len(t, result, 1)
+		require.Equal(t, "maintenance", result[0].UID)
+	})
+
+	t.Run("saves the recurring silence", func(t *testing.T) {
+		svc := &fakeRecurringSilenceService{}
+		resp := newSut(svc).RouteCreateRecurringSilence(newRequest(), apimodels.PostableRecurringSilence{
+			Matchers: matchers,
+			Schedule: "0 2 * * SUN",
+			Duration: model.Duration(2 * time.Hour),
+			Timezone: "Europe/Berlin",
+		})
+		require.Equal(t, http.StatusAccepted, resp.Status())
+		require.JSONEq(t, `{"uid":"new-uid"}`, string(resp.Body()))
+
+		require.Len(t, svc.saved, 1)
+		require.Equal(t, 2*time.Hour, svc.saved[0].Duration)
+		require.Equal(t, "Europe/Berlin", svc.saved[0].Timezone)
+	})
+
+	t.Run("returns the error of the service", func(t *testing.T) {
+		svc := &fakeRecurringSilenceService{err: notifier.WithPublicError(notifier.ErrRecurringSilenceBadRequest.Errorf("invalid schedule"))}
+		resp := newSut(svc).RouteCreateRecurringSilence(newRequest(), apimodels.PostableRecurringSilence{})
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+
+		svc.err = notifier.WithPublicError(notifier.ErrRecurringSilenceProvisioned.Errorf("provisioned"))
+		resp = newSut(svc).RouteDeleteRecurringSilence(newRequest(), "maintenance")
+		require.Equal(t, http.StatusConflict, resp.Status())
+	})
+}
+
+type fakeRecurringSilenceService struct {
+	recurringSilences []*ngmodels.RecurringSilence
+	saved             []ngmodels.RecurringSilence
+	err               error
+}
+
+func (f *fakeRecurringSilenceService) GetRecurringSilence(_ context.Context, _ identity.Requester, uid string) (*ngmodels.RecurringSilence, error) {
+	for _, rs := range f.recurringSilences {
+		if rs.UID == uid {
+			return rs, nil
+		}
+	}
+	return nil, notifier.WithPublicError(notifier.ErrRecurringSilenceNotFound.Errorf("recurring silence %s not found", uid))
+}
+
+func (f *fakeRecurringSilenceService) ListRecurringSilences(_ context.Context, _ identity.Requester) ([]*ngmodels.RecurringSilence, error) {
+	return f.recurringSilences, f.err
+}
+
+func (f *fakeRecurringSilenceService) SaveRecurringSilence(_ context.Context, _ identity.Requester, rs ngmodels.RecurringSilence) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	f.saved = append(f.saved, rs)
+	return "new-uid", nil
+}
+
+func (f *fakeRecurringSilenceService) DeleteRecurringSilence(_ context.Context, _ identity.Requester, _ string) error {
+	return f.err
+}
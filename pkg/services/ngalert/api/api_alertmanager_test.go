@@ -515,6 +515,146 @@ func TestRoutePostGrafanaAlertingConfigHistoryActivate(t *testing.T) {
 	})
 }
 
+func TestRoutePostGrafanaAlertingConfigHistoryRollback(t *testing.T) {
+	// saveTemplateChange saves a version of the configuration of org 1 that changes template "a", the configuration
+	// with id 0 is the initial one.
+	saveTemplateChange := func(t *testing.T, sut AlertmanagerSrv) {
+		t.Helper()
+		request := createAmConfigRequest(t, validConfig)
+		request.TemplateFiles = map[string]string{"a": "changed template"}
+		response := sut.RoutePostAlertingConfig(createRequestCtxInOrg(1), request)
+		require.Equal(t, http.StatusAccepted, response.Status())
+	}
+
+	t.Run("assert 404 when no historical configurations are found", func(t *testing.T) {
+		sut := createSut(t)
+
+		response := sut.RoutePostGrafanaAlertingConfigHistoryRollback(createRequestCtxInOrg(1), "42")
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+
+	t.Run("assert 400 when id is not parseable", func(t *testing.T) {
+		sut := createSut(t)
+
+		response := sut.RoutePostGrafanaAlertingConfigHistoryRollback(createRequestCtxInOrg(1), "abc")
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 202 and the historical configuration is restored", func(t *testing.T) {
+		sut := createSut(t)
+		saveTemplateChange(t, sut)
+		rc := createRequestCtxInOrg(1)
+
+		response := sut.RoutePostGrafanaAlertingConfigHistoryRollback(rc, "0")
+		require.Equal(t, http.StatusAccepted, response.Status())
+
+		body := asGettableUserConfig(t, sut.RouteGetAlertingConfig(rc))
+		require.Equal(t, map[string]string{"a": "template"}, body.TemplateFiles)
+	})
+
+	t.Run("assert 400 when the rollback changes a provisioned object", func(t *testing.T) {
+		sut := createSut(t)
+		saveTemplateChange(t, sut)
+		setTemplateProvenance(t, 1, "a", sut.mam.ProvStore)
+
+		response := sut.RoutePostGrafanaAlertingConfigHistoryRollback(createRequestCtxInOrg(1), "0")
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+}
+
+func TestRouteGetAlertingConfigVersionsAndDiff(t *testing.T) {
+	sut := createSut(t)
+	request := createAmConfigRequest(t, validConfig)
+	request.TemplateFiles = map[string]string{"a": "changed template", "b": "new template"}
+	response := sut.RoutePostAlertingConfig(createRequestCtxInOrg(1), request)
+	require.Equal(t, http.StatusAccepted, response.Status())
+
+	withQuery := func(t *testing.T, query string) *contextmodel.ReqContext {
+		t.Helper()
+		rc := createRequestCtxInOrg(1)
+		req, err := http.NewRequest(http.MethodGet, "https://grafana.net?"+query, nil)
+		require.NoError(t, err)
+		rc.Req = req
+		return rc
+	}
+
+	t.Run("assert 200 and all versions newest first", func(t *testing.T) {
+		response := sut.RouteGetAlertingConfigVersions(withQuery(t, "limit=10"))
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var versions []apimodels.GettableAlertmanagerConfigVersion
+		require.NoError(t, json.Unmarshal(response.Body(), &versions))
+		require.Len(t, versions, 2)
+		require.Equal(t, int64(1), versions[0].ID)
+		require.Equal(t, int64(0), versions[1].ID)
+	})
+
+	t.Run("assert 200 and the diff with the latest version", func(t *testing.T) {
+		response := sut.RouteGetAlertingConfigDiff(withQuery(t, "from=0"))
+		require.Equal(t, http.StatusOK, response.Status())
+
+		var diff apimodels.AlertmanagerConfigDiff
+		require.NoError(t, json.Unmarshal(response.Body(), &diff))
+		require.Equal(t, int64(1), diff.To)
+		require.Equal(t, []string{"b"}, diff.Templates.Added)
+		require.Equal(t, []string{"a"}, diff.Templates.Modified)
+		require.Empty(t, diff.Routes.Modified)
+		require.Empty(t, diff.MuteTimings.Added)
+	})
+
+	t.Run("assert 400 when from is missing or not parseable", func(t *testing.T) {
+		response := sut.RouteGetAlertingConfigDiff(withQuery(t, ""))
+		require.Equal(t, http.StatusBadRequest, response.Status())
+
+		response = sut.RouteGetAlertingConfigDiff(withQuery(t, "from=0&to=abc"))
+		require.Equal(t, http.StatusBadRequest, response.Status())
+	})
+
+	t.Run("assert 404 when a version is not found", func(t *testing.T) {
+		response := sut.RouteGetAlertingConfigDiff(withQuery(t, "from=0&to=42"))
+		require.Equal(t, http.StatusNotFound, response.Status())
+	})
+}
+
+func TestRoutePostTestReceiversCapture(t *testing.T) {
+	sut := createSut(t)
+
+	var body apimodels.TestReceiversConfigBodyParams
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"capture": true,
+		"alert": {"labels": {"alertname": "TestAlert"}},
+		"receivers": [{
+			"name": "team-a",
+			"grafana_managed_receiver_configs": [
+				{"name": "webhook", "type": "webhook", "settings": {"url": "http://localhost/hook", "title": "{{ .CommonLabels.alertname }}"}},
+				{"name": "slack", "type": "slack", "settings": {"recipient": "#alerts"}, "secureSettings": {"url": "http://localhost/slack"}}
+			]
+		}]
+	}`), &body))
+
+	response := sut.RoutePostTestReceivers(createRequestCtxInOrg(1), body)
+
+	var result apimodels.TestReceiversResult
+	require.NoError(t, json.Unmarshal(response.Body(), &result))
+	require.Len(t, result.Receivers, 1)
+	require.Len(t, result.Receivers[0].Configs, 2)
+	for _, config := range result.Receivers[0].Configs {
+		require.Empty(t, config.UID, "the temporary UID is not returned")
+		switch config.Name {
+		case "webhook":
+			require.Equal(t, "ok", config.Status)
+			require.Len(t, config.Captured, 1)
+			require.Equal(t, "webhook", config.Captured[0].Kind)
+			require.Equal(t, http.MethodPost, config.Captured[0].Method)
+			require.Contains(t, config.Captured[0].Payload, `"title":"TestAlert"`)
+		case "slack":
+			require.Equal(t, "failed", config.Status)
+			require.Contains(t, config.Error, "cannot be captured")
+			require.Empty(t, config.Captured)
+		}
+	}
+}
+
 func TestRoutePostTestTemplates(t *testing.T) {
 	sut := createSut(t)
 
@@ -2,19 +2,32 @@ package api
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
+const (
+	// defaultNotificationHistoryLimit is the number of delivery attempts that are returned if no limit is requested.
+	defaultNotificationHistoryLimit = 100
+	// maxNotificationHistoryLimit is the maximum number of delivery attempts that are returned at once.
+	maxNotificationHistoryLimit = 1000
+)
+
 type NotificationSrv struct {
-	logger            log.Logger
-	receiverService   ReceiverService
-	muteTimingService MuteTimingService // defined in api_provisioning.go
+	logger              log.Logger
+	receiverService     ReceiverService
+	muteTimingService   MuteTimingService // defined in api_provisioning.go
+	notificationHistory NotificationHistoryStore
 }
 
 type ReceiverService interface {
@@ -22,6 +35,10 @@ type ReceiverService interface {
 	ListReceivers(ctx context.Context, q models.ListReceiversQuery, user identity.Requester) ([]*models.Receiver, error)
 }
 
+type NotificationHistoryStore interface {
+	FindNotificationHistory(ctx context.Context, query models.NotificationHistoryQuery) ([]models.NotificationHistoryEntry, error)
+}
+
 func (srv *NotificationSrv) RouteGetTimeInterval(c *contextmodel.ReqContext, name string) response.Response {
 	muteTimeInterval, err := srv.muteTimingService.GetMuteTiming(c.Req.Context(), name, c.OrgID)
 	if err != nil {
@@ -78,3 +95,68 @@ func (srv *NotificationSrv) RouteGetReceivers(c *contextmodel.ReqContext) respon
 
 	return response.JSON(http.StatusOK, gettables)
 }
+
+func (srv *NotificationSrv) RouteGetNotificationHistory(c *contextmodel.ReqContext) response.Response {
+	q := models.NotificationHistoryQuery{
+		OrgID:            c.SignedInUser.GetOrgID(),
+		Receiver:         c.Query("receiver"),
+		Integration:      c.Query("integration"),
+		Status:           models.NotificationHistoryStatus(c.Query("status")),
+		AlertFingerprint: c.Query("fingerprint"),
+		Limit:            c.QueryInt("limit"),
+		Offset:           c.QueryInt("offset"),
+	}
+	switch q.Status {
+	case "", models.NotificationHistoryStatusSuccess, models.NotificationHistoryStatusFailure:
+	default:
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown status %q", q.Status), "")
+	}
+	if q.Limit < 0 || q.Offset < 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("limit and offset must not be negative"), "")
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultNotificationHistoryLimit
+	}
+	q.Limit = min(q.Limit, maxNotificationHistoryLimit)
+	if from := c.QueryInt64("from"); from > 0 {
+		q.From = time.Unix(from, 0)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		q.To = time.Unix(to, 0)
+	}
+
+	entries, err := srv.notificationHistory.FindNotificationHistory(c.Req.Context(), q)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "failed to get notification history", err)
+	}
+
+	result := make([]definitions.NotificationHistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		entry, err := notificationHistoryEntryToApi(e)
+		if err != nil {
+			return response.ErrOrFallback(http.StatusInternalServerError, "failed to convert notification history", err)
+		}
+		result = append(result, entry)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func notificationHistoryEntryToApi(e models.NotificationHistoryEntry) (definitions.NotificationHistoryEntry, error) {
+	var fingerprints []string
+	if err := json.Unmarshal([]byte(e.AlertFingerprints), &fingerprints); err != nil {
+		return definitions.NotificationHistoryEntry{}, fmt.Errorf("failed to decode the fingerprints of the notified alerts: %w", err)
+	}
+	return definitions.NotificationHistoryEntry{
+		Receiver:          e.Receiver,
+		Integration:       e.Integration,
+		IntegrationIndex:  e.IntegrationIndex,
+		GroupKey:          e.GroupKey,
+		AlertFingerprints: fingerprints,
+		Status:            string(e.Status),
+		StatusCode:        e.StatusCode,
+		Error:             e.ErrorMessage,
+		Retry:             e.Retry,
+		DurationMs:        e.Duration,
+		SentAt:            time.UnixMilli(e.SentAt).UTC(),
+	}, nil
+}
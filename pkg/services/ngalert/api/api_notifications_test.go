@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -153,6 +154,16 @@ func TestRouteGetReceivers(t *testing.T) {
 		require.Equal(t, expectedQ, call.Args[1])
 	})
 
+	t.Run("rejects negative limit and offset", func(t *testing.T) {
+		handler := NewNotificationsApi(newNotificationSrv(fakeReceiverSvc))
+		for _, param := range []string{"limit", "offset"} {
+			rc := testReqCtx("GET")
+			rc.Context.Req.Form.Set(param, "-1")
+			resp := handler.handleRouteGetReceivers(&rc)
+			require.Equal(t, http.StatusBadRequest, resp.Status(), param)
+		}
+	})
+
 	t.Run("should pass along permission denied response", func(t *testing.T) {
 		fakeReceiverSvc.ListReceiversFn = func(ctx context.Context, q models.ListReceiversQuery, u identity.Requester) ([]*models.Receiver, error) {
 			return nil, ac.ErrAuthorizationBase.Errorf("")
@@ -164,6 +175,120 @@ func TestRouteGetReceivers(t *testing.T) {
 	})
 }
 
+func TestRouteGetNotificationHistory(t *testing.T) {
+	t.Run("builds query from request context and returns the entries", func(t *testing.T) {
+		store := &fakeNotificationHistoryStore{
+			entries: []models.NotificationHistoryEntry{{
+				ID:                1,
+				OrgID:             1,
+				Receiver:          "team-a",
+				Integration:       "slack",
+				GroupKey:          `{}:{alertname="test"}`,
+				AlertFingerprints: `["0123456789abcdef"]`,
+				AlertCount:        1,
+				Status:            models.NotificationHistoryStatusFailure,
+				StatusCode:        500,
+				ErrorMessage:      "webhook response status 500 Internal Server Error",
+				Retry:             true,
+				Duration:          120,
+				SentAt:            1700000000000,
+			}},
+		}
+		srv := newNotificationSrv(fakes.NewFakeReceiverService())
+		srv.notificationHistory = store
+		handler := NewNotificationsApi(srv)
+		rc := testReqCtx("GET")
+		rc.Context.Req.Form.Set("receiver", "team-a")
+		rc.Context.Req.Form.Set("integration", "slack")
+		rc.Context.Req.Form.Set("status", "failure")
+		rc.Context.Req.Form.Set("fingerprint", "0123456789abcdef")
+		rc.Context.Req.Form.Set("from", "1600000000")
+		rc.Context.Req.Form.Set("limit", "10")
+		resp := handler.handleRouteGetNotificationHistory(&rc)
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		require.Equal(t, models.NotificationHistoryQuery{
+			OrgID:            1,
+			Receiver:         "team-a",
+			Integration:      "slack",
+			Status:           models.NotificationHistoryStatusFailure,
+			AlertFingerprint: "0123456789abcdef",
+			From:             time.Unix(1600000000, 0),
+			Limit:            10,
+		}, store.query)
+
+		var result []definitions.NotificationHistoryEntry
+		require.NoError(t, json.Unmarshal(resp.Body(), &result))
+		require.Equal(t, []definitions.NotificationHistoryEntry{{
+			Receiver:          "team-a",
+			Integration:       "slack",
+			GroupKey:          `{}:{alertname="test"}`,
+			AlertFingerprints: []string{"0123456789abcdef"},
+			Status:            "failure",
+			StatusCode:        500,
+			Error:             "webhook response status 500 Internal Server Error",
+			Retry:             true,
+			DurationMs:        120,
+			SentAt:            time.UnixMilli(1700000000000).UTC(),
+		}}, result)
+	})
+
+	t.Run("rejects unknown status", func(t *testing.T) {
+		srv := newNotificationSrv(fakes.NewFakeReceiverService())
+		srv.notificationHistory = &fakeNotificationHistoryStore{}
+		handler := NewNotificationsApi(srv)
+		rc := testReqCtx("GET")
+		rc.Context.Req.Form.Set("status", "delivered")
+		resp := handler.handleRouteGetNotificationHistory(&rc)
+		require.Equal(t, http.StatusBadRequest, resp.Status())
+	})
+
+	t.Run("limits the number of entries", func(t *testing.T) {
+		testCases := []struct {
+			limit    string
+			expected int
+		}{
+			{limit: "", expected: defaultNotificationHistoryLimit},
+			{limit: "0", expected: defaultNotificationHistoryLimit},
+			{limit: "5", expected: 5},
+			{limit: "100000", expected: maxNotificationHistoryLimit},
+		}
+		for _, tc := range testCases {
+			store := &fakeNotificationHistoryStore{}
+			srv := newNotificationSrv(fakes.NewFakeReceiverService())
+			srv.notificationHistory = store
+			handler := NewNotificationsApi(srv)
+			rc := testReqCtx("GET")
+			rc.Context.Req.Form.Set("limit", tc.limit)
+			resp := handler.handleRouteGetNotificationHistory(&rc)
+			require.Equal(t, http.StatusOK, resp.Status())
+			require.Equal(t, tc.expected, store.query.Limit, "limit %q", tc.limit)
+		}
+	})
+
+	t.Run("rejects negative limit and offset", func(t *testing.T) {
+		for _, param := range []string{"limit", "offset"} {
+			srv := newNotificationSrv(fakes.NewFakeReceiverService())
+			srv.notificationHistory = &fakeNotificationHistoryStore{}
+			handler := NewNotificationsApi(srv)
+			rc := testReqCtx("GET")
+			rc.Context.Req.Form.Set(param, "-1")
+			resp := handler.handleRouteGetNotificationHistory(&rc)
+			require.Equal(t, http.StatusBadRequest, resp.Status(), param)
+		}
+	})
+}
+
+type fakeNotificationHistoryStore struct {
+	entries []models.NotificationHistoryEntry
+	query   models.NotificationHistoryQuery
+}
+
+func (f *fakeNotificationHistoryStore) FindNotificationHistory(_ context.Context, query models.NotificationHistoryQuery) ([]models.NotificationHistoryEntry, error) {
+	f.query = query
+	return f.entries, nil
+}
+
 func TestRouteGetReceiversResponses(t *testing.T) {
 	createTestEnv := func(t *testing.T, testConfig string) testEnvironment {
 		env := createTestEnv(t, testConfig)
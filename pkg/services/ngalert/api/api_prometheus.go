@@ -177,6 +177,8 @@ func getStatesFromQuery(v url.Values) ([]eval.State, error) {
 		// nolint:goconst
 		case "error":
 			states = append(states, eval.Error)
+		case "suppressed":
+			states = append(states, eval.Suppressed)
 		default:
 			return states, fmt.Errorf("unknown state '%s'", s)
 		}
@@ -444,11 +446,12 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 	ngmodels.RulesGroup(rules).SortByGroupIndex()
 	for _, rule := range rules {
 		alertingRule := apimodels.AlertingRule{
-			State:       "inactive",
-			Name:        rule.Title,
-			Query:       ruleToQuery(log, rule),
-			Duration:    rule.For.Seconds(),
-			Annotations: apimodels.LabelsFromMap(rule.Annotations),
+			State:         "inactive",
+			Name:          rule.Title,
+			Query:         ruleToQuery(log, rule),
+			Duration:      rule.For.Seconds(),
+			KeepFiringFor: rule.KeepFiringFor.Seconds(),
+			Annotations:   apimodels.LabelsFromMap(rule.Annotations),
 		}
 
 		newRule := apimodels.Rule{
@@ -465,7 +468,7 @@ func toRuleGroup(log log.Logger, manager state.AlertInstanceManager, groupKey ng
 		for _, alertState := range states {
 			activeAt := alertState.StartsAt
 			valString := ""
-			if alertState.State == eval.Alerting || alertState.State == eval.Pending {
+			if alertState.State == eval.Alerting || alertState.State == eval.Pending || alertState.State == eval.Suppressed {
 				valString = formatValues(alertState)
 			}
 			stateKey := strings.ToLower(alertState.State.String())
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"slices"
 	"strings"
 	"time"
@@ -16,6 +17,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -57,6 +59,11 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+
+	// evaluator, appUrl and tracer are used to preview the changes of rule groups.
+	evaluator eval.EvaluatorFactory
+	appUrl    *url.URL
+	tracer    tracing.Tracer
 }
 
 var (
@@ -349,6 +356,10 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *contextmodel.ReqContext, ruleGro
 		RuleGroup:    ruleGroupConfig.Name,
 	}
 
+	if c.QueryBool("preview") {
+		return srv.previewAlertRulesInGroup(c, groupKey, rules)
+	}
+
 	return srv.updateAlertRulesInGroup(c, groupKey, rules)
 }
 
@@ -511,6 +522,10 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 }
 
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
+	return response.JSON(http.StatusAccepted, changesToUpdateRuleGroupResponse(finalChanges))
+}
+
+func changesToUpdateRuleGroupResponse(finalChanges *store.GroupDelta) apimodels.UpdateRuleGroupResponse {
 	body := apimodels.UpdateRuleGroupResponse{
 		Message: "rule group updated successfully",
 		Created: make([]string, 0, len(finalChanges.New)),
@@ -530,7 +545,7 @@ func changesToResponse(finalChanges *store.GroupDelta) response.Response {
 			body.Deleted = append(body.Deleted, r.UID)
 		}
 	}
-	return response.JSON(http.StatusAccepted, body)
+	return body
 }
 
 func toGettableRuleGroupConfig(groupName string, rules ngmodels.RulesGroup, provenanceRecords map[string]ngmodels.Provenance) apimodels.GettableRuleGroupConfig {
@@ -576,6 +591,8 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 			NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(r.NotificationSettings),
 			Record:               ApiRecordFromModelRecord(r.Record),
 			Metadata:             AlertRuleMetadataFromModelMetadata(r.Metadata),
+			InhibitedBy:          ApiInhibitionRulesFromModelInhibitionRules(r.InhibitedBy),
+			Priority:             r.Priority.String(),
 		},
 	}
 	forDuration := model.Duration(r.For)
@@ -584,6 +601,14 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, provenanceRecords map[stri
 		Annotations: r.Annotations,
 		Labels:      r.Labels,
 	}
+	if r.KeepFiringFor > 0 {
+		keepFiringFor := model.Duration(r.KeepFiringFor)
+		gettableExtendedRuleNode.ApiRuleNode.KeepFiringFor = &keepFiringFor
+	}
+	if r.EvaluationTimeout > 0 {
+		evaluationTimeout := model.Duration(r.EvaluationTimeout)
+		gettableExtendedRuleNode.GrafanaManagedAlert.EvaluationTimeout = &evaluationTimeout
+	}
 	return gettableExtendedRuleNode
 }
 
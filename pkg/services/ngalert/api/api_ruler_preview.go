@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/alerting/models"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	rulePreviewCreated = "created"
+	rulePreviewUpdated = "updated"
+	rulePreviewDeleted = "deleted"
+
+	instancePreviewFiring   = "firing"
+	instancePreviewResolved = "resolved"
+	instancePreviewLabels   = "labels"
+	instancePreviewState    = "state"
+)
+
+// previewAlertRulesInGroup calculates the changes of the rule group like updateAlertRulesInGroup does, but instead of
+// saving them, it evaluates the old and the new versions of every changed rule once at the current time and returns
+// the differences between the states of their alert instances. Nothing is persisted.
+func (srv RulerSrv) previewAlertRulesInGroup(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, rules []*ngmodels.AlertRuleWithOptionals) response.Response {
+	ctx := c.Req.Context()
+	groupChanges, err := store.CalculateChanges(ctx, srv.store, groupKey, rules)
+	if err == nil && !groupChanges.IsEmpty() {
+		err = srv.authz.AuthorizeRuleChanges(ctx, c.SignedInUser, groupChanges)
+		if err == nil {
+			err = validateQueries(ctx, groupChanges, srv.conditionValidator, c.SignedInUser)
+		}
+	}
+	if err != nil {
+		if errors.As(err, &errutil.Error{}) {
+			return response.Err(err)
+		} else if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "failed to preview rule group")
+		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "failed to preview rule group")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to preview rule group")
+	}
+
+	p := rulePreviewer{
+		evaluator:     srv.evaluator,
+		user:          c.SignedInUser,
+		folders:       make(map[string]string),
+		includeFolder: !srv.cfg.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
+		folderTitle: func(ctx context.Context, namespaceUID string) (string, error) {
+			f, err := srv.store.GetNamespaceByUID(ctx, namespaceUID, groupKey.OrgID, c.SignedInUser)
+			if err != nil {
+				return "", err
+			}
+			return f.Fullpath, nil
+		},
+		appUrl: srv.appUrl,
+		tracer: srv.tracer,
+		log:    srv.log,
+	}
+	body := changesToUpdateRuleGroupResponse(groupChanges)
+	body.Message = "rule group preview, no changes were saved"
+	if groupChanges.IsEmpty() {
+		body.Message = "no changes detected in the rule group"
+	}
+	body.Preview = p.previewChanges(ctx, groupChanges, time.Now())
+	return response.JSON(http.StatusOK, body)
+}
+
+// rulePreviewer evaluates the versions of alert rules with a state manager that does not store anything.
+type rulePreviewer struct {
+	evaluator     eval.EvaluatorFactory
+	user          identity.Requester
+	folderTitle   func(ctx context.Context, namespaceUID string) (string, error)
+	folders       map[string]string
+	includeFolder bool
+	appUrl        *url.URL
+	tracer        tracing.Tracer
+	log           log.Logger
+}
+
+// previewChanges returns the previews of the created, updated and deleted rules of the changes. Rules that are updated
+// only because their index in the group changed are left out.
+func (p rulePreviewer) previewChanges(ctx context.Context, changes *store.GroupDelta, now time.Time) []apimodels.RuleChangePreview {
+	result := make([]apimodels.RuleChangePreview, 0, len(changes.New)+len(changes.Update)+len(changes.Delete))
+	for _, rule := range changes.New {
+		result = append(result, p.previewRule(ctx, rulePreviewCreated, nil, rule, now))
+	}
+	for _, upd := range changes.Update {
+		if !shouldValidate(upd) {
+			continue
+		}
+		result = append(result, p.previewRule(ctx, rulePreviewUpdated, upd.Existing, upd.New, now))
+	}
+	for _, rule := range changes.Delete {
+		result = append(result, p.previewRule(ctx, rulePreviewDeleted, rule, nil, now))
+	}
+	return result
+}
+
+func (p rulePreviewer) previewRule(ctx context.Context, change string, existing, updated *ngmodels.AlertRule, now time.Time) apimodels.RuleChangePreview {
+	rule := updated
+	if rule == nil {
+		rule = existing
+	}
+	result := apimodels.RuleChangePreview{
+		UID:    rule.UID,
+		Title:  rule.Title,
+		Change: change,
+	}
+
+	before, err := p.evaluate(ctx, existing, now)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to evaluate the existing rule: %s", err)
+		return result
+	}
+	after, err := p.evaluate(ctx, updated, now)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to evaluate the new rule: %s", err)
+		return result
+	}
+	result.Instances = diffAlertInstances(before, after)
+	return result
+}
+
+// evaluate returns the states of the alert instances of the rule by the fingerprint of the labels of their results.
+// It returns no states for a missing rule and for recording rules, as they do not have alert instances.
+func (p rulePreviewer) evaluate(ctx context.Context, rule *ngmodels.AlertRule, now time.Time) (map[data.Fingerprint]*state.State, error) {
+	if rule == nil || rule.Type() == ngmodels.RuleTypeRecording {
+		return nil, nil
+	}
+
+	folderTitle, ok := p.folders[rule.NamespaceUID]
+	if !ok {
+		var err error
+		folderTitle, err = p.folderTitle(ctx, rule.NamespaceUID)
+		if err != nil {
+			return nil, err
+		}
+		p.folders[rule.NamespaceUID] = folderTitle
+	}
+
+	evaluator, err := p.evaluator.Create(eval.NewContext(ctx, p.user), rule.GetEvalCondition().WithSource("preview"))
+	if err != nil {
+		return nil, err
+	}
+	results, err := evaluator.Evaluate(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	manager := state.NewManager(state.ManagerCfg{
+		ExternalURL: p.appUrl,
+		Images:      &backtesting.NoopImageService{},
+		Clock:       clock.New(),
+		Tracer:      p.tracer,
+		Log:         p.log,
+	}, state.NewNoopPersister())
+	transitions := manager.ProcessEvalResults(ctx, now, rule, results, state.GetRuleExtraLabels(p.log, rule, folderTitle, p.includeFolder), nil)
+
+	states := make(map[data.Fingerprint]*state.State, len(transitions))
+	for _, tr := range transitions {
+		states[tr.ResultFingerprint] = tr.State
+	}
+	return states, nil
+}
+
+// diffAlertInstances returns the differences between the alert instances of two versions of a rule. Instances are
+// matched by the labels of their results, so that changes of the labels of the rule show up as label changes.
+// Pending instances count as firing, because they fire if their condition holds for the pending period.
+func diffAlertInstances(before, after map[data.Fingerprint]*state.State) []apimodels.AlertInstancePreview {
+	result := make([]apimodels.AlertInstancePreview, 0)
+	for fp, a := range after {
+		b := before[fp]
+		change := instanceChange(b, a)
+		if change == "" {
+			continue
+		}
+		preview := apimodels.AlertInstancePreview{
+			Change:    change,
+			NewState:  a.State.String(),
+			NewLabels: a.Labels,
+		}
+		if b != nil {
+			preview.OldState = b.State.String()
+			preview.OldLabels = b.Labels
+		}
+		result = append(result, preview)
+	}
+	for fp, b := range before {
+		if _, ok := after[fp]; ok || !isFiringPreview(b) {
+			continue
+		}
+		result = append(result, apimodels.AlertInstancePreview{
+			Change:    instancePreviewResolved,
+			OldState:  b.State.String(),
+			OldLabels: b.Labels,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return instancePreviewKey(result[i]) < instancePreviewKey(result[j])
+	})
+	return result
+}
+
+// instanceChange returns how the alert instance changes between the versions, or an empty string if it does not.
+func instanceChange(before, after *state.State) string {
+	if before == nil {
+		if isFiringPreview(after) {
+			return instancePreviewFiring
+		}
+		return ""
+	}
+	switch {
+	case isFiringPreview(after) && !isFiringPreview(before):
+		return instancePreviewFiring
+	case !isFiringPreview(after) && isFiringPreview(before):
+		return instancePreviewResolved
+	case before.Labels.Fingerprint() != after.Labels.Fingerprint():
+		return instancePreviewLabels
+	case before.State != after.State:
+		return instancePreviewState
+	}
+	return ""
+}
+
+func isFiringPreview(s *state.State) bool {
+	return s.State == eval.Alerting || s.State == eval.Pending
+}
+
+func instancePreviewKey(p apimodels.AlertInstancePreview) string {
+	if p.NewLabels != nil {
+		return data.Labels(p.NewLabels).String()
+	}
+	return data.Labels(p.OldLabels).String()
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval/eval_mocks"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestDiffAlertInstances(t *testing.T) {
+	instance := func(s eval.State, lbls data.Labels) *state.State {
+		return &state.State{State: s, Labels: lbls}
+	}
+	a := data.Labels{"instance": "a"}
+	b := data.Labels{"instance": "b"}
+
+	testCases := []struct {
+		name     string
+		before   map[data.Fingerprint]*state.State
+		after    map[data.Fingerprint]*state.State
+		expected []definitions.AlertInstancePreview
+	}{
+		{
+			name:     "new firing instance",
+			before:   map[data.Fingerprint]*state.State{1: instance(eval.Normal, a)},
+			after:    map[data.Fingerprint]*state.State{1: instance(eval.Alerting, a), 2: instance(eval.Pending, b)},
+			expected: []definitions.AlertInstancePreview{{Change: "firing", OldState: "Normal", NewState: "Alerting", OldLabels: a, NewLabels: a}, {Change: "firing", NewState: "Pending", NewLabels: b}},
+		},
+		{
+			name:     "resolved instances",
+			before:   map[data.Fingerprint]*state.State{1: instance(eval.Alerting, a), 2: instance(eval.Alerting, b)},
+			after:    map[data.Fingerprint]*state.State{1: instance(eval.Normal, a)},
+			expected: []definitions.AlertInstancePreview{{Change: "resolved", OldState: "Alerting", NewState: "Normal", OldLabels: a, NewLabels: a}, {Change: "resolved", OldState: "Alerting", OldLabels: b}},
+		},
+		{
+			name:     "label and state changes",
+			before:   map[data.Fingerprint]*state.State{1: instance(eval.Alerting, a), 2: instance(eval.Normal, b)},
+			after:    map[data.Fingerprint]*state.State{1: instance(eval.Alerting, data.Labels{"instance": "a", "team": "x"}), 2: instance(eval.NoData, b)},
+			expected: []definitions.AlertInstancePreview{{Change: "labels", OldState: "Alerting", NewState: "Alerting", OldLabels: a, NewLabels: data.Labels{"instance": "a", "team": "x"}}, {Change: "state", OldState: "Normal", NewState: "NoData", OldLabels: b, NewLabels: b}},
+		},
+		{
+			name:     "no changes",
+			before:   map[data.Fingerprint]*state.State{1: instance(eval.Alerting, a), 2: instance(eval.Normal, b)},
+			after:    map[data.Fingerprint]*state.State{1: instance(eval.Alerting, a)},
+			expected: []definitions.AlertInstancePreview{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, diffAlertInstances(tc.before, tc.after))
+		})
+	}
+}
+
+func TestRulePreviewer(t *testing.T) {
+	gen := models.RuleGen.With(models.RuleGen.WithFor(0), models.RuleGen.WithLabels(data.Labels{"team": "a"}))
+	existing := gen.With(gen.WithCondition("old")).GenerateRef()
+	updated := models.CopyRule(existing)
+	updated.Condition = "new"
+	updated.Labels = data.Labels{"team": "b"}
+	deleted := gen.With(gen.WithCondition("deleted")).GenerateRef()
+	created := gen.With(gen.WithCondition("failing")).GenerateRef()
+	created.UID = ""
+
+	now := time.Now()
+	result := func(s eval.State, instance string) eval.Result {
+		return eval.Result{State: s, Instance: data.Labels{"instance": instance}, EvaluatedAt: now}
+	}
+	p := rulePreviewer{
+		evaluator: fakePreviewEvaluatorFactory{
+			"old":     {result(eval.Alerting, "a"), result(eval.Normal, "b")},
+			"new":     {result(eval.Alerting, "a"), result(eval.Alerting, "b")},
+			"deleted": {result(eval.Alerting, "c")},
+		},
+		folders: make(map[string]string),
+		folderTitle: func(ctx context.Context, namespaceUID string) (string, error) {
+			return "folder", nil
+		},
+		tracer: tracing.InitializeTracerForTest(),
+		log:    log.NewNopLogger(),
+	}
+
+	previews := p.previewChanges(context.Background(), &store.GroupDelta{
+		New:    []*models.AlertRule{created},
+		Update: []store.RuleDelta{{Existing: existing, New: updated, Diff: existing.Diff(updated)}},
+		Delete: []*models.AlertRule{deleted},
+	}, now)
+
+	require.Len(t, previews, 3)
+
+	require.Equal(t, "created", previews[0].Change)
+	require.Equal(t, created.Title, previews[0].Title)
+	require.Contains(t, previews[0].Error, "failed to evaluate the new rule")
+
+	require.Equal(t, "updated", previews[1].Change)
+	require.Equal(t, existing.UID, previews[1].UID)
+	require.Empty(t, previews[1].Error)
+	require.Len(t, previews[1].Instances, 2)
+	require.Equal(t, "labels", previews[1].Instances[0].Change)
+	require.Equal(t, "a", previews[1].Instances[0].OldLabels["team"])
+	require.Equal(t, "b", previews[1].Instances[0].NewLabels["team"])
+	require.Equal(t, "firing", previews[1].Instances[1].Change)
+	require.Equal(t, "b", previews[1].Instances[1].NewLabels["instance"])
+
+	require.Equal(t, "deleted", previews[2].Change)
+	require.Len(t, previews[2].Instances, 1)
+	require.Equal(t, "resolved", previews[2].Instances[0].Change)
+	require.Equal(t, "c", previews[2].Instances[0].OldLabels["instance"])
+}
+
+// fakePreviewEvaluatorFactory returns evaluators that return the results of the condition of the rule.
+type fakePreviewEvaluatorFactory map[string]eval.Results
+
+func (f fakePreviewEvaluatorFactory) Create(_ eval.EvaluationContext, condition models.Condition) (eval.ConditionEvaluator, error) {
+	results, ok := f[condition.Condition]
+	if !ok {
+		return nil, errors.New("failed to build the evaluator")
+	}
+	evaluator := &eval_mocks.ConditionEvaluatorMock{}
+	evaluator.EXPECT().Evaluate(mock.Anything, mock.Anything).Return(results, nil)
+	return evaluator, nil
+}
@@ -11,6 +11,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	prommodels "github.com/prometheus/common/model"
 )
@@ -83,6 +84,16 @@ func validateRuleNode(
 		return nil, err
 	}
 
+	newAlertRule.EvaluationTimeout, err = validateEvaluationTimeout(ruleNode)
+	if err != nil {
+		return nil, err
+	}
+
+	newAlertRule.Priority, err = ngmodels.RulePriorityFromString(ruleNode.GrafanaManagedAlert.Priority)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+	}
+
 	if ruleNode.ApiRuleNode != nil {
 		newAlertRule.Annotations = ruleNode.ApiRuleNode.Annotations
 		err = validateLabels(ruleNode.Labels)
@@ -144,6 +155,13 @@ func validateAlertingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		}
 	}
 
+	newRule.InhibitedBy = ModelInhibitionRulesFromApiInhibitionRules(in.GrafanaManagedAlert.InhibitedBy)
+	for _, inhibition := range newRule.InhibitedBy {
+		if err := inhibition.Validate(); err != nil {
+			return ngmodels.AlertRule{}, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
+		}
+	}
+
 	if in.GrafanaManagedAlert.Metadata != nil {
 		newRule.Metadata.EditorSettings = ngmodels.EditorSettings{
 			SimplifiedQueryAndExpressionsSection: in.GrafanaManagedAlert.Metadata.EditorSettings.SimplifiedQueryAndExpressionsSection,
@@ -155,6 +173,11 @@ func validateAlertingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		return ngmodels.AlertRule{}, err
 	}
 
+	newRule.KeepFiringFor, err = validateKeepFiringFor(in)
+	if err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+
 	return newRule, nil
 }
 
@@ -165,29 +188,43 @@ func validateRecordingRuleFields(in *apimodels.PostableExtendedRuleNode, newRule
 		return ngmodels.AlertRule{}, fmt.Errorf("%w: recording rules cannot be created on this instance", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
-	err := validateCondition(in.GrafanaManagedAlert.Record.From, in.GrafanaManagedAlert.Data, canPatch)
+	err := validateRecord(in.GrafanaManagedAlert.Record, in.GrafanaManagedAlert.Data, canPatch)
 	if err != nil {
 		return ngmodels.AlertRule{}, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, err.Error())
 	}
-
-	metricName := prommodels.LabelValue(in.GrafanaManagedAlert.Record.Metric)
-	if !metricName.IsValid() {
-		return ngmodels.AlertRule{}, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, "metric name for recording rule must be a valid utf8 string")
-	}
-	if !prommodels.IsValidMetricName(metricName) {
-		return ngmodels.AlertRule{}, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleFailedValidation, "metric name for recording rule must be a valid Prometheus metric name")
-	}
 	newRule.Record = ModelRecordFromApiRecord(in.GrafanaManagedAlert.Record)
 
 	newRule.NoDataState = ""
 	newRule.ExecErrState = ""
 	newRule.Condition = ""
 	newRule.For = 0
+	newRule.KeepFiringFor = 0
 	newRule.NotificationSettings = nil
+	newRule.InhibitedBy = nil
 
 	return newRule, nil
 }
 
+// validateRecord validates the output node and the metric name of a recording rule.
+func validateRecord(record *apimodels.Record, queries []apimodels.AlertQuery, canPatch bool) error {
+	err := validateCondition(record.From, queries, canPatch)
+	if err != nil {
+		return err
+	}
+
+	metricName := prommodels.LabelValue(record.Metric)
+	if !metricName.IsValid() {
+		return errors.New("metric name for recording rule must be a valid utf8 string")
+	}
+	if !prommodels.IsValidMetricName(metricName) {
+		return errors.New("metric name for recording rule must be a valid Prometheus metric name")
+	}
+	if !writer.IsValidTarget(record.Target) {
+		return fmt.Errorf("unknown target %q of recording rule, must be one of: %s", record.Target, strings.Join(writer.Targets, ", "))
+	}
+	return nil
+}
+
 func validateLabels(l map[string]string) error {
 	for key := range l {
 		if _, ok := ngmodels.LabelsUserCannotSpecify[key]; ok {
@@ -270,6 +307,36 @@ func validateForInterval(ruleNode *apimodels.PostableExtendedRuleNode) (time.Dur
 	return duration, nil
 }
 
+// validateKeepFiringFor validates ApiRuleNode.KeepFiringFor and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateKeepFiringFor(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.ApiRuleNode == nil || ruleNode.ApiRuleNode.KeepFiringFor == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.ApiRuleNode.KeepFiringFor)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `keep_firing_for` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.ApiRuleNode.KeepFiringFor)
+	}
+	return duration, nil
+}
+
+// validateEvaluationTimeout validates GrafanaManagedAlert.EvaluationTimeout and converts it to time.Duration. If the field is not specified returns 0 if GrafanaManagedAlert.UID is empty and -1 if it is not.
+func validateEvaluationTimeout(ruleNode *apimodels.PostableExtendedRuleNode) (time.Duration, error) {
+	if ruleNode.GrafanaManagedAlert.EvaluationTimeout == nil {
+		if ruleNode.GrafanaManagedAlert.UID != "" {
+			return -1, nil // will be patched later with the real value of the current version of the rule
+		}
+		return 0, nil
+	}
+	duration := time.Duration(*ruleNode.GrafanaManagedAlert.EvaluationTimeout)
+	if duration < 0 {
+		return 0, fmt.Errorf("field `evaluation_timeout` cannot be negative [%v]. 0 or any positive duration are allowed", *ruleNode.GrafanaManagedAlert.EvaluationTimeout)
+	}
+	return duration, nil
+}
+
 // ValidateRuleGroup validates API model (definitions.PostableRuleGroupConfig) and converts it to a collection of models.AlertRule.
 // Returns a slice that contains all rules described by API model or error if either group specification or an alert definition is not valid.
 // It also returns a map containing current existing alerts that don't contain the is_paused field in the body of the call.
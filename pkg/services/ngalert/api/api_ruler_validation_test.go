@@ -623,6 +623,30 @@ func TestValidateRuleNodeFailures_NoUID(t *testing.T) {
 				return &r
 			},
 		},
+		{
+			name: "fail if keep_firing_for is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = util.Pointer(model.Duration(-time.Minute))
+				return &r
+			},
+		},
+		{
+			name: "fail if evaluation_timeout is negative",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.EvaluationTimeout = util.Pointer(model.Duration(-time.Minute))
+				return &r
+			},
+		},
+		{
+			name: "fail if priority is unknown",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.Priority = "urgent"
+				return &r
+			},
+		},
 		{
 			name: "fail if Data has duplicate ref ID",
 			rule: func() *apimodels.PostableExtendedRuleNode {
@@ -800,6 +824,52 @@ func TestValidateRuleNode_UID(t *testing.T) {
 				require.Equal(t, int64(panelId), *alert.PanelID)
 			},
 		},
+		{
+			name: "use -1 KeepFiringFor if it is not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = nil
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, time.Duration(-1), alert.KeepFiringFor)
+			},
+		},
+		{
+			name: "use KeepFiringFor if it is specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.ApiRuleNode.KeepFiringFor = util.Pointer(model.Duration(5 * time.Minute))
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 5*time.Minute, alert.KeepFiringFor)
+			},
+		},
+		{
+			name: "use -1 EvaluationTimeout if it is not specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.EvaluationTimeout = nil
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, time.Duration(-1), alert.EvaluationTimeout)
+			},
+		},
+		{
+			name: "use EvaluationTimeout and Priority if they are specified",
+			rule: func() *apimodels.PostableExtendedRuleNode {
+				r := validRule()
+				r.GrafanaManagedAlert.EvaluationTimeout = util.Pointer(model.Duration(10 * time.Second))
+				r.GrafanaManagedAlert.Priority = "critical"
+				return &r
+			},
+			assert: func(t *testing.T, api *apimodels.PostableExtendedRuleNode, alert *models.AlertRule) {
+				require.Equal(t, 10*time.Second, alert.EvaluationTimeout)
+				require.Equal(t, models.RulePriorityCritical, alert.Priority)
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -1046,6 +1116,51 @@ func TestValidateRuleNodeNotificationSettings(t *testing.T) {
 	}
 }
 
+func TestValidateRuleNodeInhibitedBy(t *testing.T) {
+	cfg := config(t)
+	limits := makeLimits(cfg)
+
+	testCases := []struct {
+		name             string
+		inhibitedBy      []apimodels.InhibitionRule
+		expErrorContains string
+	}{
+		{
+			name: "no inhibitions is valid",
+		},
+		{
+			name:        "inhibition with equal labels is valid",
+			inhibitedBy: []apimodels.InhibitionRule{{SourceRuleUID: "source", Equal: []string{"datacenter"}}},
+		},
+		{
+			name:             "missing source rule is invalid",
+			inhibitedBy:      []apimodels.InhibitionRule{{Equal: []string{"datacenter"}}},
+			expErrorContains: "source rule",
+		},
+		{
+			name:             "empty equal label is invalid",
+			inhibitedBy:      []apimodels.InhibitionRule{{SourceRuleUID: "source", Equal: []string{""}}},
+			expErrorContains: "equal labels",
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRule()
+			r.GrafanaManagedAlert.InhibitedBy = tt.inhibitedBy
+			alert, err := validateRuleNode(&r, util.GenerateShortUID(), cfg.BaseInterval*time.Duration(rand.Int63n(10)+1), rand.Int63(), randFolder().UID, limits)
+
+			if tt.expErrorContains != "" {
+				require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+				require.ErrorContains(t, err, tt.expErrorContains)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, ModelInhibitionRulesFromApiInhibitionRules(tt.inhibitedBy), alert.InhibitedBy)
+			}
+		})
+	}
+}
+
 func TestValidateRuleNodeReservedLabels(t *testing.T) {
 	cfg := config(t)
 	limits := makeLimits(cfg)
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -29,6 +30,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/backtesting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -51,6 +53,7 @@ type TestingApiSrv struct {
 	appUrl          *url.URL
 	tracer          tracing.Tracer
 	folderService   folderService
+	amConfigStore   AMConfigStore
 }
 
 // RouteTestGrafanaRuleConfig returns a list of potential alerts for a given rule configuration. This is intended to be
@@ -229,10 +232,13 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		return ErrResp(400, nil, "From cannot be greater than To")
 	}
 
-	noDataState, err := ngmodels.NoDataStateFromString(string(cmd.NoDataState))
-
-	if err != nil {
-		return ErrResp(400, err, "")
+	var noDataState ngmodels.NoDataState
+	if cmd.Record == nil {
+		var err error
+		noDataState, err = ngmodels.NoDataStateFromString(string(cmd.NoDataState))
+		if err != nil {
+			return ErrResp(400, err, "")
+		}
 	}
 	forInterval := time.Duration(cmd.For)
 	if forInterval < 0 {
@@ -272,6 +278,37 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 		Labels:          cmd.Labels,
 	}
 
+	if cmd.Record != nil {
+		if !srv.featureManager.IsEnabledGlobally(featuremgmt.FlagGrafanaManagedRecordingRules) {
+			return ErrResp(400, nil, "recording rules cannot be tested on this instance")
+		}
+		if err := validateRecord(cmd.Record, cmd.Data, false); err != nil {
+			return ErrResp(400, err, "")
+		}
+		rule.Record = ModelRecordFromApiRecord(cmd.Record)
+		rule.Condition = ""
+		rule.For = 0
+	}
+
+	if cmd.NotificationSettings != nil {
+		rule.NotificationSettings, err = validateNotificationSettings(cmd.NotificationSettings)
+		if err != nil {
+			return ErrResp(400, err, "")
+		}
+	}
+
+	if cmd.Notifications {
+		if cmd.NamespaceUID == "" {
+			return ErrResp(400, nil, "folderUid is required to simulate notifications")
+		}
+		folder, err := srv.folderService.GetNamespaceByUID(c.Req.Context(), cmd.NamespaceUID, c.SignedInUser.GetOrgID(), c.SignedInUser)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		rule.NamespaceUID = folder.UID
+		return srv.backtestNotifications(c, rule, cmd.From, cmd.To, folder.Fullpath)
+	}
+
 	result, err := srv.backtesting.Test(c.Req.Context(), c.SignedInUser, rule, cmd.From, cmd.To)
 	if err != nil {
 		if errors.Is(err, backtesting.ErrInvalidInputData) {
@@ -286,3 +323,84 @@ func (srv TestingApiSrv) BacktestAlertRule(c *contextmodel.ReqContext, cmd apimo
 	}
 	return response.JSON(http.StatusOK, body)
 }
+
+// backtestNotifications tests the rule and replays its alerts through the notification policy tree and the mute timings
+// of the current Alertmanager configuration of the organization.
+func (srv TestingApiSrv) backtestNotifications(c *contextmodel.ReqContext, rule *ngmodels.AlertRule, from, to time.Time, folderTitle string) response.Response {
+	policy, err := srv.backtestingNotificationPolicy(c.Req.Context(), rule)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Invalid notification settings")
+		}
+		return ErrResp(500, err, "Failed to load the notification policy")
+	}
+
+	includeFolder := !srv.cfg.ReservedLabels.IsReservedLabelDisabled(ngmodels.FolderTitleLabel)
+	frame, notifications, err := srv.backtesting.TestNotifications(c.Req.Context(), c.SignedInUser, rule, from, to, policy, folderTitle, includeFolder)
+	if err != nil {
+		if errors.Is(err, backtesting.ErrInvalidInputData) {
+			return ErrResp(400, err, "Failed to evaluate")
+		}
+		return ErrResp(500, err, "Failed to evaluate")
+	}
+
+	return response.JSON(http.StatusOK, apimodels.BacktestNotificationsResult{
+		States:        frame,
+		Notifications: backtestNotificationsFromNotifications(notifications),
+	})
+}
+
+func (srv TestingApiSrv) backtestingNotificationPolicy(ctx context.Context, rule *ngmodels.AlertRule) (backtesting.NotificationPolicy, error) {
+	dbConfig, err := srv.amConfigStore.GetLatestAlertmanagerConfiguration(ctx, rule.OrgID)
+	if err != nil {
+		return backtesting.NotificationPolicy{}, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+	cfg, err := notifier.Load([]byte(dbConfig.AlertmanagerConfiguration))
+	if err != nil {
+		return backtesting.NotificationPolicy{}, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	if len(rule.NotificationSettings) > 0 {
+		// the routes of the rule notification settings are generated in the same way as for the Alertmanager
+		err = notifier.AddAutogenConfig(ctx, srv.log, backtestingNotificationSettingsStore{rule: rule}, rule.OrgID, &cfg.AlertmanagerConfig, false)
+		if err != nil {
+			return backtesting.NotificationPolicy{}, errors.Join(backtesting.ErrInvalidInputData, err)
+		}
+	}
+	return backtesting.NotificationPolicyFromConfig(&cfg.AlertmanagerConfig), nil
+}
+
+func backtestNotificationsFromNotifications(notifications []backtesting.Notification) []apimodels.BacktestNotification {
+	result := make([]apimodels.BacktestNotification, 0, len(notifications))
+	for _, n := range notifications {
+		alerts := make([]apimodels.BacktestNotificationAlert, 0, len(n.Alerts))
+		for _, a := range n.Alerts {
+			alert := apimodels.BacktestNotificationAlert{
+				Labels:   a.Labels,
+				StartsAt: a.StartsAt,
+			}
+			if a.Resolved() {
+				alert.EndsAt = util.Pointer(a.EndsAt)
+			}
+			alerts = append(alerts, alert)
+		}
+		result = append(result, apimodels.BacktestNotification{
+			Time:        n.Time,
+			Receiver:    n.Receiver,
+			GroupLabels: n.GroupLabels,
+			Alerts:      alerts,
+			MutedBy:     n.MutedBy,
+		})
+	}
+	return result
+}
+
+// backtestingNotificationSettingsStore returns the notification settings of the rule that is tested only.
+type backtestingNotificationSettingsStore struct {
+	rule *ngmodels.AlertRule
+}
+
+func (s backtestingNotificationSettingsStore) ListNotificationSettings(_ context.Context, _ ngmodels.ListNotificationSettingsQuery) (map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings, error) {
+	return map[ngmodels.AlertRuleKey][]ngmodels.NotificationSettings{
+		s.rule.GetKey(): s.rule.NotificationSettings,
+	}, nil
+}
@@ -16,7 +16,7 @@ import (
 
 // AlertRuleFromProvisionedAlertRule converts definitions.ProvisionedAlertRule to models.AlertRule
 func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (models.AlertRule, error) {
-	return models.AlertRule{
+	rule := models.AlertRule{
 		ID:                   a.ID,
 		UID:                  a.UID,
 		OrgID:                a.OrgID,
@@ -34,12 +34,21 @@ func AlertRuleFromProvisionedAlertRule(a definitions.ProvisionedAlertRule) (mode
 		IsPaused:             a.IsPaused,
 		NotificationSettings: NotificationSettingsFromAlertRuleNotificationSettings(a.NotificationSettings),
 		Record:               ModelRecordFromApiRecord(a.Record),
-	}, nil
+		InhibitedBy:          ModelInhibitionRulesFromApiInhibitionRules(a.InhibitedBy),
+		Priority:             models.RulePriority(a.Priority),
+	}
+	if a.KeepFiringFor != nil {
+		rule.KeepFiringFor = time.Duration(*a.KeepFiringFor)
+	}
+	if a.EvaluationTimeout != nil {
+		rule.EvaluationTimeout = time.Duration(*a.EvaluationTimeout)
+	}
+	return rule, nil
 }
 
 // ProvisionedAlertRuleFromAlertRule converts models.AlertRule to definitions.ProvisionedAlertRule and sets provided provenance status
 func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.Provenance) definitions.ProvisionedAlertRule {
-	return definitions.ProvisionedAlertRule{
+	result := definitions.ProvisionedAlertRule{
 		ID:                   rule.ID,
 		UID:                  rule.UID,
 		OrgID:                rule.OrgID,
@@ -58,7 +67,16 @@ func ProvisionedAlertRuleFromAlertRule(rule models.AlertRule, provenance models.
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: AlertRuleNotificationSettingsFromNotificationSettings(rule.NotificationSettings),
 		Record:               ApiRecordFromModelRecord(rule.Record),
+		InhibitedBy:          ApiInhibitionRulesFromModelInhibitionRules(rule.InhibitedBy),
+		Priority:             rule.Priority.String(),
 	}
+	if rule.KeepFiringFor > 0 {
+		result.KeepFiringFor = util.Pointer(model.Duration(rule.KeepFiringFor))
+	}
+	if rule.EvaluationTimeout > 0 {
+		result.EvaluationTimeout = util.Pointer(model.Duration(rule.EvaluationTimeout))
+	}
+	return result
 }
 
 // ProvisionedAlertRuleFromAlertRules converts a collection of models.AlertRule to definitions.ProvisionedAlertRules with provenance status models.ProvenanceNone
@@ -213,6 +231,17 @@ func AlertRuleExportFromAlertRule(rule models.AlertRule) (definitions.AlertRuleE
 	if rule.For.Seconds() > 0 {
 		result.ForString = util.Pointer(model.Duration(rule.For).String())
 	}
+	if rule.KeepFiringFor.Seconds() > 0 {
+		result.KeepFiringFor = util.Pointer(model.Duration(rule.KeepFiringFor))
+		result.KeepFiringForString = util.Pointer(model.Duration(rule.KeepFiringFor).String())
+	}
+	if rule.EvaluationTimeout.Seconds() > 0 {
+		result.EvaluationTimeout = util.Pointer(model.Duration(rule.EvaluationTimeout))
+		result.EvaluationTimeoutString = util.Pointer(model.Duration(rule.EvaluationTimeout).String())
+	}
+	if rule.Priority != "" {
+		result.Priority = util.Pointer(rule.Priority.String())
+	}
 	if rule.Annotations != nil {
 		result.Annotations = &rule.Annotations
 	}
@@ -494,6 +523,7 @@ func AlertRuleRecordExportFromRecord(r *models.Record) *definitions.AlertRuleRec
 	return &definitions.AlertRuleRecordExport{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: r.Target,
 	}
 }
 
@@ -504,6 +534,7 @@ func ModelRecordFromApiRecord(r *definitions.Record) *models.Record {
 	return &models.Record{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: r.Target,
 	}
 }
 
@@ -514,7 +545,36 @@ func ApiRecordFromModelRecord(r *models.Record) *definitions.Record {
 	return &definitions.Record{
 		Metric: r.Metric,
 		From:   r.From,
+		Target: r.Target,
+	}
+}
+
+func ModelInhibitionRulesFromApiInhibitionRules(rules []definitions.InhibitionRule) []models.InhibitionRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	result := make([]models.InhibitionRule, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, models.InhibitionRule{
+			SourceRuleUID: r.SourceRuleUID,
+			Equal:         r.Equal,
+		})
 	}
+	return result
+}
+
+func ApiInhibitionRulesFromModelInhibitionRules(rules []models.InhibitionRule) []definitions.InhibitionRule {
+	if len(rules) == 0 {
+		return nil
+	}
+	result := make([]definitions.InhibitionRule, 0, len(rules))
+	for _, r := range rules {
+		result = append(result, definitions.InhibitionRule{
+			SourceRuleUID: r.SourceRuleUID,
+			Equal:         r.Equal,
+		})
+	}
+	return result
 }
 
 func GettableGrafanaReceiverFromReceiver(r *models.Integration, provenance models.Provenance) (definitions.GettableGrafanaReceiver, error) {
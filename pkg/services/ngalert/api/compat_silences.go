@@ -2,6 +2,9 @@ package api
 
 import (
 	"fmt"
+	"time"
+
+	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
@@ -67,3 +70,34 @@ func SilencePermissionToAPI(p models.SilencePermission) (definitions.SilencePerm
 		return "", fmt.Errorf("unknown permission: %s", p)
 	}
 }
+
+func PostableRecurringSilenceToRecurringSilence(s definitions.PostableRecurringSilence) models.RecurringSilence {
+	return models.RecurringSilence{
+		UID:       s.UID,
+		Matchers:  s.Matchers,
+		Schedule:  s.Schedule,
+		Duration:  time.Duration(s.Duration),
+		Timezone:  s.Timezone,
+		Comment:   s.Comment,
+		CreatedBy: s.CreatedBy,
+	}
+}
+
+func RecurringSilenceToGettableRecurringSilence(rs *models.RecurringSilence) definitions.GettableRecurringSilence {
+	silenceIDs := make([]string, 0, len(rs.Materialization.Silences))
+	for _, silence := range rs.Materialization.Silences {
+		silenceIDs = append(silenceIDs, silence.ID)
+	}
+	return definitions.GettableRecurringSilence{
+		UID:        rs.UID,
+		Matchers:   rs.Matchers,
+		Schedule:   rs.Schedule,
+		Duration:   model.Duration(rs.Duration),
+		Timezone:   rs.Timezone,
+		Comment:    rs.Comment,
+		CreatedBy:  rs.CreatedBy,
+		SilenceIDs: silenceIDs,
+		Provenance: definitions.Provenance(rs.Provenance),
+		UpdatedAt:  rs.Updated,
+	}
+}
@@ -167,6 +167,18 @@ func (f *AlertmanagerApiHandler) handleRoutePostGrafanaAlertingConfigHistoryActi
 	return f.GrafanaSvc.RoutePostGrafanaAlertingConfigHistoryActivate(ctx, id)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaAlertingConfigVersions(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetAlertingConfigVersions(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaAlertingConfigDiff(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetAlertingConfigDiff(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRoutePostGrafanaAlertingConfigHistoryRollback(ctx *contextmodel.ReqContext, id string) response.Response {
+	return f.GrafanaSvc.RoutePostGrafanaAlertingConfigHistoryRollback(ctx, id)
+}
+
 func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilence(ctx *contextmodel.ReqContext, id string) response.Response {
 	return f.GrafanaSvc.RouteGetSilence(ctx, id)
 }
@@ -175,6 +187,22 @@ func (f *AlertmanagerApiHandler) handleRouteGetGrafanaSilences(ctx *contextmodel
 	return f.GrafanaSvc.RouteGetSilences(ctx)
 }
 
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaRecurringSilence(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaSvc.RouteGetRecurringSilence(ctx, uid)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteGetGrafanaRecurringSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.GrafanaSvc.RouteGetRecurringSilences(ctx)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteCreateGrafanaRecurringSilence(ctx *contextmodel.ReqContext, body apimodels.PostableRecurringSilence) response.Response {
+	return f.GrafanaSvc.RouteCreateRecurringSilence(ctx, body)
+}
+
+func (f *AlertmanagerApiHandler) handleRouteDeleteGrafanaRecurringSilence(ctx *contextmodel.ReqContext, uid string) response.Response {
+	return f.GrafanaSvc.RouteDeleteRecurringSilence(ctx, uid)
+}
+
 func (f *AlertmanagerApiHandler) handleRoutePostGrafanaAlertingConfig(ctx *contextmodel.ReqContext, conf apimodels.PostableUserConfig) response.Response {
 	if !conf.AlertmanagerConfig.ReceiverType().Can(apimodels.GrafanaReceiverType) {
 		return errorToResponse(backendTypeDoesNotMatchPayloadTypeError(apimodels.GrafanaBackend, conf.AlertmanagerConfig.ReceiverType().String()))
@@ -20,10 +20,12 @@ import (
 )
 
 type AlertmanagerApi interface {
+	RouteCreateGrafanaRecurringSilence(*contextmodel.ReqContext) response.Response
 	RouteCreateGrafanaSilence(*contextmodel.ReqContext) response.Response
 	RouteCreateSilence(*contextmodel.ReqContext) response.Response
 	RouteDeleteAlertingConfig(*contextmodel.ReqContext) response.Response
 	RouteDeleteGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
+	RouteDeleteGrafanaRecurringSilence(*contextmodel.ReqContext) response.Response
 	RouteDeleteGrafanaSilence(*contextmodel.ReqContext) response.Response
 	RouteDeleteSilence(*contextmodel.ReqContext) response.Response
 	RouteGetAMAlertGroups(*contextmodel.ReqContext) response.Response
@@ -34,8 +36,12 @@ type AlertmanagerApi interface {
 	RouteGetGrafanaAMAlerts(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAMStatus(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaAlertingConfigDiff(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaAlertingConfigHistory(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaAlertingConfigVersions(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaReceivers(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaRecurringSilence(*contextmodel.ReqContext) response.Response
+	RouteGetGrafanaRecurringSilences(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaSilence(*contextmodel.ReqContext) response.Response
 	RouteGetGrafanaSilences(*contextmodel.ReqContext) response.Response
 	RouteGetSilence(*contextmodel.ReqContext) response.Response
@@ -44,10 +50,19 @@ type AlertmanagerApi interface {
 	RoutePostAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfig(*contextmodel.ReqContext) response.Response
 	RoutePostGrafanaAlertingConfigHistoryActivate(*contextmodel.ReqContext) response.Response
+	RoutePostGrafanaAlertingConfigHistoryRollback(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaReceivers(*contextmodel.ReqContext) response.Response
 	RoutePostTestGrafanaTemplates(*contextmodel.ReqContext) response.Response
 }
 
+func (f *AlertmanagerApiHandler) RouteCreateGrafanaRecurringSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Request Body
+	conf := apimodels.PostableRecurringSilence{}
+	if err := web.Bind(ctx.Req, &conf); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return f.handleRouteCreateGrafanaRecurringSilence(ctx, conf)
+}
 func (f *AlertmanagerApiHandler) RouteCreateGrafanaSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.PostableSilence{}
@@ -74,6 +89,11 @@ func (f *AlertmanagerApiHandler) RouteDeleteAlertingConfig(ctx *contextmodel.Req
 func (f *AlertmanagerApiHandler) RouteDeleteGrafanaAlertingConfig(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteDeleteGrafanaAlertingConfig(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteDeleteGrafanaRecurringSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	recurringSilenceUIDParam := web.Params(ctx.Req)[":RecurringSilenceUID"]
+	return f.handleRouteDeleteGrafanaRecurringSilence(ctx, recurringSilenceUIDParam)
+}
 func (f *AlertmanagerApiHandler) RouteDeleteGrafanaSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
@@ -117,12 +137,26 @@ func (f *AlertmanagerApiHandler) RouteGetGrafanaAMStatus(ctx *contextmodel.ReqCo
 func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfig(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaAlertingConfig(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfigDiff(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaAlertingConfigDiff(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfigHistory(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaAlertingConfigHistory(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaAlertingConfigVersions(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaAlertingConfigVersions(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	return f.handleRouteGetGrafanaReceivers(ctx)
 }
+func (f *AlertmanagerApiHandler) RouteGetGrafanaRecurringSilence(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	recurringSilenceUIDParam := web.Params(ctx.Req)[":RecurringSilenceUID"]
+	return f.handleRouteGetGrafanaRecurringSilence(ctx, recurringSilenceUIDParam)
+}
+func (f *AlertmanagerApiHandler) RouteGetGrafanaRecurringSilences(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetGrafanaRecurringSilences(ctx)
+}
 func (f *AlertmanagerApiHandler) RouteGetGrafanaSilence(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	silenceIdParam := web.Params(ctx.Req)[":SilenceId"]
@@ -175,6 +209,11 @@ func (f *AlertmanagerApiHandler) RoutePostGrafanaAlertingConfigHistoryActivate(c
 	idParam := web.Params(ctx.Req)[":id"]
 	return f.handleRoutePostGrafanaAlertingConfigHistoryActivate(ctx, idParam)
 }
+func (f *AlertmanagerApiHandler) RoutePostGrafanaAlertingConfigHistoryRollback(ctx *contextmodel.ReqContext) response.Response {
+	// Parse Path Parameters
+	idParam := web.Params(ctx.Req)[":id"]
+	return f.handleRoutePostGrafanaAlertingConfigHistoryRollback(ctx, idParam)
+}
 func (f *AlertmanagerApiHandler) RoutePostTestGrafanaReceivers(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Request Body
 	conf := apimodels.TestReceiversConfigBodyParams{}
@@ -194,6 +233,18 @@ func (f *AlertmanagerApiHandler) RoutePostTestGrafanaTemplates(ctx *contextmodel
 
 func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v2/recurring-silences"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v2/recurring-silences",
+				api.Hooks.Wrap(srv.RouteCreateGrafanaRecurringSilence),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silences"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -242,6 +293,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodDelete, "/api/alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID}",
+				api.Hooks.Wrap(srv.RouteDeleteGrafanaRecurringSilence),
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silence/{SilenceId}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -362,6 +425,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/history/diff"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/history/diff"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/history/diff",
+				api.Hooks.Wrap(srv.RouteGetGrafanaAlertingConfigDiff),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/config/history"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -374,6 +449,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/config/history/versions"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/config/history/versions"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/config/history/versions",
+				api.Hooks.Wrap(srv.RouteGetGrafanaAlertingConfigVersions),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -386,6 +473,30 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID}"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID}",
+				api.Hooks.Wrap(srv.RouteGetGrafanaRecurringSilence),
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v2/recurring-silences"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v2/recurring-silences"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v2/recurring-silences",
+				api.Hooks.Wrap(srv.RouteGetGrafanaRecurringSilences),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/grafana/api/v2/silence/{SilenceId}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -482,6 +593,18 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApi, m *metrics
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/history/{id}/_rollback"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/history/{id}/_rollback"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/history/{id}/_rollback",
+				api.Hooks.Wrap(srv.RoutePostGrafanaAlertingConfigHistoryRollback),
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/grafana/config/api/v1/receivers/test"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
@@ -19,12 +19,16 @@ import (
 )
 
 type NotificationsApi interface {
+	RouteGetNotificationHistory(*contextmodel.ReqContext) response.Response
 	RouteGetReceiver(*contextmodel.ReqContext) response.Response
 	RouteGetReceivers(*contextmodel.ReqContext) response.Response
 	RouteNotificationsGetTimeInterval(*contextmodel.ReqContext) response.Response
 	RouteNotificationsGetTimeIntervals(*contextmodel.ReqContext) response.Response
 }
 
+func (f *NotificationsApiHandler) RouteGetNotificationHistory(ctx *contextmodel.ReqContext) response.Response {
+	return f.handleRouteGetNotificationHistory(ctx)
+}
 func (f *NotificationsApiHandler) RouteGetReceiver(ctx *contextmodel.ReqContext) response.Response {
 	// Parse Path Parameters
 	nameParam := web.Params(ctx.Req)[":name"]
@@ -44,6 +48,18 @@ func (f *NotificationsApiHandler) RouteNotificationsGetTimeIntervals(ctx *contex
 
 func (api *API) RegisterNotificationsApiEndpoints(srv NotificationsApi, m *metrics.API) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/notifications/history"),
+			requestmeta.SetOwner(requestmeta.TeamAlerting),
+			requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow),
+			api.authorize(http.MethodGet, "/api/v1/notifications/history"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/notifications/history",
+				api.Hooks.Wrap(srv.RouteGetNotificationHistory),
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/notifications/receivers/{Name}"),
 			requestmeta.SetOwner(requestmeta.TeamAlerting),
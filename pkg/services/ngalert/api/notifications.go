@@ -27,6 +27,10 @@ func (f *NotificationsApiHandler) handleRouteGetReceiver(ctx *contextmodel.ReqCo
 	return f.notificationSrv.RouteGetReceiver(ctx, name)
 }
 
+func (f *NotificationsApiHandler) handleRouteGetNotificationHistory(ctx *contextmodel.ReqContext) response.Response {
+	return f.notificationSrv.RouteGetNotificationHistory(ctx)
+}
+
 func (f *NotificationsApiHandler) handleRouteGetReceivers(ctx *contextmodel.ReqContext) response.Response {
 	return f.notificationSrv.RouteGetReceivers(ctx)
 }
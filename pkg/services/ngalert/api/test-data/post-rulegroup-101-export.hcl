@@ -42,10 +42,13 @@ resource "grafana_rule_group" "rule_group_d3e8424bfbf66bc3" {
       model          = "{\"expression\":\"$reduced > 10\",\"hide\":false,\"intervalMs\":1000,\"maxDataPoints\":100,\"refId\":\"condition\",\"type\":\"math\"}"
     }
 
-    no_data_state  = "NoData"
-    exec_err_state = "Alerting"
-    for            = "2m"
-    is_paused      = false
+    no_data_state      = "NoData"
+    exec_err_state     = "Alerting"
+    for                = "2m"
+    keep_firing_for    = "5m"
+    is_paused          = false
+    evaluation_timeout = "30s"
+    priority           = "critical"
   }
   rule {
     name      = "reduced testdata query - 2"
@@ -68,6 +68,32 @@ import (
 //       400: ValidationError
 //       404: NotFound
 
+// swagger:route GET /alertmanager/grafana/config/history/versions alertmanager RouteGetGrafanaAlertingConfigVersions
+//
+// gets the versions of the Alerting configuration, applied or not, with the user that saved them
+//
+//     Responses:
+//       200: GettableAlertmanagerConfigVersions
+
+// swagger:route GET /alertmanager/grafana/config/history/diff alertmanager RouteGetGrafanaAlertingConfigDiff
+//
+// gets the routes, receivers, templates and mute timings that changed between two versions of the Alerting configuration
+//
+//     Responses:
+//       200: AlertmanagerConfigDiff
+//       400: ValidationError
+//       404: NotFound
+
+// swagger:route POST /alertmanager/grafana/config/history/{id}/_rollback alertmanager RoutePostGrafanaAlertingConfigHistoryRollback
+//
+// restore the Alerting configuration specified by the given id, with the same validation as when an Alerting config is set
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: NotFound
+//       409: AlertManagerNotReady
+
 // swagger:route DELETE /alertmanager/grafana/config/api/v1/alerts alertmanager RouteDeleteGrafanaAlertingConfig
 //
 // deletes the Alerting config for a tenant
@@ -288,6 +314,24 @@ type RouteGetGrafanaAlertingConfigHistoryParams struct {
 	Limit int `json:"limit"`
 }
 
+// swagger:parameters RouteGetGrafanaAlertingConfigVersions
+type RouteGetGrafanaAlertingConfigVersionsParams struct {
+	// Limit response to n configuration versions.
+	// in:query
+	Limit int `json:"limit"`
+}
+
+// swagger:parameters RouteGetGrafanaAlertingConfigDiff
+type RouteGetGrafanaAlertingConfigDiffParams struct {
+	// From is the id of the version to compare.
+	// in:query
+	// required: true
+	From int64 `json:"from"`
+	// To is the id of the version to compare with. Defaults to the latest version.
+	// in:query
+	To int64 `json:"to"`
+}
+
 // swagger:parameters RoutePostTestGrafanaReceivers
 type TestReceiversConfigParams struct {
 	// in:body
@@ -297,6 +341,8 @@ type TestReceiversConfigParams struct {
 type TestReceiversConfigBodyParams struct {
 	Alert     *TestReceiversConfigAlertParams `yaml:"alert,omitempty" json:"alert,omitempty"`
 	Receivers []*PostableApiReceiver          `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+	// Capture renders the test notifications and returns them in the result instead of delivering them.
+	Capture bool `yaml:"capture,omitempty" json:"capture,omitempty"`
 }
 
 type TestReceiversConfigAlertParams struct {
@@ -323,6 +369,27 @@ type TestReceiverConfigResult struct {
 	UID    string `json:"uid"`
 	Status string `json:"status"`
 	Error  string `json:"error,omitempty"`
+	// Captured are the notifications that the integration rendered when the test is run in capture mode.
+	Captured []TestReceiverCapturedNotification `json:"captured,omitempty"`
+}
+
+// swagger:model
+type TestReceiverCapturedNotification struct {
+	// Kind is webhook for notifications that are sent as HTTP requests, email for emails, and the type of the integration for integrations that deliver notifications with their own clients.
+	// enum: webhook,email,slack,sns,mqtt
+	Kind string `json:"kind"`
+	// Method is the HTTP method of a webhook.
+	Method string `json:"method,omitempty"`
+	// Headers are the HTTP headers of a webhook. The Authorization header is redacted.
+	Headers map[string]string `json:"headers,omitempty"`
+	// Payload is the body of the HTTP request of a webhook, or the message that an MQTT integration publishes.
+	Payload string `json:"payload,omitempty"`
+	// To are the recipients of an email, or the channel, topic or phone number that a Slack, SNS or MQTT integration notifies.
+	To []string `json:"to,omitempty"`
+	// Title is the subject of an email, or the title of a Slack or SNS message.
+	Title string `json:"title,omitempty"`
+	// Body is the rendered body of an email, in HTML if it is one of the configured content types, or the text of a Slack or SNS message.
+	Body string `json:"body,omitempty"`
 }
 
 // swagger:parameters RoutePostTestGrafanaTemplates
@@ -600,7 +667,7 @@ type BodyAlertingConfig struct {
 	Body PostableUserConfig
 }
 
-// swagger:parameters RoutePostGrafanaAlertingConfigHistoryActivate
+// swagger:parameters RoutePostGrafanaAlertingConfigHistoryActivate RoutePostGrafanaAlertingConfigHistoryRollback
 type HistoricalConfigId struct {
 	// Id should be the id of the GettableHistoricUserConfig
 	// in:path
@@ -831,6 +898,43 @@ type GettableHistoricUserConfigs struct {
 	Body []GettableHistoricUserConfig
 }
 
+// swagger:model
+type GettableAlertmanagerConfigVersion struct {
+	ID int64 `yaml:"id" json:"id"`
+	// Author is the login of the user that saved the version. It is empty if the version was saved by Grafana, for
+	// example when the default configuration was applied.
+	Author      string           `yaml:"author" json:"author"`
+	CreatedAt   strfmt.DateTime  `yaml:"created_at" json:"created_at"`
+	LastApplied *strfmt.DateTime `yaml:"last_applied,omitempty" json:"last_applied,omitempty"`
+	Default     bool             `yaml:"default" json:"default"`
+	Hash        string           `yaml:"hash" json:"hash"`
+}
+
+// swagger:response GettableAlertmanagerConfigVersions
+type GettableAlertmanagerConfigVersions struct {
+	// in:body
+	Body []GettableAlertmanagerConfigVersion
+}
+
+// AlertmanagerConfigDiff is the semantic difference between two versions of the Alertmanager configuration.
+// swagger:model
+type AlertmanagerConfigDiff struct {
+	From int64 `yaml:"from" json:"from"`
+	To   int64 `yaml:"to" json:"to"`
+	// Routes are identified by their path in the routing tree, made of the matchers of the route and its parents.
+	Routes      AlertmanagerConfigObjectsDiff `yaml:"routes" json:"routes"`
+	Receivers   AlertmanagerConfigObjectsDiff `yaml:"receivers" json:"receivers"`
+	Templates   AlertmanagerConfigObjectsDiff `yaml:"templates" json:"templates"`
+	MuteTimings AlertmanagerConfigObjectsDiff `yaml:"mute_timings" json:"mute_timings"`
+}
+
+// AlertmanagerConfigObjectsDiff lists the names of the objects of one kind that were added, removed or modified.
+type AlertmanagerConfigObjectsDiff struct {
+	Added    []string `yaml:"added" json:"added"`
+	Removed  []string `yaml:"removed" json:"removed"`
+	Modified []string `yaml:"modified" json:"modified"`
+}
+
 type GettableApiAlertingConfig struct {
 	Config              `yaml:",inline"`
 	MuteTimeProvenances map[string]Provenance `yaml:"muteTimeProvenances,omitempty" json:"muteTimeProvenances,omitempty"`
@@ -69,6 +69,7 @@ import (
 //     - application/yaml
 //
 //     Responses:
+//       200: UpdateRuleGroupResponse
 //       202: UpdateRuleGroupResponse
 //       403: ForbiddenError
 //
@@ -194,6 +195,15 @@ type NamespaceConfig struct {
 	Body PostableRuleGroupConfig
 }
 
+// swagger:parameters RoutePostNameGrafanaRulesConfig
+type PreviewRuleGroupParams struct {
+	// If true, the rule group is not updated. Instead, the existing and the new versions of the changed rules are
+	// evaluated once at the current time and the differences between their alert instances are returned.
+	// in: query
+	// required: false
+	Preview bool `json:"preview"`
+}
+
 // swagger:parameters RouteGetNamespaceRulesConfig RouteDeleteNamespaceRulesConfig RouteGetNamespaceGrafanaRulesConfig RouteDeleteNamespaceGrafanaRulesConfig
 type PathNamespaceConfig struct {
 	// The UID of the rule folder
@@ -497,6 +507,22 @@ type Record struct {
 	// required: true
 	// example: A
 	From string `json:"from" yaml:"from"`
+	// Where the recorded metric is written to. If it is empty, the target that is configured for the organization is used.
+	// enum: prometheus,sql,influxdb,otlp
+	// example: sql
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+// swagger:model
+type InhibitionRule struct {
+	// UID of the rule whose firing alerts suppress the alerts of the rule.
+	// required: true
+	// example: datacenter-unreachable
+	SourceRuleUID string `json:"source_rule_uid" yaml:"source_rule_uid"`
+	// Labels that must have equal values in the alerts of both rules for the alert to be suppressed.
+	// All alerts of the rule are suppressed if empty.
+	// example: ["datacenter"]
+	Equal []string `json:"equal,omitempty" yaml:"equal,omitempty"`
 }
 
 // swagger:model
@@ -511,6 +537,12 @@ type PostableGrafanaRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings" yaml:"notification_settings"`
 	Record               *Record                        `json:"record" yaml:"record"`
 	Metadata             *AlertRuleMetadata             `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	InhibitedBy          []InhibitionRule               `json:"inhibited_by,omitempty" yaml:"inhibited_by,omitempty"`
+	// Overrides the evaluation timeout that is configured for the scheduler if it is set.
+	EvaluationTimeout *model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	// The priority class of the rule when the number of concurrent evaluations is limited. Empty is the same as normal.
+	// enum: critical,normal,low
+	Priority string `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 // swagger:model
@@ -533,6 +565,12 @@ type GettableGrafanaRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	Record               *Record                        `json:"record,omitempty" yaml:"record,omitempty"`
 	Metadata             *AlertRuleMetadata             `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	InhibitedBy          []InhibitionRule               `json:"inhibited_by,omitempty" yaml:"inhibited_by,omitempty"`
+	// Overrides the evaluation timeout that is configured for the scheduler if it is set.
+	EvaluationTimeout *model.Duration `json:"evaluation_timeout,omitempty" yaml:"evaluation_timeout,omitempty"`
+	// The priority class of the rule when the number of concurrent evaluations is limited. Empty is the same as normal.
+	// enum: critical,normal,low
+	Priority string `json:"priority,omitempty" yaml:"priority,omitempty"`
 }
 
 // AlertQuery represents a single query associated with an alert definition.
@@ -608,4 +646,31 @@ type UpdateRuleGroupResponse struct {
 	Created []string `json:"created,omitempty"`
 	Updated []string `json:"updated,omitempty"`
 	Deleted []string `json:"deleted,omitempty"`
+	// Preview contains the behavior of the changed rules if the rule group was previewed instead of updated.
+	Preview []RuleChangePreview `json:"preview,omitempty"`
+}
+
+// RuleChangePreview is the difference between the alert instances of the existing and the new version of a rule,
+// when both are evaluated once at the current time.
+type RuleChangePreview struct {
+	// UID of the rule. It is empty for rules that would be created.
+	UID   string `json:"uid,omitempty"`
+	Title string `json:"title"`
+	// enum: created,updated,deleted
+	Change string `json:"change"`
+	// Error is set if the existing or the new version of the rule could not be evaluated.
+	Error     string                 `json:"error,omitempty"`
+	Instances []AlertInstancePreview `json:"instances,omitempty"`
+}
+
+// AlertInstancePreview is the difference between the states of an alert instance in the existing and the new version
+// of a rule. Pending alert instances count as firing.
+type AlertInstancePreview struct {
+	// The alert instance starts firing, stops firing, has different labels or has a different state.
+	// enum: firing,resolved,labels,state
+	Change    string            `json:"change"`
+	OldState  string            `json:"oldState,omitempty"`
+	NewState  string            `json:"newState,omitempty"`
+	OldLabels map[string]string `json:"oldLabels,omitempty"`
+	NewLabels map[string]string `json:"newLabels,omitempty"`
 }
@@ -0,0 +1,82 @@
+package definitions
+
+import "time"
+
+// swagger:route GET /v1/notifications/history notifications RouteGetNotificationHistory
+//
+// Get the delivery attempts of notifications to the integrations of contact points, newest first.
+//
+// The delivery attempts are recorded only if the notification history is enabled.
+//
+//    Responses:
+//      200: GetNotificationHistoryResponse
+//      400: ValidationError
+//      403: PermissionDenied
+
+// swagger:parameters RouteGetNotificationHistory
+type GetNotificationHistoryParams struct {
+	// Filter by the name of the contact point.
+	// in:query
+	// required: false
+	Receiver string `json:"receiver"`
+	// Filter by the type of the integration, for example slack or email.
+	// in:query
+	// required: false
+	Integration string `json:"integration"`
+	// Filter by the result of the delivery attempt.
+	// in:query
+	// required: false
+	// enum: success,failure
+	Status string `json:"status"`
+	// Filter by the fingerprint of a notified alert.
+	// in:query
+	// required: false
+	Fingerprint string `json:"fingerprint"`
+	// The Unix timestamp in seconds of the start of the time range.
+	// in:query
+	// required: false
+	From int64 `json:"from"`
+	// The Unix timestamp in seconds of the end of the time range.
+	// in:query
+	// required: false
+	To int64 `json:"to"`
+	// The maximum number of delivery attempts to return. Larger values are reduced to 1000.
+	// in:query
+	// required: false
+	// default: 100
+	// minimum: 0
+	Limit int `json:"limit"`
+	// The number of delivery attempts to skip.
+	// in:query
+	// required: false
+	// minimum: 0
+	Offset int `json:"offset"`
+}
+
+// swagger:response GetNotificationHistoryResponse
+type GetNotificationHistoryResponse struct {
+	// in:body
+	Body []NotificationHistoryEntry
+}
+
+// NotificationHistoryEntry is a delivery attempt of a notification to an integration of a contact point.
+// swagger:model
+type NotificationHistoryEntry struct {
+	Receiver string `json:"receiver"`
+	// example: slack
+	Integration      string `json:"integration"`
+	IntegrationIndex int    `json:"integrationIndex"`
+	GroupKey         string `json:"groupKey"`
+	// The fingerprints of the notified alerts.
+	AlertFingerprints []string `json:"alertFingerprints"`
+	// enum: success,failure
+	Status string `json:"status"`
+	// The HTTP status code of the response of the integration, if it sends HTTP requests through Grafana.
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// True if the failed attempt is retried.
+	Retry bool `json:"retry"`
+	// The duration of the attempt in milliseconds.
+	DurationMs int64     `json:"durationMs"`
+	SentAt     time.Time `json:"sentAt"`
+}
@@ -158,6 +158,8 @@ type ProvisionedAlertRule struct {
 	// required: true
 	// swagger:strfmt duration
 	For model.Duration `json:"for"`
+	// swagger:strfmt duration
+	KeepFiringFor *model.Duration `json:"keepFiringFor,omitempty"`
 	// example: {"runbook_url": "https://supercoolrunbook.com/page/13"}
 	Annotations map[string]string `json:"annotations,omitempty"`
 	// example: {"team": "sre-team-1"}
@@ -170,6 +172,13 @@ type ProvisionedAlertRule struct {
 	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings"`
 	//example: {"metric":"grafana_alerts_ratio", "from":"A"}
 	Record *Record `json:"record"`
+	// example: [{"source_rule_uid":"datacenter-unreachable","equal":["datacenter"]}]
+	InhibitedBy []InhibitionRule `json:"inhibited_by,omitempty"`
+	// swagger:strfmt duration
+	EvaluationTimeout *model.Duration `json:"evaluationTimeout,omitempty"`
+	// enum: critical,normal,low
+	// example: critical
+	Priority string `json:"priority,omitempty"`
 }
 
 // swagger:route GET /v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning stable RouteGetAlertRuleGroup
@@ -271,12 +280,19 @@ type AlertRuleExport struct {
 	// ForString is used to:
 	// - Only export the for field for HCL if it is non-zero.
 	// - Format the Prometheus model.Duration type properly for HCL.
-	ForString            *string                              `json:"-" yaml:"-" hcl:"for"`
+	ForString     *string         `json:"-" yaml:"-" hcl:"for"`
+	KeepFiringFor *model.Duration `json:"keepFiringFor,omitempty" yaml:"keepFiringFor,omitempty"`
+	// KeepFiringForString is used in the same way as ForString.
+	KeepFiringForString  *string                              `json:"-" yaml:"-" hcl:"keep_firing_for"`
 	Annotations          *map[string]string                   `json:"annotations,omitempty" yaml:"annotations,omitempty" hcl:"annotations"`
 	Labels               *map[string]string                   `json:"labels,omitempty" yaml:"labels,omitempty" hcl:"labels"`
 	IsPaused             bool                                 `json:"isPaused" yaml:"isPaused" hcl:"is_paused"`
 	NotificationSettings *AlertRuleNotificationSettingsExport `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty" hcl:"notification_settings,block"`
 	Record               *AlertRuleRecordExport               `json:"record,omitempty" yaml:"record,omitempty" hcl:"record,block"`
+	EvaluationTimeout    *model.Duration                      `json:"evaluationTimeout,omitempty" yaml:"evaluationTimeout,omitempty"`
+	// EvaluationTimeoutString is used in the same way as ForString.
+	EvaluationTimeoutString *string `json:"-" yaml:"-" hcl:"evaluation_timeout"`
+	Priority                *string `json:"priority,omitempty" yaml:"priority,omitempty" hcl:"priority"`
 }
 
 // AlertQueryExport is the provisioned export of models.AlertQuery.
@@ -310,4 +326,5 @@ type AlertRuleNotificationSettingsExport struct {
 type AlertRuleRecordExport struct {
 	Metric string `json:"metric" yaml:"metric" hcl:"metric"`
 	From   string `json:"from" yaml:"from" hcl:"from"`
+	Target string `json:"target,omitempty" yaml:"target,omitempty" hcl:"target,optional"`
 }
@@ -0,0 +1,101 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /alertmanager/grafana/api/v2/recurring-silences alertmanager RouteGetGrafanaRecurringSilences
+//
+// get recurring silences
+//
+//     Responses:
+//       200: gettableRecurringSilences
+//       400: ValidationError
+
+// swagger:route POST /alertmanager/grafana/api/v2/recurring-silences alertmanager RouteCreateGrafanaRecurringSilence
+//
+// create or update a recurring silence
+//
+// The recurring silence is updated if a recurring silence with the same UID exists. The silences of the windows of
+// the previous version that have not ended are expired.
+//
+//     Responses:
+//       202: postRecurringSilenceOKBody
+//       400: ValidationError
+//       409: PublicError
+
+// swagger:route GET /alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID} alertmanager RouteGetGrafanaRecurringSilence
+//
+// get recurring silence
+//
+//     Responses:
+//       200: gettableRecurringSilence
+//       404: PublicError
+
+// swagger:route DELETE /alertmanager/grafana/api/v2/recurring-silence/{RecurringSilenceUID} alertmanager RouteDeleteGrafanaRecurringSilence
+//
+// delete a recurring silence and expire its silences
+//
+//     Responses:
+//       200: Ack
+//       404: PublicError
+//       409: PublicError
+
+// swagger:parameters RouteCreateGrafanaRecurringSilence
+type CreateRecurringSilenceParams struct {
+	// in:body
+	RecurringSilence PostableRecurringSilence
+}
+
+// swagger:parameters RouteGetGrafanaRecurringSilence RouteDeleteGrafanaRecurringSilence
+type GetDeleteRecurringSilenceParams struct {
+	// in:path
+	RecurringSilenceUID string
+}
+
+// PostableRecurringSilence is a silence that repeats on a schedule. The windows of the schedule are created as
+// silences ahead of time.
+// swagger:model postableRecurringSilence
+type PostableRecurringSilence struct {
+	UID string `json:"uid,omitempty"`
+	// required: true
+	Matchers amv2.Matchers `json:"matchers"`
+	// The starts of the windows as a cron expression with five fields, or a descriptor such as @weekly.
+	// required: true
+	// example: 0 2 * * SUN
+	Schedule string `json:"schedule"`
+	// The length of every window.
+	// required: true
+	Duration model.Duration `json:"duration"`
+	// The IANA name of the location the schedule is evaluated in. Defaults to UTC.
+	// example: Europe/Berlin
+	Timezone  string `json:"timezone,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+// swagger:model gettableRecurringSilence
+type GettableRecurringSilence struct {
+	UID       string         `json:"uid"`
+	Matchers  amv2.Matchers  `json:"matchers"`
+	Schedule  string         `json:"schedule"`
+	Duration  model.Duration `json:"duration"`
+	Timezone  string         `json:"timezone,omitempty"`
+	Comment   string         `json:"comment,omitempty"`
+	CreatedBy string         `json:"createdBy,omitempty"`
+	// The IDs of the silences of the windows that have not ended.
+	SilenceIDs []string   `json:"silenceIds"`
+	Provenance Provenance `json:"provenance,omitempty"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+// swagger:model gettableRecurringSilences
+type GettableRecurringSilences []GettableRecurringSilence
+
+// swagger:model postRecurringSilenceOKBody
+type PostRecurringSilenceOKBody struct {
+	UID string `json:"uid"`
+}
@@ -15,6 +15,7 @@ import "github.com/grafana/grafana-plugin-sdk-go/data"
 //
 //     Responses:
 //       200: StateHistory
+//       400: ValidationError
 //       404: NotFound
 //       403: ForbiddenError
 //       500: Failure
@@ -50,4 +51,8 @@ type StateHistoryParams struct {
 	DashboardUID string
 	// Filter by dashboard's panel ID. Requires Dashboard UID to be specified.
 	PanelID int64
+	// Filter by the labels of the alert instances with matchers in JSON format, for example {"name":"severity","value":"crit.*","isRegex":true,"isEqual":true}. Can be repeated.
+	// in:query
+	// required: false
+	Matcher []string `json:"matcher"`
 }
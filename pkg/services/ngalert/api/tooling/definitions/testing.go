@@ -230,7 +230,48 @@ type BacktestConfig struct {
 	Annotations map[string]string `json:"annotations,omitempty"`
 
 	NoDataState NoDataState `json:"no_data_state"`
+
+	// Record makes the rule a recording rule. If set, the response is a frame with the series that the rule would have
+	// written, Condition, For and NoDataState are ignored, and notifications cannot be simulated.
+	Record *Record `json:"record,omitempty"`
+
+	// NotificationSettings are the notification settings of the rule. If set, the alerts of the rule are
+	// routed to the contact point of the settings instead of the notification policy tree.
+	NotificationSettings *AlertRuleNotificationSettings `json:"notification_settings,omitempty"`
+	// Notifications enables the simulation of the notifications that the alerts of the rule would have triggered.
+	// If true, the response is BacktestNotificationsResult instead of BacktestResult.
+	Notifications bool `json:"notifications,omitempty"`
+	// NamespaceUID is the UID of the folder of the rule. It is required to simulate notifications, as the folder is
+	// added to the labels of the alerts.
+	NamespaceUID string `json:"folderUid,omitempty"`
 }
 
 // swagger:model
 type BacktestResult data.Frame
+
+// swagger:model
+type BacktestNotificationsResult struct {
+	// States is the frame with the states of the alerts at every evaluation, as in BacktestResult.
+	States *data.Frame `json:"states"`
+	// Notifications are the notifications in the order they would have been sent.
+	Notifications []BacktestNotification `json:"notifications"`
+}
+
+// swagger:model
+type BacktestNotification struct {
+	Time     time.Time `json:"time"`
+	Receiver string    `json:"receiver"`
+	// GroupLabels are the labels the alerts of the notification are grouped by.
+	GroupLabels map[string]string           `json:"groupLabels"`
+	Alerts      []BacktestNotificationAlert `json:"alerts"`
+	// MutedBy is the name of the time interval the notification would have been muted by. Muted notifications are not sent.
+	MutedBy string `json:"mutedBy,omitempty"`
+}
+
+// swagger:model
+type BacktestNotificationAlert struct {
+	Labels   map[string]string `json:"labels"`
+	StartsAt time.Time         `json:"startsAt"`
+	// EndsAt is set if the alert is resolved.
+	EndsAt *time.Time `json:"endsAt,omitempty"`
+}
@@ -24,8 +24,9 @@ import (
 var (
 	ErrInvalidInputData = errors.New("invalid input data")
 
-	logger                      = log.New("ngalert.backtesting.engine")
-	backtestingEvaluatorFactory = newBacktestingEvaluator
+	logger                               = log.New("ngalert.backtesting.engine")
+	backtestingEvaluatorFactory          = newBacktestingEvaluator
+	backtestingRecordingEvaluatorFactory = newBacktestingRecordingEvaluator
 )
 
 type callbackFunc = func(evaluationIndex int, now time.Time, results eval.Results) error
@@ -34,6 +35,14 @@ type backtestingEvaluator interface {
 	Eval(ctx context.Context, from time.Time, interval time.Duration, evaluations int, callback callbackFunc) error
 }
 
+// recordingCallbackFunc is called with the frames of the output node of a recording rule. frames is nil if the
+// evaluation returned no data, and err is set if the output node failed.
+type recordingCallbackFunc = func(evaluationIndex int, now time.Time, frames data.Frames, err error) error
+
+type backtestingRecordingEvaluator interface {
+	EvalRecording(ctx context.Context, from time.Time, interval time.Duration, evaluations int, callback recordingCallbackFunc) error
+}
+
 type stateManager interface {
 	ProcessEvalResults(context.Context, time.Time, *models.AlertRule, eval.Results, data.Labels, state.Sender) state.StateTransitions
 	schedule.RuleStateProvider
@@ -63,17 +72,44 @@ func NewEngine(appUrl *url.URL, evalFactory eval.EvaluatorFactory, tracer tracin
 	}
 }
 
+// Test evaluates the rule over the range [from, to) at the interval of the rule. For alerting rules, it returns a frame with
+// the state of every alert instance at every evaluation. For recording rules, it returns a frame with the series that would
+// have been written.
 func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+	if rule.Type() == models.RuleTypeRecording {
+		return e.testRecording(ctx, user, rule, from, to)
+	}
+	return e.test(ctx, user, rule, from, to, nil, nil)
+}
+
+// TestNotifications tests the rule like Test, and also replays the alerts of the rule through the notification policy.
+// It returns the notifications that would have been sent in the order they would have been sent, including the
+// notifications that would have been muted. folderTitle is the title of the folder of the rule, which is added to the
+// labels of the alerts like the scheduler does unless includeFolder is false.
+func (e *Engine) TestNotifications(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, policy NotificationPolicy, folderTitle string, includeFolder bool) (*data.Frame, []Notification, error) {
+	if rule.Type() == models.RuleTypeRecording {
+		return nil, nil, fmt.Errorf("%w: recording rules do not send notifications", ErrInvalidInputData)
+	}
+	if policy.Route == nil {
+		return nil, nil, fmt.Errorf("%w: notification policy must have a root route", ErrInvalidInputData)
+	}
+	simulator := newNotificationSimulator(policy)
+	extraLabels := state.GetRuleExtraLabels(logger.FromContext(ctx), rule, folderTitle, includeFolder)
+	frame, err := e.test(ctx, user, rule, from, to, simulator, extraLabels)
+	if err != nil {
+		return nil, nil, err
+	}
+	return frame, simulator.finish(to), nil
+}
+
+func (e *Engine) test(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time, simulator *notificationSimulator, extraLabels data.Labels) (*data.Frame, error) {
 	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
 	logger := logger.FromContext(ctx)
 
-	if !from.Before(to) {
-		return nil, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
-	}
-	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
-		return nil, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	length, err := evaluationsCount(rule, from, to)
+	if err != nil {
+		return nil, err
 	}
-	length := int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds)
 
 	stateManager := e.createStateManager()
 
@@ -99,6 +135,9 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 		}
 		states := stateManager.ProcessEvalResults(ruleCtx, currentTime, rule, results, nil, nil)
 		tsField.Set(idx, currentTime)
+		if simulator != nil {
+			simulator.process(currentTime, states, extraLabels)
+		}
 		for _, s := range states {
 			field, ok := valueFields[s.CacheID]
 			if !ok {
@@ -130,7 +169,60 @@ func (e *Engine) Test(ctx context.Context, user identity.Requester, rule *models
 	return result, nil
 }
 
+// evaluationsCount returns the number of evaluations of the rule in the range [from, to).
+func evaluationsCount(rule *models.AlertRule, from, to time.Time) (int, error) {
+	if !from.Before(to) {
+		return 0, fmt.Errorf("%w: invalid interval of the backtesting [%d,%d]", ErrInvalidInputData, from.Unix(), to.Unix())
+	}
+	if to.Sub(from).Seconds() < float64(rule.IntervalSeconds) {
+		return 0, fmt.Errorf("%w: interval of the backtesting [%d,%d] is less than evaluation interval [%ds]", ErrInvalidInputData, from.Unix(), to.Unix(), rule.IntervalSeconds)
+	}
+	return int(to.Sub(from).Seconds()) / int(rule.IntervalSeconds), nil
+}
+
 func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, reader eval.AlertingResultsReader) (backtestingEvaluator, error) {
+	frame, err := dataQueryFrame(condition)
+	if err != nil {
+		return nil, err
+	}
+	if frame != nil {
+		return newDataEvaluator(condition.Condition, frame)
+	}
+
+	evaluator, err := evalFactory.Create(eval.NewContextWithPreviousResults(ctx, user, reader), condition)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &queryEvaluator{
+		eval:  evaluator,
+		refID: condition.Condition,
+	}, nil
+}
+
+func newBacktestingRecordingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingRecordingEvaluator, error) {
+	frame, err := dataQueryFrame(condition)
+	if err != nil {
+		return nil, err
+	}
+	if frame != nil {
+		return newDataEvaluator(condition.Condition, frame)
+	}
+
+	evaluator, err := evalFactory.Create(eval.NewContext(ctx, user), condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &queryEvaluator{
+		eval:  evaluator,
+		refID: condition.Condition,
+	}, nil
+}
+
+// dataQueryFrame returns the frame of the data query of the condition, or nil if the condition does not have a data query.
+func dataQueryFrame(condition models.Condition) (*data.Frame, error) {
 	for _, q := range condition.Data {
 		if q.DatasourceUID == "__data__" || q.QueryType == "__data__" {
 			if len(condition.Data) != 1 {
@@ -152,19 +244,10 @@ func newBacktestingEvaluator(ctx context.Context, evalFactory eval.EvaluatorFact
 			if model.DataFrame == nil {
 				return nil, errors.New("the data field must not be empty")
 			}
-			return newDataEvaluator(condition.Condition, model.DataFrame)
+			return model.DataFrame, nil
 		}
 	}
-
-	evaluator, err := evalFactory.Create(eval.NewContextWithPreviousResults(ctx, user, reader), condition)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return &queryEvaluator{
-		eval: evaluator,
-	}, nil
+	return nil, nil
 }
 
 // NoopImageService is a no-op image service.
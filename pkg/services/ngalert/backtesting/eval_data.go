@@ -37,17 +37,26 @@ func newDataEvaluator(refID string, frame *data.Frame) (*dataEvaluator, error) {
 	}, nil
 }
 
-func (d *dataEvaluator) Eval(_ context.Context, from time.Time, interval time.Duration, evaluations int, callback callbackFunc) error {
+// resample aligns the series of the data with the evaluations in the range [from, from + evaluations * interval).
+func (d *dataEvaluator) resample(from time.Time, interval time.Duration, evaluations int) ([]mathexp.Series, error) {
 	var resampled = make([]mathexp.Series, 0, len(d.data))
 	to := from.Add(time.Duration(evaluations) * interval)
 	for _, s := range d.data {
 		// making sure the input data frame is aligned with the interval
 		r, err := s.Resample(d.refID, interval, d.downsampleFunction, d.upsampleFunction, from, to.Add(-interval)) // we want to query [from,to)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		resampled = append(resampled, r)
 	}
+	return resampled, nil
+}
+
+func (d *dataEvaluator) Eval(_ context.Context, from time.Time, interval time.Duration, evaluations int, callback callbackFunc) error {
+	resampled, err := d.resample(from, interval, evaluations)
+	if err != nil {
+		return err
+	}
 
 	for i := 0; i < evaluations; i++ {
 		result := make([]eval.Result, 0, len(resampled))
@@ -92,3 +101,28 @@ func (d *dataEvaluator) Eval(_ context.Context, from time.Time, interval time.Du
 	}
 	return nil
 }
+
+func (d *dataEvaluator) EvalRecording(_ context.Context, from time.Time, interval time.Duration, evaluations int, callback recordingCallbackFunc) error {
+	resampled, err := d.resample(from, interval, evaluations)
+	if err != nil {
+		return err
+	}
+
+	for i, now := 0, from; i < evaluations; i, now = i+1, now.Add(interval) {
+		var frames data.Frames
+		for _, series := range resampled {
+			value := series.GetValue(i)
+			if value == nil {
+				continue
+			}
+			n := mathexp.NewNumber(d.refID, series.GetLabels())
+			n.SetValue(value)
+			frames = append(frames, n.AsDataFrame())
+		}
+		err := callback(i, now, frames, nil)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
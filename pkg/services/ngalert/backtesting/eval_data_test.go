@@ -291,3 +291,30 @@ func TestDataEvaluator_Eval(t *testing.T) {
 		require.ErrorIs(t, err, expectedError)
 	})
 }
+
+func TestDataEvaluator_EvalRecording(t *testing.T) {
+	from := time.Unix(0, 0)
+	frame := data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{from, from.Add(time.Second), from.Add(2 * time.Second)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{util.Pointer(1.0), nil, util.Pointer(3.0)}),
+	)
+	evaluator, err := newDataEvaluator("A", frame)
+	require.NoError(t, err)
+
+	var values []*float64
+	err = evaluator.EvalRecording(context.Background(), from, time.Second, 3, func(idx int, now time.Time, frames data.Frames, err error) error {
+		require.NoError(t, err)
+		require.Equal(t, from.Add(time.Duration(idx)*time.Second), now)
+		if frames == nil {
+			values = append(values, nil)
+			return nil
+		}
+		require.Len(t, frames, 1)
+		require.Equal(t, data.FrameTypeNumericMulti, frames[0].Meta.Type)
+		require.Equal(t, data.Labels{"host": "a"}, frames[0].Fields[0].Labels)
+		values = append(values, frames[0].Fields[0].At(0).(*float64))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []*float64{util.Pointer(1.0), nil, util.Pointer(3.0)}, values)
+}
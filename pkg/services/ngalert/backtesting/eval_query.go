@@ -4,12 +4,15 @@ import (
 	"context"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 )
 
 // QueryEvaluator is evaluator of regular alert rule queries
 type queryEvaluator struct {
-	eval eval.ConditionEvaluator
+	eval  eval.ConditionEvaluator
+	refID string
 }
 
 func (d *queryEvaluator) Eval(ctx context.Context, from time.Time, interval time.Duration, evaluations int, callback callbackFunc) error {
@@ -25,3 +28,25 @@ func (d *queryEvaluator) Eval(ctx context.Context, from time.Time, interval time
 	}
 	return nil
 }
+
+func (d *queryEvaluator) EvalRecording(ctx context.Context, from time.Time, interval time.Duration, evaluations int, callback recordingCallbackFunc) error {
+	for idx, now := 0, from; idx < evaluations; idx, now = idx+1, now.Add(interval) {
+		resp, err := d.eval.EvaluateRaw(ctx, now)
+		if err != nil {
+			return err
+		}
+		var frames data.Frames
+		// errors of the queries are reported per evaluation, as the scheduler does not write anything for them
+		err = eval.FindConditionError(resp, d.refID)
+		if err == nil && resp != nil {
+			if r, ok := resp.Responses[d.refID]; ok && !eval.IsNoData(r) {
+				frames = r.Frames
+			}
+		}
+		err = callback(idx, now, frames, err)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
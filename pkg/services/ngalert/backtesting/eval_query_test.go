@@ -7,6 +7,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -92,3 +94,52 @@ func TestQueryEvaluator_Eval(t *testing.T) {
 		})
 	})
 }
+
+func TestQueryEvaluator_EvalRecording(t *testing.T) {
+	ctx := context.Background()
+	from := time.Unix(0, 0)
+	frame := data.NewFrame("", data.NewField("A", data.Labels{"host": "a"}, []*float64{nil}))
+	queryErr := errors.New("query failed")
+
+	m := &eval_mocks.ConditionEvaluatorMock{}
+	m.EXPECT().EvaluateRaw(mock.Anything, from).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{"A": {Frames: data.Frames{frame}}},
+	}, nil)
+	m.EXPECT().EvaluateRaw(mock.Anything, from.Add(time.Second)).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{"A": {Frames: data.Frames{}}},
+	}, nil)
+	m.EXPECT().EvaluateRaw(mock.Anything, from.Add(2*time.Second)).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{"A": {Error: queryErr}},
+	}, nil)
+	evaluator := queryEvaluator{
+		eval:  m,
+		refID: "A",
+	}
+
+	var frames []data.Frames
+	var errs []error
+	err := evaluator.EvalRecording(ctx, from, time.Second, 3, func(idx int, now time.Time, f data.Frames, err error) error {
+		frames = append(frames, f)
+		errs = append(errs, err)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []data.Frames{{frame}, nil, nil}, frames)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.ErrorIs(t, errs[2], queryErr)
+
+	t.Run("should stop evaluation if evaluation fails", func(t *testing.T) {
+		m := &eval_mocks.ConditionEvaluatorMock{}
+		m.EXPECT().EvaluateRaw(mock.Anything, mock.Anything).Return(nil, queryErr)
+		evaluator := queryEvaluator{
+			eval:  m,
+			refID: "A",
+		}
+		err := evaluator.EvalRecording(ctx, from, time.Second, 3, func(idx int, now time.Time, f data.Frames, err error) error {
+			return nil
+		})
+		require.ErrorIs(t, err, queryErr)
+		m.AssertNumberOfCalls(t, "EvaluateRaw", 1)
+	})
+}
@@ -0,0 +1,344 @@
+package backtesting
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// NotificationPolicy is the routing configuration that the notifications of the backtesting are simulated with.
+type NotificationPolicy struct {
+	// Route is the root of the notification policy tree.
+	Route *config.Route
+	// TimeIntervals are the time intervals by name, that routes refer to as mute or active time intervals.
+	TimeIntervals map[string][]timeinterval.TimeInterval
+}
+
+// NotificationPolicyFromConfig creates a NotificationPolicy from the routes and time intervals of an Alertmanager configuration.
+func NotificationPolicyFromConfig(cfg *definitions.PostableApiAlertingConfig) NotificationPolicy {
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.MuteTimeIntervals)+len(cfg.TimeIntervals))
+	for _, ti := range cfg.MuteTimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	for _, ti := range cfg.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	return NotificationPolicy{
+		Route:         cfg.Route.AsAMRoute(),
+		TimeIntervals: intervals,
+	}
+}
+
+// Notification is a notification that the alerts of the rule would have triggered.
+type Notification struct {
+	// Time is when the notification would have been sent.
+	Time     time.Time
+	Receiver string
+	// GroupLabels are the labels the alerts of the notification are grouped by.
+	GroupLabels data.Labels
+	Alerts      []NotificationAlert
+	// MutedBy is the mute time interval that the notification was muted by, or the active time intervals
+	// that the notification was outside of. Muted notifications are not sent.
+	MutedBy string
+}
+
+// NotificationAlert is an alert in a notification.
+type NotificationAlert struct {
+	Labels   data.Labels
+	StartsAt time.Time
+	// EndsAt is when the alert was resolved. It is zero if the alert is firing.
+	EndsAt time.Time
+}
+
+// Resolved returns true if the alert is resolved.
+func (a NotificationAlert) Resolved() bool {
+	return !a.EndsAt.IsZero()
+}
+
+// notificationSimulator replays the alerts of the rule through the notification policy tree in the same way as
+// the Alertmanager dispatcher: it groups the alerts per route, flushes the groups after group_wait and then every
+// group_interval, and deduplicates the notifications like the notification log, with resolved notifications enabled.
+type notificationSimulator struct {
+	policy        NotificationPolicy
+	root          *dispatch.Route
+	groups        map[string]*simulatedGroup
+	notifications []Notification
+}
+
+type simulatedAlert struct {
+	labels   model.LabelSet
+	startsAt time.Time
+	endsAt   time.Time
+}
+
+func (a *simulatedAlert) resolvedAt(t time.Time) bool {
+	return !a.endsAt.IsZero() && !a.endsAt.After(t)
+}
+
+type simulatedGroup struct {
+	key    string
+	route  *dispatch.Route
+	labels model.LabelSet
+	alerts map[model.Fingerprint]*simulatedAlert
+	// next is the time of the next flush.
+	next time.Time
+
+	// the alerts and the time of the last notification that was sent
+	notified     bool
+	lastFiring   map[model.Fingerprint]struct{}
+	lastResolved map[model.Fingerprint]struct{}
+	lastTime     time.Time
+}
+
+func newNotificationSimulator(policy NotificationPolicy) *notificationSimulator {
+	return &notificationSimulator{
+		policy: policy,
+		root:   dispatch.NewRoute(policy.Route, nil),
+		groups: make(map[string]*simulatedGroup),
+	}
+}
+
+// process flushes the groups that are due before now and then updates the alerts with the state transitions of
+// the evaluation at now. extraLabels are added to the labels of the states, as the scheduler does.
+func (s *notificationSimulator) process(now time.Time, transitions state.StateTransitions, extraLabels data.Labels) {
+	s.flushUntil(now)
+
+	for _, tr := range transitions {
+		firing := isFiring(tr.State.State)
+		if !firing && (tr.State.State != eval.Normal || !isFiring(tr.PreviousState)) {
+			continue
+		}
+		lset := alertLabels(tr, extraLabels)
+		fp := lset.Fingerprint()
+
+		for _, route := range s.root.Match(lset) {
+			groupLabels := getGroupLabels(route, lset)
+			key := route.ID() + ":" + groupLabels.String()
+			g, ok := s.groups[key]
+			if !firing {
+				// the alert is resolved
+				if !ok {
+					continue
+				}
+				if a, ok := g.alerts[fp]; ok && a.endsAt.IsZero() {
+					a.endsAt = now
+				}
+				continue
+			}
+			if !ok {
+				g = &simulatedGroup{
+					key:    key,
+					route:  route,
+					labels: groupLabels,
+					alerts: make(map[model.Fingerprint]*simulatedAlert),
+					next:   now.Add(route.RouteOpts.GroupWait),
+				}
+				s.groups[key] = g
+			}
+			if a, ok := g.alerts[fp]; !ok || !a.endsAt.IsZero() {
+				g.alerts[fp] = &simulatedAlert{labels: lset, startsAt: tr.State.StartsAt}
+			}
+		}
+	}
+}
+
+// flushUntil flushes the groups in the order of their flush time, until there is no group to flush before t.
+func (s *notificationSimulator) flushUntil(t time.Time) {
+	for {
+		var next *simulatedGroup
+		for _, g := range s.groups {
+			if !g.next.Before(t) {
+				continue
+			}
+			if next == nil || g.next.Before(next.next) || (g.next.Equal(next.next) && g.key < next.key) {
+				next = g
+			}
+		}
+		if next == nil {
+			return
+		}
+		s.flush(next)
+	}
+}
+
+func (s *notificationSimulator) flush(g *simulatedGroup) {
+	now := g.next
+	var firing, resolved []*simulatedAlert
+	for _, a := range g.alerts {
+		if a.resolvedAt(now) {
+			resolved = append(resolved, a)
+		} else {
+			firing = append(firing, a)
+		}
+	}
+
+	if g.needsUpdate(firing, resolved, now) {
+		n := Notification{
+			Time:        now,
+			Receiver:    g.route.RouteOpts.Receiver,
+			GroupLabels: toDataLabels(g.labels),
+			Alerts:      make([]NotificationAlert, 0, len(firing)+len(resolved)),
+			MutedBy:     s.mutedBy(g.route, now),
+		}
+		for _, a := range append(firing, resolved...) {
+			na := NotificationAlert{Labels: toDataLabels(a.labels), StartsAt: a.startsAt}
+			if a.resolvedAt(now) {
+				na.EndsAt = a.endsAt
+			}
+			n.Alerts = append(n.Alerts, na)
+		}
+		sort.Slice(n.Alerts, func(i, j int) bool {
+			return n.Alerts[i].Labels.String() < n.Alerts[j].Labels.String()
+		})
+		s.notifications = append(s.notifications, n)
+
+		if n.MutedBy == "" {
+			g.notified = true
+			g.lastFiring = fingerprints(firing)
+			g.lastResolved = fingerprints(resolved)
+			g.lastTime = now
+		}
+	}
+
+	// resolved alerts are removed after the flush, and the group is removed when it is empty
+	for _, a := range resolved {
+		delete(g.alerts, a.labels.Fingerprint())
+	}
+	if len(g.alerts) == 0 {
+		delete(s.groups, g.key)
+		return
+	}
+	interval := g.route.RouteOpts.GroupInterval
+	if interval <= 0 {
+		interval = dispatch.DefaultRouteOpts.GroupInterval
+	}
+	g.next = now.Add(interval)
+}
+
+// needsUpdate returns true if a notification has to be sent for the alerts, following the rules of the
+// deduplication stage of the Alertmanager notification pipeline.
+func (g *simulatedGroup) needsUpdate(firing, resolved []*simulatedAlert, now time.Time) bool {
+	if !g.notified {
+		return len(firing) > 0
+	}
+	if !isSubset(g.lastFiring, firing) {
+		return true
+	}
+	if len(firing) == 0 {
+		// only notify about the resolved alerts if the receiver knows that they were firing
+		return len(g.lastFiring) > 0
+	}
+	if !isSubset(g.lastResolved, resolved) {
+		return true
+	}
+	return g.lastTime.Before(now.Add(-g.route.RouteOpts.RepeatInterval))
+}
+
+// mutedBy returns the mute time interval of the route that contains t, or the active time intervals of the
+// route if none of them contains t. It returns an empty string if notifications are not muted at t.
+func (s *notificationSimulator) mutedBy(route *dispatch.Route, t time.Time) string {
+	for _, name := range route.RouteOpts.MuteTimeIntervals {
+		if s.intervalContains(name, t) {
+			return name
+		}
+	}
+	if len(route.RouteOpts.ActiveTimeIntervals) == 0 {
+		return ""
+	}
+	for _, name := range route.RouteOpts.ActiveTimeIntervals {
+		if s.intervalContains(name, t) {
+			return ""
+		}
+	}
+	return strings.Join(route.RouteOpts.ActiveTimeIntervals, ", ")
+}
+
+func (s *notificationSimulator) intervalContains(name string, t time.Time) bool {
+	for _, ti := range s.policy.TimeIntervals[name] {
+		if ti.ContainsTime(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// finish flushes the groups that are due before the end of the backtesting and returns all notifications.
+func (s *notificationSimulator) finish(end time.Time) []Notification {
+	s.flushUntil(end)
+	return s.notifications
+}
+
+// isFiring returns true if alerts in the state are sent to the Alertmanager as firing alerts.
+func isFiring(s eval.State) bool {
+	return s == eval.Alerting || s == eval.NoData || s == eval.Error
+}
+
+// alertLabels returns the labels of the alert that the scheduler sends to the Alertmanager for the transition.
+func alertLabels(tr state.StateTransition, extraLabels data.Labels) model.LabelSet {
+	s := *tr.State
+	if !isFiring(s.State) {
+		// resolved alerts must have the labels of the alert they resolve, which differ for NoData and Error alerts
+		s.State = tr.PreviousState
+	}
+	s.Labels = s.Labels.Copy()
+	for k, v := range extraLabels {
+		s.Labels[k] = v
+	}
+	tr.State = &s
+	alert := state.StateToPostableAlert(tr, nil)
+
+	lset := make(model.LabelSet, len(alert.Labels))
+	for k, v := range alert.Labels {
+		lset[model.LabelName(k)] = model.LabelValue(v)
+	}
+	return lset
+}
+
+func getGroupLabels(route *dispatch.Route, lset model.LabelSet) model.LabelSet {
+	groupLabels := model.LabelSet{}
+	for ln, lv := range lset {
+		if _, ok := route.RouteOpts.GroupBy[ln]; ok || route.RouteOpts.GroupByAll {
+			groupLabels[ln] = lv
+		}
+	}
+	return groupLabels
+}
+
+func isSubset(set map[model.Fingerprint]struct{}, alerts []*simulatedAlert) bool {
+	for _, a := range alerts {
+		if _, ok := set[a.labels.Fingerprint()]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func fingerprints(alerts []*simulatedAlert) map[model.Fingerprint]struct{} {
+	result := make(map[model.Fingerprint]struct{}, len(alerts))
+	for _, a := range alerts {
+		result[a.labels.Fingerprint()] = struct{}{}
+	}
+	return result
+}
+
+// toDataLabels converts the labels without the private labels, which are not visible in notifications.
+func toDataLabels(lset model.LabelSet) data.Labels {
+	result := make(data.Labels, len(lset))
+	for k, v := range lset {
+		if strings.HasPrefix(string(k), "__") {
+			continue
+		}
+		result[string(k)] = string(v)
+	}
+	return result
+}
@@ -0,0 +1,220 @@
+package backtesting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestNotificationSimulator(t *testing.T) {
+	from := time.Unix(0, 0).UTC()
+	groupWait := model.Duration(30 * time.Second)
+	groupInterval := model.Duration(5 * time.Minute)
+
+	// firing returns the transitions of alerts that fire from the start and are resolved at resolveAt
+	firing := func(resolveAt time.Duration, lbls ...data.Labels) func(now time.Time) state.StateTransitions {
+		return func(now time.Time) state.StateTransitions {
+			result := make(state.StateTransitions, 0, len(lbls))
+			for _, l := range lbls {
+				s := &state.State{Labels: l, State: eval.Alerting, StartsAt: from}
+				previous := eval.Alerting
+				if now.Sub(from) >= resolveAt {
+					s.State = eval.Normal
+					if now.Sub(from) > resolveAt {
+						previous = eval.Normal
+					}
+				}
+				result = append(result, state.StateTransition{State: s, PreviousState: previous})
+			}
+			return result
+		}
+	}
+
+	simulate := func(policy NotificationPolicy, transitions func(now time.Time) state.StateTransitions) []Notification {
+		s := newNotificationSimulator(policy)
+		end := from.Add(20 * time.Minute)
+		for now := from; now.Before(end); now = now.Add(time.Minute) {
+			s.process(now, transitions(now), data.Labels{"alertname": "test"})
+		}
+		return s.finish(end)
+	}
+
+	t.Run("sends firing and resolved notifications after group wait and group interval", func(t *testing.T) {
+		policy := NotificationPolicy{Route: &config.Route{
+			Receiver:      "default",
+			GroupByStr:    []string{"alertname"},
+			GroupBy:       []model.LabelName{"alertname"},
+			GroupWait:     &groupWait,
+			GroupInterval: &groupInterval,
+		}}
+		notifications := simulate(policy, firing(10*time.Minute, data.Labels{"team": "a"}))
+
+		require.Equal(t, []Notification{
+			{
+				Time:        from.Add(30 * time.Second),
+				Receiver:    "default",
+				GroupLabels: data.Labels{"alertname": "test"},
+				Alerts: []NotificationAlert{
+					{Labels: data.Labels{"alertname": "test", "team": "a"}, StartsAt: from},
+				},
+			},
+			{
+				Time:        from.Add(10*time.Minute + 30*time.Second),
+				Receiver:    "default",
+				GroupLabels: data.Labels{"alertname": "test"},
+				Alerts: []NotificationAlert{
+					{Labels: data.Labels{"alertname": "test", "team": "a"}, StartsAt: from, EndsAt: from.Add(10 * time.Minute)},
+				},
+			},
+		}, notifications)
+		require.True(t, notifications[1].Alerts[0].Resolved())
+	})
+
+	t.Run("routes alerts to the matching routes", func(t *testing.T) {
+		matcher, err := labels.NewMatcher(labels.MatchEqual, "team", "b")
+		require.NoError(t, err)
+		policy := NotificationPolicy{Route: &config.Route{
+			Receiver:      "default",
+			GroupWait:     &groupWait,
+			GroupInterval: &groupInterval,
+			Routes: []*config.Route{
+				{
+					Receiver:   "team-b",
+					GroupByStr: []string{"team"},
+					GroupBy:    []model.LabelName{"team"},
+					Matchers:   config.Matchers{matcher},
+				},
+			},
+		}}
+		notifications := simulate(policy, firing(time.Hour, data.Labels{"team": "a"}, data.Labels{"team": "b"}))
+
+		require.Len(t, notifications, 2)
+		receivers := map[string]data.Labels{}
+		for _, n := range notifications {
+			require.Equal(t, from.Add(30*time.Second), n.Time)
+			require.Len(t, n.Alerts, 1)
+			receivers[n.Receiver] = n.GroupLabels
+		}
+		require.Equal(t, map[string]data.Labels{
+			"default": {},
+			"team-b":  {"team": "b"},
+		}, receivers)
+	})
+
+	t.Run("records muted notifications and sends them when the mute timing ends", func(t *testing.T) {
+		policy := NotificationPolicy{
+			Route: &config.Route{
+				Receiver:          "default",
+				GroupWait:         &groupWait,
+				GroupInterval:     &groupInterval,
+				MuteTimeIntervals: []string{"maintenance"},
+			},
+			TimeIntervals: map[string][]timeinterval.TimeInterval{
+				"maintenance": {{Times: []timeinterval.TimeRange{{StartMinute: 0, EndMinute: 10}}}},
+			},
+		}
+		notifications := simulate(policy, firing(time.Hour, data.Labels{"team": "a"}))
+
+		require.Len(t, notifications, 3)
+		require.Equal(t, "maintenance", notifications[0].MutedBy)
+		require.Equal(t, from.Add(30*time.Second), notifications[0].Time)
+		require.Equal(t, "maintenance", notifications[1].MutedBy)
+		require.Empty(t, notifications[2].MutedBy)
+		require.Equal(t, from.Add(10*time.Minute+30*time.Second), notifications[2].Time)
+	})
+
+	t.Run("uses the labels of no data alerts", func(t *testing.T) {
+		policy := NotificationPolicy{Route: &config.Route{Receiver: "default", GroupWait: &groupWait}}
+		notifications := simulate(policy, func(now time.Time) state.StateTransitions {
+			return state.StateTransitions{
+				{State: &state.State{Labels: data.Labels{"team": "a"}, State: eval.NoData, StartsAt: from}, PreviousState: eval.NoData},
+			}
+		})
+
+		require.Len(t, notifications, 1)
+		require.Equal(t, "DatasourceNoData", notifications[0].Alerts[0].Labels["alertname"])
+	})
+}
+
+func TestEngineTestNotifications(t *testing.T) {
+	evaluator := &fakeBacktestingEvaluator{
+		evalCallback: func(now time.Time) (eval.Results, error) {
+			return eval.Results{}, nil
+		},
+	}
+	backtestingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition, r eval.AlertingResultsReader) (backtestingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingEvaluatorFactory = newBacktestingEvaluator
+	})
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithInterval(time.Minute), gen.WithTitle("test-rule"), gen.WithNoNotificationSettings()).GenerateRef()
+	from := time.Unix(0, 0)
+	lbls := data.Labels{"team": "a"}
+	engine := &Engine{
+		createStateManager: func() stateManager {
+			return &fakeStateManager{stateCallback: func(now time.Time) []state.StateTransition {
+				return []state.StateTransition{
+					{State: &state.State{CacheID: lbls.Fingerprint(), Labels: lbls, State: eval.Alerting, StartsAt: from}, PreviousState: eval.Alerting},
+				}
+			}}
+		},
+	}
+
+	t.Run("returns the states and the notifications", func(t *testing.T) {
+		frame, notifications, err := engine.TestNotifications(context.Background(), nil, rule, from, from.Add(10*time.Minute), NotificationPolicy{
+			Route: &config.Route{Receiver: "default"},
+		}, "test-folder", true)
+		require.NoError(t, err)
+		require.Len(t, frame.Fields, 2)
+		require.Len(t, notifications, 1)
+		require.Equal(t, "default", notifications[0].Receiver)
+		require.Equal(t, "test-rule", notifications[0].Alerts[0].Labels["alertname"])
+		require.Equal(t, "test-folder", notifications[0].Alerts[0].Labels[models.FolderTitleLabel])
+		require.NotContains(t, notifications[0].Alerts[0].Labels, "__alert_rule_uid__")
+	})
+
+	t.Run("routes on the folder of the rule", func(t *testing.T) {
+		matcher, err := labels.NewMatcher(labels.MatchEqual, models.FolderTitleLabel, "test-folder")
+		require.NoError(t, err)
+		_, notifications, err := engine.TestNotifications(context.Background(), nil, rule, from, from.Add(10*time.Minute), NotificationPolicy{
+			Route: &config.Route{
+				Receiver: "default",
+				Routes: []*config.Route{{
+					Receiver: "folder",
+					Matchers: config.Matchers{matcher},
+				}},
+			},
+		}, "test-folder", true)
+		require.NoError(t, err)
+		require.Len(t, notifications, 1)
+		require.Equal(t, "folder", notifications[0].Receiver)
+	})
+
+	t.Run("does not add the folder if it is disabled", func(t *testing.T) {
+		_, notifications, err := engine.TestNotifications(context.Background(), nil, rule, from, from.Add(10*time.Minute), NotificationPolicy{
+			Route: &config.Route{Receiver: "default"},
+		}, "test-folder", false)
+		require.NoError(t, err)
+		require.NotContains(t, notifications[0].Alerts[0].Labels, models.FolderTitleLabel)
+	})
+
+	t.Run("fails without a root route", func(t *testing.T) {
+		_, _, err := engine.TestNotifications(context.Background(), nil, rule, from, from.Add(10*time.Minute), NotificationPolicy{}, "", true)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
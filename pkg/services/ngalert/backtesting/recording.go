@@ -0,0 +1,107 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+// testRecording evaluates the recording rule over the range and returns a wide frame with a field for every series that
+// the writer would have written. The values of the series are nil for the evaluations that would not have written them.
+// Evaluations that would have failed are reported as notices of the frame, and the cardinality of the output as stats.
+func (e *Engine) testRecording(ctx context.Context, user identity.Requester, rule *models.AlertRule, from, to time.Time) (*data.Frame, error) {
+	ruleCtx := models.WithRuleKey(ctx, rule.GetKey())
+	logger := logger.FromContext(ctx)
+
+	length, err := evaluationsCount(rule, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	evaluator, err := backtestingRecordingEvaluatorFactory(ruleCtx, e.evalFactory, user, rule.GetEvalCondition().WithSource("backtesting"))
+	if err != nil {
+		return nil, errors.Join(ErrInvalidInputData, err)
+	}
+
+	logger.Info("Start testing recording rule", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluations", length)
+
+	start := time.Now()
+
+	tsField := data.NewField("Time", nil, make([]time.Time, length))
+	valueFields := make(map[data.Fingerprint]*data.Field)
+	var failures []string
+	failureCounts := make(map[string]int)
+	maxSeries := 0
+
+	err = evaluator.EvalRecording(ruleCtx, from, time.Duration(rule.IntervalSeconds)*time.Second, length, func(idx int, currentTime time.Time, frames data.Frames, err error) error {
+		if idx >= length {
+			logger.Info("Unexpected evaluation. Skipping", "from", from, "to", to, "interval", rule.IntervalSeconds, "evaluationTime", currentTime, "evaluationIndex", idx, "expectedEvaluations", length)
+			return nil
+		}
+		tsField.Set(idx, currentTime)
+
+		var points []writer.Point
+		if err == nil && frames != nil {
+			points, err = writer.PointsFromFrames(rule.Record.Metric, currentTime, frames, rule.Labels)
+		}
+		if err != nil {
+			msg := err.Error()
+			if failureCounts[msg] == 0 {
+				failures = append(failures, msg)
+			}
+			failureCounts[msg]++
+			return nil
+		}
+
+		maxSeries = max(maxSeries, len(points))
+		for _, p := range points {
+			labels := data.Labels(p.Labels)
+			fp := labels.Fingerprint()
+			field, ok := valueFields[fp]
+			if !ok {
+				field = data.NewField(p.Name, labels, make([]*float64, length))
+				valueFields[fp] = field
+			}
+			v := p.Metric.V
+			field.Set(idx, &v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]*data.Field, 0, len(valueFields)+1)
+	for _, f := range valueFields {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Labels.String() < fields[j].Labels.String()
+	})
+	fields = append([]*data.Field{tsField}, fields...)
+
+	meta := &data.FrameMeta{
+		Stats: []data.QueryStat{
+			{FieldConfig: data.FieldConfig{DisplayName: "Series"}, Value: float64(len(valueFields))},
+			{FieldConfig: data.FieldConfig{DisplayName: "Max series per evaluation"}, Value: float64(maxSeries)},
+		},
+	}
+	for _, msg := range failures {
+		meta.Notices = append(meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("%d of %d evaluations would not have written any series: %s", failureCounts[msg], length, msg),
+		})
+	}
+	result := data.NewFrame("Testing results", fields...).SetMeta(meta)
+
+	logger.Info("Rule testing finished successfully", "duration", time.Since(start))
+	return result, nil
+}
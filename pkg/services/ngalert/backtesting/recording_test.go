@@ -0,0 +1,102 @@
+package backtesting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestEngineTestRecording(t *testing.T) {
+	number := func(labels data.Labels, v float64) *data.Frame {
+		n := mathexp.NewNumber("A", labels)
+		n.SetValue(util.Pointer(v))
+		return n.AsDataFrame()
+	}
+	queryErr := errors.New("query failed")
+
+	evaluator := &fakeBacktestingRecordingEvaluator{
+		evalCallback: func(idx int) (data.Frames, error) {
+			switch idx {
+			case 0:
+				return data.Frames{number(data.Labels{"host": "a"}, 1)}, nil
+			case 1:
+				return data.Frames{number(data.Labels{"host": "a"}, 2), number(data.Labels{"host": "b"}, 3)}, nil
+			case 2:
+				return nil, nil
+			default:
+				return nil, queryErr
+			}
+		},
+	}
+	backtestingRecordingEvaluatorFactory = func(ctx context.Context, evalFactory eval.EvaluatorFactory, user identity.Requester, condition models.Condition) (backtestingRecordingEvaluator, error) {
+		return evaluator, nil
+	}
+	t.Cleanup(func() {
+		backtestingRecordingEvaluatorFactory = newBacktestingRecordingEvaluator
+	})
+
+	gen := models.RuleGen
+	rule := gen.With(
+		gen.WithInterval(time.Minute),
+		gen.WithAllRecordingRules(),
+		gen.WithMetric("test_metric"),
+		gen.WithLabels(data.Labels{"team": "x"}),
+	).GenerateRef()
+	engine := &Engine{}
+	from := time.Unix(0, 0)
+
+	frame, err := engine.Test(context.Background(), nil, rule, from, from.Add(5*time.Minute))
+	require.NoError(t, err)
+
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "Time", frame.Fields[0].Name)
+	for i, host := range []string{"a", "b"} {
+		field := frame.Fields[i+1]
+		require.Equal(t, "test_metric", field.Name)
+		require.Equal(t, data.Labels{"host": host, "team": "x"}, field.Labels)
+	}
+	require.Equal(t, []*float64{util.Pointer(1.0), util.Pointer(2.0), nil, nil, nil}, fieldValues(frame.Fields[1]))
+	require.Equal(t, []*float64{nil, util.Pointer(3.0), nil, nil, nil}, fieldValues(frame.Fields[2]))
+
+	require.Equal(t, float64(2), frame.Meta.Stats[0].Value)
+	require.Equal(t, float64(2), frame.Meta.Stats[1].Value)
+	require.Len(t, frame.Meta.Notices, 1)
+	require.Equal(t, "2 of 5 evaluations would not have written any series: query failed", frame.Meta.Notices[0].Text)
+
+	t.Run("should not simulate notifications", func(t *testing.T) {
+		_, _, err := engine.TestNotifications(context.Background(), nil, rule, from, from.Add(5*time.Minute), NotificationPolicy{}, "", true)
+		require.ErrorIs(t, err, ErrInvalidInputData)
+	})
+}
+
+func fieldValues(f *data.Field) []*float64 {
+	result := make([]*float64, f.Len())
+	for i := range result {
+		result[i] = f.At(i).(*float64)
+	}
+	return result
+}
+
+type fakeBacktestingRecordingEvaluator struct {
+	evalCallback func(idx int) (data.Frames, error)
+}
+
+func (f *fakeBacktestingRecordingEvaluator) EvalRecording(_ context.Context, from time.Time, interval time.Duration, evaluations int, callback recordingCallbackFunc) error {
+	for idx, now := 0, from; idx < evaluations; idx, now = idx+1, now.Add(interval) {
+		frames, evalErr := f.evalCallback(idx)
+		if err := callback(idx, now, frames, evalErr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package eval
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -19,6 +20,8 @@ type EvaluationContext struct {
 	Ctx                   context.Context
 	User                  identity.Requester
 	AlertingResultsReader AlertingResultsReader
+	// EvaluationTimeout overrides the configured evaluation timeout if it is greater than zero.
+	EvaluationTimeout time.Duration
 }
 
 func NewContext(ctx context.Context, user identity.Requester) EvaluationContext {
@@ -35,3 +38,10 @@ func NewContextWithPreviousResults(ctx context.Context, user identity.Requester,
 		AlertingResultsReader: reader,
 	}
 }
+
+// WithEvaluationTimeout returns a copy of the context that evaluates the condition with the given timeout. A timeout
+// that is not greater than zero keeps the configured evaluation timeout.
+func (c EvaluationContext) WithEvaluationTimeout(timeout time.Duration) EvaluationContext {
+	c.EvaluationTimeout = timeout
+	return c
+}
@@ -27,6 +27,10 @@ import (
 
 var logger = log.New("ngalert.eval")
 
+// ErrEvaluationTimeout is returned when the queries and expressions of the condition are not executed within the
+// evaluation timeout.
+var ErrEvaluationTimeout = errors.New("evaluation timed out")
+
 type EvaluatorFactory interface {
 	// Create builds an evaluator pipeline ready to evaluate a rule's query
 	Create(ctx EvaluationContext, condition models.Condition) (ConditionEvaluator, error)
@@ -78,6 +82,9 @@ func (r *conditionEvaluator) EvaluateRaw(ctx context.Context, now time.Time) (re
 	}
 	logger.FromContext(ctx).Debug("Executing pipeline", "commands", strings.Join(r.pipeline.GetCommandTypes(), ","), "datasources", strings.Join(r.pipeline.GetDatasourceTypes(), ","))
 	result, err := r.expressionService.ExecutePipeline(execCtx, now, r.pipeline)
+	if errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		return nil, fmt.Errorf("%w after %s: %w", ErrEvaluationTimeout, r.evalTimeout, execCtx.Err())
+	}
 
 	// Check if the result of the condition evaluation is too large
 	if err == nil && result != nil && r.evalResultLimit > 0 {
@@ -296,14 +303,19 @@ const (
 	// Error is the eval state for an alert rule condition
 	// that evaluated to Error.
 	Error
+
+	// Suppressed is the state for an alert instance that
+	// is Alerting but is suppressed by a firing alert of
+	// a rule that the alert rule is inhibited by.
+	Suppressed
 )
 
 func (s State) IsValid() bool {
-	return s <= Error
+	return s <= Suppressed
 }
 
 func (s State) String() string {
-	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error"}[s]
+	return [...]string{"Normal", "Alerting", "Pending", "NoData", "Error", "Suppressed"}[s]
 }
 
 func ParseStateString(repr string) (State, error) {
@@ -318,6 +330,8 @@ func ParseStateString(repr string) (State, error) {
 		return NoData, nil
 	case "error":
 		return Error, nil
+	case "suppressed":
+		return Suppressed, nil
 	default:
 		return -1, fmt.Errorf("invalid state: %s", repr)
 	}
@@ -859,10 +873,14 @@ func (e *evaluatorImpl) Create(ctx EvaluationContext, condition models.Condition
 	if err != nil {
 		return nil, err
 	}
-	return e.create(condition, req)
+	timeout := e.evaluationTimeout
+	if ctx.EvaluationTimeout > 0 {
+		timeout = ctx.EvaluationTimeout
+	}
+	return e.create(condition, req, timeout)
 }
 
-func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (ConditionEvaluator, error) {
+func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request, timeout time.Duration) (ConditionEvaluator, error) {
 	pipeline, err := e.expressionService.BuildPipeline(req)
 	if err != nil {
 		return nil, err
@@ -874,7 +892,7 @@ func (e *evaluatorImpl) create(condition models.Condition, req *expr.Request) (C
 				pipeline:          pipeline,
 				expressionService: e.expressionService,
 				condition:         condition,
-				evalTimeout:       e.evaluationTimeout,
+				evalTimeout:       timeout,
 				evalResultLimit:   e.evaluationResultLimit,
 			}, nil
 		}
@@ -1075,6 +1075,25 @@ func TestEvaluateRaw(t *testing.T) {
 
 		_, err := e.EvaluateRaw(context.Background(), time.Now())
 		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorIs(t, err, ErrEvaluationTimeout)
+	})
+
+	t.Run("should not report a timeout if the parent context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		e := conditionEvaluator{
+			expressionService: &fakeExpressionService{
+				hook: func(ctx context.Context, now time.Time, pipeline expr.DataPipeline) (*backend.QueryDataResponse, error) {
+					cancel()
+					return nil, ctx.Err()
+				},
+			},
+			condition:   models.Condition{},
+			evalTimeout: time.Minute,
+		}
+
+		_, err := e.EvaluateRaw(ctx, time.Now())
+		require.ErrorIs(t, err, context.Canceled)
+		require.NotErrorIs(t, err, ErrEvaluationTimeout)
 	})
 }
 
@@ -1341,6 +1360,31 @@ func TestCreate(t *testing.T) {
 
 		require.Equal(t, expectedHeaders, request.Headers)
 	})
+
+	t.Run("should use the evaluation timeout of the context", func(t *testing.T) {
+		q := models.CreateClassicConditionExpression("A", "B", "avg", "gt", 1)
+		condition := models.Condition{
+			Condition: q.RefID,
+			Data:      []models.AlertQuery{q},
+		}
+		factory := evaluatorImpl{
+			evaluationTimeout: 30 * time.Second,
+			expressionService: fakeExpressionService{
+				buildHook: func(req *expr.Request) (expr.DataPipeline, error) {
+					return expr.DataPipeline{fakeNode{refID: q.RefID}}, nil
+				},
+			},
+		}
+		evalCtx := NewContext(context.Background(), &user.SignedInUser{})
+
+		e, err := factory.Create(evalCtx, condition)
+		require.NoError(t, err)
+		require.Equal(t, 30*time.Second, e.(*conditionEvaluator).evalTimeout)
+
+		e, err = factory.Create(evalCtx.WithEvaluationTimeout(5*time.Second), condition)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, e.(*conditionEvaluator).evalTimeout)
+	})
 }
 
 func TestQueryServiceResponse(t *testing.T) {
@@ -32,6 +32,14 @@ type Scheduler struct {
 	Ticker                              *ticker.Metrics
 	EvaluationMissed                    *prometheus.CounterVec
 	SimplifiedEditorRules               *prometheus.GaugeVec
+	ShardingMembers                     prometheus.Gauge
+	ShardingOwnedRules                  prometheus.Gauge
+	ShardingRebalancesTotal             prometheus.Counter
+	ShardingHandedOffRulesTotal         prometheus.Counter
+	EvalTimeouts                        *prometheus.CounterVec
+	EvaluationsQueued                   *prometheus.GaugeVec
+	EvaluationQueueDuration             *prometheus.HistogramVec
+	EvaluationsSkipped                  *prometheus.CounterVec
 }
 
 func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
@@ -179,7 +187,7 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 				Namespace: Namespace,
 				Subsystem: Subsystem,
 				Name:      "schedule_rule_evaluations_missed_total",
-				Help:      "The total number of rule evaluations missed due to a slow rule evaluation.",
+				Help:      "The total number of rule evaluations missed due to a slow rule evaluation or because no evaluation slot was available.",
 			},
 			[]string{"org", "name"},
 		),
@@ -192,5 +200,70 @@ func NewSchedulerMetrics(r prometheus.Registerer) *Scheduler {
 			},
 			[]string{"org", "setting"},
 		),
+		ShardingMembers: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_sharding_members",
+				Help:      "The number of instances that the evaluation of the alert rules is sharded across.",
+			}),
+		ShardingOwnedRules: promauto.With(r).NewGauge(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_sharding_owned_alert_rules",
+				Help:      "The number of alert rules that are evaluated by this instance when the evaluation is sharded.",
+			}),
+		ShardingRebalancesTotal: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_sharding_rebalances_total",
+				Help:      "The total number of times the alert rules were redistributed because the instances of the cluster changed.",
+			}),
+		ShardingHandedOffRulesTotal: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_sharding_handed_off_alert_rules_total",
+				Help:      "The total number of alert rules that this instance stopped evaluating because another instance owns them.",
+			}),
+		EvalTimeouts: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "rule_evaluation_timeouts_total",
+				Help:      "The total number of rule evaluation attempts that did not finish within the evaluation timeout.",
+			},
+			[]string{"org"},
+		),
+		EvaluationsQueued: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_evaluations_queued",
+				Help:      "The number of rule evaluations that wait for an evaluation slot, by priority class.",
+			},
+			[]string{"priority"},
+		),
+		EvaluationQueueDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_evaluation_queue_duration_seconds",
+				Help:      "The time a rule evaluation waited for an evaluation slot, by priority class.",
+				Buckets:   []float64{0, .01, .1, .5, 1, 5, 10, 15, 30, 60, 120, 300},
+			},
+			[]string{"priority"},
+		),
+		EvaluationsSkipped: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: Namespace,
+				Subsystem: Subsystem,
+				Name:      "schedule_evaluations_skipped_total",
+				Help:      "The total number of rule evaluations that were skipped because no evaluation slot was available before the next evaluation of the rule, by priority class.",
+			},
+			[]string{"priority"},
+		),
 	}
 }
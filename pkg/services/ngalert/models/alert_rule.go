@@ -105,6 +105,35 @@ const (
 	KeepLastErrState ExecutionErrorState = "KeepLast"
 )
 
+// RulePriority is the priority class of a rule. When the number of concurrent evaluations is limited, the scheduler
+// evaluates the rules of a higher class first. An empty priority is the same as RulePriorityNormal.
+type RulePriority string
+
+func (p RulePriority) String() string {
+	return string(p)
+}
+
+func RulePriorityFromString(priority string) (RulePriority, error) {
+	switch priority {
+	case "":
+		return "", nil
+	case string(RulePriorityCritical):
+		return RulePriorityCritical, nil
+	case string(RulePriorityNormal):
+		return RulePriorityNormal, nil
+	case string(RulePriorityLow):
+		return RulePriorityLow, nil
+	default:
+		return "", fmt.Errorf("unknown priority option %s", priority)
+	}
+}
+
+const (
+	RulePriorityCritical RulePriority = "critical"
+	RulePriorityNormal   RulePriority = "normal"
+	RulePriorityLow      RulePriority = "low"
+)
+
 type RuleType string
 
 const (
@@ -164,6 +193,8 @@ const (
 	StateReasonUpdated       = "Updated"
 	StateReasonRuleDeleted   = "RuleDeleted"
 	StateReasonKeepLast      = "KeepLast"
+	StateReasonKeepFiring    = "KeepFiring"
+	StateReasonInhibited     = "Inhibited"
 )
 
 func ConcatReasons(reasons ...string) string {
@@ -265,11 +296,18 @@ type AlertRule struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          map[string]string
 	Labels               map[string]string
 	IsPaused             bool
 	NotificationSettings []NotificationSettings
 	Metadata             AlertRuleMetadata
+	// InhibitedBy are the rules whose firing alerts suppress the alerts of this rule.
+	InhibitedBy []InhibitionRule
+	// EvaluationTimeout overrides the evaluation timeout of the scheduler for this rule if it is greater than zero.
+	EvaluationTimeout time.Duration
+	// Priority is the priority class of the rule when the number of concurrent evaluations is limited.
+	Priority RulePriority
 }
 
 type AlertRuleMetadata struct {
@@ -571,6 +609,18 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		return fmt.Errorf("%w: field `for` cannot be negative", ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.KeepFiringFor < 0 {
+		return fmt.Errorf("%w: field `keep_firing_for` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
+	if alertRule.EvaluationTimeout < 0 {
+		return fmt.Errorf("%w: field `evaluation_timeout` cannot be negative", ErrAlertRuleFailedValidation)
+	}
+
+	if _, err := RulePriorityFromString(string(alertRule.Priority)); err != nil {
+		return errors.Join(ErrAlertRuleFailedValidation, err)
+	}
+
 	if len(alertRule.Labels) > 0 {
 		for label := range alertRule.Labels {
 			if _, ok := LabelsUserCannotSpecify[label]; ok {
@@ -579,6 +629,15 @@ func (alertRule *AlertRule) ValidateAlertRule(cfg setting.UnifiedAlertingSetting
 		}
 	}
 
+	for _, inhibition := range alertRule.InhibitedBy {
+		if err := inhibition.Validate(); err != nil {
+			return errors.Join(ErrAlertRuleFailedValidation, fmt.Errorf("invalid inhibition: %w", err))
+		}
+		if alertRule.UID != "" && inhibition.SourceRuleUID == alertRule.UID {
+			return fmt.Errorf("%w: alert rule cannot be inhibited by itself", ErrAlertRuleFailedValidation)
+		}
+	}
+
 	if len(alertRule.NotificationSettings) > 0 {
 		if len(alertRule.NotificationSettings) != 1 {
 			return fmt.Errorf("%w: only one notification settings entry is allowed", ErrAlertRuleFailedValidation)
@@ -621,7 +680,9 @@ func clearRecordingRuleIgnoredFields(rule *AlertRule) {
 	rule.ExecErrState = ""
 	rule.Condition = ""
 	rule.For = 0
+	rule.KeepFiringFor = 0
 	rule.NotificationSettings = nil
+	rule.InhibitedBy = nil
 }
 
 func (alertRule *AlertRule) ResourceType() string {
@@ -792,6 +853,15 @@ func PatchPartialAlertRule(existingRule *AlertRule, ruleToPatch *AlertRuleWithOp
 	if ruleToPatch.For == -1 {
 		ruleToPatch.For = existingRule.For
 	}
+	if ruleToPatch.KeepFiringFor == -1 {
+		ruleToPatch.KeepFiringFor = existingRule.KeepFiringFor
+	}
+	if ruleToPatch.EvaluationTimeout == -1 {
+		ruleToPatch.EvaluationTimeout = existingRule.EvaluationTimeout
+	}
+	if ruleToPatch.Priority == "" {
+		ruleToPatch.Priority = existingRule.Priority
+	}
 	if !ruleToPatch.HasPause {
 		ruleToPatch.IsPaused = existingRule.IsPaused
 	}
@@ -859,6 +929,9 @@ type Record struct {
 	Metric string
 	// From contains a query RefID, indicating which expression node is the output of the recording rule.
 	From string
+	// Target is the writer target that the results are sent to. If it is empty, the results are sent to the target
+	// that is configured for the organization.
+	Target string
 }
 
 func (r *Record) Fingerprint() data.Fingerprint {
@@ -873,6 +946,59 @@ func (r *Record) Fingerprint() data.Fingerprint {
 
 	writeString(r.Metric)
 	writeString(r.From)
+	// The target is only added if it is set, so that the fingerprints of existing rules do not change.
+	if r.Target != "" {
+		writeString(r.Target)
+	}
+	return data.Fingerprint(h.Sum64())
+}
+
+// InhibitionRule suppresses the alerts of a rule while an alert of the source rule is firing. Only the alerts that have
+// the same values of the Equal labels as the firing alert are suppressed.
+type InhibitionRule struct {
+	// SourceRuleUID is the UID of the rule whose firing alerts suppress the alerts. It must be in the same organization.
+	SourceRuleUID string `json:"source_rule_uid"`
+	// Equal are the labels that must have the same values in both alerts. If it is empty, any firing alert of
+	// the source rule suppresses all alerts of the rule.
+	Equal []string `json:"equal,omitempty"`
+}
+
+func (i InhibitionRule) Validate() error {
+	if i.SourceRuleUID == "" {
+		return errors.New("source rule UID is required")
+	}
+	for _, l := range i.Equal {
+		if l == "" {
+			return errors.New("equal labels cannot be empty")
+		}
+	}
+	return nil
+}
+
+// Matches returns true if the alert with the target labels is suppressed by the alert of the source rule with the source labels.
+func (i InhibitionRule) Matches(source, target map[string]string) bool {
+	for _, l := range i.Equal {
+		if source[l] != target[l] {
+			return false
+		}
+	}
+	return true
+}
+
+func (i InhibitionRule) Fingerprint() data.Fingerprint {
+	h := fnv.New64()
+
+	writeString := func(s string) {
+		// save on extra slice allocation when string is converted to bytes.
+		_, _ = h.Write(unsafe.Slice(unsafe.StringData(s), len(s))) //nolint:gosec
+		// ignore errors returned by Write method because fnv never returns them.
+		_, _ = h.Write([]byte{255}) // use an invalid utf-8 sequence as separator
+	}
+
+	writeString(i.SourceRuleUID)
+	for _, l := range i.Equal {
+		writeString(l)
+	}
 	return data.Fingerprint(h.Sum64())
 }
 
@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/cmputil"
 )
@@ -877,3 +878,42 @@ func TestTimeRangeYAML(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, yamlRaw, string(serialized))
 }
+
+func TestInhibitionRule(t *testing.T) {
+	t.Run("Validate", func(t *testing.T) {
+		require.NoError(t, InhibitionRule{SourceRuleUID: "source"}.Validate())
+		require.NoError(t, InhibitionRule{SourceRuleUID: "source", Equal: []string{"dc"}}.Validate())
+		require.Error(t, InhibitionRule{Equal: []string{"dc"}}.Validate())
+		require.Error(t, InhibitionRule{SourceRuleUID: "source", Equal: []string{""}}.Validate())
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		source := map[string]string{"dc": "eu", "team": "a"}
+		testCases := []struct {
+			name     string
+			equal    []string
+			target   map[string]string
+			expected bool
+		}{
+			{name: "no equal labels", target: map[string]string{"dc": "us"}, expected: true},
+			{name: "equal values", equal: []string{"dc"}, target: map[string]string{"dc": "eu", "team": "b"}, expected: true},
+			{name: "different values", equal: []string{"dc", "team"}, target: map[string]string{"dc": "eu", "team": "b"}, expected: false},
+			{name: "missing label", equal: []string{"dc"}, target: map[string]string{"team": "a"}, expected: false},
+			{name: "missing in both", equal: []string{"zone"}, target: map[string]string{"team": "a"}, expected: true},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				i := InhibitionRule{SourceRuleUID: "source", Equal: tc.equal}
+				require.Equal(t, tc.expected, i.Matches(source, tc.target))
+			})
+		}
+	})
+
+	t.Run("rule cannot be inhibited by itself", func(t *testing.T) {
+		rule := RuleGen.GenerateRef()
+		rule.InhibitedBy = []InhibitionRule{{SourceRuleUID: rule.UID}}
+		err := rule.ValidateAlertRule(setting.UnifiedAlertingSettings{BaseInterval: time.Second})
+		require.ErrorIs(t, err, ErrAlertRuleFailedValidation)
+		require.ErrorContains(t, err, "inhibited by itself")
+	})
+}
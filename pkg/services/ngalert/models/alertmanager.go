@@ -22,6 +22,10 @@ type HistoricAlertConfiguration struct {
 	// LastApplied a timestamp indicating the most recent time at which the configuration was applied to an Alertmanager, or 0 otherwise.
 	// Only set this field if the configuration has been applied by the caller.
 	LastApplied int64 `xorm:"last_applied"`
+
+	// Author is the login of the user that saved the configuration, or empty if the configuration was saved by Grafana
+	// itself, for example when the default configuration is applied.
+	Author string `xorm:"author"`
 }
 
 // SaveAlertmanagerConfigurationCmd is the command to save an alertmanager configuration.
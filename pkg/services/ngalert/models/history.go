@@ -3,6 +3,8 @@ package models
 import (
 	"time"
 
+	"github.com/prometheus/alertmanager/pkg/labels"
+
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 )
 
@@ -13,8 +15,54 @@ type HistoryQuery struct {
 	DashboardUID string
 	PanelID      int64
 	Labels       map[string]string
+	// Matchers filter the entries by the labels of the alert instances, in addition to Labels.
+	Matchers     labels.Matchers
 	From         time.Time
 	To           time.Time
 	Limit        int
 	SignedInUser identity.Requester
 }
+
+// StateHistoryEntry is a state transition of an alert instance that is stored in the database.
+type StateHistoryEntry struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	RuleUID       string `xorm:"rule_uid"`
+	RuleID        int64  `xorm:"rule_id"`
+	RuleTitle     string `xorm:"rule_title"`
+	RuleGroup     string `xorm:"rule_group"`
+	RuleCondition string `xorm:"rule_condition"`
+	FolderUID     string `xorm:"folder_uid"`
+	DashboardUID  string `xorm:"dashboard_uid"`
+	PanelID       int64  `xorm:"panel_id"`
+	Fingerprint   string `xorm:"fingerprint"`
+	// Labels are the labels of the alert instance, encoded as JSON.
+	Labels        string `xorm:"labels"`
+	PreviousState string `xorm:"previous_state"`
+	CurrentState  string `xorm:"current_state"`
+	// Values are the values of the evaluation, encoded as JSON.
+	Values       string `xorm:"state_values"`
+	ErrorMessage string `xorm:"error_message"`
+	// EvaluatedAt is the time of the evaluation in Unix milliseconds.
+	EvaluatedAt int64 `xorm:"evaluated_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *StateHistoryEntry) TableName() string {
+	return "alert_state_history"
+}
+
+// StateHistoryEntryQuery is a query for the entries of the alert state history that are stored in the database.
+// The entries are returned ordered by the time of the evaluation, newest first.
+type StateHistoryEntryQuery struct {
+	OrgID        int64
+	RuleUID      string
+	DashboardUID string
+	PanelID      int64
+	// FolderUIDs restrict the entries to the rules in the folders, if it is not empty.
+	FolderUIDs []string
+	From       time.Time
+	To         time.Time
+	Limit      int
+	Offset     int
+}
@@ -17,6 +17,7 @@ type AlertInstance struct {
 	LastSentAt        *time.Time
 	ResolvedAt        *time.Time
 	ResultFingerprint string
+	KeepFiringSince   *time.Time
 }
 
 type AlertInstanceKey struct {
@@ -39,6 +40,8 @@ const (
 	InstanceStateNoData InstanceStateType = "NoData"
 	// InstanceStateError is for an erroring alert.
 	InstanceStateError InstanceStateType = "Error"
+	// InstanceStateSuppressed is for a firing alert that is suppressed by an alert of another rule.
+	InstanceStateSuppressed InstanceStateType = "Suppressed"
 )
 
 // IsValid checks that the value of InstanceStateType is a valid
@@ -48,7 +51,8 @@ func (i InstanceStateType) IsValid() bool {
 		i == InstanceStateNormal ||
 		i == InstanceStateNoData ||
 		i == InstanceStatePending ||
-		i == InstanceStateError
+		i == InstanceStateError ||
+		i == InstanceStateSuppressed
 }
 
 // ListAlertInstancesQuery is the query list alert Instances.
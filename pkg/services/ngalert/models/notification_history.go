@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationHistoryStatus is the result of a delivery attempt of a notification.
+type NotificationHistoryStatus string
+
+const (
+	NotificationHistoryStatusSuccess NotificationHistoryStatus = "success"
+	NotificationHistoryStatusFailure NotificationHistoryStatus = "failure"
+)
+
+// NotificationHistoryEntry is a delivery attempt of a notification to an integration of a contact point that is
+// stored in the database.
+type NotificationHistoryEntry struct {
+	ID       int64  `xorm:"pk autoincr 'id'"`
+	OrgID    int64  `xorm:"org_id"`
+	Receiver string `xorm:"receiver"`
+	// Integration is the type of the integration, for example slack or email.
+	Integration      string `xorm:"integration"`
+	IntegrationIndex int    `xorm:"integration_index"`
+	GroupKey         string `xorm:"group_key"`
+	// AlertFingerprints are the fingerprints of the notified alerts, encoded as JSON.
+	AlertFingerprints string                    `xorm:"alert_fingerprints"`
+	AlertCount        int                       `xorm:"alert_count"`
+	Status            NotificationHistoryStatus `xorm:"status"`
+	// StatusCode is the HTTP status code of the response of the integration. It is zero if the integration does not
+	// send HTTP requests through Grafana, or if no response was received.
+	StatusCode   int    `xorm:"status_code"`
+	ErrorMessage string `xorm:"error_message"`
+	// Retry is true if the failed attempt is retried by the Alertmanager.
+	Retry bool `xorm:"retry"`
+	// Duration is the duration of the attempt in milliseconds.
+	Duration int64 `xorm:"duration"`
+	// SentAt is the time of the attempt in Unix milliseconds.
+	SentAt int64 `xorm:"sent_at"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (e *NotificationHistoryEntry) TableName() string {
+	return "alert_notification_history"
+}
+
+// NotificationHistoryQuery is a query for the delivery attempts of notifications that are stored in the database.
+// The entries are returned ordered by the time of the attempt, newest first.
+type NotificationHistoryQuery struct {
+	OrgID       int64
+	Receiver    string
+	Integration string
+	Status      NotificationHistoryStatus
+	// AlertFingerprint restricts the entries to the attempts that notified the alert, if it is not empty.
+	AlertFingerprint string
+	From             time.Time
+	To               time.Time
+	Limit            int
+	Offset           int
+}
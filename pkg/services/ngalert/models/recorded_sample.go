@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// RecordedSample is a sample of a recording rule that is written to the Grafana database.
+type RecordedSample struct {
+	ID     int64  `xorm:"pk autoincr 'id'"`
+	OrgID  int64  `xorm:"org_id"`
+	Metric string `xorm:"metric"`
+	// Labels are the labels of the series, encoded as JSON.
+	Labels string `xorm:"labels"`
+	// LabelsHash is the fingerprint of the labels of the series.
+	LabelsHash string  `xorm:"labels_hash"`
+	Value      float64 `xorm:"value"`
+	// Timestamp is the time of the sample in Unix milliseconds.
+	Timestamp int64 `xorm:"ts"`
+}
+
+// A XORM interface that defines the used table for this struct.
+func (s *RecordedSample) TableName() string {
+	return "alert_recorded_sample"
+}
+
+// RecordedSampleQuery is a query for the samples of a metric that are stored in the database.
+// The samples are returned ordered by series and time, oldest first.
+type RecordedSampleQuery struct {
+	OrgID  int64
+	Metric string
+	From   time.Time
+	To     time.Time
+	// Labels are the labels that the series must have. The store can return series that do not have them, so they
+	// must be checked again by the caller.
+	Labels map[string]string
+	Limit  int
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MaxRecurringSilenceWindows is the maximum number of windows of a recurring silence that are materialized at once.
+// It protects the Alertmanager from schedules that repeat too often, such as every minute.
+const MaxRecurringSilenceWindows = 100
+
+var (
+	ErrRecurringSilenceNotFound       = errors.New("recurring silence not found")
+	ErrRecurringSilenceFailedValidate = errors.New("invalid recurring silence")
+)
+
+// RecurringSilence is a silence that repeats on a schedule, for example a maintenance window every Sunday from 02:00
+// to 04:00. The windows of the schedule are materialized into Alertmanager silences ahead of time.
+type RecurringSilence struct {
+	ID    int64  `xorm:"pk autoincr 'id'"`
+	OrgID int64  `xorm:"org_id"`
+	UID   string `xorm:"uid"`
+	// Matchers are the matchers of the materialized silences.
+	Matchers amv2.Matchers `xorm:"matchers"`
+	// Schedule is a cron expression in the standard format, or a descriptor such as @weekly, of the starts of the windows.
+	Schedule string `xorm:"schedule"`
+	// Duration is the length of every window.
+	Duration time.Duration `xorm:"duration"`
+	// Timezone is the IANA name of the location the schedule is evaluated in. Empty means UTC.
+	Timezone   string     `xorm:"timezone"`
+	Comment    string     `xorm:"comment"`
+	CreatedBy  string     `xorm:"created_by"`
+	Provenance Provenance `xorm:"provenance"`
+	// Deleted marks a recurring silence that is deleted but whose materialized silences are not expired yet.
+	Deleted bool `xorm:"deleted"`
+	// Version is incremented every time the recurring silence is changed or deleted.
+	Version int64     `xorm:"version"`
+	Updated time.Time `xorm:"updated"`
+
+	Materialization RecurringSilenceMaterialization `xorm:"extends"`
+}
+
+// RecurringSilenceMaterialization is the state of the materialization of the windows of a recurring silence.
+type RecurringSilenceMaterialization struct {
+	// Version is the version of the recurring silence that the silences are materialized for.
+	Version int64 `xorm:"materialized_version"`
+	// Until is the Unix timestamp in seconds until which the windows are materialized.
+	Until int64 `xorm:"materialized_until"`
+	// Silences are the silences that are created for the windows and have not ended yet.
+	Silences []MaterializedSilence `xorm:"materialized_silences"`
+}
+
+func (RecurringSilence) TableName() string {
+	return "alert_recurring_silence"
+}
+
+// MaterializedSilence is a silence that is created in the Alertmanager for a window of a recurring silence.
+type MaterializedSilence struct {
+	ID     string    `json:"id"`
+	EndsAt time.Time `json:"endsAt"`
+}
+
+// RecurringSilenceWindow is a single occurrence of a recurring silence.
+type RecurringSilenceWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Validate checks that the recurring silence can be materialized.
+func (rs *RecurringSilence) Validate() error {
+	if rs.UID != "" {
+		if err := util.ValidateUID(rs.UID); err != nil {
+			return fmt.Errorf("%w: %s", ErrRecurringSilenceFailedValidate, err)
+		}
+	}
+	if len(rs.Matchers) == 0 {
+		return fmt.Errorf("%w: at least one matcher is required", ErrRecurringSilenceFailedValidate)
+	}
+	if err := rs.Matchers.Validate(strfmt.Default); err != nil {
+		return fmt.Errorf("%w: invalid matchers: %s", ErrRecurringSilenceFailedValidate, err)
+	}
+	if _, err := rs.schedule(); err != nil {
+		return fmt.Errorf("%w: %s", ErrRecurringSilenceFailedValidate, err)
+	}
+	if rs.Duration < time.Minute {
+		return fmt.Errorf("%w: duration must be at least 1m", ErrRecurringSilenceFailedValidate)
+	}
+	if _, err := rs.location(); err != nil {
+		return fmt.Errorf("%w: %s", ErrRecurringSilenceFailedValidate, err)
+	}
+	return nil
+}
+
+// HasSameDefinition returns true if the recurring silences create the same silences and have the same provenance.
+func (rs *RecurringSilence) HasSameDefinition(other *RecurringSilence) bool {
+	return rs.Schedule == other.Schedule &&
+		rs.Duration == other.Duration &&
+		rs.Timezone == other.Timezone &&
+		rs.Comment == other.Comment &&
+		rs.CreatedBy == other.CreatedBy &&
+		rs.Provenance == other.Provenance &&
+		reflect.DeepEqual(rs.Matchers, other.Matchers)
+}
+
+// Windows returns the windows of the recurring silence that start after from and not later than to, at most
+// MaxRecurringSilenceWindows.
+func (rs *RecurringSilence) Windows(from, to time.Time) ([]RecurringSilenceWindow, error) {
+	schedule, err := rs.schedule()
+	if err != nil {
+		return nil, err
+	}
+	loc, err := rs.location()
+	if err != nil {
+		return nil, err
+	}
+	var windows []RecurringSilenceWindow
+	for start := schedule.Next(from.In(loc)); !start.IsZero() && !start.After(to); start = schedule.Next(start) {
+		windows = append(windows, RecurringSilenceWindow{Start: start, End: start.Add(rs.Duration)})
+		if len(windows) == MaxRecurringSilenceWindows {
+			break
+		}
+	}
+	return windows, nil
+}
+
+// ToSilence returns the silence of the window of the recurring silence.
+func (rs *RecurringSilence) ToSilence(window RecurringSilenceWindow) Silence {
+	matchers := make(amv2.Matchers, 0, len(rs.Matchers))
+	for _, m := range rs.Matchers {
+		if m == nil {
+			continue
+		}
+		c := *m
+		matchers = append(matchers, &c)
+	}
+	comment := rs.Comment
+	if comment == "" {
+		comment = "Recurring silence"
+	}
+	return Silence{
+		Silence: amv2.Silence{
+			Comment:   util.Pointer(fmt.Sprintf("%s (recurring silence %s)", comment, rs.UID)),
+			CreatedBy: util.Pointer(rs.CreatedBy),
+			StartsAt:  util.Pointer(strfmt.DateTime(window.Start)),
+			EndsAt:    util.Pointer(strfmt.DateTime(window.End)),
+			Matchers:  matchers,
+		},
+	}
+}
+
+func (rs *RecurringSilence) schedule() (cron.Schedule, error) {
+	if rs.Schedule == "" {
+		return nil, errors.New("schedule is required")
+	}
+	schedule, err := cron.ParseStandard(rs.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", rs.Schedule, err)
+	}
+	return schedule, nil
+}
+
+func (rs *RecurringSilence) location() (*time.Location, error) {
+	if rs.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(rs.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", rs.Timezone, err)
+	}
+	return loc, nil
+}
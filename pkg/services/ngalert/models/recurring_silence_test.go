@@ -0,0 +1,103 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestRecurringSilenceValidate(t *testing.T) {
+	valid := func() RecurringSilence {
+		return RecurringSilence{
+			UID: "maintenance",
+			Matchers: amv2.Matchers{
+				{Name: util.Pointer("env"), Value: util.Pointer("prod"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)},
+			},
+			Schedule: "0 2 * * SUN",
+			Duration: 2 * time.Hour,
+			Timezone: "Europe/Berlin",
+		}
+	}
+	require.NoError(t, (&RecurringSilence{Matchers: valid().Matchers, Schedule: "@weekly", Duration: time.Hour}).Validate())
+
+	testCases := []struct {
+		name   string
+		mutate func(rs *RecurringSilence)
+	}{
+		{name: "invalid uid", mutate: func(rs *RecurringSilence) { rs.UID = "not a uid!" }},
+		{name: "no matchers", mutate: func(rs *RecurringSilence) { rs.Matchers = nil }},
+		{name: "invalid matcher", mutate: func(rs *RecurringSilence) { rs.Matchers[0].Name = nil }},
+		{name: "no schedule", mutate: func(rs *RecurringSilence) { rs.Schedule = "" }},
+		{name: "invalid schedule", mutate: func(rs *RecurringSilence) { rs.Schedule = "0 2 * *" }},
+		{name: "duration too short", mutate: func(rs *RecurringSilence) { rs.Duration = time.Second }},
+		{name: "invalid timezone", mutate: func(rs *RecurringSilence) { rs.Timezone = "Mars/Olympus_Mons" }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rs := valid()
+			require.NoError(t, rs.Validate())
+			tc.mutate(&rs)
+			require.ErrorIs(t, rs.Validate(), ErrRecurringSilenceFailedValidate)
+		})
+	}
+}
+
+func TestRecurringSilenceWindows(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	t.Run("returns the windows in the timezone", func(t *testing.T) {
+		rs := RecurringSilence{Schedule: "0 2 * * SUN", Duration: 2 * time.Hour, Timezone: "Europe/Berlin"}
+		// Wednesday
+		from := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+		windows, err := rs.Windows(from, from.Add(14*24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, windows, 2)
+		require.Equal(t, time.Date(2024, 6, 9, 2, 0, 0, 0, berlin).UTC(), windows[0].Start.UTC())
+		require.Equal(t, time.Date(2024, 6, 9, 4, 0, 0, 0, berlin).UTC(), windows[0].End.UTC())
+		require.Equal(t, time.Date(2024, 6, 16, 2, 0, 0, 0, berlin).UTC(), windows[1].Start.UTC())
+	})
+
+	t.Run("excludes windows that start at from and includes windows that start at to", func(t *testing.T) {
+		rs := RecurringSilence{Schedule: "0 * * * *", Duration: time.Minute}
+		from := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+		windows, err := rs.Windows(from, from.Add(2*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, windows, 2)
+		require.True(t, windows[0].Start.Equal(from.Add(time.Hour)))
+		require.True(t, windows[1].Start.Equal(from.Add(2*time.Hour)))
+	})
+
+	t.Run("limits the number of windows", func(t *testing.T) {
+		rs := RecurringSilence{Schedule: "* * * * *", Duration: time.Minute}
+		from := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+		windows, err := rs.Windows(from, from.Add(24*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, windows, MaxRecurringSilenceWindows)
+	})
+}
+
+func TestRecurringSilenceToSilence(t *testing.T) {
+	rs := RecurringSilence{
+		UID: "maintenance",
+		Matchers: amv2.Matchers{
+			{Name: util.Pointer("env"), Value: util.Pointer("prod"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)},
+		},
+		Comment:   "Weekly maintenance",
+		CreatedBy: "admin",
+	}
+	start := time.Date(2024, 6, 9, 2, 0, 0, 0, time.UTC)
+	silence := rs.ToSilence(RecurringSilenceWindow{Start: start, End: start.Add(2 * time.Hour)})
+
+	require.Nil(t, silence.ID)
+	require.Equal(t, "Weekly maintenance (recurring silence maintenance)", *silence.Comment)
+	require.Equal(t, "admin", *silence.CreatedBy)
+	require.Equal(t, start, time.Time(*silence.StartsAt))
+	require.Equal(t, start.Add(2*time.Hour), time.Time(*silence.EndsAt))
+	require.Equal(t, rs.Matchers, silence.Matchers)
+	require.NotSame(t, rs.Matchers[0], silence.Matchers[0])
+}
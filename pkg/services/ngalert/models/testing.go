@@ -314,6 +314,30 @@ func (a *AlertRuleMutators) WithFor(duration time.Duration) AlertRuleMutator {
 	}
 }
 
+func (a *AlertRuleMutators) WithKeepFiringFor(duration time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.KeepFiringFor = duration
+	}
+}
+
+func (a *AlertRuleMutators) WithEvaluationTimeout(timeout time.Duration) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.EvaluationTimeout = timeout
+	}
+}
+
+func (a *AlertRuleMutators) WithPriority(priority RulePriority) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.Priority = priority
+	}
+}
+
+func (a *AlertRuleMutators) WithInhibitedBy(inhibitions ...InhibitionRule) AlertRuleMutator {
+	return func(rule *AlertRule) {
+		rule.InhibitedBy = inhibitions
+	}
+}
+
 func (a *AlertRuleMutators) WithForNTimes(timesOfInterval int64) AlertRuleMutator {
 	return func(rule *AlertRule) {
 		rule.For = time.Duration(rule.IntervalSeconds*timesOfInterval) * time.Second
@@ -599,21 +623,24 @@ func GenerateGroupKey(orgID int64) AlertRuleGroupKey {
 // CopyRule creates a deep copy of AlertRule
 func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 	result := AlertRule{
-		ID:              r.ID,
-		OrgID:           r.OrgID,
-		Title:           r.Title,
-		Condition:       r.Condition,
-		Updated:         r.Updated,
-		IntervalSeconds: r.IntervalSeconds,
-		Version:         r.Version,
-		UID:             r.UID,
-		NamespaceUID:    r.NamespaceUID,
-		RuleGroup:       r.RuleGroup,
-		RuleGroupIndex:  r.RuleGroupIndex,
-		NoDataState:     r.NoDataState,
-		ExecErrState:    r.ExecErrState,
-		For:             r.For,
-		Record:          r.Record,
+		ID:                r.ID,
+		OrgID:             r.OrgID,
+		Title:             r.Title,
+		Condition:         r.Condition,
+		Updated:           r.Updated,
+		IntervalSeconds:   r.IntervalSeconds,
+		Version:           r.Version,
+		UID:               r.UID,
+		NamespaceUID:      r.NamespaceUID,
+		RuleGroup:         r.RuleGroup,
+		RuleGroupIndex:    r.RuleGroupIndex,
+		NoDataState:       r.NoDataState,
+		ExecErrState:      r.ExecErrState,
+		For:               r.For,
+		KeepFiringFor:     r.KeepFiringFor,
+		Record:            r.Record,
+		EvaluationTimeout: r.EvaluationTimeout,
+		Priority:          r.Priority,
 	}
 
 	if r.DashboardUID != nil {
@@ -655,6 +682,7 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		result.Record = &Record{
 			From:   r.Record.From,
 			Metric: r.Record.Metric,
+			Target: r.Record.Target,
 		}
 	}
 
@@ -662,6 +690,13 @@ func CopyRule(r *AlertRule, mutators ...AlertRuleMutator) *AlertRule {
 		result.NotificationSettings = append(result.NotificationSettings, CopyNotificationSettings(s))
 	}
 
+	for _, i := range r.InhibitedBy {
+		result.InhibitedBy = append(result.InhibitedBy, InhibitionRule{
+			SourceRuleUID: i.SourceRuleUID,
+			Equal:         slices.Clone(i.Equal),
+		})
+	}
+
 	if len(mutators) > 0 {
 		for _, mutator := range mutators {
 			mutator(&result)
@@ -1329,7 +1364,9 @@ func ConvertToRecordingRule(rule *AlertRule) {
 	rule.NoDataState = ""
 	rule.ExecErrState = ""
 	rule.For = 0
+	rule.KeepFiringFor = 0
 	rule.NotificationSettings = nil
+	rule.InhibitedBy = nil
 }
 
 func nameToUid(name string) string { // Avoid legacy_storage.NameToUid import cycle.
@@ -146,6 +146,7 @@ type AlertNG struct {
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	AlertsRouter         *sender.AlertsRouter
+	recurringSilences    *notifier.RecurringSilenceService
 	accesscontrol        accesscontrol.AccessControl
 	accesscontrolService accesscontrol.Service
 	annotationsRepo      annotations.Repository
@@ -306,6 +307,10 @@ func (ng *AlertNG) init() error {
 		}
 	}
 
+	if ng.Cfg.UnifiedAlerting.NotificationHistory.Enabled {
+		overrides = append(overrides, notifier.WithNotificationHistory(ng.store))
+	}
+
 	decryptFn := ng.SecretsService.GetDecryptedValue
 	multiOrgMetrics := ng.Metrics.GetMultiOrgAlertmanagerMetrics()
 	moa, err := notifier.NewMultiOrgAlertmanager(ng.Cfg, ng.store, ng.store, ng.KVStore, ng.store, decryptFn, multiOrgMetrics, ng.NotificationService, moaLogger, ng.SecretsService, ng.FeatureToggles, overrides...)
@@ -350,34 +355,43 @@ func (ng *AlertNG) init() error {
 		// Force-disable the feature if the feature toggle is not on - sets us up for feature toggle removal.
 		ng.Cfg.UnifiedAlerting.RecordingRules.Enabled = false
 	}
-	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, clk, ng.Metrics.GetRemoteWriterMetrics())
+	recordingWriter, err := createRecordingWriter(ng.FeatureToggles, ng.Cfg.UnifiedAlerting.RecordingRules, ng.httpClientProvider, ng.store, clk, ng.Metrics.GetRemoteWriterMetrics())
 	if err != nil {
 		return fmt.Errorf("failed to initialize recording writer: %w", err)
 	}
 	ng.RecordingWriter = recordingWriter
 
 	schedCfg := schedule.SchedulerCfg{
-		MaxAttempts:          ng.Cfg.UnifiedAlerting.MaxAttempts,
-		C:                    clk,
-		BaseInterval:         ng.Cfg.UnifiedAlerting.BaseInterval,
-		MinRuleInterval:      ng.Cfg.UnifiedAlerting.MinInterval,
-		DisableGrafanaFolder: ng.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
-		JitterEvaluations:    schedule.JitterStrategyFrom(ng.Cfg.UnifiedAlerting, ng.FeatureToggles),
-		AppURL:               appUrl,
-		EvaluatorFactory:     evalFactory,
-		RuleStore:            ng.store,
-		RecordingRulesCfg:    ng.Cfg.UnifiedAlerting.RecordingRules,
-		Metrics:              ng.Metrics.GetSchedulerMetrics(),
-		AlertSender:          alertsRouter,
-		Tracer:               ng.tracer,
-		Log:                  log.New("ngalert.scheduler"),
-		RecordingWriter:      ng.RecordingWriter,
+		MaxAttempts:              ng.Cfg.UnifiedAlerting.MaxAttempts,
+		C:                        clk,
+		BaseInterval:             ng.Cfg.UnifiedAlerting.BaseInterval,
+		MinRuleInterval:          ng.Cfg.UnifiedAlerting.MinInterval,
+		DisableGrafanaFolder:     ng.Cfg.UnifiedAlerting.ReservedLabels.IsReservedLabelDisabled(models.FolderTitleLabel),
+		JitterEvaluations:        schedule.JitterStrategyFrom(ng.Cfg.UnifiedAlerting, ng.FeatureToggles),
+		DeduplicateQueries:       ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingQueryDeduplication),
+		AppURL:                   appUrl,
+		EvaluatorFactory:         evalFactory,
+		RuleStore:                ng.store,
+		RecordingRulesCfg:        ng.Cfg.UnifiedAlerting.RecordingRules,
+		Metrics:                  ng.Metrics.GetSchedulerMetrics(),
+		AlertSender:              alertsRouter,
+		Tracer:                   ng.tracer,
+		Log:                      log.New("ngalert.scheduler"),
+		RecordingWriter:          ng.RecordingWriter,
+		MaxConcurrentEvaluations: ng.Cfg.UnifiedAlerting.MaxConcurrentEvaluations,
+	}
+	if ng.Cfg.UnifiedAlerting.HASchedulerShardingEnabled {
+		if membership := ng.MultiOrgAlertmanager.ClusterMembership(); membership != nil {
+			schedCfg.ClusterMembership = membership
+		} else {
+			ng.Log.Warn("Sharding of alert rule evaluation is enabled but high availability is not configured, all rules are evaluated by this instance")
+		}
 	}
 
 	// There are a set of feature toggles available that act as short-circuits for common configurations.
 	// If any are set, override the config accordingly.
 	ApplyStateHistoryFeatureToggles(&ng.Cfg.UnifiedAlerting.StateHistory, ng.FeatureToggles, ng.Log)
-	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol))
+	history, err := configureHistorianBackend(initCtx, ng.Cfg.UnifiedAlerting.StateHistory, ng.annotationsRepo, ng.dashboardService, ng.store, ng.store, ng.Metrics.GetHistorianMetrics(), ng.Log, ng.tracer, ac.NewRuleService(ng.accesscontrol), ng.httpClientProvider, ng.Metrics.GetRemoteWriterMetrics())
 	if err != nil {
 		return err
 	}
@@ -396,10 +410,15 @@ func (ng *AlertNG) init() error {
 		Tracer:                         ng.tracer,
 		Log:                            log.New("ngalert.state.manager"),
 		ResolvedRetention:              ng.Cfg.UnifiedAlerting.ResolvedAlertRetention,
+		ShardedEvaluation:              schedCfg.ClusterMembership != nil,
 	}
 	logger := log.New("ngalert.state.manager.persist")
 	statePersister := state.NewSyncStatePersisiter(logger, cfg)
-	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
+	if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) && schedCfg.ClusterMembership != nil {
+		// The periodic save replaces all states in the database with the states of this instance, which would delete
+		// the states of the rules that are evaluated by other instances.
+		ng.Log.Warn("Periodic saving of alert state is not supported when the evaluation of alert rules is sharded, saving state after every evaluation")
+	} else if ng.FeatureToggles.IsEnabledGlobally(featuremgmt.FlagAlertingSaveStatePeriodic) {
 		ticker := clock.New().Ticker(ng.Cfg.UnifiedAlerting.StatePeriodicSaveInterval)
 		statePersister = state.NewAsyncStatePersister(logger, ticker, cfg)
 	}
@@ -445,6 +464,14 @@ func (ng *AlertNG) init() error {
 		ng.Cfg.UnifiedAlerting.RulesPerRuleGroupLimit, ng.Log, notifier.NewNotificationSettingsValidationService(ng.store),
 		ac.NewRuleService(ng.accesscontrol))
 
+	ng.recurringSilences = notifier.NewRecurringSilenceService(
+		ac.NewSilenceService(ng.accesscontrol, ng.store),
+		ng.store,
+		ng.MultiOrgAlertmanager,
+		ng.Cfg.UnifiedAlerting.RecurringSilences,
+		log.New("ngalert.recurring-silences"),
+	)
+
 	ng.Api = &api.API{
 		Cfg:                  ng.Cfg,
 		DatasourceCache:      ng.DataSourceCache,
@@ -472,6 +499,8 @@ func (ng *AlertNG) init() error {
 		FeatureManager:       ng.FeatureToggles,
 		AppUrl:               appUrl,
 		Historian:            history,
+		NotificationHistory:  ng.store,
+		RecurringSilences:    ng.recurringSilences,
 		Hooks:                api.NewHooks(ng.Log),
 		Tracer:               ng.tracer,
 	}
@@ -519,6 +548,9 @@ func (ng *AlertNG) Run(ctx context.Context) error {
 	children.Go(func() error {
 		return ng.AlertsRouter.Run(subCtx)
 	})
+	children.Go(func() error {
+		return ng.recurringSilences.Run(subCtx)
+	})
 
 	if ng.Cfg.UnifiedAlerting.ExecuteAlerts {
 		// Only Warm() the state manager if we are actually executing alerts.
@@ -562,7 +594,7 @@ type Historian interface {
 	state.Historian
 }
 
-func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl) (Historian, error) {
+func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingStateHistorySettings, ar annotations.Repository, ds dashboards.DashboardService, rs historian.RuleStore, hs historian.StateHistoryStore, met *metrics.Historian, l log.Logger, tracer tracing.Tracer, ac historian.AccessControl, hcp httpclient.Provider, wm *metrics.RemoteWriter) (Historian, error) {
 	if !cfg.Enabled {
 		met.Info.WithLabelValues("noop").Set(0)
 		return historian.NewNopHistorian(), nil
@@ -577,7 +609,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 	if backend == historian.BackendTypeMultiple {
 		primaryCfg := cfg
 		primaryCfg.Backend = cfg.MultiPrimary
-		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, met, l, tracer, ac)
+		primary, err := configureHistorianBackend(ctx, primaryCfg, ar, ds, rs, hs, met, l, tracer, ac, hcp, wm)
 		if err != nil {
 			return nil, fmt.Errorf("multi-backend target \"%s\" was misconfigured: %w", cfg.MultiPrimary, err)
 		}
@@ -586,7 +618,7 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		for _, b := range cfg.MultiSecondaries {
 			secCfg := cfg
 			secCfg.Backend = b
-			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, met, l, tracer, ac)
+			sec, err := configureHistorianBackend(ctx, secCfg, ar, ds, rs, hs, met, l, tracer, ac, hcp, wm)
 			if err != nil {
 				return nil, fmt.Errorf("multi-backend target \"%s\" was miconfigured: %w", b, err)
 			}
@@ -601,6 +633,26 @@ func configureHistorianBackend(ctx context.Context, cfg setting.UnifiedAlertingS
 		annotationBackendLogger := log.New("ngalert.state.historian", "backend", "annotations")
 		return historian.NewAnnotationBackend(annotationBackendLogger, store, rs, met, ac), nil
 	}
+	if backend == historian.BackendTypeSQL {
+		sqlBackendLogger := log.New("ngalert.state.historian", "backend", "sql")
+		return historian.NewSQLBackend(sqlBackendLogger, hs, rs, met, ac), nil
+	}
+	if backend == historian.BackendTypePrometheus {
+		if cfg.PrometheusWriteURL == "" {
+			return nil, fmt.Errorf("invalid prometheus remote write configuration: prometheus_remote_write_url is required")
+		}
+		w, err := writer.NewPrometheusWriter(setting.RecordingRuleSettings{
+			URL:               cfg.PrometheusWriteURL,
+			BasicAuthUsername: cfg.PrometheusBasicAuthUsername,
+			BasicAuthPassword: cfg.PrometheusBasicAuthPassword,
+			Timeout:           cfg.PrometheusTimeout,
+		}, hcp, clock.New(), log.New("ngalert.writer"), wm)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prometheus remote write configuration: %w", err)
+		}
+		prometheusBackendLogger := log.New("ngalert.state.historian", "backend", "prometheus")
+		return historian.NewPrometheusBackend(prometheusBackendLogger, w, met), nil
+	}
 	if backend == historian.BackendTypeLoki {
 		lcfg, err := historian.NewLokiConfig(cfg)
 		if err != nil {
@@ -670,12 +722,43 @@ func createRemoteAlertmanager(cfg remote.AlertmanagerConfig, kvstore kvstore.KVS
 	return remote.NewAlertmanager(cfg, notifier.NewFileStore(cfg.OrgID, kvstore), decryptFn, autogenFn, m, tracer)
 }
 
-func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
+func createRecordingWriter(featureToggles featuremgmt.FeatureToggles, settings setting.RecordingRuleSettings, httpClientProvider httpclient.Provider, sampleStore writer.RecordedSampleStore, clock clock.Clock, m *metrics.RemoteWriter) (schedule.RecordingWriter, error) {
 	logger := log.New("ngalert.writer")
 
-	if settings.Enabled {
-		return writer.NewPrometheusWriter(settings, httpClientProvider, clock, logger, m)
+	if !settings.Enabled {
+		return writer.NoopWriter{}, nil
+	}
+
+	defaultTarget := settings.DefaultTarget
+	if defaultTarget == "" {
+		defaultTarget = writer.TargetPrometheus
+	}
+
+	targets := map[string]writer.Writer{
+		writer.TargetSQL: writer.NewSQLWriter(sampleStore, clock, logger, m),
+	}
+	// The Prometheus target uses the settings of the [recording_rules] section, because it was the only target before.
+	if settings.URL != "" || defaultTarget == writer.TargetPrometheus {
+		w, err := writer.NewPrometheusWriter(settings, httpClientProvider, clock, logger, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure the prometheus target: %w", err)
+		}
+		targets[writer.TargetPrometheus] = w
+	}
+	if settings.InfluxDB.URL != "" {
+		w, err := writer.NewInfluxDBWriter(settings.InfluxDB, httpClientProvider, clock, logger, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure the influxdb target: %w", err)
+		}
+		targets[writer.TargetInfluxDB] = w
+	}
+	if settings.OTLP.URL != "" {
+		w, err := writer.NewOTLPWriter(settings.OTLP, httpClientProvider, clock, logger, m)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure the otlp target: %w", err)
+		}
+		targets[writer.TargetOTLP] = w
 	}
 
-	return writer.NoopWriter{}, nil
+	return writer.NewTargetWriter(targets, defaultTarget, settings.OrgTargets)
 }
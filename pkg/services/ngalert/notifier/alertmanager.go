@@ -59,6 +59,10 @@ type alertmanager struct {
 	decryptFn alertingNotify.GetDecryptedValueFn
 	orgID     int64
 
+	// notificationHistory records the delivery attempts of the integrations. It is nil if the notification history
+	// is disabled.
+	notificationHistory *notificationHistorian
+
 	withAutogen bool
 }
 
@@ -91,7 +95,7 @@ func (m maintenanceOptions) MaintenanceFunc(state alertingNotify.State) (int64,
 
 func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store AlertingStore, stateStore stateStore,
 	peer alertingNotify.ClusterPeer, decryptFn alertingNotify.GetDecryptedValueFn, ns notifications.Service,
-	m *metrics.Alertmanager, withAutogen bool,
+	notificationHistory NotificationHistoryStore, m *metrics.Alertmanager, withAutogen bool,
 ) (*alertmanager, error) {
 	nflog, err := stateStore.GetNotificationLog(ctx)
 	if err != nil {
@@ -154,6 +158,9 @@ func NewAlertmanager(ctx context.Context, orgID int64, cfg *setting.Cfg, store A
 		// TODO: Preferably, logic around autogen would be outside of the specific alertmanager implementation so that remote alertmanager will get it for free.
 		withAutogen: withAutogen,
 	}
+	if notificationHistory != nil {
+		am.notificationHistory = newNotificationHistorian(orgID, notificationHistory, l.New("component", "notification-history"))
+	}
 
 	return am, nil
 }
@@ -408,6 +415,10 @@ func (am *alertmanager) buildReceiverIntegrations(receiver *alertingNotify.APIRe
 	if err != nil {
 		return nil, err
 	}
+	integrations = wrapForTestNotificationCapture(receiver, tmpl, integrations)
+	if am.notificationHistory != nil {
+		integrations = am.notificationHistory.wrap(receiver.Name, integrations)
+	}
 	return integrations, nil
 }
 
@@ -0,0 +1,311 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/alertmanager/timeinterval"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// GetAlertmanagerConfigurationVersions returns the last n versions of the configuration for a given org, applied or not.
+func (moa *MultiOrgAlertmanager) GetAlertmanagerConfigurationVersions(ctx context.Context, org int64, limit int) ([]definitions.GettableAlertmanagerConfigVersion, error) {
+	configs, err := moa.configStore.GetConfigurationHistory(ctx, org, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get configuration history: %w", err)
+	}
+
+	versions := make([]definitions.GettableAlertmanagerConfigVersion, 0, len(configs))
+	for _, config := range configs {
+		version := definitions.GettableAlertmanagerConfigVersion{
+			ID:        config.ID,
+			Author:    config.Author,
+			CreatedAt: strfmt.DateTime(time.Unix(config.CreatedAt, 0).UTC()),
+			Default:   config.Default,
+			Hash:      config.ConfigurationHash,
+		}
+		if config.LastApplied != 0 {
+			appliedAt := strfmt.DateTime(time.Unix(config.LastApplied, 0).UTC())
+			version.LastApplied = &appliedAt
+		}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// DiffAlertmanagerConfigurationVersions returns the routes, receivers, templates and mute timings that changed between two
+// versions of the configuration for a given org. If to is 0, the latest version is used.
+func (moa *MultiOrgAlertmanager) DiffAlertmanagerConfigurationVersions(ctx context.Context, org int64, from, to int64) (definitions.AlertmanagerConfigDiff, error) {
+	if to == 0 {
+		latest, err := moa.configStore.GetConfigurationHistory(ctx, org, 1)
+		if err != nil {
+			return definitions.AlertmanagerConfigDiff{}, fmt.Errorf("failed to get configuration history: %w", err)
+		}
+		if len(latest) == 0 {
+			return definitions.AlertmanagerConfigDiff{}, store.ErrNoAlertmanagerConfiguration
+		}
+		to = latest[0].ID
+	}
+
+	fromConfig, err := moa.loadHistoricalConfiguration(ctx, org, from)
+	if err != nil {
+		return definitions.AlertmanagerConfigDiff{}, err
+	}
+	toConfig, err := moa.loadHistoricalConfiguration(ctx, org, to)
+	if err != nil {
+		return definitions.AlertmanagerConfigDiff{}, err
+	}
+
+	diff := DiffAlertmanagerConfigurations(fromConfig, toConfig)
+	diff.From = from
+	diff.To = to
+	return diff, nil
+}
+
+// GetHistoricalConfigurationForRollback returns the historical configuration with the given id in the form in which it
+// is posted to the API, so that restoring it goes through the same validation as saving a new configuration:
+// secure settings that did not change since are omitted so that the current ones are kept, the other ones are
+// decrypted so that they are encrypted again when saved, and integrations that were deleted since have no UID so that
+// they are created again.
+func (moa *MultiOrgAlertmanager) GetHistoricalConfigurationForRollback(ctx context.Context, org int64, id int64) (definitions.PostableUserConfig, error) {
+	cfg, err := moa.loadHistoricalConfiguration(ctx, org, id)
+	if err != nil {
+		return definitions.PostableUserConfig{}, err
+	}
+
+	currentReceivers := make(map[string]*definitions.PostableGrafanaReceiver)
+	current, err := moa.configStore.GetLatestAlertmanagerConfiguration(ctx, org)
+	if err != nil {
+		if !errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return definitions.PostableUserConfig{}, fmt.Errorf("failed to get latest configuration: %w", err)
+		}
+	} else if currentConfig, err := Load([]byte(current.AlertmanagerConfiguration)); err == nil {
+		// If the current config is un-loadable, treat it as if it never existed like when a new config is saved.
+		currentReceivers = currentConfig.GetGrafanaReceiverMap()
+	}
+
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		for _, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
+			currentReceiver, exists := currentReceivers[gr.UID]
+			if !exists {
+				gr.UID = ""
+			}
+			for key, encryptedValue := range gr.SecureSettings {
+				if exists && currentReceiver.SecureSettings[key] == encryptedValue {
+					delete(gr.SecureSettings, key)
+					continue
+				}
+				value, err := moa.Crypto.getDecryptedSecret(gr, key)
+				if err != nil {
+					return definitions.PostableUserConfig{}, fmt.Errorf("failed to decrypt stored secure setting: %w", err)
+				}
+				if exists {
+					currentValue, err := moa.Crypto.getDecryptedSecret(currentReceiver, key)
+					if err != nil {
+						return definitions.PostableUserConfig{}, fmt.Errorf("failed to decrypt stored secure setting: %w", err)
+					}
+					if currentValue == value {
+						delete(gr.SecureSettings, key)
+						continue
+					}
+				}
+				gr.SecureSettings[key] = value
+			}
+		}
+	}
+	return *cfg, nil
+}
+
+func (moa *MultiOrgAlertmanager) loadHistoricalConfiguration(ctx context.Context, org int64, id int64) (*definitions.PostableUserConfig, error) {
+	config, err := moa.configStore.GetHistoricalConfiguration(ctx, org, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get historical alertmanager configuration: %w", err)
+	}
+	cfg, err := Load([]byte(config.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal historical alertmanager configuration: %w", err)
+	}
+	return cfg, nil
+}
+
+// DiffAlertmanagerConfigurations returns the routes, receivers, templates and mute timings that were added, removed or
+// modified between the configurations from and to. Secure settings of receivers are compared in their encrypted form,
+// so a receiver whose secure setting was entered again is reported as modified.
+func DiffAlertmanagerConfigurations(from, to *definitions.PostableUserConfig) definitions.AlertmanagerConfigDiff {
+	return definitions.AlertmanagerConfigDiff{
+		Routes:      diffObjects(flattenRoutes(from.AlertmanagerConfig.Route), flattenRoutes(to.AlertmanagerConfig.Route), routesEqual),
+		Receivers:   diffObjects(receiversByName(from), receiversByName(to), receiversEqual),
+		Templates:   diffObjects(from.TemplateFiles, to.TemplateFiles, func(a, b string) bool { return a == b }),
+		MuteTimings: diffObjects(muteTimingsByName(from), muteTimingsByName(to), muteTimingsEqual),
+	}
+}
+
+func diffObjects[T any](from, to map[string]T, equal func(a, b T) bool) definitions.AlertmanagerConfigObjectsDiff {
+	diff := definitions.AlertmanagerConfigObjectsDiff{
+		Added:    []string{},
+		Removed:  []string{},
+		Modified: []string{},
+	}
+	for name, fromObject := range from {
+		toObject, ok := to[name]
+		if !ok {
+			diff.Removed = append(diff.Removed, name)
+			continue
+		}
+		if !equal(fromObject, toObject) {
+			diff.Modified = append(diff.Modified, name)
+		}
+	}
+	for name := range to {
+		if _, ok := from[name]; !ok {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff
+}
+
+// flattenRoutes returns the routes of the routing tree by their path. The path of the root route is "root", the path
+// of any other route is the path of its parent followed by its matchers, such as `root > {team="ops"}`. Siblings
+// with the same matchers are told apart by their position among them, such as `root > {team="ops"} #2`.
+func flattenRoutes(root *definitions.Route) map[string]*definitions.Route {
+	routes := make(map[string]*definitions.Route)
+	if root == nil {
+		return routes
+	}
+	var flatten func(path string, route *definitions.Route)
+	flatten = func(path string, route *definitions.Route) {
+		routes[path] = route
+		seen := make(map[string]int, len(route.Routes))
+		for _, child := range route.Routes {
+			if child == nil {
+				continue
+			}
+			childPath := path + " > " + routeMatchersString(child)
+			seen[childPath]++
+			if n := seen[childPath]; n > 1 {
+				childPath = fmt.Sprintf("%s #%d", childPath, n)
+			}
+			flatten(childPath, child)
+		}
+	}
+	flatten("root", root)
+	return routes
+}
+
+func routeMatchersString(route *definitions.Route) string {
+	matchers := make([]string, 0, len(route.ObjectMatchers)+len(route.Matchers)+len(route.Match)+len(route.MatchRE))
+	for _, m := range route.ObjectMatchers {
+		matchers = append(matchers, m.String())
+	}
+	for _, m := range route.Matchers {
+		matchers = append(matchers, m.String())
+	}
+	for name, value := range route.Match {
+		matchers = append(matchers, fmt.Sprintf("%s=%q", name, value))
+	}
+	for name, value := range route.MatchRE {
+		matchers = append(matchers, fmt.Sprintf("%s=~%q", name, value.String()))
+	}
+	sort.Strings(matchers)
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// routesEqual compares the settings of the routes, without their nested routes which are compared on their own.
+func routesEqual(a, b *definitions.Route) bool {
+	options := []cmp.Option{
+		cmpopts.EquateEmpty(),
+		cmpopts.IgnoreUnexported(labels.Matcher{}),
+		cmpopts.IgnoreFields(definitions.Route{}, "Routes", "Provenance"),
+	}
+	return cmp.Equal(a, b, options...)
+}
+
+func receiversByName(cfg *definitions.PostableUserConfig) map[string]*definitions.PostableApiReceiver {
+	receivers := make(map[string]*definitions.PostableApiReceiver, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		receivers[r.Name] = r
+	}
+	return receivers
+}
+
+func receiversEqual(a, b *definitions.PostableApiReceiver) bool {
+	if len(a.GrafanaManagedReceivers) != len(b.GrafanaManagedReceivers) {
+		return false
+	}
+	integrations := make(map[string]*definitions.PostableGrafanaReceiver, len(a.GrafanaManagedReceivers))
+	for _, integration := range a.GrafanaManagedReceivers {
+		integrations[integration.UID] = integration
+	}
+	for _, integration := range b.GrafanaManagedReceivers {
+		other, ok := integrations[integration.UID]
+		if !ok || !integrationsEqual(other, integration) {
+			return false
+		}
+	}
+	return true
+}
+
+func integrationsEqual(a, b *definitions.PostableGrafanaReceiver) bool {
+	if a.Name != b.Name || a.Type != b.Type || a.DisableResolveMessage != b.DisableResolveMessage {
+		return false
+	}
+	if len(a.SecureSettings) != len(b.SecureSettings) {
+		return false
+	}
+	for key, value := range a.SecureSettings {
+		if otherValue, ok := b.SecureSettings[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	if bytes.Equal(a.Settings, b.Settings) {
+		return true
+	}
+	var aSettings, bSettings any
+	if err := json.Unmarshal(a.Settings, &aSettings); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b.Settings, &bSettings); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(aSettings, bSettings)
+}
+
+// muteTimingsByName returns the time intervals of the configuration, defined either as mute time intervals or as
+// time intervals, by their name.
+func muteTimingsByName(cfg *definitions.PostableUserConfig) map[string][]timeinterval.TimeInterval {
+	intervals := make(map[string][]timeinterval.TimeInterval, len(cfg.AlertmanagerConfig.MuteTimeIntervals)+len(cfg.AlertmanagerConfig.TimeIntervals))
+	for _, mt := range cfg.AlertmanagerConfig.MuteTimeIntervals {
+		intervals[mt.Name] = mt.TimeIntervals
+	}
+	for _, ti := range cfg.AlertmanagerConfig.TimeIntervals {
+		intervals[ti.Name] = ti.TimeIntervals
+	}
+	return intervals
+}
+
+func muteTimingsEqual(a, b []timeinterval.TimeInterval) bool {
+	options := []cmp.Option{
+		cmp.Comparer(func(a, b *time.Location) bool {
+			// Check if both are nil or both have the same string representation
+			return (a == nil && b == nil) || (a != nil && b != nil && a.String() == b.String())
+		}),
+		cmpopts.EquateEmpty(),
+	}
+	return cmp.Equal(a, b, options...)
+}
@@ -0,0 +1,183 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestDiffAlertmanagerConfigurations(t *testing.T) {
+	from, err := Load([]byte(`{
+		"template_files": {"a": "{{ define \"a\" }}A{{ end }}", "b": "{{ define \"b\" }}B{{ end }}"},
+		"alertmanager_config": {
+			"route": {
+				"receiver": "email",
+				"routes": [
+					{"receiver": "slack", "object_matchers": [["team", "=", "ops"]]},
+					{"receiver": "email", "object_matchers": [["team", "=", "dev"]]}
+				]
+			},
+			"mute_time_intervals": [
+				{"name": "weekends", "time_intervals": [{"weekdays": ["saturday", "sunday"]}]},
+				{"name": "nights", "time_intervals": [{"times": [{"start_time": "00:00", "end_time": "06:00"}]}]}
+			],
+			"receivers": [
+				{"name": "email", "grafana_managed_receiver_configs": [{"uid": "email-uid", "name": "email", "type": "email", "settings": {"addresses": "a@example.com", "singleEmail": true}}]},
+				{"name": "slack", "grafana_managed_receiver_configs": [{"uid": "slack-uid", "name": "slack", "type": "slack", "settings": {"recipient": "#ops"}, "secureSettings": {"url": "encrypted-1"}}]},
+				{"name": "webhook", "grafana_managed_receiver_configs": [{"uid": "webhook-uid", "name": "webhook", "type": "webhook", "settings": {"url": "http://localhost"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	to, err := Load([]byte(`{
+		"template_files": {"a": "{{ define \"a\" }}A{{ end }}", "b": "{{ define \"b\" }}B2{{ end }}", "c": "{{ define \"c\" }}C{{ end }}"},
+		"alertmanager_config": {
+			"route": {
+				"receiver": "email",
+				"routes": [
+					{"receiver": "email", "object_matchers": [["team", "=", "ops"]]},
+					{"receiver": "pager", "object_matchers": [["team", "=", "sre"]]},
+					{"receiver": "pager", "object_matchers": [["team", "=", "sre"]]}
+				]
+			},
+			"mute_time_intervals": [
+				{"name": "weekends", "time_intervals": [{"weekdays": ["friday", "saturday", "sunday"]}]}
+			],
+			"time_intervals": [
+				{"name": "holidays", "time_intervals": [{"days_of_month": ["1"], "months": ["january"]}]}
+			],
+			"receivers": [
+				{"name": "email", "grafana_managed_receiver_configs": [{"uid": "email-uid", "name": "email", "type": "email", "settings": {"singleEmail": true, "addresses": "a@example.com"}}]},
+				{"name": "slack", "grafana_managed_receiver_configs": [{"uid": "slack-uid", "name": "slack", "type": "slack", "settings": {"recipient": "#ops"}, "secureSettings": {"url": "encrypted-2"}}]},
+				{"name": "pager", "grafana_managed_receiver_configs": [{"uid": "pager-uid", "name": "pager", "type": "pagerduty", "settings": {}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+
+	diff := DiffAlertmanagerConfigurations(from, to)
+
+	require.Equal(t, definitions.AlertmanagerConfigObjectsDiff{
+		Added:    []string{`root > {team="sre"}`, `root > {team="sre"} #2`},
+		Removed:  []string{`root > {team="dev"}`},
+		Modified: []string{`root > {team="ops"}`},
+	}, diff.Routes)
+	require.Equal(t, definitions.AlertmanagerConfigObjectsDiff{
+		Added:    []string{"pager"},
+		Removed:  []string{"webhook"},
+		Modified: []string{"slack"},
+	}, diff.Receivers)
+	require.Equal(t, definitions.AlertmanagerConfigObjectsDiff{
+		Added:    []string{"c"},
+		Removed:  []string{},
+		Modified: []string{"b"},
+	}, diff.Templates)
+	require.Equal(t, definitions.AlertmanagerConfigObjectsDiff{
+		Added:    []string{"holidays"},
+		Removed:  []string{"nights"},
+		Modified: []string{"weekends"},
+	}, diff.MuteTimings)
+
+	t.Run("identical configurations have no difference", func(t *testing.T) {
+		empty := definitions.AlertmanagerConfigObjectsDiff{Added: []string{}, Removed: []string{}, Modified: []string{}}
+		diff := DiffAlertmanagerConfigurations(to, to)
+		require.Equal(t, definitions.AlertmanagerConfigDiff{
+			Routes:      empty,
+			Receivers:   empty,
+			Templates:   empty,
+			MuteTimings: empty,
+		}, diff)
+	})
+}
+
+func TestMultiOrgAlertmanager_ConfigurationHistory(t *testing.T) {
+	mam := setupMam(t, nil)
+	ctx := context.Background()
+	require.NoError(t, mam.LoadAndSyncAlertmanagersForOrgs(ctx))
+
+	webhookConfig := func(uid, password string) definitions.PostableUserConfig {
+		cfg, err := Load([]byte(`{
+			"alertmanager_config": {
+				"route": {"receiver": "webhook"},
+				"receivers": [{"name": "webhook", "grafana_managed_receiver_configs": [{"uid": "` + uid + `", "name": "webhook", "type": "webhook", "settings": {"url": "http://localhost/hook", "username": "user"}, "secureSettings": {"password": "` + password + `"}}]}]
+			}
+		}`))
+		require.NoError(t, err)
+		return *cfg
+	}
+
+	// The default configuration has id 0, the following ones 1 and 2.
+	require.NoError(t, mam.SaveAndApplyAlertmanagerConfiguration(ctx, 1, webhookConfig("", "secret-1")))
+	current, err := mam.GetAlertmanagerConfiguration(ctx, 1, false)
+	require.NoError(t, err)
+	uid := current.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].UID
+	require.NotEmpty(t, uid)
+	require.NoError(t, mam.SaveAndApplyAlertmanagerConfiguration(ctx, 1, webhookConfig(uid, "secret-2")))
+
+	t.Run("versions are returned newest first", func(t *testing.T) {
+		versions, err := mam.GetAlertmanagerConfigurationVersions(ctx, 1, 0)
+		require.NoError(t, err)
+		require.Len(t, versions, 3)
+		require.Equal(t, []int64{2, 1, 0}, []int64{versions[0].ID, versions[1].ID, versions[2].ID})
+		require.True(t, versions[2].Default)
+		require.NotNil(t, versions[0].LastApplied)
+
+		versions, err = mam.GetAlertmanagerConfigurationVersions(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, int64(2), versions[0].ID)
+	})
+
+	t.Run("diff defaults to the latest version", func(t *testing.T) {
+		diff, err := mam.DiffAlertmanagerConfigurationVersions(ctx, 1, 1, 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), diff.From)
+		require.Equal(t, int64(2), diff.To)
+		require.Equal(t, []string{"webhook"}, diff.Receivers.Modified)
+		require.Empty(t, diff.Routes.Modified)
+
+		_, err = mam.DiffAlertmanagerConfigurationVersions(ctx, 1, 42, 0)
+		require.ErrorIs(t, err, store.ErrNoAlertmanagerConfiguration)
+	})
+
+	t.Run("rollback keeps unchanged secure settings and decrypts changed ones", func(t *testing.T) {
+		cfg, err := mam.GetHistoricalConfigurationForRollback(ctx, 1, 2)
+		require.NoError(t, err)
+		integration := cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0]
+		require.Equal(t, uid, integration.UID)
+		require.Empty(t, integration.SecureSettings)
+
+		cfg, err = mam.GetHistoricalConfigurationForRollback(ctx, 1, 1)
+		require.NoError(t, err)
+		integration = cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0]
+		require.Equal(t, uid, integration.UID)
+		require.Equal(t, map[string]string{"password": "secret-1"}, integration.SecureSettings)
+
+		// The rolled back configuration is saved like a posted one.
+		require.NoError(t, mam.SaveAndApplyAlertmanagerConfiguration(ctx, 1, cfg))
+		diff, err := mam.DiffAlertmanagerConfigurationVersions(ctx, 1, 1, 0)
+		require.NoError(t, err)
+		require.Equal(t, int64(3), diff.To)
+		require.Equal(t, []string{"webhook"}, diff.Receivers.Modified, "the secure setting is encrypted again")
+		require.Empty(t, diff.Routes.Modified)
+	})
+
+	t.Run("rollback creates again integrations that were deleted", func(t *testing.T) {
+		require.NoError(t, mam.SaveAndApplyDefaultConfig(ctx, 1))
+
+		cfg, err := mam.GetHistoricalConfigurationForRollback(ctx, 1, 1)
+		require.NoError(t, err)
+		integration := cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0]
+		require.Empty(t, integration.UID)
+		require.Equal(t, map[string]string{"password": "secret-1"}, integration.SecureSettings)
+	})
+
+	t.Run("rollback of an unknown version fails", func(t *testing.T) {
+		_, err := mam.GetHistoricalConfigurationForRollback(ctx, 1, 42)
+		require.ErrorIs(t, err, store.ErrNoAlertmanagerConfiguration)
+	})
+}
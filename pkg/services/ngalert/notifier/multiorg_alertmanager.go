@@ -100,6 +100,9 @@ type MultiOrgAlertmanager struct {
 
 	metrics *metrics.MultiOrgAlertmanager
 	ns      notifications.Service
+
+	// notificationHistory stores the delivery attempts of the notifications, if it is set.
+	notificationHistory NotificationHistoryStore
 }
 
 type OrgAlertmanagerFactory func(ctx context.Context, orgID int64) (Alertmanager, error)
@@ -155,7 +158,7 @@ func NewMultiOrgAlertmanager(
 	moa.factory = func(ctx context.Context, orgID int64) (Alertmanager, error) {
 		m := metrics.NewAlertmanagerMetrics(moa.metrics.GetOrCreateOrgRegistry(orgID), l)
 		stateStore := NewFileStore(orgID, kvStore)
-		return NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, m, featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting))
+		return NewAlertmanager(ctx, orgID, moa.settings, moa.configStore, stateStore, moa.peer, moa.decryptFn, moa.ns, moa.notificationHistory, m, featureManager.IsEnabled(ctx, featuremgmt.FlagAlertingSimplifiedRouting))
 	}
 
 	for _, opt := range opts {
@@ -401,6 +404,45 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	}
 }
 
+// ClusterMembership provides the live members of the high availability cluster.
+type ClusterMembership interface {
+	// Self returns the name of this instance in the cluster.
+	Self() string
+	// Members returns the names of the live members of the cluster.
+	Members() []string
+}
+
+// ClusterMembership returns the membership of the high availability cluster that the Alertmanagers are part of,
+// or nil if high availability is not configured.
+func (moa *MultiOrgAlertmanager) ClusterMembership() ClusterMembership {
+	switch p := moa.peer.(type) {
+	case *redisPeer:
+		return p
+	case *alertingCluster.Peer:
+		return gossipMembership{peer: p}
+	default:
+		return nil
+	}
+}
+
+// gossipMembership provides the members of the gossip cluster that are not known to be dead.
+type gossipMembership struct {
+	peer *alertingCluster.Peer
+}
+
+func (m gossipMembership) Self() string {
+	return m.peer.Name()
+}
+
+func (m gossipMembership) Members() []string {
+	nodes := m.peer.Peers()
+	members := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		members = append(members, n.Name)
+	}
+	return members
+}
+
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
 // When the organization does not have an active Alertmanager, it returns a ErrNoAlertmanagerForOrg.
 // When the Alertmanager of the organization is not ready, it returns a ErrAlertmanagerNotReady.
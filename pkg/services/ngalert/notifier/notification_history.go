@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// notificationHistorySaveTimeout is the maximum time it takes to save a delivery attempt. The attempt is saved with
+// a context that is detached from the notification pipeline, so that the failed attempts of cancelled notifications
+// are recorded too.
+const notificationHistorySaveTimeout = 10 * time.Second
+
+// NotificationHistoryStore stores the delivery attempts of notifications.
+type NotificationHistoryStore interface {
+	SaveNotificationHistory(ctx context.Context, entry models.NotificationHistoryEntry) error
+}
+
+// NotificationHistoryAdminStore deletes the delivery attempts of notifications.
+type NotificationHistoryAdminStore interface {
+	DeleteNotificationHistoryBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// WithNotificationHistory records the delivery attempts of the notifications of the Alertmanagers to the store.
+func WithNotificationHistory(store NotificationHistoryStore) Option {
+	return func(moa *MultiOrgAlertmanager) {
+		moa.notificationHistory = store
+	}
+}
+
+// notificationHistorian records the delivery attempts of the integrations of an Alertmanager.
+type notificationHistorian struct {
+	orgID int64
+	store NotificationHistoryStore
+	now   func() time.Time
+	log   log.Logger
+}
+
+func newNotificationHistorian(orgID int64, store NotificationHistoryStore, l log.Logger) *notificationHistorian {
+	return &notificationHistorian{
+		orgID: orgID,
+		store: store,
+		now:   time.Now,
+		log:   l,
+	}
+}
+
+// wrap returns the integrations of the receiver with notifiers that record every delivery attempt.
+func (h *notificationHistorian) wrap(receiver string, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		n := &recordingNotifier{
+			historian:   h,
+			receiver:    receiver,
+			integration: integration,
+		}
+		result = append(result, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiver))
+	}
+	return result
+}
+
+// recordingNotifier is a notifier that records the delivery attempts of the notifier of an integration.
+type recordingNotifier struct {
+	historian   *notificationHistorian
+	receiver    string
+	integration *alertingNotify.Integration
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	// captured test notifications are not delivered
+	if CapturesTestNotifications(ctx) {
+		return n.integration.Notify(ctx, alerts...)
+	}
+	ctx, status := withDeliveryStatus(ctx)
+	start := n.historian.now()
+	retry, err := n.integration.Notify(ctx, alerts...)
+	n.historian.record(ctx, n.receiver, n.integration, alerts, start, status.code(), retry, err)
+	return retry, err
+}
+
+func (h *notificationHistorian) record(ctx context.Context, receiver string, integration *alertingNotify.Integration, alerts []*types.Alert, start time.Time, statusCode int, retry bool, notifyErr error) {
+	fingerprints := make([]string, 0, len(alerts))
+	for _, a := range alerts {
+		fingerprints = append(fingerprints, a.Fingerprint().String())
+	}
+	encoded, err := json.Marshal(fingerprints)
+	if err != nil {
+		h.log.Error("Failed to encode the fingerprints of the notified alerts", "error", err)
+		return
+	}
+	groupKey, _ := notify.GroupKey(ctx)
+
+	entry := models.NotificationHistoryEntry{
+		OrgID:             h.orgID,
+		Receiver:          receiver,
+		Integration:       integration.Name(),
+		IntegrationIndex:  integration.Index(),
+		GroupKey:          groupKey,
+		AlertFingerprints: string(encoded),
+		AlertCount:        len(alerts),
+		Status:            models.NotificationHistoryStatusSuccess,
+		StatusCode:        statusCode,
+		Duration:          h.now().Sub(start).Milliseconds(),
+		SentAt:            start.UnixMilli(),
+	}
+	if notifyErr != nil {
+		entry.Status = models.NotificationHistoryStatusFailure
+		entry.ErrorMessage = notifyErr.Error()
+		entry.Retry = retry
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.Background(), notificationHistorySaveTimeout)
+	defer cancel()
+	if err := h.store.SaveNotificationHistory(saveCtx, entry); err != nil {
+		h.log.Error("Failed to save the notification history", "receiver", receiver, "integration", entry.Integration, "error", err)
+	}
+}
+
+type deliveryStatusKey struct{}
+
+// deliveryStatus is the status of a delivery attempt that is reported by the senders of the integrations.
+type deliveryStatus struct {
+	mtx        sync.Mutex
+	statusCode int
+}
+
+func (s *deliveryStatus) code() int {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.statusCode
+}
+
+func withDeliveryStatus(ctx context.Context) (context.Context, *deliveryStatus) {
+	status := &deliveryStatus{}
+	return context.WithValue(ctx, deliveryStatusKey{}, status), status
+}
+
+// recordStatusCode sets the HTTP status code of the delivery attempt, if the attempt is recorded. If the integration
+// sends several requests, the status code of the last response is kept.
+func recordStatusCode(ctx context.Context, statusCode int) {
+	status, ok := ctx.Value(deliveryStatusKey{}).(*deliveryStatus)
+	if !ok {
+		return
+	}
+	status.mtx.Lock()
+	defer status.mtx.Unlock()
+	status.statusCode = statusCode
+}
+
+// DeleteExpiredNotificationHistoryService is a service to delete the notification history that is stored for longer
+// than the configured retention.
+type DeleteExpiredNotificationHistoryService struct {
+	cfg   setting.UnifiedAlertingNotificationHistorySettings
+	store NotificationHistoryAdminStore
+}
+
+func ProvideDeleteExpiredNotificationHistoryService(cfg *setting.Cfg, store *store.DBstore) *DeleteExpiredNotificationHistoryService {
+	return &DeleteExpiredNotificationHistoryService{cfg: cfg.UnifiedAlerting.NotificationHistory, store: store}
+}
+
+// DeleteExpired deletes the expired notification history. It does nothing if the notification history is disabled
+// or if it is kept forever.
+func (s *DeleteExpiredNotificationHistoryService) DeleteExpired(ctx context.Context) (int64, error) {
+	if !s.cfg.Enabled || s.cfg.Retention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteNotificationHistoryBefore(ctx, time.Now().Add(-s.cfg.Retention))
+}
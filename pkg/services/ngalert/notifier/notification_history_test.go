@@ -0,0 +1,166 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNotificationHistorian(t *testing.T) {
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "a"}}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "b"}}},
+	}
+	ctx := notify.WithGroupKey(context.Background(), `{}:{alertname="a"}`)
+
+	newHistorian := func(store NotificationHistoryStore) *notificationHistorian {
+		h := newNotificationHistorian(1, store, log.NewNopLogger())
+		now := time.UnixMilli(1000)
+		h.now = func() time.Time {
+			now = now.Add(250 * time.Millisecond)
+			return now
+		}
+		return h
+	}
+	webhookIntegration := func(ns *notifications.NotificationServiceMock) *alertingNotify.Integration {
+		n := &fakeWebhookNotifier{sender: sender{ns: ns}}
+		return alertingNotify.NewIntegration(n, n, "webhook", 1, "team-a")
+	}
+
+	t.Run("records a successful delivery attempt", func(t *testing.T) {
+		store := &fakeNotificationHistoryStore{}
+		ns := &notifications.NotificationServiceMock{
+			WebhookHandler: func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+				return cmd.Validation(nil, 200)
+			},
+		}
+		integrations := newHistorian(store).wrap("team-a", []*alertingNotify.Integration{webhookIntegration(ns)})
+		require.Len(t, integrations, 1)
+		require.Equal(t, "webhook", integrations[0].Name())
+		require.Equal(t, 1, integrations[0].Index())
+
+		retry, err := integrations[0].Notify(ctx, alerts...)
+		require.NoError(t, err)
+		require.False(t, retry)
+
+		require.Len(t, store.entries, 1)
+		require.Equal(t, models.NotificationHistoryEntry{
+			OrgID:             1,
+			Receiver:          "team-a",
+			Integration:       "webhook",
+			IntegrationIndex:  1,
+			GroupKey:          `{}:{alertname="a"}`,
+			AlertFingerprints: `["` + alerts[0].Fingerprint().String() + `","` + alerts[1].Fingerprint().String() + `"]`,
+			AlertCount:        2,
+			Status:            models.NotificationHistoryStatusSuccess,
+			StatusCode:        200,
+			Duration:          250,
+			SentAt:            1250,
+		}, store.entries[0])
+	})
+
+	t.Run("records a failed delivery attempt", func(t *testing.T) {
+		store := &fakeNotificationHistoryStore{}
+		ns := &notifications.NotificationServiceMock{
+			WebhookHandler: func(_ context.Context, cmd *notifications.SendWebhookSync) error {
+				if err := cmd.Validation(nil, 503); err != nil {
+					return err
+				}
+				return errors.New("webhook response status 503 Service Unavailable")
+			},
+		}
+		integrations := newHistorian(store).wrap("team-a", []*alertingNotify.Integration{webhookIntegration(ns)})
+
+		retry, err := integrations[0].Notify(ctx, alerts...)
+		require.Error(t, err)
+		require.True(t, retry)
+
+		require.Len(t, store.entries, 1)
+		require.Equal(t, models.NotificationHistoryStatusFailure, store.entries[0].Status)
+		require.Equal(t, 503, store.entries[0].StatusCode)
+		require.Equal(t, "webhook response status 503 Service Unavailable", store.entries[0].ErrorMessage)
+		require.True(t, store.entries[0].Retry)
+	})
+
+	t.Run("does not fail the notification if the attempt cannot be saved", func(t *testing.T) {
+		store := &fakeNotificationHistoryStore{err: errors.New("database is down")}
+		ns := &notifications.NotificationServiceMock{}
+		integrations := newHistorian(store).wrap("team-a", []*alertingNotify.Integration{webhookIntegration(ns)})
+
+		_, err := integrations[0].Notify(ctx, alerts...)
+		require.NoError(t, err)
+	})
+}
+
+func TestDeleteExpiredNotificationHistoryService(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      setting.UnifiedAlertingNotificationHistorySettings
+		expected bool
+	}{
+		{name: "disabled", cfg: setting.UnifiedAlertingNotificationHistorySettings{Enabled: false, Retention: time.Hour}},
+		{name: "kept forever", cfg: setting.UnifiedAlertingNotificationHistorySettings{Enabled: true}},
+		{name: "enabled with retention", cfg: setting.UnifiedAlertingNotificationHistorySettings{Enabled: true, Retention: time.Hour}, expected: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakeNotificationHistoryStore{}
+			s := &DeleteExpiredNotificationHistoryService{cfg: tc.cfg, store: store}
+			_, err := s.DeleteExpired(context.Background())
+			require.NoError(t, err)
+			if !tc.expected {
+				require.True(t, store.deletedBefore.IsZero())
+				return
+			}
+			require.WithinDuration(t, time.Now().Add(-time.Hour), store.deletedBefore, time.Minute)
+		})
+	}
+}
+
+type fakeNotificationHistoryStore struct {
+	entries       []models.NotificationHistoryEntry
+	deletedBefore time.Time
+	err           error
+}
+
+func (f *fakeNotificationHistoryStore) SaveNotificationHistory(_ context.Context, entry models.NotificationHistoryEntry) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+func (f *fakeNotificationHistoryStore) DeleteNotificationHistoryBefore(_ context.Context, before time.Time) (int64, error) {
+	f.deletedBefore = before
+	return 0, f.err
+}
+
+// fakeWebhookNotifier sends a webhook for every notification, like the notifiers of most integrations.
+type fakeWebhookNotifier struct {
+	sender receivers.WebhookSender
+}
+
+func (n *fakeWebhookNotifier) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	if err := n.sender.SendWebhook(ctx, &receivers.SendWebhookSettings{URL: "http://localhost"}); err != nil {
+		return true, err
+	}
+	return false, nil
+}
+
+func (n *fakeWebhookNotifier) SendResolved() bool {
+	return true
+}
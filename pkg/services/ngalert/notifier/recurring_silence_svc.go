@@ -0,0 +1,397 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrRecurringSilenceNotFound    = errutil.NotFound("alerting.notifications.recurringSilences.notFound")
+	ErrRecurringSilenceBadRequest  = errutil.BadRequest("alerting.notifications.recurringSilences.badRequest")
+	ErrRecurringSilenceProvisioned = errutil.Conflict("alerting.notifications.recurringSilences.provisioned")
+)
+
+// RecurringSilenceStore is the store of the recurring silences.
+type RecurringSilenceStore interface {
+	GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error)
+	ListRecurringSilences(ctx context.Context, orgID int64) ([]*models.RecurringSilence, error)
+	GetAllRecurringSilences(ctx context.Context) ([]*models.RecurringSilence, error)
+	SaveRecurringSilence(ctx context.Context, rs *models.RecurringSilence) error
+	DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error)
+	PurgeRecurringSilence(ctx context.Context, rs *models.RecurringSilence) (bool, error)
+	UpdateRecurringSilenceMaterialization(ctx context.Context, rs *models.RecurringSilence, previous models.RecurringSilenceMaterialization) (bool, error)
+}
+
+// RecurringSilenceService manages the silences that repeat on a schedule. It materializes the windows of the
+// recurring silences into Alertmanager silences ahead of time, and expires the silences of the windows that are
+// no longer part of a recurring silence when it is changed or deleted.
+//
+// Changes are only written to the store, and the silences are reconciled by Run. This way changes that are made
+// while the Alertmanagers are not running, such as by file provisioning, take effect too. If silences is not nil,
+// the changed recurring silence is also reconciled right away.
+//
+// Access to a recurring silence is authorized as access to the silences it creates.
+type RecurringSilenceService struct {
+	authz    SilenceAccessControlService
+	store    RecurringSilenceStore
+	silences SilenceStore
+	cfg      setting.UnifiedAlertingRecurringSilencesSettings
+	now      func() time.Time
+	log      log.Logger
+}
+
+func NewRecurringSilenceService(
+	authz SilenceAccessControlService,
+	store RecurringSilenceStore,
+	silences SilenceStore,
+	cfg setting.UnifiedAlertingRecurringSilencesSettings,
+	log log.Logger,
+) *RecurringSilenceService {
+	return &RecurringSilenceService{
+		authz:    authz,
+		store:    store,
+		silences: silences,
+		cfg:      cfg,
+		now:      time.Now,
+		log:      log,
+	}
+}
+
+// GetRecurringSilence returns the recurring silence by its UID.
+func (s *RecurringSilenceService) GetRecurringSilence(ctx context.Context, user identity.Requester, uid string) (*models.RecurringSilence, error) {
+	rs, err := s.get(ctx, user.GetOrgID(), uid)
+	if err != nil {
+		return nil, err
+	}
+	silence := rs.ToSilence(models.RecurringSilenceWindow{})
+	if err := s.authz.AuthorizeReadSilence(ctx, user, &silence); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// ListRecurringSilences returns the recurring silences of the organization of the user that the user can read.
+func (s *RecurringSilenceService) ListRecurringSilences(ctx context.Context, user identity.Requester) ([]*models.RecurringSilence, error) {
+	all, err := s.store.ListRecurringSilences(ctx, user.GetOrgID())
+	if err != nil {
+		return nil, err
+	}
+	bySilence := make(map[*models.Silence]*models.RecurringSilence, len(all))
+	silences := make([]*models.Silence, 0, len(all))
+	for _, rs := range all {
+		silence := rs.ToSilence(models.RecurringSilenceWindow{})
+		bySilence[&silence] = rs
+		silences = append(silences, &silence)
+	}
+	allowed, err := s.authz.FilterByAccess(ctx, user, silences...)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*models.RecurringSilence, 0, len(allowed))
+	for _, silence := range allowed {
+		result = append(result, bySilence[silence])
+	}
+	return result, nil
+}
+
+// SaveRecurringSilence creates the recurring silence, or updates it if a recurring silence with the same UID exists,
+// and returns its UID. Recurring silences that are provisioned from files cannot be updated.
+func (s *RecurringSilenceService) SaveRecurringSilence(ctx context.Context, user identity.Requester, rs models.RecurringSilence) (string, error) {
+	rs.OrgID = user.GetOrgID()
+	rs.Provenance = models.ProvenanceNone
+	if rs.CreatedBy == "" {
+		rs.CreatedBy = user.GetLogin()
+	}
+	if err := s.validate(&rs); err != nil {
+		return "", err
+	}
+
+	silence := rs.ToSilence(models.RecurringSilenceWindow{})
+	var existing *models.RecurringSilence
+	if rs.UID != "" {
+		var err error
+		existing, err = s.get(ctx, rs.OrgID, rs.UID)
+		if err != nil && !errors.Is(err, ErrRecurringSilenceNotFound) {
+			return "", err
+		}
+	}
+	if existing == nil {
+		if err := s.authz.AuthorizeCreateSilence(ctx, user, &silence); err != nil {
+			return "", err
+		}
+		if err := s.create(ctx, &rs); err != nil {
+			return "", err
+		}
+		return rs.UID, nil
+	}
+
+	if existing.Provenance != models.ProvenanceNone {
+		return "", WithPublicError(ErrRecurringSilenceProvisioned.Errorf("recurring silence %s is provisioned and cannot be changed", rs.UID))
+	}
+	existingSilence := existing.ToSilence(models.RecurringSilenceWindow{})
+	if err := s.authz.AuthorizeUpdateSilence(ctx, user, &existingSilence); err != nil {
+		return "", err
+	}
+	if err := s.authz.AuthorizeUpdateSilence(ctx, user, &silence); err != nil {
+		return "", err
+	}
+	if err := validateSilenceUpdate(&existingSilence, silence); err != nil {
+		return "", err
+	}
+	if err := s.save(ctx, &rs); err != nil {
+		return "", err
+	}
+	return rs.UID, nil
+}
+
+// DeleteRecurringSilence deletes the recurring silence and expires its materialized silences. Recurring silences that
+// are provisioned from files cannot be deleted.
+func (s *RecurringSilenceService) DeleteRecurringSilence(ctx context.Context, user identity.Requester, uid string) error {
+	rs, err := s.GetRecurringSilence(ctx, user, uid)
+	if err != nil {
+		return err
+	}
+	if rs.Provenance != models.ProvenanceNone {
+		return WithPublicError(ErrRecurringSilenceProvisioned.Errorf("recurring silence %s is provisioned and cannot be deleted", uid))
+	}
+	silence := rs.ToSilence(models.RecurringSilenceWindow{})
+	if err := s.authz.AuthorizeUpdateSilence(ctx, user, &silence); err != nil {
+		return err
+	}
+	return s.delete(ctx, rs.OrgID, uid)
+}
+
+// ProvisionRecurringSilence creates or updates the recurring silence of the organization with the provenance of
+// files, without access control.
+func (s *RecurringSilenceService) ProvisionRecurringSilence(ctx context.Context, orgID int64, rs models.RecurringSilence) error {
+	rs.OrgID = orgID
+	rs.Provenance = models.ProvenanceFile
+	if err := s.validate(&rs); err != nil {
+		return err
+	}
+	if rs.UID == "" {
+		return WithPublicError(ErrRecurringSilenceBadRequest.Errorf("recurring silence requires a UID to be provisioned"))
+	}
+	// Files are provisioned on every start, an unchanged recurring silence keeps its silences.
+	existing, err := s.store.GetRecurringSilence(ctx, orgID, rs.UID)
+	if err != nil && !errors.Is(err, models.ErrRecurringSilenceNotFound) {
+		return err
+	}
+	if existing != nil && existing.HasSameDefinition(&rs) {
+		return nil
+	}
+	return s.save(ctx, &rs)
+}
+
+// UnprovisionRecurringSilence deletes the recurring silence of the organization without access control. It does
+// nothing if the recurring silence does not exist.
+func (s *RecurringSilenceService) UnprovisionRecurringSilence(ctx context.Context, orgID int64, uid string) error {
+	err := s.delete(ctx, orgID, uid)
+	if errors.Is(err, ErrRecurringSilenceNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Run materializes the windows of the recurring silences of all organizations at the configured interval until the
+// context is cancelled.
+func (s *RecurringSilenceService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		s.MaterializeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// MaterializeAll materializes the windows of the recurring silences of all organizations.
+func (s *RecurringSilenceService) MaterializeAll(ctx context.Context) {
+	all, err := s.store.GetAllRecurringSilences(ctx)
+	if err != nil {
+		s.log.Error("Failed to get recurring silences", "error", err)
+		return
+	}
+	for _, rs := range all {
+		if err := s.materialize(ctx, rs); err != nil {
+			s.log.Error("Failed to materialize recurring silence", "orgID", rs.OrgID, "uid", rs.UID, "error", err)
+		}
+	}
+}
+
+// materialize reconciles the silences of the recurring silence. The silences of a previous version or of a deleted
+// recurring silence are expired first. Then the silences of the windows that start within the horizon and have not
+// been created yet are created. The windows are claimed in the store before the silences are created, so that only
+// one instance creates them when Grafana runs in high availability mode.
+func (s *RecurringSilenceService) materialize(ctx context.Context, rs *models.RecurringSilence) error {
+	now := s.now()
+	previous := rs.Materialization
+	if rs.Deleted || previous.Version != rs.Version {
+		if err := s.expire(ctx, rs.OrgID, previous.Silences); err != nil {
+			return err
+		}
+		if rs.Deleted {
+			_, err := s.store.PurgeRecurringSilence(ctx, rs)
+			return err
+		}
+		rs.Materialization = models.RecurringSilenceMaterialization{Version: rs.Version}
+	}
+
+	// Windows that ended while the recurring silence was not materialized, for example because Grafana was down,
+	// are skipped. A window that is in progress is materialized from now on.
+	from := now.Add(-rs.Duration)
+	if materializedUntil := time.Unix(rs.Materialization.Until, 0); materializedUntil.After(from) {
+		from = materializedUntil
+	}
+	until := now.Add(s.cfg.Horizon)
+	windows, err := rs.Windows(from, until)
+	if err != nil {
+		return err
+	}
+
+	active := make([]models.MaterializedSilence, 0, len(rs.Materialization.Silences)+len(windows))
+	for _, silence := range rs.Materialization.Silences {
+		if silence.EndsAt.After(now) {
+			active = append(active, silence)
+		}
+	}
+	if len(windows) == 0 && len(active) == len(rs.Materialization.Silences) && rs.Materialization.Version == previous.Version {
+		return nil
+	}
+
+	claimedUntil := until.Unix()
+	if len(windows) > 0 {
+		// The windows after the last one are materialized on the next run if the limit of windows is reached.
+		claimedUntil = windows[len(windows)-1].Start.Unix()
+	}
+	rs.Materialization.Until = claimedUntil
+	rs.Materialization.Silences = active
+	claimed, err := s.store.UpdateRecurringSilenceMaterialization(ctx, rs, previous)
+	if err != nil || !claimed {
+		return err
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+
+	claim := rs.Materialization
+	created := make([]models.MaterializedSilence, 0, len(windows))
+	for i, window := range windows {
+		if !window.End.After(now) {
+			continue
+		}
+		if window.Start.Before(now) {
+			window.Start = now
+		}
+		silence := rs.ToSilence(window)
+		id, err := s.silences.CreateSilence(ctx, rs.OrgID, silence)
+		if err != nil {
+			// The windows from this one are materialized again on the next run.
+			s.log.Error("Failed to create silence of recurring silence", "orgID", rs.OrgID, "uid", rs.UID, "start", window.Start, "error", err)
+			rs.Materialization.Until = from.Unix()
+			if i > 0 {
+				rs.Materialization.Until = windows[i-1].Start.Unix()
+			}
+			break
+		}
+		created = append(created, models.MaterializedSilence{ID: id, EndsAt: window.End})
+	}
+	rs.Materialization.Silences = append(active, created...)
+	saved, err := s.store.UpdateRecurringSilenceMaterialization(ctx, rs, claim)
+	if err == nil && !saved {
+		err = errors.New("recurring silence was changed while its windows were materialized")
+	}
+	if err != nil {
+		// The recurring silence does not track the created silences, expire them so that they are not left behind.
+		if expireErr := s.expire(ctx, rs.OrgID, created); expireErr != nil {
+			s.log.Warn("Failed to expire silences of recurring silence", "orgID", rs.OrgID, "uid", rs.UID, "error", expireErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *RecurringSilenceService) validate(rs *models.RecurringSilence) error {
+	if err := rs.Validate(); err != nil {
+		return WithPublicError(ErrRecurringSilenceBadRequest.Errorf("%s", err))
+	}
+	return nil
+}
+
+func (s *RecurringSilenceService) get(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error) {
+	rs, err := s.store.GetRecurringSilence(ctx, orgID, uid)
+	if errors.Is(err, models.ErrRecurringSilenceNotFound) {
+		return nil, WithPublicError(ErrRecurringSilenceNotFound.Errorf("recurring silence %s not found", uid))
+	}
+	return rs, err
+}
+
+func (s *RecurringSilenceService) create(ctx context.Context, rs *models.RecurringSilence) error {
+	if rs.UID == "" {
+		rs.UID = util.GenerateShortUID()
+	}
+	return s.save(ctx, rs)
+}
+
+func (s *RecurringSilenceService) save(ctx context.Context, rs *models.RecurringSilence) error {
+	rs.Updated = s.now()
+	if err := s.store.SaveRecurringSilence(ctx, rs); err != nil {
+		return err
+	}
+	s.reconcile(ctx, rs)
+	return nil
+}
+
+func (s *RecurringSilenceService) delete(ctx context.Context, orgID int64, uid string) error {
+	deleted, err := s.store.DeleteRecurringSilence(ctx, orgID, uid)
+	if errors.Is(err, models.ErrRecurringSilenceNotFound) {
+		return WithPublicError(ErrRecurringSilenceNotFound.Errorf("recurring silence %s not found", uid))
+	}
+	if err != nil {
+		return err
+	}
+	s.reconcile(ctx, deleted)
+	return nil
+}
+
+// reconcile materializes the recurring silence right away if the service can access the silences. Otherwise, or if
+// it fails, the recurring silence is materialized by the next run.
+func (s *RecurringSilenceService) reconcile(ctx context.Context, rs *models.RecurringSilence) {
+	if s.silences == nil {
+		return
+	}
+	if err := s.materialize(ctx, rs); err != nil {
+		s.log.Warn("Failed to materialize recurring silence, will be retried", "orgID", rs.OrgID, "uid", rs.UID, "error", err)
+	}
+}
+
+// expire expires the materialized silences that have not ended yet. It returns an error if the Alertmanager of the
+// organization is not available, so that the silences are expired later. Other errors, for example if a silence was
+// already expired by a user, are logged.
+func (s *RecurringSilenceService) expire(ctx context.Context, orgID int64, silences []models.MaterializedSilence) error {
+	now := s.now()
+	for _, silence := range silences {
+		if !silence.EndsAt.After(now) {
+			continue
+		}
+		err := s.silences.DeleteSilence(ctx, orgID, silence.ID)
+		if errors.Is(err, ErrAlertmanagerNotFound) || errors.Is(err, ErrAlertmanagerConflict) {
+			return err
+		}
+		if err != nil && !errors.Is(err, ErrSilenceNotFound) {
+			s.log.Warn("Failed to expire silence of recurring silence", "orgID", orgID, "silenceID", silence.ID, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	ngfakes "github.com/grafana/grafana/pkg/services/ngalert/tests/fakes"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestRecurringSilenceService(t *testing.T) {
+	user := ac.BackgroundUser("test", 1, org.RoleNone, nil)
+	// Wednesday
+	start := time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC)
+
+	cfg := setting.UnifiedAlertingRecurringSilencesSettings{
+		Horizon:  7 * 24 * time.Hour,
+		Interval: time.Minute,
+	}
+	newService := func() (*RecurringSilenceService, *fakeRecurringSilenceStore, *ngfakes.FakeSilenceStore, *time.Time) {
+		store := newFakeRecurringSilenceStore()
+		silences := &ngfakes.FakeSilenceStore{Silences: map[string]*models.Silence{}}
+		svc := NewRecurringSilenceService(&fakes.FakeSilenceService{}, store, silences, cfg, log.NewNopLogger())
+		now := start
+		svc.now = func() time.Time { return now }
+		return svc, store, silences, &now
+	}
+	maintenance := func() models.RecurringSilence {
+		return models.RecurringSilence{
+			UID: "maintenance",
+			Matchers: amv2.Matchers{
+				{Name: util.Pointer("env"), Value: util.Pointer("prod"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)},
+			},
+			Schedule: "0 2 * * SUN",
+			Duration: 2 * time.Hour,
+			Comment:  "Weekly maintenance",
+		}
+	}
+
+	t.Run("creates the silences of the windows within the horizon", func(t *testing.T) {
+		svc, store, silences, now := newService()
+		uid, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.NoError(t, err)
+		require.Equal(t, "maintenance", uid)
+
+		require.Len(t, silences.Silences, 1)
+		rs := store.recurringSilences["maintenance"]
+		require.Len(t, rs.Materialization.Silences, 1)
+		silence := silences.Silences[rs.Materialization.Silences[0].ID]
+		require.Equal(t, time.Date(2024, 6, 9, 2, 0, 0, 0, time.UTC), time.Time(*silence.StartsAt))
+		require.Equal(t, time.Date(2024, 6, 9, 4, 0, 0, 0, time.UTC), time.Time(*silence.EndsAt))
+		require.Equal(t, "grafana_test", *silence.CreatedBy)
+		require.Equal(t, "Weekly maintenance (recurring silence maintenance)", *silence.Comment)
+
+		svc.MaterializeAll(context.Background())
+		require.Len(t, silences.Silences, 1, "the window was already materialized")
+
+		*now = start.Add(5 * 24 * time.Hour)
+		svc.MaterializeAll(context.Background())
+		require.Len(t, silences.Silences, 2)
+		require.Len(t, store.recurringSilences["maintenance"].Materialization.Silences, 1, "the ended silence is no longer tracked")
+	})
+
+	t.Run("materializes the window in progress from now", func(t *testing.T) {
+		svc, _, silences, now := newService()
+		*now = time.Date(2024, 6, 9, 3, 0, 0, 0, time.UTC)
+		_, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.NoError(t, err)
+
+		require.Len(t, silences.Silences, 2)
+		var startsAt []time.Time
+		for _, silence := range silences.Silences {
+			startsAt = append(startsAt, time.Time(*silence.StartsAt))
+		}
+		require.ElementsMatch(t, []time.Time{*now, time.Date(2024, 6, 16, 2, 0, 0, 0, time.UTC)}, startsAt)
+	})
+
+	t.Run("does not create silences if another instance claimed the windows", func(t *testing.T) {
+		svc, store, silences, _ := newService()
+		rs := maintenance()
+		rs.OrgID = 1
+		require.NoError(t, store.SaveRecurringSilence(context.Background(), &rs))
+
+		stale := rs
+		store.recurringSilences["maintenance"].Materialization = models.RecurringSilenceMaterialization{Version: 1, Until: 1}
+		require.NoError(t, svc.materialize(context.Background(), &stale))
+		require.Empty(t, silences.Silences)
+	})
+
+	t.Run("expires the silences of the previous version on update", func(t *testing.T) {
+		svc, store, silences, _ := newService()
+		_, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.NoError(t, err)
+		previousID := store.recurringSilences["maintenance"].Materialization.Silences[0].ID
+
+		update := maintenance()
+		update.Schedule = "0 3 * * SAT"
+		_, err = svc.SaveRecurringSilence(context.Background(), user, update)
+		require.NoError(t, err)
+
+		require.NotContains(t, silences.Silences, previousID)
+		require.Len(t, silences.Silences, 1)
+		rs := store.recurringSilences["maintenance"]
+		require.Equal(t, int64(2), rs.Version)
+		silence := silences.Silences[rs.Materialization.Silences[0].ID]
+		require.Equal(t, time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC), time.Time(*silence.StartsAt))
+	})
+
+	t.Run("expires the silences on delete", func(t *testing.T) {
+		svc, store, silences, _ := newService()
+		_, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.NoError(t, err)
+
+		require.NoError(t, svc.DeleteRecurringSilence(context.Background(), user, "maintenance"))
+		require.Empty(t, silences.Silences)
+		require.Empty(t, store.recurringSilences, "the deleted recurring silence is purged once its silences are expired")
+
+		err = svc.DeleteRecurringSilence(context.Background(), user, "maintenance")
+		require.ErrorIs(t, err, ErrRecurringSilenceNotFound)
+	})
+
+	t.Run("rejects invalid recurring silences", func(t *testing.T) {
+		svc, _, _, _ := newService()
+		rs := maintenance()
+		rs.Schedule = "every sunday"
+		_, err := svc.SaveRecurringSilence(context.Background(), user, rs)
+		require.ErrorIs(t, err, ErrRecurringSilenceBadRequest)
+	})
+
+	t.Run("provisioned recurring silences cannot be changed in the API", func(t *testing.T) {
+		svc, store, _, _ := newService()
+		require.NoError(t, svc.ProvisionRecurringSilence(context.Background(), 1, maintenance()))
+		require.Equal(t, models.ProvenanceFile, store.recurringSilences["maintenance"].Provenance)
+		require.NoError(t, svc.ProvisionRecurringSilence(context.Background(), 1, maintenance()))
+		require.Equal(t, int64(1), store.recurringSilences["maintenance"].Version, "an unchanged recurring silence is not saved again")
+
+		_, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.ErrorIs(t, err, ErrRecurringSilenceProvisioned)
+		require.ErrorIs(t, svc.DeleteRecurringSilence(context.Background(), user, "maintenance"), ErrRecurringSilenceProvisioned)
+
+		require.NoError(t, svc.UnprovisionRecurringSilence(context.Background(), 1, "maintenance"))
+		require.NoError(t, svc.UnprovisionRecurringSilence(context.Background(), 1, "maintenance"))
+		require.Empty(t, store.recurringSilences)
+	})
+
+	t.Run("reconciles changes made without access to the silences on the next run", func(t *testing.T) {
+		svc, store, silences, _ := newService()
+		_, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.NoError(t, err)
+		previousID := store.recurringSilences["maintenance"].Materialization.Silences[0].ID
+
+		// The provisioning service writes the recurring silences before the Alertmanagers run.
+		provisioning := NewRecurringSilenceService(&fakes.FakeSilenceService{}, store, nil, cfg, log.NewNopLogger())
+		update := maintenance()
+		update.Schedule = "0 3 * * SAT"
+		require.NoError(t, provisioning.ProvisionRecurringSilence(context.Background(), 1, update))
+		require.Contains(t, silences.Silences, previousID, "the silences are not reconciled yet")
+
+		svc.MaterializeAll(context.Background())
+		require.NotContains(t, silences.Silences, previousID)
+		require.Len(t, silences.Silences, 1)
+		rs := store.recurringSilences["maintenance"]
+		require.Equal(t, rs.Version, rs.Materialization.Version)
+
+		require.NoError(t, provisioning.UnprovisionRecurringSilence(context.Background(), 1, "maintenance"))
+		require.Len(t, silences.Silences, 1)
+		_, err = svc.GetRecurringSilence(context.Background(), user, "maintenance")
+		require.ErrorIs(t, err, ErrRecurringSilenceNotFound)
+
+		svc.MaterializeAll(context.Background())
+		require.Empty(t, silences.Silences)
+		require.Empty(t, store.recurringSilences)
+	})
+
+	t.Run("retries to expire the silences if the Alertmanager is not ready", func(t *testing.T) {
+		svc, store, silences, _ := newService()
+		_, err := svc.SaveRecurringSilence(context.Background(), user, maintenance())
+		require.NoError(t, err)
+
+		notReady := &notReadySilenceStore{FakeSilenceStore: silences}
+		svc.silences = notReady
+		require.NoError(t, svc.DeleteRecurringSilence(context.Background(), user, "maintenance"))
+		require.Len(t, silences.Silences, 1)
+		require.Contains(t, store.recurringSilences, "maintenance", "the deleted recurring silence is kept until its silences are expired")
+
+		svc.silences = silences
+		svc.MaterializeAll(context.Background())
+		require.Empty(t, silences.Silences)
+		require.Empty(t, store.recurringSilences)
+	})
+}
+
+type notReadySilenceStore struct {
+	*ngfakes.FakeSilenceStore
+}
+
+func (s *notReadySilenceStore) DeleteSilence(_ context.Context, orgID int64, _ string) error {
+	return WithPublicError(ErrAlertmanagerConflict.Errorf("Alertmanager is not ready for org %d", orgID))
+}
+
+// fakeRecurringSilenceStore is an in-memory store of the recurring silences of a single organization.
+type fakeRecurringSilenceStore struct {
+	recurringSilences map[string]*models.RecurringSilence
+	nextID            int64
+}
+
+func newFakeRecurringSilenceStore() *fakeRecurringSilenceStore {
+	return &fakeRecurringSilenceStore{recurringSilences: map[string]*models.RecurringSilence{}}
+}
+
+func (f *fakeRecurringSilenceStore) GetRecurringSilence(_ context.Context, _ int64, uid string) (*models.RecurringSilence, error) {
+	rs, ok := f.recurringSilences[uid]
+	if !ok || rs.Deleted {
+		return nil, models.ErrRecurringSilenceNotFound
+	}
+	c := *rs
+	return &c, nil
+}
+
+func (f *fakeRecurringSilenceStore) ListRecurringSilences(_ context.Context, _ int64) ([]*models.RecurringSilence, error) {
+	result := make([]*models.RecurringSilence, 0, len(f.recurringSilences))
+	for _, rs := range f.recurringSilences {
+		if rs.Deleted {
+			continue
+		}
+		c := *rs
+		result = append(result, &c)
+	}
+	return result, nil
+}
+
+func (f *fakeRecurringSilenceStore) GetAllRecurringSilences(_ context.Context) ([]*models.RecurringSilence, error) {
+	result := make([]*models.RecurringSilence, 0, len(f.recurringSilences))
+	for _, rs := range f.recurringSilences {
+		c := *rs
+		result = append(result, &c)
+	}
+	return result, nil
+}
+
+func (f *fakeRecurringSilenceStore) SaveRecurringSilence(_ context.Context, rs *models.RecurringSilence) error {
+	rs.Deleted = false
+	if existing, ok := f.recurringSilences[rs.UID]; ok {
+		rs.ID = existing.ID
+		rs.Version = existing.Version + 1
+		rs.Materialization = existing.Materialization
+	} else {
+		f.nextID++
+		rs.ID = f.nextID
+		rs.Version = 1
+		rs.Materialization = models.RecurringSilenceMaterialization{}
+	}
+	c := *rs
+	f.recurringSilences[rs.UID] = &c
+	return nil
+}
+
+func (f *fakeRecurringSilenceStore) DeleteRecurringSilence(_ context.Context, _ int64, uid string) (*models.RecurringSilence, error) {
+	existing, ok := f.recurringSilences[uid]
+	if !ok || existing.Deleted {
+		return nil, models.ErrRecurringSilenceNotFound
+	}
+	existing.Deleted = true
+	existing.Version++
+	c := *existing
+	return &c, nil
+}
+
+func (f *fakeRecurringSilenceStore) PurgeRecurringSilence(_ context.Context, rs *models.RecurringSilence) (bool, error) {
+	existing, ok := f.recurringSilences[rs.UID]
+	if !ok || !existing.Deleted || existing.Version != rs.Version {
+		return false, nil
+	}
+	delete(f.recurringSilences, rs.UID)
+	return true, nil
+}
+
+func (f *fakeRecurringSilenceStore) UpdateRecurringSilenceMaterialization(_ context.Context, rs *models.RecurringSilence, previous models.RecurringSilenceMaterialization) (bool, error) {
+	existing, ok := f.recurringSilences[rs.UID]
+	if !ok || existing.Version != rs.Version || existing.Materialization.Version != previous.Version || existing.Materialization.Until != previous.Until {
+		return false, nil
+	}
+	existing.Materialization = models.RecurringSilenceMaterialization{
+		Version:  rs.Materialization.Version,
+		Until:    rs.Materialization.Until,
+		Silences: append([]models.MaterializedSilence(nil), rs.Materialization.Silences...),
+	}
+	return true, nil
+}
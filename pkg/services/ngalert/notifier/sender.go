@@ -2,9 +2,12 @@ package notifier
 
 import (
 	"context"
+	"fmt"
+	"net/http"
 
 	"github.com/grafana/alerting/receivers"
 
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/notifications"
 )
 
@@ -12,7 +15,16 @@ type sender struct {
 	ns notifications.Service
 }
 
+// emailRenderer renders emails without sending them.
+type emailRenderer interface {
+	RenderEmailCommand(cmd *notifications.SendEmailCommand) (*notifications.Message, error)
+}
+
 func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSettings) error {
+	if captureTestNotification(ctx, capturedWebhook(cmd)) {
+		return nil
+	}
+	validation := cmd.Validation
 	return s.ns.SendWebhookSync(ctx, &notifications.SendWebhookSync{
 		Url:         cmd.URL,
 		User:        cmd.User,
@@ -21,20 +33,91 @@ func (s sender) SendWebhook(ctx context.Context, cmd *receivers.SendWebhookSetti
 		HttpMethod:  cmd.HTTPMethod,
 		HttpHeader:  cmd.HTTPHeader,
 		ContentType: cmd.ContentType,
-		Validation:  cmd.Validation,
+		Validation: func(body []byte, statusCode int) error {
+			// the status code is recorded in the notification history
+			recordStatusCode(ctx, statusCode)
+			if validation == nil {
+				return nil
+			}
+			return validation(body, statusCode)
+		},
 	})
 }
 
 func (s sender) SendEmail(ctx context.Context, cmd *receivers.SendEmailSettings) error {
+	emailCmd := notifications.SendEmailCommand{
+		To:            cmd.To,
+		SingleEmail:   cmd.SingleEmail,
+		Template:      cmd.Template,
+		Subject:       cmd.Subject,
+		Data:          cmd.Data,
+		ReplyTo:       cmd.ReplyTo,
+		EmbeddedFiles: cmd.EmbeddedFiles,
+	}
+	if CapturesTestNotifications(ctx) {
+		captured, err := s.captureEmail(&emailCmd)
+		if err != nil {
+			return err
+		}
+		captureTestNotification(ctx, captured)
+		return nil
+	}
 	return s.ns.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
-		SendEmailCommand: notifications.SendEmailCommand{
-			To:            cmd.To,
-			SingleEmail:   cmd.SingleEmail,
-			Template:      cmd.Template,
-			Subject:       cmd.Subject,
-			Data:          cmd.Data,
-			ReplyTo:       cmd.ReplyTo,
-			EmbeddedFiles: cmd.EmbeddedFiles,
-		},
+		SendEmailCommand: emailCmd,
 	})
 }
+
+// captureEmail renders the email, if the notification service can render emails, instead of sending it.
+func (s sender) captureEmail(cmd *notifications.SendEmailCommand) (apimodels.TestReceiverCapturedNotification, error) {
+	captured := apimodels.TestReceiverCapturedNotification{
+		Kind:  "email",
+		To:    cmd.To,
+		Title: cmd.Subject,
+	}
+	renderer, ok := s.ns.(emailRenderer)
+	if !ok {
+		return captured, nil
+	}
+	msg, err := renderer.RenderEmailCommand(cmd)
+	if err != nil {
+		return captured, fmt.Errorf("failed to render email: %w", err)
+	}
+	captured.Title = msg.Subject
+	if body, ok := msg.Body["text/html"]; ok {
+		captured.Body = body
+	} else {
+		captured.Body = msg.Body["text/plain"]
+	}
+	return captured, nil
+}
+
+// capturedWebhook returns the HTTP request that the notification service sends for the webhook.
+func capturedWebhook(cmd *receivers.SendWebhookSettings) apimodels.TestReceiverCapturedNotification {
+	method := cmd.HTTPMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+	contentType := cmd.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	headers := map[string]string{
+		"Content-Type": contentType,
+		"User-Agent":   "Grafana",
+	}
+	if cmd.User != "" && cmd.Password != "" {
+		headers["Authorization"] = "<redacted>"
+	}
+	for k, v := range cmd.HTTPHeader {
+		if http.CanonicalHeaderKey(k) == "Authorization" {
+			v = "<redacted>"
+		}
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+	return apimodels.TestReceiverCapturedNotification{
+		Kind:    "webhook",
+		Method:  method,
+		Headers: headers,
+		Payload: cmd.Body,
+	}
+}
@@ -124,6 +124,7 @@ func (f *fakeConfigStore) SaveAlertmanagerConfigurationWithCallback(_ context.Co
 	f.configs[cmd.OrgID] = &cfg
 
 	historicConfig := models.HistoricConfigFromAlertConfig(cfg)
+	historicConfig.ID = int64(len(f.historicConfigs[cmd.OrgID]))
 	if cmd.LastApplied != 0 {
 		historicConfig.LastApplied = time.Now().UTC().Unix()
 		f.historicConfigs[cmd.OrgID] = append(f.historicConfigs[cmd.OrgID], &historicConfig)
@@ -148,6 +149,7 @@ func (f *fakeConfigStore) UpdateAlertmanagerConfiguration(_ context.Context, cmd
 		f.configs[cmd.OrgID] = &newConfig
 
 		historicConfig := models.HistoricConfigFromAlertConfig(newConfig)
+		historicConfig.ID = int64(len(f.historicConfigs[cmd.OrgID]))
 		f.historicConfigs[cmd.OrgID] = append(f.historicConfigs[cmd.OrgID], &historicConfig)
 		return nil
 	}
@@ -197,6 +199,18 @@ func (f *fakeConfigStore) GetAppliedConfigurations(_ context.Context, orgID int6
 	return configs, nil
 }
 
+func (f *fakeConfigStore) GetConfigurationHistory(_ context.Context, orgID int64, limit int) ([]*models.HistoricAlertConfiguration, error) {
+	configsByOrg := f.historicConfigs[orgID]
+
+	// Iterate backwards to get the latest configs first.
+	configs := []*models.HistoricAlertConfiguration{}
+	for i := len(configsByOrg) - 1; i >= 0 && (limit < 1 || len(configs) < limit); i-- {
+		configs = append(configs, configsByOrg[i])
+	}
+
+	return configs, nil
+}
+
 func (f *fakeConfigStore) GetHistoricalConfiguration(_ context.Context, orgID int64, id int64) (*models.HistoricAlertConfiguration, error) {
 	configsByOrg, ok := f.historicConfigs[orgID]
 	if !ok {
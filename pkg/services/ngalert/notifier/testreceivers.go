@@ -3,19 +3,41 @@ package notifier
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	alertingMqtt "github.com/grafana/alerting/receivers/mqtt"
+	alertingTemplates "github.com/grafana/alerting/templates"
+	"github.com/prometheus/alertmanager/types"
 
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/util"
 )
 
+// ErrTestNotificationCaptureNotSupported is returned when the Alertmanager cannot capture test notifications.
+var ErrTestNotificationCaptureNotSupported = errors.New("capturing test notifications is not supported by the remote Alertmanager")
+
 func (am *alertmanager) TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigBodyParams) (*alertingNotify.TestReceiversResult, int, error) {
+	capture := testNotificationCaptureFromContext(ctx)
+	// In capture mode, integrations without UID get a temporary one so that their results can be matched with
+	// the notifications they rendered.
+	temporaryUIDs := make(map[string]struct{})
 	receivers := make([]*alertingNotify.APIReceiver, 0, len(c.Receivers))
 	for _, r := range c.Receivers {
 		integrations := make([]*alertingNotify.GrafanaIntegrationConfig, 0, len(r.GrafanaManagedReceivers))
 		for _, gr := range r.PostableGrafanaReceivers.GrafanaManagedReceivers {
+			uid := gr.UID
+			if capture != nil {
+				if uid == "" {
+					uid = util.GenerateShortUID()
+					temporaryUIDs[uid] = struct{}{}
+				}
+				capture.register(r.Name, len(integrations), uid)
+			}
 			integrations = append(integrations, &alertingNotify.GrafanaIntegrationConfig{
-				UID:                   gr.UID,
+				UID:                   uid,
 				Name:                  gr.Name,
 				Type:                  gr.Type,
 				DisableResolveMessage: gr.DisableResolveMessage,
@@ -35,12 +57,258 @@ func (am *alertmanager) TestReceivers(ctx context.Context, c apimodels.TestRecei
 		alert = &alertingNotify.TestReceiversConfigAlertParams{Annotations: c.Alert.Annotations, Labels: c.Alert.Labels}
 	}
 
-	return am.Base.TestReceivers(ctx, alertingNotify.TestReceiversConfigBodyParams{
+	result, status, err := am.Base.TestReceivers(ctx, alertingNotify.TestReceiversConfigBodyParams{
 		Alert:     alert,
 		Receivers: receivers,
 	})
+	if err != nil || capture == nil {
+		return result, status, err
+	}
+
+	for ix, r := range result.Receivers {
+		for jx, config := range r.Configs {
+			capture.assign(ix, jx, config.UID)
+			if _, ok := temporaryUIDs[config.UID]; ok {
+				result.Receivers[ix].Configs[jx].UID = ""
+			}
+		}
+	}
+	return result, status, err
 }
 
 func (am *alertmanager) GetReceivers(_ context.Context) ([]apimodels.Receiver, error) {
 	return am.Base.GetReceivers(), nil
 }
+
+type testNotificationCaptureKey struct{}
+
+// testIntegrationKey identifies an integration of a receiver that is tested.
+type testIntegrationKey struct {
+	receiver string
+	index    int
+}
+
+// TestNotificationCapture collects the test notifications that the integrations render in capture mode instead of
+// delivering them.
+type TestNotificationCapture struct {
+	mtx sync.Mutex
+	// uids are the UIDs of the tested integrations.
+	uids map[testIntegrationKey]string
+	// captured are the notifications rendered by the integrations, by UID.
+	captured map[string][]apimodels.TestReceiverCapturedNotification
+	// results are the captured notifications by the position of the integration in the result of the test.
+	results map[[2]int][]apimodels.TestReceiverCapturedNotification
+}
+
+// WithTestNotificationCapture returns a context that makes the test of receivers run in capture mode. The returned
+// capture holds the rendered notifications once the test has completed.
+func WithTestNotificationCapture(ctx context.Context) (context.Context, *TestNotificationCapture) {
+	capture := &TestNotificationCapture{
+		uids:     make(map[testIntegrationKey]string),
+		captured: make(map[string][]apimodels.TestReceiverCapturedNotification),
+		results:  make(map[[2]int][]apimodels.TestReceiverCapturedNotification),
+	}
+	return context.WithValue(ctx, testNotificationCaptureKey{}, capture), capture
+}
+
+// CapturesTestNotifications returns true if the context was created with WithTestNotificationCapture.
+func CapturesTestNotifications(ctx context.Context) bool {
+	return testNotificationCaptureFromContext(ctx) != nil
+}
+
+func testNotificationCaptureFromContext(ctx context.Context) *TestNotificationCapture {
+	capture, _ := ctx.Value(testNotificationCaptureKey{}).(*TestNotificationCapture)
+	return capture
+}
+
+// Notifications returns the notifications captured for the configuration jx of the receiver ix in the result of the
+// test. It returns nil if the capture is nil.
+func (c *TestNotificationCapture) Notifications(ix, jx int) []apimodels.TestReceiverCapturedNotification {
+	if c == nil {
+		return nil
+	}
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.results[[2]int{ix, jx}]
+}
+
+func (c *TestNotificationCapture) register(receiver string, index int, uid string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.uids[testIntegrationKey{receiver: receiver, index: index}] = uid
+}
+
+func (c *TestNotificationCapture) assign(ix, jx int, uid string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.results[[2]int{ix, jx}] = c.captured[uid]
+}
+
+func (c *TestNotificationCapture) add(key testIntegrationKey, n apimodels.TestReceiverCapturedNotification) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	uid := c.uids[key]
+	c.captured[uid] = append(c.captured[uid], n)
+}
+
+type capturedIntegrationKey struct{}
+
+// captureTestNotification records the notification if the integration is tested in capture mode. It returns false if
+// the notification must be delivered.
+func captureTestNotification(ctx context.Context, n apimodels.TestReceiverCapturedNotification) bool {
+	capture := testNotificationCaptureFromContext(ctx)
+	if capture == nil {
+		return false
+	}
+	key, _ := ctx.Value(capturedIntegrationKey{}).(testIntegrationKey)
+	capture.add(key, n)
+	return true
+}
+
+// wrapForTestNotificationCapture returns the integrations of the receiver with notifiers that, in capture mode,
+// tell the senders which integration renders the notification. The integrations that deliver notifications with their
+// own clients instead of the senders of Grafana are not run in capture mode, their notifications are rendered from
+// the configuration of the integration instead.
+func wrapForTestNotificationCapture(receiver *alertingNotify.APIReceiver, tmpl *alertingTemplates.Template, integrations []*alertingNotify.Integration) []*alertingNotify.Integration {
+	result := make([]*alertingNotify.Integration, 0, len(integrations))
+	for _, integration := range integrations {
+		n := &capturingNotifier{
+			receiver:    receiver.Name,
+			integration: integration,
+		}
+		if integration.Index() < len(receiver.Integrations) {
+			n.render = newCaptureRenderer(receiver.Integrations[integration.Index()], tmpl)
+		}
+		result = append(result, alertingNotify.NewIntegration(n, integration, integration.Name(), integration.Index(), receiver.Name))
+	}
+	return result
+}
+
+// capturingNotifier is a notifier that lets the senders capture the notifications of the integration in capture
+// mode. Outside of capture mode, it only calls the notifier of the integration.
+type capturingNotifier struct {
+	receiver    string
+	integration *alertingNotify.Integration
+	// render renders the notification of integrations that do not use the senders of Grafana.
+	render captureRenderer
+}
+
+func (n *capturingNotifier) Notify(ctx context.Context, alerts ...*types.Alert) (bool, error) {
+	if !CapturesTestNotifications(ctx) {
+		return n.integration.Notify(ctx, alerts...)
+	}
+	ctx = context.WithValue(ctx, capturedIntegrationKey{}, testIntegrationKey{receiver: n.receiver, index: n.integration.Index()})
+	if n.render != nil {
+		captured, err := n.render(ctx, alerts...)
+		if err != nil {
+			return false, err
+		}
+		captureTestNotification(ctx, captured)
+		return false, nil
+	}
+	return n.integration.Notify(ctx, alerts...)
+}
+
+// captureRenderer renders the notification of an integration without delivering it.
+type captureRenderer func(ctx context.Context, alerts ...*types.Alert) (apimodels.TestReceiverCapturedNotification, error)
+
+// newCaptureRenderer returns the renderer of integrations that deliver notifications with their own clients, and nil
+// for the other integrations. The renderers use the templates of the settings the same way as the integrations.
+func newCaptureRenderer(cfg *alertingNotify.GrafanaIntegrationConfig, tmpl *alertingTemplates.Template) captureRenderer {
+	switch cfg.Type {
+	case "slack":
+		return func(ctx context.Context, alerts ...*types.Alert) (apimodels.TestReceiverCapturedNotification, error) {
+			settings := struct {
+				Recipient string `json:"recipient"`
+				Title     string `json:"title"`
+				Text      string `json:"text"`
+			}{}
+			if err := json.Unmarshal(cfg.Settings, &settings); err != nil {
+				return apimodels.TestReceiverCapturedNotification{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+			}
+			expand, _ := expandCaptureTemplates(ctx, tmpl, alerts)
+			return apimodels.TestReceiverCapturedNotification{
+				Kind:  cfg.Type,
+				To:    nonEmpty(settings.Recipient),
+				Title: expand(withDefault(settings.Title, `{{ template "slack.default.title" . }}`)),
+				Body:  expand(withDefault(settings.Text, `{{ template "slack.default.text" . }}`)),
+			}, nil
+		}
+	case "sns":
+		return func(ctx context.Context, alerts ...*types.Alert) (apimodels.TestReceiverCapturedNotification, error) {
+			settings := struct {
+				TopicARN    string `json:"topic_arn"`
+				TargetARN   string `json:"target_arn"`
+				PhoneNumber string `json:"phone_number"`
+				Subject     string `json:"subject"`
+				Message     string `json:"message"`
+			}{}
+			if err := json.Unmarshal(cfg.Settings, &settings); err != nil {
+				return apimodels.TestReceiverCapturedNotification{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+			}
+			expand, _ := expandCaptureTemplates(ctx, tmpl, alerts)
+			return apimodels.TestReceiverCapturedNotification{
+				Kind:  cfg.Type,
+				To:    nonEmpty(settings.TopicARN, settings.TargetARN, settings.PhoneNumber),
+				Title: expand(withDefault(settings.Subject, alertingTemplates.DefaultMessageTitleEmbed)),
+				Body:  expand(withDefault(settings.Message, alertingTemplates.DefaultMessageEmbed)),
+			}, nil
+		}
+	case "mqtt":
+		return func(ctx context.Context, alerts ...*types.Alert) (apimodels.TestReceiverCapturedNotification, error) {
+			settings := struct {
+				Topic         string `json:"topic"`
+				Message       string `json:"message"`
+				MessageFormat string `json:"messageFormat"`
+			}{}
+			if err := json.Unmarshal(cfg.Settings, &settings); err != nil {
+				return apimodels.TestReceiverCapturedNotification{}, fmt.Errorf("failed to unmarshal settings: %w", err)
+			}
+			expand, data := expandCaptureTemplates(ctx, tmpl, alerts)
+			message := expand(withDefault(settings.Message, alertingTemplates.DefaultMessageEmbed))
+			payload := message
+			if settings.MessageFormat != alertingMqtt.MessageFormatText {
+				// the JSON message has the data of the template and the rendered message
+				b, err := json.Marshal(struct {
+					*alertingTemplates.ExtendedData
+					Message string `json:"message"`
+				}{ExtendedData: data, Message: message})
+				if err != nil {
+					return apimodels.TestReceiverCapturedNotification{}, fmt.Errorf("failed to marshal message: %w", err)
+				}
+				payload = string(b)
+			}
+			return apimodels.TestReceiverCapturedNotification{
+				Kind:    cfg.Type,
+				To:      nonEmpty(settings.Topic),
+				Payload: payload,
+			}, nil
+		}
+	}
+	return nil
+}
+
+// expandCaptureTemplates returns a function that expands the templates of the settings with the alerts, and the data
+// of the templates. Templates that fail to expand are replaced by empty strings.
+func expandCaptureTemplates(ctx context.Context, tmpl *alertingTemplates.Template, alerts []*types.Alert) (func(string) string, *alertingTemplates.ExtendedData) {
+	var tmplErr error
+	return alertingTemplates.TmplText(ctx, tmpl, alerts, LoggerFactory("ngalert.notifier.test-capture"), &tmplErr)
+}
+
+func withDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// nonEmpty returns the values that are not empty.
+func nonEmpty(values ...string) []string {
+	var result []string
+	for _, v := range values {
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -2,12 +2,24 @@ package notifier
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/url"
 	"testing"
+	"time"
 
 	alertingNotify "github.com/grafana/alerting/notify"
+	"github.com/grafana/alerting/receivers"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestInvalidReceiverError_Error(t *testing.T) {
@@ -80,3 +92,158 @@ func TestProcessNotifierError(t *testing.T) {
 		require.Equal(t, err, alertingNotify.ProcessIntegrationError(r, err))
 	})
 }
+
+func TestTestNotificationCapture(t *testing.T) {
+	webhook := &receivers.SendWebhookSettings{
+		URL:        "http://localhost/hook",
+		User:       "user",
+		Password:   "password",
+		Body:       `{"title":"[FIRING:1] test"}`,
+		HTTPMethod: "PUT",
+		HTTPHeader: map[string]string{"x-team": "a"},
+	}
+	receiver := &alertingNotify.APIReceiver{
+		ConfigReceiver: alertingNotify.ConfigReceiver{Name: "team-a"},
+		GrafanaIntegrations: alertingNotify.GrafanaIntegrations{
+			Integrations: []*alertingNotify.GrafanaIntegrationConfig{
+				{Type: "webhook", Settings: json.RawMessage(`{"url":"http://localhost/hook"}`)},
+				{Type: "slack", Settings: json.RawMessage(`{"recipient":"#alerts","title":"{{ template \"default.title\" . }}","text":"{{ len .Alerts }} alerts"}`)},
+				{Type: "sns", Settings: json.RawMessage(`{"topic_arn":"arn:aws:sns:us-east-1:123456789012:alerts","subject":"{{ .Status }}","message":"{{ len .Alerts }} alerts"}`)},
+				{Type: "mqtt", Settings: json.RawMessage(`{"brokerUrl":"tcp://localhost:1883","topic":"grafana/alerts","message":"{{ len .Alerts }} alerts"}`)},
+				{Type: "mqtt", Settings: json.RawMessage(`{"brokerUrl":"tcp://localhost:1883","topic":"grafana/alerts","message":"{{ len .Alerts }} alerts","messageFormat":"text"}`)},
+			},
+		},
+	}
+	alerts := []*types.Alert{{Alert: model.Alert{
+		Labels:   model.LabelSet{"alertname": "test"},
+		StartsAt: time.Now(),
+	}}}
+	newIntegrations := func(s sender) []*alertingNotify.Integration {
+		webhookNotifier := fakeNotifierFunc(func(ctx context.Context) error {
+			return s.SendWebhook(ctx, webhook)
+		})
+		integrations := []*alertingNotify.Integration{alertingNotify.NewIntegration(webhookNotifier, webhookNotifier, "webhook", 0, "team-a")}
+		for i, cfg := range receiver.Integrations[1:] {
+			notifier := fakeNotifierFunc(func(ctx context.Context) error {
+				return fmt.Errorf("%s was called", cfg.Type)
+			})
+			integrations = append(integrations, alertingNotify.NewIntegration(notifier, notifier, cfg.Type, i+1, "team-a"))
+		}
+		return wrapForTestNotificationCapture(receiver, templateForTests(t), integrations)
+	}
+
+	t.Run("notifications are delivered outside of capture mode", func(t *testing.T) {
+		ns := &notifications.NotificationServiceMock{}
+		integrations := newIntegrations(sender{ns: ns})
+
+		_, err := integrations[0].Notify(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, "http://localhost/hook", ns.Webhook.Url)
+	})
+
+	t.Run("webhooks are captured instead of delivered", func(t *testing.T) {
+		ns := &notifications.NotificationServiceMock{}
+		integrations := newIntegrations(sender{ns: ns})
+		ctx, capture := WithTestNotificationCapture(context.Background())
+		capture.register("team-a", 0, "webhook-uid")
+
+		_, err := integrations[0].Notify(ctx)
+		require.NoError(t, err)
+		require.Empty(t, ns.Webhook.Url)
+
+		capture.assign(0, 0, "webhook-uid")
+		require.Equal(t, []apimodels.TestReceiverCapturedNotification{{
+			Kind:   "webhook",
+			Method: "PUT",
+			Headers: map[string]string{
+				"Authorization": "<redacted>",
+				"Content-Type":  "application/json",
+				"User-Agent":    "Grafana",
+				"X-Team":        "a",
+			},
+			Payload: `{"title":"[FIRING:1] test"}`,
+		}}, capture.Notifications(0, 0))
+	})
+
+	t.Run("integrations that deliver with their own clients are rendered instead of run", func(t *testing.T) {
+		integrations := newIntegrations(sender{ns: &notifications.NotificationServiceMock{}})
+		ctx, capture := WithTestNotificationCapture(context.Background())
+		for i := 1; i < len(integrations); i++ {
+			capture.register("team-a", i, fmt.Sprint(i))
+			_, err := integrations[i].Notify(ctx, alerts...)
+			require.NoError(t, err)
+			capture.assign(0, i, fmt.Sprint(i))
+		}
+
+		slack := capture.Notifications(0, 1)
+		require.Len(t, slack, 1)
+		require.Equal(t, "slack", slack[0].Kind)
+		require.Equal(t, []string{"#alerts"}, slack[0].To)
+		require.Contains(t, slack[0].Title, "[FIRING:1]")
+		require.Equal(t, "1 alerts", slack[0].Body)
+
+		require.Equal(t, []apimodels.TestReceiverCapturedNotification{{
+			Kind:  "sns",
+			To:    []string{"arn:aws:sns:us-east-1:123456789012:alerts"},
+			Title: "firing",
+			Body:  "1 alerts",
+		}}, capture.Notifications(0, 2))
+
+		mqttJSON := capture.Notifications(0, 3)
+		require.Len(t, mqttJSON, 1)
+		require.Equal(t, "mqtt", mqttJSON[0].Kind)
+		require.Equal(t, []string{"grafana/alerts"}, mqttJSON[0].To)
+		var payload map[string]any
+		require.NoError(t, json.Unmarshal([]byte(mqttJSON[0].Payload), &payload))
+		require.Equal(t, "1 alerts", payload["message"])
+		require.Equal(t, "firing", payload["status"])
+
+		require.Equal(t, []apimodels.TestReceiverCapturedNotification{{
+			Kind:    "mqtt",
+			To:      []string{"grafana/alerts"},
+			Payload: "1 alerts",
+		}}, capture.Notifications(0, 4))
+	})
+
+	t.Run("emails are rendered even if SMTP is not configured", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.StaticRootPath = "../../../../public/"
+		cfg.Smtp.TemplatesPatterns = []string{"emails/*.html", "emails/*.txt"}
+		cfg.Smtp.FromAddress = "from@address.com"
+		cfg.Smtp.ContentTypes = []string{"text/html", "text/plain"}
+		mailer := notifications.NewFakeMailer()
+		ns, err := notifications.ProvideService(bus.ProvideBus(tracing.InitializeTracerForTest()), cfg, mailer, nil)
+		require.NoError(t, err)
+
+		ctx, capture := WithTestNotificationCapture(context.Background())
+		ctx = context.WithValue(ctx, capturedIntegrationKey{}, testIntegrationKey{receiver: "team-a", index: 0})
+		capture.register("team-a", 0, "email-uid")
+
+		err = sender{ns: ns}.SendEmail(ctx, &receivers.SendEmailSettings{
+			To:       []string{"a@example.com"},
+			Subject:  "[FIRING:1] test",
+			Template: "welcome_on_signup",
+		})
+		require.NoError(t, err)
+		require.Empty(t, mailer.Sent)
+
+		capture.assign(0, 0, "email-uid")
+		captured := capture.Notifications(0, 0)
+		require.Len(t, captured, 1)
+		require.Equal(t, "email", captured[0].Kind)
+		require.Equal(t, []string{"a@example.com"}, captured[0].To)
+		require.Equal(t, "[FIRING:1] test", captured[0].Title)
+		require.Contains(t, captured[0].Body, "<html")
+	})
+}
+
+// fakeNotifierFunc is a notifier that calls the function for every notification.
+type fakeNotifierFunc func(ctx context.Context) error
+
+func (f fakeNotifierFunc) Notify(ctx context.Context, _ ...*types.Alert) (bool, error) {
+	return false, f(ctx)
+}
+
+func (f fakeNotifierFunc) SendResolved() bool {
+	return true
+}
@@ -13,6 +13,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -61,6 +62,7 @@ func newRuleFactory(
 	met *metrics.Scheduler,
 	logger log.Logger,
 	tracer tracing.Tracer,
+	limiter *evaluationLimiter,
 	recordingWriter RecordingWriter,
 	evalAppliedHook evalAppliedFunc,
 	stopAppliedHook stopAppliedFunc,
@@ -77,6 +79,7 @@ func newRuleFactory(
 				logger,
 				met,
 				tracer,
+				limiter,
 				recordingWriter,
 				evalAppliedHook,
 				stopAppliedHook,
@@ -96,6 +99,7 @@ func newRuleFactory(
 			met,
 			logger,
 			tracer,
+			limiter,
 			evalAppliedHook,
 			stopAppliedHook,
 		)
@@ -134,6 +138,7 @@ type alertRule struct {
 	metrics *metrics.Scheduler
 	logger  log.Logger
 	tracer  tracing.Tracer
+	limiter *evaluationLimiter
 }
 
 func newAlertRule(
@@ -150,6 +155,7 @@ func newAlertRule(
 	met *metrics.Scheduler,
 	logger log.Logger,
 	tracer tracing.Tracer,
+	limiter *evaluationLimiter,
 	evalAppliedHook func(ngmodels.AlertRuleKey, time.Time),
 	stopAppliedHook func(ngmodels.AlertRuleKey),
 ) *alertRule {
@@ -173,6 +179,7 @@ func newAlertRule(
 		metrics:              met,
 		logger:               logger.FromContext(ctx),
 		tracer:               tracer,
+		limiter:              limiter,
 	}
 }
 
@@ -262,7 +269,17 @@ func (a *alertRule) Run() error {
 			logger := a.logger.New("version", ctx.rule.Version, "fingerprint", f, "now", ctx.scheduledAt)
 			logger.Debug("Processing tick")
 
+			release, err := a.limiter.acquire(grafanaCtx, ctx.rule.Priority, nextEvaluation(ctx))
+			if err != nil {
+				if errors.Is(err, errNoEvaluationSlot) {
+					logger.Warn("Skip rule evaluation because no evaluation slot became available before the next evaluation", "priority", ctx.rule.Priority)
+					a.metrics.EvaluationMissed.WithLabelValues(fmt.Sprint(a.key.OrgID), ctx.rule.Title).Inc()
+				}
+				continue
+			}
+
 			func() {
+				defer release()
 				orgID := fmt.Sprint(a.key.OrgID)
 				evalDuration := a.metrics.EvalDuration.WithLabelValues(orgID)
 				evalTotal := a.metrics.EvalTotal.WithLabelValues(orgID)
@@ -349,6 +366,10 @@ func (a *alertRule) Run() error {
 				states := a.stateManager.DeleteStateByRuleUID(ngmodels.WithRuleKey(ctx, a.key), a.key, ngmodels.StateReasonRuleDeleted)
 				a.expireAndSend(grafanaCtx, states)
 			}
+			// the rule is evaluated by another instance now, which continues from the state in the database
+			if errors.Is(grafanaCtx.Err(), errRuleHandedOff) {
+				a.stateManager.ForgetStateByRuleUID(ngmodels.WithRuleKey(context.Background(), a.key), a.key)
+			}
 			a.logger.Debug("Stopping alert rule routine")
 			return nil
 		}
@@ -365,7 +386,7 @@ func (a *alertRule) evaluate(ctx context.Context, e *Evaluation, span trace.Span
 
 	start := a.clock.Now()
 
-	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule))
+	evalCtx := eval.NewContextWithPreviousResults(ctx, SchedulerUserFor(e.rule.OrgID), a.newLoadedMetricsReader(e.rule)).WithEvaluationTimeout(e.rule.EvaluationTimeout)
 	ruleEval, err := a.evalFactory.Create(evalCtx, e.rule.GetEvalCondition().WithSource("scheduler").WithFolder(e.folderTitle))
 	var results eval.Results
 	var dur time.Duration
@@ -373,10 +394,13 @@ func (a *alertRule) evaluate(ctx context.Context, e *Evaluation, span trace.Span
 		dur = a.clock.Now().Sub(start)
 		logger.Error("Failed to build rule evaluator", "error", err)
 	} else {
-		results, err = ruleEval.Evaluate(ctx, e.scheduledAt)
+		results, err = ruleEval.Evaluate(expr.WithQueryCache(ctx, e.queryCache), e.scheduledAt)
 		dur = a.clock.Now().Sub(start)
 		if err != nil {
 			logger.Error("Failed to evaluate rule", "error", err, "duration", dur)
+			if errors.Is(err, eval.ErrEvaluationTimeout) {
+				a.metrics.EvalTimeouts.WithLabelValues(orgID).Inc()
+			}
 		}
 	}
 
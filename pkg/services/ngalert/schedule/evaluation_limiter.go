@@ -0,0 +1,155 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// errNoEvaluationSlot is returned when no evaluation slot becomes available before the next evaluation of the rule.
+var errNoEvaluationSlot = errors.New("no evaluation slot became available before the next evaluation of the rule")
+
+// evaluationPriorities are the priority classes in the order in which the waiting evaluations are started.
+var evaluationPriorities = []ngmodels.RulePriority{
+	ngmodels.RulePriorityCritical,
+	ngmodels.RulePriorityNormal,
+	ngmodels.RulePriorityLow,
+}
+
+// priorityClass returns the index of the priority in evaluationPriorities. Empty and unknown priorities are normal.
+func priorityClass(priority ngmodels.RulePriority) int {
+	switch priority {
+	case ngmodels.RulePriorityCritical:
+		return 0
+	case ngmodels.RulePriorityLow:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// evaluationLimiter bounds the number of rule evaluations that run at the same time, so that slow rules cannot use up
+// the data sources for all other rules. When all slots are taken, the waiting evaluations are started by the order
+// of their priority class, and by the order in which they started to wait within a class.
+type evaluationLimiter struct {
+	mtx      sync.Mutex
+	capacity int
+	running  int
+	waiting  [][]*evaluationWaiter
+
+	clock   clock.Clock
+	metrics *metrics.Scheduler
+}
+
+type evaluationWaiter struct {
+	ready chan struct{}
+	// granted is set when the slot of a finished evaluation is passed to the waiter.
+	granted bool
+}
+
+// newEvaluationLimiter returns a limiter that runs at most capacity evaluations at the same time. It returns nil,
+// which does not limit the evaluations, if the capacity is not greater than zero.
+func newEvaluationLimiter(capacity int, clock clock.Clock, metrics *metrics.Scheduler) *evaluationLimiter {
+	if capacity <= 0 {
+		return nil
+	}
+	return &evaluationLimiter{
+		capacity: capacity,
+		waiting:  make([][]*evaluationWaiter, len(evaluationPriorities)),
+		clock:    clock,
+		metrics:  metrics,
+	}
+}
+
+// acquire waits for an evaluation slot until the deadline, which is usually the time of the next evaluation of the
+// rule. It returns errNoEvaluationSlot if the deadline passes, or the error of the context if it is done first.
+// The returned function releases the slot and must be called exactly once when the evaluation is finished.
+func (l *evaluationLimiter) acquire(ctx context.Context, priority ngmodels.RulePriority, deadline time.Time) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	class := priorityClass(priority)
+	label := evaluationPriorities[class].String()
+	queueDuration := l.metrics.EvaluationQueueDuration.WithLabelValues(label)
+
+	l.mtx.Lock()
+	if l.running < l.capacity && !l.hasWaiting() {
+		l.running++
+		l.mtx.Unlock()
+		queueDuration.Observe(0)
+		return l.release, nil
+	}
+	w := &evaluationWaiter{ready: make(chan struct{})}
+	l.waiting[class] = append(l.waiting[class], w)
+	l.metrics.EvaluationsQueued.WithLabelValues(label).Inc()
+	l.mtx.Unlock()
+
+	start := l.clock.Now()
+	waitCtx, cancel := l.clock.WithDeadline(ctx, deadline)
+	defer cancel()
+	select {
+	case <-w.ready:
+		queueDuration.Observe(l.clock.Since(start).Seconds())
+		return l.release, nil
+	case <-waitCtx.Done():
+	}
+
+	l.mtx.Lock()
+	if w.granted {
+		// The slot was passed to the waiter at the same time as it stopped waiting, pass it on to the next one.
+		l.mtx.Unlock()
+		l.release()
+	} else {
+		l.waiting[class] = slices.DeleteFunc(l.waiting[class], func(e *evaluationWaiter) bool {
+			return e == w
+		})
+		l.metrics.EvaluationsQueued.WithLabelValues(label).Dec()
+		l.mtx.Unlock()
+	}
+	queueDuration.Observe(l.clock.Since(start).Seconds())
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	l.metrics.EvaluationsSkipped.WithLabelValues(label).Inc()
+	return nil, errNoEvaluationSlot
+}
+
+// release passes the slot to the first waiter of the highest priority class, or frees it if nobody waits.
+func (l *evaluationLimiter) release() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	for class, waiters := range l.waiting {
+		if len(waiters) == 0 {
+			continue
+		}
+		w := waiters[0]
+		l.waiting[class] = waiters[1:]
+		l.metrics.EvaluationsQueued.WithLabelValues(evaluationPriorities[class].String()).Dec()
+		w.granted = true
+		close(w.ready)
+		return
+	}
+	l.running--
+}
+
+func (l *evaluationLimiter) hasWaiting() bool {
+	for _, waiters := range l.waiting {
+		if len(waiters) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nextEvaluation returns the time of the evaluation of the rule that follows the given one.
+func nextEvaluation(e *Evaluation) time.Time {
+	return e.scheduledAt.Add(time.Duration(e.rule.IntervalSeconds) * time.Second)
+}
@@ -0,0 +1,126 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluationLimiter(t *testing.T) {
+	newLimiter := func(capacity int) *evaluationLimiter {
+		return newEvaluationLimiter(capacity, clock.New(), metrics.NewSchedulerMetrics(prometheus.NewRegistry()))
+	}
+	farDeadline := time.Now().Add(time.Hour)
+
+	t.Run("does not limit the evaluations if the capacity is not set", func(t *testing.T) {
+		l := newLimiter(0)
+		require.Nil(t, l)
+		for i := 0; i < 10; i++ {
+			_, err := l.acquire(context.Background(), models.RulePriorityLow, time.Now())
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("runs up to capacity evaluations at the same time", func(t *testing.T) {
+		l := newLimiter(2)
+		release1, err := l.acquire(context.Background(), "", farDeadline)
+		require.NoError(t, err)
+		release2, err := l.acquire(context.Background(), "", farDeadline)
+		require.NoError(t, err)
+
+		_, err = l.acquire(context.Background(), "", time.Now().Add(10*time.Millisecond))
+		require.ErrorIs(t, err, errNoEvaluationSlot)
+
+		release1()
+		release3, err := l.acquire(context.Background(), "", farDeadline)
+		require.NoError(t, err)
+		release2()
+		release3()
+		require.Zero(t, l.running)
+	})
+
+	t.Run("starts the waiting evaluations by priority class and then by arrival", func(t *testing.T) {
+		l := newLimiter(1)
+		release, err := l.acquire(context.Background(), models.RulePriorityNormal, farDeadline)
+		require.NoError(t, err)
+
+		waiters := []models.RulePriority{
+			models.RulePriorityLow,
+			models.RulePriorityNormal,
+			"",
+			models.RulePriorityCritical,
+		}
+		started := make(chan int, len(waiters))
+		for i, priority := range waiters {
+			go func() {
+				release, err := l.acquire(context.Background(), priority, farDeadline)
+				if err != nil {
+					return
+				}
+				started <- i
+				release()
+			}()
+			require.Eventually(t, func() bool {
+				l.mtx.Lock()
+				defer l.mtx.Unlock()
+				return len(l.waiting[priorityClass(priority)]) > 0 && countWaiting(l) == i+1
+			}, time.Second, time.Millisecond)
+		}
+		require.Equal(t, 2.0, testutil.ToFloat64(l.metrics.EvaluationsQueued.WithLabelValues("normal")))
+
+		release()
+		order := make([]int, 0, len(waiters))
+		for range waiters {
+			order = append(order, <-started)
+		}
+		require.Equal(t, []int{3, 1, 2, 0}, order)
+		require.Zero(t, l.running)
+		require.Zero(t, testutil.ToFloat64(l.metrics.EvaluationsQueued.WithLabelValues("normal")))
+	})
+
+	t.Run("skips the evaluation if no slot becomes available before the deadline", func(t *testing.T) {
+		l := newLimiter(1)
+		release, err := l.acquire(context.Background(), "", farDeadline)
+		require.NoError(t, err)
+
+		_, err = l.acquire(context.Background(), models.RulePriorityLow, time.Now().Add(10*time.Millisecond))
+		require.ErrorIs(t, err, errNoEvaluationSlot)
+		require.Equal(t, 1.0, testutil.ToFloat64(l.metrics.EvaluationsSkipped.WithLabelValues("low")))
+		require.Zero(t, countWaiting(l))
+
+		release()
+		require.Zero(t, l.running)
+	})
+
+	t.Run("stops waiting if the context is cancelled", func(t *testing.T) {
+		l := newLimiter(1)
+		release, err := l.acquire(context.Background(), "", farDeadline)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = l.acquire(ctx, "", farDeadline)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Zero(t, testutil.ToFloat64(l.metrics.EvaluationsSkipped.WithLabelValues("normal")))
+		require.Zero(t, countWaiting(l))
+
+		release()
+		require.Zero(t, l.running)
+	})
+}
+
+func countWaiting(l *evaluationLimiter) int {
+	count := 0
+	for _, waiters := range l.waiting {
+		count += len(waiters)
+	}
+	return count
+}
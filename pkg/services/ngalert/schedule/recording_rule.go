@@ -2,6 +2,7 @@ package schedule
 
 import (
 	context "context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,11 +14,13 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -54,9 +57,10 @@ type recordingRule struct {
 	logger  log.Logger
 	metrics *metrics.Scheduler
 	tracer  tracing.Tracer
+	limiter *evaluationLimiter
 }
 
-func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, cfg setting.RecordingRuleSettings, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, writer RecordingWriter, evalAppliedHook evalAppliedFunc, stopAppliedHook stopAppliedFunc) *recordingRule {
+func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAttempts int64, clock clock.Clock, evalFactory eval.EvaluatorFactory, cfg setting.RecordingRuleSettings, logger log.Logger, metrics *metrics.Scheduler, tracer tracing.Tracer, limiter *evaluationLimiter, writer RecordingWriter, evalAppliedHook evalAppliedFunc, stopAppliedHook stopAppliedFunc) *recordingRule {
 	ctx, stop := util.WithCancelCause(ngmodels.WithRuleKey(parent, key))
 	return &recordingRule{
 		key:                 key,
@@ -76,6 +80,7 @@ func newRecordingRule(parent context.Context, key ngmodels.AlertRuleKey, maxAtte
 		logger:              logger.FromContext(ctx),
 		metrics:             metrics,
 		tracer:              tracer,
+		limiter:             limiter,
 		writer:              writer,
 	}
 }
@@ -139,7 +144,18 @@ func (r *recordingRule) Run() error {
 			// TODO: Skipping the "evalRunning" guard that the alert rule routine does, because it seems to be dead code and impossible to hit.
 			// TODO: Either implement me or remove from alert rules once investigated.
 
-			r.doEvaluate(ctx, eval)
+			release, err := r.limiter.acquire(ctx, eval.rule.Priority, nextEvaluation(eval))
+			if err != nil {
+				if errors.Is(err, errNoEvaluationSlot) {
+					r.logger.Warn("Skip recording rule evaluation because no evaluation slot became available before the next evaluation", "now", eval.scheduledAt, "priority", eval.rule.Priority)
+					r.metrics.EvaluationMissed.WithLabelValues(fmt.Sprint(eval.rule.OrgID), eval.rule.Title).Inc()
+				}
+				continue
+			}
+			func() {
+				defer release()
+				r.doEvaluate(ctx, eval)
+			}()
 		case <-ctx.Done():
 			r.logger.Debug("Stopping recording rule routine")
 			return nil
@@ -235,10 +251,13 @@ func (r *recordingRule) doEvaluate(ctx context.Context, ev *Evaluation) {
 
 func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logger log.Logger) error {
 	evalStart := r.clock.Now()
-	evalCtx := eval.NewContext(ctx, SchedulerUserFor(ev.rule.OrgID))
+	evalCtx := eval.NewContext(ctx, SchedulerUserFor(ev.rule.OrgID)).WithEvaluationTimeout(ev.rule.EvaluationTimeout)
 	result, err := r.buildAndExecutePipeline(ctx, evalCtx, ev, logger)
 	evalDur := r.clock.Now().Sub(evalStart)
 	if err != nil {
+		if errors.Is(err, eval.ErrEvaluationTimeout) {
+			r.metrics.EvalTimeouts.WithLabelValues(fmt.Sprint(ev.rule.OrgID)).Inc()
+		}
 		return fmt.Errorf("server side expressions pipeline returned an error: %w", err)
 	}
 
@@ -265,7 +284,7 @@ func (r *recordingRule) tryEvaluation(ctx context.Context, ev *Evaluation, logge
 	}
 
 	writeStart := r.clock.Now()
-	err = r.writer.Write(ctx, ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.OrgID, ev.rule.Labels)
+	err = r.writer.Write(writer.WithTarget(ctx, ev.rule.Record.Target), ev.rule.Record.Metric, ev.scheduledAt, frames, ev.rule.OrgID, ev.rule.Labels)
 	writeDur := r.clock.Now().Sub(writeStart)
 
 	if err != nil {
@@ -289,7 +308,7 @@ func (r *recordingRule) buildAndExecutePipeline(ctx context.Context, evalCtx eva
 		logger.Error("Failed to build rule evaluator", "error", err)
 		return nil, err
 	}
-	results, err := evaluator.EvaluateRaw(ctx, ev.scheduledAt)
+	results, err := evaluator.EvaluateRaw(expr.WithQueryCache(ctx, ev.queryCache), ev.scheduledAt)
 	if err != nil {
 		logger.Error("Failed to evaluate rule", "error", err, "duration", r.clock.Now().Sub(start))
 	}
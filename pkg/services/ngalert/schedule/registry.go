@@ -12,12 +12,14 @@ import (
 	"time"
 	"unsafe"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
 
 var (
 	errRuleDeleted   = errors.New("rule deleted")
 	errRuleRestarted = errors.New("rule restarted")
+	errRuleHandedOff = errors.New("rule handed off to another instance")
 )
 
 type ruleFactory interface {
@@ -88,6 +90,9 @@ type Evaluation struct {
 	scheduledAt time.Time
 	rule        *models.AlertRule
 	folderTitle string
+	// queryCache is shared by the evaluations of the same tick, so that identical queries are executed once.
+	// It is nil if queries are not deduplicated.
+	queryCache *expr.QueryCache
 }
 
 func (e *Evaluation) Fingerprint() fingerprint {
@@ -304,11 +309,17 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 		writeBytes(tmp)
 	}
 
+	for _, inhibition := range rule.InhibitedBy {
+		binary.LittleEndian.PutUint64(tmp, uint64(inhibition.Fingerprint()))
+		writeBytes(tmp)
+	}
+
 	// fields that do not affect the state.
 	// TODO consider removing fields below from the fingerprint
 	writeInt(rule.ID)
 	writeInt(rule.OrgID)
 	writeInt(int64(rule.For))
+	writeInt(int64(rule.KeepFiringFor))
 	if rule.DashboardUID != nil {
 		writeString(*rule.DashboardUID)
 	}
@@ -319,6 +330,8 @@ func (r ruleWithFolder) Fingerprint() fingerprint {
 	writeInt(int64(rule.RuleGroupIndex))
 	writeString(string(rule.NoDataState))
 	writeString(string(rule.ExecErrState))
+	writeInt(int64(rule.EvaluationTimeout))
+	writeString(string(rule.Priority))
 	if rule.Record != nil {
 		binary.LittleEndian.PutUint64(tmp, uint64(rule.Record.Fingerprint()))
 		writeBytes(tmp)
@@ -194,6 +194,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			ExecErrState:    "test-err",
 			Record:          &models.Record{Metric: "my_metric", From: "A"},
 			For:             12,
+			KeepFiringFor:   13,
 			Annotations: map[string]string{
 				"key-annotation": "value-annotation",
 			},
@@ -209,6 +210,11 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 					SimplifiedQueryAndExpressionsSection: false,
 				},
 			},
+			InhibitedBy: []models.InhibitionRule{
+				{SourceRuleUID: "source-uid", Equal: []string{"key-label"}},
+			},
+			EvaluationTimeout: 14,
+			Priority:          models.RulePriorityCritical,
 		}
 		r2 := &models.AlertRule{
 			ID:        2,
@@ -238,6 +244,7 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 			ExecErrState:    "test-err2",
 			Record:          &models.Record{Metric: "my_metric2", From: "B"},
 			For:             1141,
+			KeepFiringFor:   1142,
 			Annotations: map[string]string{
 				"key-annotation2": "value-annotation",
 			},
@@ -253,6 +260,11 @@ func TestRuleWithFolderFingerprint(t *testing.T) {
 					SimplifiedQueryAndExpressionsSection: true,
 				},
 			},
+			InhibitedBy: []models.InhibitionRule{
+				{SourceRuleUID: "source-uid2"},
+			},
+			EvaluationTimeout: 1143,
+			Priority:          models.RulePriorityLow,
 		}
 
 		excludedFields := map[string]struct{}{
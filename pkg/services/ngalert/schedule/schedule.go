@@ -13,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
@@ -85,6 +86,8 @@ type schedule struct {
 	disableGrafanaFolder bool
 	jitterEvaluations    JitterStrategy
 	rrCfg                setting.RecordingRuleSettings
+	// deduplicateQueries makes the rules evaluated in the same tick execute identical datasource queries once.
+	deduplicateQueries bool
 
 	metrics *metrics.Scheduler
 
@@ -100,6 +103,12 @@ type schedule struct {
 	tracer tracing.Tracer
 
 	recordingWriter RecordingWriter
+
+	// sharder decides which rules are evaluated by this instance. It is nil if every instance evaluates all rules.
+	sharder *ruleSharder
+
+	// limiter bounds the number of concurrent rule evaluations. It is nil if the evaluations are not limited.
+	limiter *evaluationLimiter
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -112,6 +121,7 @@ type SchedulerCfg struct {
 	RecordingRulesCfg    setting.RecordingRuleSettings
 	AppURL               *url.URL
 	JitterEvaluations    JitterStrategy
+	DeduplicateQueries   bool
 	EvaluatorFactory     eval.EvaluatorFactory
 	RuleStore            RulesStore
 	Metrics              *metrics.Scheduler
@@ -119,6 +129,10 @@ type SchedulerCfg struct {
 	Tracer               tracing.Tracer
 	Log                  log.Logger
 	RecordingWriter      RecordingWriter
+	// ClusterMembership shards the evaluation of the rules across the instances of the cluster if it is not nil.
+	ClusterMembership ClusterMembership
+	// MaxConcurrentEvaluations is the maximum number of rules that are evaluated at the same time. Zero means unlimited.
+	MaxConcurrentEvaluations int
 }
 
 // NewScheduler returns a new scheduler.
@@ -141,6 +155,7 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		appURL:                cfg.AppURL,
 		disableGrafanaFolder:  cfg.DisableGrafanaFolder,
 		jitterEvaluations:     cfg.JitterEvaluations,
+		deduplicateQueries:    cfg.DeduplicateQueries,
 		rrCfg:                 cfg.RecordingRulesCfg,
 		stateManager:          stateManager,
 		minRuleInterval:       cfg.MinRuleInterval,
@@ -148,6 +163,10 @@ func NewScheduler(cfg SchedulerCfg, stateManager *state.Manager) *schedule {
 		alertsSender:          cfg.AlertSender,
 		tracer:                cfg.Tracer,
 		recordingWriter:       cfg.RecordingWriter,
+		limiter:               newEvaluationLimiter(cfg.MaxConcurrentEvaluations, cfg.C, cfg.Metrics),
+	}
+	if cfg.ClusterMembership != nil {
+		sch.sharder = newRuleSharder(cfg.ClusterMembership, cfg.Metrics, cfg.Log)
 	}
 
 	return &sch
@@ -194,6 +213,22 @@ func (sch *schedule) deleteAlertRule(keys ...ngmodels.AlertRuleKey) {
 	sch.updateRulesMetrics(alertRules)
 }
 
+// handOffAlertRule stops the evaluation of the rules that are owned by another instance of the cluster. The state of
+// the rules is kept in the database for the new owner but it is removed from the state cache of this instance.
+func (sch *schedule) handOffAlertRule(ctx context.Context, keys ...ngmodels.AlertRuleKey) {
+	for _, key := range keys {
+		ruleRoutine, ok := sch.registry.del(key)
+		if !ok {
+			// The rule was never evaluated by this instance, only the state loaded at startup has to be removed.
+			sch.stateManager.ForgetStateByRuleUID(ctx, key)
+			continue
+		}
+		sch.log.Debug("Alert rule is handed off to another instance", key.LogContext()...)
+		ruleRoutine.Stop(errRuleHandedOff)
+		sch.metrics.ShardingHandedOffRulesTotal.Inc()
+	}
+}
+
 func (sch *schedule) schedulePeriodic(ctx context.Context, t *ticker.T) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
 	for {
@@ -248,7 +283,21 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 
 	sch.updateRulesMetrics(alertRules)
 
+	// When the evaluation is sharded, the states of the rules loaded at startup are kept until the first tick and
+	// the states of the rules that this instance takes over are loaded from the database.
+	initialTick, rebalanced := false, false
+	if sch.sharder != nil {
+		initialTick = sch.sharder.ring == nil
+		rebalanced = sch.sharder.update()
+	}
+	handedOff := make([]ngmodels.AlertRuleKey, 0)
+	ownedRules := 0
+
 	readyToRun := make([]readyToRunItem, 0)
+	var queryCache *expr.QueryCache
+	if sch.deduplicateQueries {
+		queryCache = expr.NewQueryCache()
+	}
 	updatedRules := make([]ngmodels.AlertRuleKeyWithVersion, 0, len(updated)) // this is needed for tests only
 	restartedRules := make([]Rule, 0)
 	missingFolder := make(map[string][]string)
@@ -265,15 +314,30 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		sch.metrics,
 		sch.log,
 		sch.tracer,
+		sch.limiter,
 		sch.recordingWriter,
 		sch.evalAppliedFunc,
 		sch.stopAppliedFunc,
 	)
 	for _, item := range alertRules {
-		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		key := item.GetKey()
+		if sch.sharder != nil && !sch.sharder.owns(item) {
+			if _, registered := registeredDefinitions[key]; registered || rebalanced {
+				handedOff = append(handedOff, key)
+			}
+			delete(registeredDefinitions, key)
+			continue
+		}
+		ownedRules++
+
+		ruleRoutine, newRoutine := sch.registry.getOrCreate(ctx, item, ruleFactory)
 		logger := sch.log.FromContext(ctx).New(key.LogContext()...)
 
+		if newRoutine && sch.sharder != nil && !initialTick {
+			logger.Debug("Loading the state of the rule taken over from another instance")
+			sch.stateManager.WarmRule(ctx, item)
+		}
+
 		// enforce minimum evaluation interval
 		if item.IntervalSeconds < int64(sch.minRuleInterval.Seconds()) {
 			logger.Debug("Interval adjusted", "originalInterval", item.IntervalSeconds, "adjustedInterval", sch.minRuleInterval.Seconds())
@@ -323,6 +387,7 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 				scheduledAt: tick,
 				rule:        item,
 				folderTitle: folderTitle,
+				queryCache:  queryCache,
 			}})
 		}
 		if _, isUpdated := updated[key]; isUpdated && !isReadyToRun {
@@ -379,6 +444,11 @@ func (sch *schedule) processTick(ctx context.Context, dispatcherGroup *errgroup.
 		oldRoutine.Stop(errRuleRestarted)
 	}
 
+	if sch.sharder != nil {
+		sch.handOffAlertRule(ctx, handedOff...)
+		sch.metrics.ShardingOwnedRules.Set(float64(ownedRules))
+	}
+
 	// unregister and stop routines of the deleted alert rules
 	toDelete := make([]ngmodels.AlertRuleKey, 0, len(registeredDefinitions))
 	for key := range registeredDefinitions {
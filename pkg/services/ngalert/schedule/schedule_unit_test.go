@@ -491,6 +491,30 @@ func TestProcessTicks(t *testing.T) {
 		require.Len(t, scheduled, len(rules))
 		assert.Truef(t, slices.IsSorted(actualUids), "The scheduler rules should be sorted by UID but they aren't")
 		require.Equal(t, expectedUids, actualUids)
+		for _, item := range scheduled {
+			require.Nil(t, item.queryCache)
+		}
+	})
+
+	t.Run("rules evaluated on the same tick share the query cache when queries are deduplicated", func(t *testing.T) {
+		sched.deduplicateQueries = true
+		t.Cleanup(func() {
+			sched.deduplicateQueries = false
+		})
+
+		tick = tick.Add(cfg.BaseInterval)
+		scheduled, _, _ := sched.processTick(ctx, dispatcherGroup, tick)
+		require.NotEmpty(t, scheduled)
+		cache := scheduled[0].queryCache
+		require.NotNil(t, cache)
+		for _, item := range scheduled {
+			require.Same(t, cache, item.queryCache)
+		}
+
+		tick = tick.Add(cfg.BaseInterval)
+		scheduled, _, _ = sched.processTick(ctx, dispatcherGroup, tick)
+		require.NotEmpty(t, scheduled)
+		require.NotSame(t, cache, scheduled[0].queryCache)
 	})
 }
 
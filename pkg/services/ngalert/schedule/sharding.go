@@ -0,0 +1,134 @@
+package schedule
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// shardingTokensPerMember is the number of points every instance has on the hash ring. More points spread the rule
+// groups more evenly across the instances.
+const shardingTokensPerMember = 128
+
+// ClusterMembership provides the live instances of the high availability cluster that the evaluation of the alert
+// rules is sharded across.
+type ClusterMembership interface {
+	// Self returns the name of this instance in the cluster.
+	Self() string
+	// Members returns the names of the live instances of the cluster.
+	Members() []string
+}
+
+// hashRing is a consistent hash ring that assigns keys to its members. When a member joins or leaves the ring, only
+// the keys of the neighbours of its tokens move to another member.
+type hashRing struct {
+	members []string
+	tokens  []uint32
+	owners  []string
+}
+
+func newHashRing(members []string) *hashRing {
+	type token struct {
+		value uint32
+		owner string
+	}
+	tokens := make([]token, 0, len(members)*shardingTokensPerMember)
+	for _, m := range members {
+		for i := 0; i < shardingTokensPerMember; i++ {
+			tokens = append(tokens, token{value: hashKey(m + "-" + strconv.Itoa(i)), owner: m})
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if tokens[i].value == tokens[j].value {
+			return tokens[i].owner < tokens[j].owner
+		}
+		return tokens[i].value < tokens[j].value
+	})
+
+	r := &hashRing{
+		members: members,
+		tokens:  make([]uint32, 0, len(tokens)),
+		owners:  make([]string, 0, len(tokens)),
+	}
+	for _, t := range tokens {
+		r.tokens = append(r.tokens, t.value)
+		r.owners = append(r.owners, t.owner)
+	}
+	return r
+}
+
+// owner returns the member that owns the key, that is the owner of the first token that is greater than or equal
+// to the hash of the key.
+func (r *hashRing) owner(key string) string {
+	if len(r.tokens) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i := sort.Search(len(r.tokens), func(i int) bool {
+		return r.tokens[i] >= h
+	})
+	if i == len(r.tokens) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
+
+// ruleSharder decides which alert rules are evaluated by this instance when the evaluation is sharded across the
+// instances of a high availability cluster. The rules of a rule group are always evaluated by the same instance.
+type ruleSharder struct {
+	membership ClusterMembership
+	self       string
+	ring       *hashRing
+	metrics    *metrics.Scheduler
+	log        log.Logger
+}
+
+func newRuleSharder(membership ClusterMembership, metrics *metrics.Scheduler, logger log.Logger) *ruleSharder {
+	return &ruleSharder{
+		membership: membership,
+		metrics:    metrics,
+		log:        logger,
+	}
+}
+
+// update rebuilds the hash ring if the live instances of the cluster changed since the last update. It returns true
+// if the ring was rebuilt. This instance is always part of the ring, so that it evaluates all rules if it cannot see
+// the other instances.
+func (s *ruleSharder) update() bool {
+	self := s.membership.Self()
+	members := append([]string{self}, s.membership.Members()...)
+	slices.Sort(members)
+	members = slices.Compact(members)
+	if s.ring != nil && s.self == self && slices.Equal(s.ring.members, members) {
+		return false
+	}
+	if s.ring != nil {
+		s.log.Info("Cluster members changed, redistributing alert rules", "previous", len(s.ring.members), "current", len(members))
+		s.metrics.ShardingRebalancesTotal.Inc()
+	}
+	s.self = self
+	s.ring = newHashRing(members)
+	s.metrics.ShardingMembers.Set(float64(len(members)))
+	return true
+}
+
+// owns returns true if the rule is evaluated by this instance.
+func (s *ruleSharder) owns(rule *ngmodels.AlertRule) bool {
+	return s.ring.owner(shardKey(rule.GetGroupKey())) == s.self
+}
+
+func shardKey(key ngmodels.AlertRuleGroupKey) string {
+	return fmt.Sprintf("%d/%s/%s", key.OrgID, key.NamespaceUID, key.RuleGroup)
+}
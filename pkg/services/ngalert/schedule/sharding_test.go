@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestHashRing(t *testing.T) {
+	keys := make([]string, 0, 3000)
+	for i := 0; i < cap(keys); i++ {
+		keys = append(keys, fmt.Sprintf("1/folder-%d/group-%d", i%7, i))
+	}
+
+	t.Run("distributes the keys across all members", func(t *testing.T) {
+		ring := newHashRing([]string{"a", "b", "c"})
+		counts := make(map[string]int)
+		for _, k := range keys {
+			counts[ring.owner(k)]++
+		}
+		require.Len(t, counts, 3)
+		for member, count := range counts {
+			require.Greaterf(t, count, len(keys)/6, "member %s owns too few keys", member)
+		}
+	})
+
+	t.Run("only moves keys to a member that joins", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "b", "c", "d"})
+		moved := 0
+		for _, k := range keys {
+			if owner := after.owner(k); owner != before.owner(k) {
+				require.Equal(t, "d", owner)
+				moved++
+			}
+		}
+		require.NotZero(t, moved)
+	})
+
+	t.Run("only moves the keys of a member that leaves", func(t *testing.T) {
+		before := newHashRing([]string{"a", "b", "c"})
+		after := newHashRing([]string{"a", "c"})
+		for _, k := range keys {
+			if owner := before.owner(k); owner != "b" {
+				require.Equal(t, owner, after.owner(k))
+			}
+		}
+	})
+}
+
+func TestRuleSharder(t *testing.T) {
+	membership := &fakeClusterMembership{self: "a"}
+	sharder := newRuleSharder(membership, metrics.NewSchedulerMetrics(prometheus.NewRegistry()), log.NewNopLogger())
+
+	t.Run("owns all rules if it cannot see other members", func(t *testing.T) {
+		require.True(t, sharder.update())
+		for _, rule := range models.RuleGen.GenerateManyRef(10) {
+			require.True(t, sharder.owns(rule))
+		}
+	})
+
+	t.Run("rebuilds the ring only if the members change", func(t *testing.T) {
+		membership.members = []string{"b", "a"}
+		require.True(t, sharder.update())
+		membership.members = []string{"a", "b", "b"}
+		require.False(t, sharder.update())
+	})
+
+	t.Run("rules of the same group have the same owner", func(t *testing.T) {
+		gen := models.RuleGen
+		rules := gen.With(gen.WithGroupKey(models.GenerateGroupKey(1))).GenerateManyRef(10)
+		owned := sharder.owns(rules[0])
+		for _, rule := range rules {
+			require.Equal(t, owned, sharder.owns(rule))
+		}
+	})
+}
+
+func TestProcessTicksSharding(t *testing.T) {
+	dispatcherGroup, ctx := errgroup.WithContext(context.Background())
+	ruleStore := newFakeRulesStore()
+	instanceStore := &state.FakeInstanceStore{}
+	sch := setupScheduler(t, ruleStore, instanceStore, nil, nil, nil)
+	membership := &fakeClusterMembership{self: "a", members: []string{"a", "b"}}
+	sch.sharder = newRuleSharder(membership, sch.metrics, sch.log)
+
+	gen := models.RuleGen
+	// The interval is longer than the ticks of the test, so that the rules are not evaluated.
+	rules := gen.With(gen.WithInterval(time.Hour)).GenerateManyRef(20)
+	ruleStore.PutRule(ctx, rules...)
+
+	tick := time.Time{}.Add(time.Second)
+	sch.processTick(ctx, dispatcherGroup, tick)
+
+	var otherRules []*models.AlertRule
+	for _, rule := range rules {
+		owned := sch.sharder.owns(rule)
+		require.Equal(t, owned, sch.registry.exists(rule.GetKey()))
+		if !owned {
+			otherRules = append(otherRules, rule)
+		}
+	}
+	require.NotEmpty(t, otherRules)
+	require.Less(t, len(otherRules), len(rules))
+	require.Empty(t, instanceStore.RecordedOps(), "the state loaded at startup should be used on the first tick")
+
+	t.Run("takes over the rules of a member that leaves and loads their state", func(t *testing.T) {
+		membership.members = []string{"a"}
+		tick = tick.Add(time.Second)
+		sch.processTick(ctx, dispatcherGroup, tick)
+
+		for _, rule := range rules {
+			require.True(t, sch.registry.exists(rule.GetKey()))
+		}
+		require.Len(t, instanceStore.RecordedOps(), len(otherRules))
+		for _, rule := range otherRules {
+			require.Contains(t, instanceStore.RecordedOps(), models.ListAlertInstancesQuery{RuleOrgID: rule.OrgID, RuleUID: rule.UID})
+		}
+	})
+
+	t.Run("hands off the rules of a member that joins", func(t *testing.T) {
+		ruleFactory := ruleFactoryFromScheduler(sch)
+		routines := make([]Rule, 0, len(otherRules))
+		for _, rule := range otherRules {
+			routine, _ := sch.registry.getOrCreate(ctx, rule, ruleFactory)
+			routines = append(routines, routine)
+		}
+
+		membership.members = []string{"a", "b"}
+		tick = tick.Add(time.Second)
+		sch.processTick(ctx, dispatcherGroup, tick)
+
+		for i, rule := range otherRules {
+			require.False(t, sch.registry.exists(rule.GetKey()))
+			require.ErrorIs(t, routines[i].(*alertRule).ctx.Err(), errRuleHandedOff)
+		}
+		scheduled, _ := sch.Rules()
+		require.Len(t, scheduled, len(rules), "handed off rules should stay schedulable")
+	})
+}
+
+type fakeClusterMembership struct {
+	self    string
+	members []string
+}
+
+func (f *fakeClusterMembership) Self() string {
+	return f.self
+}
+
+func (f *fakeClusterMembership) Members() []string {
+	return f.members
+}
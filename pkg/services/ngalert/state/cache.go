@@ -52,6 +52,7 @@ func (c *cache) RegisterMetrics(r prometheus.Registerer) {
 	r.MustRegister(newAlertCountByState(eval.Pending))
 	r.MustRegister(newAlertCountByState(eval.Error))
 	r.MustRegister(newAlertCountByState(eval.NoData))
+	r.MustRegister(newAlertCountByState(eval.Suppressed))
 }
 
 func (c *cache) countAlertsBy(state eval.State) float64 {
@@ -261,6 +262,16 @@ func (c *cache) setAllStates(newStates map[int64]map[string]*ruleStates) {
 	c.states = newStates
 }
 
+// setRuleStates replaces the states of the rule.
+func (c *cache) setRuleStates(orgID int64, alertRuleUID string, states map[data.Fingerprint]*State) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	if _, ok := c.states[orgID]; !ok {
+		c.states[orgID] = make(map[string]*ruleStates)
+	}
+	c.states[orgID][alertRuleUID] = &ruleStates{states: states}
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -372,6 +383,7 @@ func (c *cache) asInstances(skipNormalState bool) []ngModels.AlertInstance {
 					ResolvedAt:        v2.ResolvedAt,
 					LastSentAt:        v2.LastSentAt,
 					ResultFingerprint: v2.ResultFingerprint.String(),
+					KeepFiringSince:   v2.KeepFiringSince,
 				})
 			}
 		}
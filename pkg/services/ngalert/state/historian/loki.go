@@ -482,6 +482,15 @@ func buildQueryTail(query models.HistoryQuery) (string, error) {
 			return "", err
 		}
 	}
+
+	matchers := make([]string, 0, len(query.Matchers))
+	for _, m := range query.Matchers {
+		matchers = append(matchers, fmt.Sprintf(" | labels_%s%s%q", m.Name, m.Type, m.Value))
+	}
+	sort.Strings(matchers)
+	for _, m := range matchers {
+		b.WriteString(m)
+	}
 	return b.String(), nil
 }
 
@@ -489,11 +498,18 @@ func queryHasLogFilters(query models.HistoryQuery) bool {
 	return query.RuleUID != "" ||
 		query.DashboardUID != "" ||
 		query.PanelID != 0 ||
-		len(query.Labels) > 0
+		len(query.Labels) > 0 ||
+		len(query.Matchers) > 0
 }
 
 func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query models.HistoryQuery) ([]string, error) {
-	bypass, err := h.ac.CanReadAllRules(ctx, query.SignedInUser)
+	return getFolderUIDsForFilter(ctx, query, h.ac, h.ruleStore)
+}
+
+// getFolderUIDsForFilter returns the UIDs of the folders that the user can read the state history of. It returns nil
+// if the user can read the history of all rules, or if the query is for a single rule that the user has access to.
+func getFolderUIDsForFilter(ctx context.Context, query models.HistoryQuery, ac AccessControl, ruleStore RuleStore) ([]string, error) {
+	bypass, err := ac.CanReadAllRules(ctx, query.SignedInUser)
 	if err != nil {
 		return nil, err
 	}
@@ -502,7 +518,7 @@ func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query mo
 	}
 	// if there is a filter by rule UID, find that rule UID and make sure that user has access to it.
 	if query.RuleUID != "" {
-		rule, err := h.ruleStore.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{
+		rule, err := ruleStore.GetAlertRuleByUID(ctx, &models.GetAlertRuleByUIDQuery{
 			UID:   query.RuleUID,
 			OrgID: query.OrgID,
 		})
@@ -512,17 +528,17 @@ func (h *RemoteLokiBackend) getFolderUIDsForFilter(ctx context.Context, query mo
 		if rule == nil {
 			return nil, models.ErrAlertRuleNotFound
 		}
-		return nil, h.ac.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
+		return nil, ac.AuthorizeAccessInFolder(ctx, query.SignedInUser, rule)
 	}
 	// if no filter, then we need to get all namespaces user has access to
-	folders, err := h.ruleStore.GetUserVisibleNamespaces(ctx, query.OrgID, query.SignedInUser)
+	folders, err := ruleStore.GetUserVisibleNamespaces(ctx, query.OrgID, query.SignedInUser)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch folders that user can access: %w", err)
 	}
 	uids := make([]string, 0, len(folders))
 	// now keep only UIDs of folder in which user can read rules.
 	for _, f := range folders {
-		hasAccess, err := h.ac.HasAccessInFolder(ctx, query.SignedInUser, models.Namespace(*f))
+		hasAccess, err := ac.HasAccessInFolder(ctx, query.SignedInUser, models.Namespace(*f))
 		if err != nil {
 			return nil, err
 		}
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
@@ -271,6 +272,17 @@ func TestBuildLogQuery(t *testing.T) {
 			},
 			exp: []string{`{orgID="123",from="state-history"} | json | ruleUID="rule-uid" | labels_customlabel="customvalue"`},
 		},
+		{
+			name: "filters instance labels by matchers in log line",
+			query: models.HistoryQuery{
+				OrgID: 123,
+				Matchers: labels.Matchers{
+					labels.MustNewMatcher(labels.MatchRegexp, "severity", "crit.*"),
+					labels.MustNewMatcher(labels.MatchNotEqual, "env", "dev"),
+				},
+			},
+			exp: []string{`{orgID="123",from="state-history"} | json | labels_env!="dev" | labels_severity=~"crit.*"`},
+		},
 		{
 			name: "should return if query does not exceed max limit",
 			query: models.HistoryQuery{
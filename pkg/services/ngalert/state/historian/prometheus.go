@@ -0,0 +1,165 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheus "github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/value"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+const (
+	// AlertsMetricName is the name of the series that has the value 1 for every pending or firing alert,
+	// like the ALERTS series of Prometheus.
+	AlertsMetricName = "ALERTS"
+	// AlertsForStateMetricName is the name of the series whose value is the Unix timestamp in seconds of when the
+	// alert entered its current state, like the ALERTS_FOR_STATE series of Prometheus.
+	AlertsForStateMetricName = "ALERTS_FOR_STATE"
+	// AlertStateLabel is the label of the ALERTS series that is either "pending" or "firing".
+	AlertStateLabel = "alertstate"
+)
+
+var ErrPrometheusQueryNotSupported = errors.New("the prometheus state history backend does not support queries, query the ALERTS series in the remote write target instead")
+
+// PointsWriter writes points to a Prometheus remote write endpoint.
+type PointsWriter interface {
+	WritePoints(ctx context.Context, points []writer.Point, orgID int64) error
+}
+
+// PrometheusBackend is a state.Historian that writes the state of the alerts as the ALERTS and ALERTS_FOR_STATE series
+// to a Prometheus remote write endpoint, in the same way as Prometheus does for its alerting rules.
+type PrometheusBackend struct {
+	writer  PointsWriter
+	metrics *metrics.Historian
+	log     log.Logger
+}
+
+func NewPrometheusBackend(logger log.Logger, w PointsWriter, metrics *metrics.Historian) *PrometheusBackend {
+	return &PrometheusBackend{
+		writer:  w,
+		metrics: metrics,
+		log:     logger,
+	}
+}
+
+// Record writes the series of the pending and firing alerts of the rule. The series of the alerts that are no longer
+// pending or firing are ended with staleness markers.
+func (h *PrometheusBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	points, transitions := StatesToPoints(states)
+
+	errCh := make(chan error, 1)
+	if len(points) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it, for the same reasons as in the Loki backend.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+		logger.Debug("Writing alert series", "samples", len(points))
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "prometheus").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(transitions))
+
+		if err := h.writer.WritePoints(ctx, points, rule.OrgID); err != nil {
+			logger.Error("Failed to write alert series", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "prometheus").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(transitions))
+			errCh <- fmt.Errorf("failed to write alert series: %w", err)
+		}
+	}(writeCtx)
+	return errCh
+}
+
+// Query is not supported, the series are queried from the Prometheus data source of the remote write target.
+func (h *PrometheusBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	return nil, ErrPrometheusQueryNotSupported
+}
+
+// StatesToPoints converts the states to the points of the ALERTS and ALERTS_FOR_STATE series. It returns the points
+// and the number of states that the points were created for.
+func StatesToPoints(states []state.StateTransition) ([]writer.Point, int) {
+	points := make([]writer.Point, 0, 2*len(states))
+	transitions := 0
+	stale := math.Float64frombits(value.StaleNaN)
+	for _, tr := range states {
+		t := tr.State.LastEvaluationTime
+		before := len(points)
+
+		current, currentActive := alertSeriesLabels(tr.State.Labels, tr.State.State)
+		if currentActive {
+			points = append(points,
+				writer.Point{Name: AlertsMetricName, Labels: withAlertState(current, tr.State.State), Metric: writer.Metric{T: t, V: 1}},
+				writer.Point{Name: AlertsForStateMetricName, Labels: current, Metric: writer.Metric{T: t, V: float64(tr.State.StartsAt.Unix())}},
+			)
+		}
+
+		// Prometheus marks the series of the alerts that are no longer active as stale, so that they end immediately.
+		previous, previousActive := alertSeriesLabels(tr.State.Labels, tr.PreviousState)
+		if previousActive {
+			previousAlerts := withAlertState(previous, tr.PreviousState)
+			if !currentActive || !maps.Equal(previousAlerts, withAlertState(current, tr.State.State)) {
+				points = append(points, writer.Point{Name: AlertsMetricName, Labels: previousAlerts, Metric: writer.Metric{T: t, V: stale}})
+			}
+			if !currentActive || !maps.Equal(previous, current) {
+				points = append(points, writer.Point{Name: AlertsForStateMetricName, Labels: previous, Metric: writer.Metric{T: t, V: stale}})
+			}
+		}
+
+		if len(points) > before {
+			transitions++
+		}
+	}
+	return points, transitions
+}
+
+// alertSeriesLabels returns the labels of the series of an alert in the state, and whether the alert is pending or
+// firing. NoData and Error alerts have the same labels as the alerts that are sent to the Alertmanager.
+func alertSeriesLabels(lbls data.Labels, s eval.State) (map[string]string, bool) {
+	switch s {
+	case eval.Pending, eval.Alerting, eval.NoData, eval.Error:
+	default:
+		return nil, false
+	}
+	result := removePrivateLabels(lbls)
+	if s == eval.NoData || s == eval.Error {
+		if name, ok := result[prometheus.AlertNameLabel]; ok {
+			result[state.Rulename] = name
+		}
+		result[prometheus.AlertNameLabel] = state.NoDataAlertName
+		if s == eval.Error {
+			result[prometheus.AlertNameLabel] = state.ErrorAlertName
+		}
+	}
+	return result, true
+}
+
+func withAlertState(lbls map[string]string, s eval.State) map[string]string {
+	result := make(map[string]string, len(lbls)+1)
+	maps.Copy(result, lbls)
+	result[AlertStateLabel] = "firing"
+	if s == eval.Pending {
+		result[AlertStateLabel] = "pending"
+	}
+	return result
+}
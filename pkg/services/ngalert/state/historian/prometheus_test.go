@@ -0,0 +1,156 @@
+package historian
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/model/value"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
+)
+
+func TestStatesToPoints(t *testing.T) {
+	now := time.Now()
+	startsAt := now.Add(-time.Minute)
+	lbls := data.Labels{"alertname": "test", "team": "a", "__alert_rule_uid__": "rule-uid"}
+	transition := func(previous, current eval.State) state.StateTransition {
+		return state.StateTransition{
+			PreviousState: previous,
+			State: &state.State{
+				State:              current,
+				Labels:             lbls,
+				StartsAt:           startsAt,
+				LastEvaluationTime: now,
+			},
+		}
+	}
+	series := func(alertname string, extra map[string]string) map[string]string {
+		result := map[string]string{"alertname": alertname, "team": "a"}
+		for k, v := range extra {
+			result[k] = v
+		}
+		return result
+	}
+	stale := math.Float64frombits(value.StaleNaN)
+
+	testCases := []struct {
+		name     string
+		tr       state.StateTransition
+		expected []writer.Point
+	}{
+		{
+			name: "writes pending alerts",
+			tr:   transition(eval.Normal, eval.Pending),
+			expected: []writer.Point{
+				{Name: AlertsMetricName, Labels: series("test", map[string]string{"alertstate": "pending"}), Metric: writer.Metric{T: now, V: 1}},
+				{Name: AlertsForStateMetricName, Labels: series("test", nil), Metric: writer.Metric{T: now, V: float64(startsAt.Unix())}},
+			},
+		},
+		{
+			name: "writes firing alerts and marks the pending series as stale",
+			tr:   transition(eval.Pending, eval.Alerting),
+			expected: []writer.Point{
+				{Name: AlertsMetricName, Labels: series("test", map[string]string{"alertstate": "firing"}), Metric: writer.Metric{T: now, V: 1}},
+				{Name: AlertsForStateMetricName, Labels: series("test", nil), Metric: writer.Metric{T: now, V: float64(startsAt.Unix())}},
+				{Name: AlertsMetricName, Labels: series("test", map[string]string{"alertstate": "pending"}), Metric: writer.Metric{T: now, V: stale}},
+			},
+		},
+		{
+			name: "marks the series of resolved alerts as stale",
+			tr:   transition(eval.Alerting, eval.Normal),
+			expected: []writer.Point{
+				{Name: AlertsMetricName, Labels: series("test", map[string]string{"alertstate": "firing"}), Metric: writer.Metric{T: now, V: stale}},
+				{Name: AlertsForStateMetricName, Labels: series("test", nil), Metric: writer.Metric{T: now, V: stale}},
+			},
+		},
+		{
+			name: "writes no data alerts with the labels sent to the Alertmanager",
+			tr:   transition(eval.NoData, eval.NoData),
+			expected: []writer.Point{
+				{Name: AlertsMetricName, Labels: series(state.NoDataAlertName, map[string]string{"alertstate": "firing", "rulename": "test"}), Metric: writer.Metric{T: now, V: 1}},
+				{Name: AlertsForStateMetricName, Labels: series(state.NoDataAlertName, map[string]string{"rulename": "test"}), Metric: writer.Metric{T: now, V: float64(startsAt.Unix())}},
+			},
+		},
+		{
+			name:     "does not write normal alerts",
+			tr:       transition(eval.Normal, eval.Normal),
+			expected: []writer.Point{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			points, transitions := StatesToPoints([]state.StateTransition{tc.tr})
+
+			require.Len(t, points, len(tc.expected))
+			for i, p := range points {
+				require.Equal(t, tc.expected[i].Name, p.Name)
+				require.Equal(t, tc.expected[i].Labels, p.Labels)
+				require.Equal(t, tc.expected[i].Metric.T, p.Metric.T)
+				if value.IsStaleNaN(tc.expected[i].Metric.V) {
+					require.True(t, value.IsStaleNaN(p.Metric.V))
+				} else {
+					require.Equal(t, tc.expected[i].Metric.V, p.Metric.V)
+				}
+			}
+			if len(tc.expected) > 0 {
+				require.Equal(t, 1, transitions)
+			}
+		})
+	}
+}
+
+func TestPrometheusBackend(t *testing.T) {
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	states := singleFromNormal(&state.State{State: eval.Alerting, Labels: data.Labels{"alertname": "test"}})
+
+	t.Run("writes the series of the states", func(t *testing.T) {
+		w := &fakePointsWriter{}
+		backend := NewPrometheusBackend(log.NewNopLogger(), w, met)
+
+		err := <-backend.Record(context.Background(), createTestRule(), states)
+
+		require.NoError(t, err)
+		require.Len(t, w.points, 2)
+		require.Equal(t, int64(1), w.orgID)
+	})
+
+	t.Run("returns an error if the series cannot be written", func(t *testing.T) {
+		w := &fakePointsWriter{err: errors.New("failed")}
+		backend := NewPrometheusBackend(log.NewNopLogger(), w, met)
+
+		err := <-backend.Record(context.Background(), createTestRule(), states)
+
+		require.ErrorContains(t, err, "failed to write alert series")
+	})
+
+	t.Run("does not support queries", func(t *testing.T) {
+		backend := NewPrometheusBackend(log.NewNopLogger(), &fakePointsWriter{}, met)
+
+		_, err := backend.Query(context.Background(), models.HistoryQuery{})
+
+		require.ErrorIs(t, err, ErrPrometheusQueryNotSupported)
+	})
+}
+
+type fakePointsWriter struct {
+	points []writer.Point
+	orgID  int64
+	err    error
+}
+
+func (f *fakePointsWriter) WritePoints(_ context.Context, points []writer.Point, orgID int64) error {
+	f.points = append(f.points, points...)
+	f.orgID = orgID
+	return f.err
+}
@@ -0,0 +1,325 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	history_model "github.com/grafana/grafana/pkg/services/ngalert/state/historian/model"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// sqlQueryPageSize is the number of entries that are loaded from the database at once, when the entries have to be
+// filtered by their labels.
+const sqlQueryPageSize = 1000
+
+type StateHistoryStore interface {
+	SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error
+	FindStateHistory(ctx context.Context, query models.StateHistoryEntryQuery) ([]models.StateHistoryEntry, error)
+}
+
+type StateHistoryAdminStore interface {
+	// DeleteStateHistoryBefore deletes the state history of evaluations before the time. It returns the number of
+	// deleted entries or an error.
+	DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// DeleteExpiredService is a service to delete the state history that is stored by the SQL backend for longer than
+// the configured retention.
+type DeleteExpiredService struct {
+	cfg   setting.UnifiedAlertingStateHistorySettings
+	store StateHistoryAdminStore
+}
+
+func ProvideDeleteExpiredService(cfg *setting.Cfg, store *store.DBstore) *DeleteExpiredService {
+	return &DeleteExpiredService{cfg: cfg.UnifiedAlerting.StateHistory, store: store}
+}
+
+// DeleteExpired deletes the expired state history. It does nothing if the state history is not written to the SQL
+// backend or if it is kept forever.
+func (s *DeleteExpiredService) DeleteExpired(ctx context.Context) (int64, error) {
+	if !s.cfg.Enabled || s.cfg.SQLRetention <= 0 || !usesSQLBackend(s.cfg) {
+		return 0, nil
+	}
+	return s.store.DeleteStateHistoryBefore(ctx, time.Now().Add(-s.cfg.SQLRetention))
+}
+
+func usesSQLBackend(cfg setting.UnifiedAlertingStateHistorySettings) bool {
+	backends := []string{cfg.Backend}
+	if backend, _ := ParseBackendType(cfg.Backend); backend == BackendTypeMultiple {
+		backends = append([]string{cfg.MultiPrimary}, cfg.MultiSecondaries...)
+	}
+	for _, b := range backends {
+		if backend, err := ParseBackendType(b); err == nil && backend == BackendTypeSQL {
+			return true
+		}
+	}
+	return false
+}
+
+// SQLBackend is a state.Historian that records state history to the Grafana database.
+type SQLBackend struct {
+	store     StateHistoryStore
+	ruleStore RuleStore
+	ac        AccessControl
+	metrics   *metrics.Historian
+	log       log.Logger
+}
+
+func NewSQLBackend(logger log.Logger, store StateHistoryStore, ruleStore RuleStore, metrics *metrics.Historian, ac AccessControl) *SQLBackend {
+	return &SQLBackend{
+		store:     store,
+		ruleStore: ruleStore,
+		ac:        ac,
+		metrics:   metrics,
+		log:       logger,
+	}
+}
+
+// Record writes a number of state transitions for a given rule to the database.
+func (h *SQLBackend) Record(ctx context.Context, rule history_model.RuleMeta, states []state.StateTransition) <-chan error {
+	logger := h.log.FromContext(ctx)
+	entries := statesToHistoryEntries(rule, states, logger)
+
+	errCh := make(chan error, 1)
+	if len(entries) == 0 {
+		close(errCh)
+		return errCh
+	}
+
+	// This is a new background job, so let's create a brand new context for it, for the same reasons as in the Loki backend.
+	writeCtx := context.Background()
+	writeCtx, cancel := context.WithTimeout(writeCtx, StateHistoryWriteTimeout)
+	writeCtx = history_model.WithRuleData(writeCtx, rule)
+	writeCtx = trace.ContextWithSpan(writeCtx, trace.SpanFromContext(ctx))
+
+	go func(ctx context.Context) {
+		defer cancel()
+		defer close(errCh)
+		logger := h.log.FromContext(ctx)
+		logger.Debug("Saving state history batch", "samples", len(entries))
+		org := fmt.Sprint(rule.OrgID)
+		h.metrics.WritesTotal.WithLabelValues(org, "sql").Inc()
+		h.metrics.TransitionsTotal.WithLabelValues(org).Add(float64(len(entries)))
+
+		if err := h.store.SaveStateHistory(ctx, entries); err != nil {
+			logger.Error("Failed to save alert state history batch", "error", err)
+			h.metrics.WritesFailed.WithLabelValues(org, "sql").Inc()
+			h.metrics.TransitionsFailed.WithLabelValues(org).Add(float64(len(entries)))
+			errCh <- fmt.Errorf("failed to save alert state history batch: %w", err)
+			return
+		}
+		logger.Debug("Done saving alert state history batch", "samples", len(entries))
+	}(writeCtx)
+	return errCh
+}
+
+// Query retrieves state history entries from the database and formats the results into a dataframe in the same
+// format as the Loki backend.
+func (h *SQLBackend) Query(ctx context.Context, query models.HistoryQuery) (*data.Frame, error) {
+	uids, err := getFolderUIDsForFilter(ctx, query, h.ac, h.ruleStore)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if query.To.IsZero() {
+		query.To = now
+	}
+	if query.From.IsZero() {
+		query.From = now.Add(-defaultQueryRange)
+	}
+	limit := query.Limit
+	if limit < 1 {
+		limit = defaultPageSize
+	}
+	if limit > maximumPageSize {
+		limit = maximumPageSize
+	}
+
+	filterLabels := len(query.Labels) > 0 || len(query.Matchers) > 0
+	pageSize := limit
+	if filterLabels {
+		pageSize = sqlQueryPageSize
+	}
+	q := models.StateHistoryEntryQuery{
+		OrgID:        query.OrgID,
+		RuleUID:      query.RuleUID,
+		DashboardUID: query.DashboardUID,
+		PanelID:      query.PanelID,
+		FolderUIDs:   uids,
+		From:         query.From,
+		To:           query.To,
+		Limit:        pageSize,
+	}
+
+	result := make([]models.StateHistoryEntry, 0, limit)
+	for len(result) < limit {
+		page, err := h.store.FindStateHistory(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range page {
+			if filterLabels {
+				lbls, err := decodeEntryLabels(entry)
+				if err != nil {
+					return nil, err
+				}
+				if !matchesLabels(lbls, query) {
+					continue
+				}
+			}
+			result = append(result, entry)
+			if len(result) == limit {
+				break
+			}
+		}
+		if len(page) < pageSize {
+			break
+		}
+		q.Offset += pageSize
+	}
+
+	return entriesToFrame(result)
+}
+
+func statesToHistoryEntries(rule history_model.RuleMeta, states []state.StateTransition, logger log.Logger) []models.StateHistoryEntry {
+	entries := make([]models.StateHistoryEntry, 0, len(states))
+	for _, state := range states {
+		if !shouldRecord(state) {
+			continue
+		}
+
+		sanitizedLabels := removePrivateLabels(state.Labels)
+		lbls, err := json.Marshal(sanitizedLabels)
+		if err != nil {
+			logger.Error("Failed to encode labels of state, skipping", "error", err)
+			continue
+		}
+		values, err := json.Marshal(valuesAsDataBlob(state.State))
+		if err != nil {
+			logger.Error("Failed to encode values of state, skipping", "error", err)
+			continue
+		}
+
+		entry := models.StateHistoryEntry{
+			OrgID:         rule.OrgID,
+			RuleUID:       rule.UID,
+			RuleID:        rule.ID,
+			RuleTitle:     rule.Title,
+			RuleGroup:     rule.Group,
+			RuleCondition: rule.Condition,
+			FolderUID:     rule.NamespaceUID,
+			DashboardUID:  rule.DashboardUID,
+			PanelID:       rule.PanelID,
+			Fingerprint:   labelFingerprint(sanitizedLabels),
+			Labels:        string(lbls),
+			PreviousState: state.PreviousFormatted(),
+			CurrentState:  state.Formatted(),
+			Values:        string(values),
+			EvaluatedAt:   state.State.LastEvaluationTime.UnixMilli(),
+		}
+		if state.State.State == eval.Error && state.Error != nil {
+			entry.ErrorMessage = state.Error.Error()
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func decodeEntryLabels(entry models.StateHistoryEntry) (map[string]string, error) {
+	lbls := make(map[string]string)
+	if entry.Labels == "" {
+		return lbls, nil
+	}
+	if err := json.Unmarshal([]byte(entry.Labels), &lbls); err != nil {
+		return nil, fmt.Errorf("failed to decode labels of state history entry %d: %w", entry.ID, err)
+	}
+	return lbls, nil
+}
+
+// matchesLabels returns true if the labels of the alert instance match both the labels and the matchers of the query.
+func matchesLabels(lbls map[string]string, query models.HistoryQuery) bool {
+	for k, v := range query.Labels {
+		if lbls[k] != v {
+			return false
+		}
+	}
+	for _, m := range query.Matchers {
+		if !m.Matches(lbls[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// entriesToFrame converts the entries, newest first, to a frame with the oldest entry first.
+func entriesToFrame(entries []models.StateHistoryEntry) (*data.Frame, error) {
+	times := make([]time.Time, 0, len(entries))
+	lines := make([]json.RawMessage, 0, len(entries))
+	labels := make([]json.RawMessage, 0, len(entries))
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		instanceLabels, err := decodeEntryLabels(e)
+		if err != nil {
+			return nil, err
+		}
+		values := simplejson.New()
+		if e.Values != "" {
+			values, err = simplejson.NewJson([]byte(e.Values))
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode values of state history entry %d: %w", e.ID, err)
+			}
+		}
+		line, err := json.Marshal(LokiEntry{
+			SchemaVersion:  1,
+			Previous:       e.PreviousState,
+			Current:        e.CurrentState,
+			Error:          e.ErrorMessage,
+			Values:         values,
+			Condition:      e.RuleCondition,
+			DashboardUID:   e.DashboardUID,
+			PanelID:        e.PanelID,
+			Fingerprint:    e.Fingerprint,
+			RuleTitle:      e.RuleTitle,
+			RuleID:         e.RuleID,
+			RuleUID:        e.RuleUID,
+			InstanceLabels: instanceLabels,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize state history entry %d: %w", e.ID, err)
+		}
+		lbls, err := json.Marshal(map[string]string{
+			StateHistoryLabelKey: StateHistoryLabelValue,
+			OrgIDLabel:           fmt.Sprint(e.OrgID),
+			GroupLabel:           e.RuleGroup,
+			FolderUIDLabel:       e.FolderUID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize labels of state history entry %d: %w", e.ID, err)
+		}
+
+		times = append(times, time.UnixMilli(e.EvaluatedAt))
+		lines = append(lines, line)
+		labels = append(labels, lbls)
+	}
+
+	frame := data.NewFrame("states")
+	lbls := data.Labels(map[string]string{})
+	frame.Fields = append(frame.Fields, data.NewField(dfTime, lbls, times))
+	frame.Fields = append(frame.Fields, data.NewField(dfLine, lbls, lines))
+	frame.Fields = append(frame.Fields, data.NewField(dfLabels, lbls, labels))
+	return frame, nil
+}
@@ -0,0 +1,210 @@
+package historian
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	acfakes "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol/fakes"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSQLBackend(t *testing.T) {
+	t.Run("records state transitions with the labels of the instances", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sql := createTestSQLBackend(store)
+		rule := createTestRule()
+		now := time.Now()
+		states := singleFromNormal(&state.State{
+			State:              eval.Alerting,
+			Labels:             data.Labels{"a": "b", "__private__": "c"},
+			Values:             map[string]float64{"A": 2},
+			LastEvaluationTime: now,
+		})
+
+		err := <-sql.Record(context.Background(), rule, states)
+
+		require.NoError(t, err)
+		require.Len(t, store.entries, 1)
+		entry := store.entries[0]
+		require.Equal(t, rule.UID, entry.RuleUID)
+		require.Equal(t, rule.NamespaceUID, entry.FolderUID)
+		require.Equal(t, rule.Group, entry.RuleGroup)
+		require.Equal(t, "Normal", entry.PreviousState)
+		require.Equal(t, "Alerting", entry.CurrentState)
+		require.JSONEq(t, `{"a":"b"}`, entry.Labels)
+		require.JSONEq(t, `{"A":2}`, entry.Values)
+		require.Equal(t, now.UnixMilli(), entry.EvaluatedAt)
+	})
+
+	t.Run("does not record states that did not change", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sql := createTestSQLBackend(store)
+		states := []state.StateTransition{{PreviousState: eval.Normal, State: &state.State{State: eval.Normal}}}
+
+		err := <-sql.Record(context.Background(), createTestRule(), states)
+
+		require.NoError(t, err)
+		require.Empty(t, store.entries)
+	})
+
+	t.Run("returns an error if the entries cannot be saved", func(t *testing.T) {
+		store := &fakeStateHistoryStore{err: errors.New("failed")}
+		sql := createTestSQLBackend(store)
+		states := singleFromNormal(&state.State{State: eval.Alerting, Labels: data.Labels{"a": "b"}})
+
+		err := <-sql.Record(context.Background(), createTestRule(), states)
+
+		require.ErrorContains(t, err, "failed to save alert state history batch")
+	})
+
+	t.Run("queries the entries that match the labels and matchers, oldest first", func(t *testing.T) {
+		store := &fakeStateHistoryStore{}
+		sql := createTestSQLBackend(store)
+		rule := createTestRule()
+		now := time.Now()
+		for i, severity := range []string{"critical", "warning", "critical", "critical"} {
+			states := singleFromNormal(&state.State{
+				State:              eval.Alerting,
+				Labels:             data.Labels{"severity": severity, "team": "a"},
+				LastEvaluationTime: now.Add(time.Duration(i-4) * time.Minute),
+			})
+			require.NoError(t, <-sql.Record(context.Background(), rule, states))
+		}
+
+		frame, err := sql.Query(context.Background(), models.HistoryQuery{
+			OrgID:    rule.OrgID,
+			Labels:   map[string]string{"team": "a"},
+			Matchers: labels.Matchers{labels.MustNewMatcher(labels.MatchRegexp, "severity", "crit.*")},
+			Limit:    2,
+		})
+
+		require.NoError(t, err)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, now.Add(-2*time.Minute).UnixMilli(), frame.Fields[0].At(0).(time.Time).UnixMilli())
+		require.Equal(t, now.Add(-time.Minute).UnixMilli(), frame.Fields[0].At(1).(time.Time).UnixMilli())
+
+		var entry LokiEntry
+		require.NoError(t, json.Unmarshal(frame.Fields[1].At(0).(json.RawMessage), &entry))
+		require.Equal(t, rule.UID, entry.RuleUID)
+		require.Equal(t, map[string]string{"severity": "critical", "team": "a"}, entry.InstanceLabels)
+		var lbls map[string]string
+		require.NoError(t, json.Unmarshal(frame.Fields[2].At(0).(json.RawMessage), &lbls))
+		require.Equal(t, rule.NamespaceUID, lbls[FolderUIDLabel])
+	})
+}
+
+func TestDeleteExpiredService(t *testing.T) {
+	testCases := []struct {
+		name     string
+		cfg      setting.UnifiedAlertingStateHistorySettings
+		expected bool
+	}{
+		{
+			name:     "deletes if the sql backend is used",
+			cfg:      setting.UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "sql", SQLRetention: time.Hour},
+			expected: true,
+		},
+		{
+			name:     "deletes if the sql backend is a secondary backend",
+			cfg:      setting.UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "multiple", MultiPrimary: "loki", MultiSecondaries: []string{"sql"}, SQLRetention: time.Hour},
+			expected: true,
+		},
+		{
+			name: "does not delete if another backend is used",
+			cfg:  setting.UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "annotations", SQLRetention: time.Hour},
+		},
+		{
+			name: "does not delete if the history is kept forever",
+			cfg:  setting.UnifiedAlertingStateHistorySettings{Enabled: true, Backend: "sql"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &fakeStateHistoryStore{}
+			svc := &DeleteExpiredService{cfg: tc.cfg, store: store}
+
+			_, err := svc.DeleteExpired(context.Background())
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, !store.deletedBefore.IsZero())
+		})
+	}
+}
+
+func createTestSQLBackend(store StateHistoryStore) *SQLBackend {
+	ac := &acfakes.FakeRuleService{}
+	ac.CanReadAllRulesFunc = func(ctx context.Context, requester identity.Requester) (bool, error) {
+		return true, nil
+	}
+	met := metrics.NewHistorianMetrics(prometheus.NewRegistry(), metrics.Subsystem)
+	return NewSQLBackend(log.NewNopLogger(), store, nil, met, ac)
+}
+
+type fakeStateHistoryStore struct {
+	mtx           sync.Mutex
+	entries       []models.StateHistoryEntry
+	deletedBefore time.Time
+	err           error
+}
+
+func (f *fakeStateHistoryStore) SaveStateHistory(_ context.Context, entries []models.StateHistoryEntry) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	for _, e := range entries {
+		e.ID = int64(len(f.entries) + 1)
+		f.entries = append(f.entries, e)
+	}
+	return nil
+}
+
+func (f *fakeStateHistoryStore) FindStateHistory(_ context.Context, query models.StateHistoryEntryQuery) ([]models.StateHistoryEntry, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	var result []models.StateHistoryEntry
+	for _, e := range f.entries {
+		if e.OrgID != query.OrgID || (query.RuleUID != "" && e.RuleUID != query.RuleUID) {
+			continue
+		}
+		if e.EvaluatedAt < query.From.UnixMilli() || e.EvaluatedAt > query.To.UnixMilli() {
+			continue
+		}
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].EvaluatedAt > result[j].EvaluatedAt
+	})
+	if query.Offset >= len(result) {
+		return nil, nil
+	}
+	result = result[query.Offset:]
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, nil
+}
+
+func (f *fakeStateHistoryStore) DeleteStateHistoryBefore(_ context.Context, before time.Time) (int64, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.deletedBefore = before
+	return 0, nil
+}
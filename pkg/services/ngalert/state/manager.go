@@ -56,6 +56,7 @@ type Manager struct {
 	doNotSaveNormalState           bool
 	applyNoDataAndErrorToAllStates bool
 	rulesPerRuleGroupLimit         int64
+	storedInhibitionSources        bool
 
 	persister StatePersister
 }
@@ -81,6 +82,11 @@ type ManagerCfg struct {
 	// Duration for which a resolved alert state transition will continue to be sent to the Alertmanager.
 	ResolvedRetention time.Duration
 
+	// ShardedEvaluation must be set when the evaluation of the rules is sharded across the instances of a cluster.
+	// The states of the rules that inhibit a rule are then read from the instance store if they are not in the cache,
+	// as the rules can be evaluated by other instances.
+	ShardedEvaluation bool
+
 	Tracer tracing.Tracer
 	Log    log.Logger
 }
@@ -107,6 +113,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
 		applyNoDataAndErrorToAllStates: cfg.ApplyNoDataAndErrorToAllStates,
 		rulesPerRuleGroupLimit:         cfg.RulesPerRuleGroupLimit,
+		storedInhibitionSources:        cfg.ShardedEvaluation,
 		persister:                      statePersister,
 		tracer:                         cfg.Tracer,
 	}
@@ -187,44 +194,14 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 				continue
 			}
 
-			// nil safety.
-			annotations := ruleForEntry.Annotations
-			if annotations == nil {
-				annotations = make(map[string]string)
-			}
-
 			rulesStates, ok := orgStates[entry.RuleUID]
 			if !ok {
 				rulesStates = &ruleStates{states: make(map[data.Fingerprint]*State)}
 				orgStates[entry.RuleUID] = rulesStates
 			}
 
-			lbs := map[string]string(entry.Labels)
-			cacheID := entry.Labels.Fingerprint()
-			var resultFp data.Fingerprint
-			if entry.ResultFingerprint != "" {
-				fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
-				if err != nil {
-					st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
-				}
-				resultFp = data.Fingerprint(fp)
-			}
-			rulesStates.states[cacheID] = &State{
-				AlertRuleUID:         entry.RuleUID,
-				OrgID:                entry.RuleOrgID,
-				CacheID:              cacheID,
-				Labels:               lbs,
-				State:                translateInstanceState(entry.CurrentState),
-				StateReason:          entry.CurrentReason,
-				LastEvaluationString: "",
-				StartsAt:             entry.CurrentStateSince,
-				EndsAt:               entry.CurrentStateEnd,
-				LastEvaluationTime:   entry.LastEvalTime,
-				Annotations:          annotations,
-				ResultFingerprint:    resultFp,
-				ResolvedAt:           entry.ResolvedAt,
-				LastSentAt:           entry.LastSentAt,
-			}
+			s := st.stateFromInstance(entry, ruleForEntry)
+			rulesStates.states[s.CacheID] = s
 			statesCount++
 		}
 	}
@@ -233,6 +210,75 @@ func (st *Manager) Warm(ctx context.Context, rulesReader RuleReader) {
 	st.log.Info("State cache has been initialized", "states", statesCount, "duration", time.Since(startTime))
 }
 
+// WarmRule replaces the states of the rule in the cache with the states in the instance store. It is used when the
+// evaluation of the rule is taken over from another instance of a high availability cluster.
+func (st *Manager) WarmRule(ctx context.Context, rule *ngModels.AlertRule) {
+	if st.instanceStore == nil {
+		return
+	}
+	logger := st.log.FromContext(ctx).New(rule.GetKey().LogContext()...)
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: rule.OrgID,
+		RuleUID:   rule.UID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch previous state of the rule", "error", err)
+		return
+	}
+	states := make(map[data.Fingerprint]*State, len(alertInstances))
+	for _, entry := range alertInstances {
+		s := st.stateFromInstance(entry, rule)
+		states[s.CacheID] = s
+	}
+	st.cache.setRuleStates(rule.OrgID, rule.UID, states)
+	logger.Debug("State of the rule has been loaded", "states", len(states))
+}
+
+// ForgetStateByRuleUID removes the rule instances from the cache but keeps them in the instance store, so that
+// another instance of a high availability cluster can continue the evaluation of the rule from the same state.
+func (st *Manager) ForgetStateByRuleUID(ctx context.Context, ruleKey ngModels.AlertRuleKey) {
+	states := st.cache.removeByRuleUID(ruleKey.OrgID, ruleKey.UID)
+	if len(states) > 0 {
+		st.log.FromContext(ctx).Debug("Removed state of the rule from the cache", append(ruleKey.LogContext(), "states", len(states))...)
+	}
+}
+
+func (st *Manager) stateFromInstance(entry *ngModels.AlertInstance, rule *ngModels.AlertRule) *State {
+	// nil safety.
+	annotations := rule.Annotations
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	lbs := map[string]string(entry.Labels)
+	cacheID := entry.Labels.Fingerprint()
+	var resultFp data.Fingerprint
+	if entry.ResultFingerprint != "" {
+		fp, err := strconv.ParseUint(entry.ResultFingerprint, 16, 64)
+		if err != nil {
+			st.log.Error("Failed to parse result fingerprint of alert instance", "error", err, "ruleUID", entry.RuleUID)
+		}
+		resultFp = data.Fingerprint(fp)
+	}
+	return &State{
+		AlertRuleUID:         entry.RuleUID,
+		OrgID:                entry.RuleOrgID,
+		CacheID:              cacheID,
+		Labels:               lbs,
+		State:                translateInstanceState(entry.CurrentState),
+		StateReason:          entry.CurrentReason,
+		LastEvaluationString: "",
+		StartsAt:             entry.CurrentStateSince,
+		EndsAt:               entry.CurrentStateEnd,
+		LastEvaluationTime:   entry.LastEvalTime,
+		Annotations:          annotations,
+		ResultFingerprint:    resultFp,
+		ResolvedAt:           entry.ResolvedAt,
+		LastSentAt:           entry.LastSentAt,
+		KeepFiringSince:      entry.KeepFiringSince,
+	}
+}
+
 func (st *Manager) Get(orgID int64, alertRuleUID string, stateId data.Fingerprint) *State {
 	return st.cache.get(orgID, alertRuleUID, stateId)
 }
@@ -330,7 +376,8 @@ func (st *Manager) ProcessEvalResults(
 
 	logger := st.log.FromContext(ctx)
 	logger.Debug("State manager processing evaluation results", "resultCount", len(results))
-	states := st.setNextStateForRule(ctx, alertRule, results, extraLabels, logger)
+	inhibitions := st.getInhibitionSources(ctx, alertRule, logger)
+	states := st.setNextStateForRule(ctx, alertRule, results, extraLabels, inhibitions, logger)
 
 	staleStates := st.deleteStaleStatesFromCache(ctx, logger, evaluatedAt, alertRule)
 	span.AddEvent("results processed", trace.WithAttributes(
@@ -374,7 +421,7 @@ func (st *Manager) updateLastSentAt(states StateTransitions, evaluatedAt time.Ti
 	return result
 }
 
-func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels, logger log.Logger) []StateTransition {
+func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.AlertRule, results eval.Results, extraLabels data.Labels, inhibitions inhibitionSources, logger log.Logger) []StateTransition {
 	if st.applyNoDataAndErrorToAllStates && results.IsNoData() && (alertRule.NoDataState == ngModels.Alerting || alertRule.NoDataState == ngModels.OK || alertRule.NoDataState == ngModels.KeepLast) { // If it is no data, check the mapping and switch all results to the new state
 		// aggregate UID of datasources that returned NoData into one and provide as auxiliary info via annotationa. See: https://github.com/grafana/grafana/issues/88184
 		var refIds strings.Builder
@@ -398,7 +445,7 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 				}
 			}
 		}
-		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], inhibitions, logger)
 		if len(transitions) > 0 {
 			for _, t := range transitions {
 				if t.State.Annotations == nil {
@@ -412,7 +459,7 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 	}
 	if st.applyNoDataAndErrorToAllStates && results.IsError() && (alertRule.ExecErrState == ngModels.AlertingErrState || alertRule.ExecErrState == ngModels.OkErrState || alertRule.ExecErrState == ngModels.KeepLastErrState) {
 		// TODO squash all errors into one, and provide as annotation
-		transitions := st.setNextStateForAll(ctx, alertRule, results[0], logger)
+		transitions := st.setNextStateForAll(ctx, alertRule, results[0], inhibitions, logger)
 		if len(transitions) > 0 {
 			return transitions // if there are no current states for the rule. Create ones for each result
 		}
@@ -420,24 +467,24 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 	transitions := make([]StateTransition, 0, len(results))
 	for _, result := range results {
 		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
-		s := st.setNextState(ctx, alertRule, currentState, result, logger)
+		s := st.setNextState(ctx, alertRule, currentState, result, inhibitions, logger)
 		transitions = append(transitions, s)
 	}
 	return transitions
 }
 
-func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, logger log.Logger) []StateTransition {
+func (st *Manager) setNextStateForAll(ctx context.Context, alertRule *ngModels.AlertRule, result eval.Result, inhibitions inhibitionSources, logger log.Logger) []StateTransition {
 	currentStates := st.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID, false)
 	transitions := make([]StateTransition, 0, len(currentStates))
 	for _, currentState := range currentStates {
-		t := st.setNextState(ctx, alertRule, currentState, result, logger)
+		t := st.setNextState(ctx, alertRule, currentState, result, inhibitions, logger)
 		transitions = append(transitions, t)
 	}
 	return transitions
 }
 
 // Set the current state based on evaluation results
-func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, logger log.Logger) StateTransition {
+func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRule, currentState *State, result eval.Result, inhibitions inhibitionSources, logger log.Logger) StateTransition {
 	start := st.clock.Now()
 
 	currentState.LastEvaluationTime = result.EvaluatedAt
@@ -503,13 +550,23 @@ func (st *Manager) setNextState(ctx context.Context, alertRule *ngModels.AlertRu
 		currentState.StateReason = resultStateReason(result, alertRule)
 	}
 
+	if currentState.KeepFiringSince != nil {
+		if currentState.StateReason == "" {
+			currentState.StateReason = ngModels.StateReasonKeepFiring
+		} else {
+			currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, ngModels.StateReasonKeepFiring)
+		}
+	}
+
+	st.applyInhibitions(alertRule, currentState, result.EvaluatedAt, inhibitions, logger)
+
 	// Set Resolved property so the scheduler knows to send a postable alert
-	// to Alertmanager.
+	// to Alertmanager. Alerts that become suppressed are resolved in the Alertmanager too.
 	newlyResolved := false
-	if oldState == eval.Alerting && currentState.State == eval.Normal {
+	if oldState == eval.Alerting && (currentState.State == eval.Normal || currentState.State == eval.Suppressed) {
 		currentState.ResolvedAt = &result.EvaluatedAt
 		newlyResolved = true
-	} else if currentState.State != eval.Normal && currentState.State != eval.Pending { // Retain the last resolved time for Normal->Normal and Normal->Pending.
+	} else if currentState.State != eval.Normal && currentState.State != eval.Pending && currentState.State != eval.Suppressed { // Retain the last resolved time for Normal->Normal, Normal->Pending and Suppressed->Suppressed.
 		currentState.ResolvedAt = nil
 	}
 
@@ -562,6 +619,97 @@ func (st *Manager) Put(states []*State) {
 	}
 }
 
+// applyInhibitions suppresses the Alerting state if it is inhibited by a firing alert of another rule, and makes
+// a Suppressed state Alerting again when it is no longer inhibited. Suppressed states keep their start time but
+// are not sent to the Alertmanager.
+func (st *Manager) applyInhibitions(alertRule *ngModels.AlertRule, currentState *State, evaluatedAt time.Time, inhibitions inhibitionSources, logger log.Logger) {
+	if currentState.State != eval.Alerting && currentState.State != eval.Suppressed {
+		return
+	}
+	inhibitedBy, inhibited := inhibitingRule(alertRule, currentState, inhibitions)
+	switch {
+	case inhibited && currentState.State == eval.Alerting:
+		logger.Debug("Changing state", "previous_state", currentState.State, "next_state", eval.Suppressed, "inhibited_by", inhibitedBy)
+		currentState.State = eval.Suppressed
+		currentState.EndsAt = evaluatedAt
+	case inhibited:
+		currentState.EndsAt = evaluatedAt
+	case currentState.State == eval.Suppressed:
+		logger.Debug("Changing state", "previous_state", currentState.State, "next_state", eval.Alerting)
+		currentState.State = eval.Alerting
+		currentState.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+		// the alert was resolved in the Alertmanager when it was suppressed, so it must be sent again right away
+		currentState.LastSentAt = nil
+		return
+	default:
+		return
+	}
+	if currentState.StateReason == "" {
+		currentState.StateReason = ngModels.StateReasonInhibited
+	} else {
+		currentState.StateReason = ngModels.ConcatReasons(currentState.StateReason, ngModels.StateReasonInhibited)
+	}
+}
+
+// inhibitionSources are the states of the rules that an alert rule is inhibited by, by rule UID.
+type inhibitionSources map[string][]*State
+
+// getInhibitionSources returns the states of the rules that the alert rule is inhibited by. They are read once per
+// evaluation of the rule. When the evaluation is sharded, the states of the rules that are not in the cache are read
+// from the instance store, as they are evaluated by other instances which save their state after every evaluation.
+func (st *Manager) getInhibitionSources(ctx context.Context, alertRule *ngModels.AlertRule, logger log.Logger) inhibitionSources {
+	if len(alertRule.InhibitedBy) == 0 {
+		return nil
+	}
+	sources := make(inhibitionSources, len(alertRule.InhibitedBy))
+	for _, inh := range alertRule.InhibitedBy {
+		if _, ok := sources[inh.SourceRuleUID]; ok {
+			continue
+		}
+		states := st.cache.getStatesForRuleUID(alertRule.OrgID, inh.SourceRuleUID, false)
+		if len(states) == 0 && st.storedInhibitionSources && st.instanceStore != nil {
+			states = st.getStoredStates(ctx, alertRule.OrgID, inh.SourceRuleUID, logger)
+		}
+		sources[inh.SourceRuleUID] = states
+	}
+	return sources
+}
+
+// getStoredStates returns the states of a rule in the instance store with their labels and state only.
+func (st *Manager) getStoredStates(ctx context.Context, orgID int64, ruleUID string, logger log.Logger) []*State {
+	alertInstances, err := st.instanceStore.ListAlertInstances(ctx, &ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgID,
+		RuleUID:   ruleUID,
+	})
+	if err != nil {
+		logger.Error("Unable to fetch the state of the inhibiting rule", "inhibiting_rule_uid", ruleUID, "error", err)
+		return nil
+	}
+	states := make([]*State, 0, len(alertInstances))
+	for _, entry := range alertInstances {
+		states = append(states, &State{
+			AlertRuleUID: entry.RuleUID,
+			OrgID:        entry.RuleOrgID,
+			Labels:       data.Labels(entry.Labels),
+			State:        translateInstanceState(entry.CurrentState),
+		})
+	}
+	return states
+}
+
+// inhibitingRule returns the UID of the first rule that the alert rule is inhibited by that has a firing alert
+// matching the labels of the state.
+func inhibitingRule(alertRule *ngModels.AlertRule, currentState *State, inhibitions inhibitionSources) (string, bool) {
+	for _, inh := range alertRule.InhibitedBy {
+		for _, source := range inhibitions[inh.SourceRuleUID] {
+			if source.State == eval.Alerting && inh.Matches(source.Labels, currentState.Labels) {
+				return inh.SourceRuleUID, true
+			}
+		}
+	}
+	return "", false
+}
+
 func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 	switch state {
 	case ngModels.InstanceStateFiring:
@@ -574,18 +722,44 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 		return eval.NoData
 	case ngModels.InstanceStatePending:
 		return eval.Pending
+	case ngModels.InstanceStateSuppressed:
+		return eval.Suppressed
 	default:
 		return eval.Error
 	}
 }
 
 func (st *Manager) deleteStaleStatesFromCache(ctx context.Context, logger log.Logger, evaluatedAt time.Time, alertRule *ngModels.AlertRule) []StateTransition {
+	// Missing series of firing alerts keep firing until keep_firing_for elapses. They are checked on every evaluation
+	// until then, even though their last evaluation time is updated.
+	keepFiringReason := ngModels.ConcatReasons(ngModels.StateReasonMissingSeries, ngModels.StateReasonKeepFiring)
+	var keptFiring []*State
 	// If we are removing two or more stale series it makes sense to share the resolved image as the alert rule is the same.
 	// TODO: We will need to change this when we support images without screenshots as each series will have a different image
 	staleStates := st.cache.deleteRuleStates(alertRule.GetKey(), func(s *State) bool {
-		return stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds)
+		if !stateIsStale(evaluatedAt, s.LastEvaluationTime, alertRule.IntervalSeconds) && s.StateReason != keepFiringReason {
+			return false
+		}
+		if s.keepFiring(alertRule, evaluatedAt) {
+			keptFiring = append(keptFiring, s)
+			return false
+		}
+		return true
 	})
-	resolvedStates := make([]StateTransition, 0, len(staleStates))
+	resolvedStates := make([]StateTransition, 0, len(staleStates)+len(keptFiring))
+
+	for _, s := range keptFiring {
+		logger.Debug("Keeping stale state firing", "cacheID", s.CacheID, "keepFiringSince", s.KeepFiringSince)
+		oldReason := s.StateReason
+		s.StateReason = keepFiringReason
+		s.LastEvaluationTime = evaluatedAt
+		s.Maintain(alertRule.IntervalSeconds, evaluatedAt)
+		resolvedStates = append(resolvedStates, StateTransition{
+			State:               s,
+			PreviousState:       s.State,
+			PreviousStateReason: oldReason,
+		})
+	}
 
 	for _, s := range staleStates {
 		logger.Info("Detected stale state entry", "cacheID", s.CacheID, "state", s.State, "reason", s.StateReason)
@@ -9,6 +9,7 @@ import (
 	"math/rand"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -1975,6 +1976,238 @@ func TestStaleResults(t *testing.T) {
 	})
 }
 
+func TestInhibitedStates(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+
+	cfg := state.ManagerCfg{
+		Metrics:       metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+		ExternalURL:   nil,
+		InstanceStore: &state.FakeInstanceStore{},
+		Images:        &state.NoopImageService{},
+		Clock:         clk,
+		Historian:     &state.FakeHistorian{},
+		Tracer:        tracing.InitializeTracerForTest(),
+		Log:           log.New("ngalert.state.manager"),
+	}
+	st := state.NewManager(cfg, state.NewNoopPersister())
+
+	gen := models.RuleGen
+	source := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithLabels(data.Labels{})).GenerateRef()
+	rule := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithInterval(10*time.Second), gen.WithLabels(data.Labels{}), gen.WithInhibitedBy(models.InhibitionRule{
+		SourceRuleUID: source.UID,
+		Equal:         []string{"dc"},
+	})).GenerateRef()
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+
+	evaluate := func(r *models.AlertRule, results ...eval.Result) (map[string]state.StateTransition, state.StateTransitions) {
+		t.Helper()
+		for i := range results {
+			results[i].EvaluatedAt = clk.Now()
+		}
+		var sent state.StateTransitions
+		processed := st.ProcessEvalResults(ctx, clk.Now(), r, results, nil, func(_ context.Context, states state.StateTransitions) {
+			sent = states
+		})
+		byDC := make(map[string]state.StateTransition, len(processed))
+		for _, tr := range processed {
+			byDC[tr.Labels["dc"]] = tr
+		}
+		return byDC, sent
+	}
+	alerting := func(dc string) eval.Result {
+		return eval.ResultGen(eval.WithState(eval.Alerting), eval.WithLabels(data.Labels{"dc": dc}))()
+	}
+
+	start := clk.Now()
+	byDC, sent := evaluate(rule, alerting("eu"), alerting("us"))
+	require.Equal(t, eval.Alerting, byDC["eu"].State.State)
+	require.Equal(t, eval.Alerting, byDC["us"].State.State)
+	require.Len(t, sent, 2)
+
+	_, _ = evaluate(source, alerting("eu"))
+
+	t.Run("should suppress alerts with matching labels while the source rule is firing", func(t *testing.T) {
+		clk.Add(interval)
+		byDC, sent := evaluate(rule, alerting("eu"), alerting("us"))
+
+		eu := byDC["eu"]
+		require.Equal(t, eval.Suppressed, eu.State.State)
+		require.Equal(t, eval.Alerting, eu.PreviousState)
+		require.Equal(t, models.StateReasonInhibited, eu.StateReason)
+		require.Equal(t, start, eu.StartsAt)
+		require.Equal(t, clk.Now(), eu.EndsAt)
+		require.Equal(t, clk.Now(), *eu.ResolvedAt)
+		require.Equal(t, eval.Alerting, byDC["us"].State.State)
+
+		// the suppressed alert is resolved in the Alertmanager
+		require.Contains(t, sent, eu)
+	})
+
+	t.Run("should keep the alert suppressed", func(t *testing.T) {
+		clk.Add(interval)
+		byDC, sent := evaluate(rule, alerting("eu"), alerting("us"))
+		require.Equal(t, eval.Suppressed, byDC["eu"].State.State)
+		require.Equal(t, eval.Suppressed, byDC["eu"].PreviousState)
+		require.Equal(t, start, byDC["eu"].StartsAt)
+		require.NotContains(t, sent, byDC["eu"])
+	})
+
+	t.Run("should fire again when the source rule is resolved", func(t *testing.T) {
+		clk.Add(interval)
+		_, _ = evaluate(source, eval.ResultGen(eval.WithState(eval.Normal), eval.WithLabels(data.Labels{"dc": "eu"}))())
+		byDC, sent := evaluate(rule, alerting("eu"), alerting("us"))
+
+		eu := byDC["eu"]
+		require.Equal(t, eval.Alerting, eu.State.State)
+		require.Equal(t, eval.Suppressed, eu.PreviousState)
+		require.Empty(t, eu.StateReason)
+		require.Equal(t, start, eu.StartsAt)
+		require.Nil(t, eu.ResolvedAt)
+		require.Contains(t, sent, eu)
+	})
+}
+
+// sharedInstanceStore is an instance store that is shared by the state managers of several instances.
+type sharedInstanceStore struct {
+	state.FakeInstanceStore
+	mtx       sync.Mutex
+	instances map[models.AlertInstanceKey]models.AlertInstance
+}
+
+func newSharedInstanceStore() *sharedInstanceStore {
+	return &sharedInstanceStore{instances: map[models.AlertInstanceKey]models.AlertInstance{}}
+}
+
+func (s *sharedInstanceStore) SaveAlertInstance(_ context.Context, instance models.AlertInstance) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.instances[instance.AlertInstanceKey] = instance
+	return nil
+}
+
+func (s *sharedInstanceStore) ListAlertInstances(_ context.Context, q *models.ListAlertInstancesQuery) ([]*models.AlertInstance, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	var result []*models.AlertInstance
+	for _, instance := range s.instances {
+		if instance.RuleOrgID == q.RuleOrgID && instance.RuleUID == q.RuleUID {
+			instance := instance
+			result = append(result, &instance)
+		}
+	}
+	return result, nil
+}
+
+func TestInhibitedStates_ShardedEvaluation(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	store := newSharedInstanceStore()
+
+	newManager := func(sharded bool) *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:           metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore:     store,
+			Images:            &state.NoopImageService{},
+			Clock:             clk,
+			Historian:         &state.FakeHistorian{},
+			Tracer:            tracing.InitializeTracerForTest(),
+			Log:               log.New("ngalert.state.manager"),
+			ShardedEvaluation: sharded,
+		}
+		return state.NewManager(cfg, state.NewSyncStatePersisiter(log.New("ngalert.state.manager.persist"), cfg))
+	}
+	// the source rule is evaluated by another instance
+	other := newManager(true)
+
+	gen := models.RuleGen
+	source := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithLabels(data.Labels{})).GenerateRef()
+	rule := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithLabels(data.Labels{}), gen.WithInhibitedBy(models.InhibitionRule{
+		SourceRuleUID: source.UID,
+		Equal:         []string{"dc"},
+	})).GenerateRef()
+
+	evaluate := func(st *state.Manager, r *models.AlertRule, s eval.State) state.StateTransitions {
+		result := eval.ResultGen(eval.WithState(s), eval.WithLabels(data.Labels{"dc": "eu"}))()
+		result.EvaluatedAt = clk.Now()
+		return st.ProcessEvalResults(ctx, clk.Now(), r, eval.Results{result}, nil, nil)
+	}
+
+	_ = evaluate(other, source, eval.Alerting)
+
+	t.Run("should be inhibited by the stored state of a rule evaluated by another instance", func(t *testing.T) {
+		st := newManager(true)
+		transitions := evaluate(st, rule, eval.Alerting)
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Suppressed, transitions[0].State.State)
+	})
+
+	t.Run("should not read the stored state if the evaluation is not sharded", func(t *testing.T) {
+		st := newManager(false)
+		transitions := evaluate(st, rule, eval.Alerting)
+		require.Len(t, transitions, 1)
+		require.Equal(t, eval.Alerting, transitions[0].State.State)
+	})
+}
+
+func TestKeepFiringFor_ShardedHandOff(t *testing.T) {
+	ctx := context.Background()
+	clk := clock.NewMock()
+	store := newSharedInstanceStore()
+
+	newManager := func() *state.Manager {
+		cfg := state.ManagerCfg{
+			Metrics:           metrics.NewNGAlert(prometheus.NewPedanticRegistry()).GetStateMetrics(),
+			InstanceStore:     store,
+			Images:            &state.NoopImageService{},
+			Clock:             clk,
+			Historian:         &state.FakeHistorian{},
+			Tracer:            tracing.InitializeTracerForTest(),
+			Log:               log.New("ngalert.state.manager"),
+			ShardedEvaluation: true,
+		}
+		return state.NewManager(cfg, state.NewSyncStatePersisiter(log.New("ngalert.state.manager.persist"), cfg))
+	}
+
+	gen := models.RuleGen
+	rule := gen.With(gen.WithOrgID(1), gen.WithFor(0), gen.WithLabels(data.Labels{}), gen.WithKeepFiringFor(time.Minute)).GenerateRef()
+
+	evaluate := func(st *state.Manager, s eval.State) *state.State {
+		t.Helper()
+		result := eval.ResultGen(eval.WithState(s), eval.WithLabels(data.Labels{"dc": "eu"}))()
+		result.EvaluatedAt = clk.Now()
+		transitions := st.ProcessEvalResults(ctx, clk.Now(), rule, eval.Results{result}, nil, nil)
+		require.Len(t, transitions, 1)
+		return transitions[0].State
+	}
+
+	previous := newManager()
+	_ = evaluate(previous, eval.Alerting)
+	clk.Add(10 * time.Second)
+	keepFiringSince := clk.Now()
+	s := evaluate(previous, eval.Normal)
+	require.Equal(t, eval.Alerting, s.State)
+	require.Equal(t, keepFiringSince, *s.KeepFiringSince)
+
+	// the rule is handed off to another instance
+	previous.ForgetStateByRuleUID(ctx, rule.GetKey())
+	next := newManager()
+	next.WarmRule(ctx, rule)
+
+	states := next.GetStatesForRuleUID(rule.OrgID, rule.UID)
+	require.Len(t, states, 1)
+	require.NotNil(t, states[0].KeepFiringSince)
+	require.Equal(t, keepFiringSince, *states[0].KeepFiringSince)
+
+	t.Run("should keep firing until keep_firing_for has elapsed since the first instance started it", func(t *testing.T) {
+		clk.Add(30 * time.Second)
+		require.Equal(t, eval.Alerting, evaluate(next, eval.Normal).State)
+
+		clk.Add(30 * time.Second)
+		require.Equal(t, eval.Normal, evaluate(next, eval.Normal).State)
+	})
+}
+
 func TestDeleteStateByRuleUID(t *testing.T) {
 	interval := time.Minute
 	ctx := context.Background()
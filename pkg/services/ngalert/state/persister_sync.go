@@ -105,6 +105,7 @@ func (a *SyncStatePersister) saveAlertStates(ctx context.Context, states ...Stat
 			ResolvedAt:        s.ResolvedAt,
 			LastSentAt:        s.LastSentAt,
 			ResultFingerprint: s.ResultFingerprint.String(),
+			KeepFiringSince:   s.KeepFiringSince,
 		}
 
 		err = a.store.SaveAlertInstance(ctx, instance)
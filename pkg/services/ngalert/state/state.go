@@ -73,6 +73,11 @@ type State struct {
 	LastEvaluationString string
 	LastEvaluationTime   time.Time
 	EvaluationDuration   time.Duration
+
+	// KeepFiringSince is set when an Alerting state would have been resolved but keeps firing because of the
+	// keep_firing_for of the rule. It is persisted with the alert instance, so the period continues when the
+	// state is restored, for example after a restart or when another server takes over the rule.
+	KeepFiringSince *time.Time
 }
 
 func (a *State) GetRuleKey() models.AlertRuleKey {
@@ -98,6 +103,7 @@ func (a *State) SetAlerting(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // SetPending the state to Pending. It changes both the start and end time.
@@ -107,6 +113,7 @@ func (a *State) SetPending(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // SetNoData sets the state to NoData. It changes both the start and end time.
@@ -116,6 +123,7 @@ func (a *State) SetNoData(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
 }
 
 // SetError sets the state to Error. It changes both the start and end time.
@@ -125,6 +133,7 @@ func (a *State) SetError(err error, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = err
+	a.KeepFiringSince = nil
 }
 
 // SetNormal sets the state to Normal. It changes both the start and end time.
@@ -134,6 +143,19 @@ func (a *State) SetNormal(reason string, startsAt, endsAt time.Time) {
 	a.StartsAt = startsAt
 	a.EndsAt = endsAt
 	a.Error = nil
+	a.KeepFiringSince = nil
+}
+
+// keepFiring returns true if the Alerting or Suppressed state must keep firing at evaluatedAt instead of being resolved,
+// because the keep_firing_for duration of the rule has not elapsed. The period starts when it is first called for the state.
+func (a *State) keepFiring(rule *models.AlertRule, evaluatedAt time.Time) bool {
+	if (a.State != eval.Alerting && a.State != eval.Suppressed) || rule.KeepFiringFor <= 0 {
+		return false
+	}
+	if a.KeepFiringSince == nil {
+		a.KeepFiringSince = &evaluatedAt
+	}
+	return evaluatedAt.Sub(*a.KeepFiringSince) < rule.KeepFiringFor
 }
 
 // Maintain updates the end time using the most recent evaluation.
@@ -263,9 +285,21 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]float6
 	return result
 }
 
-func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
+func resultNormal(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	if state.State == eval.Normal {
 		logger.Debug("Keeping state", "state", state.State)
+	} else if state.keepFiring(rule, result.EvaluatedAt) {
+		prevEndsAt := state.EndsAt
+		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		logger.Debug("Keeping state",
+			"state",
+			state.State,
+			"keep_firing_since",
+			state.KeepFiringSince,
+			"previous_ends_at",
+			prevEndsAt,
+			"next_ends_at",
+			state.EndsAt)
 	} else {
 		nextEndsAt := result.EvaluatedAt
 		logger.Debug("Changing state",
@@ -284,9 +318,11 @@ func resultNormal(state *State, _ *models.AlertRule, result eval.Result, logger
 
 func resultAlerting(state *State, rule *models.AlertRule, result eval.Result, logger log.Logger, reason string) {
 	switch state.State {
-	case eval.Alerting:
+	case eval.Alerting, eval.Suppressed:
+		// Suppressed states are firing, they are only not sent to the Alertmanager
 		prevEndsAt := state.EndsAt
 		state.Maintain(rule.IntervalSeconds, result.EvaluatedAt)
+		state.KeepFiringSince = nil
 		logger.Debug("Keeping state",
 			"state",
 			state.State,
@@ -440,7 +476,7 @@ func resultKeepLast(state *State, rule *models.AlertRule, result eval.Result, lo
 	reason := models.ConcatReasons(result.State.String(), models.StateReasonKeepLast)
 
 	switch state.State {
-	case eval.Alerting:
+	case eval.Alerting, eval.Suppressed:
 		logger.Debug("Execution keep last state is Alerting", "handler", "resultAlerting")
 		resultAlerting(state, rule, result, logger, reason)
 	case eval.Pending:
@@ -477,9 +513,9 @@ func (a *State) NeedsSending(resendDelay time.Duration, resolvedRetention time.D
 		return true
 	}
 
-	// For normal states, we should only be sending if this is a resolved notification or a re-send of the resolved
-	// notification within the resolvedRetention period.
-	if a.State == eval.Normal && (a.ResolvedAt == nil || a.LastEvaluationTime.Sub(*a.ResolvedAt) > resolvedRetention) {
+	// For normal and suppressed states, we should only be sending if this is a resolved notification or a re-send of
+	// the resolved notification within the resolvedRetention period.
+	if (a.State == eval.Normal || a.State == eval.Suppressed) && (a.ResolvedAt == nil || a.LastEvaluationTime.Sub(*a.ResolvedAt) > resolvedRetention) {
 		return false
 	}
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 )
@@ -91,6 +92,7 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(ctx context.Context,
 
 		historicConfig := models.HistoricConfigFromAlertConfig(config)
 		historicConfig.LastApplied = cmd.LastApplied
+		historicConfig.Author = configurationAuthor(ctx)
 		if _, err := sess.Table("alert_configuration_history").Insert(historicConfig); err != nil {
 			return err
 		}
@@ -129,6 +131,7 @@ func (st *DBstore) UpdateAlertmanagerConfiguration(ctx context.Context, cmd *mod
 		}
 
 		historicConfig := models.HistoricConfigFromAlertConfig(config)
+		historicConfig.Author = configurationAuthor(ctx)
 		if _, err := sess.Table("alert_configuration_history").Insert(historicConfig); err != nil {
 			return err
 		}
@@ -189,6 +192,26 @@ func (st *DBstore) GetAppliedConfigurations(ctx context.Context, orgID int64, li
 	return configs, nil
 }
 
+// GetConfigurationHistory returns all configuration versions of the organization, applied or not, ordered newest -> oldest by id.
+func (st *DBstore) GetConfigurationHistory(ctx context.Context, orgID int64, limit int) ([]*models.HistoricAlertConfiguration, error) {
+	if limit < 1 || limit > ConfigRecordsLimit {
+		limit = ConfigRecordsLimit
+	}
+
+	configs := []*models.HistoricAlertConfiguration{}
+	if err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_configuration_history").
+			Desc("id").
+			Where("org_id = ?", orgID).
+			Limit(limit).
+			Find(&configs)
+	}); err != nil {
+		return []*models.HistoricAlertConfiguration{}, err
+	}
+
+	return configs, nil
+}
+
 // GetHistoricalConfiguration returns a single historical configuration based on provided org and id.
 func (st *DBstore) GetHistoricalConfiguration(ctx context.Context, orgID int64, id int64) (*models.HistoricAlertConfiguration, error) {
 	var config models.HistoricAlertConfiguration
@@ -209,6 +232,16 @@ func (st *DBstore) GetHistoricalConfiguration(ctx context.Context, orgID int64,
 	return &config, nil
 }
 
+// configurationAuthor returns the login of the user that saves a configuration, or an empty string if the
+// configuration is saved by Grafana itself.
+func configurationAuthor(ctx context.Context) string {
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return ""
+	}
+	return user.GetLogin()
+}
+
 func (st *DBstore) deleteOldConfigurations(ctx context.Context, orgID int64, limit int) (int64, error) {
 	if limit < 1 {
 		return 0, fmt.Errorf("failed to delete old configurations: limit is set to '%d' but needs to be > 0", limit)
@@ -10,9 +10,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
 
@@ -402,6 +404,66 @@ func TestIntegrationGetAppliedConfigurations(t *testing.T) {
 	})
 }
 
+func TestIntegrationGetConfigurationHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlStore := db.InitTestDB(t)
+	store := &DBstore{
+		SQLStore: sqlStore,
+		Logger:   log.NewNopLogger(),
+	}
+
+	t.Run("no configurations = empty slice", func(tt *testing.T) {
+		configs, err := store.GetConfigurationHistory(context.Background(), 10, 10)
+		require.NoError(tt, err)
+		require.NotNil(tt, configs)
+		require.Len(tt, configs, 0)
+	})
+
+	t.Run("all saved configurations should be returned with their author", func(tt *testing.T) {
+		var org int64 = 1
+
+		// A configuration saved by Grafana itself has no author.
+		cmd := buildSaveConfigCmd(tt, "system", org)
+		cmd.LastApplied = time.Now().UTC().Unix()
+		require.NoError(tt, store.SaveAlertmanagerConfiguration(context.Background(), &cmd))
+
+		// A configuration saved on behalf of a user that is not applied yet.
+		ctx := identity.WithRequester(context.Background(), &user.SignedInUser{Login: "editor", OrgID: org})
+		cmd = buildSaveConfigCmd(tt, "unapplied", org)
+		require.NoError(tt, store.SaveAlertmanagerConfiguration(ctx, &cmd))
+
+		// A configuration updated on behalf of a user.
+		ctx = identity.WithRequester(context.Background(), &user.SignedInUser{Login: "admin", OrgID: org})
+		cmd = buildSaveConfigCmd(tt, "updated", org)
+		cmd.FetchedConfigurationHash = fmt.Sprintf("%x", md5.Sum([]byte("unapplied")))
+		require.NoError(tt, store.UpdateAlertmanagerConfiguration(ctx, &cmd))
+
+		// Save a configuration for another org.
+		setupConfigInOrg(tt, "other", org+1, store)
+
+		configs, err := store.GetConfigurationHistory(context.Background(), org, 10)
+		require.NoError(tt, err)
+		require.Len(tt, configs, 3)
+
+		require.Equal(tt, "updated", configs[0].AlertmanagerConfiguration)
+		require.Equal(tt, "admin", configs[0].Author)
+		require.Equal(tt, "unapplied", configs[1].AlertmanagerConfiguration)
+		require.Equal(tt, "editor", configs[1].Author)
+		require.Zero(tt, configs[1].LastApplied)
+		require.Equal(tt, "system", configs[2].AlertmanagerConfiguration)
+		require.Empty(tt, configs[2].Author)
+		require.NotZero(tt, configs[2].LastApplied)
+
+		// The limit should be considered by the store.
+		configs, err = store.GetConfigurationHistory(context.Background(), org, 1)
+		require.NoError(tt, err)
+		require.Len(tt, configs, 1)
+		require.Equal(tt, "updated", configs[0].AlertmanagerConfiguration)
+	})
+}
+
 func TestIntegrationGetHistoricalConfiguration(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping integration test")
@@ -17,22 +17,24 @@ func alertRuleToModelsAlertRule(ar alertRule, l log.Logger) (models.AlertRule, e
 	}
 
 	result := models.AlertRule{
-		ID:              ar.ID,
-		OrgID:           ar.OrgID,
-		Title:           ar.Title,
-		Condition:       ar.Condition,
-		Data:            data,
-		Updated:         ar.Updated,
-		IntervalSeconds: ar.IntervalSeconds,
-		Version:         ar.Version,
-		UID:             ar.UID,
-		NamespaceUID:    ar.NamespaceUID,
-		DashboardUID:    ar.DashboardUID,
-		PanelID:         ar.PanelID,
-		RuleGroup:       ar.RuleGroup,
-		RuleGroupIndex:  ar.RuleGroupIndex,
-		For:             ar.For,
-		IsPaused:        ar.IsPaused,
+		ID:                ar.ID,
+		OrgID:             ar.OrgID,
+		Title:             ar.Title,
+		Condition:         ar.Condition,
+		Data:              data,
+		Updated:           ar.Updated,
+		IntervalSeconds:   ar.IntervalSeconds,
+		Version:           ar.Version,
+		UID:               ar.UID,
+		NamespaceUID:      ar.NamespaceUID,
+		DashboardUID:      ar.DashboardUID,
+		PanelID:           ar.PanelID,
+		RuleGroup:         ar.RuleGroup,
+		RuleGroupIndex:    ar.RuleGroupIndex,
+		For:               ar.For,
+		KeepFiringFor:     ar.KeepFiringFor,
+		IsPaused:          ar.IsPaused,
+		EvaluationTimeout: ar.EvaluationTimeout,
 	}
 
 	if ar.NoDataState != "" {
@@ -49,6 +51,13 @@ func alertRuleToModelsAlertRule(ar alertRule, l log.Logger) (models.AlertRule, e
 			result.ExecErrState = models.ErrorErrState
 		}
 	}
+	if ar.Priority != "" {
+		result.Priority, err = models.RulePriorityFromString(ar.Priority)
+		if err != nil {
+			l.Warn("Unknown Priority value, defaulting to normal", append(result.GetKey().LogContext(), "original", ar.Priority)...)
+			result.Priority = models.RulePriorityNormal
+		}
+	}
 
 	if ar.Record != "" {
 		var record models.Record
@@ -81,6 +90,13 @@ func alertRuleToModelsAlertRule(ar alertRule, l log.Logger) (models.AlertRule, e
 		result.NotificationSettings = ns
 	}
 
+	if ar.InhibitedBy != "" {
+		err = json.Unmarshal([]byte(ar.InhibitedBy), &result.InhibitedBy)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("failed to parse inhibitions: %w", err)
+		}
+	}
+
 	if ar.Metadata != "" {
 		err = json.Unmarshal([]byte(ar.Metadata), &result.Metadata)
 		if err != nil {
@@ -101,23 +117,26 @@ func parseNotificationSettings(s string) ([]models.NotificationSettings, error)
 
 func alertRuleFromModelsAlertRule(ar models.AlertRule) (alertRule, error) {
 	result := alertRule{
-		ID:              ar.ID,
-		OrgID:           ar.OrgID,
-		Title:           ar.Title,
-		Condition:       ar.Condition,
-		Updated:         ar.Updated,
-		IntervalSeconds: ar.IntervalSeconds,
-		Version:         ar.Version,
-		UID:             ar.UID,
-		NamespaceUID:    ar.NamespaceUID,
-		DashboardUID:    ar.DashboardUID,
-		PanelID:         ar.PanelID,
-		RuleGroup:       ar.RuleGroup,
-		RuleGroupIndex:  ar.RuleGroupIndex,
-		NoDataState:     ar.NoDataState.String(),
-		ExecErrState:    ar.ExecErrState.String(),
-		For:             ar.For,
-		IsPaused:        ar.IsPaused,
+		ID:                ar.ID,
+		OrgID:             ar.OrgID,
+		Title:             ar.Title,
+		Condition:         ar.Condition,
+		Updated:           ar.Updated,
+		IntervalSeconds:   ar.IntervalSeconds,
+		Version:           ar.Version,
+		UID:               ar.UID,
+		NamespaceUID:      ar.NamespaceUID,
+		DashboardUID:      ar.DashboardUID,
+		PanelID:           ar.PanelID,
+		RuleGroup:         ar.RuleGroup,
+		RuleGroupIndex:    ar.RuleGroupIndex,
+		NoDataState:       ar.NoDataState.String(),
+		ExecErrState:      ar.ExecErrState.String(),
+		For:               ar.For,
+		KeepFiringFor:     ar.KeepFiringFor,
+		IsPaused:          ar.IsPaused,
+		EvaluationTimeout: ar.EvaluationTimeout,
+		Priority:          ar.Priority.String(),
 	}
 
 	// Serialize complex types to JSON strings
@@ -159,6 +178,14 @@ func alertRuleFromModelsAlertRule(ar models.AlertRule) (alertRule, error) {
 		result.NotificationSettings = string(notificationSettingsData)
 	}
 
+	if len(ar.InhibitedBy) > 0 {
+		inhibitedByData, err := json.Marshal(ar.InhibitedBy)
+		if err != nil {
+			return alertRule{}, fmt.Errorf("failed to marshal inhibitions: %w", err)
+		}
+		result.InhibitedBy = string(inhibitedByData)
+	}
+
 	metadata, err := json.Marshal(ar.Metadata)
 	if err != nil {
 		return alertRule{}, fmt.Errorf("failed to metadata: %w", err)
@@ -187,10 +214,14 @@ func alertRuleToAlertRuleVersion(rule alertRule) alertRuleVersion {
 		NoDataState:          rule.NoDataState,
 		ExecErrState:         rule.ExecErrState,
 		For:                  rule.For,
+		KeepFiringFor:        rule.KeepFiringFor,
 		Annotations:          rule.Annotations,
 		Labels:               rule.Labels,
 		IsPaused:             rule.IsPaused,
 		NotificationSettings: rule.NotificationSettings,
 		Metadata:             rule.Metadata,
+		InhibitedBy:          rule.InhibitedBy,
+		EvaluationTimeout:    rule.EvaluationTimeout,
+		Priority:             rule.Priority,
 	}
 }
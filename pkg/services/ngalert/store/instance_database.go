@@ -69,12 +69,13 @@ func (st DBstore) SaveAlertInstance(ctx context.Context, alertInstance models.Al
 			nullableTimeToUnix(alertInstance.ResolvedAt),
 			nullableTimeToUnix(alertInstance.LastSentAt),
 			alertInstance.ResultFingerprint,
+			nullableTimeToUnix(alertInstance.KeepFiringSince),
 		)
 
 		upsertSQL := st.SQLStore.GetDialect().UpsertSQL(
 			"alert_instance",
 			[]string{"rule_org_id", "rule_uid", "labels_hash"},
-			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "resolved_at", "last_sent_at", "result_fingerprint"})
+			[]string{"rule_org_id", "rule_uid", "labels", "labels_hash", "current_state", "current_reason", "current_state_since", "current_state_end", "last_eval_time", "resolved_at", "last_sent_at", "result_fingerprint", "keep_firing_since"})
 		_, err = sess.SQL(upsertSQL, params...).Query()
 		if err != nil {
 			return err
@@ -234,7 +235,7 @@ func (st DBstore) FullSync(ctx context.Context, instances []models.AlertInstance
 			}
 
 			_, err = sess.Exec(
-				"INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time, resolved_at, last_sent_at) VALUES (?,?,?,?,?,?,?,?,?,?,?)",
+				"INSERT INTO alert_instance (rule_org_id, rule_uid, labels, labels_hash, current_state, current_reason, current_state_since, current_state_end, last_eval_time, resolved_at, last_sent_at, keep_firing_since) VALUES (?,?,?,?,?,?,?,?,?,?,?,?)",
 				alertInstance.RuleOrgID,
 				alertInstance.RuleUID,
 				labelTupleJSON,
@@ -246,6 +247,7 @@ func (st DBstore) FullSync(ctx context.Context, instances []models.AlertInstance
 				alertInstance.LastEvalTime.Unix(),
 				nullableTimeToUnix(alertInstance.ResolvedAt),
 				nullableTimeToUnix(alertInstance.LastSentAt),
+				nullableTimeToUnix(alertInstance.KeepFiringSince),
 			)
 			if err != nil {
 				return fmt.Errorf("failed to insert into alert_instance table: %w", err)
@@ -388,6 +388,7 @@ func generateTestAlertInstance(orgID int64, ruleID string) models.AlertInstance
 		LastEvalTime:      time.Now(),
 		LastSentAt:        util.Pointer(time.Now()),
 		ResolvedAt:        util.Pointer(time.Now()),
+		KeepFiringSince:   util.Pointer(time.Now()),
 		CurrentReason:     "abc",
 	}
 }
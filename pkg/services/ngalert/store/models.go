@@ -22,11 +22,15 @@ type alertRule struct {
 	NoDataState          string
 	ExecErrState         string
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          string
 	Labels               string
 	IsPaused             bool
 	NotificationSettings string `xorm:"notification_settings"`
 	Metadata             string `xorm:"metadata"`
+	InhibitedBy          string `xorm:"inhibited_by"`
+	EvaluationTimeout    time.Duration
+	Priority             string
 }
 
 func (a alertRule) TableName() string {
@@ -56,11 +60,15 @@ type alertRuleVersion struct {
 	// ideally this field should have been apimodels.ApiDuration
 	// but this is currently not possible because of circular dependencies
 	For                  time.Duration
+	KeepFiringFor        time.Duration
 	Annotations          string
 	Labels               string
 	IsPaused             bool
 	NotificationSettings string `xorm:"notification_settings"`
 	Metadata             string `xorm:"metadata"`
+	InhibitedBy          string `xorm:"inhibited_by"`
+	EvaluationTimeout    time.Duration
+	Priority             string
 }
 
 func (a alertRuleVersion) TableName() string {
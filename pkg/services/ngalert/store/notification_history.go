@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// notificationHistoryDeleteBatchSize is the maximum number of notification history entries that are deleted in one statement.
+const notificationHistoryDeleteBatchSize = 1000
+
+// SaveNotificationHistory inserts the delivery attempt of a notification.
+func (st DBstore) SaveNotificationHistory(ctx context.Context, entry models.NotificationHistoryEntry) error {
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Insert(&entry); err != nil {
+			return fmt.Errorf("failed to save notification history: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindNotificationHistory returns the delivery attempts of notifications that match the query, newest first.
+func (st DBstore) FindNotificationHistory(ctx context.Context, query models.NotificationHistoryQuery) ([]models.NotificationHistoryEntry, error) {
+	var entries []models.NotificationHistoryEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(&models.NotificationHistoryEntry{}).Where("org_id = ?", query.OrgID)
+		if query.Receiver != "" {
+			q = q.And("receiver = ?", query.Receiver)
+		}
+		if query.Integration != "" {
+			q = q.And("integration = ?", query.Integration)
+		}
+		if query.Status != "" {
+			q = q.And("status = ?", query.Status)
+		}
+		if query.AlertFingerprint != "" {
+			// the fingerprints are stored as a JSON array of strings
+			q = q.And("alert_fingerprints LIKE ?", fmt.Sprintf("%%%q%%", query.AlertFingerprint))
+		}
+		if !query.From.IsZero() {
+			q = q.And("sent_at >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("sent_at <= ?", query.To.UnixMilli())
+		}
+		q = q.Desc("sent_at", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit, query.Offset)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notification history: %w", err)
+	}
+	return entries, nil
+}
+
+// DeleteNotificationHistoryBefore deletes the delivery attempts of notifications before the time, in batches until
+// there is nothing left to delete. It returns the number of deleted entries.
+func (st DBstore) DeleteNotificationHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var ids []int64
+		var affected int64
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			err := sess.Table(&models.NotificationHistoryEntry{}).Cols("id").Where("sent_at < ?", before.UnixMilli()).
+				Asc("id").Limit(notificationHistoryDeleteBatchSize).Find(&ids)
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+			affected, err = sess.In("id", ids).Delete(&models.NotificationHistoryEntry{})
+			return err
+		})
+		total += affected
+		if err != nil {
+			return total, fmt.Errorf("failed to delete notification history: %w", err)
+		}
+		if len(ids) < notificationHistoryDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationNotificationHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Millisecond)
+	entry := func(orgID int64, receiver, integration string, status models.NotificationHistoryStatus, fingerprints string, sentAt time.Time) models.NotificationHistoryEntry {
+		return models.NotificationHistoryEntry{
+			OrgID:             orgID,
+			Receiver:          receiver,
+			Integration:       integration,
+			GroupKey:          `{}:{alertname="test"}`,
+			AlertFingerprints: fingerprints,
+			AlertCount:        1,
+			Status:            status,
+			StatusCode:        200,
+			Duration:          15,
+			SentAt:            sentAt.UnixMilli(),
+		}
+	}
+	for _, e := range []models.NotificationHistoryEntry{
+		entry(1, "team-a", "slack", models.NotificationHistoryStatusSuccess, `["0123456789abcdef"]`, now.Add(-3*time.Hour)),
+		entry(1, "team-a", "email", models.NotificationHistoryStatusFailure, `["0123456789abcdef"]`, now.Add(-2*time.Hour)),
+		entry(1, "team-b", "webhook", models.NotificationHistoryStatusSuccess, `["fedcba9876543210"]`, now.Add(-time.Hour)),
+		entry(2, "team-a", "slack", models.NotificationHistoryStatusSuccess, `["0123456789abcdef"]`, now),
+	} {
+		require.NoError(t, dbstore.SaveNotificationHistory(ctx, e))
+	}
+
+	t.Run("finds the entries of the org, newest first", func(t *testing.T) {
+		result, err := dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, "team-b", result[0].Receiver)
+		require.Equal(t, now.Add(-time.Hour).UnixMilli(), result[0].SentAt)
+		require.Equal(t, now.Add(-3*time.Hour).UnixMilli(), result[2].SentAt)
+		require.Equal(t, `["fedcba9876543210"]`, result[0].AlertFingerprints)
+		require.Equal(t, 200, result[0].StatusCode)
+	})
+
+	t.Run("filters by receiver, integration, status, alert and time range", func(t *testing.T) {
+		result, err := dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1, Receiver: "team-a"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		result, err = dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1, Receiver: "team-a", Integration: "slack"})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+
+		result, err = dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1, Status: models.NotificationHistoryStatusFailure})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "email", result[0].Integration)
+
+		result, err = dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1, AlertFingerprint: "fedcba9876543210"})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "team-b", result[0].Receiver)
+
+		result, err = dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+	})
+
+	t.Run("applies limit and offset", func(t *testing.T) {
+		result, err := dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1, Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, now.Add(-2*time.Hour).UnixMilli(), result[0].SentAt)
+	})
+
+	t.Run("deletes the entries before the time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteNotificationHistoryBefore(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		result, err := dbstore.FindNotificationHistory(ctx, models.NotificationHistoryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "team-b", result[0].Receiver)
+	})
+}
@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// recordedSamplesDeleteBatchSize is the maximum number of recorded samples that are deleted in one statement.
+const recordedSamplesDeleteBatchSize = 1000
+
+// SaveRecordedSamples inserts the samples of recording rules.
+func (st DBstore) SaveRecordedSamples(ctx context.Context, samples []models.RecordedSample) error {
+	if len(samples) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		opts := sqlstore.NativeSettingsForDialect(st.SQLStore.GetDialect())
+		if _, err := sess.BulkInsert(&models.RecordedSample{}, samples, opts); err != nil {
+			return fmt.Errorf("failed to save recorded samples: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindRecordedSamples returns the samples of the metric that match the query, ordered by series and time.
+func (st DBstore) FindRecordedSamples(ctx context.Context, query models.RecordedSampleQuery) ([]models.RecordedSample, error) {
+	var samples []models.RecordedSample
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(&models.RecordedSample{}).Where("org_id = ?", query.OrgID).And("metric = ?", query.Metric)
+		if !query.From.IsZero() {
+			q = q.And("ts >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("ts <= ?", query.To.UnixMilli())
+		}
+		keys := make([]string, 0, len(query.Labels))
+		for k := range query.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			pattern, err := labelPattern(k, query.Labels[k])
+			if err != nil {
+				return err
+			}
+			q = q.And("labels LIKE ? ESCAPE '"+likeEscapeChar+"'", pattern)
+		}
+		q = q.Asc("labels_hash", "ts", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit)
+		}
+		return q.Find(&samples)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recorded samples: %w", err)
+	}
+	return samples, nil
+}
+
+// likeEscapeChar escapes the wildcards in LIKE patterns. It is not the backslash, which is the default escape
+// character of MySQL and PostgreSQL, because JSON encoded labels contain backslashes, for example in \u003c.
+const likeEscapeChar = "!"
+
+var likeEscaper = strings.NewReplacer(likeEscapeChar, likeEscapeChar+likeEscapeChar, "%", likeEscapeChar+"%", "_", likeEscapeChar+"_")
+
+// labelPattern returns a LIKE pattern, escaped with likeEscapeChar, that matches the JSON encoded labels that have the
+// label. The key and the value are JSON strings, so the pattern cannot match a part of another label. Case insensitive
+// collations only make the pattern match more series than needed.
+func labelPattern(key, value string) (string, error) {
+	b, err := json.Marshal(map[string]string{key: value})
+	if err != nil {
+		return "", err
+	}
+	return "%" + likeEscaper.Replace(strings.TrimSuffix(strings.TrimPrefix(string(b), "{"), "}")) + "%", nil
+}
+
+// DeleteRecordedSamplesBefore deletes the recorded samples before the time, in batches until there is nothing left
+// to delete. It returns the number of deleted samples.
+func (st DBstore) DeleteRecordedSamplesBefore(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var ids []int64
+		var affected int64
+		// load the IDs first and delete them in a separate statement, for the same reason as for the state history
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			err := sess.Table(&models.RecordedSample{}).Cols("id").Where("ts < ?", before.UnixMilli()).
+				Asc("id").Limit(recordedSamplesDeleteBatchSize).Find(&ids)
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+			affected, err = sess.In("id", ids).Delete(&models.RecordedSample{})
+			return err
+		})
+		total += affected
+		if err != nil {
+			return total, fmt.Errorf("failed to delete recorded samples: %w", err)
+		}
+		if len(ids) < recordedSamplesDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationRecordedSamples(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Millisecond)
+	sample := func(orgID int64, metric, hash string, value float64, ts time.Time) models.RecordedSample {
+		return models.RecordedSample{
+			OrgID:      orgID,
+			Metric:     metric,
+			Labels:     `{"env":"prod","team":"a"}`,
+			LabelsHash: hash,
+			Value:      value,
+			Timestamp:  ts.UnixMilli(),
+		}
+	}
+	require.NoError(t, dbstore.SaveRecordedSamples(ctx, []models.RecordedSample{
+		sample(1, "metric_a", "0000000000000002", 1, now.Add(-2*time.Hour)),
+		sample(1, "metric_a", "0000000000000001", 2, now.Add(-time.Hour)),
+		sample(1, "metric_a", "0000000000000001", 3, now.Add(-2*time.Hour)),
+		sample(1, "metric_b", "0000000000000001", 4, now),
+		sample(2, "metric_a", "0000000000000001", 5, now),
+		{OrgID: 1, Metric: "metric_b", Labels: `{"team":"b"}`, LabelsHash: "0000000000000002", Value: 6, Timestamp: now.UnixMilli()},
+		{OrgID: 1, Metric: "metric_c", Labels: `{"team":"a\u003cb"}`, LabelsHash: "0000000000000001", Value: 7, Timestamp: now.UnixMilli()},
+		{OrgID: 1, Metric: "metric_c", Labels: `{"team":"a%b!"}`, LabelsHash: "0000000000000002", Value: 8, Timestamp: now.UnixMilli()},
+		{OrgID: 1, Metric: "metric_c", Labels: `{"team":"axb!"}`, LabelsHash: "0000000000000003", Value: 9, Timestamp: now.UnixMilli()},
+	}))
+
+	t.Run("finds the samples of the metric, ordered by series and time", func(t *testing.T) {
+		result, err := dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_a"})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, []float64{3, 2, 1}, []float64{result[0].Value, result[1].Value, result[2].Value})
+		require.Equal(t, `{"env":"prod","team":"a"}`, result[0].Labels)
+	})
+
+	t.Run("filters by labels", func(t *testing.T) {
+		result, err := dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_b", Labels: map[string]string{"team": "b"}})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, float64(6), result[0].Value)
+
+		result, err = dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_b", Labels: map[string]string{"env": "prod", "team": "a"}})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, float64(4), result[0].Value)
+
+		result, err = dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_b", Labels: map[string]string{"team": "prod"}})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("filters by labels with escaped characters and wildcards", func(t *testing.T) {
+		result, err := dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_c", Labels: map[string]string{"team": "a<b"}})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, float64(7), result[0].Value)
+
+		result, err = dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_c", Labels: map[string]string{"team": "a%b!"}})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, float64(8), result[0].Value)
+
+		result, err = dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_c", Labels: map[string]string{"team": "a_b!"}})
+		require.NoError(t, err)
+		require.Empty(t, result)
+	})
+
+	t.Run("filters by time range", func(t *testing.T) {
+		result, err := dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_a", From: now.Add(-90 * time.Minute), To: now})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, float64(2), result[0].Value)
+	})
+
+	t.Run("deletes the samples before the time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteRecordedSamplesBefore(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		result, err := dbstore.FindRecordedSamples(ctx, models.RecordedSampleQuery{OrgID: 1, Metric: "metric_a"})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+	})
+}
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// recurringSilenceColumns are the columns of the definition of a recurring silence. The columns of the
+// materialization are only written by UpdateRecurringSilenceMaterialization.
+var recurringSilenceColumns = []string{"matchers", "schedule", "duration", "timezone", "comment", "created_by", "provenance", "deleted", "version", "updated"}
+
+// GetRecurringSilence returns the recurring silence of the organization by its UID.
+func (st DBstore) GetRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error) {
+	var result *models.RecurringSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		rs := models.RecurringSilence{}
+		has, err := sess.Where("org_id = ? AND uid = ? AND deleted = ?", orgID, uid, false).Get(&rs)
+		if err != nil {
+			return fmt.Errorf("failed to get recurring silence: %w", err)
+		}
+		if !has {
+			return models.ErrRecurringSilenceNotFound
+		}
+		result = &rs
+		return nil
+	})
+	return result, err
+}
+
+// ListRecurringSilences returns the recurring silences of the organization, ordered by UID.
+func (st DBstore) ListRecurringSilences(ctx context.Context, orgID int64) ([]*models.RecurringSilence, error) {
+	var result []*models.RecurringSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND deleted = ?", orgID, false).Asc("uid").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring silences: %w", err)
+	}
+	return result, nil
+}
+
+// GetAllRecurringSilences returns the recurring silences of all organizations, including the deleted ones whose
+// materialized silences are not expired yet.
+func (st DBstore) GetAllRecurringSilences(ctx context.Context) ([]*models.RecurringSilence, error) {
+	var result []*models.RecurringSilence
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Asc("org_id", "uid").Find(&result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recurring silences: %w", err)
+	}
+	return result, nil
+}
+
+// SaveRecurringSilence inserts the recurring silence, or replaces the definition of the recurring silence with the
+// same organization and UID and increments its version. A deleted recurring silence is restored. The ID, version and
+// materialization of rs are set to the stored ones.
+func (st DBstore) SaveRecurringSilence(ctx context.Context, rs *models.RecurringSilence) error {
+	return st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := models.RecurringSilence{}
+		has, err := sess.Where("org_id = ? AND uid = ?", rs.OrgID, rs.UID).Get(&existing)
+		if err != nil {
+			return fmt.Errorf("failed to get recurring silence: %w", err)
+		}
+		rs.Deleted = false
+		if !has {
+			rs.Version = 1
+			rs.Materialization = models.RecurringSilenceMaterialization{}
+			if _, err := sess.Insert(rs); err != nil {
+				return fmt.Errorf("failed to insert recurring silence: %w", err)
+			}
+			return nil
+		}
+		rs.ID = existing.ID
+		rs.Version = existing.Version + 1
+		rs.Materialization = existing.Materialization
+		if _, err := sess.ID(rs.ID).Cols(recurringSilenceColumns...).Update(rs); err != nil {
+			return fmt.Errorf("failed to update recurring silence: %w", err)
+		}
+		return nil
+	})
+}
+
+// DeleteRecurringSilence marks the recurring silence of the organization as deleted, increments its version and
+// returns it. The row is kept until its materialized silences are expired, see PurgeRecurringSilence.
+func (st DBstore) DeleteRecurringSilence(ctx context.Context, orgID int64, uid string) (*models.RecurringSilence, error) {
+	var deleted *models.RecurringSilence
+	err := st.SQLStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := models.RecurringSilence{}
+		has, err := sess.Where("org_id = ? AND uid = ? AND deleted = ?", orgID, uid, false).Get(&existing)
+		if err != nil {
+			return fmt.Errorf("failed to get recurring silence: %w", err)
+		}
+		if !has {
+			return models.ErrRecurringSilenceNotFound
+		}
+		existing.Deleted = true
+		existing.Version++
+		if _, err := sess.ID(existing.ID).Cols("deleted", "version").NoAutoTime().Update(&existing); err != nil {
+			return fmt.Errorf("failed to delete recurring silence: %w", err)
+		}
+		deleted = &existing
+		return nil
+	})
+	return deleted, err
+}
+
+// PurgeRecurringSilence removes the deleted recurring silence if its version did not change since it was read. It
+// returns false if the recurring silence was changed, for example restored, in the meantime.
+func (st DBstore) PurgeRecurringSilence(ctx context.Context, rs *models.RecurringSilence) (bool, error) {
+	var purged bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("id = ? AND version = ? AND deleted = ?", rs.ID, rs.Version, true).Delete(&models.RecurringSilence{})
+		if err != nil {
+			return fmt.Errorf("failed to purge recurring silence: %w", err)
+		}
+		purged = affected > 0
+		return nil
+	})
+	return purged, err
+}
+
+// UpdateRecurringSilenceMaterialization saves the materialization of the recurring silence if neither the version of
+// the recurring silence nor its materialization changed since it was read, that is if the stored materialization
+// still equals previous. It returns false if the recurring silence was changed, for example by another instance.
+func (st DBstore) UpdateRecurringSilenceMaterialization(ctx context.Context, rs *models.RecurringSilence, previous models.RecurringSilenceMaterialization) (bool, error) {
+	var updated bool
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Table(&models.RecurringSilence{}).
+			Where("id = ? AND version = ? AND materialized_version = ? AND materialized_until = ?", rs.ID, rs.Version, previous.Version, previous.Until).
+			Cols("materialized_version", "materialized_until", "materialized_silences").
+			NoAutoTime().
+			Update(rs)
+		if err != nil {
+			return fmt.Errorf("failed to update materialization of recurring silence: %w", err)
+		}
+		updated = affected > 0
+		return nil
+	})
+	return updated, err
+}
@@ -0,0 +1,152 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func TestIntegrationRecurringSilences(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	recurringSilence := func(orgID int64, uid string) *models.RecurringSilence {
+		return &models.RecurringSilence{
+			OrgID: orgID,
+			UID:   uid,
+			Matchers: amv2.Matchers{
+				{Name: util.Pointer("env"), Value: util.Pointer("prod"), IsEqual: util.Pointer(true), IsRegex: util.Pointer(false)},
+			},
+			Schedule:  "0 2 * * SUN",
+			Duration:  2 * time.Hour,
+			Timezone:  "Europe/Berlin",
+			Comment:   "weekly maintenance",
+			CreatedBy: "admin",
+			Updated:   time.Now(),
+		}
+	}
+	for _, rs := range []*models.RecurringSilence{
+		recurringSilence(1, "b"),
+		recurringSilence(1, "a"),
+		recurringSilence(2, "a"),
+	} {
+		require.NoError(t, dbstore.SaveRecurringSilence(ctx, rs))
+		require.NotZero(t, rs.ID)
+		require.Equal(t, int64(1), rs.Version)
+	}
+
+	t.Run("gets and lists the recurring silences of the org", func(t *testing.T) {
+		rs, err := dbstore.GetRecurringSilence(ctx, 1, "a")
+		require.NoError(t, err)
+		require.Equal(t, "0 2 * * SUN", rs.Schedule)
+		require.Equal(t, 2*time.Hour, rs.Duration)
+		require.Equal(t, "env", *rs.Matchers[0].Name)
+
+		_, err = dbstore.GetRecurringSilence(ctx, 1, "c")
+		require.ErrorIs(t, err, models.ErrRecurringSilenceNotFound)
+
+		result, err := dbstore.ListRecurringSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		require.Equal(t, "a", result[0].UID)
+		require.Equal(t, "b", result[1].UID)
+
+		all, err := dbstore.GetAllRecurringSilences(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+	})
+
+	t.Run("saves the materialization only if the recurring silence did not change", func(t *testing.T) {
+		rs, err := dbstore.GetRecurringSilence(ctx, 1, "a")
+		require.NoError(t, err)
+
+		previous := rs.Materialization
+		rs.Materialization = models.RecurringSilenceMaterialization{
+			Version:  rs.Version,
+			Until:    1000,
+			Silences: []models.MaterializedSilence{{ID: "silence-1", EndsAt: time.Unix(2000, 0).UTC()}},
+		}
+		ok, err := dbstore.UpdateRecurringSilenceMaterialization(ctx, rs, previous)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, err = dbstore.UpdateRecurringSilenceMaterialization(ctx, rs, previous)
+		require.NoError(t, err)
+		require.False(t, ok, "the materialization was already changed")
+
+		stored, err := dbstore.GetRecurringSilence(ctx, 1, "a")
+		require.NoError(t, err)
+		require.Equal(t, rs.Materialization, stored.Materialization)
+
+		update := recurringSilence(1, "a")
+		update.Schedule = "0 3 * * SAT"
+		require.NoError(t, dbstore.SaveRecurringSilence(ctx, update))
+		require.Equal(t, int64(2), update.Version)
+		require.Equal(t, rs.Materialization, update.Materialization, "the materialization is kept until it is reconciled")
+
+		ok, err = dbstore.UpdateRecurringSilenceMaterialization(ctx, rs, rs.Materialization)
+		require.NoError(t, err)
+		require.False(t, ok, "the version was changed")
+
+		stored, err = dbstore.GetRecurringSilence(ctx, 1, "a")
+		require.NoError(t, err)
+		require.Equal(t, "0 3 * * SAT", stored.Schedule)
+		require.Equal(t, int64(1), stored.Materialization.Version)
+		require.Equal(t, "silence-1", stored.Materialization.Silences[0].ID)
+	})
+
+	t.Run("deletes the recurring silence", func(t *testing.T) {
+		deleted, err := dbstore.DeleteRecurringSilence(ctx, 1, "b")
+		require.NoError(t, err)
+		require.True(t, deleted.Deleted)
+		require.Equal(t, int64(2), deleted.Version)
+		_, err = dbstore.DeleteRecurringSilence(ctx, 1, "b")
+		require.ErrorIs(t, err, models.ErrRecurringSilenceNotFound)
+
+		_, err = dbstore.GetRecurringSilence(ctx, 1, "b")
+		require.ErrorIs(t, err, models.ErrRecurringSilenceNotFound)
+		result, err := dbstore.ListRecurringSilences(ctx, 1)
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		all, err := dbstore.GetAllRecurringSilences(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 3, "the deleted recurring silence is kept until it is purged")
+
+		stale := *deleted
+		stale.Version = 1
+		ok, err := dbstore.PurgeRecurringSilence(ctx, &stale)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		ok, err = dbstore.PurgeRecurringSilence(ctx, deleted)
+		require.NoError(t, err)
+		require.True(t, ok)
+		all, err = dbstore.GetAllRecurringSilences(ctx)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+	})
+
+	t.Run("restores a deleted recurring silence", func(t *testing.T) {
+		_, err := dbstore.DeleteRecurringSilence(ctx, 2, "a")
+		require.NoError(t, err)
+
+		restored := recurringSilence(2, "a")
+		require.NoError(t, dbstore.SaveRecurringSilence(ctx, restored))
+		require.Equal(t, int64(3), restored.Version)
+
+		stored, err := dbstore.GetRecurringSilence(ctx, 2, "a")
+		require.NoError(t, err)
+		require.False(t, stored.Deleted)
+	})
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// stateHistoryDeleteBatchSize is the maximum number of state history entries that are deleted in one statement.
+const stateHistoryDeleteBatchSize = 1000
+
+// SaveStateHistory inserts the entries of the alert state history.
+func (st DBstore) SaveStateHistory(ctx context.Context, entries []models.StateHistoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		opts := sqlstore.NativeSettingsForDialect(st.SQLStore.GetDialect())
+		if _, err := sess.BulkInsert(&models.StateHistoryEntry{}, entries, opts); err != nil {
+			return fmt.Errorf("failed to save state history: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindStateHistory returns the entries of the alert state history that match the query, newest first.
+func (st DBstore) FindStateHistory(ctx context.Context, query models.StateHistoryEntryQuery) ([]models.StateHistoryEntry, error) {
+	var entries []models.StateHistoryEntry
+	err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Table(&models.StateHistoryEntry{}).Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.And("rule_uid = ?", query.RuleUID)
+		}
+		if query.DashboardUID != "" {
+			q = q.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		if query.PanelID != 0 {
+			q = q.And("panel_id = ?", query.PanelID)
+		}
+		if len(query.FolderUIDs) > 0 {
+			q = q.In("folder_uid", query.FolderUIDs)
+		}
+		if !query.From.IsZero() {
+			q = q.And("evaluated_at >= ?", query.From.UnixMilli())
+		}
+		if !query.To.IsZero() {
+			q = q.And("evaluated_at <= ?", query.To.UnixMilli())
+		}
+		q = q.Desc("evaluated_at", "id")
+		if query.Limit > 0 {
+			q = q.Limit(query.Limit, query.Offset)
+		}
+		return q.Find(&entries)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find state history: %w", err)
+	}
+	return entries, nil
+}
+
+// DeleteStateHistoryBefore deletes the entries of the alert state history of evaluations before the time,
+// in batches until there is nothing left to delete. It returns the number of deleted entries.
+func (st DBstore) DeleteStateHistoryBefore(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+		var ids []int64
+		var affected int64
+		// load the IDs first and delete them in a separate statement, because deletes with sub-queries can deadlock
+		// with the concurrent inserts on MySQL
+		err := st.SQLStore.WithDbSession(ctx, func(sess *db.Session) error {
+			err := sess.Table(&models.StateHistoryEntry{}).Cols("id").Where("evaluated_at < ?", before.UnixMilli()).
+				Asc("id").Limit(stateHistoryDeleteBatchSize).Find(&ids)
+			if err != nil || len(ids) == 0 {
+				return err
+			}
+			affected, err = sess.In("id", ids).Delete(&models.StateHistoryEntry{})
+			return err
+		})
+		total += affected
+		if err != nil {
+			return total, fmt.Errorf("failed to delete state history: %w", err)
+		}
+		if len(ids) < stateHistoryDeleteBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestIntegrationStateHistory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	now := time.Now().Truncate(time.Millisecond)
+	entry := func(orgID int64, ruleUID, folderUID string, evaluatedAt time.Time) models.StateHistoryEntry {
+		return models.StateHistoryEntry{
+			OrgID:         orgID,
+			RuleUID:       ruleUID,
+			RuleTitle:     "rule " + ruleUID,
+			FolderUID:     folderUID,
+			Fingerprint:   "0123456789abcdef",
+			Labels:        `{"team":"a"}`,
+			PreviousState: "Normal",
+			CurrentState:  "Alerting",
+			Values:        `{"A":1}`,
+			EvaluatedAt:   evaluatedAt.UnixMilli(),
+		}
+	}
+	require.NoError(t, dbstore.SaveStateHistory(ctx, []models.StateHistoryEntry{
+		entry(1, "rule-1", "folder-1", now.Add(-3*time.Hour)),
+		entry(1, "rule-1", "folder-1", now.Add(-2*time.Hour)),
+		entry(1, "rule-2", "folder-2", now.Add(-time.Hour)),
+		entry(2, "rule-3", "folder-1", now),
+	}))
+
+	t.Run("finds the entries of the org, newest first", func(t *testing.T) {
+		result, err := dbstore.FindStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 3)
+		require.Equal(t, "rule-2", result[0].RuleUID)
+		require.Equal(t, now.Add(-time.Hour).UnixMilli(), result[0].EvaluatedAt)
+		require.Equal(t, now.Add(-3*time.Hour).UnixMilli(), result[2].EvaluatedAt)
+		require.Equal(t, `{"team":"a"}`, result[0].Labels)
+		require.Equal(t, `{"A":1}`, result[0].Values)
+	})
+
+	t.Run("filters by rule, folders and time range", func(t *testing.T) {
+		result, err := dbstore.FindStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, RuleUID: "rule-1"})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+
+		result, err = dbstore.FindStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, FolderUIDs: []string{"folder-2"}})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "rule-2", result[0].RuleUID)
+
+		result, err = dbstore.FindStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now})
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+	})
+
+	t.Run("applies limit and offset", func(t *testing.T) {
+		result, err := dbstore.FindStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1, Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, now.Add(-2*time.Hour).UnixMilli(), result[0].EvaluatedAt)
+	})
+
+	t.Run("deletes the entries before the time", func(t *testing.T) {
+		deleted, err := dbstore.DeleteStateHistoryBefore(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		require.Equal(t, int64(2), deleted)
+
+		result, err := dbstore.FindStateHistory(ctx, models.StateHistoryEntryQuery{OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "rule-2", result[0].RuleUID)
+	})
+}
@@ -0,0 +1,113 @@
+package writer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// maxErrorBodySize is the maximum number of bytes of the response body that is included in a write error.
+const maxErrorBodySize = 1024
+
+// httpTarget sends the samples of recording rules to an HTTP endpoint.
+type httpTarget struct {
+	client  *http.Client
+	url     string
+	token   string
+	backend string
+	clock   clock.Clock
+	logger  log.Logger
+	metrics *metrics.RemoteWriter
+}
+
+func newHTTPTarget(
+	backend string,
+	settings setting.RecordingRuleTargetSettings,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (httpTarget, error) {
+	if err := validateTargetSettings(settings); err != nil {
+		return httpTarget{}, err
+	}
+
+	cl, err := httpClientProvider.New(httpclient.Options{
+		BasicAuth: createAuthOpts(settings.BasicAuthUsername, settings.BasicAuthPassword),
+	})
+	if err != nil {
+		return httpTarget{}, err
+	}
+	cl.Timeout = settings.Timeout
+
+	return httpTarget{
+		client:  cl,
+		url:     settings.URL,
+		token:   settings.Token,
+		backend: backend,
+		clock:   clock,
+		logger:  l,
+		metrics: metrics,
+	}, nil
+}
+
+func validateTargetSettings(settings setting.RecordingRuleTargetSettings) error {
+	if settings.BasicAuthUsername != "" && settings.BasicAuthPassword == "" {
+		return fmt.Errorf("basic auth password is required if username is set")
+	}
+
+	if _, err := url.ParseRequestURI(settings.URL); err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if settings.Timeout <= 0 {
+		return fmt.Errorf("timeout must be greater than 0")
+	}
+
+	return nil
+}
+
+// post sends the body to the endpoint and returns an error if the endpoint does not accept it.
+func (t httpTarget) post(ctx context.Context, orgID int64, contentType string, body []byte) error {
+	lvs := []string{fmt.Sprint(orgID), t.backend}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Join(ErrWriteFailure, err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "grafana-recording-rule")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Token "+t.token)
+	}
+
+	writeStart := t.clock.Now()
+	res, err := t.client.Do(req)
+	t.metrics.WriteDuration.WithLabelValues(lvs...).Observe(t.clock.Now().Sub(writeStart).Seconds())
+	if err != nil {
+		t.metrics.WritesTotal.WithLabelValues(append(lvs, "0")...).Inc()
+		return errors.Join(ErrWriteFailure, err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	t.metrics.WritesTotal.WithLabelValues(append(lvs, fmt.Sprint(res.StatusCode))...).Inc()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBodySize))
+		return errors.Join(ErrWriteFailure, fmt.Errorf("unexpected status code %d: %s", res.StatusCode, strings.TrimSpace(string(msg))))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	measurementEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `)
+	tagEscaper         = strings.NewReplacer(`,`, `\,`, `=`, `\=`, ` `, `\ `)
+)
+
+// InfluxDBWriter writes the result of recording rules to an InfluxDB line protocol endpoint, for example the
+// /api/v2/write endpoint of InfluxDB 2 or the /write endpoint of InfluxDB 1. The name of the metric is the
+// measurement, the labels are the tags and the result is the "value" field.
+type InfluxDBWriter struct {
+	target httpTarget
+	logger log.Logger
+}
+
+func NewInfluxDBWriter(
+	settings setting.RecordingRuleTargetSettings,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*InfluxDBWriter, error) {
+	target, err := newHTTPTarget(TargetInfluxDB, settings, httpClientProvider, clock, l, metrics)
+	if err != nil {
+		return nil, err
+	}
+	return &InfluxDBWriter{
+		target: target,
+		logger: l,
+	}, nil
+}
+
+// Write writes the given frames to the InfluxDB endpoint.
+func (w InfluxDBWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+
+	l := w.logger.FromContext(ctx)
+	l.Debug("Writing metric", "name", name)
+	var sb strings.Builder
+	for _, p := range points {
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			// InfluxDB does not support NaN and infinite values.
+			l.Debug("Skipping sample that InfluxDB cannot store", "value", p.Metric.V)
+			continue
+		}
+		sb.WriteString(LineProtocolFromPoint(p))
+		sb.WriteByte('\n')
+	}
+	if sb.Len() == 0 {
+		return nil
+	}
+
+	return w.target.post(ctx, orgID, "text/plain; charset=utf-8", []byte(sb.String()))
+}
+
+// LineProtocolFromPoint returns the line of the point in the InfluxDB line protocol, with a timestamp in nanoseconds.
+// Labels with empty values are left out, as InfluxDB does not support empty tag values.
+func LineProtocolFromPoint(p Point) string {
+	keys := make([]string, 0, len(p.Labels))
+	for k, v := range p.Labels {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString(measurementEscaper.Replace(p.Name))
+	for _, k := range keys {
+		sb.WriteByte(',')
+		sb.WriteString(tagEscaper.Replace(k))
+		sb.WriteByte('=')
+		sb.WriteString(tagEscaper.Replace(p.Labels[k]))
+	}
+	sb.WriteString(" value=")
+	sb.WriteString(strconv.FormatFloat(p.Metric.V, 'g', -1, 64))
+	sb.WriteByte(' ')
+	sb.WriteString(strconv.FormatInt(p.Metric.T.UnixNano(), 10))
+	return sb.String()
+}
@@ -0,0 +1,96 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLineProtocolFromPoint(t *testing.T) {
+	ts := time.Unix(1700000000, 5)
+	testCases := []struct {
+		name     string
+		point    Point
+		expected string
+	}{
+		{
+			name:     "sorts the tags",
+			point:    Point{Name: "test", Labels: map[string]string{"b": "2", "a": "1"}, Metric: Metric{T: ts, V: 1.5}},
+			expected: "test,a=1,b=2 value=1.5 1700000000000000005",
+		},
+		{
+			name:     "escapes the measurement and the tags",
+			point:    Point{Name: "my metric,x", Labels: map[string]string{"a b": "c=d,e"}, Metric: Metric{T: ts, V: 2}},
+			expected: `my\ metric\,x,a\ b=c\=d\,e value=2 1700000000000000005`,
+		},
+		{
+			name:     "leaves out empty tag values",
+			point:    Point{Name: "test", Labels: map[string]string{"a": ""}, Metric: Metric{T: ts, V: -3}},
+			expected: "test value=-3 1700000000000000005",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, LineProtocolFromPoint(tc.point))
+		})
+	}
+}
+
+func TestInfluxDBWriter_Write(t *testing.T) {
+	var (
+		body   string
+		header http.Header
+		status = http.StatusNoContent
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body, header = string(b), r.Header
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("partial write: field type conflict"))
+	}))
+	t.Cleanup(server.Close)
+
+	writer, err := NewInfluxDBWriter(setting.RecordingRuleTargetSettings{
+		URL:     server.URL,
+		Token:   "secret",
+		Timeout: 10 * time.Second,
+	}, httpclient.NewProvider(), clock.New(), log.NewNopLogger(), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	series := []map[string]string{{"foo": "1"}, {"foo": "2"}}
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, series)
+	now := time.Now()
+
+	t.Run("writes a line for every series", func(t *testing.T) {
+		err := writer.Write(context.Background(), "test", now, frames, 1, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(body), "\n")
+		require.Len(t, lines, len(series))
+		for _, line := range lines {
+			require.True(t, strings.HasPrefix(line, "test,extra=label,foo="), line)
+		}
+		require.Equal(t, "Token secret", header.Get("Authorization"))
+	})
+
+	t.Run("returns the response of a failed write", func(t *testing.T) {
+		status = http.StatusBadRequest
+		err := writer.Write(context.Background(), "test", now, frames, 1, nil)
+		require.ErrorIs(t, err, ErrWriteFailure)
+		require.ErrorContains(t, err, "field type conflict")
+	})
+}
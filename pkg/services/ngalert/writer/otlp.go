@@ -0,0 +1,125 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const otlpScopeName = "grafana-recording-rule"
+
+// OTLPWriter writes the result of recording rules as gauges to an OTLP/HTTP metrics endpoint, for example the
+// /v1/metrics endpoint of an OpenTelemetry Collector. The labels of the series are the attributes of the data points.
+type OTLPWriter struct {
+	target httpTarget
+	logger log.Logger
+}
+
+func NewOTLPWriter(
+	settings setting.RecordingRuleTargetSettings,
+	httpClientProvider HttpClientProvider,
+	clock clock.Clock,
+	l log.Logger,
+	metrics *metrics.RemoteWriter,
+) (*OTLPWriter, error) {
+	target, err := newHTTPTarget(TargetOTLP, settings, httpClientProvider, clock, l, metrics)
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPWriter{
+		target: target,
+		logger: l,
+	}, nil
+}
+
+// Write writes the given frames to the OTLP endpoint.
+func (w OTLPWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	body, err := proto.Marshal(OTLPRequestFromPoints(points))
+	if err != nil {
+		return errors.Join(ErrWriteFailure, err)
+	}
+
+	w.logger.FromContext(ctx).Debug("Writing metric", "name", name)
+	return w.target.post(ctx, orgID, "application/x-protobuf", body)
+}
+
+// OTLPRequestFromPoints returns an OTLP export request with a gauge for every metric name of the points.
+func OTLPRequestFromPoints(points []Point) *colmetricspb.ExportMetricsServiceRequest {
+	gauges := make(map[string]*metricspb.Gauge)
+	names := make([]string, 0)
+	for _, p := range points {
+		gauge, ok := gauges[p.Name]
+		if !ok {
+			gauge = &metricspb.Gauge{}
+			gauges[p.Name] = gauge
+			names = append(names, p.Name)
+		}
+		gauge.DataPoints = append(gauge.DataPoints, &metricspb.NumberDataPoint{
+			Attributes:   otlpAttributes(p.Labels),
+			TimeUnixNano: uint64(p.Metric.T.UnixNano()),
+			Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: p.Metric.V},
+		})
+	}
+
+	result := make([]*metricspb.Metric, 0, len(names))
+	for _, name := range names {
+		result = append(result, &metricspb.Metric{
+			Name: name,
+			Data: &metricspb.Metric_Gauge{Gauge: gauges[name]},
+		})
+	}
+
+	return &colmetricspb.ExportMetricsServiceRequest{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: &resourcepb.Resource{
+					Attributes: otlpAttributes(map[string]string{"service.name": "grafana"}),
+				},
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope:   &commonpb.InstrumentationScope{Name: otlpScopeName},
+						Metrics: result,
+					},
+				},
+			},
+		},
+	}
+}
+
+func otlpAttributes(labels map[string]string) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]*commonpb.KeyValue, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, &commonpb.KeyValue{
+			Key:   k,
+			Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: labels[k]}},
+		})
+	}
+	return attrs
+}
@@ -0,0 +1,81 @@
+package writer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/grafana/grafana/pkg/infra/httpclient"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestOTLPRequestFromPoints(t *testing.T) {
+	now := time.Now()
+	points := []Point{
+		{Name: "a", Labels: map[string]string{"foo": "1"}, Metric: Metric{T: now, V: 1}},
+		{Name: "b", Labels: map[string]string{"foo": "1"}, Metric: Metric{T: now, V: 2}},
+		{Name: "a", Labels: map[string]string{"foo": "2"}, Metric: Metric{T: now, V: 3}},
+	}
+
+	req := OTLPRequestFromPoints(points)
+
+	require.Len(t, req.ResourceMetrics, 1)
+	require.Len(t, req.ResourceMetrics[0].ScopeMetrics, 1)
+	result := req.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, result, 2)
+	require.Equal(t, "a", result[0].Name)
+	require.Len(t, result[0].GetGauge().DataPoints, 2)
+	require.Equal(t, "b", result[1].Name)
+
+	dp := result[0].GetGauge().DataPoints[1]
+	require.Equal(t, 3.0, dp.GetAsDouble())
+	require.Equal(t, uint64(now.UnixNano()), dp.TimeUnixNano)
+	require.Len(t, dp.Attributes, 1)
+	require.Equal(t, "foo", dp.Attributes[0].Key)
+	require.Equal(t, "2", dp.Attributes[0].Value.GetStringValue())
+}
+
+func TestOTLPWriter_Write(t *testing.T) {
+	var (
+		body        []byte
+		contentType string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	writer, err := NewOTLPWriter(setting.RecordingRuleTargetSettings{
+		URL:     server.URL,
+		Timeout: 10 * time.Second,
+	}, httpclient.NewProvider(), clock.New(), log.NewNopLogger(), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	series := []map[string]string{{"foo": "1"}, {"foo": "2"}}
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, series)
+
+	err = writer.Write(context.Background(), "test", time.Now(), frames, 1, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, "application/x-protobuf", contentType)
+	req := &colmetricspb.ExportMetricsServiceRequest{}
+	require.NoError(t, proto.Unmarshal(body, req))
+	result := req.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, result, 1)
+	require.Equal(t, "test", result[0].Name)
+	require.Len(t, result[0].GetGauge().DataPoints, len(series))
+}
@@ -0,0 +1,198 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// sqlQueryLimit is the maximum number of samples that are read from the database for one query.
+const sqlQueryLimit = 100000
+
+// ErrTooManySamples is returned when a query matches more samples than can be read from the database at once.
+var ErrTooManySamples = fmt.Errorf("the query matches more than %d samples, reduce the time range or add labels to match", sqlQueryLimit)
+
+type RecordedSampleStore interface {
+	SaveRecordedSamples(ctx context.Context, samples []models.RecordedSample) error
+	FindRecordedSamples(ctx context.Context, query models.RecordedSampleQuery) ([]models.RecordedSample, error)
+}
+
+type RecordedSampleAdminStore interface {
+	// DeleteRecordedSamplesBefore deletes the recorded samples before the time. It returns the number of deleted
+	// samples or an error.
+	DeleteRecordedSamplesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// SQLWriter writes the result of recording rules to the Grafana database.
+type SQLWriter struct {
+	store   RecordedSampleStore
+	clock   clock.Clock
+	logger  log.Logger
+	metrics *metrics.RemoteWriter
+}
+
+func NewSQLWriter(store RecordedSampleStore, clock clock.Clock, l log.Logger, metrics *metrics.RemoteWriter) *SQLWriter {
+	return &SQLWriter{
+		store:   store,
+		clock:   clock,
+		logger:  l,
+		metrics: metrics,
+	}
+}
+
+// Write writes the given frames to the database.
+func (w SQLWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	points, err := PointsFromFrames(name, t, frames, extraLabels)
+	if err != nil {
+		return errors.Join(ErrBadFrame, err)
+	}
+
+	l := w.logger.FromContext(ctx)
+	samples := make([]models.RecordedSample, 0, len(points))
+	for _, p := range points {
+		if math.IsNaN(p.Metric.V) || math.IsInf(p.Metric.V, 0) {
+			// Not all the databases that Grafana supports can store NaN and infinite values.
+			l.Debug("Skipping sample that the database cannot store", "value", p.Metric.V)
+			continue
+		}
+		lbls, err := json.Marshal(p.Labels)
+		if err != nil {
+			return errors.Join(ErrBadFrame, err)
+		}
+		samples = append(samples, models.RecordedSample{
+			OrgID:      orgID,
+			Metric:     p.Name,
+			Labels:     string(lbls),
+			LabelsHash: data.Labels(p.Labels).Fingerprint().String(),
+			Value:      p.Metric.V,
+			Timestamp:  p.Metric.T.UnixMilli(),
+		})
+	}
+
+	if len(samples) == 0 {
+		return nil
+	}
+
+	l.Debug("Writing metric", "name", name)
+	lvs := []string{fmt.Sprint(orgID), TargetSQL}
+	writeStart := w.clock.Now()
+	err = w.store.SaveRecordedSamples(ctx, samples)
+	w.metrics.WriteDuration.WithLabelValues(lvs...).Observe(w.clock.Now().Sub(writeStart).Seconds())
+	// The status code of the metric is the one of a successful or failed HTTP request, to match the other targets.
+	if err != nil {
+		w.metrics.WritesTotal.WithLabelValues(append(lvs, "500")...).Inc()
+		return errors.Join(ErrWriteFailure, err)
+	}
+	w.metrics.WritesTotal.WithLabelValues(append(lvs, "200")...).Inc()
+	return nil
+}
+
+// SQLReader reads the metrics that recording rules wrote to the Grafana database.
+type SQLReader struct {
+	store RecordedSampleStore
+}
+
+func ProvideSQLReader(store *store.DBstore) *SQLReader {
+	return NewSQLReader(store)
+}
+
+func NewSQLReader(store RecordedSampleStore) *SQLReader {
+	return &SQLReader{store: store}
+}
+
+// QueryRecordedMetric returns a frame for every series of the metric in the time range, whose labels include the
+// given labels. It returns ErrTooManySamples if the query matches more than sqlQueryLimit samples.
+func (r *SQLReader) QueryRecordedMetric(ctx context.Context, orgID int64, metric string, labels map[string]string, from, to time.Time) (data.Frames, error) {
+	samples, err := r.store.FindRecordedSamples(ctx, models.RecordedSampleQuery{
+		OrgID:  orgID,
+		Metric: metric,
+		From:   from,
+		To:     to,
+		Labels: labels,
+		// read one more sample to know if the result would be truncated
+		Limit: sqlQueryLimit + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(samples) > sqlQueryLimit {
+		return nil, ErrTooManySamples
+	}
+
+	frames := data.Frames{}
+	var (
+		hash   string
+		times  []time.Time
+		values []float64
+		lbls   data.Labels
+	)
+	flush := func() {
+		if len(times) == 0 {
+			return
+		}
+		frame := data.NewFrame(metric,
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, lbls, values),
+		)
+		frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTimeSeriesMulti})
+		frames = append(frames, frame)
+		times, values = nil, nil
+	}
+	for _, s := range samples {
+		if s.LabelsHash != hash {
+			flush()
+			hash = s.LabelsHash
+			lbls = data.Labels{}
+			if err := json.Unmarshal([]byte(s.Labels), &lbls); err != nil {
+				return nil, fmt.Errorf("failed to decode labels of recorded sample %d: %w", s.ID, err)
+			}
+		}
+		if !hasLabels(lbls, labels) {
+			continue
+		}
+		times = append(times, time.UnixMilli(s.Timestamp).UTC())
+		values = append(values, s.Value)
+	}
+	flush()
+	return frames, nil
+}
+
+func hasLabels(lbls data.Labels, match map[string]string) bool {
+	for k, v := range match {
+		if lbls[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteExpiredService is a service to delete the samples that are stored by the "sql" target for longer than the
+// configured retention.
+type DeleteExpiredService struct {
+	cfg   setting.RecordingRuleSettings
+	store RecordedSampleAdminStore
+}
+
+func ProvideDeleteExpiredService(cfg *setting.Cfg, store *store.DBstore) *DeleteExpiredService {
+	return &DeleteExpiredService{cfg: cfg.UnifiedAlerting.RecordingRules, store: store}
+}
+
+// DeleteExpired deletes the expired samples. It does nothing if the samples are kept forever.
+func (s *DeleteExpiredService) DeleteExpired(ctx context.Context) (int64, error) {
+	if s.cfg.SQLRetention <= 0 {
+		return 0, nil
+	}
+	return s.store.DeleteRecordedSamplesBefore(ctx, time.Now().Add(-s.cfg.SQLRetention))
+}
@@ -0,0 +1,148 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSQLWriterAndReader(t *testing.T) {
+	store := &fakeRecordedSampleStore{}
+	writer := NewSQLWriter(store, clock.New(), log.NewNopLogger(), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+	reader := NewSQLReader(store)
+
+	series := []map[string]string{{"foo": "1"}, {"foo": "2"}}
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, series)
+	now := time.UnixMilli(time.Now().UnixMilli()).UTC()
+
+	t.Run("writes a sample for every series", func(t *testing.T) {
+		err := writer.Write(context.Background(), "test", now, frames, 1, map[string]string{"extra": "label"})
+		require.NoError(t, err)
+		require.Len(t, store.samples, len(series))
+		for _, s := range store.samples {
+			require.Equal(t, int64(1), s.OrgID)
+			require.Equal(t, "test", s.Metric)
+			require.Equal(t, now.UnixMilli(), s.Timestamp)
+		}
+	})
+
+	t.Run("returns a frame for every series", func(t *testing.T) {
+		result, err := reader.QueryRecordedMetric(context.Background(), 1, "test", nil, now.Add(-time.Minute), now.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, result, len(series))
+		for _, frame := range result {
+			require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+			require.Equal(t, "label", frame.Fields[1].Labels["extra"])
+			require.Equal(t, now, frame.Fields[0].At(0))
+			require.Equal(t, extractValue(t, frames, map[string]string{"foo": frame.Fields[1].Labels["foo"]}, data.FrameTypeNumericWide), frame.Fields[1].At(0))
+		}
+	})
+
+	t.Run("filters the series by labels", func(t *testing.T) {
+		result, err := reader.QueryRecordedMetric(context.Background(), 1, "test", map[string]string{"foo": "2"}, now.Add(-time.Minute), now.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "2", result[0].Fields[1].Labels["foo"])
+	})
+
+	t.Run("returns the error of the store", func(t *testing.T) {
+		store.err = errors.New("database is locked")
+		err := writer.Write(context.Background(), "test", now, frames, 1, nil)
+		require.ErrorIs(t, err, ErrWriteFailure)
+	})
+}
+
+func TestSQLWriter_NonFiniteValues(t *testing.T) {
+	store := &fakeRecordedSampleStore{}
+	writer := NewSQLWriter(store, clock.New(), log.NewNopLogger(), metrics.NewRemoteWriterMetrics(prometheus.NewRegistry()))
+
+	frames := frameGenFromLabels(t, data.FrameTypeNumericWide, []map[string]string{{"foo": "1"}, {"foo": "2"}, {"foo": "3"}})
+	frames[0].Fields[1].Set(0, math.NaN())
+	frames[0].Fields[2].Set(0, math.Inf(1))
+
+	t.Run("skips NaN and infinite values", func(t *testing.T) {
+		err := writer.Write(context.Background(), "test", time.Now(), frames, 1, nil)
+		require.NoError(t, err)
+		require.Len(t, store.samples, 1)
+		require.Contains(t, store.samples[0].Labels, `"foo":"3"`)
+	})
+
+	t.Run("does not write if all values are skipped", func(t *testing.T) {
+		store.err = errors.New("database is locked")
+		err := writer.Write(context.Background(), "test", time.Now(), data.Frames{data.NewFrame("test", frames[0].Fields[:2]...).SetMeta(frames[0].Meta)}, 1, nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestSQLReader_Limit(t *testing.T) {
+	now := time.Now()
+	store := &fakeRecordedSampleStore{}
+	for i := 0; i < sqlQueryLimit; i++ {
+		store.samples = append(store.samples, models.RecordedSample{OrgID: 1, Metric: "test", Labels: `{"foo":"1"}`, LabelsHash: "1", Timestamp: now.UnixMilli()})
+	}
+	store.samples = append(store.samples, models.RecordedSample{OrgID: 1, Metric: "test", Labels: `{"foo":"2"}`, LabelsHash: "2", Timestamp: now.UnixMilli()})
+	reader := NewSQLReader(store)
+
+	t.Run("fails if the query matches more samples than the limit", func(t *testing.T) {
+		_, err := reader.QueryRecordedMetric(context.Background(), 1, "test", nil, now.Add(-time.Minute), now.Add(time.Minute))
+		require.ErrorIs(t, err, ErrTooManySamples)
+	})
+
+	t.Run("matches the labels before the limit is applied", func(t *testing.T) {
+		result, err := reader.QueryRecordedMetric(context.Background(), 1, "test", map[string]string{"foo": "2"}, now.Add(-time.Minute), now.Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		require.Equal(t, "2", result[0].Fields[1].Labels["foo"])
+	})
+}
+
+type fakeRecordedSampleStore struct {
+	samples []models.RecordedSample
+	err     error
+}
+
+func (f *fakeRecordedSampleStore) SaveRecordedSamples(_ context.Context, samples []models.RecordedSample) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.samples = append(f.samples, samples...)
+	return nil
+}
+
+// FindRecordedSamples returns the samples of the metric in the order and up to the limit of the database store.
+func (f *fakeRecordedSampleStore) FindRecordedSamples(_ context.Context, query models.RecordedSampleQuery) ([]models.RecordedSample, error) {
+	var result []models.RecordedSample
+	for _, s := range f.samples {
+		if s.OrgID != query.OrgID || s.Metric != query.Metric || s.Timestamp < query.From.UnixMilli() || s.Timestamp > query.To.UnixMilli() {
+			continue
+		}
+		lbls := data.Labels{}
+		if err := json.Unmarshal([]byte(s.Labels), &lbls); err != nil {
+			return nil, err
+		}
+		if hasLabels(lbls, query.Labels) {
+			result = append(result, s)
+		}
+	}
+	slices.SortStableFunc(result, func(a, b models.RecordedSample) int {
+		return strings.Compare(a.LabelsHash, b.LabelsHash)
+	})
+	if query.Limit > 0 && len(result) > query.Limit {
+		result = result[:query.Limit]
+	}
+	return result, f.err
+}
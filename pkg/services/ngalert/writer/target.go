@@ -0,0 +1,97 @@
+package writer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// TargetPrometheus writes to a Prometheus remote write endpoint.
+	TargetPrometheus = "prometheus"
+	// TargetSQL writes to the Grafana database, where the metrics can be queried with the Grafana data source.
+	TargetSQL = "sql"
+	// TargetInfluxDB writes to an InfluxDB line protocol endpoint.
+	TargetInfluxDB = "influxdb"
+	// TargetOTLP writes to an OTLP/HTTP metrics endpoint.
+	TargetOTLP = "otlp"
+)
+
+// Targets are the names of the writer targets that recording rules can select.
+var Targets = []string{TargetPrometheus, TargetSQL, TargetInfluxDB, TargetOTLP}
+
+var ErrTargetNotConfigured = errors.New("recording rule writer target is not configured")
+
+// IsValidTarget returns true if the target is empty, which selects the configured target, or a known target.
+func IsValidTarget(target string) bool {
+	return target == "" || slices.Contains(Targets, target)
+}
+
+type targetContextKey struct{}
+
+// WithTarget returns a context that selects the writer target of a recording rule. An empty target selects the target
+// configured for the organization of the rule.
+func WithTarget(ctx context.Context, target string) context.Context {
+	return context.WithValue(ctx, targetContextKey{}, target)
+}
+
+// TargetFromContext returns the writer target that is selected in the context.
+func TargetFromContext(ctx context.Context) string {
+	if target, ok := ctx.Value(targetContextKey{}).(string); ok {
+		return target
+	}
+	return ""
+}
+
+// Writer writes the result of a recording rule.
+type Writer interface {
+	Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error
+}
+
+// TargetWriter writes the result of every recording rule to its target: the target that is selected in the context,
+// otherwise the target configured for the organization of the rule, otherwise the default target.
+type TargetWriter struct {
+	targets       map[string]Writer
+	defaultTarget string
+	orgTargets    map[int64]string
+}
+
+func NewTargetWriter(targets map[string]Writer, defaultTarget string, orgTargets map[int64]string) (*TargetWriter, error) {
+	if _, ok := targets[defaultTarget]; !ok {
+		return nil, fmt.Errorf("%w: default target %q", ErrTargetNotConfigured, defaultTarget)
+	}
+	for orgID, target := range orgTargets {
+		if _, ok := targets[target]; !ok {
+			return nil, fmt.Errorf("%w: target %q of organization %d", ErrTargetNotConfigured, target, orgID)
+		}
+	}
+	return &TargetWriter{
+		targets:       targets,
+		defaultTarget: defaultTarget,
+		orgTargets:    orgTargets,
+	}, nil
+}
+
+// Write writes the given frames to the target of the recording rule.
+func (w *TargetWriter) Write(ctx context.Context, name string, t time.Time, frames data.Frames, orgID int64, extraLabels map[string]string) error {
+	target := w.targetFor(ctx, orgID)
+	tw, ok := w.targets[target]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrTargetNotConfigured, target)
+	}
+	return tw.Write(ctx, name, t, frames, orgID, extraLabels)
+}
+
+func (w *TargetWriter) targetFor(ctx context.Context, orgID int64) string {
+	if target := TargetFromContext(ctx); target != "" {
+		return target
+	}
+	if target, ok := w.orgTargets[orgID]; ok {
+		return target
+	}
+	return w.defaultTarget
+}
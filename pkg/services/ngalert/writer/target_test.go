@@ -0,0 +1,82 @@
+package writer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetWriter(t *testing.T) {
+	var written []string
+	targetFor := func(name string) Writer {
+		return FakeWriter{WriteFunc: func(ctx context.Context, _ string, _ time.Time, _ data.Frames, _ int64, _ map[string]string) error {
+			written = append(written, name)
+			return nil
+		}}
+	}
+	targets := map[string]Writer{
+		TargetPrometheus: targetFor(TargetPrometheus),
+		TargetSQL:        targetFor(TargetSQL),
+	}
+
+	t.Run("fails if the default target is not configured", func(t *testing.T) {
+		_, err := NewTargetWriter(targets, TargetInfluxDB, nil)
+		require.ErrorIs(t, err, ErrTargetNotConfigured)
+	})
+
+	t.Run("fails if the target of an organization is not configured", func(t *testing.T) {
+		_, err := NewTargetWriter(targets, TargetPrometheus, map[int64]string{2: TargetOTLP})
+		require.ErrorIs(t, err, ErrTargetNotConfigured)
+	})
+
+	w, err := NewTargetWriter(targets, TargetPrometheus, map[int64]string{2: TargetSQL})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		orgID    int64
+		expected string
+	}{
+		{
+			name:     "writes to the default target",
+			ctx:      context.Background(),
+			orgID:    1,
+			expected: TargetPrometheus,
+		},
+		{
+			name:     "writes to the target of the organization",
+			ctx:      context.Background(),
+			orgID:    2,
+			expected: TargetSQL,
+		},
+		{
+			name:     "writes to the target of the rule",
+			ctx:      WithTarget(context.Background(), TargetSQL),
+			orgID:    1,
+			expected: TargetSQL,
+		},
+		{
+			name:     "empty target of the rule selects the target of the organization",
+			ctx:      WithTarget(context.Background(), ""),
+			orgID:    2,
+			expected: TargetSQL,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			written = nil
+			err := w.Write(tc.ctx, "test", time.Now(), nil, tc.orgID, nil)
+			require.NoError(t, err)
+			require.Equal(t, []string{tc.expected}, written)
+		})
+	}
+
+	t.Run("fails if the target of the rule is not configured", func(t *testing.T) {
+		err := w.Write(WithTarget(context.Background(), TargetOTLP), "test", time.Now(), nil, 1, nil)
+		require.ErrorIs(t, err, ErrTargetNotConfigured)
+	})
+}
@@ -58,7 +58,12 @@ func (ns *NotificationService) buildEmailMessage(cmd *SendEmailCommand) (*Messag
 	if !ns.Cfg.Smtp.Enabled {
 		return nil, ErrSmtpNotEnabled
 	}
+	return ns.RenderEmailCommand(cmd)
+}
 
+// RenderEmailCommand renders the message of the command without sending it. Unlike sending, rendering does not
+// require SMTP to be configured.
+func (ns *NotificationService) RenderEmailCommand(cmd *SendEmailCommand) (*Message, error) {
 	data := cmd.Data
 	if data == nil {
 		data = make(map[string]any, 10)
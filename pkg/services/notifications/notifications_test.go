@@ -189,6 +189,32 @@ func TestSendEmailSync(t *testing.T) {
 	})
 }
 
+func TestRenderEmailCommand(t *testing.T) {
+	bus := newBus(t)
+
+	t.Run("When SMTP disabled in configuration", func(t *testing.T) {
+		cfg := createSmtpConfig()
+		cfg.Smtp.Enabled = false
+		ns, mailer, err := createSutWithConfig(t, bus, cfg)
+		require.NoError(t, err)
+		cmd := &SendEmailCommand{
+			Subject:     "subject",
+			To:          []string{"1@grafana.com"},
+			SingleEmail: true,
+			Template:    "welcome_on_signup",
+		}
+
+		msg, err := ns.RenderEmailCommand(cmd)
+
+		require.NoError(t, err)
+		require.Equal(t, "subject", msg.Subject)
+		require.Equal(t, []string{"1@grafana.com"}, msg.To)
+		require.NotEmpty(t, msg.Body["text/html"])
+		require.NotEmpty(t, msg.Body["text/plain"])
+		require.Empty(t, mailer.Sent)
+	})
+}
+
 func TestSendEmailAsync(t *testing.T) {
 	bus := newBus(t)
 
@@ -3,6 +3,7 @@ package alerting
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -34,6 +35,8 @@ const (
 	testFileCorrectProperties_t         = "./testdata/templates/correct-properties"
 	testFileCorrectPropertiesWithOrg_t  = "./testdata/templates/correct-properties-with-org"
 	testFileMultipleTs                  = "./testdata/templates/multiple-templates"
+	testFileCorrectProperties_rs        = "./testdata/recurring_silences/correct-properties"
+	testFileInvalidMatcher_rs           = "./testdata/recurring_silences/invalid-matcher"
 )
 
 func TestConfigReader(t *testing.T) {
@@ -160,6 +163,27 @@ func TestConfigReader(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, file[0].Templates, 2)
 	})
+	t.Run("a recurring silences file with correct properties should not error", func(t *testing.T) {
+		file, err := configReader.readConfig(ctx, testFileCorrectProperties_rs)
+		require.NoError(t, err)
+		require.Len(t, file[0].RecurringSilences, 1)
+		rs := file[0].RecurringSilences[0]
+		require.Equal(t, int64(1337), rs.OrgID)
+		require.Equal(t, "weekly-maintenance", rs.RecurringSilence.UID)
+		require.Equal(t, "0 2 * * SUN", rs.RecurringSilence.Schedule)
+		require.Equal(t, 2*time.Hour, rs.RecurringSilence.Duration)
+		require.Equal(t, "Europe/Berlin", rs.RecurringSilence.Timezone)
+		require.Len(t, rs.RecurringSilence.Matchers, 2)
+		require.Equal(t, "team", *rs.RecurringSilence.Matchers[1].Name)
+		require.Equal(t, "ops|sre", *rs.RecurringSilence.Matchers[1].Value)
+		require.True(t, *rs.RecurringSilence.Matchers[1].IsRegex)
+		require.True(t, *rs.RecurringSilence.Matchers[1].IsEqual)
+		require.Equal(t, []DeleteRecurringSilence{{OrgID: 1, UID: "nightly-backup"}}, file[0].DeleteRecurringSilences)
+	})
+	t.Run("a recurring silences file with an invalid matcher should fail", func(t *testing.T) {
+		_, err := configReader.readConfig(ctx, testFileInvalidMatcher_rs)
+		require.Error(t, err)
+	})
 	t.Run("a rule file with dasboard typo", func(t *testing.T) {
 		ruleFiles, err := configReader.readConfig(ctx, testFileDasboardTypoSupport)
 		require.NoError(t, err)
@@ -19,6 +19,7 @@ type ProvisionerConfig struct {
 	NotificiationPolicyService provisioning.NotificationPolicyService
 	MuteTimingService          provisioning.MuteTimingService
 	TemplateService            provisioning.TemplateService
+	RecurringSilenceService    RecurringSilenceService
 }
 
 func Provision(ctx context.Context, cfg ProvisionerConfig) error {
@@ -62,6 +63,15 @@ func Provision(ctx context.Context, cfg ProvisionerConfig) error {
 	if err != nil {
 		return fmt.Errorf("text templates: %w", err)
 	}
+	rsProvisioner := NewRecurringSilencesProvisioner(logger, cfg.RecurringSilenceService)
+	err = rsProvisioner.Provision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("recurring silences: %w", err)
+	}
+	err = rsProvisioner.Unprovision(ctx, files)
+	if err != nil {
+		return fmt.Errorf("recurring silences: %w", err)
+	}
 	ruleProvisioner := NewAlertRuleProvisioner(
 		logger,
 		cfg.FolderService,
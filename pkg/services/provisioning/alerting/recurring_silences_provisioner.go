@@ -0,0 +1,57 @@
+package alerting
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type RecurringSilenceService interface {
+	ProvisionRecurringSilence(ctx context.Context, orgID int64, rs models.RecurringSilence) error
+	UnprovisionRecurringSilence(ctx context.Context, orgID int64, uid string) error
+}
+
+type RecurringSilencesProvisioner interface {
+	Provision(ctx context.Context, files []*AlertingFile) error
+	Unprovision(ctx context.Context, files []*AlertingFile) error
+}
+
+type defaultRecurringSilencesProvisioner struct {
+	logger                  log.Logger
+	recurringSilenceService RecurringSilenceService
+}
+
+func NewRecurringSilencesProvisioner(logger log.Logger,
+	recurringSilenceService RecurringSilenceService) RecurringSilencesProvisioner {
+	return &defaultRecurringSilencesProvisioner{
+		logger:                  logger,
+		recurringSilenceService: recurringSilenceService,
+	}
+}
+
+func (c *defaultRecurringSilencesProvisioner) Provision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, rs := range file.RecurringSilences {
+			err := c.recurringSilenceService.ProvisionRecurringSilence(ctx, rs.OrgID, rs.RecurringSilence)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *defaultRecurringSilencesProvisioner) Unprovision(ctx context.Context,
+	files []*AlertingFile) error {
+	for _, file := range files {
+		for _, deleteRecurringSilence := range file.DeleteRecurringSilences {
+			err := c.recurringSilenceService.UnprovisionRecurringSilence(ctx, deleteRecurringSilence.OrgID, deleteRecurringSilence.UID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type RecurringSilenceV1 struct {
+	OrgID    values.Int64Value    `json:"orgId" yaml:"orgId"`
+	UID      values.StringValue   `json:"uid" yaml:"uid"`
+	Matchers []values.StringValue `json:"matchers" yaml:"matchers"`
+	Schedule values.StringValue   `json:"schedule" yaml:"schedule"`
+	Duration values.StringValue   `json:"duration" yaml:"duration"`
+	Timezone values.StringValue   `json:"timezone" yaml:"timezone"`
+	Comment  values.StringValue   `json:"comment" yaml:"comment"`
+}
+
+func (v1 *RecurringSilenceV1) mapToModel() (RecurringSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return RecurringSilence{}, errors.New("recurring silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	matchers := make(amv2.Matchers, 0, len(v1.Matchers))
+	for _, value := range v1.Matchers {
+		m, err := labels.ParseMatcher(value.Value())
+		if err != nil {
+			return RecurringSilence{}, fmt.Errorf("recurring silence %s: %w", uid, err)
+		}
+		matchers = append(matchers, &amv2.Matcher{
+			Name:    &m.Name,
+			Value:   &m.Value,
+			IsEqual: util.Pointer(m.Type == labels.MatchEqual || m.Type == labels.MatchRegexp),
+			IsRegex: util.Pointer(m.Type == labels.MatchRegexp || m.Type == labels.MatchNotRegexp),
+		})
+	}
+	duration, err := model.ParseDuration(v1.Duration.Value())
+	if err != nil {
+		return RecurringSilence{}, fmt.Errorf("recurring silence %s: invalid duration: %w", uid, err)
+	}
+	return RecurringSilence{
+		OrgID: orgID,
+		RecurringSilence: models.RecurringSilence{
+			UID:       uid,
+			Matchers:  matchers,
+			Schedule:  strings.TrimSpace(v1.Schedule.Value()),
+			Duration:  time.Duration(duration),
+			Timezone:  strings.TrimSpace(v1.Timezone.Value()),
+			Comment:   v1.Comment.Value(),
+			CreatedBy: "provisioning",
+		},
+	}, nil
+}
+
+type RecurringSilence struct {
+	OrgID            int64
+	RecurringSilence models.RecurringSilence
+}
+
+type DeleteRecurringSilenceV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+func (v1 *DeleteRecurringSilenceV1) mapToModel() (DeleteRecurringSilence, error) {
+	uid := strings.TrimSpace(v1.UID.Value())
+	if uid == "" {
+		return DeleteRecurringSilence{}, errors.New("delete recurring silence missing uid")
+	}
+	orgID := v1.OrgID.Value()
+	if orgID < 1 {
+		orgID = 1
+	}
+	return DeleteRecurringSilence{
+		OrgID: orgID,
+		UID:   uid,
+	}, nil
+}
+
+type DeleteRecurringSilence struct {
+	OrgID int64
+	UID   string
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/writer"
 	"github.com/grafana/grafana/pkg/services/provisioning/values"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -72,11 +73,14 @@ type AlertRuleV1 struct {
 	NoDataState          values.StringValue      `json:"noDataState" yaml:"noDataState"`
 	ExecErrState         values.StringValue      `json:"execErrState" yaml:"execErrState"`
 	For                  values.StringValue      `json:"for" yaml:"for"`
+	KeepFiringFor        values.StringValue      `json:"keepFiringFor" yaml:"keepFiringFor"`
 	Annotations          values.StringMapValue   `json:"annotations" yaml:"annotations"`
 	Labels               values.StringMapValue   `json:"labels" yaml:"labels"`
 	IsPaused             values.BoolValue        `json:"isPaused" yaml:"isPaused"`
 	NotificationSettings *NotificationSettingsV1 `json:"notification_settings" yaml:"notification_settings"`
 	Record               *RecordV1               `json:"record" yaml:"record"`
+	EvaluationTimeout    values.StringValue      `json:"evaluationTimeout" yaml:"evaluationTimeout"`
+	Priority             values.StringValue      `json:"priority" yaml:"priority"`
 }
 
 func withFallback(value, fallback string) *string {
@@ -102,6 +106,24 @@ func (rule *AlertRuleV1) mapToModel(orgID int64) (models.AlertRule, error) {
 		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
 	}
 	alertRule.For = time.Duration(duration)
+	if keepFiringFor := rule.KeepFiringFor.Value(); keepFiringFor != "" {
+		duration, err := model.ParseDuration(keepFiringFor)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.KeepFiringFor = time.Duration(duration)
+	}
+	if evaluationTimeout := rule.EvaluationTimeout.Value(); evaluationTimeout != "" {
+		duration, err := model.ParseDuration(evaluationTimeout)
+		if err != nil {
+			return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+		}
+		alertRule.EvaluationTimeout = time.Duration(duration)
+	}
+	alertRule.Priority, err = models.RulePriorityFromString(strings.TrimSpace(rule.Priority.Value()))
+	if err != nil {
+		return models.AlertRule{}, fmt.Errorf("rule '%s' failed to parse: %w", alertRule.Title, err)
+	}
 	dasboardUID := rule.DasboardUID.Value()
 	dashboardUID := rule.DashboardUID.Value()
 	alertRule.DashboardUID = withFallback(dashboardUID, dasboardUID) // Use correct spelling over supported typo.
@@ -267,11 +289,17 @@ func (nsV1 *NotificationSettingsV1) mapToModel() (models.NotificationSettings, e
 type RecordV1 struct {
 	Metric values.StringValue `json:"metric" yaml:"metric"`
 	From   values.StringValue `json:"from" yaml:"from"`
+	Target values.StringValue `json:"target" yaml:"target"`
 }
 
 func (record *RecordV1) mapToModel() (models.Record, error) {
+	target := record.Target.Value()
+	if !writer.IsValidTarget(target) {
+		return models.Record{}, fmt.Errorf("unknown target %q of recording rule, must be one of: %s", target, strings.Join(writer.Targets, ", "))
+	}
 	return models.Record{
 		Metric: record.Metric.Value(),
 		From:   record.From.Value(),
+		Target: target,
 	}, nil
 }
@@ -127,6 +127,50 @@ func TestRules(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 48*time.Hour, ruleMapped.For)
 	})
+	t.Run("a rule with a keep firing for duration should work", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("5m"), &keepFiringFor)
+		rule.KeepFiringFor = keepFiringFor
+		require.NoError(t, err)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Minute, ruleMapped.KeepFiringFor)
+	})
+	t.Run("a rule with an invalid keep firing for duration should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		keepFiringFor := values.StringValue{}
+		err := yaml.Unmarshal([]byte("10x"), &keepFiringFor)
+		rule.KeepFiringFor = keepFiringFor
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with an evaluation timeout and a priority should work", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("45s"), &rule.EvaluationTimeout)
+		require.NoError(t, err)
+		err = yaml.Unmarshal([]byte("critical"), &rule.Priority)
+		require.NoError(t, err)
+		ruleMapped, err := rule.mapToModel(1)
+		require.NoError(t, err)
+		require.Equal(t, 45*time.Second, ruleMapped.EvaluationTimeout)
+		require.Equal(t, models.RulePriorityCritical, ruleMapped.Priority)
+	})
+	t.Run("a rule with an invalid evaluation timeout should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("10x"), &rule.EvaluationTimeout)
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
+	t.Run("a rule with an unknown priority should error", func(t *testing.T) {
+		rule := validRuleV1(t)
+		err := yaml.Unmarshal([]byte("urgent"), &rule.Priority)
+		require.NoError(t, err)
+		_, err = rule.mapToModel(1)
+		require.Error(t, err)
+	})
 	t.Run("a rule with out a condition should error", func(t *testing.T) {
 		rule := validRuleV1(t)
 		rule.Condition = values.StringValue{}
@@ -294,6 +294,9 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		st, ps.SQLStore, ps.Cfg.UnifiedAlerting, ps.log)
 	mutetimingsService := provisioning.NewMuteTimingService(configStore, st, &st, ps.log, &st)
 	templateService := provisioning.NewTemplateService(configStore, st, &st, ps.log)
+	// The silences of the recurring silences are materialized by the running Alertmanagers.
+	recurringSilenceService := notifier.NewRecurringSilenceService(alertingauthz.NewSilenceService(ps.ac, &st), &st, nil,
+		ps.Cfg.UnifiedAlerting.RecurringSilences, ps.log)
 	cfg := prov_alerting.ProvisionerConfig{
 		Path:                       alertingPath,
 		RuleService:                *ruleService,
@@ -303,6 +306,7 @@ func (ps *ProvisioningServiceImpl) ProvisionAlerting(ctx context.Context) error
 		NotificiationPolicyService: *notificationPolicyService,
 		MuteTimingService:          *mutetimingsService,
 		TemplateService:            *templateService,
+		RecurringSilenceService:    recurringSilenceService,
 	}
 	return ps.provisionAlerting(ctx, cfg)
 }
@@ -131,6 +131,26 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.AddReceiverActionScopesMigration(mg)
 
 	ualert.AddRuleMetadata(mg)
+
+	ualert.AddRuleKeepFiringFor(mg)
+
+	ualert.AddRuleInhibitedBy(mg)
+
+	ualert.AddAlertStateHistoryTable(mg)
+
+	ualert.AddRecordedSamplesTable(mg)
+
+	ualert.AddRuleEvaluationSettings(mg)
+
+	ualert.AddNotificationHistoryTable(mg)
+
+	ualert.AddRecurringSilenceTable(mg)
+
+	ualert.AddConfigurationHistoryAuthor(mg)
+
+	ualert.AddStateKeepFiringSinceColumn(mg)
+
+	addLivePipelineMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -0,0 +1,17 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddConfigurationHistoryAuthor adds column to store the login of the user that saved a version of the Alertmanager configuration.
+func AddConfigurationHistoryAuthor(mg *migrator.Migrator) {
+	mg.AddMigration(
+		"add author column to alert_configuration_history table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_configuration_history"}, &migrator.Column{
+			Name:     "author",
+			Type:     migrator.DB_NVarchar,
+			Length:   190,
+			Nullable: false,
+			Default:  "''",
+		}),
+	)
+}
@@ -0,0 +1,36 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleEvaluationSettings adds columns to store the evaluation timeout and the priority class of the rule.
+func AddRuleEvaluationSettings(mg *migrator.Migrator) {
+	timeout := &migrator.Column{
+		Name:     "evaluation_timeout",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}
+	priority := &migrator.Column{
+		Name:     "priority",
+		Type:     migrator.DB_NVarchar,
+		Length:   15,
+		Nullable: true,
+	}
+
+	mg.AddMigration(
+		"add evaluation_timeout column to alert_rule table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, timeout),
+	)
+	mg.AddMigration(
+		"add evaluation_timeout column to alert_rule_version table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, timeout),
+	)
+	mg.AddMigration(
+		"add priority column to alert_rule table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, priority),
+	)
+	mg.AddMigration(
+		"add priority column to alert_rule_version table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, priority),
+	)
+}
@@ -0,0 +1,21 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleInhibitedBy adds column to store the rules whose firing alerts suppress the alerts of the rule.
+func AddRuleInhibitedBy(mg *migrator.Migrator) {
+	column := &migrator.Column{
+		Name:     "inhibited_by",
+		Type:     migrator.DB_Text,
+		Nullable: true,
+	}
+
+	mg.AddMigration(
+		"add inhibited_by column to alert_rule table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, column),
+	)
+	mg.AddMigration(
+		"add inhibited_by column to alert_rule_version table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, column),
+	)
+}
@@ -0,0 +1,22 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRuleKeepFiringFor adds column to store the duration for which an alert keeps firing after the condition is no longer met.
+func AddRuleKeepFiringFor(mg *migrator.Migrator) {
+	column := &migrator.Column{
+		Name:     "keep_firing_for",
+		Type:     migrator.DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}
+
+	mg.AddMigration(
+		"add keep_firing_for column to alert_rule table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule"}, column),
+	)
+	mg.AddMigration(
+		"add keep_firing_for column to alert_rule_version table",
+		migrator.NewAddColumnMigration(migrator.Table{Name: "alert_rule_version"}, column),
+	)
+}
@@ -0,0 +1,36 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddNotificationHistoryTable creates the table that the Alertmanager writes the delivery attempts of notifications to.
+func AddNotificationHistoryTable(mg *migrator.Migrator) {
+	notificationHistory := migrator.Table{
+		Name: "alert_notification_history",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "receiver", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "integration_index", Type: migrator.DB_Int, Nullable: false},
+			{Name: "group_key", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alert_fingerprints", Type: migrator.DB_Text, Nullable: false},
+			{Name: "alert_count", Type: migrator.DB_Int, Nullable: false},
+			{Name: "status", Type: migrator.DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "status_code", Type: migrator.DB_Int, Nullable: false},
+			{Name: "error_message", Type: migrator.DB_Text, Nullable: true},
+			{Name: "retry", Type: migrator.DB_Bool, Nullable: false},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "receiver", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"org_id", "sent_at"}, Type: migrator.IndexType},
+			{Cols: []string{"sent_at"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_notification_history table", migrator.NewAddTableMigration(notificationHistory))
+	mg.AddMigration("add index on org_id, receiver and sent_at to alert_notification_history table", migrator.NewAddIndexMigration(notificationHistory, notificationHistory.Indices[0]))
+	mg.AddMigration("add index on org_id and sent_at to alert_notification_history table", migrator.NewAddIndexMigration(notificationHistory, notificationHistory.Indices[1]))
+	mg.AddMigration("add index on sent_at to alert_notification_history table", migrator.NewAddIndexMigration(notificationHistory, notificationHistory.Indices[2]))
+}
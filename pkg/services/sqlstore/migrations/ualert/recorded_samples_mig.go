@@ -0,0 +1,27 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRecordedSamplesTable creates the table that recording rules with the "sql" target write their samples to.
+func AddRecordedSamplesTable(mg *migrator.Migrator) {
+	samples := migrator.Table{
+		Name: "alert_recorded_sample",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "metric", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: false},
+			{Name: "labels_hash", Type: migrator.DB_NVarchar, Length: 16, Nullable: false},
+			{Name: "value", Type: migrator.DB_Double, Nullable: false},
+			{Name: "ts", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "metric", "ts"}, Type: migrator.IndexType},
+			{Cols: []string{"ts"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_recorded_sample table", migrator.NewAddTableMigration(samples))
+	mg.AddMigration("add index on org_id, metric and ts to alert_recorded_sample table", migrator.NewAddIndexMigration(samples, samples.Indices[0]))
+	mg.AddMigration("add index on ts to alert_recorded_sample table", migrator.NewAddIndexMigration(samples, samples.Indices[1]))
+}
@@ -0,0 +1,34 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddRecurringSilenceTable creates the table of the silences that repeat on a schedule.
+func AddRecurringSilenceTable(mg *migrator.Migrator) {
+	recurringSilence := migrator.Table{
+		Name: "alert_recurring_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: UIDMaxLength, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "schedule", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "duration", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "timezone", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: DefaultFieldMaxLength, Nullable: false},
+			{Name: "provenance", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "deleted", Type: migrator.DB_Bool, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "materialized_version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "materialized_until", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "materialized_silences", Type: migrator.DB_Text, Nullable: true},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_recurring_silence table", migrator.NewAddTableMigration(recurringSilence))
+	mg.AddMigration("add unique index on org_id and uid to alert_recurring_silence table", migrator.NewAddIndexMigration(recurringSilence, recurringSilence.Indices[0]))
+}
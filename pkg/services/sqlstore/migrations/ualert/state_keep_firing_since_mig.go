@@ -0,0 +1,12 @@
+package ualert
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+// AddStateKeepFiringSinceColumn adds column to alert_instance to represent KeepFiringSince.
+func AddStateKeepFiringSinceColumn(mg *migrator.Migrator) {
+	mg.AddMigration("add keep_firing_since column to alert_instance table", migrator.NewAddColumnMigration(migrator.Table{Name: "alert_instance"}, &migrator.Column{
+		Name:     "keep_firing_since",
+		Type:     migrator.DB_BigInt,
+		Nullable: true,
+	}))
+}
@@ -413,6 +413,15 @@ type Cfg struct {
 
 	// ExpressionsEnabled specifies whether expressions are enabled.
 	ExpressionsEnabled bool
+	// SQLExpressionMaxInputRows is the maximum number of input rows of a SQL
+	// expression. 0 disables the limit.
+	SQLExpressionMaxInputRows int64
+	// SQLExpressionMaxOutputRows is the maximum number of rows a SQL
+	// expression may return. 0 disables the limit.
+	SQLExpressionMaxOutputRows int64
+	// SQLExpressionMaxMemoryBytes is the maximum estimated memory a SQL
+	// expression may use. 0 disables the limit.
+	SQLExpressionMaxMemoryBytes int64
 
 	ImageUploadProvider string
 
@@ -429,6 +438,15 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePipelineStorage is the storage of the channel rules and write configs of
+	// the Live pipeline: "file" or "database". Zero value disables the pipeline.
+	LivePipelineStorage string
+	// LiveManagedStreamHistoryFrames, LiveManagedStreamHistoryDuration and
+	// LiveManagedStreamHistoryMaxBytes limit the frames managed streams keep for every
+	// channel and send to new subscribers. Zero frames and duration keep the last frame only.
+	LiveManagedStreamHistoryFrames   int
+	LiveManagedStreamHistoryDuration time.Duration
+	LiveManagedStreamHistoryMaxBytes int
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -749,6 +767,9 @@ func (cfg *Cfg) readAnnotationSettings() error {
 func (cfg *Cfg) readExpressionsSettings() {
 	expressions := cfg.Raw.Section("expressions")
 	cfg.ExpressionsEnabled = expressions.Key("enabled").MustBool(true)
+	cfg.SQLExpressionMaxInputRows = expressions.Key("sql_expression_max_input_rows").MustInt64(200000)
+	cfg.SQLExpressionMaxOutputRows = expressions.Key("sql_expression_max_output_rows").MustInt64(100000)
+	cfg.SQLExpressionMaxMemoryBytes = expressions.Key("sql_expression_max_memory_bytes").MustInt64(256 * 1024 * 1024)
 }
 
 type AnnotationCleanupSettings struct {
@@ -2044,6 +2065,26 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 
 	cfg.LiveAllowedOrigins = originPatterns
+
+	cfg.LivePipelineStorage = section.Key("pipeline_storage").MustString("")
+	switch cfg.LivePipelineStorage {
+	case "", "file", "database":
+	default:
+		return fmt.Errorf("unsupported live pipeline storage type: %s", cfg.LivePipelineStorage)
+	}
+
+	cfg.LiveManagedStreamHistoryFrames = section.Key("managed_stream_history_frames").MustInt(0)
+	if cfg.LiveManagedStreamHistoryFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_frames", cfg.LiveManagedStreamHistoryFrames)
+	}
+	cfg.LiveManagedStreamHistoryDuration = section.Key("managed_stream_history_duration").MustDuration(0)
+	if cfg.LiveManagedStreamHistoryDuration < 0 {
+		return fmt.Errorf("unexpected value %s for [live] managed_stream_history_duration", cfg.LiveManagedStreamHistoryDuration)
+	}
+	cfg.LiveManagedStreamHistoryMaxBytes = section.Key("managed_stream_history_max_bytes").MustInt(1048576)
+	if cfg.LiveManagedStreamHistoryMaxBytes < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_max_bytes", cfg.LiveManagedStreamHistoryMaxBytes)
+	}
 	return nil
 }
 
@@ -65,6 +65,15 @@ const (
 	lokiDefaultMaxQueryLength      = 721 * time.Hour // 30d1h, matches the default value in Loki
 	defaultRecordingRequestTimeout = 10 * time.Second
 	lokiDefaultMaxQuerySize        = 65536 // 64kb
+	sqlDefaultRetention            = 30 * 24 * time.Hour
+	recordingRulesDefaultTarget    = "prometheus"
+	// notificationHistoryDefaultRetention is long enough to look into the notifications of the last on-call rotation.
+	notificationHistoryDefaultRetention = 7 * 24 * time.Hour
+	// recurringSilencesDefaultHorizon covers a daily window and the time to recover from an outage of the database.
+	recurringSilencesDefaultHorizon  = 24 * time.Hour
+	recurringSilencesDefaultInterval = time.Minute
+	// recordingRulesDefaultSQLRetention matches the default retention of Prometheus.
+	recordingRulesDefaultSQLRetention = 15 * 24 * time.Hour
 )
 
 type UnifiedAlertingSettings struct {
@@ -90,7 +99,9 @@ type UnifiedAlertingSettings struct {
 	HARedisMaxConns                 int
 	HARedisTLSEnabled               bool
 	HARedisTLSConfig                dstls.ClientConfig
+	HASchedulerShardingEnabled      bool
 	MaxAttempts                     int64
+	MaxConcurrentEvaluations        int
 	MinInterval                     time.Duration
 	EvaluationTimeout               time.Duration
 	EvaluationResultLimit           int
@@ -108,6 +119,8 @@ type UnifiedAlertingSettings struct {
 	ReservedLabels                UnifiedAlertingReservedLabelSettings
 	SkipClustering                bool
 	StateHistory                  UnifiedAlertingStateHistorySettings
+	NotificationHistory           UnifiedAlertingNotificationHistorySettings
+	RecurringSilences             UnifiedAlertingRecurringSilencesSettings
 	RemoteAlertmanager            RemoteAlertmanagerSettings
 	RecordingRules                RecordingRuleSettings
 
@@ -130,6 +143,26 @@ type RecordingRuleSettings struct {
 	BasicAuthPassword string
 	CustomHeaders     map[string]string
 	Timeout           time.Duration
+
+	// DefaultTarget is the writer target of the recording rules that do not select a target and whose organization
+	// does not have one configured.
+	DefaultTarget string
+	// OrgTargets are the writer targets of the recording rules of organizations, by organization ID.
+	OrgTargets map[int64]string
+	InfluxDB   RecordingRuleTargetSettings
+	OTLP       RecordingRuleTargetSettings
+	// SQLRetention is how long the samples written to the "sql" target are kept. Zero keeps them forever.
+	SQLRetention time.Duration
+}
+
+// RecordingRuleTargetSettings is the configuration of a writer target of recording rules that is reached over HTTP.
+type RecordingRuleTargetSettings struct {
+	URL               string
+	BasicAuthUsername string
+	BasicAuthPassword string
+	// Token is sent in the Authorization header with the "Token" scheme, as expected by InfluxDB.
+	Token   string
+	Timeout time.Duration
 }
 
 // RemoteAlertmanagerSettings contains the configuration needed
@@ -169,6 +202,28 @@ type UnifiedAlertingStateHistorySettings struct {
 	MultiPrimary          string
 	MultiSecondaries      []string
 	ExternalLabels        map[string]string
+	// SQLRetention is how long the state history stored by the "sql" backend is kept. Zero keeps it forever.
+	SQLRetention time.Duration
+	// PrometheusWriteURL, PrometheusBasicAuthUsername, PrometheusBasicAuthPassword and PrometheusTimeout configure
+	// the remote write endpoint that the "prometheus" backend writes the ALERTS and ALERTS_FOR_STATE series to.
+	PrometheusWriteURL          string
+	PrometheusBasicAuthUsername string
+	PrometheusBasicAuthPassword string
+	PrometheusTimeout           time.Duration
+}
+
+type UnifiedAlertingNotificationHistorySettings struct {
+	// Enabled enables the recording of the delivery attempts of notifications to the Grafana database.
+	Enabled bool
+	// Retention is how long the delivery attempts are kept. Zero keeps them forever.
+	Retention time.Duration
+}
+
+type UnifiedAlertingRecurringSilencesSettings struct {
+	// Horizon is how far ahead the windows of recurring silences are materialized into silences.
+	Horizon time.Duration
+	// Interval is how often the windows of recurring silences are materialized.
+	Interval time.Duration
 }
 
 // IsEnabled returns true if UnifiedAlertingSettings.Enabled is either nil or true.
@@ -277,6 +332,7 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	uaCfg.HARedisTLSConfig.InsecureSkipVerify = ua.Key("ha_redis_tls_insecure_skip_verify").MustBool(false)
 	uaCfg.HARedisTLSConfig.CipherSuites = ua.Key("ha_redis_tls_cipher_suites").MustString("")
 	uaCfg.HARedisTLSConfig.MinVersion = ua.Key("ha_redis_tls_min_version").MustString("")
+	uaCfg.HASchedulerShardingEnabled = ua.Key("ha_scheduler_sharding_enabled").MustBool(false)
 
 	// TODO load from ini file
 	uaCfg.DefaultConfiguration = alertmanagerDefaultConfiguration
@@ -306,6 +362,11 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 
 	uaCfg.MaxAttempts = ua.Key("max_attempts").MustInt64(schedulerDefaultMaxAttempts)
 
+	uaCfg.MaxConcurrentEvaluations = ua.Key("max_concurrent_evaluations").MustInt(0)
+	if uaCfg.MaxConcurrentEvaluations < 0 {
+		return fmt.Errorf("value of setting 'max_concurrent_evaluations' cannot be negative: %d", uaCfg.MaxConcurrentEvaluations)
+	}
+
 	uaCfg.BaseInterval = SchedulerBaseInterval
 
 	// TODO: This was promoted from a feature toggle and is now the default behavior.
@@ -404,22 +465,45 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 	stateHistory := iniFile.Section("unified_alerting.state_history")
 	stateHistoryLabels := iniFile.Section("unified_alerting.state_history.external_labels")
 	uaCfgStateHistory := UnifiedAlertingStateHistorySettings{
-		Enabled:               stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
-		Backend:               stateHistory.Key("backend").MustString("annotations"),
-		LokiRemoteURL:         stateHistory.Key("loki_remote_url").MustString(""),
-		LokiReadURL:           stateHistory.Key("loki_remote_read_url").MustString(""),
-		LokiWriteURL:          stateHistory.Key("loki_remote_write_url").MustString(""),
-		LokiTenantID:          stateHistory.Key("loki_tenant_id").MustString(""),
-		LokiBasicAuthUsername: stateHistory.Key("loki_basic_auth_username").MustString(""),
-		LokiBasicAuthPassword: stateHistory.Key("loki_basic_auth_password").MustString(""),
-		LokiMaxQueryLength:    stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
-		LokiMaxQuerySize:      stateHistory.Key("loki_max_query_size").MustInt(lokiDefaultMaxQuerySize),
-		MultiPrimary:          stateHistory.Key("primary").MustString(""),
-		MultiSecondaries:      splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
-		ExternalLabels:        stateHistoryLabels.KeysHash(),
+		Enabled:                     stateHistory.Key("enabled").MustBool(stateHistoryDefaultEnabled),
+		Backend:                     stateHistory.Key("backend").MustString("annotations"),
+		LokiRemoteURL:               stateHistory.Key("loki_remote_url").MustString(""),
+		LokiReadURL:                 stateHistory.Key("loki_remote_read_url").MustString(""),
+		LokiWriteURL:                stateHistory.Key("loki_remote_write_url").MustString(""),
+		LokiTenantID:                stateHistory.Key("loki_tenant_id").MustString(""),
+		LokiBasicAuthUsername:       stateHistory.Key("loki_basic_auth_username").MustString(""),
+		LokiBasicAuthPassword:       stateHistory.Key("loki_basic_auth_password").MustString(""),
+		LokiMaxQueryLength:          stateHistory.Key("loki_max_query_length").MustDuration(lokiDefaultMaxQueryLength),
+		LokiMaxQuerySize:            stateHistory.Key("loki_max_query_size").MustInt(lokiDefaultMaxQuerySize),
+		MultiPrimary:                stateHistory.Key("primary").MustString(""),
+		MultiSecondaries:            splitTrim(stateHistory.Key("secondaries").MustString(""), ","),
+		ExternalLabels:              stateHistoryLabels.KeysHash(),
+		SQLRetention:                stateHistory.Key("sql_retention").MustDuration(sqlDefaultRetention),
+		PrometheusWriteURL:          stateHistory.Key("prometheus_remote_write_url").MustString(""),
+		PrometheusBasicAuthUsername: stateHistory.Key("prometheus_basic_auth_username").MustString(""),
+		PrometheusBasicAuthPassword: stateHistory.Key("prometheus_basic_auth_password").MustString(""),
+		PrometheusTimeout:           stateHistory.Key("prometheus_timeout").MustDuration(defaultRecordingRequestTimeout),
 	}
 	uaCfg.StateHistory = uaCfgStateHistory
 
+	notificationHistory := iniFile.Section("unified_alerting.notification_history")
+	uaCfg.NotificationHistory = UnifiedAlertingNotificationHistorySettings{
+		Enabled:   notificationHistory.Key("enabled").MustBool(false),
+		Retention: notificationHistory.Key("retention").MustDuration(notificationHistoryDefaultRetention),
+	}
+
+	recurringSilences := iniFile.Section("unified_alerting.recurring_silences")
+	uaCfg.RecurringSilences = UnifiedAlertingRecurringSilencesSettings{
+		Horizon:  recurringSilences.Key("horizon").MustDuration(recurringSilencesDefaultHorizon),
+		Interval: recurringSilences.Key("interval").MustDuration(recurringSilencesDefaultInterval),
+	}
+	if uaCfg.RecurringSilences.Horizon <= 0 {
+		return fmt.Errorf("setting 'horizon' in section 'unified_alerting.recurring_silences' must be positive")
+	}
+	if uaCfg.RecurringSilences.Interval <= 0 || uaCfg.RecurringSilences.Interval > uaCfg.RecurringSilences.Horizon {
+		return fmt.Errorf("setting 'interval' in section 'unified_alerting.recurring_silences' must be positive and not greater than 'horizon'")
+	}
+
 	rr := iniFile.Section("recording_rules")
 	uaCfgRecordingRules := RecordingRuleSettings{
 		Enabled:           rr.Key("enabled").MustBool(false),
@@ -436,6 +520,39 @@ func (cfg *Cfg) ReadUnifiedAlertingSettings(iniFile *ini.File) error {
 		uaCfgRecordingRules.CustomHeaders[key.Name()] = key.Value()
 	}
 
+	uaCfgRecordingRules.DefaultTarget = rr.Key("target").MustString(recordingRulesDefaultTarget)
+	rrOrgTargets := iniFile.Section("recording_rules.org_targets")
+	uaCfgRecordingRules.OrgTargets = make(map[int64]string, len(rrOrgTargets.Keys()))
+	for _, key := range rrOrgTargets.Keys() {
+		orgID, err := strconv.ParseInt(key.Name(), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID %q in section [recording_rules.org_targets]: %w", key.Name(), err)
+		}
+		uaCfgRecordingRules.OrgTargets[orgID] = key.Value()
+	}
+
+	rrInflux := iniFile.Section("recording_rules.influxdb")
+	uaCfgRecordingRules.InfluxDB = RecordingRuleTargetSettings{
+		URL:               rrInflux.Key("url").MustString(""),
+		BasicAuthUsername: rrInflux.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: rrInflux.Key("basic_auth_password").MustString(""),
+		Token:             rrInflux.Key("token").MustString(""),
+		Timeout:           rrInflux.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+	}
+
+	rrOTLP := iniFile.Section("recording_rules.otlp")
+	uaCfgRecordingRules.OTLP = RecordingRuleTargetSettings{
+		URL:               rrOTLP.Key("url").MustString(""),
+		BasicAuthUsername: rrOTLP.Key("basic_auth_username").MustString(""),
+		BasicAuthPassword: rrOTLP.Key("basic_auth_password").MustString(""),
+		Timeout:           rrOTLP.Key("timeout").MustDuration(defaultRecordingRequestTimeout),
+	}
+
+	uaCfgRecordingRules.SQLRetention, err = gtime.ParseDuration(valueAsString(iniFile.Section("recording_rules.sql"), "retention", recordingRulesDefaultSQLRetention.String()))
+	if err != nil {
+		return err
+	}
+
 	uaCfg.RecordingRules = uaCfgRecordingRules
 
 	uaCfg.MaxStateSaveConcurrency = ua.Key("max_state_save_concurrency").MustInt(1)
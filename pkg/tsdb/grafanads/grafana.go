@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -51,15 +52,21 @@ var (
 	)
 )
 
-func ProvideService(search searchV2.SearchService, store store.StorageService) *Service {
-	return newService(search, store)
+// RecordedMetricsReader reads the metrics that recording rules wrote to the Grafana database.
+type RecordedMetricsReader interface {
+	QueryRecordedMetric(ctx context.Context, orgID int64, metric string, labels map[string]string, from, to time.Time) (data.Frames, error)
 }
 
-func newService(search searchV2.SearchService, store store.StorageService) *Service {
+func ProvideService(search searchV2.SearchService, store store.StorageService, recorded RecordedMetricsReader) *Service {
+	return newService(search, store, recorded)
+}
+
+func newService(search searchV2.SearchService, store store.StorageService, recorded RecordedMetricsReader) *Service {
 	s := &Service{
-		search: search,
-		store:  store,
-		log:    log.New("grafanads"),
+		search:   search,
+		store:    store,
+		recorded: recorded,
+		log:      log.New("grafanads"),
 	}
 
 	return s
@@ -67,9 +74,10 @@ func newService(search searchV2.SearchService, store store.StorageService) *Serv
 
 // Service exists regardless of user settings
 type Service struct {
-	search searchV2.SearchService
-	store  store.StorageService
-	log    log.Logger
+	search   searchV2.SearchService
+	store    store.StorageService
+	recorded RecordedMetricsReader
+	log      log.Logger
 }
 
 func DataSourceModel(orgId int64) *datasources.DataSource {
@@ -97,6 +105,8 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			response.Responses[q.RefID] = s.doReadQuery(ctx, q)
 		case queryTypeSearch:
 			response.Responses[q.RefID] = s.doSearchQuery(ctx, req, q)
+		case queryTypeRecordedMetric:
+			response.Responses[q.RefID] = s.doRecordedMetricQuery(ctx, req, q)
 		default:
 			response.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("unknown query type"),
@@ -163,6 +173,30 @@ func (s *Service) doReadQuery(ctx context.Context, query backend.DataQuery) back
 	return response
 }
 
+func (s *Service) doRecordedMetricQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &recordedMetricQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	if q.Metric == "" {
+		response.Error = fmt.Errorf("metric is required")
+		return response
+	}
+	if s.recorded == nil {
+		response.Error = fmt.Errorf("recorded metrics are not available")
+		return response
+	}
+
+	frames, err := s.recorded.QueryRecordedMetric(ctx, req.PluginContext.OrgID, q.Metric, q.Labels, query.TimeRange.From, query.TimeRange.To)
+	response.Error = err
+	response.Frames = frames
+	return response
+}
+
 func (s *Service) doRandomWalk(query backend.DataQuery) backend.DataResponse {
 	response := backend.DataResponse{}
 
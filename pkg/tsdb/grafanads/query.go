@@ -14,6 +14,9 @@ const (
 	// currently only .csv files are supported,
 	// other file types will eventually be supported (parquet, etc)
 	queryTypeRead = "read"
+
+	// QueryTypeRecordedMetric returns the series of a metric that recording rules wrote to the Grafana database
+	queryTypeRecordedMetric = "recordedMetric"
 )
 
 type listQueryModel struct {
@@ -22,3 +25,9 @@ type listQueryModel struct {
 type readQueryModel struct {
 	Path string `json:"path"`
 }
+
+type recordedMetricQueryModel struct {
+	Metric string `json:"metric"`
+	// Labels restrict the series to the ones with these labels.
+	Labels map[string]string `json:"labels"`
+}
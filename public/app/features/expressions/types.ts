@@ -66,7 +66,7 @@ export const expressionTypes: Array<SelectableValue<ExpressionQueryType>> = [
   {
     value: ExpressionQueryType.sql,
     label: 'SQL',
-    description: 'Transform data using SQL. Supports Aggregate/Analytics functions from DuckDB',
+    description: 'Transform data using SQL. Supports joins, aggregate and window functions',
   },
 ].filter((expr) => {
   if (expr.value === ExpressionQueryType.sql) {
@@ -121,6 +121,8 @@ export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
   { value: 'fillna', label: 'fillna', description: 'Fill with NaNs' },
+  { value: 'linear', label: 'linear', description: 'interpolate between the last known and the next known value' },
+  { value: 'nearest', label: 'nearest', description: 'fill with the known value closest in time' },
 ];
 
 export const thresholdFunctions: Array<SelectableValue<EvalFunction>> = [